@@ -0,0 +1,757 @@
+// bt-shieldml gRPC 接口：流式返回每个文件的扫描结果，以及引擎状态。
+// 修改后在仓库根目录重新生成 Go 代码：
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//          --go-grpc_out=. --go-grpc_opt=paths=source_relative api/proto/shield.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: api/proto/shield.proto
+
+package shieldpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RiskLevel 与 types.RiskLevel 的取值一致
+type RiskLevel int32
+
+const (
+	RiskLevel_RISK_LEVEL_UNKNOWN  RiskLevel = 0
+	RiskLevel_RISK_LEVEL_NONE     RiskLevel = 1
+	RiskLevel_RISK_LEVEL_LOW      RiskLevel = 2
+	RiskLevel_RISK_LEVEL_MEDIUM   RiskLevel = 3
+	RiskLevel_RISK_LEVEL_HIGH     RiskLevel = 4
+	RiskLevel_RISK_LEVEL_CRITICAL RiskLevel = 5
+)
+
+// Enum value maps for RiskLevel.
+var (
+	RiskLevel_name = map[int32]string{
+		0: "RISK_LEVEL_UNKNOWN",
+		1: "RISK_LEVEL_NONE",
+		2: "RISK_LEVEL_LOW",
+		3: "RISK_LEVEL_MEDIUM",
+		4: "RISK_LEVEL_HIGH",
+		5: "RISK_LEVEL_CRITICAL",
+	}
+	RiskLevel_value = map[string]int32{
+		"RISK_LEVEL_UNKNOWN":  0,
+		"RISK_LEVEL_NONE":     1,
+		"RISK_LEVEL_LOW":      2,
+		"RISK_LEVEL_MEDIUM":   3,
+		"RISK_LEVEL_HIGH":     4,
+		"RISK_LEVEL_CRITICAL": 5,
+	}
+)
+
+func (x RiskLevel) Enum() *RiskLevel {
+	p := new(RiskLevel)
+	*p = x
+	return p
+}
+
+func (x RiskLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RiskLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_shield_proto_enumTypes[0].Descriptor()
+}
+
+func (RiskLevel) Type() protoreflect.EnumType {
+	return &file_api_proto_shield_proto_enumTypes[0]
+}
+
+func (x RiskLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RiskLevel.Descriptor instead.
+func (RiskLevel) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_shield_proto_rawDescGZIP(), []int{0}
+}
+
+type ScanRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Paths          []string               `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`                                          // 服务端上的文件或目录，必须存在
+	Exclusions     []string               `protobuf:"bytes,2,rep,name=exclusions,proto3" json:"exclusions,omitempty"`                                // 需要排除的文件或目录
+	ScanArchives   bool                   `protobuf:"varint,3,opt,name=scan_archives,json=scanArchives,proto3" json:"scan_archives,omitempty"`       // 同 -scan-archives
+	FollowSymlinks bool                   `protobuf:"varint,4,opt,name=follow_symlinks,json=followSymlinks,proto3" json:"follow_symlinks,omitempty"` // 同 -follow-symlinks
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ScanRequest) Reset() {
+	*x = ScanRequest{}
+	mi := &file_api_proto_shield_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRequest) ProtoMessage() {}
+
+func (x *ScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shield_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRequest.ProtoReflect.Descriptor instead.
+func (*ScanRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_shield_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ScanRequest) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *ScanRequest) GetExclusions() []string {
+	if x != nil {
+		return x.Exclusions
+	}
+	return nil
+}
+
+func (x *ScanRequest) GetScanArchives() bool {
+	if x != nil {
+		return x.ScanArchives
+	}
+	return false
+}
+
+func (x *ScanRequest) GetFollowSymlinks() bool {
+	if x != nil {
+		return x.FollowSymlinks
+	}
+	return false
+}
+
+type ScanResultEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      int64                  `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"` // 本次扫描中的事件序号，从 1 开始
+	Result        *ScanResult            `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanResultEvent) Reset() {
+	*x = ScanResultEvent{}
+	mi := &file_api_proto_shield_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanResultEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanResultEvent) ProtoMessage() {}
+
+func (x *ScanResultEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shield_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanResultEvent.ProtoReflect.Descriptor instead.
+func (*ScanResultEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_shield_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScanResultEvent) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *ScanResultEvent) GetResult() *ScanResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type FileInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	RelativePath  string                 `protobuf:"bytes,2,opt,name=relative_path,json=relativePath,proto3" json:"relative_path,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	ModTime       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"`
+	Encoding      string                 `protobuf:"bytes,5,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_api_proto_shield_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shield_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_api_proto_shield_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FileInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileInfo) GetRelativePath() string {
+	if x != nil {
+		return x.RelativePath
+	}
+	return ""
+}
+
+func (x *FileInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetModTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModTime
+	}
+	return nil
+}
+
+func (x *FileInfo) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+type Finding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Analyzer      string                 `protobuf:"bytes,1,opt,name=analyzer,proto3" json:"analyzer,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Risk          RiskLevel              `protobuf:"varint,3,opt,name=risk,proto3,enum=btshieldml.v1.RiskLevel" json:"risk,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Severity      string                 `protobuf:"bytes,5,opt,name=severity,proto3" json:"severity,omitempty"`
+	CvssVector    string                 `protobuf:"bytes,6,opt,name=cvss_vector,json=cvssVector,proto3" json:"cvss_vector,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 值为 JSON 编码
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Finding) Reset() {
+	*x = Finding{}
+	mi := &file_api_proto_shield_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Finding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Finding) ProtoMessage() {}
+
+func (x *Finding) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shield_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Finding.ProtoReflect.Descriptor instead.
+func (*Finding) Descriptor() ([]byte, []int) {
+	return file_api_proto_shield_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Finding) GetAnalyzer() string {
+	if x != nil {
+		return x.Analyzer
+	}
+	return ""
+}
+
+func (x *Finding) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Finding) GetRisk() RiskLevel {
+	if x != nil {
+		return x.Risk
+	}
+	return RiskLevel_RISK_LEVEL_UNKNOWN
+}
+
+func (x *Finding) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Finding) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Finding) GetCvssVector() string {
+	if x != nil {
+		return x.CvssVector
+	}
+	return ""
+}
+
+func (x *Finding) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type ScanResult struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	File              *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	OverallRisk       RiskLevel              `protobuf:"varint,2,opt,name=overall_risk,json=overallRisk,proto3,enum=btshieldml.v1.RiskLevel" json:"overall_risk,omitempty"`
+	Findings          []*Finding             `protobuf:"bytes,3,rep,name=findings,proto3" json:"findings,omitempty"`
+	Error             string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"` // 扫描出错时的错误信息，为空表示成功
+	Duration          *durationpb.Duration   `protobuf:"bytes,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	SkippedAst        bool                   `protobuf:"varint,6,opt,name=skipped_ast,json=skippedAst,proto3" json:"skipped_ast,omitempty"`
+	PartialAst        bool                   `protobuf:"varint,7,opt,name=partial_ast,json=partialAst,proto3" json:"partial_ast,omitempty"`
+	SkippedAnalyzers  []string               `protobuf:"bytes,8,rep,name=skipped_analyzers,json=skippedAnalyzers,proto3" json:"skipped_analyzers,omitempty"`
+	TruncatedFindings bool                   `protobuf:"varint,9,opt,name=truncated_findings,json=truncatedFindings,proto3" json:"truncated_findings,omitempty"`
+	DeployedBy        string                 `protobuf:"bytes,10,opt,name=deployed_by,json=deployedBy,proto3" json:"deployed_by,omitempty"`
+	DuplicateOf       string                 `protobuf:"bytes,11,opt,name=duplicate_of,json=duplicateOf,proto3" json:"duplicate_of,omitempty"`
+	Incomplete        bool                   `protobuf:"varint,12,opt,name=incomplete,proto3" json:"incomplete,omitempty"`
+	Cached            bool                   `protobuf:"varint,13,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ScanResult) Reset() {
+	*x = ScanResult{}
+	mi := &file_api_proto_shield_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanResult) ProtoMessage() {}
+
+func (x *ScanResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shield_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanResult.ProtoReflect.Descriptor instead.
+func (*ScanResult) Descriptor() ([]byte, []int) {
+	return file_api_proto_shield_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScanResult) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *ScanResult) GetOverallRisk() RiskLevel {
+	if x != nil {
+		return x.OverallRisk
+	}
+	return RiskLevel_RISK_LEVEL_UNKNOWN
+}
+
+func (x *ScanResult) GetFindings() []*Finding {
+	if x != nil {
+		return x.Findings
+	}
+	return nil
+}
+
+func (x *ScanResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ScanResult) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *ScanResult) GetSkippedAst() bool {
+	if x != nil {
+		return x.SkippedAst
+	}
+	return false
+}
+
+func (x *ScanResult) GetPartialAst() bool {
+	if x != nil {
+		return x.PartialAst
+	}
+	return false
+}
+
+func (x *ScanResult) GetSkippedAnalyzers() []string {
+	if x != nil {
+		return x.SkippedAnalyzers
+	}
+	return nil
+}
+
+func (x *ScanResult) GetTruncatedFindings() bool {
+	if x != nil {
+		return x.TruncatedFindings
+	}
+	return false
+}
+
+func (x *ScanResult) GetDeployedBy() string {
+	if x != nil {
+		return x.DeployedBy
+	}
+	return ""
+}
+
+func (x *ScanResult) GetDuplicateOf() string {
+	if x != nil {
+		return x.DuplicateOf
+	}
+	return ""
+}
+
+func (x *ScanResult) GetIncomplete() bool {
+	if x != nil {
+		return x.Incomplete
+	}
+	return false
+}
+
+func (x *ScanResult) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type StatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // ok，PHP 桥接不可用时为 degraded
+	Analyzers     []string               `protobuf:"bytes,2,rep,name=analyzers,proto3" json:"analyzers,omitempty"`
+	AstBridge     string                 `protobuf:"bytes,3,opt,name=ast_bridge,json=astBridge,proto3" json:"ast_bridge,omitempty"`
+	Workers       int32                  `protobuf:"varint,4,opt,name=workers,proto3" json:"workers,omitempty"`
+	BusyWorkers   int32                  `protobuf:"varint,5,opt,name=busy_workers,json=busyWorkers,proto3" json:"busy_workers,omitempty"`
+	ActiveScans   int64                  `protobuf:"varint,6,opt,name=active_scans,json=activeScans,proto3" json:"active_scans,omitempty"`
+	UptimeSeconds int64                  `protobuf:"varint,7,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_api_proto_shield_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_shield_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_shield_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetAnalyzers() []string {
+	if x != nil {
+		return x.Analyzers
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetAstBridge() string {
+	if x != nil {
+		return x.AstBridge
+	}
+	return ""
+}
+
+func (x *StatusResponse) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetBusyWorkers() int32 {
+	if x != nil {
+		return x.BusyWorkers
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetActiveScans() int64 {
+	if x != nil {
+		return x.ActiveScans
+	}
+	return 0
+}
+
+func (x *StatusResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+var File_api_proto_shield_proto protoreflect.FileDescriptor
+
+const file_api_proto_shield_proto_rawDesc = "" +
+	"\n" +
+	"\x16api/proto/shield.proto\x12\rbtshieldml.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x91\x01\n" +
+	"\vScanRequest\x12\x14\n" +
+	"\x05paths\x18\x01 \x03(\tR\x05paths\x12\x1e\n" +
+	"\n" +
+	"exclusions\x18\x02 \x03(\tR\n" +
+	"exclusions\x12#\n" +
+	"\rscan_archives\x18\x03 \x01(\bR\fscanArchives\x12'\n" +
+	"\x0ffollow_symlinks\x18\x04 \x01(\bR\x0efollowSymlinks\"`\n" +
+	"\x0fScanResultEvent\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x03R\bsequence\x121\n" +
+	"\x06result\x18\x02 \x01(\v2\x19.btshieldml.v1.ScanResultR\x06result\"\xaa\x01\n" +
+	"\bFileInfo\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12#\n" +
+	"\rrelative_path\x18\x02 \x01(\tR\frelativePath\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x125\n" +
+	"\bmod_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\amodTime\x12\x1a\n" +
+	"\bencoding\x18\x05 \x01(\tR\bencoding\"\xd1\x02\n" +
+	"\aFinding\x12\x1a\n" +
+	"\banalyzer\x18\x01 \x01(\tR\banalyzer\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12,\n" +
+	"\x04risk\x18\x03 \x01(\x0e2\x18.btshieldml.v1.RiskLevelR\x04risk\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x01R\n" +
+	"confidence\x12\x1a\n" +
+	"\bseverity\x18\x05 \x01(\tR\bseverity\x12\x1f\n" +
+	"\vcvss_vector\x18\x06 \x01(\tR\n" +
+	"cvssVector\x12@\n" +
+	"\bmetadata\x18\a \x03(\v2$.btshieldml.v1.Finding.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x91\x04\n" +
+	"\n" +
+	"ScanResult\x12+\n" +
+	"\x04file\x18\x01 \x01(\v2\x17.btshieldml.v1.FileInfoR\x04file\x12;\n" +
+	"\foverall_risk\x18\x02 \x01(\x0e2\x18.btshieldml.v1.RiskLevelR\voverallRisk\x122\n" +
+	"\bfindings\x18\x03 \x03(\v2\x16.btshieldml.v1.FindingR\bfindings\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x125\n" +
+	"\bduration\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x1f\n" +
+	"\vskipped_ast\x18\x06 \x01(\bR\n" +
+	"skippedAst\x12\x1f\n" +
+	"\vpartial_ast\x18\a \x01(\bR\n" +
+	"partialAst\x12+\n" +
+	"\x11skipped_analyzers\x18\b \x03(\tR\x10skippedAnalyzers\x12-\n" +
+	"\x12truncated_findings\x18\t \x01(\bR\x11truncatedFindings\x12\x1f\n" +
+	"\vdeployed_by\x18\n" +
+	" \x01(\tR\n" +
+	"deployedBy\x12!\n" +
+	"\fduplicate_of\x18\v \x01(\tR\vduplicateOf\x12\x1e\n" +
+	"\n" +
+	"incomplete\x18\f \x01(\bR\n" +
+	"incomplete\x12\x16\n" +
+	"\x06cached\x18\r \x01(\bR\x06cached\"\xec\x01\n" +
+	"\x0eStatusResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1c\n" +
+	"\tanalyzers\x18\x02 \x03(\tR\tanalyzers\x12\x1d\n" +
+	"\n" +
+	"ast_bridge\x18\x03 \x01(\tR\tastBridge\x12\x18\n" +
+	"\aworkers\x18\x04 \x01(\x05R\aworkers\x12!\n" +
+	"\fbusy_workers\x18\x05 \x01(\x05R\vbusyWorkers\x12!\n" +
+	"\factive_scans\x18\x06 \x01(\x03R\vactiveScans\x12%\n" +
+	"\x0euptime_seconds\x18\a \x01(\x03R\ruptimeSeconds*\x91\x01\n" +
+	"\tRiskLevel\x12\x16\n" +
+	"\x12RISK_LEVEL_UNKNOWN\x10\x00\x12\x13\n" +
+	"\x0fRISK_LEVEL_NONE\x10\x01\x12\x12\n" +
+	"\x0eRISK_LEVEL_LOW\x10\x02\x12\x15\n" +
+	"\x11RISK_LEVEL_MEDIUM\x10\x03\x12\x13\n" +
+	"\x0fRISK_LEVEL_HIGH\x10\x04\x12\x17\n" +
+	"\x13RISK_LEVEL_CRITICAL\x10\x052\x99\x01\n" +
+	"\rShieldService\x12D\n" +
+	"\x04Scan\x12\x1a.btshieldml.v1.ScanRequest\x1a\x1e.btshieldml.v1.ScanResultEvent0\x01\x12B\n" +
+	"\tGetStatus\x12\x16.google.protobuf.Empty\x1a\x1d.btshieldml.v1.StatusResponseB Z\x1ebt-shieldml/api/proto;shieldpbb\x06proto3"
+
+var (
+	file_api_proto_shield_proto_rawDescOnce sync.Once
+	file_api_proto_shield_proto_rawDescData []byte
+)
+
+func file_api_proto_shield_proto_rawDescGZIP() []byte {
+	file_api_proto_shield_proto_rawDescOnce.Do(func() {
+		file_api_proto_shield_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_shield_proto_rawDesc), len(file_api_proto_shield_proto_rawDesc)))
+	})
+	return file_api_proto_shield_proto_rawDescData
+}
+
+var file_api_proto_shield_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_shield_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_api_proto_shield_proto_goTypes = []any{
+	(RiskLevel)(0),                // 0: btshieldml.v1.RiskLevel
+	(*ScanRequest)(nil),           // 1: btshieldml.v1.ScanRequest
+	(*ScanResultEvent)(nil),       // 2: btshieldml.v1.ScanResultEvent
+	(*FileInfo)(nil),              // 3: btshieldml.v1.FileInfo
+	(*Finding)(nil),               // 4: btshieldml.v1.Finding
+	(*ScanResult)(nil),            // 5: btshieldml.v1.ScanResult
+	(*StatusResponse)(nil),        // 6: btshieldml.v1.StatusResponse
+	nil,                           // 7: btshieldml.v1.Finding.MetadataEntry
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 9: google.protobuf.Duration
+	(*emptypb.Empty)(nil),         // 10: google.protobuf.Empty
+}
+var file_api_proto_shield_proto_depIdxs = []int32{
+	5,  // 0: btshieldml.v1.ScanResultEvent.result:type_name -> btshieldml.v1.ScanResult
+	8,  // 1: btshieldml.v1.FileInfo.mod_time:type_name -> google.protobuf.Timestamp
+	0,  // 2: btshieldml.v1.Finding.risk:type_name -> btshieldml.v1.RiskLevel
+	7,  // 3: btshieldml.v1.Finding.metadata:type_name -> btshieldml.v1.Finding.MetadataEntry
+	3,  // 4: btshieldml.v1.ScanResult.file:type_name -> btshieldml.v1.FileInfo
+	0,  // 5: btshieldml.v1.ScanResult.overall_risk:type_name -> btshieldml.v1.RiskLevel
+	4,  // 6: btshieldml.v1.ScanResult.findings:type_name -> btshieldml.v1.Finding
+	9,  // 7: btshieldml.v1.ScanResult.duration:type_name -> google.protobuf.Duration
+	1,  // 8: btshieldml.v1.ShieldService.Scan:input_type -> btshieldml.v1.ScanRequest
+	10, // 9: btshieldml.v1.ShieldService.GetStatus:input_type -> google.protobuf.Empty
+	2,  // 10: btshieldml.v1.ShieldService.Scan:output_type -> btshieldml.v1.ScanResultEvent
+	6,  // 11: btshieldml.v1.ShieldService.GetStatus:output_type -> btshieldml.v1.StatusResponse
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_shield_proto_init() }
+func file_api_proto_shield_proto_init() {
+	if File_api_proto_shield_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_shield_proto_rawDesc), len(file_api_proto_shield_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_proto_shield_proto_goTypes,
+		DependencyIndexes: file_api_proto_shield_proto_depIdxs,
+		EnumInfos:         file_api_proto_shield_proto_enumTypes,
+		MessageInfos:      file_api_proto_shield_proto_msgTypes,
+	}.Build()
+	File_api_proto_shield_proto = out.File
+	file_api_proto_shield_proto_goTypes = nil
+	file_api_proto_shield_proto_depIdxs = nil
+}
@@ -0,0 +1,173 @@
+// bt-shieldml gRPC 接口：流式返回每个文件的扫描结果，以及引擎状态。
+// 修改后在仓库根目录重新生成 Go 代码：
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//          --go-grpc_out=. --go-grpc_opt=paths=source_relative api/proto/shield.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: api/proto/shield.proto
+
+package shieldpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ShieldService_Scan_FullMethodName      = "/btshieldml.v1.ShieldService/Scan"
+	ShieldService_GetStatus_FullMethodName = "/btshieldml.v1.ShieldService/GetStatus"
+)
+
+// ShieldServiceClient is the client API for ShieldService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ShieldServiceClient interface {
+	// Scan 扫描请求中的路径，每个文件扫描完成后立即返回一个事件 (顺序为完成顺序)
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanResultEvent], error)
+	// GetStatus 返回引擎状态 (启用的分析器、PHP 桥接、并发名额)
+	GetStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusResponse, error)
+}
+
+type shieldServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShieldServiceClient(cc grpc.ClientConnInterface) ShieldServiceClient {
+	return &shieldServiceClient{cc}
+}
+
+func (c *shieldServiceClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanResultEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShieldService_ServiceDesc.Streams[0], ShieldService_Scan_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ScanRequest, ScanResultEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShieldService_ScanClient = grpc.ServerStreamingClient[ScanResultEvent]
+
+func (c *shieldServiceClient) GetStatus(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, ShieldService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShieldServiceServer is the server API for ShieldService service.
+// All implementations must embed UnimplementedShieldServiceServer
+// for forward compatibility.
+type ShieldServiceServer interface {
+	// Scan 扫描请求中的路径，每个文件扫描完成后立即返回一个事件 (顺序为完成顺序)
+	Scan(*ScanRequest, grpc.ServerStreamingServer[ScanResultEvent]) error
+	// GetStatus 返回引擎状态 (启用的分析器、PHP 桥接、并发名额)
+	GetStatus(context.Context, *emptypb.Empty) (*StatusResponse, error)
+	mustEmbedUnimplementedShieldServiceServer()
+}
+
+// UnimplementedShieldServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShieldServiceServer struct{}
+
+func (UnimplementedShieldServiceServer) Scan(*ScanRequest, grpc.ServerStreamingServer[ScanResultEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedShieldServiceServer) GetStatus(context.Context, *emptypb.Empty) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedShieldServiceServer) mustEmbedUnimplementedShieldServiceServer() {}
+func (UnimplementedShieldServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeShieldServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShieldServiceServer will
+// result in compilation errors.
+type UnsafeShieldServiceServer interface {
+	mustEmbedUnimplementedShieldServiceServer()
+}
+
+func RegisterShieldServiceServer(s grpc.ServiceRegistrar, srv ShieldServiceServer) {
+	// If the following call pancis, it indicates UnimplementedShieldServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ShieldService_ServiceDesc, srv)
+}
+
+func _ShieldService_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShieldServiceServer).Scan(m, &grpc.GenericServerStream[ScanRequest, ScanResultEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShieldService_ScanServer = grpc.ServerStreamingServer[ScanResultEvent]
+
+func _ShieldService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShieldServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShieldService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShieldServiceServer).GetStatus(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ShieldService_ServiceDesc is the grpc.ServiceDesc for ShieldService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShieldService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "btshieldml.v1.ShieldService",
+	HandlerType: (*ShieldServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _ShieldService_GetStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Scan",
+			Handler:       _ShieldService_Scan_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/proto/shield.proto",
+}
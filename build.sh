@@ -13,7 +13,7 @@ fi
 cd "$(dirname "$0")"
 
 # 创建嵌入文件的目录结构
-mkdir -p pkg/embedded/data/models pkg/embedded/data/signatures
+mkdir -p pkg/embedded/data/models pkg/embedded/data/signatures pkg/embedded/data/config
 
 # 复制所有需要嵌入的文件到pkg/embedded目录下的相应位置
 cp -f config.yaml pkg/embedded/
@@ -21,6 +21,7 @@ cp -f data/models/ProcessSVM.model.info pkg/embedded/data/models/
 cp -f data/models/ProcessSVM.model.model pkg/embedded/data/models/
 cp -f data/models/Words.model pkg/embedded/data/models/
 cp -f data/signatures/Webshells_rules.yar pkg/embedded/data/signatures/
+cp -f data/config/fingerprints.yaml pkg/embedded/data/config/
 
 # 设置完全静态编译的环境变量
 export CGO_ENABLED=1
@@ -36,6 +37,7 @@ go build -tags yara_static,netgo,osusergo -ldflags '-s -w -extldflags "-static"'
 echo "静态构建完成: bt-shieldml"
 
 # 编译web服务平台
-go build -tags netgo,osusergo -ldflags '-s -w -extldflags "-static"' -o shieldml_server ./shieldml_server.go
+# 规则提交接口需要 YARA 编译器试编译规则
+go build -tags yara_static,netgo,osusergo -ldflags '-s -w -extldflags "-static"' -o shieldml_server ./shieldml_server.go
 
 echo "静态构建完成: shieldml_server"
\ No newline at end of file
@@ -0,0 +1,191 @@
+/*
+ * @Date: 2025-06-20 16:40:53
+ * @Editors: Mr wpl
+ * @Description: 在带标注的样本集上对比两种评分策略，以 Markdown 输出 A/B 对比结果与各策略相对标注的检出效果
+ */
+package main
+
+import (
+	"bt-shieldml/internal/config"
+	"bt-shieldml/internal/engine"
+	"bt-shieldml/internal/scoring"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sample 标注样本
+type sample struct {
+	path     string
+	webshell bool
+}
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "Path to configuration file")
+	truthPath := flag.String("truth", "", "Ground-truth CSV with a header row: path,label (label: 1/webshell or 0/normal); relative paths are resolved against the CSV's directory (required)")
+	strategyA := flag.String("a", "default", "Scoring strategy A: default or max_finding")
+	strategyB := flag.String("b", "max_finding", "Scoring strategy B: default or max_finding")
+	thresholdName := flag.String("threshold", "medium", "Minimum risk level counted as a webshell detection when comparing with the labels")
+	verbose := flag.Bool("verbose", false, "Show INFO/WARNING logs from scanning")
+	flag.Parse()
+
+	if *truthPath == "" {
+		logging.ErrorLogger.Println("Error: -truth argument is required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+	threshold, err := types.ParseRiskLevel(*thresholdName)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Invalid -threshold: %v", err)
+	}
+
+	samples, err := readTruth(*truthPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read ground truth: %v", err)
+	}
+	if len(samples) == 0 {
+		logging.ErrorLogger.Fatalf("No samples in %s", *truthPath)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if cfg == nil {
+		logging.ErrorLogger.Fatalf("Failed to load configuration: %v", err)
+	}
+	// default 策略使用配置中的 scoring 规则
+	a, err := scoring.StrategyByName(*strategyA, cfg.Scoring)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Invalid -a: %v", err)
+	}
+	b, err := scoring.StrategyByName(*strategyB, cfg.Scoring)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Invalid -b: %v", err)
+	}
+	if !*verbose {
+		logging.InfoLogger.SetOutput(io.Discard)
+		logging.WarnLogger.SetOutput(io.Discard)
+	}
+	scanEngine, err := engine.NewEngine(cfg)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to initialize engine: %v", err)
+	}
+	defer scanEngine.Close()
+
+	results := make([]*types.ScanResult, 0, len(samples))
+	labels := make(map[string]bool, len(samples))
+	for _, s := range samples {
+		result := scanEngine.ScanFile(s.path)
+		if result.Error != nil {
+			logging.ErrorLogger.Printf("Skipping %s: %v", s.path, result.Error)
+			continue
+		}
+		results = append(results, result)
+		labels[result.File.Path] = s.webshell
+	}
+
+	report := scoring.ABTest(a, b, results)
+	fmt.Print(report.Markdown())
+
+	fmt.Printf("\n### Against ground truth (detection = risk >= %s)\n\n", threshold)
+	fmt.Println("| Strategy | TP | FP | FN | TN | Precision | Recall | F1 |")
+	fmt.Println("|---|---|---|---|---|---|---|---|")
+	for _, side := range []struct {
+		name  string
+		risks map[string]types.RiskLevel
+	}{{report.StrategyA, report.RisksA}, {report.StrategyB, report.RisksB}} {
+		var tp, fp, fn, tn int
+		for path, risk := range side.risks {
+			detected := risk >= threshold
+			switch {
+			case detected && labels[path]:
+				tp++
+			case detected:
+				fp++
+			case labels[path]:
+				fn++
+			default:
+				tn++
+			}
+		}
+		precision, recall, f1 := prf(tp, fp, fn)
+		fmt.Printf("| %s | %d | %d | %d | %d | %.4f | %.4f | %.4f |\n", side.name, tp, fp, fn, tn, precision, recall, f1)
+	}
+}
+
+// prf 计算精确率、召回率与 F1，分母为 0 时为 0
+func prf(tp, fp, fn int) (float64, float64, float64) {
+	var precision, recall, f1 float64
+	if tp+fp > 0 {
+		precision = float64(tp) / float64(tp+fp)
+	}
+	if tp+fn > 0 {
+		recall = float64(tp) / float64(tp+fn)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return precision, recall, f1
+}
+
+/**
+ * @Description: 读取标注 CSV，相对路径按 CSV 所在目录解析
+ * @author: Mr wpl
+ * @param path string: CSV 路径
+ * @return []sample: 标注样本
+ * @return error: 错误
+ */
+func readTruth(path string) ([]sample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	pathCol, ok := columns["path"]
+	if !ok {
+		return nil, fmt.Errorf("missing column path")
+	}
+	labelCol, ok := columns["label"]
+	if !ok {
+		return nil, fmt.Errorf("missing column label")
+	}
+
+	baseDir := filepath.Dir(path)
+	var samples []sample
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		s := sample{path: strings.TrimSpace(record[pathCol])}
+		if !filepath.IsAbs(s.path) {
+			s.path = filepath.Join(baseDir, s.path)
+		}
+		switch strings.ToLower(strings.TrimSpace(record[labelCol])) {
+		case "1", "webshell":
+			s.webshell = true
+		case "0", "normal":
+		default:
+			return nil, fmt.Errorf("line %d: invalid label %q (expected 1/webshell or 0/normal)", line, record[labelCol])
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
@@ -0,0 +1,97 @@
+/*
+ * @Date: 2025-05-28 10:40:33
+ * @Editors: Mr wpl
+ * @Description: 将 feedback.jsonl 中的人工反馈应用到 Bayes Words 模型
+ */
+package main
+
+import (
+	"bt-shieldml/internal/analyzers/ml"
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/internal/features"
+	"bt-shieldml/internal/feedback"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	feedbackPath := flag.String("feedback", feedback.DefaultPath, "Path to the feedback jsonl file")
+	modelPath := flag.String("model", "data/models/Words.model", "Path to the Bayes Words model to update")
+	flag.Parse()
+
+	entries, err := feedback.ReadAll(*feedbackPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read feedback: %v", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No feedback to apply.")
+		return
+	}
+
+	model, err := ml.LoadWordsModel(*modelPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to load model: %v", err)
+	}
+
+	astMgr, err := ast.NewPhpAstManager()
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to start PHP AST bridge: %v", err)
+	}
+	defer astMgr.Cleanup()
+
+	applied := 0
+	for _, entry := range entries {
+		words, err := extractWords(astMgr, entry.Path)
+		if err != nil {
+			fmt.Printf("[SKIP] %s: %v\n", entry.Path, err)
+			continue
+		}
+		if err := model.ApplyCorrection(words, entry.CorrectLabel); err != nil {
+			fmt.Printf("[SKIP] %s: %v\n", entry.Path, err)
+			continue
+		}
+		fmt.Printf("[OK] %s -> %s (%d words)\n", entry.Path, entry.CorrectLabel, len(words))
+		applied++
+	}
+
+	if applied == 0 {
+		fmt.Println("No feedback could be applied, model unchanged.")
+		os.Exit(1)
+	}
+	if err := model.Save(*modelPath); err != nil {
+		logging.ErrorLogger.Fatalf("Failed to save model: %v", err)
+	}
+	fmt.Printf("Applied %d/%d feedback entries to %s\n", applied, len(entries), *modelPath)
+}
+
+/**
+ * @Description: 重新解析文件并提取与扫描时一致的 AST 词
+ * @author: Mr wpl
+ * @param astMgr *ast.PhpAstManager: AST 管理器
+ * @param path string: 文件路径
+ * @return []string: 词
+ * @return error: 错误
+ */
+func extractWords(astMgr *ast.PhpAstManager, path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	goAST, err := astMgr.GetAST(content)
+	if err != nil {
+		return nil, fmt.Errorf("AST parse failed: %w", err)
+	}
+	fileInfo := types.FileInfo{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+	fs, _ := features.ExtractAllFeatures(fileInfo, content, goAST, astMgr)
+	if fs == nil || len(fs.ASTWords) == 0 {
+		return nil, fmt.Errorf("no words extracted")
+	}
+	return fs.ASTWords, nil
+}
@@ -0,0 +1,42 @@
+/*
+ * @Date: 2025-06-19 09:36:51
+ * @Editors: Mr wpl
+ * @Description: 删除报告目录中已过期 (由 -report-ttl 写入过期时间) 的 HTML 与 JSON 报告
+ */
+package main
+
+import (
+	"bt-shieldml/internal/reporting"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "data/reports", "Directory holding the .html and .json reports")
+	dryRun := flag.Bool("dry-run", false, "Only list expired reports, do not delete them")
+	flag.Parse()
+
+	expired, errs := reporting.CleanupExpiredReports(*dir, time.Now(), *dryRun)
+	for _, err := range errs {
+		fmt.Printf("[ERROR] %v\n", err)
+	}
+
+	action := "[DELETED]"
+	if *dryRun {
+		action = "[EXPIRED]"
+	}
+	for _, report := range expired {
+		fmt.Printf("%s %s (expired %s)\n", action, report.Path, report.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	if *dryRun {
+		fmt.Printf("\n%d expired reports in %s (dry run, nothing deleted).\n", len(expired), *dir)
+	} else {
+		fmt.Printf("\nDeleted %d expired reports from %s.\n", len(expired), *dir)
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * @Date: 2025-06-11 15:47:02
+ * @Editors: Mr wpl
+ * @Description: 解释 SVM Prosses 分析器对单个文件的判定，按贡献输出 9 个特征的排名
+ */
+package main
+
+import (
+	"bt-shieldml/internal/analyzers/ml"
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/internal/config"
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "Path to configuration file (for data_paths.models)")
+	filePath := flag.String("file", "", "PHP file to explain (required)")
+	verbose := flag.Bool("verbose", false, "Show INFO/WARNING logs from feature extraction and model loading")
+	flag.Parse()
+
+	if *filePath == "" && flag.NArg() > 0 {
+		*filePath = flag.Arg(0)
+	}
+	if *filePath == "" {
+		logging.ErrorLogger.Println("Error: -file argument is required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if cfg == nil {
+		logging.ErrorLogger.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	content, err := os.ReadFile(*filePath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read %s: %v", *filePath, err)
+	}
+	absPath, _ := filepath.Abs(*filePath)
+	fileInfo := types.FileInfo{Path: absPath, Size: int64(len(content))}
+
+	if !*verbose {
+		logging.InfoLogger.SetOutput(io.Discard)
+		logging.WarnLogger.SetOutput(io.Discard)
+	}
+
+	astMgr, err := ast.NewPhpAstManager()
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to start PHP AST bridge: %v", err)
+	}
+	defer astMgr.Cleanup()
+
+	featureSet, _, err := features.ExtractAllFeaturesParallel(fileInfo, content, astMgr)
+	if err != nil && (featureSet == nil || featureSet.Statistical == nil) {
+		logging.ErrorLogger.Fatalf("Failed to extract features: %v", err)
+	}
+
+	analyzer, err := ml.NewSvmProssesAnalyzer(cfg.DataPaths.Models)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to load SVM model: %v", err)
+	}
+	score, rawDecision, contributions, err := analyzer.Explain(fileInfo, content, featureSet)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to explain %s: %v", *filePath, err)
+	}
+
+	// 按贡献绝对值从大到小排序
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return math.Abs(contributions[i].Contribution) > math.Abs(contributions[j].Contribution)
+	})
+
+	fmt.Printf("File:          %s\n", absPath)
+	fmt.Printf("SVM score:     %.4f (raw decision %.4f)\n", score, rawDecision)
+	fmt.Printf("Contribution:  raw decision minus decision with the feature set to its training mean\n\n")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "rank\tfeature\traw_value\tnormalized\tcontribution\t")
+	for i, c := range contributions {
+		fmt.Fprintf(w, "%d\t%s\t%.4f\t%.4f\t%+.4f\t\n", i+1, c.Name, c.Raw, c.Normalized, c.Contribution)
+	}
+	w.Flush()
+}
@@ -0,0 +1,38 @@
+/*
+ * @Date: 2025-06-20 14:18:25
+ * @Editors: Mr wpl
+ * @Description: 根据已确认的木马文件生成 YARA 规则骨架并输出到 stdout，审核后可加入 Webshells_rules.yar
+ */
+package main
+
+import (
+	"bt-shieldml/internal/analyzers/static"
+	"bt-shieldml/pkg/logging"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	filePath := flag.String("file", "", "Detected webshell PHP file to build the rule from (required)")
+	flag.Parse()
+
+	if *filePath == "" && flag.NArg() > 0 {
+		*filePath = flag.Arg(0)
+	}
+	if *filePath == "" {
+		logging.ErrorLogger.Println("Error: -file argument is required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	content, err := os.ReadFile(*filePath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read %s: %v", *filePath, err)
+	}
+	rule, err := static.GenerateYaraRule(*filePath, content)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to generate rule: %v", err)
+	}
+	fmt.Print(rule)
+}
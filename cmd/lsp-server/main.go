@@ -0,0 +1,261 @@
+/*
+ * @Date: 2025-06-13 11:05:52
+ * @Editors: Mr wpl
+ * @Description: 最小化的 LSP 语言服务器 (stdio)，IDE 打开或保存 PHP 文件时扫描并以诊断形式显示 webshell 告警
+ */
+package main
+
+import (
+	"bt-shieldml/internal/config"
+	"bt-shieldml/internal/engine"
+	"bt-shieldml/internal/reporting"
+	"bt-shieldml/pkg/logging"
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// JSON-RPC 错误码
+const (
+	rpcMethodNotFound = -32601
+)
+
+// rpcMessage 客户端发来的请求或通知，ID 为空时是通知
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcError JSON-RPC 错误
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcResponse 对请求的成功响应 (result 可以为 null，但不能省略)
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result"`
+}
+
+// rpcErrorResponse 对请求的错误响应
+type rpcErrorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   rpcError        `json:"error"`
+}
+
+// textDocumentParams didOpen/didSave/didClose 中只用到文档 URI
+type textDocumentParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+// server 语言服务器状态
+type server struct {
+	engine   *engine.Engine
+	out      io.Writer
+	shutdown bool
+}
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "Path to configuration file")
+	flag.Parse()
+
+	// stdout 是 LSP 通道，日志只能写到 stderr
+	logging.RedirectToStderr()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if cfg == nil {
+		logging.ErrorLogger.Fatalf("Failed to load configuration: %v", err)
+	}
+	scanEngine, err := engine.NewEngine(cfg)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to initialize engine: %v", err)
+	}
+	defer scanEngine.Close()
+	// 常驻进程启动时预热，第一次 didOpen/didSave 不再承担初始化耗时
+	if err := scanEngine.Warmup(); err != nil {
+		logging.WarnLogger.Printf("Warmup incomplete: %v", err)
+	}
+
+	s := &server{engine: scanEngine, out: os.Stdout}
+	if err := s.serve(bufio.NewReader(os.Stdin)); err != nil && err != io.EOF {
+		logging.ErrorLogger.Printf("LSP server stopped: %v", err)
+	}
+	if !s.shutdown {
+		scanEngine.Close()
+		os.Exit(1) // 未收到 shutdown 就退出，按 LSP 约定返回 1
+	}
+}
+
+/**
+ * @Description: 读取并处理消息，直到收到 exit 或输入结束
+ * @author: Mr wpl
+ * @param r *bufio.Reader: 输入
+ * @return error: 错误
+ */
+func (s *server) serve(r *bufio.Reader) error {
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+/**
+ * @Description: 处理一条消息，只有写出失败时返回错误
+ * @author: Mr wpl
+ * @param msg *rpcMessage: 消息
+ * @return error: 错误
+ */
+func (s *server) handle(msg *rpcMessage) error {
+	switch msg.Method {
+	case "initialize":
+		return s.reply(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				// openClose + save，不需要文档内容的增量同步
+				"textDocumentSync": map[string]interface{}{"openClose": true, "change": 0, "save": true},
+			},
+			"serverInfo": map[string]string{"name": reporting.LSPSource},
+		})
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(msg.ID, nil)
+	case "textDocument/didOpen", "textDocument/didSave":
+		var params textDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			logging.WarnLogger.Printf("Invalid %s params: %v", msg.Method, err)
+			return nil
+		}
+		return s.scan(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params textDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		return s.publish(reporting.LSPPublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []reporting.LSPDiagnostic{}})
+	}
+
+	if len(msg.ID) > 0 {
+		return reporting.WriteLSPMessage(s.out, rpcErrorResponse{
+			JSONRPC: "2.0",
+			ID:      msg.ID,
+			Error:   rpcError{Code: rpcMethodNotFound, Message: "method not supported: " + msg.Method},
+		}, true)
+	}
+	return nil // 忽略其它通知 (initialized、didChange 等)
+}
+
+/**
+ * @Description: 扫描 URI 对应的本地文件并发布诊断 (没有发现时发布空诊断以清除旧告警)
+ * @author: Mr wpl
+ * @param uri string: 文档 URI
+ * @return error: 错误
+ */
+func (s *server) scan(uri string) error {
+	path, err := uriToPath(uri)
+	if err != nil {
+		logging.WarnLogger.Printf("Skipping %s: %v", uri, err)
+		return nil
+	}
+	result := s.engine.ScanFile(path)
+	params := reporting.ToLSPDiagnostics(result)
+	params.URI = uri // 沿用客户端的 URI，避免编码差异导致诊断对不上文档
+	logging.InfoLogger.Printf("Scanned %s: %s, %d diagnostics", path, result.OverallRisk, len(params.Diagnostics))
+	return s.publish(params)
+}
+
+// publish 发送 publishDiagnostics 通知
+func (s *server) publish(params reporting.LSPPublishDiagnosticsParams) error {
+	return reporting.WriteLSPMessage(s.out, reporting.LSPNotification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  params,
+	}, true)
+}
+
+// reply 发送请求的响应
+func (s *server) reply(id json.RawMessage, result interface{}) error {
+	return reporting.WriteLSPMessage(s.out, rpcResponse{JSONRPC: "2.0", ID: id, Result: result}, true)
+}
+
+/**
+ * @Description: 按 LSP 基础协议读取一条消息：若干 "Header: value" 行、空行、Content-Length 字节的 JSON
+ * @author: Mr wpl
+ * @param r *bufio.Reader: 输入
+ * @return *rpcMessage: 消息
+ * @return error: 错误
+ */
+func readMessage(r *bufio.Reader) (*rpcMessage, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q: %w", value, err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var msg rpcMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid JSON-RPC message: %w", err)
+	}
+	return &msg, nil
+}
+
+/**
+ * @Description: 将 file:// URI 转换为本地路径
+ * @author: Mr wpl
+ * @param uri string: 文档 URI
+ * @return string: 本地路径
+ * @return error: 非 file 协议时返回错误
+ */
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme %q", u.Scheme)
+	}
+	path := u.Path
+	// file:///C:/www/a.php → C:/www/a.php
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
@@ -8,28 +8,124 @@ package main
 import (
 	"bt-shieldml/internal/config"
 	"bt-shieldml/internal/engine"
+	"bt-shieldml/internal/memdiff"
+	"bt-shieldml/internal/metrics"
+	"bt-shieldml/internal/reporting"
+	"bt-shieldml/internal/scanstats"
+	"bt-shieldml/internal/scheduler"
+	"bt-shieldml/internal/state"
 	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bufio"
+	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 )
 
 func main() {
 	// --- Argument Parsing ---
 	configPath := flag.String("config", "config.yaml", "Path to configuration file")
-	targetPathsRaw := flag.String("path", "", "Comma-separated files or directories to scan (required)")
-	exclusionsRaw := flag.String("exclude", "", "Comma-separated files or directories to exclude")
-	outputFormat := flag.String("format", "", "Output format (console, json, html). Overrides config file.")
-	reportPath := flag.String("output", "", "Path to save report file (for json/html formats)")
+	targetPathsRaw := flag.String("path", "", "Comma-separated files or directories to scan (required unless -path-file is given). \"-\" reads PHP code from stdin; separate multiple files with a line containing only ---")
+	pathFile := flag.String("path-file", "", "File listing files or directories to scan, one per line (\"-\" for stdin). Empty lines and lines starting with # are skipped; merged with -path.")
+	exclusionsRaw := flag.String("exclude", "", "Comma-separated files, directories or glob patterns to exclude (e.g. vendor/**,*.min.php,tests/fixtures/**; ** matches any number of directories). Merged with exclusions in config.")
+	outputFormat := flag.String("format", "", "Output format (console, json, html, ndjson, lsp, sarif, csv, junit). Overrides config file. ndjson streams one JSON object per file to stdout as it completes; lsp streams LSP publishDiagnostics notifications; sarif writes a SARIF 2.1.0 document for code scanning platforms; csv writes one row per file for spreadsheets; junit writes JUnit XML (one testcase per file) for CI pipelines.")
+	reportPath := flag.String("output", "", "Path to save report file (for json/html/ndjson/sarif/pdf/csv/xml formats; the extension selects the format, .xml writes JUnit XML)")
+	signKeyPath := flag.String("sign-key", "", "PEM private key (Ed25519 or RSA) used to sign scan results")
+	verifyKeyPath := flag.String("verify-key", "", "PEM public key used to verify signatures right after signing")
+	followSymlinks := flag.Bool("follow-symlinks", false, "Follow symbolic links when walking directories (may be slow on wide symlink trees)")
+	scanArchives := flag.Bool("scan-archives", false, "Also scan .phar archives and the files packed inside .phar, .zip and .tar.gz/.tgz archives (nested archives included); same as scan_archives in the config")
+	groupByDir := flag.Bool("group-by-dir", false, "Print per-directory subtotals in the console report")
+	vtTimeout := flag.Duration("vt-timeout", 60*time.Second, "Maximum total time spent on VirusTotal lookups; unresolved files are reported as pending")
+	consoleTemplate := flag.String("console-template", "", "text/template file for the console report. Overrides output.console_template in config.")
+	sortBy := flag.String("sort-by", "", "Comma-separated report sort keys: risk_desc, risk_asc, path_asc, path_desc, size_desc, duration_desc, mtime_desc (e.g. risk_desc,path_asc)")
+	dumpConfig := flag.Bool("dump-config", false, "Print the effective configuration (after flag overrides) as YAML and exit")
+	minConfidence := flag.Float64("min-confidence", 0, "Suppress findings whose confidence is below this value (0-1). Findings without a confidence score are kept. Overrides output.min_confidence in config.")
+	verbose := flag.Bool("verbose", false, "Show findings suppressed by -min-confidence in the report, marked as [suppressed]")
+	since := flag.String("since", "", "Only scan files modified at or after this time (RFC3339, \"2006-01-02 15:04:05\" or \"2006-01-02\", local time)")
+	lastDays := flag.Int("last-days", 0, "Only scan files modified in the last N days (e.g. 1 = last 24 hours). Combined with -since/-last-hours, the most recent cutoff wins.")
+	lastHours := flag.Int("last-hours", 0, "Only scan files modified in the last N hours. Combined with -since/-last-days, the most recent cutoff wins.")
+	noDedup := flag.Bool("no-dedup", false, "Scan every path even if its content is identical to a file already scanned (by default duplicates reuse the first result)")
+	emoji := flag.Bool("emoji", false, "Use emoji risk markers in the console report (falls back to text when TERM=dumb or LC_ALL=C)")
+	schedule := flag.String("schedule", "", "Run as a daemon and rescan on this cron schedule (5 fields, e.g. \"0 3 * * *\"). Scans once immediately on startup; each run saves an HTML report to -output-dir.")
+	scheduleLog := flag.String("schedule-log", "", "Append a JSON summary line per scheduled run to this file")
+	timezone := flag.String("timezone", "", "IANA time zone for -schedule and report file names (e.g. Asia/Shanghai). Defaults to local time.")
+	outputDir := flag.String("output-dir", "data/reports", "Directory for the dated HTML reports written by -schedule (YYYY-MM-DD_HH-MM.html)")
+	profileMemDiff := flag.Bool("profile-memory-diff", false, "Print heap statistics, heap profiles and the top 10 allocation sites before vs. after each scan to stderr (diagnostic; requires the debugprofile build tag or BTSHIELD_DEBUG_MEM=1)")
+	reportTTL := flag.String("report-ttl", "", "Record an expiry time in JSON/HTML reports, e.g. 30d or 72h; expired reports are deleted by cleanup-reports or -schedule-cleanup")
+	scheduleCleanup := flag.String("schedule-cleanup", "", "With -schedule, delete expired reports in -output-dir on this cron schedule (e.g. \"0 4 * * *\"); waits for a running scan to finish")
+	colorScheme := flag.String("color-scheme", "", "HTML report risk colors: default, accessible (deuteranopia-safe), greyscale, custom, or a scheme defined in <data_paths.config>/color_schemes.yaml")
+	primaryColor := flag.String("primary-color", "", "Override the HTML report primary color (#rgb or #rrggbb); with -color-scheme custom, unset colors use the default scheme")
+	criticalColor := flag.String("critical-color", "", "Override the HTML report Critical risk color (#rgb or #rrggbb)")
+	highColor := flag.String("high-color", "", "Override the HTML report High risk color (#rgb or #rrggbb)")
+	mediumColor := flag.String("medium-color", "", "Override the HTML report Medium risk color (#rgb or #rrggbb)")
+	lowColor := flag.String("low-color", "", "Override the HTML report Low risk color (#rgb or #rrggbb)")
+	journalPath := flag.String("journal", "", "Record each scan in this JSON-L journal (e.g. "+scanstats.DefaultJournalPath+"); HTML reports show a trend of earlier scans of the same directory from it. Disabled when empty.")
+	warmup := flag.Bool("warmup", false, "Initialize regex rules, models and the PHP bridge before the first file and log how long it took")
+	incremental := flag.Bool("incremental", false, "Only scan files whose modification time or size changed since the last -incremental run; unchanged files reuse results from state_path (default data/scan_state.db)")
+	resetState := flag.Bool("reset-state", false, "Delete the -incremental scan state so every file is scanned again; exits after resetting when no -path is given")
+	analyzerTimeout := flag.Duration("analyzer-timeout", types.DefaultAnalyzerTimeout, "Maximum time per analyzer per file; a timed-out analyzer is recorded as \"analyzer timed out\" and the scan continues. 0 disables. Overrides performance.analyzer_timeout in config.")
+	fileTimeout := flag.Duration("file-timeout", types.DefaultFileScanTimeout, "Maximum time to scan one file; a timed-out file is reported as a scan error. 0 disables. Overrides performance.file_scan_timeout in config.")
+	minSize := flag.String("min-size", "", "Skip files smaller than this size (e.g. 512B, 1KB) and report them as Safe. Overrides performance.min_file_size_bytes in config.")
+	maxSize := flag.String("max-size", "", "Skip files larger than this size (e.g. 10MB, 1GB) and report them as Safe; 0 disables. Overrides performance.max_file_size_bytes in config.")
+	webhookURL := flag.String("webhook", "", "URL to POST a JSON notification to when a scheduled run finds Critical files, or (with -watch) when a rescanned file's risk increases. Overrides watch.webhook_url in config.")
+	slackWebhook := flag.String("slack-webhook", "", "Slack Incoming Webhook URL to notify when a scan finds files at or above slack.min_risk (default high). Overrides slack.webhook_url in config.")
+	metricsAddr := flag.String("metrics-addr", "", "With -schedule or -watch, serve Prometheus metrics on GET /metrics at this address (host:port)")
+	whitelistPath := flag.String("whitelist", "", "Whitelist file: one path glob (e.g. /var/www/vendor/** or vendor/laravel/) or sha256:<hash> per line; matching files skip all analyzers and are reported Safe. Overrides whitelist_path in config.")
+	historyReport := flag.Bool("history-report", false, "Print a JSON report of files whose risk level increased between the last two scans of the same path recorded in the scan history (history.enabled), then exit")
+	exportExcel := flag.String("export-excel", "", "Convert an existing JSON report (-json-report) to an .xlsx workbook at this path (Summary, Results and Findings sheets), then exit; no scan is run")
+	jsonReport := flag.String("json-report", "data/webshellJson.json", "JSON report read by -export-excel (written by -output report.json or -format json)")
+	dryRun := flag.Bool("dry-run", false, "List the files that would be scanned (count, paths, breakdown by extension, excluded paths), the analyzers that would run and an estimated scan time, without scanning or writing a report. -format json prints JSON.")
+	watch := flag.Bool("watch", false, "After the scan, keep running and rescan files under -path when they are created or modified (debounced by watch.debounce); stops on SIGINT/SIGTERM")
 
 	flag.Parse()
 
-	if *targetPathsRaw == "" {
-		logging.ErrorLogger.Println("Error: -path argument is required.")
+	if *targetPathsRaw == "" && *pathFile == "" && !*dumpConfig && !*resetState && !*historyReport && *exportExcel == "" {
+		logging.ErrorLogger.Println("Error: -path or -path-file argument is required.")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	paths := []string{}
+	readStdin := false
+	for _, p := range strings.Split(*targetPathsRaw, ",") {
+		if p = strings.TrimSpace(p); p == "-" {
+			readStdin = true
+		} else if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if readStdin && *pathFile == "-" {
+		logging.ErrorLogger.Fatalf("-path - and -path-file - cannot both read from stdin")
+	}
+	if readStdin && *schedule != "" {
+		logging.ErrorLogger.Fatalf("-path - cannot be used with -schedule")
+	}
+	if *pathFile != "" {
+		filePaths, err := readPathFile(*pathFile)
+		if err != nil {
+			logging.ErrorLogger.Fatalf("Failed to read -path-file: %v", err)
+		}
+		paths = append(paths, filePaths...)
+	}
+	if len(paths) == 0 && !readStdin && !*dumpConfig && !*resetState && !*historyReport && *exportExcel == "" {
+		logging.ErrorLogger.Fatalf("No paths to scan: -path and -path-file are empty")
+	}
+
+	sinceTime, err := engine.ParseSince(*since)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Invalid -since: %v", err)
+	}
+	if *lastDays < 0 || *lastHours < 0 {
+		logging.ErrorLogger.Fatalf("-last-days and -last-hours must not be negative")
+	}
+
 	// --- Load Configuration ---
 	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
@@ -45,39 +141,461 @@ func main() {
 	if *outputFormat != "" {
 		cfg.Output.Format = *outputFormat
 	}
+	if *consoleTemplate != "" {
+		cfg.Output.ConsoleTemplate = *consoleTemplate
+	}
+	if *sortBy != "" {
+		cfg.Output.SortKeys = strings.Split(*sortBy, ",")
+	}
+	if *whitelistPath != "" {
+		cfg.WhitelistPath = *whitelistPath
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "min-confidence":
+			cfg.Output.MinConfidence = *minConfidence
+		case "analyzer-timeout":
+			cfg.Performance.AnalyzerTimeout = timeoutFlag(*analyzerTimeout)
+		case "file-timeout":
+			cfg.Performance.FileScanTimeout = timeoutFlag(*fileTimeout)
+		case "min-size":
+			cfg.Performance.MinFileSizeBytes = sizeFlagBytes("min-size", *minSize)
+		case "max-size":
+			cfg.Performance.MaxFileSizeBytes = sizeFlagBytes("max-size", *maxSize)
+		}
+	})
+	if max := cfg.Performance.MaxFileSizeBytes; max > 0 && cfg.Performance.MinFileSizeBytes > max {
+		logging.ErrorLogger.Fatalf("Invalid size limits: minimum %d bytes is larger than maximum %d bytes", cfg.Performance.MinFileSizeBytes, max)
+	}
 
-	// --- Initialize Engine ---
-	scanEngine, err := engine.NewEngine(cfg)
-	if err != nil {
-		logging.ErrorLogger.Fatalf("Failed to initialize engine: %v", err)
+	// 流式格式写到 stdout 时，启动阶段的日志也不能混入输出
+	if *reportPath == "" {
+		switch strings.ToLower(cfg.Output.Format) {
+		case "ndjson", "lsp", "sarif", "csv", "junit":
+			logging.RedirectToStderr()
+		}
+	}
+	if *dryRun && strings.EqualFold(cfg.Output.Format, "json") {
+		logging.RedirectToStderr()
+	}
+
+	if *dumpConfig {
+		data, err := config.DumpConfig(cfg)
+		if err != nil {
+			logging.ErrorLogger.Fatalf("Failed to dump configuration: %v", err)
+		}
+		os.Stdout.Write(data)
+		return
+	}
+
+	if *exportExcel != "" {
+		if err := exportExcelReport(*jsonReport, *exportExcel); err != nil {
+			logging.ErrorLogger.Fatalf("-export-excel: %v", err)
+		}
+		logging.InfoLogger.Printf("Excel report written to %s", *exportExcel)
+		return
+	}
+
+	if *historyReport {
+		if err := printHistoryReport(cfg.History.Path()); err != nil {
+			logging.ErrorLogger.Fatalf("-history-report: %v", err)
+		}
+		return
+	}
+
+	if *resetState {
+		if err := state.Reset(cfg.StateFile()); err != nil {
+			logging.ErrorLogger.Fatalf("-reset-state: %v", err)
+		}
+		logging.InfoLogger.Printf("Scan state %s reset", cfg.StateFile())
+		if len(paths) == 0 && !readStdin {
+			return
+		}
 	}
 
 	// --- Prepare Scan Task ---
-	paths := strings.Split(*targetPathsRaw, ",")
 	exclusions := []string{}
 	if *exclusionsRaw != "" {
 		exclusions = strings.Split(*exclusionsRaw, ",")
 	}
 
-	// Trim spaces from paths and exclusions
-	for i := range paths {
-		paths[i] = strings.TrimSpace(paths[i])
-	}
+	// Trim spaces from exclusions
 	for i := range exclusions {
 		exclusions[i] = strings.TrimSpace(exclusions[i])
 	}
 
 	task := &engine.Task{
-		Paths:        paths,
-		Exclusions:   exclusions,
-		ReportPath:   *reportPath,
-		OutputFormat: cfg.Output.Format, // Use potentially overridden format
+		Paths:            paths,
+		Exclusions:       exclusions,
+		ReportPath:       *reportPath,
+		OutputFormat:     cfg.Output.Format, // Use potentially overridden format
+		FollowSymlinks:   *followSymlinks,
+		ScanArchives:     *scanArchives,
+		GroupByDirectory: *groupByDir,
+		Emoji:            *emoji,
+		VTTimeout:        *vtTimeout,
+		Verbose:          *verbose,
+		TimeFilter:       engine.NewRecentFilesFilter(time.Now(), sinceTime, *lastDays, *lastHours),
+		NoDedup:          *noDedup,
+		Incremental:      *incremental,
+		JournalPath:      *journalPath,
+		DryRun:           *dryRun,
+	}
+
+	if *reportTTL != "" {
+		task.ReportTTL, err = reporting.ParseTTL(*reportTTL)
+		if err != nil {
+			logging.ErrorLogger.Fatalf("Invalid -report-ttl: %v", err)
+		}
+	}
+	task.ColorScheme, err = reporting.ResolveColorScheme(*colorScheme, cfg.DataPaths.Config, reporting.ColorScheme{
+		Primary:  *primaryColor,
+		Critical: *criticalColor,
+		High:     *highColor,
+		Medium:   *mediumColor,
+		Low:      *lowColor,
+	})
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Invalid -color-scheme: %v", err)
+	}
+
+	// Load signing keys if requested
+	if *signKeyPath != "" {
+		task.SignKey, err = os.ReadFile(*signKeyPath)
+		if err != nil {
+			logging.ErrorLogger.Fatalf("Failed to read sign key: %v", err)
+		}
+	}
+	if *verifyKeyPath != "" {
+		if *signKeyPath == "" {
+			logging.WarnLogger.Println("-verify-key has no effect without -sign-key")
+		}
+		task.VerifyKey, err = os.ReadFile(*verifyKeyPath)
+		if err != nil {
+			logging.ErrorLogger.Fatalf("Failed to read verify key: %v", err)
+		}
+	}
+
+	if *watch && *schedule != "" {
+		logging.ErrorLogger.Fatalf("-watch cannot be combined with -schedule")
+	}
+	if *dryRun && (*watch || *schedule != "") {
+		logging.ErrorLogger.Fatalf("-dry-run cannot be combined with -watch or -schedule")
+	}
+	if *watch && *webhookURL != "" {
+		cfg.Watch.WebhookURL = *webhookURL
+	}
+	if *slackWebhook != "" {
+		cfg.Slack.WebhookURL = *slackWebhook
+	}
+	if *metricsAddr != "" {
+		if *schedule == "" && !*watch {
+			logging.WarnLogger.Println("-metrics-addr is only useful with -schedule or -watch; metrics are served until the scan finishes")
+		}
+		go func() {
+			logging.InfoLogger.Printf("Metrics listening on %s", *metricsAddr)
+			if err := metrics.Serve(*metricsAddr); err != nil {
+				logging.ErrorLogger.Printf("Metrics listener stopped: %v", err)
+			}
+		}()
+	}
+	if *scheduleCleanup != "" && *schedule == "" {
+		logging.WarnLogger.Println("-schedule-cleanup has no effect without -schedule")
+	}
+	if *profileMemDiff && !memdiff.Enabled() {
+		logging.WarnLogger.Printf("-profile-memory-diff ignored: build with -tags debugprofile or set %s=1", memdiff.EnvVar)
+		*profileMemDiff = false
+	}
+
+	// --- Scheduled Mode ---
+	if *schedule != "" {
+		opts := scheduler.Options{
+			Schedule:        *schedule,
+			Timezone:        *timezone,
+			OutputDir:       *outputDir,
+			LogPath:         *scheduleLog,
+			WebhookURL:      *webhookURL,
+			CleanupSchedule: *scheduleCleanup,
+		}
+		s, err := scheduler.New(opts, func(reportPath string) ([]*types.ScanResult, error) {
+			if *profileMemDiff {
+				defer startMemDiff()()
+			}
+			return runScheduledScan(cfg, *task, reportPath, sinceTime, *lastDays, *lastHours)
+		})
+		if err != nil {
+			logging.ErrorLogger.Fatalf("Invalid schedule: %v", err)
+		}
+		s.Run()
+		return
+	}
+
+	// --- Read stdin Targets ---
+	cleanupStdin := func() {}
+	if readStdin {
+		stdinPaths, displayPaths, err := readStdinTargets(os.Stdin)
+		cleanupStdin = func() {
+			for tmpPath := range displayPaths {
+				os.Remove(tmpPath)
+			}
+		}
+		if err != nil {
+			cleanupStdin()
+			logging.ErrorLogger.Fatalf("Failed to read stdin: %v", err)
+		}
+		task.Paths = append(task.Paths, stdinPaths...)
+		task.DisplayPaths = displayPaths
+	}
+	defer cleanupStdin()
+
+	// --- Initialize Engine ---
+	scanEngine, err := engine.NewEngine(cfg)
+	if err != nil {
+		cleanupStdin()
+		logging.ErrorLogger.Fatalf("Failed to initialize engine: %v", err)
+	}
+	if *warmup {
+		warmupStart := time.Now()
+		if err := scanEngine.Warmup(); err != nil {
+			logging.WarnLogger.Printf("Warmup incomplete: %v", err)
+		}
+		logging.InfoLogger.Printf("Warmup finished in %s", time.Since(warmupStart))
 	}
 
 	// --- Run Scan ---
+	var memReport func()
+	if *profileMemDiff {
+		memReport = startMemDiff()
+	}
+	var baseline []*types.ScanResult
+	if *watch {
+		// 监控模式继续使用同一引擎，首次扫描的结果作为风险比较的基准
+		task.KeepAlive = true
+		task.OnResults = func(results []*types.ScanResult) {
+			baseline = results
+		}
+	}
 	if err := scanEngine.Scan(task); err != nil {
+		cleanupStdin()
+		scanEngine.Close()
 		logging.ErrorLogger.Fatalf("Scan failed: %v", err)
 	}
+	if memReport != nil {
+		memReport()
+	}
 
 	logging.InfoLogger.Println("Scan completed successfully.")
+
+	// --- Watch Mode ---
+	if *watch {
+		defer scanEngine.Close()
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+		if err := scanEngine.Watch(ctx, task, baseline); err != nil {
+			cleanupStdin()
+			scanEngine.Close()
+			logging.ErrorLogger.Fatalf("Watch failed: %v", err)
+		}
+	}
+}
+
+/**
+ * @Description: 记录扫描前的内存快照，返回在扫描后调用的函数，输出对比结果到 stderr
+ * @author: Mr wpl
+ * @return func(): 扫描结束后调用
+ */
+func startMemDiff() func() {
+	profiler, err := memdiff.Start()
+	if err != nil {
+		logging.WarnLogger.Printf("Memory diff profiling unavailable: %v", err)
+		return func() {}
+	}
+	return func() {
+		if err := profiler.Report(os.Stderr); err != nil {
+			logging.WarnLogger.Printf("Failed to write memory diff: %v", err)
+		}
+	}
+}
+
+/**
+ * @Description: 执行一次定时扫描。Scan 结束时会关闭 PHP 桥接，因此每次都创建新引擎；
+ * 报告固定为 HTML，-last-days/-last-hours 按本次扫描时间重新计算
+ * @author: Mr wpl
+ * @param cfg *types.Config: 配置
+ * @param task engine.Task: 任务模板 (按值传入，不影响下次扫描)
+ * @param reportPath string: 本次报告路径
+ * @param sinceTime time.Time: -since 时间
+ * @param lastDays int: -last-days
+ * @param lastHours int: -last-hours
+ * @return []*types.ScanResult: 扫描结果
+ * @return error: 错误
+ */
+func runScheduledScan(cfg *types.Config, task engine.Task, reportPath string, sinceTime time.Time, lastDays int, lastHours int) ([]*types.ScanResult, error) {
+	scanEngine, err := engine.NewEngine(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize engine: %w", err)
+	}
+
+	var results []*types.ScanResult
+	task.ReportPath = reportPath
+	task.OutputFormat = "html"
+	task.TimeFilter = engine.NewRecentFilesFilter(time.Now(), sinceTime, lastDays, lastHours)
+	task.OnResults = func(r []*types.ScanResult) {
+		results = r
+	}
+	if err := scanEngine.Scan(&task); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// timeoutFlag 将 -analyzer-timeout/-file-timeout 转换为配置的时长，0 及负数表示不限制
+func timeoutFlag(d time.Duration) time.Duration {
+	if d <= 0 {
+		return -1
+	}
+	return d
+}
+
+// sizeFlagBytes 解析 -min-size/-max-size 的可读大小，格式无效时退出
+func sizeFlagBytes(name string, value string) int64 {
+	n, err := config.ParseSize(value)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Invalid -%s: %v", name, err)
+	}
+	return n
+}
+
+/**
+ * @Description: 读取路径列表文件，每行一个路径，去除首尾空白，跳过空行和 # 开头的注释行
+ * @author: Mr wpl
+ * @param name string: 文件路径，"-" 表示标准输入
+ * @return []string: 路径列表
+ * @return error: 错误
+ */
+func readPathFile(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return paths, nil
+}
+
+// stdinDelimiter 标准输入中分隔多个 PHP 文件的行
+const stdinDelimiter = "---"
+
+/**
+ * @Description: 读取标准输入中的 PHP 代码，按单独一行的 --- 拆分为多个文件，分别写入临时文件。
+ * 只有一个输入时显示为 <stdin>，多个时依次显示为 <stdin:1>、<stdin:2>…，只含空白的部分被跳过
+ * @author: Mr wpl
+ * @param r io.Reader: 标准输入
+ * @return []string: 临时文件路径
+ * @return map[string]string: 临时文件路径 → 显示名称，调用方负责删除其中的临时文件
+ * @return error: 错误 (已创建的临时文件仍在返回的 map 中)
+ */
+func readStdinTargets(r io.Reader) ([]string, map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var parts []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.TrimRight(line, "\r\n") == stdinDelimiter {
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteString(line)
+	}
+	parts = append(parts, current.String())
+
+	var contents []string
+	for _, part := range parts {
+		if strings.TrimSpace(part) != "" {
+			contents = append(contents, part)
+		}
+	}
+	if len(contents) == 0 {
+		return nil, nil, fmt.Errorf("no PHP code on stdin")
+	}
+
+	paths := make([]string, 0, len(contents))
+	displayPaths := make(map[string]string, len(contents))
+	for i, content := range contents {
+		// 扩展名需为 .php，否则会被文件遍历跳过
+		f, err := os.CreateTemp("", "shieldml_stdin_*.php")
+		if err != nil {
+			return paths, displayPaths, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		displayName := "<stdin>"
+		if len(contents) > 1 {
+			displayName = fmt.Sprintf("<stdin:%d>", i+1)
+		}
+		displayPaths[f.Name()] = displayName
+		paths = append(paths, f.Name())
+
+		_, err = f.WriteString(content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, displayPaths, fmt.Errorf("failed to write temp file %s: %w", f.Name(), err)
+		}
+	}
+	return paths, displayPaths, nil
+}
+
+// printHistoryReport 将扫描历史中风险上升的文件以 JSON 写到 stdout
+func printHistoryReport(dbPath string) error {
+	logging.RedirectToStderr()
+	history, err := state.OpenHistory(dbPath)
+	if err != nil {
+		return err
+	}
+	defer history.Close()
+	report, err := history.RiskIncreaseReport()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// exportExcelReport 将 JSON 报告转换为 .xlsx 文件
+func exportExcelReport(jsonPath string, xlsxPath string) error {
+	report, scanTime, err := reporting.ReadJSONReport(jsonPath)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(xlsxPath)
+	if err != nil {
+		return err
+	}
+	if err := reporting.WriteExcel(out, report, scanTime); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
 }
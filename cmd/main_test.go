@@ -0,0 +1,180 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 命令行参数辅助函数测试
+ */
+package main
+
+import (
+	"bt-shieldml/internal/config"
+	"bt-shieldml/internal/engine"
+	"bt-shieldml/pkg/types"
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePathFile 在临时目录中写入路径列表文件
+func writePathFile(t *testing.T, content string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "paths.txt")
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestReadPathFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "comments and blank lines",
+			content: "# changed files\n/var/www/a.php\n\n   \n# /var/www/skipped.php\n/var/www/b.php\n",
+			want:    []string{"/var/www/a.php", "/var/www/b.php"},
+		},
+		{
+			name:    "surrounding whitespace trimmed",
+			content: "  /var/www/a.php  \n\t/var/www/dir with spaces/b.php\t\n",
+			want:    []string{"/var/www/a.php", "/var/www/dir with spaces/b.php"},
+		},
+		{
+			name:    "indented comment",
+			content: "   # comment\n/var/www/a.php",
+			want:    []string{"/var/www/a.php"},
+		},
+		{
+			name:    "CRLF line endings",
+			content: "/var/www/a.php\r\n# comment\r\n/var/www/b.php\r\n",
+			want:    []string{"/var/www/a.php", "/var/www/b.php"},
+		},
+		{
+			name:    "only comments",
+			content: "# nothing changed\n\n",
+			want:    nil,
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPathFile(writePathFile(t, tt.content))
+			if err != nil {
+				t.Fatalf("readPathFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readPathFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPathFileMissing(t *testing.T) {
+	if _, err := readPathFile(filepath.Join(t.TempDir(), "missing.txt")); !os.IsNotExist(err) {
+		t.Errorf("readPathFile() error = %v, want a not-exist error", err)
+	}
+}
+
+// TestPathFileEntriesAreScanned 路径列表文件中 5 个非注释路径 (文件与目录) 都被扫描，注释掉的路径不被扫描
+func TestPathFileEntriesAreScanned(t *testing.T) {
+	root := t.TempDir()
+	var want []string
+	for _, name := range []string{"a.php", "b.php", "dir with spaces/c.php", "d.php", "nested/e.php", "skipped.php"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<?php echo 1;"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if name != "skipped.php" {
+			want = append(want, path)
+		}
+	}
+	pathFile := writePathFile(t, strings.Join([]string{
+		"# files changed in this deploy",
+		filepath.Join(root, "a.php"),
+		"  " + filepath.Join(root, "b.php") + "  ",
+		"",
+		filepath.Join(root, "dir with spaces", "c.php"),
+		"# " + filepath.Join(root, "skipped.php"),
+		filepath.Join(root, "d.php"),
+		filepath.Join(root, "nested"),
+	}, "\n"))
+
+	paths, err := readPathFile(pathFile)
+	if err != nil {
+		t.Fatalf("readPathFile() error = %v", err)
+	}
+	if len(paths) != 5 {
+		t.Fatalf("readPathFile() returned %d paths, want 5: %q", len(paths), paths)
+	}
+
+	cfg := config.GetDefaultConfig()
+	cfg.EnabledAnalyzers = []string{"entropy_string"} // 不需要 PHP 桥接与模型
+	e, err := engine.NewEngine(cfg)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	report := filepath.Join(t.TempDir(), "results.ndjson")
+	if err := e.Scan(&engine.Task{Paths: paths, ReportPath: report, NoDedup: true}); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	f, err := os.Open(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var scanned []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line struct {
+			File struct {
+				Path string `json:"path"`
+			} `json:"file"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid report line %q: %v", scanner.Text(), err)
+		}
+		scanned = append(scanned, line.File.Path)
+	}
+	sort.Strings(scanned)
+	sort.Strings(want)
+	if !reflect.DeepEqual(scanned, want) {
+		t.Errorf("scanned %q, want %q", scanned, want)
+	}
+}
+
+// TestTimeoutFlag 超时参数按原时长写入配置，不取整到秒
+func TestTimeoutFlag(t *testing.T) {
+	tests := []struct {
+		flag time.Duration
+		want time.Duration
+	}{
+		{500 * time.Millisecond, 500 * time.Millisecond},
+		{90 * time.Second, 90 * time.Second},
+		{0, -1},
+		{-time.Second, -1},
+	}
+	for _, tt := range tests {
+		if got := timeoutFlag(tt.flag); got != tt.want {
+			t.Errorf("timeoutFlag(%s) = %s, want %s", tt.flag, got, tt.want)
+		}
+		perf := types.Performance{AnalyzerTimeout: timeoutFlag(tt.flag)}
+		if wait := perf.AnalyzerWait(); tt.flag > 0 && wait != tt.flag || tt.flag <= 0 && wait != 0 {
+			t.Errorf("AnalyzerWait() for -analyzer-timeout %s = %s", tt.flag, wait)
+		}
+	}
+}
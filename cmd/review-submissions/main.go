@@ -0,0 +1,262 @@
+/*
+ * @Date: 2025-06-16 11:32:17
+ * @Editors: Mr wpl
+ * @Description: 审核 /api/submit-pattern 收到的规则提交：批准的 regex 追加到 custom_rules.re，yara 追加到 Webshells_rules.yar
+ */
+package main
+
+import (
+	"bt-shieldml/internal/analyzers/static"
+	"bt-shieldml/internal/submissions"
+	"bt-shieldml/pkg/logging"
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// yaraRuleFile 内置 YARA 规则文件名 (位于 signatures 目录下)
+const yaraRuleFile = "Webshells_rules.yar"
+
+func main() {
+	submissionsPath := flag.String("submissions", submissions.DefaultPath, "Path to the submissions jsonl file")
+	decisionsPath := flag.String("decisions", submissions.DefaultDecisionPath, "Path to the decision log (jsonl)")
+	signaturesDir := flag.String("signatures", "data/signatures", "Directory holding custom_rules.re and Webshells_rules.yar")
+	reviewer := flag.String("reviewer", defaultReviewer(), "Reviewer name recorded with each decision")
+	list := flag.Bool("list", false, "Only list pending submissions")
+	approveID := flag.String("approve", "", "Approve the submission with this ID without prompting")
+	rejectID := flag.String("reject", "", "Reject the submission with this ID without prompting")
+	reason := flag.String("reason", "", "Reason recorded with -approve/-reject")
+	flag.Parse()
+
+	subs, err := submissions.ReadAll(*submissionsPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read submissions: %v", err)
+	}
+	decisions, err := submissions.ReadDecisions(*decisionsPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read decisions: %v", err)
+	}
+	pending := submissions.Pending(subs, decisions)
+
+	r := &reviewerSession{
+		decisionsPath: *decisionsPath,
+		signaturesDir: *signaturesDir,
+		reviewer:      *reviewer,
+	}
+
+	if *approveID != "" || *rejectID != "" {
+		id, decision := *approveID, submissions.DecisionApproved
+		if *rejectID != "" {
+			id, decision = *rejectID, submissions.DecisionRejected
+		}
+		sub := findSubmission(pending, id)
+		if sub == nil {
+			logging.ErrorLogger.Fatalf("No pending submission with ID %s", id)
+		}
+		if err := r.decide(*sub, decision, *reason); err != nil {
+			logging.ErrorLogger.Fatalf("Failed to %s %s: %v", strings.TrimSuffix(decision, "d"), id, err)
+		}
+		return
+	}
+
+	if len(pending) == 0 {
+		fmt.Println("No pending submissions.")
+		return
+	}
+	fmt.Printf("%d pending submissions\n", len(pending))
+	if *list {
+		for _, sub := range pending {
+			printSubmission(sub)
+		}
+		return
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for _, sub := range pending {
+		printSubmission(sub)
+		// 审核时重新试编译，提交之后规则环境可能已变化
+		if err := submissions.TestCompile(sub.Type, sub.Pattern); err != nil {
+			fmt.Printf("  compile:     FAILED (%v)\n", err)
+		} else {
+			fmt.Println("  compile:     ok")
+		}
+
+		answer, ok := prompt(in, "[a]pprove, [r]eject, [s]kip, [q]uit? ")
+		if !ok {
+			return
+		}
+		switch strings.ToLower(answer) {
+		case "a", "approve":
+			reason, _ := prompt(in, "Reason (optional): ")
+			if err := r.decide(sub, submissions.DecisionApproved, reason); err != nil {
+				fmt.Printf("[ERROR] %v\n", err)
+			}
+		case "r", "reject":
+			reason, _ := prompt(in, "Reason (optional): ")
+			if err := r.decide(sub, submissions.DecisionRejected, reason); err != nil {
+				fmt.Printf("[ERROR] %v\n", err)
+			}
+		case "q", "quit":
+			return
+		default:
+			fmt.Println("Skipped.")
+		}
+	}
+}
+
+// reviewerSession 审核所需的路径和审核人
+type reviewerSession struct {
+	decisionsPath string
+	signaturesDir string
+	reviewer      string
+}
+
+/**
+ * @Description: 记录审核决定，批准时先把规则追加到规则文件，写入失败则不记录决定
+ * @author: Mr wpl
+ * @param sub submissions.Submission: 提交
+ * @param decision string: approved 或 rejected
+ * @param reason string: 原因
+ * @return error: 错误
+ */
+func (r *reviewerSession) decide(sub submissions.Submission, decision string, reason string) error {
+	if decision == submissions.DecisionApproved {
+		target, err := r.install(sub)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[APPROVED] %s appended to %s\n", sub.ID, target)
+		if sub.Type == submissions.TypeYara {
+			fmt.Println("           YARA rules are embedded at build time; rebuild with build.sh to activate.")
+		}
+	} else {
+		fmt.Printf("[REJECTED] %s\n", sub.ID)
+	}
+
+	d := submissions.Decision{
+		ID:        sub.ID,
+		Decision:  decision,
+		Reviewer:  r.reviewer,
+		Reason:    strings.TrimSpace(reason),
+		Timestamp: time.Now(),
+	}
+	if err := submissions.AppendDecision(r.decisionsPath, d); err != nil {
+		return err
+	}
+	logging.InfoLogger.Printf("Submission %s (%s from %s) %s by %s", sub.ID, sub.Type, sub.Submitter, decision, r.reviewer)
+	return nil
+}
+
+/**
+ * @Description: 将规则追加到对应的规则文件。YARA 规则与现有规则文件一起试编译，避免规则名冲突破坏整个规则库
+ * @author: Mr wpl
+ * @param sub submissions.Submission: 提交
+ * @return string: 规则文件路径
+ * @return error: 错误
+ */
+func (r *reviewerSession) install(sub submissions.Submission) (string, error) {
+	header := fmt.Sprintf("submission %s by %s: %s", sub.ID, orUnknown(sub.Submitter), oneLine(sub.Description))
+
+	switch sub.Type {
+	case submissions.TypeRegex:
+		if err := submissions.TestCompile(sub.Type, sub.Pattern); err != nil {
+			return "", fmt.Errorf("regex does not compile: %w", err)
+		}
+		target := filepath.Join(r.signaturesDir, static.CustomRegexFile)
+		return target, appendToFile(target, "# "+header+"\n"+strings.TrimSpace(sub.Pattern)+"\n")
+	case submissions.TypeYara:
+		target := filepath.Join(r.signaturesDir, yaraRuleFile)
+		existing, err := os.ReadFile(target)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read %s: %w", target, err)
+		}
+		if err := submissions.TestCompile(sub.Type, string(existing)+"\n"+sub.Pattern); err != nil {
+			return "", fmt.Errorf("rule does not compile together with %s: %w", target, err)
+		}
+		return target, appendToFile(target, "\n// "+header+"\n"+strings.TrimSpace(sub.Pattern)+"\n")
+	}
+	return "", fmt.Errorf("unknown rule type %q", sub.Type)
+}
+
+// appendToFile 追加内容到文件，不存在时创建
+func appendToFile(path string, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printSubmission 显示一条提交
+func printSubmission(sub submissions.Submission) {
+	fmt.Println(strings.Repeat("-", 60))
+	fmt.Printf("  id:          %s\n", sub.ID)
+	fmt.Printf("  type:        %s\n", sub.Type)
+	fmt.Printf("  submitter:   %s\n", orUnknown(sub.Submitter))
+	fmt.Printf("  submitted:   %s\n", sub.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("  description: %s\n", sub.Description)
+	fmt.Printf("  pattern:\n%s\n", indent(sub.Pattern))
+}
+
+// findSubmission 按 ID 查找
+func findSubmission(subs []submissions.Submission, id string) *submissions.Submission {
+	for i := range subs {
+		if subs[i].ID == id {
+			return &subs[i]
+		}
+	}
+	return nil
+}
+
+// prompt 读取一行输入 (去除首尾空白)，输入结束时 ok 为 false
+func prompt(in *bufio.Reader, question string) (string, bool) {
+	fmt.Print(question)
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// defaultReviewer 默认审核人为当前系统用户
+func defaultReviewer() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// orUnknown 空字符串显示为 unknown
+func orUnknown(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// oneLine 合并为单行，用于规则文件中的注释
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// indent 每行缩进 4 个空格
+func indent(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i := range lines {
+		lines[i] = "    " + lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
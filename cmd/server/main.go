@@ -0,0 +1,360 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: REST API 与 gRPC 服务：常驻进程，引擎只初始化一次，部署流水线通过 HTTP 发起扫描而无需每次启动二进制
+ */
+package main
+
+import (
+	"bt-shieldml/internal/config"
+	"bt-shieldml/internal/engine"
+	grpcapi "bt-shieldml/internal/grpc"
+	"bt-shieldml/internal/metrics"
+	"bt-shieldml/internal/reporting"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	grpclib "google.golang.org/grpc"
+)
+
+// maxRequestBody 扫描请求体的大小上限
+const maxRequestBody = 1 << 20
+
+// scanRequest POST /api/v1/scan 的请求体
+type scanRequest struct {
+	Paths      []string `json:"paths"`
+	Exclusions []string `json:"exclusions"`
+	Format     string   `json:"format"` // 目前只支持 json (默认)
+}
+
+// errorResponse 错误响应
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// healthResponse GET /api/v1/health 的响应
+type healthResponse struct {
+	Status        string        `json:"status"` // ok，PHP 桥接不可用时为 degraded
+	Engine        engine.Status `json:"engine"`
+	ActiveScans   int64         `json:"active_scans"`
+	UptimeSeconds int64         `json:"uptime_seconds"`
+}
+
+// reloadResponse POST /api/v1/reload 的响应
+type reloadResponse struct {
+	Status    string                `json:"status"` // ok，任一分析器失败时为 failed (失败的分析器继续使用旧规则)
+	Analyzers []engine.ReloadResult `json:"analyzers"`
+}
+
+// server REST API 服务状态
+type server struct {
+	engine  *engine.Engine
+	metrics *apiMetrics
+	started time.Time
+}
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "Path to configuration file")
+	listen := flag.String("listen", "127.0.0.1:8090", "Address to listen on (host:port); the API has no authentication, so keep it on localhost or behind a reverse proxy")
+	metricsAddr := flag.String("metrics-addr", "", "Also serve GET /metrics alone on this address (host:port), e.g. to let Prometheus scrape from outside while the scan API stays internal")
+	grpcAddr := flag.String("grpc-addr", grpcapi.DefaultAddr, "Address for the gRPC API (api/proto/shield.proto: streaming Scan, GetStatus, reflection for grpcurl); empty disables it. Addresses other than loopback require -grpc-cert")
+	grpcCert := flag.String("grpc-cert", "", "PEM server certificate for the gRPC API; without it gRPC is plaintext")
+	grpcKey := flag.String("grpc-key", "", "PEM private key for -grpc-cert")
+	grpcClientCA := flag.String("grpc-client-ca", "", "PEM CA bundle; when set, gRPC clients must present a certificate signed by it (mutual TLS)")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(*configPath)
+	if cfg == nil {
+		logging.ErrorLogger.Fatalf("Failed to load configuration: %v", err)
+	}
+	scanEngine, err := engine.NewEngine(cfg)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to initialize engine: %v", err)
+	}
+	defer scanEngine.Close()
+	// 常驻进程启动时预热，第一个扫描请求不再承担初始化耗时
+	if err := scanEngine.Warmup(); err != nil {
+		logging.WarnLogger.Printf("Warmup incomplete: %v", err)
+	}
+
+	s := &server{engine: scanEngine, metrics: newAPIMetrics(scanEngine), started: time.Now()}
+	if *metricsAddr != "" {
+		go func() {
+			logging.InfoLogger.Printf("Metrics listening on %s", *metricsAddr)
+			if err := metrics.Serve(*metricsAddr); err != nil {
+				logging.ErrorLogger.Printf("Metrics listener stopped: %v", err)
+			}
+		}()
+	}
+	var grpcServer *grpclib.Server
+	grpcStopped := make(chan struct{})
+	if *grpcAddr != "" {
+		tlsCfg := grpcapi.TLSConfig{CertFile: *grpcCert, KeyFile: *grpcKey, ClientCAFile: *grpcClientCA}
+		if err := grpcapi.CheckListenAddr(*grpcAddr, tlsCfg); err != nil {
+			scanEngine.Close()
+			logging.ErrorLogger.Fatalf("%v", err)
+		}
+		grpcServer, err = grpcapi.NewGRPCServer(grpcapi.NewServer(scanEngine), tlsCfg)
+		if err != nil {
+			scanEngine.Close()
+			logging.ErrorLogger.Fatalf("Failed to initialize gRPC API: %v", err)
+		}
+		if *grpcCert == "" {
+			logging.WarnLogger.Printf("gRPC API on %s is plaintext and unauthenticated; any local process can scan server paths", *grpcAddr)
+		}
+		go func() {
+			logging.InfoLogger.Printf("gRPC API listening on %s", *grpcAddr)
+			if err := grpcapi.Serve(grpcServer, *grpcAddr); err != nil {
+				logging.ErrorLogger.Printf("gRPC API stopped: %v", err)
+			}
+		}()
+	}
+	httpServer := &http.Server{
+		Addr:              *listen,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// SIGHUP 时重新加载分析器规则 (如新推送的 YARA 签名)，不中断服务
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGHUP)
+	defer signal.Stop(reloadSignal)
+	go func() {
+		for range reloadSignal {
+			logging.InfoLogger.Println("SIGHUP received, reloading analyzer rules")
+			if _, err := scanEngine.ReloadAnalyzers(); err == nil {
+				logging.InfoLogger.Println("Analyzer rules reloaded")
+			}
+		}
+	}()
+
+	// SIGTERM/SIGINT 时停止接收新请求，等待进行中的扫描返回 (扫描自身收到 SIGTERM 后输出部分结果)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Performance.ShutdownWait()+5*time.Second)
+		defer cancel()
+		if grpcServer != nil {
+			go func() {
+				grpcServer.GracefulStop()
+				close(grpcStopped)
+			}()
+		}
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logging.WarnLogger.Printf("HTTP server shutdown: %v", err)
+		}
+		// GracefulStop 等待进行中的流式扫描结束，超时后强制关闭
+		if grpcServer != nil {
+			select {
+			case <-grpcStopped:
+			case <-shutdownCtx.Done():
+				grpcServer.Stop()
+				<-grpcStopped
+			}
+		}
+	}()
+
+	logging.InfoLogger.Printf("API server listening on %s", *listen)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		scanEngine.Close()
+		logging.ErrorLogger.Fatalf("API server stopped: %v", err)
+	}
+	if grpcServer != nil {
+		<-grpcStopped
+	}
+	logging.InfoLogger.Println("API server stopped")
+}
+
+// routes 注册 API 路由
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/scan", s.handleScan)
+	mux.HandleFunc("/api/v1/health", s.handleHealth)
+	mux.HandleFunc("/api/v1/reload", s.handleReload)
+	mux.HandleFunc("/api/v1/export/excel", s.handleExportExcel)
+	mux.HandleFunc("/api/v1/metrics", s.handleMetrics)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+/**
+ * @Description: POST /api/v1/scan，扫描请求中的路径，返回与 -output report.json 相同格式的 JSON 报告，
+ * 另附 error_files (扫描出错的文件数，这些文件不在 results 中) 与 duration_ms
+ * @author: Mr wpl
+ * @param w http.ResponseWriter: 响应
+ * @param r *http.Request: 请求
+ */
+func (s *server) handleScan(w http.ResponseWriter, r *http.Request) {
+	results, duration, ok := s.scan(w, r)
+	if !ok {
+		return
+	}
+
+	report := reporting.NewJsonReporter().Build(results)
+	errorFiles := 0
+	for _, res := range results {
+		if res.Error != nil {
+			errorFiles++
+		}
+	}
+	report["error_files"] = errorFiles
+	report["duration_ms"] = duration.Milliseconds()
+	writeJSON(w, http.StatusOK, report)
+}
+
+/**
+ * @Description: 解析扫描请求并执行扫描，请求无效或扫描失败时写出错误响应
+ * @author: Mr wpl
+ * @param w http.ResponseWriter: 响应
+ * @param r *http.Request: 请求，须为 POST，请求体为 scanRequest
+ * @return []*types.ScanResult: 扫描结果
+ * @return time.Duration: 扫描耗时
+ * @return bool: 为 false 时已写出错误响应
+ */
+func (s *server) scan(w http.ResponseWriter, r *http.Request) ([]*types.ScanResult, time.Duration, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return nil, 0, false
+	}
+
+	var req scanRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request body: %v", err)})
+		return nil, 0, false
+	}
+	if err := req.validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return nil, 0, false
+	}
+
+	s.metrics.scanStarted()
+	start := time.Now()
+	results, err := s.engine.ScanPaths(&engine.Task{Paths: req.Paths, Exclusions: req.Exclusions})
+	duration := time.Since(start)
+	s.metrics.scanFinished(err)
+	if err != nil {
+		logging.ErrorLogger.Printf("API scan of %v failed: %v", req.Paths, err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return nil, 0, false
+	}
+	return results, duration, true
+}
+
+/**
+ * @Description: POST /api/v1/export/excel，请求体与 /api/v1/scan 相同，扫描后返回 .xlsx 文件
+ * (Summary、Results、Findings 三个工作表，内容与 -export-excel 由 JSON 报告生成的相同)
+ * @author: Mr wpl
+ * @param w http.ResponseWriter: 响应
+ * @param r *http.Request: 请求
+ */
+func (s *server) handleExportExcel(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	results, _, ok := s.scan(w, r)
+	if !ok {
+		return
+	}
+
+	report := &reporting.JSONReport{Results: reporting.NewJsonReporter().Build(results)["results"].([]reporting.SimpleResult)}
+	var buf bytes.Buffer
+	if err := reporting.WriteExcel(&buf, report, start); err != nil {
+		logging.ErrorLogger.Printf("Failed to generate Excel export: %v", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", reporting.ExcelContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=scan_report.xlsx")
+	w.Write(buf.Bytes())
+}
+
+// validate 检查扫描请求：至少一个路径，路径必须存在，格式只能是 json
+func (req *scanRequest) validate() error {
+	if format := strings.ToLower(req.Format); format != "" && format != "json" {
+		return fmt.Errorf("unsupported format %q (only json is supported)", req.Format)
+	}
+	paths := req.Paths[:0]
+	for _, p := range req.Paths {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("paths is required")
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			return fmt.Errorf("invalid path %q: %w", p, err)
+		}
+	}
+	req.Paths = paths
+	return nil
+}
+
+// handleHealth GET /api/v1/health，返回引擎状态
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	status := s.engine.Status()
+	health := healthResponse{
+		Status:        "ok",
+		Engine:        status,
+		ActiveScans:   s.metrics.activeScans.Load(),
+		UptimeSeconds: int64(time.Since(s.started).Seconds()),
+	}
+	if status.ASTBridge != "ok" && status.ASTBridge != "disabled" {
+		health.Status = "degraded"
+	}
+	writeJSON(w, http.StatusOK, health)
+}
+
+// handleReload POST /api/v1/reload，重新加载分析器规则 (与发送 SIGHUP 相同)，任一分析器失败时返回 500
+func (s *server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	results, err := s.engine.ReloadAnalyzers()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, reloadResponse{Status: "failed", Analyzers: results})
+		return
+	}
+	writeJSON(w, http.StatusOK, reloadResponse{Status: "ok", Analyzers: results})
+}
+
+// handleMetrics GET /metrics (及 /api/v1/metrics)，返回 Prometheus 指标
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	metrics.Handler().ServeHTTP(w, r)
+}
+
+// writeJSON 以 JSON 输出响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.WarnLogger.Printf("Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: REST API 服务的请求与并发名额指标，注册到 metrics.Registry，与引擎记录的扫描指标一同输出
+ */
+package main
+
+import (
+	"bt-shieldml/internal/engine"
+	"bt-shieldml/internal/metrics"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// apiMetrics 扫描请求计数
+type apiMetrics struct {
+	activeScans atomic.Int64 // 进行中的扫描请求，供健康检查使用
+	requests    *prometheus.CounterVec
+}
+
+/**
+ * @Description: 创建请求指标并注册到 metrics.Registry，并发名额的使用情况在抓取时从引擎读取
+ * @author: Mr wpl
+ * @param scanEngine *engine.Engine: 引擎
+ * @return *apiMetrics: 请求指标
+ */
+func newAPIMetrics(scanEngine *engine.Engine) *apiMetrics {
+	m := &apiMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bt_shieldml_scan_requests_total",
+			Help: "Scan requests handled by the API server, by outcome.",
+		}, []string{"status"}),
+	}
+	m.requests.WithLabelValues("ok")
+	m.requests.WithLabelValues("error")
+	metrics.Registry.MustRegister(
+		m.requests,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "bt_shieldml_workers",
+			Help: "File scan worker pool size (performance.concurrency).",
+		}, func() float64 {
+			_, total := scanEngine.Workers()
+			return float64(total)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "bt_shieldml_busy_workers",
+			Help: "File scan workers in use, shared by all scan requests.",
+		}, func() float64 {
+			busy, _ := scanEngine.Workers()
+			return float64(busy)
+		}),
+	)
+	return m
+}
+
+// scanStarted 记录一个开始的扫描请求
+func (m *apiMetrics) scanStarted() {
+	m.activeScans.Add(1)
+}
+
+// scanFinished 记录一个结束的扫描请求，文件级指标由引擎记录
+func (m *apiMetrics) scanFinished(err error) {
+	m.activeScans.Add(-1)
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.requests.WithLabelValues(status).Inc()
+}
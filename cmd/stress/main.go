@@ -0,0 +1,245 @@
+/*
+ * @Date: 2025-06-03 15:12:07
+ * @Editors: Mr wpl
+ * @Description: PHP 桥接压力测试工具，测量并发 GetAST 的吞吐量、延迟、错误率和内存占用
+ */
+package main
+
+import (
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/pkg/logging"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// fixture 一个测试文件
+type fixture struct {
+	content []byte
+}
+
+// sample 一次请求的测量结果
+type sample struct {
+	size    int
+	latency time.Duration
+	err     error
+}
+
+// sizeBuckets 按文件大小分组统计，upper 为上限 (不含)
+var sizeBuckets = []struct {
+	name  string
+	upper int
+}{
+	{"< 4KB", 4 << 10},
+	{"4KB - 64KB", 64 << 10},
+	{"64KB - 1MB", 1 << 20},
+	{">= 1MB", int(^uint(0) >> 1)},
+}
+
+func main() {
+	fixtureDir := flag.String("fixtures", "samples", "Directory containing PHP fixture files of varying sizes")
+	requests := flag.Int("requests", 500, "Total number of GetAST requests to send")
+	workers := flag.Int("workers", 8, "Number of concurrent goroutines sending requests")
+	transport := flag.String("transport", ast.TransportPipe, "PHP bridge transport: pipe or shmem")
+	flag.Parse()
+
+	if *requests <= 0 || *workers <= 0 {
+		logging.ErrorLogger.Fatalf("-requests and -workers must be positive")
+	}
+
+	fixtures, err := loadFixtures(*fixtureDir)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to load fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		logging.ErrorLogger.Fatalf("No .php fixtures found in %s", *fixtureDir)
+	}
+
+	astMgr, err := ast.NewPhpAstManagerWithTransport(*transport)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to start PHP AST bridge: %v", err)
+	}
+	defer astMgr.Cleanup()
+
+	fmt.Printf("Sending %d requests with %d workers over %s transport (%d fixtures)\n",
+		*requests, *workers, *transport, len(fixtures))
+
+	// 后台采样内存，记录测试期间的峰值
+	var peakHeap, peakSys uint64
+	stopSampler := make(chan struct{})
+	samplerDone := make(chan struct{})
+	go func() {
+		defer close(samplerDone)
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		var ms runtime.MemStats
+		for {
+			runtime.ReadMemStats(&ms)
+			if ms.HeapAlloc > peakHeap {
+				peakHeap = ms.HeapAlloc
+			}
+			if ms.Sys > peakSys {
+				peakSys = ms.Sys
+			}
+			select {
+			case <-ticker.C:
+			case <-stopSampler:
+				return
+			}
+		}
+	}()
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	samples := make([]sample, *requests)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fx := fixtures[i%len(fixtures)]
+				reqStart := time.Now()
+				_, err := astMgr.GetAST(fx.content)
+				samples[i] = sample{size: len(fx.content), latency: time.Since(reqStart), err: err}
+			}
+		}()
+	}
+	for i := 0; i < *requests; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	close(stopSampler)
+	<-samplerDone
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+
+	printSummary(samples, elapsed)
+	fmt.Printf("\nMemory: peak heap %s, peak sys %s, allocated during test %s, GC cycles %d\n",
+		formatBytes(peakHeap), formatBytes(peakSys), formatBytes(after.TotalAlloc-before.TotalAlloc), after.NumGC-before.NumGC)
+}
+
+/**
+ * @Description: 读取目录下所有 .php 文件作为测试数据
+ * @author: Mr wpl
+ * @param dir string: 目录
+ * @return []fixture: 测试文件
+ * @return error: 错误
+ */
+func loadFixtures(dir string) ([]fixture, error) {
+	var fixtures []fixture
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".php") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if len(content) > 0 {
+			fixtures = append(fixtures, fixture{content: content})
+		}
+		return nil
+	})
+	return fixtures, err
+}
+
+/**
+ * @Description: 输出总体和按文件大小分组的吞吐量、延迟与错误率
+ * @author: Mr wpl
+ * @param samples []sample: 测量结果
+ * @param elapsed time.Duration: 总耗时
+ */
+func printSummary(samples []sample, elapsed time.Duration) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Files\tRequests\tErrors\tError rate\tMedian\tP99\tMax\t")
+
+	writeRow := func(name string, group []sample) {
+		if len(group) == 0 {
+			return
+		}
+		latencies := make([]time.Duration, 0, len(group))
+		errors := 0
+		for _, s := range group {
+			if s.err != nil {
+				errors++
+			}
+			latencies = append(latencies, s.latency)
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\t%s\t%s\t%s\t\n", name, len(group), errors,
+			float64(errors)*100/float64(len(group)), percentile(latencies, 0.50), percentile(latencies, 0.99),
+			latencies[len(latencies)-1])
+	}
+
+	lower := 0
+	for _, bucket := range sizeBuckets {
+		var group []sample
+		for _, s := range samples {
+			if s.size >= lower && s.size < bucket.upper {
+				group = append(group, s)
+			}
+		}
+		writeRow(bucket.name, group)
+		lower = bucket.upper
+	}
+	writeRow("all", samples)
+	tw.Flush()
+
+	fmt.Printf("\nThroughput: %.1f requests/s (%d requests in %s)\n",
+		float64(len(samples))/elapsed.Seconds(), len(samples), elapsed.Round(time.Millisecond))
+}
+
+/**
+ * @Description: 计算已排序延迟的百分位 (最近秩法)
+ * @author: Mr wpl
+ * @param sorted []time.Duration: 升序排列的延迟
+ * @param p float64: 百分位 (0-1)
+ * @return time.Duration: 延迟
+ */
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+/**
+ * @Description: 格式化字节数
+ * @author: Mr wpl
+ * @param n uint64: 字节数
+ * @return string: 如 "12.3 MB"
+ */
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 从带标签的样本目录重新训练 Bayes Words 模型 (Words.model)，可留出部分样本输出精确率、召回率与 F1
+ */
+package main
+
+import (
+	"bt-shieldml/internal/analyzers/ml"
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/internal/training"
+	"bt-shieldml/pkg/logging"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	samplesDir := flag.String("samples-dir", "", "Directory with webshell/ and normal/ subdirectories of labelled PHP files (required)")
+	outputPath := flag.String("output", "data/models/"+ml.BayesWordsModelFile, "Path to write the trained model (same JSON format as the embedded Words.model)")
+	testSplit := flag.Float64("test-split", 0, "Fraction of each class held out from training and used to report precision/recall/F1 (e.g. 0.2)")
+	seed := flag.Int64("seed", 1, "Random seed for the train/test split")
+	flag.Parse()
+
+	if *samplesDir == "" {
+		logging.ErrorLogger.Println("Error: -samples-dir argument is required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *testSplit < 0 || *testSplit >= 1 {
+		logging.ErrorLogger.Fatalf("-test-split must be in [0, 1)")
+	}
+
+	astMgr, err := ast.NewPhpAstManager()
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to start PHP AST bridge: %v", err)
+	}
+	samples, err := training.LoadBayesSamples(*samplesDir, astMgr)
+	astMgr.Cleanup()
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read samples: %v", err)
+	}
+
+	train, test := training.SplitBayesSamples(samples, *testSplit, *seed)
+	model, err := training.TrainBayes(train)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to train model: %v", err)
+	}
+	if err := model.Save(*outputPath); err != nil {
+		logging.ErrorLogger.Fatalf("Failed to save model: %v", err)
+	}
+	fmt.Printf("Trained on %d samples (%d held out): vocabulary %d words, saved to %s\n", len(train), len(test), model.Vocabulary(), *outputPath)
+
+	if len(test) > 0 {
+		m := training.EvaluateBayes(model, test)
+		fmt.Printf("Held-out evaluation (%d samples, webshell = positive): precision %.4f, recall %.4f, F1 %.4f (TP %d, FP %d, FN %d, TN %d)\n",
+			m.Samples, m.Precision, m.Recall, m.F1, m.TruePositives, m.FalsePositives, m.FalseNegatives, m.TrueNegatives)
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 从正常代码与 webshell 样本目录训练字符 4-gram 模型 (Ngram.model)，并在训练样本上校准阈值
+ */
+package main
+
+import (
+	"bt-shieldml/internal/analyzers/ml"
+	"bt-shieldml/pkg/logging"
+	"flag"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// sample 一个训练样本的内容与标签
+type sample struct {
+	content  []byte
+	webshell bool
+}
+
+func main() {
+	normalDir := flag.String("normal", "", "Directory of normal (benign) source files (required)")
+	webshellDir := flag.String("webshell", "", "Directory of webshell samples, ideally obfuscated ones (required)")
+	outputPath := flag.String("output", "data/models/"+ml.NgramModelFile, "Path to write the trained model")
+	extensions := flag.String("ext", ".php", "Comma-separated file extensions to read from both directories")
+	minCount := flag.Int("min-count", 5, "Drop 4-grams seen fewer than this many times in both classes together")
+	threshold := flag.Float64("threshold", 0, "Mean log-likelihood ratio threshold stored in the model (0 = calibrate for best accuracy on the training files)")
+	flag.Parse()
+
+	if *normalDir == "" || *webshellDir == "" {
+		logging.ErrorLogger.Println("Error: -normal and -webshell arguments are required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	exts := strings.Split(strings.ToLower(*extensions), ",")
+	normal, err := readSamples(*normalDir, exts, false)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read normal samples: %v", err)
+	}
+	webshell, err := readSamples(*webshellDir, exts, true)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read webshell samples: %v", err)
+	}
+	if len(normal) == 0 || len(webshell) == 0 {
+		logging.ErrorLogger.Fatalf("Need samples of both classes (normal: %d, webshell: %d)", len(normal), len(webshell))
+	}
+
+	model := train(normal, webshell, *minCount)
+	if len(model.Ngrams) == 0 {
+		logging.ErrorLogger.Fatalf("No 4-gram reached -min-count %d", *minCount)
+	}
+	samples := append(normal, webshell...)
+	accuracy := 0.0
+	if *threshold != 0 {
+		model.Threshold = *threshold
+		accuracy = evaluate(model, samples, model.Threshold)
+	} else {
+		model.Threshold, accuracy = calibrate(model, samples)
+	}
+
+	if err := model.Save(*outputPath); err != nil {
+		logging.ErrorLogger.Fatalf("Failed to save model: %v", err)
+	}
+	fmt.Printf("Trained on %d normal and %d webshell files: %d 4-grams, threshold %.4f, training accuracy %.4f, saved to %s\n",
+		len(normal), len(webshell), len(model.Ngrams), model.Threshold, accuracy, *outputPath)
+}
+
+/**
+ * @Description: 递归读取目录中指定扩展名的文件
+ * @author: Mr wpl
+ * @param dir string: 目录
+ * @param exts []string: 扩展名 (小写、带点)
+ * @param webshell bool: 样本标签
+ * @return []sample: 样本
+ * @return error: 错误
+ */
+func readSamples(dir string, exts []string, webshell bool) ([]sample, error) {
+	var samples []sample
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !hasExtension(path, exts) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		samples = append(samples, sample{content: content, webshell: webshell})
+		return nil
+	})
+	return samples, err
+}
+
+// hasExtension 判断文件扩展名是否在列表中
+func hasExtension(path string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if strings.TrimSpace(e) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @Description: 统计两类样本中的 4-gram 次数，丢弃总次数低于 minCount 的 4-gram 与不是合法 UTF-8 的 4-gram
+ * (JSON 键只能保存 UTF-8，截断多字节字符的 4-gram 对 PHP 混淆检测意义不大)，按加一平滑计算各类中的相对频率
+ * @author: Mr wpl
+ * @param normal []sample: 正常样本
+ * @param webshell []sample: webshell 样本
+ * @param minCount int: 最小总次数
+ * @return *ml.NgramModel: 模型 (阈值未设置)
+ */
+func train(normal []sample, webshell []sample, minCount int) *ml.NgramModel {
+	normalCounts, normalTotal := countNgrams(normal)
+	webshellCounts, webshellTotal := countNgrams(webshell)
+
+	kept := make(map[string]bool)
+	for _, counts := range []map[string]int{normalCounts, webshellCounts} {
+		for gram := range counts {
+			if normalCounts[gram]+webshellCounts[gram] >= minCount && utf8.ValidString(gram) {
+				kept[gram] = true
+			}
+		}
+	}
+
+	vocab := float64(len(kept))
+	model := &ml.NgramModel{N: ml.NgramSize, Ngrams: make(map[string]ml.NgramFreq, len(kept))}
+	for gram := range kept {
+		model.Ngrams[gram] = ml.NgramFreq{
+			NormalFreq:   (float64(normalCounts[gram]) + 1) / (float64(normalTotal) + vocab),
+			WebshellFreq: (float64(webshellCounts[gram]) + 1) / (float64(webshellTotal) + vocab),
+		}
+	}
+	return model
+}
+
+// countNgrams 统计样本中每个 4-gram 的次数及 4-gram 总数
+func countNgrams(samples []sample) (map[string]int, int) {
+	counts := make(map[string]int)
+	total := 0
+	for _, s := range samples {
+		for i := 0; i+ml.NgramSize <= len(s.content); i++ {
+			counts[string(s.content[i:i+ml.NgramSize])]++
+			total++
+		}
+	}
+	return counts, total
+}
+
+/**
+ * @Description: 在训练样本上选择准确率最高的阈值 (相邻两个得分的中点)
+ * @author: Mr wpl
+ * @param model *ml.NgramModel: 模型
+ * @param samples []sample: 训练样本
+ * @return float64: 阈值
+ * @return float64: 该阈值下的准确率
+ */
+func calibrate(model *ml.NgramModel, samples []sample) (float64, float64) {
+	type scored struct {
+		score    float64
+		webshell bool
+	}
+	scores := make([]scored, 0, len(samples))
+	for _, s := range samples {
+		score, _ := model.Score(s.content)
+		scores = append(scores, scored{score: score, webshell: s.webshell})
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].score < scores[j].score })
+
+	// 阈值低于全部得分时所有样本判为 webshell，之后每越过一个样本，该样本改判为正常
+	correct := 0
+	for _, s := range scores {
+		if s.webshell {
+			correct++
+		}
+	}
+	bestCorrect, bestThreshold := correct, scores[0].score-1
+	for i, s := range scores {
+		if s.webshell {
+			correct--
+		} else {
+			correct++
+		}
+		if i+1 < len(scores) && scores[i+1].score == s.score {
+			continue
+		}
+		if correct > bestCorrect {
+			bestCorrect = correct
+			if i+1 < len(scores) {
+				bestThreshold = (s.score + scores[i+1].score) / 2
+			} else {
+				bestThreshold = s.score + 1
+			}
+		}
+	}
+	// 阈值为 0 时分析器会改用默认值，取一个极小的偏移
+	if bestThreshold == 0 {
+		bestThreshold = math.SmallestNonzeroFloat64
+	}
+	return bestThreshold, float64(bestCorrect) / float64(len(scores))
+}
+
+// evaluate 计算指定阈值在样本上的准确率
+func evaluate(model *ml.NgramModel, samples []sample, threshold float64) float64 {
+	correct := 0
+	for _, s := range samples {
+		score, _ := model.Score(s.content)
+		if (score > threshold) == s.webshell {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(samples))
+}
@@ -0,0 +1,250 @@
+/*
+ * @Date: 2025-06-05 14:48:30
+ * @Editors: Mr wpl
+ * @Description: 从 CSV 特征向量训练随机森林模型 (RF.model.json)
+ */
+package main
+
+import (
+	"bt-shieldml/internal/analyzers/ml"
+	"bt-shieldml/pkg/logging"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// trainParams 训练参数
+type trainParams struct {
+	trees    int
+	maxDepth int
+	minLeaf  int
+	mtry     int // 每次分裂随机选择的特征数
+}
+
+func main() {
+	csvPath := flag.String("csv", "", "CSV with a header row: LM,LVC,WM,WVC,SR,TR,SPL,IE,BAYES,label (label: 1/webshell or 0/normal) (required)")
+	outputPath := flag.String("output", "data/models/"+ml.RandomForestModelFile, "Path to write the trained model")
+	trees := flag.Int("trees", 100, "Number of trees")
+	maxDepth := flag.Int("max-depth", 12, "Maximum tree depth")
+	minLeaf := flag.Int("min-leaf", 2, "Minimum samples per leaf")
+	mtry := flag.Int("mtry", 0, "Features tried per split (0 = sqrt of feature count)")
+	threshold := flag.Float64("threshold", 0.5, "Webshell probability threshold stored in the model")
+	seed := flag.Int64("seed", 1, "Random seed for bootstrap sampling and feature selection")
+	flag.Parse()
+
+	if *csvPath == "" {
+		logging.ErrorLogger.Println("Error: -csv argument is required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	x, y, err := readCSV(*csvPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read training data: %v", err)
+	}
+	if len(x) == 0 {
+		logging.ErrorLogger.Fatalf("No training samples in %s", *csvPath)
+	}
+
+	params := trainParams{trees: *trees, maxDepth: *maxDepth, minLeaf: *minLeaf, mtry: *mtry}
+	if params.mtry <= 0 || params.mtry > len(ml.RandomForestFeatureNames) {
+		params.mtry = int(math.Sqrt(float64(len(ml.RandomForestFeatureNames))))
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	model := &ml.RandomForestModel{
+		FeatureNames: ml.RandomForestFeatureNames,
+		Threshold:    *threshold,
+	}
+	for t := 0; t < params.trees; t++ {
+		sample := make([]int, len(x))
+		for i := range sample {
+			sample[i] = rng.Intn(len(x)) // bootstrap 有放回抽样
+		}
+		b := &treeBuilder{x: x, y: y, params: params, rng: rng}
+		b.build(sample, 0)
+		model.Trees = append(model.Trees, ml.DecisionTree{Nodes: b.nodes})
+	}
+
+	correct := 0
+	for i := range x {
+		p, err := model.Predict(x[i])
+		if err != nil {
+			logging.ErrorLogger.Fatalf("Failed to evaluate model: %v", err)
+		}
+		if (p >= model.Threshold) == (y[i] == 1) {
+			correct++
+		}
+	}
+
+	if err := model.Save(*outputPath); err != nil {
+		logging.ErrorLogger.Fatalf("Failed to save model: %v", err)
+	}
+	fmt.Printf("Trained %d trees on %d samples, training accuracy %.4f, saved to %s\n",
+		len(model.Trees), len(x), float64(correct)/float64(len(x)), *outputPath)
+}
+
+/**
+ * @Description: 读取 CSV 训练数据，按表头中的特征名取列
+ * @author: Mr wpl
+ * @param path string: CSV 路径
+ * @return [][]float64: 特征向量
+ * @return []int: 标签 (1 = webshell, 0 = normal)
+ * @return error: 错误
+ */
+func readCSV(path string) ([][]float64, []int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	featureCols := make([]int, len(ml.RandomForestFeatureNames))
+	for i, name := range ml.RandomForestFeatureNames {
+		col, ok := columns[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing column %s", name)
+		}
+		featureCols[i] = col
+	}
+	labelCol, ok := columns["LABEL"]
+	if !ok {
+		return nil, nil, fmt.Errorf("missing column label")
+	}
+
+	var x [][]float64
+	var y []int
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		row := make([]float64, len(featureCols))
+		for i, col := range featureCols {
+			row[i], err = strconv.ParseFloat(strings.TrimSpace(record[col]), 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d, column %s: %w", line, ml.RandomForestFeatureNames[i], err)
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(record[labelCol])) {
+		case "1", "webshell":
+			y = append(y, 1)
+		case "0", "normal":
+			y = append(y, 0)
+		default:
+			return nil, nil, fmt.Errorf("line %d: invalid label %q", line, record[labelCol])
+		}
+		x = append(x, row)
+	}
+	return x, y, nil
+}
+
+// treeBuilder 使用 Gini 不纯度构建一棵 CART 决策树
+type treeBuilder struct {
+	x      [][]float64
+	y      []int
+	params trainParams
+	rng    *rand.Rand
+	nodes  []ml.TreeNode
+}
+
+/**
+ * @Description: 递归构建子树，返回节点下标
+ * @author: Mr wpl
+ * @param idx []int: 落入该节点的样本下标
+ * @param depth int: 当前深度
+ * @return int: 节点下标
+ */
+func (b *treeBuilder) build(idx []int, depth int) int {
+	positives := 0
+	for _, i := range idx {
+		positives += b.y[i]
+	}
+	nodeIdx := len(b.nodes)
+	b.nodes = append(b.nodes, ml.TreeNode{Feature: -1, Left: -1, Right: -1, Value: float64(positives) / float64(len(idx))})
+
+	if depth >= b.params.maxDepth || len(idx) < 2*b.params.minLeaf || positives == 0 || positives == len(idx) {
+		return nodeIdx
+	}
+
+	feature, threshold, ok := b.bestSplit(idx, positives)
+	if !ok {
+		return nodeIdx
+	}
+	var left, right []int
+	for _, i := range idx {
+		if b.x[i][feature] <= threshold {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+
+	leftIdx := b.build(left, depth+1)
+	rightIdx := b.build(right, depth+1)
+	b.nodes[nodeIdx] = ml.TreeNode{Feature: feature, Threshold: threshold, Left: leftIdx, Right: rightIdx}
+	return nodeIdx
+}
+
+/**
+ * @Description: 在随机选择的 mtry 个特征中寻找 Gini 不纯度最低的分裂点
+ * @author: Mr wpl
+ * @param idx []int: 样本下标
+ * @param positives int: 正样本数
+ * @return int: 特征下标
+ * @return float64: 分裂阈值
+ * @return bool: 是否找到满足 minLeaf 的分裂
+ */
+func (b *treeBuilder) bestSplit(idx []int, positives int) (int, float64, bool) {
+	n := len(idx)
+	bestFeature, bestThreshold := -1, 0.0
+	bestImpurity := gini(positives, n)
+
+	sorted := make([]int, n)
+	for _, feature := range b.rng.Perm(len(ml.RandomForestFeatureNames))[:b.params.mtry] {
+		copy(sorted, idx)
+		sort.Slice(sorted, func(i, j int) bool { return b.x[sorted[i]][feature] < b.x[sorted[j]][feature] })
+
+		leftPos := 0
+		for k := 1; k < n; k++ {
+			leftPos += b.y[sorted[k-1]]
+			lo, hi := b.x[sorted[k-1]][feature], b.x[sorted[k]][feature]
+			if lo == hi || k < b.params.minLeaf || n-k < b.params.minLeaf {
+				continue
+			}
+			impurity := (float64(k)*gini(leftPos, k) + float64(n-k)*gini(positives-leftPos, n-k)) / float64(n)
+			if impurity < bestImpurity {
+				bestFeature, bestThreshold, bestImpurity = feature, (lo+hi)/2, impurity
+			}
+		}
+	}
+	return bestFeature, bestThreshold, bestFeature >= 0
+}
+
+// gini 二分类 Gini 不纯度
+func gini(positives, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	p := float64(positives) / float64(total)
+	return 2 * p * (1 - p)
+}
@@ -0,0 +1,67 @@
+/*
+ * @Date: 2025-05-21 15:02:17
+ * @Editors: Mr wpl
+ * @Description: 校验JSON报告中扫描结果的签名
+ */
+package main
+
+import (
+	"bt-shieldml/internal/signing"
+	"bt-shieldml/pkg/logging"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	reportPath := flag.String("report", "data/webshellJson.json", "Path to the JSON report to verify")
+	keyPath := flag.String("key", "", "PEM public key (Ed25519 or RSA) used to verify signatures (required)")
+	flag.Parse()
+
+	if *keyPath == "" {
+		logging.ErrorLogger.Println("Error: -key argument is required.")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	publicKey, err := os.ReadFile(*keyPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read public key: %v", err)
+	}
+
+	data, err := os.ReadFile(*reportPath)
+	if err != nil {
+		logging.ErrorLogger.Fatalf("Failed to read report: %v", err)
+	}
+
+	var report struct {
+		SignedResults []signing.Record `json:"signed_results"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		logging.ErrorLogger.Fatalf("Failed to parse report: %v", err)
+	}
+	if len(report.SignedResults) == 0 {
+		logging.ErrorLogger.Fatalf("Report %s contains no signed results", *reportPath)
+	}
+
+	failed := 0
+	for _, rec := range report.SignedResults {
+		ok, verifyErr := signing.Verify(rec.ToResult(), publicKey)
+		switch {
+		case verifyErr != nil:
+			failed++
+			fmt.Printf("[ERROR] %s: %v\n", rec.File.Path, verifyErr)
+		case !ok:
+			failed++
+			fmt.Printf("[INVALID] %s\n", rec.File.Path)
+		default:
+			fmt.Printf("[OK] %s\n", rec.File.Path)
+		}
+	}
+
+	fmt.Printf("\nVerified %d results, %d failed.\n", len(report.SignedResults), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
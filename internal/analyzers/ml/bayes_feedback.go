@@ -0,0 +1,103 @@
+/*
+ * @Date: 2025-05-28 10:12:05
+ * @Editors: Mr wpl
+ * @Description: 根据人工反馈调整 Bayes Words 模型的词频
+ */
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WordsModel 可修改的 Bayes Words 模型 (Words.model 的 JSON 结构)
+type WordsModel struct {
+	data goBayesianModelData
+}
+
+/**
+ * @Description: 从磁盘读取 Words.model
+ * @author: Mr wpl
+ * @param path string: 模型路径
+ * @return *WordsModel: 模型
+ * @return error: 错误
+ */
+func LoadWordsModel(path string) (*WordsModel, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 bayes 模型文件失败: %w", err)
+	}
+	m := &WordsModel{}
+	if err := json.Unmarshal(raw, &m.data); err != nil {
+		return nil, fmt.Errorf("解析bayes模型JSON失败: %w", err)
+	}
+	if m.data.Normal.WordCount == nil {
+		m.data.Normal.WordCount = make(map[string]int)
+	}
+	if m.data.Webshell.WordCount == nil {
+		m.data.Webshell.WordCount = make(map[string]int)
+	}
+	return m, nil
+}
+
+/**
+ * @Description: 将一个文件的词从错误类别移到正确类别：正确类别词频加一，错误类别词频减一 (不低于 0)，
+ * 文档数同样从错误类别移到正确类别
+ * @author: Mr wpl
+ * @param words []string: 文件的 AST 词
+ * @param correctLabel string: "normal" 或 "webshell"
+ * @return error: 标签非法时返回错误
+ */
+func (m *WordsModel) ApplyCorrection(words []string, correctLabel string) error {
+	var target, other *classData
+	switch correctLabel {
+	case "normal":
+		target, other = &m.data.Normal, &m.data.Webshell
+	case "webshell":
+		target, other = &m.data.Webshell, &m.data.Normal
+	default:
+		return fmt.Errorf("unknown label %q", correctLabel)
+	}
+
+	for _, word := range words {
+		target.WordCount[word]++
+		target.TotalWordCount++
+		if other.WordCount[word] > 0 {
+			other.WordCount[word]--
+			other.TotalWordCount--
+			if other.WordCount[word] == 0 {
+				delete(other.WordCount, word)
+			}
+		}
+	}
+
+	target.DocCount++
+	if other.DocCount > 0 {
+		other.DocCount--
+	}
+	m.data.TotalDocumentCount = m.data.Normal.DocCount + m.data.Webshell.DocCount
+	return nil
+}
+
+/**
+ * @Description: 将模型写回磁盘 (先写临时文件再替换，避免中途失败损坏模型)
+ * @author: Mr wpl
+ * @param path string: 模型路径
+ * @return error: 错误
+ */
+func (m *WordsModel) Save(path string) error {
+	raw, err := json.Marshal(&m.data)
+	if err != nil {
+		return fmt.Errorf("序列化bayes模型失败: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, raw, 0644); err != nil {
+		return fmt.Errorf("写入bayes模型失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("替换bayes模型失败: %w", err)
+	}
+	return nil
+}
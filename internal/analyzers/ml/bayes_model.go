@@ -0,0 +1,92 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 从头构建 Bayes Words 模型 (供 train-bayes 使用)，输出与原 Words.model 相同的 JSON 结构
+ */
+package ml
+
+import "fmt"
+
+// BayesWordsModelFile Bayes Words 模型文件名 (位于 data_paths.models 下)
+const BayesWordsModelFile = "Words.model"
+
+/**
+ * @Description: 创建空的 Bayes Words 模型
+ * @author: Mr wpl
+ * @return *WordsModel: 模型
+ */
+func NewWordsModel() *WordsModel {
+	return &WordsModel{data: goBayesianModelData{
+		Normal:   classData{WordCount: make(map[string]int)},
+		Webshell: classData{WordCount: make(map[string]int)},
+	}}
+}
+
+/**
+ * @Description: 将一个训练文档的词计入对应类别的词频与文档数
+ * @author: Mr wpl
+ * @param words []string: 文档的 AST 词
+ * @param label string: "normal" 或 "webshell"
+ * @return error: 标签非法时返回错误
+ */
+func (m *WordsModel) AddDocument(words []string, label string) error {
+	var target *classData
+	switch label {
+	case "normal":
+		target = &m.data.Normal
+	case "webshell":
+		target = &m.data.Webshell
+	default:
+		return fmt.Errorf("unknown label %q", label)
+	}
+	for _, word := range words {
+		target.WordCount[word]++
+	}
+	target.TotalWordCount += len(words)
+	target.DocCount++
+	m.data.TotalDocumentCount = m.data.Normal.DocCount + m.data.Webshell.DocCount
+	return nil
+}
+
+/**
+ * @Description: 添加一个验证样本，保存在模型中，BAYES_VALIDATE=1 时加载模型后据此计算 F1
+ * @author: Mr wpl
+ * @param name string: 样本名称 (如文件的相对路径)
+ * @param words []string: 样本的 AST 词
+ * @param label string: "normal" 或 "webshell"
+ * @return error: 标签非法时返回错误
+ */
+func (m *WordsModel) AddValidationSample(name string, words []string, label string) error {
+	if label != "normal" && label != "webshell" {
+		return fmt.Errorf("unknown label %q", label)
+	}
+	if m.data.ValidationSamples == nil {
+		m.data.ValidationSamples = make(map[string]bayesValidationSample)
+	}
+	m.data.ValidationSamples[name] = bayesValidationSample{Words: words, ExpectedClass: label}
+	return nil
+}
+
+// Vocabulary 模型中不同词的数量
+func (m *WordsModel) Vocabulary() int {
+	vocab := len(m.data.Normal.WordCount)
+	for word := range m.data.Webshell.WordCount {
+		if _, ok := m.data.Normal.WordCount[word]; !ok {
+			vocab++
+		}
+	}
+	return vocab
+}
+
+/**
+ * @Description: 用与 BayesWordsAnalyzer 相同的分类器预测词序列的类别
+ * @author: Mr wpl
+ * @return func([]string) string: 预测函数，返回 "normal" 或 "webshell"；模型之后的修改不影响已返回的函数
+ */
+func (m *WordsModel) Classifier() func(words []string) string {
+	classifier := newBayesClassifier(&m.data)
+	return func(words []string) string {
+		_, predicted, _ := classifier.Classify(words...)
+		return string(predicted)
+	}
+}
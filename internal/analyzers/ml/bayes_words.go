@@ -15,6 +15,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"bt-shieldml/pkg/embedded"
 
@@ -35,14 +36,37 @@ type goBayesianModelData struct {
 	Normal             classData `json:"normal"`             // "normal" 类别的数据
 	Webshell           classData `json:"webshell"`           // "webshell" 类别的数据
 	TotalDocumentCount int       `json:"totalDocumentCount"` // 所有类别的总文档数
+	// 验证样本，BAYES_VALIDATE=1 时加载模型后用于计算精确率、召回率与 F1
+	ValidationSamples map[string]bayesValidationSample `json:"validationSamples,omitempty"`
+}
+
+// bayesValidationSample Bayes Words 模型的验证样本
+type bayesValidationSample struct {
+	Words         []string `json:"words"`         // 样本的 AST 词
+	ExpectedClass string   `json:"expectedClass"` // "normal" 或 "webshell"
 }
 
 // --- 结构体定义结束 ---
 
+// BayesValidateEnv 设置为 1 时，加载 Bayes Words 模型后用模型中的验证样本评估分类效果
+const BayesValidateEnv = "BAYES_VALIDATE"
+
+// bayesMinF1 验证 F1 低于该值时建议重新训练模型
+const bayesMinF1 = 0.7
+
+// BayesStats Bayes Words 模型概况
+type BayesStats struct {
+	Vocabulary       int     // 词表大小
+	NormalDocCount   int     // normal 类文档数
+	WebshellDocCount int     // webshell 类文档数
+	ValidationF1     float64 // 验证样本上的 F1，未验证时为 0
+}
+
 type BayesWordsAnalyzer struct {
 	analyzerName  string
 	classifier    bayesian.Classifier
 	isInitialized bool
+	stats         BayesStats
 }
 
 func NewBayesWordsAnalyzer(modelPath string) (*BayesWordsAnalyzer, error) {
@@ -81,6 +105,21 @@ func NewBayesWordsAnalyzer(modelPath string) (*BayesWordsAnalyzer, error) {
 		return nil, fmt.Errorf("解析bayes模型JSON失败: %w", err)
 	}
 
+	analyzer.classifier = newBayesClassifier(&modelData)
+	analyzer.isInitialized = true
+	analyzer.stats = BayesStats{
+		Vocabulary:       len(analyzer.classifier.LearningResults),
+		NormalDocCount:   modelData.Normal.DocCount,
+		WebshellDocCount: modelData.Webshell.DocCount,
+	}
+	if os.Getenv(BayesValidateEnv) == "1" {
+		analyzer.validateModel(modelData.ValidationSamples)
+	}
+	return analyzer, nil
+}
+
+// newBayesClassifier 由模型 JSON 数据构建 go-bayesian 分类器
+func newBayesClassifier(modelData *goBayesianModelData) bayesian.Classifier {
 	// --- 第 3 步: 手动构建 bayesian.Classifier 对象 ---
 	// 根据 JSON 数据定义分类器的类别
 	normalClass := bayesian.Class("normal")
@@ -130,7 +169,7 @@ func NewBayesWordsAnalyzer(modelPath string) (*BayesWordsAnalyzer, error) {
 	}
 
 	// --- 创建最终的 classifier 对象 ---
-	analyzer.classifier = bayesian.Classifier{
+	return bayesian.Classifier{
 		Model: bayesian.MultinomialTf, 
 		// 注意：go-bayesian 库的 PriorProbabilities 字段存储的是对数先验概率
 		PriorProbabilities: priorProbabilities,           // 存储计算出的对数先验概率
@@ -139,9 +178,65 @@ func NewBayesWordsAnalyzer(modelPath string) (*BayesWordsAnalyzer, error) {
 		NFrequencyByClass:  nFreqByClass,                 // 设置各类别的总词频
 		NAllDocument:       modelData.TotalDocumentCount, // 设置总文档数
 	}
+}
 
-	analyzer.isInitialized = true
-	return analyzer, nil
+/**
+ * @Description: 用验证样本评估模型，以 webshell 为正类输出精确率、召回率与 F1，F1 过低时建议重新训练
+ * @author: Mr wpl
+ * @param samples map[string]bayesValidationSample: 验证样本
+ */
+func (a *BayesWordsAnalyzer) validateModel(samples map[string]bayesValidationSample) {
+	if len(samples) == 0 {
+		logging.WarnLogger.Printf("Bayes Words 模型没有验证样本，跳过验证")
+		return
+	}
+
+	truePos, falsePos, falseNeg, total := 0, 0, 0, 0
+	for sampleName, sample := range samples {
+		if sample.ExpectedClass != "normal" && sample.ExpectedClass != "webshell" {
+			logging.WarnLogger.Printf("验证样本 %s 的期望类别 %q 无效，已跳过", sampleName, sample.ExpectedClass)
+			continue
+		}
+		total++
+		_, predicted, _ := a.classifier.Classify(sample.Words...)
+		switch {
+		case predicted == "webshell" && sample.ExpectedClass == "webshell":
+			truePos++
+		case predicted == "webshell":
+			falsePos++
+		case sample.ExpectedClass == "webshell":
+			falseNeg++
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	var precision, recall, f1 float64
+	if truePos+falsePos > 0 {
+		precision = float64(truePos) / float64(truePos+falsePos)
+	}
+	if truePos+falseNeg > 0 {
+		recall = float64(truePos) / float64(truePos+falseNeg)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	a.stats.ValidationF1 = f1
+
+	logging.InfoLogger.Printf("Bayes Words 模型验证完成 (%d 个样本): 精确率=%.4f, 召回率=%.4f, F1=%.4f", total, precision, recall, f1)
+	if f1 < bayesMinF1 {
+		logging.WarnLogger.Printf("Bayes Words 模型验证 F1 (%.4f) 低于 %.2f，建议重新训练模型", f1, bayesMinF1)
+	}
+}
+
+/**
+ * @Description: 返回模型概况：词表大小、各类别文档数与验证 F1
+ * @author: Mr wpl
+ * @return BayesStats: 模型概况
+ */
+func (a *BayesWordsAnalyzer) Stats() BayesStats {
+	return a.stats
 }
 
 func (a *BayesWordsAnalyzer) Name() string {
@@ -153,6 +248,11 @@ func (a *BayesWordsAnalyzer) RequiredFeatures() []string {
 	return []string{"ast_words"}
 }
 
+// SupportedExtensions 模型基于 PHP 样本训练，只用于 PHP 文件
+func (a *BayesWordsAnalyzer) SupportedExtensions() []string {
+	return types.PHPExtensions
+}
+
 func (a *BayesWordsAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
 	// 1. 检查分析器是否已初始化
 	if !a.isInitialized {
@@ -210,12 +310,87 @@ func (a *BayesWordsAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, fe
 	}
 
 	// --- 7. 构建并返回发现 ---
-	return &types.Finding{
+	finding := &types.Finding{
 		AnalyzerName: a.Name(),
 		Description:  fmt.Sprintf("Bayes Words 模型预测为 (类别: %s, 置信度: %.4f)", predictedClass, confidence),
 		Risk:         types.RiskMedium,
 		Confidence:   confidence,
-	}, nil
+		Severity:     types.SeverityMedium,
+	}
+
+	// 预测为 webshell 时附带影响最大的词，便于人工研判
+	if predictedClass == "webshell" {
+		topWords := make([]map[string]interface{}, 0, defaultTopWords)
+		for _, wi := range a.TopInfluencingWords(words, defaultTopWords) {
+			topWords = append(topWords, map[string]interface{}{
+				"word":     wi.Word,
+				"log_odds": wi.LogOdds,
+			})
+		}
+		finding.Metadata = map[string]interface{}{"top_words": topWords}
+	}
+
+	return finding, nil
+}
+
+// defaultTopWords TopInfluencingWords 默认返回的词数
+const defaultTopWords = 10
+
+// WordInfluence 单个词对分类结果的影响
+type WordInfluence struct {
+	Word    string
+	LogOdds float64 // log P(word|webshell) - log P(word|normal)，正值偏向 webshell
+}
+
+/**
+ * @Description: 计算对分类结果影响最大的词，按 |LogOdds| 降序排列。
+ * 词的条件概率使用拉普拉斯平滑: P(w|c) = (count(w,c)+1) / (total(c)+|V|)
+ * @author: Mr wpl
+ * @param words []string: 文件中提取的词
+ * @param n int: 返回数量，<=0 时使用默认值 10
+ * @return []WordInfluence: 影响最大的 n 个词
+ */
+func (a *BayesWordsAnalyzer) TopInfluencingWords(words []string, n int) []WordInfluence {
+	if !a.isInitialized {
+		return nil
+	}
+	if n <= 0 {
+		n = defaultTopWords
+	}
+
+	webshellClass := bayesian.Class("webshell")
+	normalClass := bayesian.Class("normal")
+	vocabSize := float64(len(a.classifier.LearningResults))
+	webshellTotal := float64(a.classifier.NFrequencyByClass[webshellClass]) + vocabSize
+	normalTotal := float64(a.classifier.NFrequencyByClass[normalClass]) + vocabSize
+	if webshellTotal <= 0 || normalTotal <= 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	influences := make([]WordInfluence, 0, len(words))
+	for _, word := range words {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+
+		counts := a.classifier.LearningResults[word]
+		pWebshell := (float64(counts[webshellClass]) + 1) / webshellTotal
+		pNormal := (float64(counts[normalClass]) + 1) / normalTotal
+		influences = append(influences, WordInfluence{
+			Word:    word,
+			LogOdds: math.Log(pWebshell) - math.Log(pNormal),
+		})
+	}
+
+	sort.SliceStable(influences, func(i, j int) bool {
+		return math.Abs(influences[i].LogOdds) > math.Abs(influences[j].LogOdds)
+	})
+	if len(influences) > n {
+		influences = influences[:n]
+	}
+	return influences
 }
 
 // min 函数 (用于日志截断)
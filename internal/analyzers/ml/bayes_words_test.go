@@ -0,0 +1,70 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: Bayes 影响词计算测试
+ */
+package ml
+
+import (
+	"math"
+	"testing"
+
+	"github.com/CyrusF/go-bayesian"
+)
+
+// newTestBayesAnalyzer 以已知词表构造分析器：webshell 类共 16 个词，normal 类共 13 个词，词表大小 4
+func newTestBayesAnalyzer() *BayesWordsAnalyzer {
+	webshell, normal := bayesian.Class("webshell"), bayesian.Class("normal")
+	return &BayesWordsAnalyzer{
+		analyzerName:  "bayes_words",
+		isInitialized: true,
+		classifier: bayesian.Classifier{
+			LearningResults: map[string]map[bayesian.Class]int{
+				"eval":          {webshell: 8, normal: 0},
+				"echo":          {webshell: 1, normal: 9},
+				"base64_decode": {webshell: 4, normal: 1},
+				"function":      {webshell: 3, normal: 3},
+			},
+			NFrequencyByClass: map[bayesian.Class]int{webshell: 16, normal: 13},
+		},
+	}
+}
+
+func TestTopInfluencingWords(t *testing.T) {
+	a := newTestBayesAnalyzer()
+	// 拉普拉斯平滑：P(w|webshell) = (c+1)/(16+4)，P(w|normal) = (c+1)/(13+4)
+	want := []WordInfluence{
+		{"eval", math.Log(9.0/20) - math.Log(1.0/17)},          // ≈ +2.03
+		{"echo", math.Log(2.0/20) - math.Log(10.0/17)},         // ≈ -1.77
+		{"base64_decode", math.Log(5.0/20) - math.Log(2.0/17)}, // ≈ +0.75
+		{"function", math.Log(4.0/20) - math.Log(4.0/17)},      // ≈ -0.16
+		{"unseen", math.Log(1.0/20) - math.Log(1.0/17)},        // 未登录词只有平滑项
+	}
+
+	// 重复的词只计算一次；|LogOdds| 相同时保持首次出现的顺序
+	words := []string{"function", "echo", "eval", "unseen", "eval", "base64_decode", "echo"}
+	got := a.TopInfluencingWords(words, 0)
+	if len(got) != len(want) {
+		t.Fatalf("TopInfluencingWords() returned %d words, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].Word != want[i].Word || math.Abs(got[i].LogOdds-want[i].LogOdds) > 1e-12 {
+			t.Errorf("TopInfluencingWords()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTopInfluencingWordsLimit(t *testing.T) {
+	a := newTestBayesAnalyzer()
+	got := a.TopInfluencingWords([]string{"function", "echo", "eval", "base64_decode"}, 2)
+	if len(got) != 2 || got[0].Word != "eval" || got[1].Word != "echo" {
+		t.Errorf("TopInfluencingWords(n=2) = %v, want [eval echo]", got)
+	}
+}
+
+func TestTopInfluencingWordsUninitialized(t *testing.T) {
+	a := &BayesWordsAnalyzer{analyzerName: "bayes_words"}
+	if got := a.TopInfluencingWords([]string{"eval"}, 10); got != nil {
+		t.Errorf("TopInfluencingWords() on an uninitialized analyzer = %v, want nil", got)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 字符 4-gram 模型 (纯 Go)：按原始字节的 4-gram 在正常代码与 webshell 中的频率计算对数似然比，识别新型混淆
+ */
+package ml
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// NgramModelFile n-gram 模型文件名 (位于 data_paths.models 下)
+const NgramModelFile = "Ngram.model"
+
+// NgramSize n-gram 的长度 (字节)
+const NgramSize = 4
+
+// defaultNgramThreshold 模型未记录阈值时使用的平均对数似然比阈值
+const defaultNgramThreshold = 0.5
+
+// NgramFreq 一个 n-gram 在两类样本中的相对频率 (训练时已做平滑，均大于 0)
+type NgramFreq struct {
+	NormalFreq   float64 `json:"normal_freq"`
+	WebshellFreq float64 `json:"webshell_freq"`
+}
+
+// NgramModel Ngram.model 的内容
+type NgramModel struct {
+	N         int                  `json:"n"`         // n-gram 长度，目前只支持 4
+	Threshold float64              `json:"threshold"` // 平均对数似然比超过该值时报告 (训练时校准)
+	Ngrams    map[string]NgramFreq `json:"ngrams"`    // n-gram (原始字节) → 频率
+}
+
+/**
+ * @Description: 从 JSON 文件加载 n-gram 模型，频率不为正的条目被丢弃
+ * @author: Mr wpl
+ * @param path string: 模型文件路径
+ * @return *NgramModel: 模型
+ * @return error: 错误
+ */
+func LoadNgramModel(path string) (*NgramModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	model := &NgramModel{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, fmt.Errorf("解析 n-gram 模型失败: %w", err)
+	}
+	if model.N == 0 {
+		model.N = NgramSize
+	}
+	if model.N != NgramSize {
+		return nil, fmt.Errorf("不支持的 n-gram 长度 %d (只支持 %d)", model.N, NgramSize)
+	}
+	for gram, freq := range model.Ngrams {
+		if len(gram) != NgramSize || freq.NormalFreq <= 0 || freq.WebshellFreq <= 0 {
+			delete(model.Ngrams, gram)
+		}
+	}
+	if len(model.Ngrams) == 0 {
+		return nil, fmt.Errorf("n-gram 模型不包含有效的 %d-gram", NgramSize)
+	}
+	if model.Threshold == 0 {
+		model.Threshold = defaultNgramThreshold
+	}
+	return model, nil
+}
+
+/**
+ * @Description: 将模型保存为 JSON 文件
+ * @author: Mr wpl
+ * @param path string: 模型文件路径
+ * @return error: 错误
+ */
+func (m *NgramModel) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+/**
+ * @Description: 滑动窗口遍历原始字节的 4-gram，累加模型中已有 n-gram 的对数似然比 log(webshell_freq/normal_freq)，
+ * 再除以 4-gram 总数 (按文件长度归一化)。模型中没有的 n-gram 贡献为 0
+ * @author: Mr wpl
+ * @param content []byte: 文件内容
+ * @return float64: 平均对数似然比，大于 0 表示更接近 webshell
+ * @return int: 4-gram 总数，文件不足 4 字节时为 0
+ */
+func (m *NgramModel) Score(content []byte) (float64, int) {
+	total := len(content) - NgramSize + 1
+	if total <= 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for i := 0; i < total; i++ {
+		if freq, ok := m.Ngrams[string(content[i:i+NgramSize])]; ok {
+			sum += math.Log(freq.WebshellFreq / freq.NormalFreq)
+		}
+	}
+	return sum / float64(total), total
+}
+
+// NgramAnalyzer 字符 n-gram 分析器，不依赖 AST
+type NgramAnalyzer struct {
+	model *NgramModel
+}
+
+/**
+ * @Description: 初始化 n-gram 分析器，模型文件不存在时分析器处于非活动状态
+ * @author: Mr wpl
+ * @param modelPath string: 模型目录
+ * @return *NgramAnalyzer: 分析器实例
+ * @return error: 错误信息
+ */
+func NewNgramAnalyzer(modelPath string) (*NgramAnalyzer, error) {
+	analyzer := &NgramAnalyzer{}
+
+	model, err := LoadNgramModel(filepath.Join(modelPath, NgramModelFile))
+	if err != nil {
+		logging.WarnLogger.Printf("加载 n-gram 模型失败: %v，分析器将处于非活动状态。", err)
+		return analyzer, nil
+	}
+	analyzer.model = model
+
+	logging.InfoLogger.Printf("成功加载 n-gram 模型: %d 个 %d-gram, 阈值 %.4f", len(model.Ngrams), model.N, model.Threshold)
+	return analyzer, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *NgramAnalyzer) Name() string {
+	return "ngram"
+}
+
+/**
+ * @Description: 返回此分析器所需的特征，直接分析原始字节，不需要 AST
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *NgramAnalyzer) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，模型基于 PHP 样本训练，只用于 PHP 文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *NgramAnalyzer) SupportedExtensions() []string {
+	return types.PHPExtensions
+}
+
+/**
+ * @Description: 实现Analyzer接口的Analyze方法，平均对数似然比超过模型阈值时报告 Medium
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *NgramAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	if a.model == nil {
+		return nil, nil
+	}
+	score, grams := a.model.Score(content)
+	if grams == 0 || score <= a.model.Threshold {
+		return nil, nil
+	}
+	featureSet.Logger().Infof("n-gram score for %s: %.4f (threshold %.4f)", fileInfo.Path, score, a.model.Threshold)
+
+	return &types.Finding{
+		AnalyzerName: a.Name(),
+		Description:  fmt.Sprintf("字符 %d-gram 分布接近混淆 webshell (平均对数似然比 %.4f, 阈值 %.4f)", a.model.N, score, a.model.Threshold),
+		Risk:         types.RiskMedium,
+		Confidence:   1 / (1 + math.Exp(-score)),
+		Severity:     types.SeverityMedium,
+		Metadata:     map[string]interface{}{"score": score, "ngrams": grams},
+	}, nil
+}
@@ -0,0 +1,172 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 字符 4-gram 模型测试：testdata/ngram 中的小模型，混淆样本得分高于阈值，正常代码低于阈值
+ */
+package ml
+
+import (
+	"bt-shieldml/pkg/types"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ngramTestData 测试模型 (混淆常见 4-gram 的 webshell 频率为正常代码的 100 倍，反之亦然) 与样本
+const ngramTestData = "testdata/ngram"
+
+func TestNgramAnalyzerFixtures(t *testing.T) {
+	a, err := NewNgramAnalyzer(ngramTestData)
+	if err != nil {
+		t.Fatalf("NewNgramAnalyzer() error = %v", err)
+	}
+	if a.model == nil {
+		t.Fatal("test model was not loaded")
+	}
+
+	tests := []struct {
+		file      string
+		wantMatch bool
+	}{
+		{"obfuscated_globals.php", true},
+		{"obfuscated_chr.php", true},
+		{"normal_class.php", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(ngramTestData, tt.file)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			finding, err := a.Analyze(types.FileInfo{Path: path}, content, nil)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			score, _ := a.model.Score(content)
+			if (finding != nil) != tt.wantMatch {
+				t.Fatalf("Analyze() finding = %+v (score %.4f), want match %v", finding, score, tt.wantMatch)
+			}
+			if finding == nil {
+				return
+			}
+			if finding.Risk != types.RiskMedium || finding.AnalyzerName != "ngram" {
+				t.Errorf("finding = %s/%v, want ngram/Medium", finding.AnalyzerName, finding.Risk)
+			}
+			if finding.Confidence <= 0.5 || finding.Confidence >= 1 {
+				t.Errorf("Confidence = %v, want in (0.5, 1) for a positive score", finding.Confidence)
+			}
+			if finding.Metadata["score"] != score {
+				t.Errorf("metadata score = %v, want %v", finding.Metadata["score"], score)
+			}
+		})
+	}
+}
+
+func TestNgramModelScore(t *testing.T) {
+	m := &NgramModel{N: NgramSize, Threshold: 0.5, Ngrams: map[string]NgramFreq{
+		"eval": {NormalFreq: 0.001, WebshellFreq: 0.01},
+		"echo": {NormalFreq: 0.01, WebshellFreq: 0.001},
+	}}
+	ln10 := math.Log(10)
+	tests := []struct {
+		name      string
+		content   string
+		wantScore float64
+		wantGrams int
+	}{
+		{"shorter than n", "eva", 0, 0},
+		{"exactly one gram", "eval", ln10, 1},
+		{"unknown grams contribute zero", "xxxxeval", ln10 / 5, 5},
+		{"opposing grams cancel", "evalecho", 0, 5},
+		{"normal gram is negative", "echo", -ln10, 1},
+		{"repeated gram", "evaleval", 2 * ln10 / 5, 5},
+		{"empty", "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, grams := m.Score([]byte(tt.content))
+			if grams != tt.wantGrams || math.Abs(score-tt.wantScore) > 1e-9 {
+				t.Errorf("Score(%q) = %v, %d, want %v, %d", tt.content, score, grams, tt.wantScore, tt.wantGrams)
+			}
+		})
+	}
+}
+
+func TestLoadNgramModel(t *testing.T) {
+	tests := []struct {
+		name          string
+		json          string
+		wantErr       bool
+		wantGrams     int
+		wantThreshold float64
+	}{
+		{"valid", `{"n":4,"threshold":1.5,"ngrams":{"eval":{"normal_freq":0.1,"webshell_freq":0.2}}}`, false, 1, 1.5},
+		{"n defaults to 4", `{"ngrams":{"eval":{"normal_freq":0.1,"webshell_freq":0.2}}}`, false, 1, defaultNgramThreshold},
+		{"invalid entries dropped", `{"ngrams":{"eval":{"normal_freq":0.1,"webshell_freq":0.2},"abc":{"normal_freq":0.1,"webshell_freq":0.2},"echo":{"normal_freq":0,"webshell_freq":0.2}}}`, false, 1, defaultNgramThreshold},
+		{"unsupported n", `{"n":3,"ngrams":{"eva":{"normal_freq":0.1,"webshell_freq":0.2}}}`, true, 0, 0},
+		{"no valid grams", `{"ngrams":{"echo":{"normal_freq":-1,"webshell_freq":0.2}}}`, true, 0, 0},
+		{"not json", `Ngram`, true, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), NgramModelFile)
+			if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+				t.Fatal(err)
+			}
+			m, err := LoadNgramModel(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadNgramModel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(m.Ngrams) != tt.wantGrams || m.Threshold != tt.wantThreshold || m.N != NgramSize {
+				t.Errorf("LoadNgramModel() = %d grams, threshold %v, n %d, want %d, %v, %d",
+					len(m.Ngrams), m.Threshold, m.N, tt.wantGrams, tt.wantThreshold, NgramSize)
+			}
+		})
+	}
+}
+
+func TestNgramModelSaveLoad(t *testing.T) {
+	want, err := LoadNgramModel(filepath.Join(ngramTestData, NgramModelFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), NgramModelFile)
+	if err := want.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := LoadNgramModel(path)
+	if err != nil {
+		t.Fatalf("LoadNgramModel() error = %v", err)
+	}
+	if got.Threshold != want.Threshold || len(got.Ngrams) != len(want.Ngrams) {
+		t.Fatalf("round trip = %d grams, threshold %v, want %d, %v", len(got.Ngrams), got.Threshold, len(want.Ngrams), want.Threshold)
+	}
+	for gram, freq := range want.Ngrams {
+		if got.Ngrams[gram] != freq {
+			t.Errorf("gram %q = %+v, want %+v", gram, got.Ngrams[gram], freq)
+		}
+	}
+}
+
+// TestNgramAnalyzerInactive 模型不存在时分析器不活动，不报告任何文件
+func TestNgramAnalyzerInactive(t *testing.T) {
+	a, err := NewNgramAnalyzer(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewNgramAnalyzer() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(ngramTestData, "obfuscated_globals.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finding, err := a.Analyze(types.FileInfo{Path: "obfuscated_globals.php"}, content, nil); finding != nil || err != nil {
+		t.Errorf("Analyze() = %+v, %v, want nil, nil", finding, err)
+	}
+	if a.RequiredFeatures() != nil {
+		t.Errorf("RequiredFeatures() = %v, want nil", a.RequiredFeatures())
+	}
+}
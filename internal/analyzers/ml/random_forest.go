@@ -0,0 +1,259 @@
+/*
+ * @Date: 2025-06-05 10:26:14
+ * @Editors: Mr wpl
+ * @Description: 随机森林模型预测 (纯 Go)，使用与 svm_prosses 相同的 8 大统计特征 + 朴素贝叶斯评分
+ */
+package ml
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RandomForestModelFile 随机森林模型文件名 (位于 data_paths.models 下)
+const RandomForestModelFile = "RF.model.json"
+
+// RandomForestFeatureNames 模型输入特征顺序，与 SvmProssesAnalyzer 一致
+var RandomForestFeatureNames = []string{"LM", "LVC", "WM", "WVC", "SR", "TR", "SPL", "IE", "BAYES"}
+
+// TreeNode 决策树节点。Feature < 0 表示叶子节点，Value 为该叶子的 webshell 概率
+type TreeNode struct {
+	Feature   int     `json:"feature"`
+	Threshold float64 `json:"threshold"` // 特征值 <= Threshold 走左子树
+	Left      int     `json:"left"`
+	Right     int     `json:"right"`
+	Value     float64 `json:"value"`
+}
+
+// DecisionTree 以节点数组表示的决策树，下标 0 为根节点
+type DecisionTree struct {
+	Nodes []TreeNode `json:"nodes"`
+}
+
+// RandomForestModel RF.model.json 的内容
+type RandomForestModel struct {
+	FeatureNames []string       `json:"feature_names"`
+	Threshold    float64        `json:"threshold"` // 平均概率达到该值时判定为 webshell
+	Trees        []DecisionTree `json:"trees"`
+}
+
+/**
+ * @Description: 计算单棵树对特征向量的预测值
+ * @author: Mr wpl
+ * @param x []float64: 特征向量
+ * @return float64: 叶子节点的 webshell 概率
+ * @return error: 树结构无效时返回错误
+ */
+func (t *DecisionTree) Predict(x []float64) (float64, error) {
+	idx := 0
+	for steps := 0; steps <= len(t.Nodes); steps++ {
+		if idx < 0 || idx >= len(t.Nodes) {
+			return 0, fmt.Errorf("node index %d out of range", idx)
+		}
+		node := t.Nodes[idx]
+		if node.Feature < 0 {
+			return node.Value, nil
+		}
+		if node.Feature >= len(x) {
+			return 0, fmt.Errorf("feature index %d out of range", node.Feature)
+		}
+		if x[node.Feature] <= node.Threshold {
+			idx = node.Left
+		} else {
+			idx = node.Right
+		}
+	}
+	return 0, fmt.Errorf("tree contains a cycle")
+}
+
+/**
+ * @Description: 对所有树的叶子预测值取平均
+ * @author: Mr wpl
+ * @param x []float64: 特征向量
+ * @return float64: webshell 概率
+ * @return error: 错误
+ */
+func (m *RandomForestModel) Predict(x []float64) (float64, error) {
+	if len(m.Trees) == 0 {
+		return 0, fmt.Errorf("random forest has no trees")
+	}
+	sum := 0.0
+	for i := range m.Trees {
+		p, err := m.Trees[i].Predict(x)
+		if err != nil {
+			return 0, fmt.Errorf("tree %d: %w", i, err)
+		}
+		sum += p
+	}
+	return sum / float64(len(m.Trees)), nil
+}
+
+/**
+ * @Description: 从 JSON 文件加载随机森林模型
+ * @author: Mr wpl
+ * @param path string: 模型文件路径
+ * @return *RandomForestModel: 模型
+ * @return error: 错误
+ */
+func LoadRandomForestModel(path string) (*RandomForestModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	model := &RandomForestModel{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, fmt.Errorf("解析随机森林模型失败: %w", err)
+	}
+	if len(model.Trees) == 0 {
+		return nil, fmt.Errorf("随机森林模型不包含任何树")
+	}
+	if model.Threshold <= 0 || model.Threshold >= 1 {
+		model.Threshold = 0.5
+	}
+	return model, nil
+}
+
+/**
+ * @Description: 将模型保存为 JSON 文件
+ * @author: Mr wpl
+ * @param path string: 模型文件路径
+ * @return error: 错误
+ */
+func (m *RandomForestModel) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// RandomForestAnalyzer 随机森林分析器，不依赖 libsvm
+type RandomForestAnalyzer struct {
+	model      *RandomForestModel
+	bayesModel *BayesWordsAnalyzer
+}
+
+/**
+ * @Description: 初始化随机森林分析器，模型文件不存在时分析器处于非活动状态
+ * @author: Mr wpl
+ * @param modelPath string: 模型目录
+ * @return *RandomForestAnalyzer: 分析器实例
+ * @return error: 错误信息
+ */
+func NewRandomForestAnalyzer(modelPath string) (*RandomForestAnalyzer, error) {
+	analyzer := &RandomForestAnalyzer{}
+
+	model, err := LoadRandomForestModel(filepath.Join(modelPath, RandomForestModelFile))
+	if err != nil {
+		logging.WarnLogger.Printf("加载随机森林模型失败: %v，分析器将处于非活动状态。", err)
+		return analyzer, nil
+	}
+	analyzer.model = model
+
+	bayesModel, err := NewBayesWordsAnalyzer(modelPath)
+	if err != nil {
+		logging.WarnLogger.Printf("加载朴素贝叶斯模型失败: %v，BAYES 特征将使用默认值。", err)
+	}
+	analyzer.bayesModel = bayesModel
+
+	logging.InfoLogger.Printf("成功加载随机森林模型: %d 棵树, 阈值 %.2f", len(model.Trees), model.Threshold)
+	return analyzer, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *RandomForestAnalyzer) Name() string {
+	return "random_forest"
+}
+
+/**
+ * @Description: 返回此分析器所需的特征，AST 词汇可选 (用于 BAYES 特征)
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *RandomForestAnalyzer) RequiredFeatures() []string {
+	return []string{"statistical"}
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，模型基于 PHP 样本训练，只用于 PHP 文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *RandomForestAnalyzer) SupportedExtensions() []string {
+	return types.PHPExtensions
+}
+
+/**
+ * @Description: 实现Analyzer接口的Analyze方法
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *RandomForestAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	if a.model == nil {
+		return nil, nil
+	}
+	if featureSet == nil || featureSet.Statistical == nil {
+		return nil, fmt.Errorf("RandomForestAnalyzer: 缺少必需的statistical特征集")
+	}
+
+	x := FusionFeatureVector(featureSet.Statistical, bayesScore(a.bayesModel, fileInfo, content, featureSet))
+	score, err := a.model.Predict(x)
+	if err != nil {
+		return nil, fmt.Errorf("随机森林预测失败: %w", err)
+	}
+
+	if score < a.model.Threshold {
+		return nil, nil
+	}
+	return &types.Finding{
+		AnalyzerName: a.Name(),
+		Description:  fmt.Sprintf("随机森林检测到可疑代码 (8大统计特征+朴素贝叶斯评分, %d 棵树平均概率: %.4f)", len(a.model.Trees), score),
+		Risk:         types.RiskHigh,
+		Confidence:   score,
+		Severity:     types.SeverityHigh,
+	}, nil
+}
+
+/**
+ * @Description: 按 RandomForestFeatureNames 的顺序组装原始 (未标准化) 特征向量
+ * @author: Mr wpl
+ * @param stats *features.StatisticalFeatures: 统计特征
+ * @param bayes float64: 朴素贝叶斯评分
+ * @return []float64: 特征向量
+ */
+func FusionFeatureVector(stats *features.StatisticalFeatures, bayes float64) []float64 {
+	return []float64{stats.LM, stats.LVC, stats.WM, stats.WVC, stats.SR, stats.TR, stats.SPL, stats.IE, bayes}
+}
+
+/**
+ * @Description: 获取朴素贝叶斯评分作为融合特征，模型或 AST 词汇不可用时返回 0.5
+ * @author: Mr wpl
+ * @param bayesModel *BayesWordsAnalyzer: 朴素贝叶斯模型
+ * @param fileInfo types.FileInfo: 文件信息
+ * @param content []byte: 文件内容
+ * @param featureSet *features.FeatureSet: 特征集
+ * @return float64: 评分
+ */
+func bayesScore(bayesModel *BayesWordsAnalyzer, fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) float64 {
+	if bayesModel == nil || len(featureSet.ASTWords) == 0 {
+		return 0.5
+	}
+	finding, err := bayesModel.Analyze(fileInfo, content, featureSet)
+	if err != nil || finding == nil {
+		return 0.5
+	}
+	return finding.Confidence
+}
@@ -357,6 +357,15 @@ func (s *SvmProssesAnalyzer) RequiredFeatures() []string {
 	return []string{"statistical", "ast_words"}
 }
 
+/**
+ * @Description: 返回分析器支持的文件扩展名，模型基于 PHP 样本训练，只用于 PHP 文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (s *SvmProssesAnalyzer) SupportedExtensions() []string {
+	return types.PHPExtensions
+}
+
 /**
  * @Description: 实现Analyzer接口的Analyze方法
  * @author: Mr wpl
@@ -404,6 +413,7 @@ func (s *SvmProssesAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, fe
 			Description:  description,
 			Risk:         types.RiskHigh,
 			Confidence:   confidence,
+			Severity:     types.SeverityHigh,
 		}, nil
 	}
 
@@ -419,21 +429,21 @@ func (s *SvmProssesAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, fe
  * @return error 错误信息
  */
 func (s *SvmProssesAnalyzer) extractFeatures(filepath string, content []byte, featureSet *features.FeatureSet) (map[int]float64, error) {
-	features := make(map[int]float64)
+	return s.normalizeVector(s.rawFeatureVector(filepath, content, featureSet)), nil
+}
 
+/**
+ * @Description: 计算未标准化的特征向量：8个统计特征 (LM, LVC, WM, WVC, SR, TR, SPL, IE) 和朴素贝叶斯评分
+ * @author: Mr wpl
+ * @param filepath 文件路径
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return []float64 原始特征值，顺序与 featureNames 一致
+ */
+func (s *SvmProssesAnalyzer) rawFeatureVector(filepath string, content []byte, featureSet *features.FeatureSet) []float64 {
 	// 1. 从featureSet获取8个统计特征
 	statFeatures := featureSet.Statistical
 
-	// 将8个统计特征添加到特征映射
-	features[1] = s.normalizeFeature(float64(statFeatures.LM), 0, 1)  // 行长度最大值
-	features[2] = s.normalizeFeature(float64(statFeatures.LVC), 1, 2) // 行变异系数
-	features[3] = s.normalizeFeature(float64(statFeatures.WM), 2, 3)  // 词长度最大值
-	features[4] = s.normalizeFeature(float64(statFeatures.WVC), 3, 4) // 词变异系数
-	features[5] = s.normalizeFeature(float64(statFeatures.SR), 4, 5)  // 符号比率
-	features[6] = s.normalizeFeature(float64(statFeatures.TR), 5, 6)  // 标签比率
-	features[7] = s.normalizeFeature(float64(statFeatures.SPL), 6, 7) // 每行语句数
-	features[8] = s.normalizeFeature(float64(statFeatures.IE), 7, 8)  // 信息熵
-
 	// 2. 获取朴素贝叶斯分数作为特征
 	var bayesScore float64 = 0.5
 	if s.bayesModel != nil && featureSet.ASTWords != nil && len(featureSet.ASTWords) > 0 {
@@ -449,9 +459,92 @@ func (s *SvmProssesAnalyzer) extractFeatures(filepath string, content []byte, fe
 		logging.InfoLogger.Printf("朴素贝叶斯模型不可用或AST词汇为空，使用默认评分0.5")
 	}
 
-	features[9] = s.normalizeFeature(bayesScore, 8, 9)
+	return []float64{
+		float64(statFeatures.LM),  // 行长度最大值
+		float64(statFeatures.LVC), // 行变异系数
+		float64(statFeatures.WM),  // 词长度最大值
+		float64(statFeatures.WVC), // 词变异系数
+		float64(statFeatures.SR),  // 符号比率
+		float64(statFeatures.TR),  // 标签比率
+		float64(statFeatures.SPL), // 每行语句数
+		float64(statFeatures.IE),  // 信息熵
+		bayesScore,
+	}
+}
+
+/**
+ * @Description: 标准化原始特征向量，转为 libsvm 的特征映射 (索引从1开始)
+ * @author: Mr wpl
+ * @param raw 原始特征值
+ * @return map[int]float64 特征
+ */
+func (s *SvmProssesAnalyzer) normalizeVector(raw []float64) map[int]float64 {
+	features := make(map[int]float64, len(raw))
+	for i, value := range raw {
+		// 沿用原有的索引约定：均值取 Means[i]，标准差取 Stds[i+1]
+		features[i+1] = s.normalizeFeature(value, i, i+1)
+	}
+	return features
+}
+
+// FeatureContribution 单个特征对 SVM 决策的影响
+type FeatureContribution struct {
+	Name         string  // 特征名称
+	Raw          float64 // 原始值
+	Normalized   float64 // 标准化后的值
+	Contribution float64 // 原始决策值 - 将该特征置零 (即取训练集均值) 后的决策值
+}
+
+/**
+ * @Description: 解释 SVM 对文件的判定：逐个将标准化后的特征置零，以决策值的变化近似该特征的贡献 (排列重要性)
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return float64 sigmoid 分数
+ * @return float64 原始决策值
+ * @return []FeatureContribution 各特征的贡献，顺序与 featureNames 一致
+ * @return error 错误信息
+ */
+func (s *SvmProssesAnalyzer) Explain(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (float64, float64, []FeatureContribution, error) {
+	if !s.isInitialized || s.model == nil {
+		return 0, 0, nil, fmt.Errorf("SVM模型未加载")
+	}
+	if featureSet == nil || featureSet.Statistical == nil {
+		return 0, 0, nil, fmt.Errorf("SvmProssesAnalyzer: 缺少必需的statistical特征集")
+	}
+
+	raw := s.rawFeatureVector(fileInfo.Path, content, featureSet)
+	normalized := s.normalizeVector(raw)
+	score, rawDecision, err := s.predict(normalized)
+	if err != nil {
+		return 0, 0, nil, err
+	}
 
-	return features, nil
+	contributions := make([]FeatureContribution, len(raw))
+	for i := range raw {
+		perturbed := make(map[int]float64, len(normalized))
+		for k, v := range normalized {
+			perturbed[k] = v
+		}
+		perturbed[i+1] = 0
+		_, zeroedDecision, err := s.predict(perturbed)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		name := fmt.Sprintf("F%d", i+1)
+		if i < len(s.featureNames) {
+			name = s.featureNames[i]
+		}
+		contributions[i] = FeatureContribution{
+			Name:         name,
+			Raw:          raw[i],
+			Normalized:   normalized[i+1],
+			Contribution: rawDecision - zeroedDecision,
+		}
+	}
+	return score, rawDecision, contributions, nil
 }
 
 /**
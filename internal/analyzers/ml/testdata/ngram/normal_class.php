@@ -0,0 +1,28 @@
+<?php
+
+namespace App\Service;
+
+class Cart
+{
+    private array $items = [];
+
+    public function add(string $sku, int $quantity): void
+    {
+        $this->items[$sku] = ($this->items[$sku] ?? 0) + $quantity;
+    }
+
+    public function remove(string $sku): void
+    {
+        unset($this->items[$sku]);
+    }
+
+    public function count(): int
+    {
+        return array_sum($this->items);
+    }
+
+    public function isEmpty(): bool
+    {
+        return $this->count() === 0;
+    }
+}
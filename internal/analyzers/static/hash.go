@@ -19,35 +19,45 @@ import (
 )
 
 type HashAnalyzer struct {
-	analyzerName string // Renamed field to avoid conflict
-	badHashes    map[string]bool
+	analyzerName string   // Renamed field to avoid conflict
+	badHashes    *HashSet // 布隆过滤器预筛选 + 精确比对，千万级哈希不再占用 map 的内存
 }
 
 /**
  * @Description: 创建HashAnalyzer实例
  * @author: Mr wpl
  * @param dataPath 数据路径
+ * @param fpRate 布隆过滤器目标误判率，0 时使用 DefaultHashFPRate
  * @return *HashAnalyzer 哈希分析器实例
  * @return error 错误信息
  */
-func NewHashAnalyzer(dataPath string) (*HashAnalyzer, error) {
-	hashes := make(map[string]bool)
+func NewHashAnalyzer(dataPath string, fpRate float64) (*HashAnalyzer, error) {
+	if fpRate < 0 || fpRate >= 1 {
+		return nil, fmt.Errorf("invalid hash.fp_rate %v: must be between 0 and 1", fpRate)
+	}
 	hashFilePath := filepath.Join(dataPath, "SampleHash.txt")
 	file, err := os.Open(hashFilePath)
 	if err != nil {
 		logging.WarnLogger.Printf("Hash signature file not found at %s: %v. Hash analyzer will be inactive.", hashFilePath, err)
-		return &HashAnalyzer{analyzerName: "hash", badHashes: hashes}, nil // Use renamed field here
+		return &HashAnalyzer{analyzerName: "hash", badHashes: NewHashSet(nil, fpRate)}, nil // Use renamed field here
 	}
 	defer file.Close()
 
+	// 以 32 字节摘要保存，避免每条 64 字符的十六进制字符串及 map 的开销
+	var digests [][sha256.Size]byte
 	scanner := bufio.NewScanner(file)
 	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
 		hash := strings.TrimSpace(scanner.Text())
+		var digest [sha256.Size]byte
 		if len(hash) == 64 {
-			hashes[strings.ToLower(hash)] = true
-		} else if hash != "" && !strings.HasPrefix(hash, "#") {
+			if _, err := hex.Decode(digest[:], []byte(hash)); err == nil {
+				digests = append(digests, digest)
+				continue
+			}
+		}
+		if hash != "" && !strings.HasPrefix(hash, "#") {
 			logging.WarnLogger.Printf("Invalid hash format on line %d in %s: %s", lineNum, hashFilePath, hash)
 		}
 	}
@@ -55,8 +65,9 @@ func NewHashAnalyzer(dataPath string) (*HashAnalyzer, error) {
 		logging.ErrorLogger.Printf("Error reading hash file %s: %v", hashFilePath, err)
 	}
 
-	logging.InfoLogger.Printf("Loaded %d bad hashes from %s", len(hashes), hashFilePath)
-	return &HashAnalyzer{analyzerName: "hash", badHashes: hashes}, nil // Use renamed field here
+	badHashes := NewHashSet(digests, fpRate)
+	logging.InfoLogger.Printf("Loaded %d bad hashes from %s (%d KB)", badHashes.Len(), hashFilePath, badHashes.SizeBytes()/1024)
+	return &HashAnalyzer{analyzerName: "hash", badHashes: badHashes}, nil // Use renamed field here
 }
 	
 /**
@@ -77,6 +88,15 @@ func (a *HashAnalyzer) RequiredFeatures() []string {
 	return []string{}
 }
 
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *HashAnalyzer) SupportedExtensions() []string {
+	return nil
+}
+
 /**
  * @Description: 分析文件
  * @author: Mr wpl
@@ -85,25 +105,35 @@ func (a *HashAnalyzer) RequiredFeatures() []string {
  * @param featureSet 特征集
  */
 func (a *HashAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
-	if len(a.badHashes) == 0 {
+	if a.badHashes.Len() == 0 {
 		return nil, nil
 	}
 
-	hasher := sha256.New()
-	if _, err := hasher.Write(content); err != nil {
-		return nil, fmt.Errorf("failed to calculate hash: %w", err)
-	}
-	hashString := hex.EncodeToString(hasher.Sum(nil))
+	// 样本库记录的是原始文件的哈希，不使用转码后的内容
+	digest := sha256.Sum256(featureSet.OriginalContent(content))
+	return a.MatchDigest(fileInfo, digest, featureSet), nil
+}
 
-	if a.badHashes[strings.ToLower(hashString)] {
-		logging.InfoLogger.Printf("Hash match found for %s", fileInfo.Path)
-		return &types.Finding{
-			AnalyzerName: a.analyzerName, // Use renamed field here
-			Description:  fmt.Sprintf("Matched known bad file hash: %s", hashString),
-			Risk:         types.RiskCritical,
-			Confidence:   1.0,
-		}, nil
+/**
+ * @Description: 按完整内容的 SHA-256 匹配已知样本，供分段扫描的大文件使用流式计算的摘要
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param digest 原始内容的 SHA-256
+ * @param featureSet 特征集
+ * @return *types.Finding 匹配时的发现，否则为 nil
+ */
+func (a *HashAnalyzer) MatchDigest(fileInfo types.FileInfo, digest [sha256.Size]byte, featureSet *features.FeatureSet) *types.Finding {
+	if a.badHashes.Len() == 0 || !a.badHashes.Contains(digest) {
+		return nil
+	}
+	hashString := hex.EncodeToString(digest[:])
+	featureSet.Logger().Infof("Hash match found for %s", fileInfo.Path)
+	return &types.Finding{
+		AnalyzerName: a.analyzerName, // Use renamed field here
+		Description:  fmt.Sprintf("Matched known bad file hash: %s", hashString),
+		Risk:         types.RiskCritical,
+		Confidence:   1.0,
+		Severity:     types.SeverityCritical,
+		CVSSVector:   types.WebshellRCEVector, // 已知 webshell 样本
 	}
-
-	return nil, nil
 }
@@ -0,0 +1,82 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 已知哈希集合：布隆过滤器预筛选 + 有序摘要表精确比对，千万级哈希只占摘要本身 (32 字节/条) 的内存
+ */
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/bits-and-blooms/bloom/v3"
+)
+
+// DefaultHashFPRate 未配置 hash.fp_rate 时布隆过滤器的目标误判率
+const DefaultHashFPRate = 0.001
+
+/**
+ * @Description: 已知 SHA-256 集合。布隆过滤器判定不存在的摘要直接返回 (绝大多数文件)；
+ * 判定可能存在时在有序摘要表中二分查找，排除布隆过滤器的误判。
+ * 创建后只读，Contains 可并发调用
+ * @author: Mr wpl
+ */
+type HashSet struct {
+	digests [][sha256.Size]byte // 升序、无重复
+	filter  *bloom.BloomFilter
+}
+
+/**
+ * @Description: 创建哈希集合，digests 会被原地排序去重，调用方之后不应再修改
+ * @author: Mr wpl
+ * @param digests [][sha256.Size]byte: SHA-256 摘要
+ * @param fpRate float64: 布隆过滤器目标误判率，不在 (0, 1) 内时使用 DefaultHashFPRate
+ * @return *HashSet: 哈希集合
+ */
+func NewHashSet(digests [][sha256.Size]byte, fpRate float64) *HashSet {
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = DefaultHashFPRate
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return bytes.Compare(digests[i][:], digests[j][:]) < 0
+	})
+	unique := digests[:0]
+	for i, digest := range digests {
+		if i == 0 || digest != digests[i-1] {
+			unique = append(unique, digest)
+		}
+	}
+
+	filter := bloom.NewWithEstimates(uint(len(unique)), fpRate)
+	for i := range unique {
+		filter.Add(unique[i][:])
+	}
+	return &HashSet{digests: unique, filter: filter}
+}
+
+/**
+ * @Description: 判断摘要是否在集合中 (精确，无误判)
+ * @author: Mr wpl
+ * @param digest [sha256.Size]byte: SHA-256 摘要
+ * @return bool: 是否在集合中
+ */
+func (s *HashSet) Contains(digest [sha256.Size]byte) bool {
+	if len(s.digests) == 0 || !s.filter.Test(digest[:]) {
+		return false
+	}
+	i := sort.Search(len(s.digests), func(i int) bool {
+		return bytes.Compare(s.digests[i][:], digest[:]) >= 0
+	})
+	return i < len(s.digests) && s.digests[i] == digest
+}
+
+// Len 集合中的哈希数
+func (s *HashSet) Len() int {
+	return len(s.digests)
+}
+
+// SizeBytes 摘要表与布隆过滤器占用的字节数
+func (s *HashSet) SizeBytes() int {
+	return len(s.digests)*sha256.Size + int(s.filter.Cap()+7)/8
+}
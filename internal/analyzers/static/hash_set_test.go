@@ -0,0 +1,154 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 已知哈希集合测试：精确判定、布隆过滤器误判率，以及千万条哈希时与原 map 实现的基准比较
+ */
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// testDigest 第 i 个测试摘要，set 区分不同集合
+func testDigest(set byte, i int) [sha256.Size]byte {
+	var buf [9]byte
+	buf[0] = set
+	binary.BigEndian.PutUint64(buf[1:], uint64(i))
+	return sha256.Sum256(buf[:])
+}
+
+func TestHashSetContains(t *testing.T) {
+	known := [][sha256.Size]byte{testDigest('a', 3), testDigest('a', 1), testDigest('a', 2), testDigest('a', 1)}
+	set := NewHashSet(known, 0)
+	if set.Len() != 3 {
+		t.Errorf("Len() = %d, want 3 after removing the duplicate", set.Len())
+	}
+
+	tests := []struct {
+		name   string
+		digest [sha256.Size]byte
+		want   bool
+	}{
+		{"first", testDigest('a', 1), true},
+		{"last", testDigest('a', 3), true},
+		{"unknown", testDigest('b', 1), false},
+		{"zero digest", [sha256.Size]byte{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := set.Contains(tt.digest); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	empty := NewHashSet(nil, 0)
+	if empty.Contains(testDigest('a', 1)) || empty.Len() != 0 {
+		t.Error("empty set reports a digest as present")
+	}
+}
+
+// TestHashSetFalsePositiveRate 10 万条哈希、目标 0.1% 时，另外 10 万个未知摘要中通过布隆过滤器的比例低于 0.1%，
+// 且有序摘要表排除全部误判
+func TestHashSetFalsePositiveRate(t *testing.T) {
+	const items, fpRate = 100000, 0.001
+	digests := make([][sha256.Size]byte, items)
+	for i := range digests {
+		digests[i] = testDigest('a', i)
+	}
+	set := NewHashSet(digests, fpRate)
+
+	for i := 0; i < items; i++ {
+		if !set.Contains(testDigest('a', i)) {
+			t.Fatalf("Contains() = false for known digest %d", i)
+		}
+	}
+
+	const probes = 100000
+	falsePositives := 0
+	for i := 0; i < probes; i++ {
+		digest := testDigest('b', i)
+		if set.filter.Test(digest[:]) {
+			falsePositives++
+		}
+		if set.Contains(digest) {
+			t.Fatalf("Contains() = true for unknown digest %d", i)
+		}
+	}
+	rate := float64(falsePositives) / probes
+	t.Logf("bloom false positives: %d/%d (%.4f%%), %d bytes", falsePositives, probes, rate*100, set.SizeBytes())
+	if rate >= fpRate {
+		t.Errorf("false positive rate = %.4f%%, want < %.4f%%", rate*100, fpRate*100)
+	}
+}
+
+// BenchmarkHashSet 千万条已知哈希时比较原 map[string]bool 实现与 HashSet 的查找耗时和堆内存，
+// 查询中 1% 为已知哈希 (大多数被扫描的文件不在库中)。-short 时使用 10 万条
+func BenchmarkHashSet(b *testing.B) {
+	count := 10_000_000
+	if testing.Short() {
+		count = 100_000
+	}
+	rng := rand.New(rand.NewSource(1))
+	known := make([][sha256.Size]byte, count)
+	for i := range known {
+		rng.Read(known[i][:])
+	}
+	queries := make([][sha256.Size]byte, 1<<16)
+	for i := range queries {
+		if rng.Float64() < 0.01 {
+			queries[i] = known[rng.Intn(len(known))]
+		} else {
+			rng.Read(queries[i][:])
+		}
+	}
+
+	// 各实现只构建一次，b.Run 以不同的 b.N 多次调用时复用
+	var badHashes map[string]bool
+	var mapHeap uint64
+	b.Run("map", func(b *testing.B) {
+		if badHashes == nil {
+			before := heapInUse()
+			badHashes = make(map[string]bool, len(known))
+			for _, digest := range known {
+				badHashes[hex.EncodeToString(digest[:])] = true
+			}
+			mapHeap = heapInUse() - before
+			b.ResetTimer()
+		}
+		for i := 0; i < b.N; i++ {
+			digest := queries[i%len(queries)]
+			_ = badHashes[hex.EncodeToString(digest[:])]
+		}
+		b.ReportMetric(float64(mapHeap)/(1<<20), "heap-MB")
+	})
+	badHashes = nil
+
+	var set *HashSet
+	b.Run("bloom+sorted", func(b *testing.B) {
+		if set == nil {
+			// NewHashSet 原地排序，传入副本以免影响 known
+			digests := make([][sha256.Size]byte, len(known))
+			copy(digests, known)
+			set = NewHashSet(digests, DefaultHashFPRate)
+			b.ResetTimer()
+		}
+		for i := 0; i < b.N; i++ {
+			set.Contains(queries[i%len(queries)])
+		}
+		b.ReportMetric(float64(set.SizeBytes())/(1<<20), "heap-MB")
+	})
+}
+
+// heapInUse GC 后正在使用的堆内存
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
@@ -0,0 +1,68 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 哈希分析器测试：GBK、Latin-1 与带 BOM 的样本按转码前的原始字节匹配 SampleHash.txt
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashAnalyzerOriginalContent(t *testing.T) {
+	samples := []struct {
+		name string
+		raw  []byte
+	}{
+		// $用户 = $_POST['x']; (GBK)
+		{"gbk", []byte("<?php $\xd3\xc3\xbb\xa7 = $_POST['x']; eval($\xd3\xc3\xbb\xa7);")},
+		{"latin1", []byte("<?php // caf\xe9\neval($_POST['x']);")},
+		{"utf8 bom", []byte("\xef\xbb\xbf<?php eval($_POST['x']);")},
+	}
+
+	dir := t.TempDir()
+	var list []byte
+	for _, s := range samples {
+		sum := sha256.Sum256(s.raw)
+		list = append(list, hex.EncodeToString(sum[:])+"\n"...)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SampleHash.txt"), list, 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewHashAnalyzer(dir, 0)
+	if err != nil {
+		t.Fatalf("NewHashAnalyzer() error = %v", err)
+	}
+
+	for _, s := range samples {
+		t.Run(s.name, func(t *testing.T) {
+			transcoded, _, err := features.DetectAndTranscode(s.raw)
+			if err != nil {
+				t.Fatalf("DetectAndTranscode() error = %v", err)
+			}
+			if string(transcoded) == string(s.raw) {
+				t.Fatalf("sample was not transcoded")
+			}
+			fileInfo := types.FileInfo{Path: "/www/" + s.name + ".php"}
+
+			finding, err := a.Analyze(fileInfo, transcoded, &features.FeatureSet{RawContent: s.raw})
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if finding == nil || finding.Risk != types.RiskCritical {
+				t.Errorf("Analyze() with the original bytes = %+v, want a Critical hash match", finding)
+			}
+
+			// 只有转码后的内容时哈希不同，无法匹配
+			if finding, _ := a.Analyze(fileInfo, transcoded, &features.FeatureSet{}); finding != nil {
+				t.Errorf("Analyze() with transcoded bytes only = %+v, want no match", finding)
+			}
+		})
+	}
+}
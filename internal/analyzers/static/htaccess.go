@@ -0,0 +1,120 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: .htaccess 恶意配置检测：攻击者写入 SetHandler/AddHandler、auto_prepend_file 等指令让任意文件作为 PHP 或 CGI 执行
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// htaccessRules 可疑的 .htaccess 指令，只匹配行首的指令 (忽略 # 注释行)
+var htaccessRules = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"SetHandler executes files as PHP", regexp.MustCompile(`(?im)^[ \t]*SetHandler[ \t]+[^\n]*php`)},
+	{"AddHandler executes files as PHP", regexp.MustCompile(`(?im)^[ \t]*AddHandler[ \t]+[^\n]*php`)},
+	{"AddType maps extensions to PHP", regexp.MustCompile(`(?im)^[ \t]*AddType[ \t]+[^\n]*x-httpd-php`)},
+	{"php_value auto_prepend_file/auto_append_file", regexp.MustCompile(`(?im)^[ \t]*php_(?:admin_)?value[ \t]+auto_(?:prepend|append)_file\b`)},
+	// Options -ExecCGI 是关闭 CGI，不匹配
+	{"Options enables ExecCGI", regexp.MustCompile(`(?im)^[ \t]*Options[ \t]+(?:[^\n#]*[ \t+])?ExecCGI\b`)},
+	// 以 %{HTTP_HOST}/%{SERVER_NAME} 拼接的目标是本站的规范化跳转 (http -> https 等)，不匹配
+	{"RewriteRule to an absolute URL (possible RFI or spam redirect)", regexp.MustCompile(`(?im)^[ \t]*RewriteRule[ \t]+\S+[ \t]+"?(?:https?|ftp)://[^%\s]`)},
+	{"Allow from all opens access", regexp.MustCompile(`(?im)^[ \t]*Allow[ \t]+from[ \t]+all\b`)},
+}
+
+// IsHtaccessFile 是否为 .htaccess 文件，这类文件不交给 PHP 桥接解析
+func IsHtaccessFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), types.HtaccessFileName)
+}
+
+/**
+ * @Description: .htaccess 分析器，只处理 .htaccess 文件 (需开启 scan_htaccess)
+ * @author: Mr wpl
+ */
+type HtaccessAnalyzer struct {
+	analyzerName string
+}
+
+/**
+ * @Description: 创建HtaccessAnalyzer实例
+ * @author: Mr wpl
+ * @return *HtaccessAnalyzer .htaccess 分析器实例
+ * @return error 错误信息
+ */
+func NewHtaccessAnalyzer() (*HtaccessAnalyzer, error) {
+	return &HtaccessAnalyzer{analyzerName: "htaccess"}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *HtaccessAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征，直接匹配文件内容
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *HtaccessAnalyzer) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *HtaccessAnalyzer) SupportedExtensions() []string {
+	return []string{types.HtaccessFileName}
+}
+
+/**
+ * @Description: 检查所有可疑指令，任一命中即报告 High，描述列出全部命中的指令，代码片段取第一处命中
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *HtaccessAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	var matched []string
+	var first []int
+	for _, rule := range htaccessRules {
+		loc := rule.pattern.FindIndex(content)
+		if loc == nil {
+			continue
+		}
+		matched = append(matched, rule.name)
+		if first == nil || loc[0] < first[0] {
+			first = loc
+		}
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	featureSet.Logger().Infof("Suspicious .htaccess directives found in %s: %s", fileInfo.Path, strings.Join(matched, "; "))
+	finding := &types.Finding{
+		AnalyzerName: a.analyzerName,
+		Description:  fmt.Sprintf("Suspicious .htaccess directives: %s", strings.Join(matched, "; ")),
+		Risk:         types.RiskHigh,
+		Confidence:   0.8,
+		Severity:     types.SeverityHigh,
+		Metadata:     map[string]interface{}{"directives": matched},
+	}
+	attachSnippet(finding, content, first[0], first[1])
+	return finding, nil
+}
@@ -0,0 +1,125 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: JSP 统计特征检测：按 JSP 分词计算 8 个统计特征，异常且调用危险 Java API 时报告
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// jspDangerousCalls JSP 木马常用的命令执行、动态类加载与脚本引擎调用，作用相当于 PHP 的 callable 特征
+var jspDangerousCalls = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Runtime.exec", regexp.MustCompile(`Runtime\s*\.\s*getRuntime\s*\(\s*\)\s*\.\s*exec\s*\(`)},
+	{"ProcessBuilder", regexp.MustCompile(`new\s+(?:java\.lang\.)?ProcessBuilder\s*\(`)},
+	{"defineClass", regexp.MustCompile(`\bdefineClass\s*\(`)},
+	{"ScriptEngine", regexp.MustCompile(`\bScriptEngineManager\b|\bgetEngineByName\s*\(`)},
+	{"Class.forName", regexp.MustCompile(`\bClass\s*\.\s*forName\s*\(`)},
+}
+
+// IsJSPFile 是否为 JSP 文件 (.jsp、.jspx)，这类文件不交给 PHP 桥接解析
+func IsJSPFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsp", ".jspx":
+		return true
+	}
+	return false
+}
+
+/**
+ * @Description: JSP 统计分析器。与 statistical 使用相同的阈值，统计特征按 JSP 分词 (<% %> 脚本片段为标签) 计算，
+ * 统计特征异常且存在危险 Java 调用时报告 Medium；非 JSP 文件直接跳过
+ * @author: Mr wpl
+ */
+type JSPStatisticalAnalyzer struct {
+	thresholds StatisticalThresholds
+}
+
+/**
+ * @Description: 创建JSPStatisticalAnalyzer实例
+ * @author: Mr wpl
+ * @return *JSPStatisticalAnalyzer JSP 统计分析器实例
+ * @return error 错误信息
+ */
+func NewJSPStatisticalAnalyzer() (*JSPStatisticalAnalyzer, error) {
+	return &JSPStatisticalAnalyzer{thresholds: GetDefaultStatisticalThresholds()}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *JSPStatisticalAnalyzer) Name() string {
+	return "jsp_statistical"
+}
+
+/**
+ * @Description: 返回分析器所需的特征。统计特征由分析器按 JSP 分词自行计算，不依赖特征集
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *JSPStatisticalAnalyzer) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *JSPStatisticalAnalyzer) SupportedExtensions() []string {
+	return []string{".jsp", ".jspx"}
+}
+
+/**
+ * @Description: 计算 JSP 统计特征并检查危险 Java 调用
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集 (未使用)
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *JSPStatisticalAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	if !IsJSPFile(fileInfo.Path) || len(content) == 0 {
+		return nil, nil
+	}
+
+	var calls []string
+	for _, call := range jspDangerousCalls {
+		if call.pattern.Match(content) {
+			calls = append(calls, call.name)
+		}
+	}
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	stats, err := features.CalculateJSPStatisticalFeatures(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("jsp statistical features: %w", err)
+	}
+	if !IsStatisticalAbnormal(&stats, a.thresholds) {
+		return nil, nil
+	}
+
+	return &types.Finding{
+		AnalyzerName: a.Name(),
+		Description: fmt.Sprintf("JSP 文件存在统计特征异常且调用了危险 Java API %s (e.g., LM:%.0f, LVC:%.4f, WM:%.0f, WVC:%.2f, SR:%.2f, TR:%.2f, IE:%.4f)",
+			strings.Join(calls, ", "), stats.LM, stats.LVC, stats.WM, stats.WVC, stats.SR, stats.TR, stats.IE),
+		Risk:       types.RiskMedium,
+		Confidence: 0.7,
+		Severity:   types.SeverityMedium,
+	}, nil
+}
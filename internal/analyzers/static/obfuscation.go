@@ -0,0 +1,85 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: AST 结构混淆检测：动态变量名、编码字符串与深层嵌套调用 (eval 链) 组合得分过高的文件
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"fmt"
+)
+
+// obfuscationThreshold 混淆分数超过该值时报告 Medium
+const obfuscationThreshold = 0.7
+
+/**
+ * @Description: 结构混淆分析器，读取特征提取阶段计算的 ObfuscationScore
+ * @author: Mr wpl
+ */
+type ObfuscationAnalyzer struct {
+	analyzerName string
+}
+
+/**
+ * @Description: 创建ObfuscationAnalyzer实例
+ * @author: Mr wpl
+ * @return *ObfuscationAnalyzer 结构混淆分析器实例
+ * @return error 错误信息
+ */
+func NewObfuscationAnalyzer() (*ObfuscationAnalyzer, error) {
+	return &ObfuscationAnalyzer{analyzerName: "obfuscation"}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *ObfuscationAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *ObfuscationAnalyzer) RequiredFeatures() []string {
+	return []string{"obfuscation"}
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，依赖 PHP AST，只用于 PHP 文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *ObfuscationAnalyzer) SupportedExtensions() []string {
+	return types.PHPExtensions
+}
+
+/**
+ * @Description: 混淆分数超过 0.7 时报告 Medium
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *ObfuscationAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	score := featureSet.ObfuscationScore
+	if score <= obfuscationThreshold {
+		return nil, nil
+	}
+	featureSet.Logger().Infof("AST obfuscation score for %s: %.2f", fileInfo.Path, score)
+	return &types.Finding{
+		AnalyzerName: a.analyzerName,
+		Description:  fmt.Sprintf("Structurally obfuscated code (dynamic variable names, encoded strings, nested call chains): score %.2f", score),
+		Risk:         types.RiskMedium,
+		Confidence:   score,
+		Severity:     types.SeverityMedium,
+		Metadata:     map[string]interface{}{"obfuscation_score": score},
+	}, nil
+}
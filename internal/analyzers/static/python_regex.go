@@ -0,0 +1,112 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: Python 木马正则检测：针对 Django/Flask 等应用中上传的 Python webshell 的命令执行与动态代码执行模式
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// pythonRegexRules Python 高危模式，按风险从高到低排列，报告第一条命中的规则
+var pythonRegexRules = []struct {
+	name    string
+	pattern *regexp.Regexp
+	risk    types.RiskLevel
+}{
+	// 请求参数直接进入命令执行或动态执行
+	{"request input passed to command/code execution", regexp.MustCompile(`\b(?:os\.(?:system|popen)|subprocess\.\w+|eval|exec)\s*\(\s*(?:flask\.)?request\.(?:args|form|values|GET|POST|cookies|data|json)\b`), types.RiskCritical},
+	{"exec(compile(...))", regexp.MustCompile(`\bexec\s*\(\s*compile\s*\(`), types.RiskHigh},
+	{"__import__('os').popen/system", regexp.MustCompile(`__import__\s*\(\s*['"]os['"]\s*\)\s*\.\s*(?:popen|system)\s*\(`), types.RiskHigh},
+	{"eval/exec(base64.b64decode(...))", regexp.MustCompile(`\b(?:eval|exec)\s*\(\s*(?:base64\.)?b64decode\s*\(`), types.RiskHigh},
+	{"exec of decompressed code", regexp.MustCompile(`\b(?:eval|exec)\s*\(\s*(?:zlib\.decompress|marshal\.loads)\s*\(`), types.RiskHigh},
+	{"os.system", regexp.MustCompile(`\bos\.system\s*\(`), types.RiskMedium},
+	{"subprocess call", regexp.MustCompile(`\bsubprocess\.(?:call|run|Popen|check_output|check_call|getoutput|getstatusoutput)\s*\(`), types.RiskMedium},
+}
+
+// IsPythonFile 是否为 Python 文件 (.py)，这类文件不交给 PHP 桥接解析
+func IsPythonFile(path string) bool {
+	return features.FileTypeOf(path) == features.FileTypePython
+}
+
+/**
+ * @Description: Python 正则分析器，只处理 .py 文件
+ * @author: Mr wpl
+ */
+type PythonRegexAnalyzer struct {
+	analyzerName string
+}
+
+/**
+ * @Description: 创建PythonRegexAnalyzer实例
+ * @author: Mr wpl
+ * @return *PythonRegexAnalyzer Python 正则分析器实例
+ * @return error 错误信息
+ */
+func NewPythonRegexAnalyzer() (*PythonRegexAnalyzer, error) {
+	return &PythonRegexAnalyzer{analyzerName: "python_regex"}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *PythonRegexAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征，直接匹配文件内容
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *PythonRegexAnalyzer) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *PythonRegexAnalyzer) SupportedExtensions() []string {
+	return []string{".py"}
+}
+
+/**
+ * @Description: 按风险从高到低匹配 Python 高危模式，报告第一条命中的规则
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *PythonRegexAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	for _, rule := range pythonRegexRules {
+		loc := rule.pattern.FindIndex(content)
+		if loc == nil {
+			continue
+		}
+		featureSet.Logger().Infof("Python regex match found for %s (Rule: %s)", fileInfo.Path, rule.name)
+		severity := types.SeverityMedium
+		if rule.risk >= types.RiskHigh {
+			severity = types.SeverityHigh
+		}
+		return &types.Finding{
+			AnalyzerName: a.analyzerName,
+			Description:  fmt.Sprintf("Matched high-risk Python pattern: %s", rule.name),
+			Risk:         rule.risk,
+			Confidence:   0.9,
+			Severity:     severity,
+			Metadata:     map[string]interface{}{"line": bytes.Count(content[:loc[0]], []byte{'\n'}) + 1},
+		}, nil
+	}
+	return nil, nil
+}
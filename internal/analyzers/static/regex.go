@@ -4,12 +4,18 @@ import (
 	"bt-shieldml/internal/features"
 	"bt-shieldml/pkg/logging"
 	"bt-shieldml/pkg/types"
+	"bufio"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
 )
 
+// CustomRegexFile 自定义正则规则文件 (位于 data_paths.signatures 下)，每行一条，# 开头为注释
+const CustomRegexFile = "custom_rules.re"
+
 var highRiskRegexList []*regexp.Regexp
 var regexCompileOnce sync.Once
 var regexCompileErr error
@@ -88,22 +94,69 @@ func initializeRegexRules() {
  */
 type RegexAnalyzer struct {
 	analyzerName string // Renamed field
+	customRules  []*regexp.Regexp
 }
 
 /**
- * @Description: 创建RegexAnalyzer实例
+ * @Description: 创建RegexAnalyzer实例，并加载 dataPath 下的自定义规则 (custom_rules.re，可选)
  * @author: Mr wpl
+ * @param dataPath 规则目录 (data_paths.signatures)
  * @return *RegexAnalyzer 正则表达式分析器实例
  * @return error 错误信息
  */
-func NewRegexAnalyzer() (*RegexAnalyzer, error) {
+func NewRegexAnalyzer(dataPath string) (*RegexAnalyzer, error) {
 	initializeRegexRules()
 	if regexCompileErr != nil && len(highRiskRegexList) == 0 {
 		return nil, fmt.Errorf("regex analyzer failed to initialize: no rules compiled: %w", regexCompileErr)
 	} else if regexCompileErr != nil {
 		logging.WarnLogger.Printf("Regex analyzer initialized with %d rules, but some failed to compile: %v", len(highRiskRegexList), regexCompileErr)
 	}
-	return &RegexAnalyzer{analyzerName: "regex"}, nil // Use renamed field
+	customRules, err := loadCustomRegexRules(filepath.Join(dataPath, CustomRegexFile))
+	if err != nil {
+		return nil, err
+	}
+	return &RegexAnalyzer{analyzerName: "regex", customRules: customRules}, nil // Use renamed field
+}
+
+/**
+ * @Description: 加载自定义正则规则，文件不存在时返回空列表，无法编译的规则记录警告后跳过
+ * @author: Mr wpl
+ * @param path string: 规则文件
+ * @return []*regexp.Regexp: 规则
+ * @return error: 错误
+ */
+func loadCustomRegexRules(path string) ([]*regexp.Regexp, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open custom regex rules %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var rules []*regexp.Regexp
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := regexp.Compile(line)
+		if err != nil {
+			logging.WarnLogger.Printf("Skipping custom regex rule at %s:%d: %v", path, lineNo, err)
+			continue
+		}
+		rules = append(rules, re)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read custom regex rules %s: %w", path, err)
+	}
+	logging.InfoLogger.Printf("Loaded %d custom regex rules from %s", len(rules), path)
+	return rules, nil
 }
 
 /**
@@ -124,6 +177,15 @@ func (a *RegexAnalyzer) RequiredFeatures() []string {
 	return nil
 }
 
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *RegexAnalyzer) SupportedExtensions() []string {
+	return nil
+}
+
 /**
  * @Description: 分析文件
  * @author: Mr wpl
@@ -133,19 +195,26 @@ func (a *RegexAnalyzer) RequiredFeatures() []string {
  * @return *types.Finding 发现
  */
 func (a *RegexAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
-	if len(highRiskRegexList) == 0 {
+	rules := highRiskRegexList
+	if len(a.customRules) > 0 {
+		rules = append(append([]*regexp.Regexp(nil), highRiskRegexList...), a.customRules...)
+	}
+	if len(rules) == 0 {
 		return nil, nil
 	}
 
-	for _, re := range highRiskRegexList {
-		if re.Match(content) {
-			logging.InfoLogger.Printf("Regex match found for %s (Rule: %s)", fileInfo.Path, re.String())
-			return &types.Finding{
+	for _, re := range rules {
+		if loc := re.FindIndex(content); loc != nil {
+			featureSet.Logger().Infof("Regex match found for %s (Rule: %s)", fileInfo.Path, re.String())
+			finding := &types.Finding{
 				AnalyzerName: a.analyzerName,
 				Description:  fmt.Sprintf("Matched high-risk regex pattern: %s", re.String()),
 				Risk:         types.RiskCritical,
 				Confidence:   0.9,
-			}, nil
+				Severity:     types.SeverityHigh, // 仅为模式匹配，未确认可利用
+			}
+			attachSnippet(finding, content, loc[0], loc[1])
+			return finding, nil
 		}
 	}
 
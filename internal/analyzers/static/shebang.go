@@ -0,0 +1,144 @@
+/*
+ * @Date: 2025-06-13 16:41:08
+ * @Editors: Mr wpl
+ * @Description: Shebang 检测：声明为 shell/Perl 等脚本却包含 PHP 代码的伪装文件，以及以非 PHP shebang 开头的 .php 文件
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// shebangPHPScanLen 查找 <?php 标记的字节数
+const shebangPHPScanLen = 1000
+
+/**
+ * @Description: Shebang 分析器。文件以 #! 开头且解释器不是 PHP 时：
+ * 前 1000 字节含 <?php 报告 Medium (伪装成其它脚本的 PHP 文件)；否则若扩展名为 .php 报告 Low
+ * @author: Mr wpl
+ */
+type ShebangAnalyzer struct {
+	analyzerName string
+}
+
+/**
+ * @Description: 创建ShebangAnalyzer实例
+ * @author: Mr wpl
+ * @return *ShebangAnalyzer Shebang分析器实例
+ * @return error 错误信息
+ */
+func NewShebangAnalyzer() (*ShebangAnalyzer, error) {
+	return &ShebangAnalyzer{analyzerName: "shebang"}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *ShebangAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征，直接检查文件开头
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *ShebangAnalyzer) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *ShebangAnalyzer) SupportedExtensions() []string {
+	return nil
+}
+
+/**
+ * @Description: 检查文件的 shebang 行与内容是否一致
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ */
+func (a *ShebangAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	interpreter, ok := parseShebang(content)
+	if !ok || isPHPInterpreter(interpreter) {
+		return nil, nil
+	}
+
+	head := content
+	if len(head) > shebangPHPScanLen {
+		head = head[:shebangPHPScanLen]
+	}
+	if bytes.Contains(bytes.ToLower(head), []byte("<?php")) {
+		featureSet.Logger().Infof("PHP code behind %s shebang in %s", interpreter, fileInfo.Path)
+		return &types.Finding{
+			AnalyzerName: a.analyzerName,
+			Description:  fmt.Sprintf("File declares %s shebang but contains PHP code", interpreter),
+			Risk:         types.RiskMedium,
+			Confidence:   0.7,
+			Severity:     types.SeverityMedium,
+			Metadata:     map[string]interface{}{"interpreter": interpreter},
+		}, nil
+	}
+
+	if strings.EqualFold(filepath.Ext(fileInfo.Path), ".php") {
+		return &types.Finding{
+			AnalyzerName: a.analyzerName,
+			Description:  fmt.Sprintf("Unexpected shebang in PHP file: %s script", interpreter),
+			Risk:         types.RiskLow,
+			Confidence:   0.5,
+			Severity:     types.SeverityLow,
+			Metadata:     map[string]interface{}{"interpreter": interpreter},
+		}, nil
+	}
+	return nil, nil
+}
+
+/**
+ * @Description: 解析首行 shebang，返回解释器路径；"#!/usr/bin/env perl" 返回 "perl"
+ * @author: Mr wpl
+ * @param content []byte: 文件内容
+ * @return string: 解释器
+ * @return bool: 是否以 #! 开头且声明了解释器
+ */
+func parseShebang(content []byte) (string, bool) {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return "", false
+	}
+	line := content[2:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return "", false
+	}
+	interpreter := fields[0]
+	if path.Base(interpreter) == "env" {
+		// 跳过 env 的选项 (如 -S)
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				return f, true
+			}
+		}
+	}
+	return interpreter, true
+}
+
+// isPHPInterpreter 解释器是否为 PHP (php、php7.4、php-cli 等)
+func isPHPInterpreter(interpreter string) bool {
+	return strings.HasPrefix(strings.ToLower(path.Base(interpreter)), "php")
+}
@@ -0,0 +1,60 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 为 regex/YARA 发现截取匹配位置所在行的代码片段与行号，便于分析人员核实
+ */
+package static
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"unicode/utf8"
+)
+
+const (
+	// snippetContext 匹配前后各保留的最多字节数 (不跨行)
+	snippetContext = 120
+	// snippetMaxLen 代码片段的最大字节数
+	snippetMaxLen = 200
+)
+
+/**
+ * @Description: 根据匹配的字节区间 [start, end) 设置发现的 Snippet (匹配所在行，前后各最多 snippetContext 字节，
+ * 总长不超过 snippetMaxLen) 与 LineNumber (从 1 开始)。匹配在片段中的区间记录在 Metadata["snippet_match"]，
+ * 供 HTML 报告高亮。片段保存原文，由各报告在输出时转义
+ * @author: Mr wpl
+ * @param finding *types.Finding: 发现
+ * @param content []byte: 被扫描的内容
+ * @param start int: 匹配起始偏移
+ * @param end int: 匹配结束偏移
+ */
+func attachSnippet(finding *types.Finding, content []byte, start int, end int) {
+	if start < 0 || start >= len(content) || end < start {
+		return
+	}
+	lineStart := bytes.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := len(content)
+	if i := bytes.IndexByte(content[start:], '\n'); i >= 0 {
+		lineEnd = start + i
+	}
+	end = min(end, lineEnd) // 跨行的匹配只显示第一行
+
+	from := max(lineStart, start-snippetContext)
+	to := min(lineEnd, end+snippetContext, from+snippetMaxLen)
+	for from < start && !utf8.RuneStart(content[from]) {
+		from++
+	}
+	for to > end && to < len(content) && !utf8.RuneStart(content[to]) {
+		to--
+	}
+	for to > end && content[to-1] == '\r' {
+		to--
+	}
+
+	finding.Snippet = string(content[from:to])
+	finding.LineNumber = bytes.Count(content[:start], []byte{'\n'}) + 1
+	if finding.Metadata == nil {
+		finding.Metadata = make(map[string]interface{})
+	}
+	finding.Metadata["snippet_match"] = []int{start - from, min(end, to) - from}
+}
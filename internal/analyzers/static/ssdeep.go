@@ -0,0 +1,210 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 模糊哈希 (ssdeep) 分析器：与已知 webshell 的 ssdeep 哈希比较，识别仅改了变量名、空白等少量字符的变种
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glaslos/ssdeep"
+)
+
+// SsdeepHashFile ssdeep 签名文件名 (位于 data_paths.signatures 下)，每行 <ssdeep_hash>:<label>
+const SsdeepHashFile = "ssdeep_hashes.txt"
+
+// DefaultSsdeepThreshold 未配置 ssdeep.threshold 时的相似度阈值 (0-100)
+const DefaultSsdeepThreshold = 80
+
+// ssdeepSignature 一条已知 webshell 的模糊哈希
+type ssdeepSignature struct {
+	hash  string
+	label string
+}
+
+/**
+ * @Description: ssdeep 模糊哈希分析器
+ * @author: Mr wpl
+ */
+type SsdeepAnalyzer struct {
+	analyzerName string
+	threshold    int
+	signatures   []ssdeepSignature
+}
+
+/**
+ * @Description: 创建 ssdeep 分析器，加载 <dataPath>/ssdeep_hashes.txt，文件不存在时分析器处于非活动状态
+ * @author: Mr wpl
+ * @param dataPath string: 签名目录 (data_paths.signatures)
+ * @param threshold int: 相似度阈值 (1-100)，超过该值时报告，0 时使用默认值 80
+ * @return *SsdeepAnalyzer: ssdeep 分析器实例
+ * @return error: 错误信息
+ */
+func NewSsdeepAnalyzer(dataPath string, threshold int) (*SsdeepAnalyzer, error) {
+	if threshold == 0 {
+		threshold = DefaultSsdeepThreshold
+	}
+	if threshold < 0 || threshold > 100 {
+		return nil, fmt.Errorf("invalid ssdeep threshold %d (expected 1-100)", threshold)
+	}
+	analyzer := &SsdeepAnalyzer{analyzerName: "ssdeep", threshold: threshold}
+
+	filePath := filepath.Join(dataPath, SsdeepHashFile)
+	file, err := os.Open(filePath)
+	if err != nil {
+		logging.WarnLogger.Printf("ssdeep signature file not found at %s: %v. ssdeep analyzer will be inactive.", filePath, err)
+		return analyzer, nil
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sig, err := parseSsdeepSignature(line)
+		if err != nil {
+			logging.WarnLogger.Printf("Invalid ssdeep signature on line %d in %s: %v", lineNum, filePath, err)
+			continue
+		}
+		analyzer.signatures = append(analyzer.signatures, sig)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ssdeep signatures %s: %w", filePath, err)
+	}
+
+	logging.InfoLogger.Printf("Loaded %d ssdeep signatures from %s (threshold %d)", len(analyzer.signatures), filePath, threshold)
+	return analyzer, nil
+}
+
+/**
+ * @Description: 解析一行签名。ssdeep 哈希本身为 <块大小>:<哈希1>:<哈希2>，第三个冒号之后为标签 (可为空)
+ * @author: Mr wpl
+ * @param line string: 签名行
+ * @return ssdeepSignature: 签名
+ * @return error: 格式错误
+ */
+func parseSsdeepSignature(line string) (ssdeepSignature, error) {
+	parts := strings.SplitN(line, ":", 4)
+	if len(parts) < 3 {
+		return ssdeepSignature{}, fmt.Errorf("expected <blocksize>:<hash>:<hash>:<label>, got %q", line)
+	}
+	hash := strings.Join(parts[:3], ":")
+	// 与自身比较可校验哈希格式
+	if _, err := ssdeep.Distance(hash, hash); err != nil {
+		return ssdeepSignature{}, fmt.Errorf("malformed ssdeep hash %q: %w", hash, err)
+	}
+	sig := ssdeepSignature{hash: hash}
+	if len(parts) == 4 {
+		sig.label = strings.TrimSpace(parts[3])
+	}
+	if sig.label == "" {
+		sig.label = "unknown"
+	}
+	return sig, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *SsdeepAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征，直接对文件内容计算哈希
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *SsdeepAnalyzer) RequiredFeatures() []string {
+	return nil
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *SsdeepAnalyzer) SupportedExtensions() []string {
+	return nil
+}
+
+/**
+ * @Description: 计算文件的 ssdeep 哈希并与每条签名比较，最高相似度超过阈值时报告 High。
+ * 小于 4096 字节的文件无法得到有意义的模糊哈希，直接跳过 (由 hash/regex/yara 覆盖)
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ * @return error 错误信息
+ */
+func (a *SsdeepAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	if len(a.signatures) == 0 {
+		return nil, nil
+	}
+	// 签名由原始样本文件生成，使用转码前的字节
+	hash, err := ssdeep.FuzzyBytes(featureSet.OriginalContent(content))
+	if err != nil {
+		if errors.Is(err, ssdeep.ErrFileTooSmall) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ssdeep hash of %s: %w", fileInfo.Path, err)
+	}
+
+	best, bestScore := a.bestMatch(hash)
+	if bestScore <= a.threshold {
+		return nil, nil
+	}
+	featureSet.Logger().Infof("ssdeep match for %s: %s (similarity %d)", fileInfo.Path, best.label, bestScore)
+
+	return &types.Finding{
+		AnalyzerName: a.analyzerName,
+		Description:  fmt.Sprintf("Near-duplicate of known webshell %s (ssdeep similarity %d%%)", best.label, bestScore),
+		Risk:         types.RiskHigh,
+		Confidence:   float64(bestScore) / 100,
+		Severity:     types.SeverityHigh,
+		Metadata: map[string]interface{}{
+			"ssdeep":     hash,
+			"signature":  best.hash,
+			"similarity": bestScore,
+		},
+	}, nil
+}
+
+/**
+ * @Description: 返回与哈希最相似的签名及相似度 (0-100)，块大小不兼容的签名相似度为 0
+ * @author: Mr wpl
+ * @param hash string: 文件的 ssdeep 哈希
+ * @return ssdeepSignature: 最相似的签名
+ * @return int: 相似度
+ */
+func (a *SsdeepAnalyzer) bestMatch(hash string) (ssdeepSignature, int) {
+	var best ssdeepSignature
+	bestScore := 0
+	for _, sig := range a.signatures {
+		score, err := ssdeep.Distance(hash, sig.hash)
+		if err != nil || score <= bestScore {
+			continue
+		}
+		best, bestScore = sig, score
+		if score == 100 {
+			break
+		}
+	}
+	return best, bestScore
+}
@@ -0,0 +1,189 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: ssdeep 分析器测试：testdata/ssdeep 中的已知样本、改名变种与正常文件，以及 1000 条签名时比较循环的基准
+ */
+package static
+
+import (
+	"bt-shieldml/pkg/types"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glaslos/ssdeep"
+)
+
+const ssdeepTestData = "testdata/ssdeep"
+
+func TestSsdeepAnalyzer(t *testing.T) {
+	a, err := NewSsdeepAnalyzer(ssdeepTestData, 0)
+	if err != nil {
+		t.Fatalf("NewSsdeepAnalyzer() error = %v", err)
+	}
+	if len(a.signatures) != 1 || a.signatures[0].label != "FileManager" {
+		t.Fatalf("loaded signatures = %+v, want the FileManager signature only", a.signatures)
+	}
+
+	tests := []struct {
+		file      string
+		wantMatch bool
+	}{
+		{"known_shell.php", true},
+		{"renamed_variant.php", true},
+		{"benign.php", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(ssdeepTestData, tt.file)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			finding, err := a.Analyze(types.FileInfo{Path: path}, content, nil)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if (finding != nil) != tt.wantMatch {
+				t.Fatalf("Analyze() finding = %+v, want match %v", finding, tt.wantMatch)
+			}
+			if finding == nil {
+				return
+			}
+			if finding.Risk != types.RiskHigh {
+				t.Errorf("Risk = %v, want High", finding.Risk)
+			}
+			if score := finding.Metadata["similarity"].(int); score <= DefaultSsdeepThreshold {
+				t.Errorf("similarity = %d, want > %d", score, DefaultSsdeepThreshold)
+			}
+		})
+	}
+}
+
+func TestSsdeepAnalyzerThreshold(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join(ssdeepTestData, "renamed_variant.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 变种与已知样本的相似度为 97
+	tests := []struct {
+		threshold int
+		wantMatch bool
+	}{
+		{80, true},
+		{96, true},
+		{97, false},
+		{100, false},
+	}
+	for _, tt := range tests {
+		a, err := NewSsdeepAnalyzer(ssdeepTestData, tt.threshold)
+		if err != nil {
+			t.Fatalf("NewSsdeepAnalyzer(%d) error = %v", tt.threshold, err)
+		}
+		finding, err := a.Analyze(types.FileInfo{Path: "renamed_variant.php"}, content, nil)
+		if err != nil {
+			t.Fatalf("Analyze() error = %v", err)
+		}
+		if (finding != nil) != tt.wantMatch {
+			t.Errorf("threshold %d: match = %v, want %v", tt.threshold, finding != nil, tt.wantMatch)
+		}
+	}
+}
+
+func TestNewSsdeepAnalyzerInvalid(t *testing.T) {
+	for _, threshold := range []int{-1, 101} {
+		if _, err := NewSsdeepAnalyzer(ssdeepTestData, threshold); err == nil {
+			t.Errorf("NewSsdeepAnalyzer(%d) error = nil, want an error", threshold)
+		}
+	}
+
+	// 签名文件不存在时分析器不活动，不报告任何文件
+	a, err := NewSsdeepAnalyzer(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewSsdeepAnalyzer() without signatures error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(ssdeepTestData, "known_shell.php"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finding, err := a.Analyze(types.FileInfo{Path: "known_shell.php"}, content, nil); finding != nil || err != nil {
+		t.Errorf("inactive analyzer Analyze() = %+v, %v, want nil, nil", finding, err)
+	}
+}
+
+func TestSsdeepAnalyzerSmallFile(t *testing.T) {
+	a, err := NewSsdeepAnalyzer(ssdeepTestData, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if finding, err := a.Analyze(types.FileInfo{Path: "small.php"}, []byte("<?php eval($_POST['a']);"), nil); finding != nil || err != nil {
+		t.Errorf("Analyze() on a file below the ssdeep minimum = %+v, %v, want nil, nil", finding, err)
+	}
+}
+
+func TestParseSsdeepSignature(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantLabel string
+		wantErr   bool
+	}{
+		{"3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C:c99", "c99", false},
+		{"3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C:WSO: with colon", "WSO: with colon", false},
+		{"3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C", "unknown", false},
+		{"3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C:  ", "unknown", false},
+		{"AXGBicFlgVNhBGcL6wCrFQEv", "", true},
+		{"3:onlyone", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			sig, err := parseSsdeepSignature(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSsdeepSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sig.label != tt.wantLabel {
+				t.Errorf("label = %q, want %q", sig.label, tt.wantLabel)
+			}
+		})
+	}
+}
+
+// BenchmarkSsdeepBestMatch 一个文件与 1000 条签名比较 (无匹配，需遍历全部签名)
+func BenchmarkSsdeepBestMatch(b *testing.B) {
+	const signatures = 1000
+	rng := rand.New(rand.NewSource(1))
+	content := make([]byte, 8<<10)
+	a := &SsdeepAnalyzer{analyzerName: "ssdeep", threshold: DefaultSsdeepThreshold}
+	for len(a.signatures) < signatures {
+		for i := range content {
+			content[i] = byte(' ' + rng.Intn(95))
+		}
+		hash, err := ssdeep.FuzzyBytes(content)
+		if err != nil {
+			b.Fatal(err)
+		}
+		a.signatures = append(a.signatures, ssdeepSignature{hash: hash, label: "synthetic"})
+	}
+	target, err := os.ReadFile(filepath.Join(ssdeepTestData, "benign.php"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	hash, err := ssdeep.FuzzyBytes(target)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("compare", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			a.bestMatch(hash)
+		}
+	})
+	b.Run("analyze", func(b *testing.B) {
+		b.SetBytes(int64(len(target)))
+		for i := 0; i < b.N; i++ {
+			if _, err := a.Analyze(types.FileInfo{Path: "benign.php"}, target, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
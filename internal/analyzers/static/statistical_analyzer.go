@@ -3,7 +3,6 @@ package static
 
 import (
 	"bt-shieldml/internal/features" // Import features package
-	"bt-shieldml/pkg/logging"
 	"bt-shieldml/pkg/types"
 	"fmt"
 	"math"
@@ -70,6 +69,15 @@ func (a *StatisticalAnalyzer) RequiredFeatures() []string {
 	return []string{"statistical", "callable"}
 }
 
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *StatisticalAnalyzer) SupportedExtensions() []string {
+	return nil
+}
+
 /**
  * @Description: 执行统计分析。
  * @author: Mr wpl
@@ -87,7 +95,7 @@ func (a *StatisticalAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, f
 			return nil, nil
 		}
 		// Log an error if stats are missing for non-empty content
-		logging.ErrorLogger.Printf("Required 'statistical' feature missing in FeatureSet for %s", fileInfo.Path)
+		featureSet.Logger().Errorf("Required 'statistical' feature missing in FeatureSet for %s", fileInfo.Path)
 		return nil, fmt.Errorf("missing statistical features")
 	}
 
@@ -106,6 +114,7 @@ func (a *StatisticalAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, f
 			Description:  desc,
 			Risk:         types.RiskMedium, // Assign risk level as per requirement
 			Confidence:   0.7,              // Example confidence
+			Severity:     types.SeverityMedium,
 		}, nil
 	}
 
@@ -0,0 +1,102 @@
+/*
+ * @Date: 2025-06-19 14:08:35
+ * @Editors: Mr wpl
+ * @Description: 超全局变量访问检测：访问的超全局变量种类或次数过多的文件
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"strings"
+)
+
+const (
+	superGlobalDistinctLimit = 5  // 超过该种类数报告 Medium
+	superGlobalTotalLimit    = 10 // 超过该访问次数报告 High
+)
+
+/**
+ * @Description: 超全局变量分析器。访问超过 5 种超全局变量报告 Medium，总访问超过 10 次报告 High
+ * @author: Mr wpl
+ */
+type SuperGlobalAnalyzer struct {
+	analyzerName string
+}
+
+/**
+ * @Description: 创建SuperGlobalAnalyzer实例
+ * @author: Mr wpl
+ * @return *SuperGlobalAnalyzer 超全局变量分析器实例
+ * @return error 错误信息
+ */
+func NewSuperGlobalAnalyzer() (*SuperGlobalAnalyzer, error) {
+	return &SuperGlobalAnalyzer{analyzerName: "superglobal"}, nil
+}
+
+/**
+ * @Description: 返回分析器名称
+ * @author: Mr wpl
+ * @return string 分析器名称
+ */
+func (a *SuperGlobalAnalyzer) Name() string {
+	return a.analyzerName
+}
+
+/**
+ * @Description: 返回分析器所需的特征
+ * @author: Mr wpl
+ * @return []string 分析器所需的特征
+ */
+func (a *SuperGlobalAnalyzer) RequiredFeatures() []string {
+	return []string{"superglobals"}
+}
+
+/**
+ * @Description: 返回分析器支持的文件扩展名，依赖 PHP AST，只用于 PHP 文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *SuperGlobalAnalyzer) SupportedExtensions() []string {
+	return types.PHPExtensions
+}
+
+/**
+ * @Description: 根据超全局变量的访问种类与次数判断风险
+ * @author: Mr wpl
+ * @param fileInfo 文件信息
+ * @param content 文件内容
+ * @param featureSet 特征集
+ * @return *types.Finding 发现
+ */
+func (a *SuperGlobalAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	counts, total := featureSet.SuperGlobalCounts, featureSet.TotalSuperGlobalAccesses
+	distinct := len(counts)
+
+	var risk types.RiskLevel
+	var severity string
+	switch {
+	case total > superGlobalTotalLimit:
+		risk, severity = types.RiskHigh, types.SeverityHigh
+	case distinct > superGlobalDistinctLimit:
+		risk, severity = types.RiskMedium, types.SeverityMedium
+	default:
+		return nil, nil
+	}
+
+	names := features.SortedSuperGlobals(counts)
+	summary := make([]string, 0, len(names))
+	for _, name := range names {
+		summary = append(summary, fmt.Sprintf("$%s(%d)", name, counts[name]))
+	}
+	featureSet.Logger().Infof("%d superglobal accesses (%d distinct) in %s", total, distinct, fileInfo.Path)
+	return &types.Finding{
+		AnalyzerName: a.analyzerName,
+		Description:  fmt.Sprintf("Accesses %d distinct superglobals %d times: %s", distinct, total, strings.Join(summary, ", ")),
+		Risk:         risk,
+		Confidence:   0.6,
+		Severity:     severity,
+		Metadata:     map[string]interface{}{"superglobals": counts, "total_accesses": total},
+	}, nil
+}
@@ -0,0 +1,306 @@
+<?php
+/**
+ * Blog archive template
+ */
+namespace App\View;
+
+function render_archive_month_0(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_1(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_2(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_3(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_4(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_5(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_6(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_7(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_8(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_9(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_10(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_11(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_12(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_13(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_14(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_15(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_16(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_17(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_18(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_19(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_20(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_21(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_22(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_23(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_24(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_25(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_26(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_27(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_28(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_29(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_30(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_31(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_32(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_33(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_34(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_35(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_36(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_37(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_38(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_39(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_40(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_41(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_42(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_43(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_44(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_45(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_46(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_47(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_48(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_49(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_50(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_51(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_52(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_53(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_54(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_55(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_56(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_57(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_58(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
+function render_archive_month_59(array $posts): string {
+    $html = '<section class="archive-month">';
+    foreach ($posts as $post) { $html .= '<h2>' . htmlspecialchars($post['title'], ENT_QUOTES) . '</h2>'; }
+    return $html . '</section>';
+}
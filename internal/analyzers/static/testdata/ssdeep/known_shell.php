@@ -0,0 +1,175 @@
+<?php
+// synthetic test sample modelled on a file-manager webshell; not functional
+error_reporting(0);
+@set_time_limit(0);
+$action = isset($_REQUEST['act']) ? $_REQUEST['act'] : '';
+$dir = isset($_REQUEST['d']) ? $_REQUEST['d'] : getcwd();
+
+function fm_list_dir($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|list_dir', $entry, fileperms($dir . '/' . $entry), 0);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_read_file($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|read_file', $entry, fileperms($dir . '/' . $entry), 1);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_write_file($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|write_file', $entry, fileperms($dir . '/' . $entry), 2);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_delete_path($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|delete_path', $entry, fileperms($dir . '/' . $entry), 3);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_rename_path($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|rename_path', $entry, fileperms($dir . '/' . $entry), 4);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_chmod_path($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|chmod_path', $entry, fileperms($dir . '/' . $entry), 5);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_exec_cmd($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|exec_cmd', $entry, fileperms($dir . '/' . $entry), 6);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_upload_file($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|upload_file', $entry, fileperms($dir . '/' . $entry), 7);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_download_file($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|download_file', $entry, fileperms($dir . '/' . $entry), 8);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_db_query($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|db_query', $entry, fileperms($dir . '/' . $entry), 9);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_phpinfo_page($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|phpinfo_page', $entry, fileperms($dir . '/' . $entry), 10);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_mass_deface($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|mass_deface', $entry, fileperms($dir . '/' . $entry), 11);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_port_scan($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|port_scan', $entry, fileperms($dir . '/' . $entry), 12);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_back_connect($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|back_connect', $entry, fileperms($dir . '/' . $entry), 13);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+function fm_self_remove($dir, $payload) {
+    $lines = array();
+    foreach (scandir($dir) as $entry) {
+        if ($entry === '.' || $entry === '..') { continue; }
+        $lines[] = sprintf('%s|%o|%d|self_remove', $entry, fileperms($dir . '/' . $entry), 14);
+    }
+    if ($payload !== '') { $lines[] = base64_decode(str_rot13(strrev($payload))); }
+    return implode("\n", $lines);
+}
+
+switch ($action) {
+    case 'list_dir': echo fm_list_dir($dir, $_POST['x']); break;
+    case 'read_file': echo fm_read_file($dir, $_POST['x']); break;
+    case 'write_file': echo fm_write_file($dir, $_POST['x']); break;
+    case 'delete_path': echo fm_delete_path($dir, $_POST['x']); break;
+    case 'rename_path': echo fm_rename_path($dir, $_POST['x']); break;
+    case 'chmod_path': echo fm_chmod_path($dir, $_POST['x']); break;
+    case 'exec_cmd': echo fm_exec_cmd($dir, $_POST['x']); break;
+    case 'upload_file': echo fm_upload_file($dir, $_POST['x']); break;
+    case 'download_file': echo fm_download_file($dir, $_POST['x']); break;
+    case 'db_query': echo fm_db_query($dir, $_POST['x']); break;
+    case 'phpinfo_page': echo fm_phpinfo_page($dir, $_POST['x']); break;
+    case 'mass_deface': echo fm_mass_deface($dir, $_POST['x']); break;
+    case 'port_scan': echo fm_port_scan($dir, $_POST['x']); break;
+    case 'back_connect': echo fm_back_connect($dir, $_POST['x']); break;
+    case 'self_remove': echo fm_self_remove($dir, $_POST['x']); break;
+    default: echo '<form method="post"><input name="act"><input name="x"></form>';
+}
@@ -13,13 +13,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/hillu/go-yara/v4"
 )
 
 type YaraAnalyzer struct {
 	analyzerName string // Renamed field
-	rules        *yara.Rules
+	dataPath     string // 签名目录，Reload 时从此目录重新读取 .yar 文件
+	mu           sync.RWMutex
+	rules        *yara.Rules // 受 mu 保护，Reload 编译成功后整体替换
 }
 
 /**
@@ -39,7 +44,7 @@ func NewYaraAnalyzer(dataPath string) (*YaraAnalyzer, error) {
 
 		if _, err := os.Stat(ruleFilePath); os.IsNotExist(err) {
 			logging.WarnLogger.Printf("YARA rule file not found at %s: %v. YARA analyzer will be inactive.", ruleFilePath, err)
-			return &YaraAnalyzer{analyzerName: "yara", rules: nil}, nil // Use renamed field
+			return &YaraAnalyzer{analyzerName: "yara", dataPath: dataPath, rules: nil}, nil // Use renamed field
 		}
 
 		compiler, err := yara.NewCompiler()
@@ -63,7 +68,7 @@ func NewYaraAnalyzer(dataPath string) (*YaraAnalyzer, error) {
 			return nil, fmt.Errorf("failed to compile yara rules from %s: %w", ruleFilePath, err)
 		}
 
-		return &YaraAnalyzer{analyzerName: "yara", rules: rules}, nil
+		return &YaraAnalyzer{analyzerName: "yara", dataPath: dataPath, rules: rules}, nil
 	}
 
 	// 使用嵌入的规则数据
@@ -83,7 +88,7 @@ func NewYaraAnalyzer(dataPath string) (*YaraAnalyzer, error) {
 	}
 	// logging.InfoLogger.Printf("成功编译嵌入的YARA规则")
 
-	return &YaraAnalyzer{analyzerName: "yara", rules: rules}, nil
+	return &YaraAnalyzer{analyzerName: "yara", dataPath: dataPath, rules: rules}, nil
 }
 
 /**
@@ -104,6 +109,15 @@ func (a *YaraAnalyzer) RequiredFeatures() []string {
 	return nil
 }
 
+/**
+ * @Description: 返回分析器支持的文件扩展名，nil 表示所有文件
+ * @author: Mr wpl
+ * @return []string 支持的扩展名
+ */
+func (a *YaraAnalyzer) SupportedExtensions() []string {
+	return nil
+}
+
 /**
  * @Description: 分析文件，是否匹配yara规则
  * @author: Mr wpl
@@ -113,33 +127,133 @@ func (a *YaraAnalyzer) RequiredFeatures() []string {
  * @return *types.Finding 发现
  */
 func (a *YaraAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
-	if a.rules == nil {
+	a.mu.RLock()
+	rules := a.rules
+	a.mu.RUnlock()
+	if rules == nil {
 		return nil, nil
 	}
 
-	scanner, err := yara.NewScanner(a.rules)
+	scanner, err := yara.NewScanner(rules)
 	if err != nil {
-		logging.ErrorLogger.Printf("Failed to create YARA scanner for %s: %v", fileInfo.Path, err)
+		featureSet.Logger().Errorf("Failed to create YARA scanner for %s: %v", fileInfo.Path, err)
 		return nil, fmt.Errorf("yara scanner creation failed: %w", err)
 	}
 
 	var matches yara.MatchRules
 	err = scanner.SetCallback(&matches).ScanMem(content)
 	if err != nil {
-		logging.WarnLogger.Printf("YARA scan failed for %s: %v", fileInfo.Path, err)
+		featureSet.Logger().Warnf("YARA scan failed for %s: %v", fileInfo.Path, err)
 		return nil, fmt.Errorf("yara scan execution failed: %w", err)
 	}
 
 	if len(matches) > 0 {
 		match := matches[0]
-		logging.InfoLogger.Printf("YARA match found for %s (Rule: %s)", fileInfo.Path, match.Rule)
-		return &types.Finding{
+		featureSet.Logger().Infof("YARA match found for %s (Rule: %s)", fileInfo.Path, match.Rule)
+		finding := &types.Finding{
 			AnalyzerName: a.analyzerName, // Use renamed field
 			Description:  fmt.Sprintf("Matched YARA rule: %s", match.Rule),
 			Risk:         types.RiskCritical,
 			Confidence:   1.0,
-		}, nil
+			Severity:     types.SeverityCritical,
+		}
+		applyCVSSMeta(finding, match)
+		if len(match.Strings) > 0 {
+			offset := int(match.Strings[0].Offset)
+			attachSnippet(finding, content, offset, offset+len(match.Strings[0].Data))
+		}
+		return finding, nil
 	}
 
 	return nil, nil
 }
+
+/**
+ * @Description: 重新读取并编译签名目录下的所有 .yar 文件 (目录中没有 .yar 文件时使用嵌入的规则)，
+ * 编译成功后替换当前规则，进行中的扫描继续使用旧规则；编译失败时保留旧规则并返回错误
+ * @author: Mr wpl
+ * @return error: 读取或编译失败时返回错误
+ */
+func (a *YaraAnalyzer) Reload() error {
+	ruleFiles, err := filepath.Glob(filepath.Join(a.dataPath, "*.yar"))
+	if err != nil {
+		return fmt.Errorf("failed to list yara rule files in %s: %w", a.dataPath, err)
+	}
+	sort.Strings(ruleFiles)
+
+	compiler, err := yara.NewCompiler()
+	if err != nil {
+		return fmt.Errorf("failed to create yara compiler: %w", err)
+	}
+	if len(ruleFiles) == 0 {
+		ruleData, err := embedded.GetFileContent("data/signatures/Webshells_rules.yar")
+		if err != nil {
+			return fmt.Errorf("no yara rule files in %s and no embedded rules: %w", a.dataPath, err)
+		}
+		if err := compiler.AddString(string(ruleData), "webshell"); err != nil {
+			return fmt.Errorf("failed to add embedded yara rules to compiler: %w", err)
+		}
+	}
+	for _, ruleFile := range ruleFiles {
+		if err := addRuleFile(compiler, ruleFile); err != nil {
+			return err
+		}
+	}
+	rules, err := compiler.GetRules()
+	if err != nil {
+		return fmt.Errorf("failed to compile yara rules from %s: %w", a.dataPath, err)
+	}
+
+	a.mu.Lock()
+	a.rules = rules
+	a.mu.Unlock()
+	if len(ruleFiles) == 0 {
+		logging.InfoLogger.Printf("Reloaded embedded YARA rules (no .yar files in %s)", a.dataPath)
+	} else {
+		logging.InfoLogger.Printf("Reloaded YARA rules from %d file(s) in %s", len(ruleFiles), a.dataPath)
+	}
+	return nil
+}
+
+// addRuleFile 将规则文件加入编译器，每个文件使用以文件名命名的命名空间，避免不同文件中的同名规则冲突
+func addRuleFile(compiler *yara.Compiler, ruleFile string) error {
+	file, err := os.Open(ruleFile)
+	if err != nil {
+		return fmt.Errorf("failed to open yara rule file %s: %w", ruleFile, err)
+	}
+	defer file.Close()
+	namespace := strings.TrimSuffix(filepath.Base(ruleFile), filepath.Ext(ruleFile))
+	if err := compiler.AddFile(file, namespace); err != nil {
+		return fmt.Errorf("failed to add yara rule file %s to compiler: %w", ruleFile, err)
+	}
+	return nil
+}
+
+/**
+ * @Description: 使用 YARA 规则 meta 中的 severity 与 cvss_vector 覆盖发现的默认值，非法值忽略
+ * @author: Mr wpl
+ * @param finding *types.Finding: 发现
+ * @param match yara.MatchRule: 匹配的规则
+ */
+func applyCVSSMeta(finding *types.Finding, match yara.MatchRule) {
+	for _, meta := range match.Metas {
+		value, ok := meta.Value.(string)
+		if !ok {
+			continue
+		}
+		switch meta.Identifier {
+		case "severity":
+			if severity := types.NormalizeSeverity(value); severity != "" {
+				finding.Severity = severity
+			} else {
+				logging.WarnLogger.Printf("YARA rule %s has invalid severity meta %q", match.Rule, value)
+			}
+		case "cvss_vector":
+			if _, err := types.ParseCVSSVector(value); err == nil {
+				finding.CVSSVector = value
+			} else {
+				logging.WarnLogger.Printf("YARA rule %s has invalid cvss_vector meta: %v", match.Rule, err)
+			}
+		}
+	}
+}
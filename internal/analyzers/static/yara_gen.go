@@ -0,0 +1,208 @@
+/*
+ * @Date: 2025-06-20 14:18:25
+ * @Editors: Mr wpl
+ * @Description: 根据已确认的木马文件生成 YARA 规则骨架 (gen-yara)：高熵字符串、危险函数嵌套调用链与十六进制编码字符串
+ */
+package static
+
+import (
+	"bt-shieldml/internal/features"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// yaraGenMinStringLen 高熵字符串字面量的最小长度
+	yaraGenMinStringLen = 20
+	// yaraGenMinEntropy 高熵字符串的最低熵 (bits/char)，20 字符的字符串熵最多约 4.32
+	yaraGenMinEntropy = 4.0
+	// yaraGenMaxEntropyStrings 规则中最多包含的高熵字符串数
+	yaraGenMaxEntropyStrings = 5
+	// yaraGenMaxStringLen 规则中单个字符串的最大长度，过长的字面量只取开头
+	yaraGenMaxStringLen = 64
+	// yaraGenMaxPatterns 调用链与十六进制字符串各自最多包含的数量
+	yaraGenMaxPatterns = 5
+)
+
+// dangerousCallChainRegex 两层及以上嵌套的危险函数调用，如 eval(gzinflate(base64_decode(
+var dangerousCallChainRegex = regexp.MustCompile(`(?i)(?:\b(?:` + strings.Join(DefaultDangerousFunctions, "|") + `)\s*\(\s*){2,}`)
+
+// dangerousCallNameRegex 调用链中的函数名
+var dangerousCallNameRegex = regexp.MustCompile(`(?i)[a-z0-9_]+`)
+
+// hexEscapeRegex 连续 4 个以上的 \xNN 转义
+var hexEscapeRegex = regexp.MustCompile(`(?:\\x[0-9a-fA-F]{2}){4,}`)
+
+// hexLiteralRegex 只由十六进制字符组成的字符串字面量 (偶数长度，如 hex2bin 的参数)
+var hexLiteralRegex = regexp.MustCompile(`^(?:[0-9a-fA-F]{2}){10,}$`)
+
+// yaraGenString 规则中的一个字符串定义
+type yaraGenString struct {
+	name    string
+	value   string // 已转义的 YARA 字符串或正则 (含引号或斜杠)
+	comment string
+}
+
+/**
+ * @Description: 生成 YARA 规则骨架，规则名取自文件 SHA-256 前缀，条件为 any of them。
+ * 字符串包括：熵最高的 5 个高熵字符串字面量 (不少于 20 字符)、危险函数嵌套调用链、十六进制编码字符串
+ * @author: Mr wpl
+ * @param fileName string: 文件名，写入 meta
+ * @param content []byte: 文件内容
+ * @return string: YARA 规则
+ * @return error: 没有可用的特征字符串时返回错误
+ */
+func GenerateYaraRule(fileName string, content []byte) (string, error) {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	var strs []yaraGenString
+	for i, s := range topEntropyStrings(content) {
+		strs = append(strs, yaraGenString{
+			name:    fmt.Sprintf("$entropy%d", i+1),
+			value:   yaraTextString(s.text),
+			comment: fmt.Sprintf("entropy %.2f", s.entropy),
+		})
+	}
+	for i, chain := range dangerousCallChains(content) {
+		strs = append(strs, yaraGenString{
+			name:    fmt.Sprintf("$chain%d", i+1),
+			value:   callChainRegex(chain),
+			comment: strings.Join(chain, " -> "),
+		})
+	}
+	for i, h := range hexEncodedStrings(content) {
+		strs = append(strs, yaraGenString{name: fmt.Sprintf("$hex%d", i+1), value: yaraTextString(h)})
+	}
+	if len(strs) == 0 {
+		return "", fmt.Errorf("no high-entropy strings, call chains or hex-encoded strings found in %s", fileName)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "rule webshell_auto_%s : webshell {\n", hash[:12])
+	b.WriteString("\tmeta:\n")
+	fmt.Fprintf(&b, "\t\tdescription = %s\n", yaraTextString("Auto-generated skeleton from "+filepath.Base(fileName)+" - review before use"))
+	b.WriteString("\t\tauthor = \"bt-shieldml gen-yara\"\n")
+	fmt.Fprintf(&b, "\t\tdate = \"%s\"\n", time.Now().Format("2006/01/02"))
+	fmt.Fprintf(&b, "\t\thash = \"%s\"\n", hash)
+	if sf, err := features.CalculateStatisticalFeatures(bytes.NewReader(content), features.FileTypeOf(fileName)); err == nil {
+		fmt.Fprintf(&b, "\t\t// entropy %.4f, longest line %.0f, symbol ratio %.4f\n", sf.IE, sf.LM, sf.SR)
+	}
+	b.WriteString("\tstrings:\n")
+	for _, s := range strs {
+		fmt.Fprintf(&b, "\t\t%s = %s", s.name, s.value)
+		if s.comment != "" {
+			fmt.Fprintf(&b, " // %s", s.comment)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\tcondition:\n\t\tany of them\n}\n")
+	return b.String(), nil
+}
+
+// entropyString 一个高熵字符串字面量
+type entropyString struct {
+	text    string
+	entropy float64
+}
+
+// topEntropyStrings 熵最高的字符串字面量 (十六进制字符串除外)，最多 5 个，过长时截断
+func topEntropyStrings(content []byte) []entropyString {
+	seen := make(map[string]bool)
+	var found []entropyString
+	for _, lit := range findStringLiterals(content, yaraGenMinStringLen-1) {
+		body := content[lit[0]:lit[1]]
+		if len(body) > yaraGenMaxStringLen {
+			body = body[:yaraGenMaxStringLen]
+		}
+		entropy := shannonEntropy(body)
+		if entropy < yaraGenMinEntropy || seen[string(body)] || hexLiteralRegex.Match(body) {
+			continue
+		}
+		seen[string(body)] = true
+		found = append(found, entropyString{text: string(body), entropy: entropy})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].entropy > found[j].entropy
+	})
+	if len(found) > yaraGenMaxEntropyStrings {
+		found = found[:yaraGenMaxEntropyStrings]
+	}
+	return found
+}
+
+// dangerousCallChains 去重后的危险函数嵌套调用链 (函数名小写)
+func dangerousCallChains(content []byte) [][]string {
+	seen := make(map[string]bool)
+	var chains [][]string
+	for _, m := range dangerousCallChainRegex.FindAll(content, -1) {
+		names := dangerousCallNameRegex.FindAllString(strings.ToLower(string(m)), -1)
+		key := strings.Join(names, ",")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		chains = append(chains, names)
+		if len(chains) >= yaraGenMaxPatterns {
+			break
+		}
+	}
+	return chains
+}
+
+// callChainRegex 调用链的 YARA 正则，允许括号前后有空白，不区分大小写
+func callChainRegex(chain []string) string {
+	return "/" + strings.Join(chain, `\s*\(\s*`) + `\s*\(/ nocase`
+}
+
+// hexEncodedStrings 文件中的 \xNN 转义序列与纯十六进制字符串字面量 (去重，过长时截断)
+func hexEncodedStrings(content []byte) []string {
+	seen := make(map[string]bool)
+	var found []string
+	add := func(s []byte) {
+		if len(s) > yaraGenMaxStringLen {
+			s = s[:yaraGenMaxStringLen]
+		}
+		if seen[string(s)] || len(found) >= yaraGenMaxPatterns {
+			return
+		}
+		seen[string(s)] = true
+		found = append(found, string(s))
+	}
+	for _, m := range hexEscapeRegex.FindAll(content, -1) {
+		add(m)
+	}
+	for _, lit := range findStringLiterals(content, yaraGenMinStringLen-1) {
+		if body := content[lit[0]:lit[1]]; hexLiteralRegex.Match(body) {
+			add(body)
+		}
+	}
+	return found
+}
+
+// yaraTextString 转义为 YARA 文本字符串，不可打印字符写为 \xNN
+func yaraTextString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,221 @@
+/*
+ * @Date: 2025-06-06 10:18:42
+ * @Editors: Mr wpl
+ * @Description: 从 AST 构建函数调用图并检测循环调用 (互相递归的函数)
+ */
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// php-ast 节点类型 (kind)
+const (
+	kindFuncDecl      = 67   // AST_FUNC_DECL
+	kindMethod        = 69   // AST_METHOD
+	kindIncludeOrEval = 269  // AST_INCLUDE_OR_EVAL
+	kindCall          = 515  // AST_CALL
+	kindMethodCall    = 768  // AST_METHOD_CALL
+	kindStaticCall    = 769  // AST_STATIC_CALL
+	kindName          = 2048 // AST_NAME
+)
+
+// flagExecEval AST_INCLUDE_OR_EVAL 的 flags，表示 eval
+const flagExecEval = 1
+
+// MainScope 函数体之外 (文件顶层) 的调用记录在该名称下
+const MainScope = "{main}"
+
+// callbackFuncs 第一个参数为回调函数名的内置函数，字符串字面量参数视为一次调用
+var callbackFuncs = map[string]bool{
+	"call_user_func":       true,
+	"call_user_func_array": true,
+}
+
+/**
+ * @Description: 遍历 AST 提取函数调用图。函数名统一为小写 (PHP 函数名不区分大小写)，
+ * 方法以方法名记录，eval 记为 "eval"，无法静态确定名称的动态调用被忽略
+ * @author: Mr wpl
+ * @param astRoot interface{}: AST根节点
+ * @return map[string][]string: 函数名 -> 被调用的函数名 (去重并排序)
+ * @return error: 错误
+ */
+func BuildCallGraph(astRoot interface{}) (map[string][]string, error) {
+	if astRoot == nil {
+		return nil, fmt.Errorf("cannot process nil AST")
+	}
+
+	edges := make(map[string]map[string]bool)
+	addEdge := func(caller, callee string) {
+		if callee == "" {
+			return
+		}
+		if edges[caller] == nil {
+			edges[caller] = make(map[string]bool)
+		}
+		edges[caller][callee] = true
+	}
+
+	var walk func(node interface{}, scope string)
+	walk = func(node interface{}, scope string) {
+		switch value := node.(type) {
+		case astNode:
+			switch value.Kind {
+			case kindFuncDecl, kindMethod:
+				if name := strings.ToLower(childString(value, "name")); name != "" {
+					scope = name
+					if edges[scope] == nil {
+						edges[scope] = make(map[string]bool)
+					}
+				}
+			case kindCall:
+				callee := calleeName(childNode(value, "expr"))
+				addEdge(scope, callee)
+				if callbackFuncs[callee] {
+					addEdge(scope, strings.ToLower(firstStringArg(value)))
+				}
+			case kindMethodCall, kindStaticCall:
+				addEdge(scope, strings.ToLower(childString(value, "method")))
+			case kindIncludeOrEval:
+				if value.Flag == flagExecEval {
+					addEdge(scope, "eval")
+				}
+			}
+			walk(value.Children, scope)
+		case []interface{}:
+			for _, item := range value {
+				walk(item, scope)
+			}
+		case map[string]interface{}:
+			for _, item := range value {
+				walk(item, scope)
+			}
+		}
+	}
+	walk(astRoot, MainScope)
+
+	graph := make(map[string][]string, len(edges))
+	for caller, callees := range edges {
+		list := make([]string, 0, len(callees))
+		for callee := range callees {
+			list = append(list, callee)
+		}
+		sort.Strings(list)
+		graph[caller] = list
+	}
+	return graph, nil
+}
+
+/**
+ * @Description: 使用 DFS 查找调用图中的循环。每个循环以最小名称开头，结果去重并排序，
+ * 自递归的函数返回长度为 1 的循环
+ * @author: Mr wpl
+ * @param callGraph map[string][]string: 调用图
+ * @return [][]string: 循环列表，每个循环为依次调用的函数名
+ */
+func DetectCycles(callGraph map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make(map[string]int, len(callGraph))
+	var stack []string
+	seen := make(map[string]bool)
+	var cycles [][]string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = onStack
+		stack = append(stack, name)
+		for _, callee := range callGraph[name] {
+			switch state[callee] {
+			case unvisited:
+				visit(callee)
+			case onStack:
+				// 回边：栈中从 callee 到当前函数的部分构成一个循环
+				start := len(stack) - 1
+				for stack[start] != callee {
+					start--
+				}
+				cycle := normalizeCycle(stack[start:])
+				key := strings.Join(cycle, "\x00")
+				if !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+	}
+
+	names := make([]string, 0, len(callGraph))
+	for name := range callGraph {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return strings.Join(cycles[i], ",") < strings.Join(cycles[j], ",")
+	})
+	return cycles
+}
+
+// normalizeCycle 复制循环并旋转为以最小名称开头，便于去重
+func normalizeCycle(path []string) []string {
+	minIdx := 0
+	for i, name := range path {
+		if name < path[minIdx] {
+			minIdx = i
+		}
+	}
+	cycle := make([]string, 0, len(path))
+	cycle = append(cycle, path[minIdx:]...)
+	return append(cycle, path[:minIdx]...)
+}
+
+// childNode 返回命名子节点
+func childNode(node astNode, key string) interface{} {
+	if children, ok := node.Children.(map[string]interface{}); ok {
+		return children[key]
+	}
+	return nil
+}
+
+// childString 返回字符串类型的命名子节点，不存在时返回空字符串
+func childString(node astNode, key string) string {
+	s, _ := childNode(node, key).(string)
+	return s
+}
+
+// calleeName 返回 AST_CALL 的函数名 (小写)，动态调用 ($f()) 返回空字符串
+func calleeName(expr interface{}) string {
+	nameNode, ok := expr.(astNode)
+	if !ok || nameNode.Kind != kindName {
+		return ""
+	}
+	name := strings.TrimPrefix(childString(nameNode, "name"), "\\")
+	return strings.ToLower(name)
+}
+
+// firstStringArg 返回调用的第一个参数为字符串字面量时的值
+func firstStringArg(call astNode) string {
+	args, ok := childNode(call, "args").(astNode)
+	if !ok {
+		return ""
+	}
+	list, ok := args.Children.([]interface{})
+	if !ok || len(list) == 0 {
+		return ""
+	}
+	s, _ := list[0].(string)
+	return strings.TrimPrefix(s, "\\")
+}
@@ -0,0 +1,104 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: AST 响应压缩测试：500KB 的 PHP 文件在压缩与未压缩模式下得到相同的 AST
+ */
+package ast
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// compressionTestSource 生成约 size 字节的 PHP 源码
+func compressionTestSource(size int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<?php\n")
+	for i := 0; buf.Len() < size; i++ {
+		fmt.Fprintf(&buf, "function handler_%d($request) {\n", i)
+		fmt.Fprintf(&buf, "    $name = isset($request['name']) ? trim($request['name']) : \"guest_%d\";\n", i)
+		buf.WriteString("    return htmlspecialchars(implode(' ', explode(',', $name)), ENT_QUOTES, 'UTF-8');\n}\n")
+	}
+	return buf.Bytes()
+}
+
+func TestGetASTCompressedMatchesUncompressed(t *testing.T) {
+	source := compressionTestSource(500 << 10)
+	m, bridge := startMockBridge(t)
+
+	uncompressed, err := m.GetAST(source)
+	if err != nil {
+		t.Fatalf("GetAST() uncompressed error = %v", err)
+	}
+	if n := bridge.gzipResponses.Load(); n != 0 {
+		t.Fatalf("bridge sent %d compressed responses without compression enabled", n)
+	}
+
+	m.SetCompression(true)
+	compressed, err := m.GetAST(source)
+	if err != nil {
+		t.Fatalf("GetAST() compressed error = %v", err)
+	}
+	if n := bridge.gzipResponses.Load(); n != 1 {
+		t.Fatalf("bridge sent %d compressed responses, want 1", n)
+	}
+
+	if !reflect.DeepEqual(compressed, uncompressed) {
+		t.Error("compressed and uncompressed AST differ")
+	}
+	if compressed != string(source) {
+		t.Error("AST does not match the source sent to the bridge")
+	}
+}
+
+// gzipResponse 以压缩标记 'C' 编码桥接响应
+func gzipResponse(t *testing.T, body string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("C%d\n%s", buf.Len(), buf.Bytes())
+}
+
+func TestCommunicateWithBridgeMarkers(t *testing.T) {
+	tests := []struct {
+		name       string
+		compress   bool
+		response   string
+		wantHeader string
+		want       string
+		wantErr    bool
+	}{
+		{"uncompressed marker", false, "U3\n{}\n", "5\n", "{}\n", false},
+		{"legacy bridge without marker", false, "3\n{}\n", "5\n", "{}\n", false},
+		{"compressed marker", true, gzipResponse(t, "{\"kind\":\"AST_STMT_LIST\"}\n"), "5 gzip\n", "{\"kind\":\"AST_STMT_LIST\"}\n", false},
+		{"zlib unavailable falls back to uncompressed", true, "U3\n{}\n", "5 gzip\n", "{}\n", false},
+		{"corrupt gzip", true, "C4\nnope", "5 gzip\n", "", true},
+		{"truncated body", false, "U10\n{}", "5\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdin bytes.Buffer
+			m := &PhpAstManager{}
+			got, err := m.communicateWithBridge(&stdin, strings.NewReader(tt.response), []byte("<?php"), tt.compress)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("communicateWithBridge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("communicateWithBridge() = %q, want %q", got, tt.want)
+			}
+			if header, _, _ := strings.Cut(stdin.String(), "<?php"); header != tt.wantHeader {
+				t.Errorf("request header = %q, want %q", header, tt.wantHeader)
+			}
+		})
+	}
+}
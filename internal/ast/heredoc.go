@@ -0,0 +1,65 @@
+/*
+ * @Date: 2025-05-20 10:12:41
+ * @Editors: Mr wpl
+ * @Description: heredoc/nowdoc 字符串内容提取
+ */
+package ast
+
+import (
+	"regexp"
+	"strings"
+)
+
+// heredocOpenRegex 匹配 heredoc (<<<EOT / <<<"EOT") 与 nowdoc (<<<'EOT') 的起始标记
+var heredocOpenRegex = regexp.MustCompile(`<<<[ \t]*(?:'([A-Za-z_][A-Za-z0-9_]*)'|"([A-Za-z_][A-Za-z0-9_]*)"|([A-Za-z_][A-Za-z0-9_]*))\r?\n`)
+
+/**
+ * @Description: 从PHP源码中提取所有 heredoc 和 nowdoc 字符串的内容
+ * @author: Mr wpl
+ * @param source []byte: PHP源码
+ * @return []string: 字符串内容列表，按出现顺序排列
+ */
+func ExtractHeredocContents(source []byte) []string {
+	var contents []string
+	src := string(source)
+
+	offset := 0
+	for offset < len(src) {
+		loc := heredocOpenRegex.FindStringSubmatchIndex(src[offset:])
+		if loc == nil {
+			break
+		}
+
+		// 取出匹配到的标签名 (三种写法只会有一个分组命中)
+		label := ""
+		for g := 1; g <= 3; g++ {
+			if loc[2*g] >= 0 {
+				label = src[offset+loc[2*g] : offset+loc[2*g+1]]
+				break
+			}
+		}
+		bodyStart := offset + loc[1]
+
+		// 结束标记: 独占一行的标签名 (PHP 7.3+ 允许缩进)，后面不能紧跟标识符字符
+		closeRegex, err := regexp.Compile(`(?m)^[ \t]*` + regexp.QuoteMeta(label) + `(?:[^A-Za-z0-9_]|$)`)
+		if err != nil {
+			offset = bodyStart
+			continue
+		}
+		closeLoc := closeRegex.FindStringIndex(src[bodyStart:])
+		if closeLoc == nil {
+			// 未闭合的 heredoc，跳过起始标记继续查找
+			offset = bodyStart
+			continue
+		}
+
+		body := src[bodyStart : bodyStart+closeLoc[0]]
+		body = strings.TrimSuffix(body, "\n")
+		body = strings.TrimSuffix(body, "\r")
+		contents = append(contents, body)
+
+		offset = bodyStart + closeLoc[0] + len(label)
+	}
+
+	return contents
+}
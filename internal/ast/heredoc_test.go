@@ -0,0 +1,77 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: heredoc/nowdoc 提取测试
+ */
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractHeredocContents(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "no heredoc",
+			source: "<?php\n$a = 'eval';\necho $a;\n",
+			want:   nil,
+		},
+		{
+			name:   "single heredoc",
+			source: "<?php\n$a = <<<EOT\nsystem($_GET['c']);\nEOT;\n",
+			want:   []string{"system($_GET['c']);"},
+		},
+		{
+			name:   "quoted heredoc label",
+			source: "<?php\n$a = <<<\"PAYLOAD\"\nassert($x);\nPAYLOAD;\n",
+			want:   []string{"assert($x);"},
+		},
+		{
+			name:   "nowdoc",
+			source: "<?php\n$a = <<<'EOT'\nbase64_decode('ZXZhbA==');\nEOT;\n",
+			want:   []string{"base64_decode('ZXZhbA==');"},
+		},
+		{
+			name: "multiple heredoc and nowdoc",
+			source: "<?php\n" +
+				"$a = <<<ONE\nfirst line\nsecond line\nONE;\n" +
+				"$b = <<<'TWO'\neval($b);\nTWO;\n" +
+				"$c = <<<THREE\ngzinflate\nTHREE;\n",
+			want: []string{"first line\nsecond line", "eval($b);", "gzinflate"},
+		},
+		{
+			name:   "indented closing label",
+			source: "<?php\nfunction f() {\n    return <<<EOT\n    shell_exec('id');\n    EOT;\n}\n",
+			want:   []string{"    shell_exec('id');"},
+		},
+		{
+			name:   "label prefix inside body is not a close",
+			source: "<?php\n$a = <<<EOT\nEOTX is not the end\nEOT;\n",
+			want:   []string{"EOTX is not the end"},
+		},
+		{
+			name:   "crlf line endings",
+			source: "<?php\r\n$a = <<<EOT\r\npassthru('ls');\r\nEOT;\r\n",
+			want:   []string{"passthru('ls');"},
+		},
+		{
+			name:   "unterminated heredoc",
+			source: "<?php\n$a = <<<EOT\nnever closed\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractHeredocContents([]byte(tt.source))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractHeredocContents() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	phpbridge "bt-shieldml/php-bridge" // 确认包路径
 	"bt-shieldml/pkg/logging"
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
@@ -12,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -28,17 +31,67 @@ type PhpAstManager struct {
 	phpStdin  io.WriteCloser // Go -> PHP
 	phpStdout io.ReadCloser  // PHP -> Go
 	phpExited chan error     // 监控进程退出
-	mu        sync.Mutex     // 保护对 PHP 进程管道的并发访问
-	isActive  bool           // 标记桥接是否仍被认为可用
+	mu        sync.Mutex     // 保护以下状态字段，GetAST 在整个请求期间持有
+	isActive  bool           // 标记桥接是否仍被认为可用，只能在持有 mu 时读写
+	ioMu      sync.Mutex     // 串行化对桥接的读写：超时后遗留的通信协程完成前，不会开始新的请求
+	shmem     *ShmemBridge   // 共享内存传输，为 nil 时使用管道
+	compress  bool           // 请求桥接以 gzip 压缩返回 AST JSON (仅管道传输)，只能在持有 mu 时读写
+	instance  int            // 桥接实例 ID：0 为进程内桥接，大于 0 为连接池中的桥接子进程
+	// shmemPendingClose Cleanup 时仍有通信协程使用的共享内存，由持有 ioMu 的协程结束时释放
+	shmemPendingClose atomic.Pointer[ShmemBridge]
 }
 
-// NewPhpAstManager 创建管理器实例并初始化（或获取）持久化桥接
+const (
+	// TransportPipe 通过管道与 PHP 桥接通信 (默认)
+	TransportPipe = "pipe"
+	// TransportShmem 通过共享内存与 PHP 桥接通信，适合大文件
+	TransportShmem = "shmem"
+)
+
+// NewPhpAstManager 创建管理器实例并初始化（或获取）持久化桥接，使用管道传输
 func NewPhpAstManager() (*PhpAstManager, error) {
+	return NewPhpAstManagerWithTransport(TransportPipe)
+}
+
+/**
+ * @Description: 按指定传输方式创建管理器实例。传输方式只在桥接首次启动时生效；
+ * 共享内存初始化失败时回退到管道传输。
+ * @author: Mr wpl
+ * @param transport string: "pipe" (默认) 或 "shmem"
+ * @return *PhpAstManager: 管理器
+ * @return error: 错误
+ */
+func NewPhpAstManagerWithTransport(transport string) (*PhpAstManager, error) {
+	return newPhpAstManager(transport, 0)
+}
+
+// newPhpAstManager 创建指定实例 ID 的管理器，共享内存传输只用于进程内桥接 (实例 0)
+func newPhpAstManager(transport string, instance int) (*PhpAstManager, error) {
+	var shmem *ShmemBridge
+	switch transport {
+	case "", TransportPipe:
+	case TransportShmem:
+		if instance > 0 {
+			break
+		}
+		var err error
+		shmem, err = NewShmemBridge()
+		if err != nil {
+			logging.WarnLogger.Printf("Shared memory bridge transport unavailable, falling back to pipe: %v", err)
+			shmem = nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown bridge transport %q", transport)
+	}
+
 	// 尝试启动或获取持久化桥接
-	stdin, stdout, exited, startErr := phpbridge.StartBridge()
+	stdin, stdout, exited, startErr := phpbridge.StartBridge(instance)
 	if startErr != nil {
 		// 如果启动失败，manager 无法工作
 		logging.ErrorLogger.Printf("Failed to start or get persistent PHP bridge: %v", startErr)
+		if shmem != nil {
+			shmem.Close()
+		}
 		return nil, startErr
 	}
 
@@ -47,6 +100,8 @@ func NewPhpAstManager() (*PhpAstManager, error) {
 		phpStdout: stdout,
 		phpExited: exited,
 		isActive:  true,
+		shmem:     shmem,
+		instance:  instance,
 	}
 
 	// 启动后台监控协程
@@ -55,6 +110,18 @@ func NewPhpAstManager() (*PhpAstManager, error) {
 	return manager, nil
 }
 
+/**
+ * @Description: 开启或关闭 AST 响应压缩。大文件的 AST JSON 可达数 MB，压缩后可减少管道 I/O；
+ * 共享内存传输不受影响
+ * @author: Mr wpl
+ * @param enabled bool: 是否压缩
+ */
+func (m *PhpAstManager) SetCompression(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.compress = enabled
+}
+
 // monitorExit 监控持久化 PHP 进程的退出事件
 func (m *PhpAstManager) monitorExit() {
 	if m.phpExited == nil {
@@ -83,6 +150,14 @@ func (m *PhpAstManager) monitorExit() {
 	}
 }
 
+// Ping 解析一段最小的 PHP 代码，确认桥接可用并完成首次请求的初始化
+func (m *PhpAstManager) Ping() error {
+	if _, err := m.GetAST([]byte("<?php\n")); err != nil {
+		return fmt.Errorf("php bridge ping failed: %w", err)
+	}
+	return nil
+}
+
 // GetAST 发送源码到持久化桥接并获取解析后的 AST 结构
 func (m *PhpAstManager) GetAST(source []byte) (interface{}, error) {
 	m.mu.Lock()         // 在开始任何操作前获取锁
@@ -93,9 +168,11 @@ func (m *PhpAstManager) GetAST(source []byte) (interface{}, error) {
 		return nil, fmt.Errorf("php bridge is not active or initialized")
 	}
 
-	// 在持有锁的情况下获取管道引用
+	// 在持有锁的情况下获取管道引用，通信协程只使用这些副本，不再访问 m 的字段
 	currentStdin := m.phpStdin
 	currentStdout := m.phpStdout
+	currentShmem := m.shmem
+	compress := m.compress
 
 	// 使用 context 控制超时，建议将 timeout 值设为可配置
 	timeout := 60 * time.Second // 暂时增加到 60 秒，后续可配置
@@ -107,7 +184,17 @@ func (m *PhpAstManager) GetAST(source []byte) (interface{}, error) {
 
 	// 启动通信 goroutine，但我们在持有锁的情况下等待它完成
 	go func() {
-		astData, err := m.communicateWithBridge(currentStdin, currentStdout, source)
+		// 超时返回后本协程可能仍在读写桥接，ioMu 保证下一个请求等待本次响应被完整读走，避免协议错位
+		m.ioMu.Lock()
+		defer m.releaseIO()
+
+		var astData []byte
+		var err error
+		if currentShmem != nil {
+			astData, err = currentShmem.Exchange(source)
+		} else {
+			astData, err = m.communicateWithBridge(currentStdin, currentStdout, source, compress)
+		}
 		if err != nil {
 			// 先检查是否是因为 context 超时/取消导致的错误
 			select {
@@ -156,14 +243,27 @@ func (m *PhpAstManager) GetAST(source []byte) (interface{}, error) {
 	}
 }
 
-// communicateWithBridge 处理底层发送/接收逻辑 (函数保持不变)
-func (m *PhpAstManager) communicateWithBridge(stdin io.Writer, stdout io.Reader, source []byte) ([]byte, error) {
+/**
+ * @Description: 处理底层发送/接收逻辑。请求头为 "<长度>\n"，压缩时为 "<长度> gzip\n"；
+ * 响应以标记字节开头：'C' 表示 gzip 压缩的 AST JSON，'U' 表示未压缩，旧版桥接无标记
+ * @author: Mr wpl
+ * @param stdin io.Writer: Go -> PHP
+ * @param stdout io.Reader: PHP -> Go
+ * @param source []byte: 源码
+ * @param compress bool: 是否请求压缩
+ * @return []byte: AST JSON
+ * @return error: 错误
+ */
+func (m *PhpAstManager) communicateWithBridge(stdin io.Writer, stdout io.Reader, source []byte, compress bool) ([]byte, error) {
 	srcLen := len(source)
 	if srcLen == 0 {
 		return nil, fmt.Errorf("cannot process empty source code")
 	}
 	// 1. 发送长度头
 	lenStr := strconv.Itoa(srcLen) + "\n"
+	if compress {
+		lenStr = strconv.Itoa(srcLen) + " gzip\n"
+	}
 	if _, err := stdin.Write([]byte(lenStr)); err != nil {
 		return nil, fmt.Errorf("failed to write length to php bridge: %w", err)
 	}
@@ -171,8 +271,24 @@ func (m *PhpAstManager) communicateWithBridge(stdin io.Writer, stdout io.Reader,
 	if _, err := stdin.Write(source); err != nil {
 		return nil, fmt.Errorf("failed to write source to php bridge: %w", err)
 	}
-	// 3. 读取响应长度头
 	reader := bufio.NewReader(stdout)
+	// 3. 读取压缩标记
+	compressed := false
+	marker, err := reader.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("failed to read response from php bridge (EOF reached), bridge likely closed unexpectedly")
+		}
+		return nil, fmt.Errorf("failed to read response from php bridge: %w", err)
+	}
+	switch marker {
+	case 'C':
+		compressed = true
+	case 'U':
+	default:
+		reader.UnreadByte() // 旧版桥接直接以长度开头
+	}
+	// 4. 读取响应长度头
 	lenBytes, err := reader.ReadBytes('\n')
 	if err != nil {
 		if err == io.EOF {
@@ -195,15 +311,27 @@ func (m *PhpAstManager) communicateWithBridge(stdin io.Writer, stdout io.Reader,
 		if readErr != nil && readErr != io.EOF {
 			logging.WarnLogger.Printf("Could not read error details after zero length: %v", readErr)
 		}
-		return nil, fmt.Errorf("php bridge reported a parse error (length 0). PHP error: %s", strings.TrimSpace(errorLine))
+		return nil, fmt.Errorf("%w: php bridge returned length 0. PHP error: %s", ErrPHPParse, strings.TrimSpace(errorLine))
 	}
-	// 4. 读取 AST 数据
+	// 5. 读取 AST 数据 (完整读出后再解压，解压失败也不会使协议错位)
 	astData := make([]byte, resultLen)
 	bytesRead, err := io.ReadFull(reader, astData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read full AST data from php bridge (expected %d, got %d): %w", resultLen, bytesRead, err)
 	}
-	return astData, nil
+	if !compressed {
+		return astData, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(astData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress AST data from php bridge: %w", err)
+	}
+	defer gz.Close()
+	decompressed, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress AST data from php bridge: %w", err)
+	}
+	return decompressed, nil
 }
 
 // GetWordsAndCallable 从解析后的 AST 中提取词汇和可调用状态
@@ -459,9 +587,36 @@ func cleanOpSerial(data [][]int, maxLen int) [][]int {
 func (m *PhpAstManager) Cleanup() error {
 	// 调用 php-bridge 的 StopBridge 来处理清理
 	// StopBridge 内部使用了 sync.Once 保证只清理一次
-	err := phpbridge.StopBridge() // 这里会处理 stdin/stdout 的关闭
+	err := phpbridge.StopBridge(m.instance) // 这里会处理 stdin/stdout 的关闭
 	m.mu.Lock()
 	m.isActive = false // 确保标记为 inactive
+	if m.shmem != nil {
+		// 仍有通信协程在使用共享内存时 (例如 PHP 未响应) 不能立即释放映射，登记为待释放，由该协程结束时释放
+		m.shmemPendingClose.Store(m.shmem)
+		m.closePendingShmem()
+		if m.shmemPendingClose.Load() != nil {
+			logging.WarnLogger.Println("Shared memory bridge still in use, it will be unmapped when the pending request finishes.")
+		}
+		m.shmem = nil
+	}
 	m.mu.Unlock()
 	return err
 }
+
+// releaseIO 通信协程结束时释放 ioMu，并释放期间由 Cleanup 登记的共享内存
+func (m *PhpAstManager) releaseIO() {
+	m.ioMu.Unlock()
+	m.closePendingShmem()
+}
+
+// closePendingShmem 存在待释放的共享内存且没有协程持有 ioMu 时释放映射。
+// Cleanup 与通信协程在各自登记或释放 ioMu 之后都会调用，两者中后执行的一方一定能看到待释放的映射
+func (m *PhpAstManager) closePendingShmem() {
+	if m.shmemPendingClose.Load() == nil || !m.ioMu.TryLock() {
+		return
+	}
+	if shmem := m.shmemPendingClose.Swap(nil); shmem != nil {
+		shmem.Close() // 在 ioMu 内释放，之后排队的请求会看到已关闭的共享内存并返回错误
+	}
+	m.ioMu.Unlock()
+}
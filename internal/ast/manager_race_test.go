@@ -0,0 +1,167 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: PhpAstManager 并发安全测试，使用模拟的桥接协议，不需要 CGo 的 PHP 桥接，需以 go test -race 运行
+ */
+package ast
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// mockBridgeInstance 模拟管理器使用的实例 ID，不对应任何桥接子进程，Cleanup 中的 StopBridge 不做任何事
+const mockBridgeInstance = 1 << 20
+
+// mockBridge 模拟桥接的统计信息
+type mockBridge struct {
+	gzipResponses atomic.Int32 // 以压缩标记 'C' 返回的响应数
+}
+
+/**
+ * @Description: 创建连接到模拟桥接的管理器。模拟桥接按管道协议读取 "<长度>\n<源码>" 或 "<长度> gzip\n<源码>"，
+ * 返回 {"ast": 源码}，未请求压缩时以标记 'U' 原样返回，请求压缩时以标记 'C' 返回 gzip 数据，
+ * 调用方可据此确认收到的是自己请求的响应
+ * @author: Mr wpl
+ * @param t *testing.T: 测试
+ * @return *PhpAstManager: 管理器
+ * @return *mockBridge: 模拟桥接
+ */
+func startMockBridge(t *testing.T) (*PhpAstManager, *mockBridge) {
+	t.Helper()
+	goStdinReader, goStdinWriter := io.Pipe()
+	goStdoutReader, goStdoutWriter := io.Pipe()
+	t.Cleanup(func() {
+		goStdinWriter.Close()
+		goStdoutReader.Close()
+	})
+
+	bridge := &mockBridge{}
+	go func() {
+		defer goStdoutWriter.Close()
+		reader := bufio.NewReader(goStdinReader)
+		for {
+			header, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(header)
+			n, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return
+			}
+			source := make([]byte, n)
+			if _, err := io.ReadFull(reader, source); err != nil {
+				return
+			}
+			payload, _ := json.Marshal(map[string]string{"ast": string(source)})
+			marker := "U"
+			if len(fields) > 1 && fields[1] == "gzip" {
+				var buf bytes.Buffer
+				gz := gzip.NewWriter(&buf)
+				gz.Write(payload)
+				gz.Close()
+				payload, marker = buf.Bytes(), "C"
+				bridge.gzipResponses.Add(1)
+			}
+			if _, err := fmt.Fprintf(goStdoutWriter, "%s%d\n%s", marker, len(payload), payload); err != nil {
+				return
+			}
+		}
+	}()
+
+	m := &PhpAstManager{
+		phpStdin:  goStdinWriter,
+		phpStdout: goStdoutReader,
+		phpExited: make(chan error, 1),
+		isActive:  true,
+		instance:  mockBridgeInstance,
+	}
+	go m.monitorExit()
+	return m, bridge
+}
+
+// newMockASTManager 创建连接到模拟桥接的管理器，见 startMockBridge
+func newMockASTManager(t *testing.T) *PhpAstManager {
+	t.Helper()
+	m, _ := startMockBridge(t)
+	return m
+}
+
+func TestGetAST_ConcurrentCalls(t *testing.T) {
+	m := newMockASTManager(t)
+
+	const goroutines = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			source := fmt.Sprintf("<?php echo %d;", i)
+			astRoot, err := m.GetAST([]byte(source))
+			if err != nil {
+				errs <- fmt.Errorf("goroutine %d: %w", i, err)
+				return
+			}
+			// 请求与响应错位时会收到其他协程的源码
+			if astRoot != source {
+				errs <- fmt.Errorf("goroutine %d received %v, want %q", i, astRoot, source)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestGetAST_ConcurrentWithCleanup(t *testing.T) {
+	m := newMockASTManager(t)
+
+	const goroutines = 50
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			source := fmt.Sprintf("<?php echo %d;", i)
+			// Cleanup 之前完成的请求必须得到自己的响应，之后的请求必须返回错误
+			astRoot, err := m.GetAST([]byte(source))
+			if err == nil && astRoot != source {
+				t.Errorf("goroutine %d received %v, want %q", i, astRoot, source)
+			}
+		}(i)
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-start
+		if err := m.Cleanup(); err != nil {
+			t.Errorf("Cleanup() error = %v", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		<-start
+		m.phpExited <- fmt.Errorf("bridge exited") // monitorExit 同时把桥接标记为不可用
+	}()
+	close(start)
+	wg.Wait()
+
+	if _, err := m.GetAST([]byte("<?php echo 'after';")); err == nil {
+		t.Error("GetAST() after Cleanup succeeded, want an inactive bridge error")
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: AST 结构混淆度量：动态变量名、编码字符串字面量与函数调用嵌套深度 (eval 链)
+ */
+package ast
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// php-ast 节点类型 (kind)，其余见 callgraph.go
+const (
+	kindClosure   = 68  // AST_CLOSURE
+	kindArrowFunc = 71  // AST_ARROW_FUNC
+	kindVar       = 256 // AST_VAR
+)
+
+// obfuscationMinSamples 计算比例时的最小分母，避免变量或字符串很少的小文件因一两处命中得到很高的比例
+const obfuscationMinSamples = 5
+
+// minEncodedStringLen 参与编码判断的字符串字面量最小长度 (字符)，更短的如 "\0" 不计
+const minEncodedStringLen = 4
+
+// hexEscapeReg 单引号字符串中保留原样的十六进制转义，如 '\x65\x76\x61\x6c'
+var hexEscapeReg = regexp.MustCompile(`^(\\x[0-9a-fA-F]{2})+$`)
+
+// nameKeys 值为标识符而不是字符串字面量的子节点名称
+var nameKeys = map[string]bool{
+	"name":       true,
+	"method":     true,
+	"prop":       true,
+	"class":      true,
+	"alias":      true,
+	"docComment": true,
+}
+
+// ObfuscationMetrics AST 中与混淆相关的计数
+type ObfuscationMetrics struct {
+	Vars           int // AST_VAR 节点数
+	DynamicVars    int // 变量名不是字面量的 AST_VAR ($$x、${'a'.'b'})
+	Strings        int // 长度不小于 minEncodedStringLen 的字符串字面量数
+	EncodedStrings int // 其中过半为不可打印字符或完全由 \xNN 转义组成的字符串
+	MaxCallDepth   int // AST_CALL 与 eval 的最大嵌套层数，函数与闭包内重新计数
+}
+
+/**
+ * @Description: 遍历 AST 统计混淆度量
+ * @author: Mr wpl
+ * @param astRoot interface{}: AST根节点
+ * @return ObfuscationMetrics: 度量
+ * @return error: 错误
+ */
+func MeasureObfuscation(astRoot interface{}) (ObfuscationMetrics, error) {
+	var m ObfuscationMetrics
+	if astRoot == nil {
+		return m, fmt.Errorf("cannot process nil AST")
+	}
+
+	var walk func(node interface{}, key string, depth int)
+	walk = func(node interface{}, key string, depth int) {
+		switch value := node.(type) {
+		case astNode:
+			switch value.Kind {
+			case kindVar:
+				m.Vars++
+				if _, literal := childNode(value, "name").(string); !literal {
+					m.DynamicVars++
+				}
+			case kindFuncDecl, kindMethod, kindClosure, kindArrowFunc:
+				depth = 0
+			case kindCall:
+				depth++
+			case kindIncludeOrEval:
+				if value.Flag == flagExecEval {
+					depth++
+				}
+			}
+			if depth > m.MaxCallDepth {
+				m.MaxCallDepth = depth
+			}
+			walk(value.Children, "", depth)
+		case []interface{}:
+			for _, item := range value {
+				walk(item, "", depth)
+			}
+		case map[string]interface{}:
+			for k, item := range value {
+				walk(item, k, depth)
+			}
+		case string:
+			if nameKeys[key] || utf8.RuneCountInString(value) < minEncodedStringLen {
+				return
+			}
+			m.Strings++
+			if isEncodedString(value) {
+				m.EncodedStrings++
+			}
+		}
+	}
+	walk(astRoot, "", 0)
+	return m, nil
+}
+
+// isEncodedString 字符串完全由 \xNN 转义组成，或至少一半为不可打印字符 (无效 UTF-8 字节、控制字符)
+func isEncodedString(s string) bool {
+	if hexEscapeReg.MatchString(s) {
+		return true
+	}
+	total, unprintable := 0, 0
+	for _, r := range s {
+		total++
+		if r == utf8.RuneError || (!unicode.IsPrint(r) && r != '\t' && r != '\n' && r != '\r') {
+			unprintable++
+		}
+	}
+	return unprintable*2 >= total
+}
+
+/**
+ * @Description: 合并三项度量为 0~1 的混淆分数 (noisy-OR)：score = 1 - (1-动态变量比例)(1-编码字符串比例)(1-嵌套得分)。
+ * 比例的分母至少为 obfuscationMinSamples；调用嵌套 2 层及以下 (如 trim(strtolower($s))) 不计分，6 层及以上为 1
+ * @author: Mr wpl
+ * @return float64: 混淆分数
+ */
+func (m ObfuscationMetrics) Score() float64 {
+	dynamic := ratio(m.DynamicVars, m.Vars)
+	encoded := ratio(m.EncodedStrings, m.Strings)
+	nesting := float64(m.MaxCallDepth-2) / 4
+	if nesting < 0 {
+		nesting = 0
+	} else if nesting > 1 {
+		nesting = 1
+	}
+	return 1 - (1-dynamic)*(1-encoded)*(1-nesting)
+}
+
+// ratio 计算比例，分母至少为 obfuscationMinSamples
+func ratio(hits int, total int) float64 {
+	if total < obfuscationMinSamples {
+		total = obfuscationMinSamples
+	}
+	return float64(hits) / float64(total)
+}
@@ -3,11 +3,19 @@ package ast
 
 import (
 	"bt-shieldml/pkg/logging"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 )
 
+// ErrPHPParse PHP 解析器报告源码存在语法错误
+var ErrPHPParse = errors.New("php parse error")
+
+// MaxRecoveryLines 部分恢复时最多从文件末尾去掉的行数
+const MaxRecoveryLines = 10
+
 // ParseAST 解析 JSON 并将其转换为 astNode 结构
 func ParseAST(data []byte) (interface{}, error) {
 	var rawData interface{} // 先解析到通用 interface{}
@@ -28,7 +36,7 @@ func ParseAST(data []byte) (interface{}, error) {
 			// 处理 PHP 解析器直接返回的错误
 			if reasonStr, ok := reason.(string); ok {
 				logging.ErrorLogger.Printf("PHP parser returned error: %s", reasonStr)
-				return nil, fmt.Errorf("php parser error: %s", reasonStr)
+				return nil, fmt.Errorf("%w: %s", ErrPHPParse, reasonStr)
 			}
 			logging.ErrorLogger.Printf("PHP parser returned unknown error structure: %v", reason)
 			return nil, fmt.Errorf("php parser returned unknown error")
@@ -46,6 +54,34 @@ func ParseAST(data []byte) (interface{}, error) {
 	return transformedAst, nil
 }
 
+// GetASTWithRecovery 获取 AST，源码存在语法错误时依次去掉末尾 1 到 MaxRecoveryLines 行重试，
+// 返回第一个能解析的截断版本的 AST 以及去掉的行数 (完整解析时为 0)。非语法错误直接返回
+func GetASTWithRecovery(mgr ASTManager, source []byte) (interface{}, int, error) {
+	goAST, err := mgr.GetAST(source)
+	if err == nil || !errors.Is(err, ErrPHPParse) {
+		return goAST, 0, err
+	}
+
+	truncated := bytes.TrimRight(source, "\r\n")
+	for lines := 1; lines <= MaxRecoveryLines; lines++ {
+		cut := bytes.LastIndexByte(truncated, '\n')
+		if cut <= 0 {
+			break // 没有更多可去掉的行
+		}
+		truncated = truncated[:cut]
+
+		partialAST, retryErr := mgr.GetAST(truncated)
+		if retryErr == nil {
+			logging.InfoLogger.Printf("Recovered partial AST after stripping the last %d lines", lines)
+			return partialAST, lines, nil
+		}
+		if !errors.Is(retryErr, ErrPHPParse) {
+			break
+		}
+	}
+	return nil, 0, err
+}
+
 // transformAstNode 将interface{} 转换为 astNode 结构
 func transformAstNode(nodeData interface{}) interface{} {
 	if nodeData == nil {
@@ -0,0 +1,109 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: PHP 桥接连接池：多个桥接并发解析 AST，避免高并发扫描时单个桥接成为瓶颈
+ */
+package ast
+
+import (
+	"bt-shieldml/pkg/logging"
+	"fmt"
+)
+
+// DefaultMaxPoolSize 未配置 ast.pool_size 时连接池大小的上限 (实际为 min(并发数, 4))
+const DefaultMaxPoolSize = 4
+
+/**
+ * @Description: PHP 桥接连接池，实现 ASTManager。第一个桥接在进程内运行 (可使用共享内存传输)，
+ * 其余为独立的桥接子进程 (管道传输)。GetAST 从 idle 中取出一个空闲桥接，用完放回，
+ * 没有空闲桥接时等待
+ * @author: Mr wpl
+ */
+type PhpAstManagerPool struct {
+	managers []*PhpAstManager
+	idle     chan *PhpAstManager // 空闲桥接，容量为池大小，兼作并发名额
+}
+
+/**
+ * @Description: 启动 size 个 PHP 桥接组成连接池。第一个桥接启动失败时返回错误；
+ * 其余桥接启动失败时记录警告，以已启动的桥接继续运行
+ * @author: Mr wpl
+ * @param size int: 池大小，小于 1 时为 1
+ * @param transport string: 第一个桥接的传输方式 ("pipe" 或 "shmem")，子进程桥接总是使用管道
+ * @return *PhpAstManagerPool: 连接池
+ * @return error: 错误
+ */
+func NewPhpAstManagerPool(size int, transport string) (*PhpAstManagerPool, error) {
+	if size < 1 {
+		size = 1
+	}
+	first, err := newPhpAstManager(transport, 0)
+	if err != nil {
+		return nil, err
+	}
+	managers := []*PhpAstManager{first}
+	for i := 1; i < size; i++ {
+		m, err := newPhpAstManager(TransportPipe, i)
+		if err != nil {
+			logging.WarnLogger.Printf("Failed to start PHP bridge %d of %d, continuing with %d: %v", i+1, size, len(managers), err)
+			break
+		}
+		managers = append(managers, m)
+	}
+
+	idle := make(chan *PhpAstManager, len(managers))
+	for _, m := range managers {
+		idle <- m
+	}
+	return &PhpAstManagerPool{managers: managers, idle: idle}, nil
+}
+
+// Size 池中桥接的数量
+func (p *PhpAstManagerPool) Size() int {
+	return len(p.managers)
+}
+
+// SetCompression 开启或关闭所有桥接的 AST 响应压缩
+func (p *PhpAstManagerPool) SetCompression(enabled bool) {
+	for _, m := range p.managers {
+		m.SetCompression(enabled)
+	}
+}
+
+// GetAST 取出一个空闲桥接解析源码，用完后放回
+func (p *PhpAstManagerPool) GetAST(source []byte) (interface{}, error) {
+	m := <-p.idle
+	defer func() { p.idle <- m }()
+	return m.GetAST(source)
+}
+
+// GetWordsAndCallable 从解析后的 AST 中提取词汇和可调用状态 (不访问桥接)
+func (p *PhpAstManagerPool) GetWordsAndCallable(astRoot interface{}) ([]string, bool, error) {
+	return p.managers[0].GetWordsAndCallable(astRoot)
+}
+
+// GetOpSerial 从解析后的 AST 中提取操作序列 (不访问桥接)
+func (p *PhpAstManagerPool) GetOpSerial(astRoot interface{}) ([][]int, error) {
+	return p.managers[0].GetOpSerial(astRoot)
+}
+
+// Ping 依次确认每个桥接可用，返回第一个失败的桥接的错误
+func (p *PhpAstManagerPool) Ping() error {
+	for i, m := range p.managers {
+		if err := m.Ping(); err != nil {
+			return fmt.Errorf("bridge %d of %d: %w", i+1, len(p.managers), err)
+		}
+	}
+	return nil
+}
+
+// Cleanup 停止池中所有桥接，返回第一个错误
+func (p *PhpAstManagerPool) Cleanup() error {
+	var firstErr error
+	for _, m := range p.managers {
+		if err := m.Cleanup(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,99 @@
+//go:build !windows
+
+/*
+ * @Date: 2025-05-26 10:12:38
+ * @Editors: Mr wpl
+ * @Description: 基于共享内存的 PHP 桥接传输，避免大文件经管道在内核中拷贝
+ */
+package ast
+
+import (
+	phpbridge "bt-shieldml/php-bridge"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// shmemInputCap 输入缓冲区大小，需大于引擎的单文件大小限制 (10MB)
+	shmemInputCap = 16 * 1024 * 1024
+	// shmemOutputCap 输出缓冲区大小，AST JSON 通常是源码的数十倍；匿名映射按需分配物理页
+	shmemOutputCap = 512 * 1024 * 1024
+)
+
+// ShmemBridge 通过 mmap 共享内存与 PHP 桥接交换数据
+type ShmemBridge struct {
+	region []byte // 整个映射区域: 头部 | 输入缓冲区 | 输出缓冲区
+	input  []byte
+	output []byte
+}
+
+/**
+ * @Description: 创建共享内存区域并登记到 php-bridge，必须在桥接启动前调用
+ * @author: Mr wpl
+ * @return *ShmemBridge: 共享内存桥接
+ * @return error: 错误
+ */
+func NewShmemBridge() (*ShmemBridge, error) {
+	size := phpbridge.ShmemHeaderSize + shmemInputCap + shmemOutputCap
+	region, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_ANON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mmap shared memory (%d bytes): %w", size, err)
+	}
+
+	if err := phpbridge.AttachShmem(region, shmemInputCap, shmemOutputCap); err != nil {
+		unix.Munmap(region)
+		return nil, err
+	}
+
+	inputStart := phpbridge.ShmemHeaderSize
+	outputStart := inputStart + shmemInputCap
+	return &ShmemBridge{
+		region: region,
+		input:  region[inputStart:outputStart],
+		output: region[outputStart : outputStart+shmemOutputCap],
+	}, nil
+}
+
+/**
+ * @Description: 将源码写入共享输入缓冲区，等待 PHP 返回 AST JSON
+ * @author: Mr wpl
+ * @param source []byte: PHP 源码
+ * @return []byte: AST JSON (已从共享内存复制)
+ * @return error: 错误
+ */
+func (b *ShmemBridge) Exchange(source []byte) ([]byte, error) {
+	if len(source) == 0 {
+		return nil, fmt.Errorf("cannot process empty source code")
+	}
+	if b.region == nil {
+		return nil, fmt.Errorf("shared memory bridge is closed")
+	}
+	if len(source) > len(b.input) {
+		return nil, fmt.Errorf("source too large for shared memory transport: %d bytes (max %d)", len(source), len(b.input))
+	}
+
+	copy(b.input, source)
+	n, err := phpbridge.ShmemExchange(len(source))
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("php bridge returned empty response")
+	}
+
+	// 复制出共享区域，下一次请求会覆盖输出缓冲区
+	astData := make([]byte, n)
+	copy(astData, b.output[:n])
+	return astData, nil
+}
+
+// Close 释放共享内存映射，须在 StopBridge 之后调用
+func (b *ShmemBridge) Close() error {
+	if b.region == nil {
+		return nil
+	}
+	err := unix.Munmap(b.region)
+	b.region, b.input, b.output = nil, nil, nil
+	return err
+}
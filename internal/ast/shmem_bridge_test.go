@@ -0,0 +1,117 @@
+//go:build !windows
+
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 共享内存桥接测试：Cleanup 释放映射，以及管道与共享内存两种传输的 1MB PHP 文件基准测试
+ */
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// benchSourceSize 基准测试使用的 PHP 源码大小
+const benchSourceSize = 1 << 20
+
+// benchPHPSource 生成约 size 字节的 PHP 源码，由大量普通函数组成
+func benchPHPSource(size int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<?php\n")
+	for i := 0; buf.Len() < size; i++ {
+		fmt.Fprintf(&buf, "function handler_%d($request) {\n", i)
+		fmt.Fprintf(&buf, "    $name = isset($request['name']) ? trim($request['name']) : 'guest_%d';\n", i)
+		buf.WriteString("    $items = array_map('strtoupper', explode(',', $name));\n")
+		buf.WriteString("    return htmlspecialchars(implode(' ', $items), ENT_QUOTES, 'UTF-8');\n")
+		buf.WriteString("}\n")
+	}
+	return buf.Bytes()
+}
+
+/**
+ * @Description: 以指定传输方式与实例运行 GetAST 基准测试，PHP 桥接不可用时跳过
+ * @author: Mr wpl
+ * @param b *testing.B: 基准测试
+ * @param transport string: 传输方式
+ * @param instance int: 桥接实例 ID
+ */
+func benchmarkBridge(b *testing.B, transport string, instance int) {
+	mgr, err := newPhpAstManager(transport, instance)
+	if err != nil {
+		b.Skipf("php bridge unavailable: %v", err)
+	}
+	defer mgr.Cleanup()
+	if err := mgr.Ping(); err != nil {
+		b.Skipf("php bridge unavailable: %v", err)
+	}
+	if transport == TransportShmem && mgr.shmem == nil {
+		b.Skip("shared memory transport unavailable, manager fell back to pipe")
+	}
+
+	source := benchPHPSource(benchSourceSize)
+	b.SetBytes(int64(len(source)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mgr.GetAST(source); err != nil {
+			b.Fatalf("GetAST() error = %v", err)
+		}
+	}
+}
+
+// BenchmarkBridgePipe 管道传输。进程内桥接 (实例 0) 每个进程只能启动一次且传输方式固定，
+// 这里使用桥接子进程 (实例 1)，它只支持管道，与 BenchmarkBridgeShmem 可在同一次 go test 中运行
+func BenchmarkBridgePipe(b *testing.B) {
+	benchmarkBridge(b, TransportPipe, 1)
+}
+
+// BenchmarkBridgeShmem 共享内存传输，只有进程内桥接 (实例 0) 支持
+func BenchmarkBridgeShmem(b *testing.B) {
+	benchmarkBridge(b, TransportShmem, 0)
+}
+
+// newTestShmemBridge 创建一个未登记到 php-bridge 的小共享内存区域，只用于测试映射的释放
+func newTestShmemBridge(t *testing.T) *ShmemBridge {
+	t.Helper()
+	region, err := unix.Mmap(-1, 0, 8192, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_ANON)
+	if err != nil {
+		t.Skipf("mmap unavailable: %v", err)
+	}
+	return &ShmemBridge{region: region, input: region[:4096], output: region[4096:]}
+}
+
+func TestCleanupUnmapsIdleShmem(t *testing.T) {
+	shmem := newTestShmemBridge(t)
+	m := &PhpAstManager{isActive: true, shmem: shmem, instance: mockBridgeInstance}
+
+	m.Cleanup()
+	if shmem.region != nil {
+		t.Error("Cleanup() left an idle shared memory mapping in place")
+	}
+}
+
+// TestCleanupDefersShmemUnmap 通信协程仍持有 ioMu 时，映射在其结束时释放，而不是被遗弃
+func TestCleanupDefersShmemUnmap(t *testing.T) {
+	shmem := newTestShmemBridge(t)
+	m := &PhpAstManager{isActive: true, shmem: shmem, instance: mockBridgeInstance}
+
+	m.ioMu.Lock() // 模拟超时后仍在等待 PHP 响应的通信协程
+	m.Cleanup()
+	if shmem.region == nil {
+		t.Fatal("Cleanup() unmapped shared memory that is still in use")
+	}
+	if m.shmem != nil {
+		t.Error("Cleanup() kept the shared memory bridge on the manager")
+	}
+
+	m.releaseIO() // 通信协程结束
+	if shmem.region != nil {
+		t.Error("shared memory was not unmapped when the pending request finished")
+	}
+	if _, err := shmem.Exchange([]byte("<?php")); err == nil {
+		t.Error("Exchange() on a closed shared memory bridge succeeded")
+	}
+}
@@ -0,0 +1,26 @@
+/*
+ * @Date: 2025-05-26 10:12:38
+ * @Editors: Mr wpl
+ * @Description: Windows 下不支持共享内存传输
+ */
+package ast
+
+import "fmt"
+
+// ShmemBridge Windows 下的占位实现
+type ShmemBridge struct{}
+
+// NewShmemBridge Windows 下始终返回错误，调用方应回退到管道传输
+func NewShmemBridge() (*ShmemBridge, error) {
+	return nil, fmt.Errorf("shmem bridge transport is not supported on windows")
+}
+
+// Exchange 占位实现
+func (b *ShmemBridge) Exchange(source []byte) ([]byte, error) {
+	return nil, fmt.Errorf("shmem bridge transport is not supported on windows")
+}
+
+// Close 占位实现
+func (b *ShmemBridge) Close() error {
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+ * @Date: 2025-05-30 09:21:45
+ * @Editors: Mr wpl
+ * @Description: 以带注释的 YAML 输出生效配置 (-dump-config)
+ */
+package config
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldDoc 配置项说明，键为以 "." 连接的 YAML 路径，输出时作为行尾注释
+var FieldDoc = map[string]string{
+	"data_paths":                           "Data file locations",
+	"data_paths.models":                    "Used by SVM, Bayes",
+	"data_paths.signatures":                "Used by Hash, YARA",
+	"data_paths.config":                    "Used by Statistical, potentially SVM (for hashState.json)",
+	"data_paths.rules":                     "Explicit rule path (optional)",
+	"performance":                          "Performance tuning",
+	"performance.concurrency":              "Number of files scanned in parallel",
+	"performance.max_findings_per_file":    "Keep only the highest-risk findings per file (default 50)",
+	"performance.segmented_scan_threshold": "Files larger than this many bytes are scanned in overlapping segments with regex and YARA only; AST analysis is skipped, known-bad and whitelisted hashes are matched on the full content (default 10485760)",
+	"performance.shutdown_timeout":         "Seconds to wait for files still being scanned after SIGTERM before writing a partial report (default 30)",
+	"performance.file_scan_timeout":        "Time allowed to scan one file, e.g. 120s; timed-out files are reported as scan errors (default 120s, -1s disables)",
+	"performance.analyzer_timeout":         "Time allowed per analyzer per file, e.g. 500ms; a timed-out analyzer is recorded as \"analyzer timed out\" and not scored (default 30s, -1s disables)",
+	"performance.min_file_size_bytes":      "Files smaller than this many bytes are skipped and reported Safe (default 0, disabled; -min-size 512B)",
+	"performance.max_file_size_bytes":      "Files larger than this many bytes are skipped and reported Safe (default 0, disabled; -max-size 50MB)",
+	"performance.parallel_analyzers":       "Run content-only analyzers (regex, yara, hash, statistical) concurrently within a file; AST/ML analyzers run after them (default true)",
+	"performance.short_circuit":            "Skip a file's remaining analyzers once one reports Critical (default true); analyzers already running in parallel still finish. false always runs every analyzer",
+	"output":                               "Report output",
+	"output.format":                        "console, json, html, ndjson, lsp, sarif, csv, or junit (Default if -output not used)",
+	"output.console_template":              "text/template file or inline template for the console report (empty = built-in format)",
+	"output.sort_keys":                     "Report order: risk_desc, risk_asc, path_asc, path_desc, size_desc, duration_desc, mtime_desc",
+	"output.min_confidence":                "Suppress findings with confidence below this value (0 = no filter; findings without a confidence are kept)",
+	"output.pdf_font":                      "UTF-8 TrueType font for .pdf reports, e.g. a CJK font (empty = built-in Helvetica with English labels)",
+	"enabled_analyzers":                    "regex, python_regex, htaccess, yara, statistical, jsp_statistical, bayes_words, svm_prosses, random_forest, ngram, entropy_string, callgraph, obfuscation, fingerprint, ssdeep, shebang, superglobal",
+	"bridge_transport":                     "PHP bridge transport: pipe (default) or shmem (not on Windows)",
+	"ast":                                  "PHP bridge pool settings",
+	"ast.pool_size":                        "Number of PHP bridges parsing ASTs concurrently (default min(performance.concurrency, 4)); the first runs in-process, the others as worker processes (pipe transport)",
+	"early_exit":                           "Skip remaining analyzers (and remaining segments of large files) once one reports Critical",
+	"callgraph":                            "callgraph analyzer settings",
+	"callgraph.suspicious_functions":       "Functions that make a call cycle Critical (empty = built-in list)",
+	"ssdeep":                               "ssdeep analyzer settings",
+	"logging":                              "Log output settings",
+	"logging.format":                       "text (default) or json: one JSON object per line with level, time, caller, message and context fields (scan_session_id, file_path, duration_ms, analyzer)",
+	"logging.level":                        "Minimum log level: debug, info (default), warn, error",
+	"history":                              "SQLite scan history used by -history-report",
+	"history.enabled":                      "Record every completed scan (per-file risk level, SHA-256, duration) in the history database",
+	"history.db_path":                      "Scan history database (default data/scan_history.db)",
+	"watch":                                "-watch mode settings",
+	"watch.debounce":                       "Seconds a changed file must stay unchanged before it is rescanned (default 2)",
+	"watch.webhook_url":                    "URL to POST a JSON alert to when a rescanned file's risk increases (-webhook overrides; empty = log only)",
+	"hash":                                 "hash analyzer settings",
+	"hash.fp_rate":                         "Target false-positive rate of the bloom filter that pre-screens <data_paths.signatures>/SampleHash.txt (default 0.001); candidates are confirmed by an exact lookup",
+	"ssdeep.threshold":                     "Report files whose ssdeep similarity (0-100) to a known webshell in <data_paths.signatures>/ssdeep_hashes.txt exceeds this value (default 80)",
+	"email":                                "Email notification when a scan finds High or Critical files (disabled when smtp_host or to is empty)",
+	"email.smtp_host":                      "SMTP server",
+	"email.smtp_port":                      "SMTP port (default 25); STARTTLS is used when the server supports it",
+	"email.username":                       "SMTP username; empty for an unauthenticated relay. ${ENV_VAR} references are expanded",
+	"email.password":                       "SMTP password, e.g. ${SMTP_PASSWORD} to read it from the environment",
+	"email.from":                           "Sender address",
+	"email.to":                             "Recipient addresses",
+	"slack":                                "Slack Incoming Webhook notification for risky files (disabled when webhook_url is empty)",
+	"slack.webhook_url":                    "Incoming Webhook URL (-slack-webhook overrides)",
+	"slack.min_risk":                       "Minimum file risk that triggers a message: low, medium, high or critical (default high)",
+	"virustotal":                           "Optional VirusTotal lookups for risky files (disabled when api_key is empty)",
+	"virustotal.api_key":                   "VirusTotal API key",
+	"virustotal.concurrency":               "Parallel lookups (0 = 4)",
+	"virustotal.requests_per_minute":       "API rate limit (free accounts: 4)",
+	"virustotal.cache_path":                "Lookup cache, results kept 7 days (empty = data/vt_cache.db)",
+	"asset_provider":                       "Deployed asset inventory: file (data_paths.config/deployed_assets.json), file:<path>, or a CMDB http(s) URL; known files are capped at Low",
+	"ast_compression":                      "gzip-compress AST JSON sent by the PHP bridge over the pipe transport",
+	"scan_extensions":                      "File extensions scanned when walking directories, e.g. [.php, .jsp, .jspx, .py] (default [.php]); JSP and Python files skip the PHP AST bridge",
+	"state_path":                           "-incremental scan state database (default data/scan_state.db); -reset-state deletes it",
+	"whitelist_path":                       "Whitelist file: one path glob (** spans directories, relative patterns match at any depth) or sha256:<hash> per line; matching files are reported Safe without running analyzers (-whitelist overrides)",
+	"scoring":                              "Scoring rules: points per analyzer hit, the score cap and the minimum score of each risk level; omitted fields keep their defaults",
+	"scoring.regex":                        "Points for a regex match (default 1)",
+	"scoring.yara":                         "Points for a YARA match (default 1)",
+	"scoring.regex_and_yara":               "Extra points when regex and YARA both match (default 2)",
+	"scoring.callable_high_confidence":     "Points when callable is true and svm_prosses confidence exceeds high_confidence (default 2)",
+	"scoring.callable_statistical":         "Points when callable is true and the statistical analyzer reports an anomaly (default 2)",
+	"scoring.high_confidence":              "svm_prosses confidence above which a prediction counts as high confidence (default 0.91)",
+	"scoring.max_score":                    "Score cap (default 5)",
+	"scoring.critical_score":               "Minimum score for Critical (default 5)",
+	"scoring.high_score":                   "Minimum score for High (default 4)",
+	"scoring.medium_score":                 "Minimum score for Medium (default 3)",
+	"scoring.low_score":                    "Minimum score for Low (default 1)",
+	"scan_archives":                        "Unpack .phar, .zip and .tar.gz/.tgz archives and scan the files inside (same as -scan-archives)",
+	"exclusions":                           "Paths or glob patterns to skip, merged with -exclude: vendor/**, *.min.php, /abs/path/**; ** matches any number of directories, relative globs match at any depth",
+	"scan_htaccess":                        "Also collect .htaccess files while walking directories, checked by the htaccess analyzer",
+	"max_archive_size":                     "Total bytes extracted from one archive, nested archives included (0 = 100 MB, -1 = unlimited)",
+}
+
+/**
+ * @Description: 将配置序列化为 YAML，并按 FieldDoc 为每个字段添加注释
+ * @author: Mr wpl
+ * @param cfg *types.Config: 配置
+ * @return []byte: YAML 内容
+ * @return error: 错误
+ */
+func DumpConfig(cfg *types.Config) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("编码配置失败: %w", err)
+	}
+	annotateNode(&node, "")
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+/**
+ * @Description: 递归为映射节点的键添加注释
+ * @author: Mr wpl
+ * @param node *yaml.Node: YAML 节点
+ * @param prefix string: 当前节点的 YAML 路径
+ */
+func annotateNode(node *yaml.Node, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		if doc, ok := FieldDoc[path]; ok {
+			if value.Kind == yaml.ScalarNode {
+				value.LineComment = doc
+			} else {
+				key.HeadComment = doc
+			}
+		}
+		annotateNode(value, path)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"bt-shieldml/pkg/types"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -37,16 +40,26 @@ func LoadConfig(configPath string) (*types.Config, error) {
 		}
 	}
 
-	cfg := &types.Config{}
+	// 预置默认评分规则，配置文件只需填写要修改的字段
+	cfg := &types.Config{Scoring: types.DefaultScoringRules()}
 	if err := yaml.Unmarshal(configData, cfg); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
+	// 凭据可写为 ${ENV_VAR}，避免明文写在配置文件中
+	cfg.Email.Username = expandEnvRefs(cfg.Email.Username)
+	cfg.Email.Password = expandEnvRefs(cfg.Email.Password)
+
 	// 验证必要的配置
 	if err := validateConfig(cfg); err != nil {
 		return nil, err
 	}
 
+	// 按 logging 段设置日志格式与级别，此后的日志均按该设置输出
+	if err := logging.Configure(cfg.Logging.Format, cfg.Logging.Level); err != nil {
+		return nil, fmt.Errorf("无效的日志配置: %w", err)
+	}
+
 	return cfg, nil
 }
 
@@ -56,6 +69,7 @@ func LoadConfig(configPath string) (*types.Config, error) {
  * @return *types.Config: 配置
  */
 func GetDefaultConfig() *types.Config {
+	earlyExit, parallel, shortCircuit := true, true, true
 	return &types.Config{
 		DataPaths: types.DataPaths{
 			Models:     "data/models",
@@ -63,7 +77,11 @@ func GetDefaultConfig() *types.Config {
 			Config:     "data/config",
 		},
 		Performance: types.Performance{
-			Concurrency: 8,
+			Concurrency:            8,
+			MaxFindingsPerFile:     types.DefaultMaxFindingsPerFile,
+			SegmentedScanThreshold: types.DefaultSegmentedScanThreshold,
+			ParallelAnalyzers:      &parallel,
+			ShortCircuit:           &shortCircuit,
 		},
 		Output: types.Output{
 			Format: "console",
@@ -75,6 +93,10 @@ func GetDefaultConfig() *types.Config {
 			"bayes_words",
 			"svm_prosses",
 		},
+		BridgeTransport: "pipe",
+		EarlyExit:       &earlyExit,
+		ScanExtensions:  []string{types.DefaultScanExtension},
+		Scoring:         types.DefaultScoringRules(),
 	}
 }
 
@@ -86,9 +108,73 @@ func GetDefaultConfig() *types.Config {
  */
 func validateConfig(cfg *types.Config) error {
 	// 实现配置验证逻辑
+	switch cfg.BridgeTransport {
+	case "":
+		cfg.BridgeTransport = "pipe"
+	case "pipe", "shmem":
+	default:
+		return fmt.Errorf("无效的 bridge_transport: %s (可选 pipe、shmem)", cfg.BridgeTransport)
+	}
+	if cfg.Performance.MaxFindingsPerFile <= 0 {
+		cfg.Performance.MaxFindingsPerFile = types.DefaultMaxFindingsPerFile
+	}
+	if cfg.Performance.SegmentedScanThreshold <= 0 {
+		cfg.Performance.SegmentedScanThreshold = types.DefaultSegmentedScanThreshold
+	}
+	if cfg.Performance.MinFileSizeBytes < 0 || cfg.Performance.MaxFileSizeBytes < 0 {
+		return fmt.Errorf("无效的文件大小限制: min_file_size_bytes 与 max_file_size_bytes 不能为负数")
+	}
+	if max := cfg.Performance.MaxFileSizeBytes; max > 0 && cfg.Performance.MinFileSizeBytes > max {
+		return fmt.Errorf("无效的文件大小限制: min_file_size_bytes (%d) 大于 max_file_size_bytes (%d)", cfg.Performance.MinFileSizeBytes, max)
+	}
+	// 不带单位的整数按纳秒解析，"120" 会变成 120ns
+	for _, timeout := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"file_scan_timeout", cfg.Performance.FileScanTimeout},
+		{"analyzer_timeout", cfg.Performance.AnalyzerTimeout},
+	} {
+		if timeout.d > 0 && timeout.d < time.Millisecond {
+			return fmt.Errorf("无效的 %s: %s (请带单位，如 120s)", timeout.key, timeout.d)
+		}
+	}
+	if cfg.EarlyExit == nil {
+		earlyExit := true
+		cfg.EarlyExit = &earlyExit
+	}
+	if cfg.Performance.ParallelAnalyzers == nil {
+		parallel := true
+		cfg.Performance.ParallelAnalyzers = &parallel
+	}
+	if cfg.Performance.ShortCircuit == nil {
+		shortCircuit := true
+		cfg.Performance.ShortCircuit = &shortCircuit
+	}
+	if _, err := cfg.Slack.MinRiskLevel(); err != nil {
+		return fmt.Errorf("无效的 slack.min_risk: %w", err)
+	}
+	if cfg.ScanHtaccess && !slices.Contains(cfg.EnabledAnalyzers, "htaccess") {
+		logging.WarnLogger.Printf("scan_htaccess 已开启但 enabled_analyzers 中没有 htaccess，.htaccess 文件只由 regex、yara 等通用分析器检查")
+	}
 	return nil
 }
 
+// envRefPattern 配置值中的环境变量引用 ${NAME}
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvRefs 将 ${NAME} 替换为环境变量的值 (未设置时为空并记录警告)；只识别带花括号的写法，密码中的其他 $ 保持原样
+func expandEnvRefs(value string) string {
+	return envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRefPattern.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			logging.WarnLogger.Printf("配置引用的环境变量 %s 未设置", name)
+		}
+		return v
+	})
+}
+
 // Helper function to check if a command-line flag was explicitly set
 // (Requires integrating with flag package in main.go)
 func flagWasSet(name string) bool {
@@ -0,0 +1,48 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 可读文件大小解析，如 "512B"、"64KB"、"10MB"，用于 -min-size、-max-size
+ */
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits 大小单位 (按 1024 进位)，长的后缀在前，避免 "MB" 被 "B" 先匹配
+var sizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+/**
+ * @Description: 解析可读的文件大小，单位不区分大小写且按 1024 进位 (B、KB、MB、GB，也接受 K、KiB 等写法)，
+ * 没有单位时为字节数，允许小数 (如 "1.5MB")
+ * @author: Mr wpl
+ * @param s string: 大小，如 "512B"、"10MB"
+ * @return int64: 字节数
+ * @return error: 格式无效或为负数时返回错误
+ */
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512B, 64KB, 10MB)", s)
+	}
+	return int64(n * multiplier), nil
+}
@@ -0,0 +1,82 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 引擎初始化时校验分析器契约：enabled_analyzers 中的名称是否已知、RequiredFeatures 是否可满足
+ */
+package engine
+
+import (
+	"bt-shieldml/pkg/logging"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// featureNeedsAST RequiredFeatures 可使用的特征键 (与 canRunAnalyzer 一致) 及其是否依赖 AST 管理器
+var featureNeedsAST = map[string]bool{
+	"statistical":     false,
+	"callable":        false, // AST 不可用时为 false，不影响分析器运行
+	"ast_callable":    false,
+	"ast_words":       true,
+	"ast_op_sequence": true,
+	"raw_ast":         true,
+	"superglobals":    true,
+	"obfuscation":     true,
+}
+
+/**
+ * @Description: 对 enabled_analyzers 中未知的分析器名称记录警告 (不中断初始化)
+ * @author: Mr wpl
+ * @param names []string: 配置中的分析器名称
+ */
+func warnUnknownAnalyzers(names []string) {
+	known := make([]string, 0, len(analyzerPriority))
+	for name := range analyzerPriority {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	for _, name := range names {
+		if _, ok := analyzerPriority[strings.ToLower(name)]; !ok {
+			logger := logging.WithCtx(context.Background())
+			logger.Warn().Str(logging.FieldAnalyzer, name).
+				Msgf("Unknown analyzer '%s' in enabled_analyzers is ignored (known analyzers: %s)", name, strings.Join(known, ", "))
+		}
+	}
+}
+
+/**
+ * @Description: 校验已初始化分析器的 RequiredFeatures。AST 管理器不可用时，移除依赖 AST 特征的分析器并记录警告，
+ * 避免其对每个文件都因缺少特征而静默跳过
+ * @author: Mr wpl
+ * @param analyzers map[string]Analyzer: 已初始化的分析器，不可满足的分析器从中删除
+ * @param astAvailable bool: AST 管理器是否可用
+ * @return error: 分析器声明了未知的特征键时返回错误
+ */
+func validateAnalyzerContracts(analyzers map[string]Analyzer, astAvailable bool) error {
+	names := make([]string, 0, len(analyzers))
+	for name := range analyzers {
+		names = append(names, name)
+	}
+	sortAnalyzerNames(names)
+
+	for _, name := range names {
+		var astFeatures []string
+		for _, feature := range analyzers[name].RequiredFeatures() {
+			needsAST, ok := featureNeedsAST[strings.ToLower(feature)]
+			if !ok {
+				return fmt.Errorf("analyzer '%s' requires unknown feature %q", name, feature)
+			}
+			if needsAST {
+				astFeatures = append(astFeatures, feature)
+			}
+		}
+		if len(astFeatures) > 0 && !astAvailable {
+			logger := logging.WithCtx(context.Background())
+			logger.Warn().Str(logging.FieldAnalyzer, name).Strs("features", astFeatures).
+				Msgf("Disabling analyzer '%s': it requires %s but the AST manager is not available", name, strings.Join(astFeatures, ", "))
+			delete(analyzers, name)
+		}
+	}
+	return nil
+}
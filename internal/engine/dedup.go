@@ -0,0 +1,172 @@
+/*
+ * @Date: 2025-06-12 09:26:18
+ * @Editors: Mr wpl
+ * @Description: 按文件内容去重：多个路径下内容相同的文件 (如多个 WordPress 站点中的同一插件) 只扫描一次
+ */
+package engine
+
+import (
+	"bt-shieldml/pkg/types"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// dedupHeaderSize 快速头部哈希读取的字节数
+const dedupHeaderSize = 512
+
+// dedupEntry 一个参与去重的文件。内容首次出现的文件扫描后通过 Complete 发布结果，done 关闭后 result 可读
+type dedupEntry struct {
+	d       *ContentHashDeduplicator
+	path    string
+	earlier []*dedupEntry // 头部哈希相同、先于本文件分发的文件，按分发顺序
+	sumOnce sync.Once
+	sum     string // 完整内容的 SHA-256，首次需要比较时才计算，读取失败时为空
+	done    chan struct{}
+	result  *types.ScanResult
+}
+
+/**
+ * @Description: 内容去重器。分发时只比较前 512 字节的 MD5，头部相同的文件在各自的扫描协程中
+ * 比较完整内容的 SHA-256，完全相同的文件复用最先分发的文件的扫描结果
+ * @author: Mr wpl
+ */
+type ContentHashDeduplicator struct {
+	reader     FileReader               // 读取文件内容，与引擎使用同一个 FileReader
+	headers    map[string][]*dedupEntry // 头部 MD5 → 已分发的文件，仅由分发文件的协程访问
+	duplicates atomic.Int64
+}
+
+/**
+ * @Description: 创建内容去重器
+ * @author: Mr wpl
+ * @param reader FileReader: 文件读取器
+ * @return *ContentHashDeduplicator: 去重器
+ */
+func NewContentHashDeduplicator(reader FileReader) *ContentHashDeduplicator {
+	return &ContentHashDeduplicator{reader: reader, headers: make(map[string][]*dedupEntry)}
+}
+
+/**
+ * @Description: 在启动扫描协程前调用 (同一协程内按分发顺序调用)，只读取文件头部。返回的 entry 为 nil 时正常扫描，
+ * 否则由扫描协程调用 Resolve 判断是否与先分发的文件内容相同
+ * @author: Mr wpl
+ * @param path string: 文件路径
+ * @return *dedupEntry: 该文件的去重条目
+ */
+func (d *ContentHashDeduplicator) Check(path string) *dedupEntry {
+	header, err := d.hashFile(path, md5.New(), dedupHeaderSize)
+	if err != nil {
+		return nil // 无法读取时交给 scanFile 报告错误
+	}
+	group := d.headers[header]
+	entry := &dedupEntry{d: d, path: path, earlier: group[:len(group):len(group)], done: make(chan struct{})}
+	d.headers[header] = append(group, entry)
+	return entry
+}
+
+/**
+ * @Description: 在扫描协程中调用：头部与先分发的文件相同时计算完整哈希并依次比较。返回 nil 时由调用方扫描后
+ * 通过 Complete 发布结果，否则通过返回条目的 Wait 获取复用的结果。先分发的文件已占用工作槽位，等待其哈希或结果不会死锁
+ * @author: Mr wpl
+ * @return *dedupEntry: 内容相同且最先分发的文件，没有时为 nil
+ */
+func (e *dedupEntry) Resolve() *dedupEntry {
+	if len(e.earlier) == 0 {
+		return nil
+	}
+	sum := e.digest()
+	if sum == "" {
+		return nil
+	}
+	for _, prev := range e.earlier {
+		if prev.digest() == sum {
+			e.d.duplicates.Add(1)
+			return prev
+		}
+	}
+	return nil
+}
+
+// digest 返回完整内容的 SHA-256，只计算一次
+func (e *dedupEntry) digest() string {
+	e.sumOnce.Do(func() {
+		e.sum, _ = e.d.hashFile(e.path, sha256.New(), -1)
+	})
+	return e.sum
+}
+
+/**
+ * @Description: 发布首个文件的扫描结果，唤醒等待该内容的重复文件
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 扫描结果
+ */
+func (e *dedupEntry) Complete(result *types.ScanResult) {
+	e.result = result
+	close(e.done)
+}
+
+/**
+ * @Description: 等待首个文件扫描完成，返回以 path 为路径的结果副本，DuplicateOf 指向首个文件
+ * @author: Mr wpl
+ * @param path string: 重复文件路径
+ * @param info os.FileInfo: 重复文件的信息 (可为 nil)
+ * @return *types.ScanResult: 扫描结果
+ */
+func (e *dedupEntry) Wait(path string, info os.FileInfo) *types.ScanResult {
+	<-e.done
+	dup := *e.result
+	dup.File.Path = path
+	dup.File.RelativePath = ""
+	if info != nil {
+		dup.File.ModTime = info.ModTime()
+	}
+	dup.Findings = append([]*types.Finding(nil), e.result.Findings...)
+	dup.SuppressedFindings = append([]*types.Finding(nil), e.result.SuppressedFindings...)
+	dup.SkippedAnalyzers = append([]string(nil), e.result.SkippedAnalyzers...)
+	dup.Duration = 0
+	dup.Signature = ""
+	dup.DuplicateOf = e.result.File.Path
+	return &dup
+}
+
+/**
+ * @Description: 返回复用了已有结果的文件数
+ * @author: Mr wpl
+ * @return int: 重复文件数
+ */
+func (d *ContentHashDeduplicator) Duplicates() int {
+	return int(d.duplicates.Load())
+}
+
+/**
+ * @Description: 计算文件前 limit 字节 (limit < 0 时为全部内容) 的哈希
+ * @author: Mr wpl
+ * @param path string: 文件路径
+ * @param h hash.Hash: 哈希算法
+ * @param limit int64: 读取字节数
+ * @return string: 十六进制哈希
+ * @return error: 错误
+ */
+func (d *ContentHashDeduplicator) hashFile(path string, h hash.Hash, limit int64) (string, error) {
+	f, err := d.reader.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,148 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: -dry-run：只查找文件并输出将要扫描的文件、启用的分析器、排除数量、扩展名分布与预计耗时，不运行分析器
+ */
+package engine
+
+import (
+	"bt-shieldml/pkg/logging"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 预计耗时的粗略估算：每个文件的固定开销 (特征提取、AST、模型) 加按大小计的读取与匹配时间，再按并发数均摊
+const (
+	dryRunFileOverhead   = 20 * time.Millisecond
+	dryRunBytesPerSecond = 1 << 20
+)
+
+// DryRunReport -dry-run 的输出
+type DryRunReport struct {
+	FileCount         int            `json:"file_count"`
+	TotalBytes        int64          `json:"total_bytes"`
+	AverageBytes      int64          `json:"average_bytes"`
+	EstimatedDuration string         `json:"estimated_duration"`
+	Concurrency       int            `json:"concurrency"`
+	Analyzers         []string       `json:"analyzers"`
+	Excluded          int            `json:"excluded"`
+	SkippedExtension  int            `json:"skipped_extension"`
+	SkippedByTime     int            `json:"skipped_by_time"`
+	SkippedBySize     int            `json:"skipped_by_size"`
+	Extensions        map[string]int `json:"extensions"`
+	Files             []string       `json:"files"`
+}
+
+/**
+ * @Description: 按任务查找文件并输出 DryRunReport (task.OutputFormat 为 json 时输出 JSON，否则为文本)。
+ * 不解包归档、不创建 ScanResult、不写报告文件；超出 performance.min/max_file_size_bytes 的文件计入 SkippedBySize
+ * @author: Mr wpl
+ * @param task *Task: 任务
+ * @param w io.Writer: 输出
+ * @return error: 错误
+ */
+func (e *Engine) dryRun(task *Task, w io.Writer) error {
+	scanArchives := task.ScanArchives || e.config.ScanArchives
+	files, stats, err := findFilesWithStats(task.Paths, e.exclusions(task), e.config.Extensions(), task.FollowSymlinks, scanArchives, task.TimeFilter)
+	if err != nil {
+		return fmt.Errorf("error finding files to scan: %w", err)
+	}
+
+	report := &DryRunReport{
+		Concurrency:      cap(e.workers),
+		Excluded:         stats.Excluded,
+		SkippedExtension: stats.SkippedExtension,
+		SkippedByTime:    stats.SkippedByTime,
+		Extensions:       make(map[string]int),
+		Files:            make([]string, 0, len(files)),
+	}
+	for name := range e.analyzers {
+		report.Analyzers = append(report.Analyzers, name)
+	}
+	sort.Strings(report.Analyzers)
+
+	minSize, maxSize := e.config.Performance.MinFileSizeBytes, e.config.Performance.MaxFileSizeBytes
+	for _, path := range files {
+		if info, statErr := os.Stat(path); statErr == nil {
+			if info.Size() < minSize || (maxSize > 0 && info.Size() > maxSize) {
+				report.SkippedBySize++
+				continue
+			}
+			report.TotalBytes += info.Size()
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext == "" {
+			ext = "(none)"
+		}
+		report.Extensions[ext]++
+		report.Files = append(report.Files, path)
+	}
+	report.FileCount = len(report.Files)
+
+	var estimate time.Duration
+	if report.FileCount > 0 {
+		report.AverageBytes = report.TotalBytes / int64(report.FileCount)
+		perFile := dryRunFileOverhead + time.Duration(float64(report.AverageBytes)/dryRunBytesPerSecond*float64(time.Second))
+		estimate = perFile * time.Duration(report.FileCount) / time.Duration(max(report.Concurrency, 1))
+	}
+	report.EstimatedDuration = estimate.Round(time.Millisecond).String()
+	logging.InfoLogger.Printf("Dry run: %d files would be scanned, no analyzers were run", report.FileCount)
+
+	if strings.EqualFold(task.OutputFormat, "json") {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return writeDryRunText(w, report)
+}
+
+// writeDryRunText 以文本输出 DryRunReport，扩展名按文件数降序
+func writeDryRunText(w io.Writer, report *DryRunReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run: %d files would be scanned (%s, average %s)\n", report.FileCount, formatBytes(report.TotalBytes), formatBytes(report.AverageBytes))
+	fmt.Fprintf(&b, "Estimated scan time: ~%s with %d workers\n", report.EstimatedDuration, report.Concurrency)
+	fmt.Fprintf(&b, "Analyzers: %s\n", strings.Join(report.Analyzers, ", "))
+	fmt.Fprintf(&b, "Excluded: %d paths; skipped %d files with other extensions, %d outside the time window, %d outside the size limits\n",
+		report.Excluded, report.SkippedExtension, report.SkippedByTime, report.SkippedBySize)
+
+	exts := make([]string, 0, len(report.Extensions))
+	for ext := range report.Extensions {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		if report.Extensions[exts[i]] != report.Extensions[exts[j]] {
+			return report.Extensions[exts[i]] > report.Extensions[exts[j]]
+		}
+		return exts[i] < exts[j]
+	})
+	b.WriteString("By extension:\n")
+	for _, ext := range exts {
+		fmt.Fprintf(&b, "  %-10s %d\n", ext, report.Extensions[ext])
+	}
+	b.WriteString("Files:\n")
+	for _, path := range report.Files {
+		fmt.Fprintf(&b, "  %s\n", path)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatBytes 格式化字节数，如 "12.3 MB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
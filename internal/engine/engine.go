@@ -5,24 +5,46 @@ import (
 	"bt-shieldml/internal/analyzers/static"
 	"bt-shieldml/internal/ast"
 	"bt-shieldml/internal/features"
+	"bt-shieldml/internal/integration"
+	"bt-shieldml/internal/metrics"
+	"bt-shieldml/internal/platform"
 	"bt-shieldml/internal/reporting"
+	"bt-shieldml/internal/scanstats"
 	"bt-shieldml/internal/scoring"
+	"bt-shieldml/internal/signing"
+	"bt-shieldml/internal/state"
+	"bt-shieldml/internal/unpacker"
+	"bt-shieldml/internal/whitelist"
 	"bt-shieldml/pkg/logging"
 	"bt-shieldml/pkg/types"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Engine 协调扫描过程
 type Engine struct {
-	config     *types.Config
-	analyzers  map[string]Analyzer
-	astManager ast.ASTManager // 持有 AST 管理器实例
+	config          *types.Config
+	analyzers       map[string]Analyzer
+	astManager      ast.ASTManager            // 持有 AST 管理器实例
+	consoleTemplate string                    // 已校验的控制台报告模板，为空时使用默认格式
+	sortKeys        []reporting.SortKey       // 报告结果排序键，为空时使用各报告的默认顺序
+	assetProvider   integration.AssetProvider // 部署资产清单，为 nil 时不查询
+	whitelist       *whitelist.Whitelist      // 白名单 (whitelist_path)，为 nil 时不检查
+	fileReader      FileReader                // 读取待扫描文件，默认直接读取文件系统
+	// workers 文件扫描的并发名额 (performance.concurrency)，同一引擎上并发执行的扫描 (如 REST API 的多个请求) 共用
+	workers chan struct{}
+	// abandoned 超时后被放弃、仍在后台运行的文件扫描与分析器 goroutine 数
+	abandoned atomic.Int64
 }
 
 /**
@@ -32,14 +54,56 @@ type Engine struct {
  * @return *Engine: 引擎
  */
 func NewEngine(cfg *types.Config) (*Engine, error) {
+	return newEngine(cfg, nil)
+}
+
+/**
+ * @Description: 初始化检测引擎，sharedAST 不为 nil 时复用已启动的 PHP 桥接而不是再启动一个
+ * @author: Mr wpl
+ * @param cfg *types.Config: 配置
+ * @param sharedAST ast.ASTManager: 共享的 AST 管理器，可为 nil
+ * @return *Engine: 引擎
+ */
+func newEngine(cfg *types.Config, sharedAST ast.ASTManager) (*Engine, error) {
 	var astMgr ast.ASTManager
 	var err error
 
+	// 启动前校验控制台模板，避免扫描结束后才发现配置错误
+	consoleTemplate := ""
+	if cfg.Output.ConsoleTemplate != "" {
+		consoleTemplate, err = reporting.LoadConsoleTemplate(cfg.Output.ConsoleTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sortKeys, err := reporting.ParseSortKeys(cfg.Output.SortKeys)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Output.MinConfidence < 0 || cfg.Output.MinConfidence > 1 {
+		return nil, fmt.Errorf("invalid min_confidence %.2f: must be between 0 and 1", cfg.Output.MinConfidence)
+	}
+	assetProvider, err := integration.NewAssetProvider(cfg.AssetProvider, cfg.DataPaths.Config)
+	if err != nil {
+		return nil, err
+	}
+	var wl *whitelist.Whitelist
+	if cfg.WhitelistPath != "" {
+		if wl, err = whitelist.Load(cfg.WhitelistPath); err != nil {
+			return nil, err
+		}
+	}
+
+	concurrency := cfg.Performance.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4 // Default if invalid
+	}
+
 	// 默认初始化 AST通道
 	needsAST := false
 
 	// 需要AST的分析器
-	astRequiredBy := []string{"regex", "yara", "bayes_words", "statistical", "svm_prosses"} // Add more if needed
+	astRequiredBy := []string{"regex", "yara", "bayes_words", "statistical", "svm_prosses", "random_forest", "callgraph", "obfuscation", "superglobal"} // Add more if needed
 	enabledSet := make(map[string]bool)
 	for _, name := range cfg.EnabledAnalyzers {
 		enabledSet[strings.ToLower(name)] = true
@@ -51,12 +115,17 @@ func NewEngine(cfg *types.Config) (*Engine, error) {
 		}
 	}
 
-	if needsAST {
-		astMgr, err = ast.NewPhpAstManager()
-		if err != nil {
-			logging.ErrorLogger.Printf("Failed to initialize AST Manager (PHP bridge start failed): %v. AST-dependent analyzers will be inactive.", err)
+	if needsAST && sharedAST != nil {
+		astMgr = sharedAST
+	} else if needsAST {
+		phpAstMgr, mgrErr := ast.NewPhpAstManagerPool(cfg.AST.PoolSizeFor(concurrency), cfg.BridgeTransport)
+		if mgrErr != nil {
+			logging.ErrorLogger.Printf("Failed to initialize AST Manager (PHP bridge start failed): %v. AST-dependent analyzers will be inactive.", mgrErr)
 			// Don't return error here, allow engine to continue without AST features
 			astMgr = nil
+		} else {
+			phpAstMgr.SetCompression(cfg.ASTCompression)
+			astMgr = phpAstMgr
 		}
 	} else {
 		logging.InfoLogger.Println("No AST-dependent analyzers enabled, skipping AST Manager initialization.")
@@ -66,39 +135,54 @@ func NewEngine(cfg *types.Config) (*Engine, error) {
 	enabledAnalyzers := make(map[string]Analyzer)
 	analyzerErrors := []string{}
 
+	// 未知名称在初始化前统一警告，switch 的 default 分支直接跳过
+	warnUnknownAnalyzers(cfg.EnabledAnalyzers)
+
 	// Use the enabledSet for quick lookup
 	for nameLower := range enabledSet {
 		var analyzer Analyzer
 		var initErr error
 
-		// Check if analyzer requires AST and if manager is available
-		requiresAST := false
-		for _, req := range astRequiredBy {
-			if nameLower == req {
-				requiresAST = true
-				break
-			}
-		}
-		if requiresAST && astMgr == nil {
-			// logging.WarnLogger.Printf("Skipping initialization of analyzer '%s': requires AST but AST Manager failed to initialize.", nameLower)
-			continue
-		}
-
 		switch nameLower {
+		case "hash":
+			analyzer, initErr = static.NewHashAnalyzer(cfg.DataPaths.Signatures, cfg.HashAnalyzer.FPRate)
 		case "regex":
-			analyzer, initErr = static.NewRegexAnalyzer()
+			analyzer, initErr = static.NewRegexAnalyzer(cfg.DataPaths.Signatures)
 		case "yara":
 			analyzer, initErr = static.NewYaraAnalyzer(cfg.DataPaths.Signatures)
 		case "statistical":
 			analyzer, initErr = static.NewStatisticalAnalyzer() // Already checks for AST manager internally if needed
+		case "jsp_statistical":
+			analyzer, initErr = static.NewJSPStatisticalAnalyzer()
+		case "python_regex":
+			analyzer, initErr = static.NewPythonRegexAnalyzer()
+		case "entropy_string":
+			analyzer, initErr = static.NewHighEntropyStringDetector()
+		case "shebang":
+			analyzer, initErr = static.NewShebangAnalyzer()
+		case "htaccess":
+			analyzer, initErr = static.NewHtaccessAnalyzer()
+		case "superglobal":
+			analyzer, initErr = static.NewSuperGlobalAnalyzer()
+		case "fingerprint":
+			analyzer, initErr = static.NewFingerprintAnalyzer(cfg.DataPaths.Config)
+		case "ssdeep":
+			analyzer, initErr = static.NewSsdeepAnalyzer(cfg.DataPaths.Signatures, cfg.Ssdeep.Threshold)
+		case "callgraph":
+			analyzer, initErr = static.NewCallGraphAnalyzer(cfg.CallGraph.SuspiciousFunctions)
+		case "obfuscation":
+			analyzer, initErr = static.NewObfuscationAnalyzer()
 		// case "svm_ops":
 		// 	analyzer, initErr = ml.NewSvmOpsAnalyzer(cfg.DataPaths.Models, cfg.DataPaths.Config)
 		case "bayes_words":
 			analyzer, initErr = ml.NewBayesWordsAnalyzer(cfg.DataPaths.Models)
 		case "svm_prosses":
 			analyzer, initErr = ml.NewSvmProssesAnalyzer(cfg.DataPaths.Models)
+		case "random_forest":
+			analyzer, initErr = ml.NewRandomForestAnalyzer(cfg.DataPaths.Models)
+		case "ngram":
+			analyzer, initErr = ml.NewNgramAnalyzer(cfg.DataPaths.Models)
 		default:
-			logging.WarnLogger.Printf("Unknown analyzer specified in config: %s", nameLower)
 			continue
 		}
 
@@ -112,6 +196,11 @@ func NewEngine(cfg *types.Config) (*Engine, error) {
 		}
 	}
 
+	// AST 不可用时移除依赖 AST 特征的分析器，而不是让它们对每个文件静默跳过
+	if err := validateAnalyzerContracts(enabledAnalyzers, astMgr != nil); err != nil {
+		return nil, err
+	}
+
 	if len(enabledAnalyzers) == 0 {
 		errMsg := "No analyzers were enabled or successfully initialized."
 		if len(analyzerErrors) > 0 {
@@ -123,9 +212,15 @@ func NewEngine(cfg *types.Config) (*Engine, error) {
 	}
 
 	return &Engine{
-		config:     cfg,
-		analyzers:  enabledAnalyzers,
-		astManager: astMgr, // Store potentially nil AST manager
+		config:          cfg,
+		analyzers:       enabledAnalyzers,
+		astManager:      astMgr, // Store potentially nil AST manager
+		consoleTemplate: consoleTemplate,
+		sortKeys:        sortKeys,
+		assetProvider:   assetProvider,
+		whitelist:       wl,
+		fileReader:      osFileReader{},
+		workers:         make(chan struct{}, concurrency),
 	}, nil
 }
 
@@ -136,8 +231,12 @@ func NewEngine(cfg *types.Config) (*Engine, error) {
  * @return error: 错误
  */
 func (e *Engine) Scan(task *Task) error {
-	// Cleanup AST Manager if it was initialized
-	if e.astManager != nil {
+	// 每次扫描生成关联 ID，并发扫描时可按 scanID/file 区分交错的日志
+	scanCtx := logging.WithContext(context.Background(), logging.NewScanID(), "")
+	logging.InfoCtx(scanCtx, "Scan started for %v", task.Paths)
+
+	// Cleanup AST Manager if it was initialized (PHP 桥接每个进程只能启动一次，KeepAlive 时留给后续扫描)
+	if e.astManager != nil && !task.KeepAlive {
 		defer func() {
 			if err := e.astManager.Cleanup(); err != nil {
 				logging.ErrorLogger.Printf("Error during AST Manager cleanup: %v", err)
@@ -145,10 +244,16 @@ func (e *Engine) Scan(task *Task) error {
 		}()
 	}
 
-	filesToScan, err := findFiles(task.Paths, task.Exclusions)
+	if task.DryRun {
+		return e.dryRun(task, os.Stdout)
+	}
+	defer metrics.ScanStarted()()
+
+	filesToScan, virtualPaths, cleanup, err := e.collectFiles(task)
 	if err != nil {
-		return fmt.Errorf("error finding files to scan: %w", err)
+		return err
 	}
+	defer cleanup()
 	if len(filesToScan) == 0 {
 		logging.InfoLogger.Println("No files found to scan.")
 		if task.ReportPath != "" {
@@ -156,131 +261,574 @@ func (e *Engine) Scan(task *Task) error {
 		}
 		return nil
 	}
+	root := scanRoot(task.rootPaths())
 
 	results := make([]*types.ScanResult, 0, len(filesToScan))
-	var wg sync.WaitGroup
 	resultChan := make(chan *types.ScanResult, len(filesToScan))
 
-	concurrency := e.config.Performance.Concurrency
-	if concurrency <= 0 {
-		concurrency = 4 // Default if invalid
+	// NDJSON/LSP 模式下每个文件扫描完成即输出，不等待全部结果
+	var streamDone chan error
+	if streamer := e.streamingReporter(task); streamer != nil {
+		out := os.Stdout
+		if task.ReportPath != "" {
+			f, createErr := os.Create(task.ReportPath)
+			if createErr != nil {
+				return fmt.Errorf("failed to create streaming output %s: %w", task.ReportPath, createErr)
+			}
+			defer f.Close()
+			out = f
+		} else {
+			logging.RedirectToStderr() // stdout 只保留机器可读输出
+		}
+		if e.config.VirusTotal.APIKey != "" || len(task.SignKey) > 0 {
+			logging.WarnLogger.Println("VirusTotal enrichment and result signing are not applied in streaming output modes (ndjson, lsp)")
+		}
+		streamDone = make(chan error, 1)
+		go func() {
+			streamDone <- streamer.Stream(resultChan, out)
+		}()
 	}
-	sem := make(chan struct{}, concurrency)
 
 	startTime := time.Now()
+	shuttingDown, err := e.dispatch(scanCtx, task, filesToScan, virtualPaths, root, resultChan)
+	if err != nil {
+		return err
+	}
 
-	for _, filePath := range filesToScan {
+	if streamDone != nil {
+		if streamErr := <-streamDone; streamErr != nil {
+			return fmt.Errorf("failed to stream results: %w", streamErr)
+		}
+		logging.InfoLogger.Printf("Scanning finished in %s", time.Since(startTime))
+		return nil
+	}
+
+	for res := range resultChan {
+		results = append(results, res)
+	}
+
+	totalDuration := time.Since(startTime)
+	logging.InfoLogger.Printf("Scanning finished in %s", totalDuration)
+
+	// Enrich risky files with VirusTotal detections (bounded by task.VTTimeout)
+	if e.config.VirusTotal.APIKey != "" && !shuttingDown {
+		e.enrichWithVirusTotal(results, task.VTTimeout)
+	}
+
+	// Sign results for tamper-evident reports
+	if len(task.SignKey) > 0 {
+		if err := signResults(results, task.SignKey, task.VerifyKey); err != nil {
+			return fmt.Errorf("failed to sign scan results: %w", err)
+		}
+	}
+
+	if task.JournalPath != "" {
+		entry := scanstats.EntryFromResults(startTime, root, results)
+		if err := scanstats.AppendJournal(task.JournalPath, entry); err != nil {
+			logging.WarnLogger.Printf("Failed to record scan in journal: %v", err)
+		}
+	}
+
+	// Record per-file risk levels for -history-report (interrupted scans would look like removed files)
+	if e.config.History.Enabled && !shuttingDown {
+		e.recordHistory(startTime, root, results)
+	}
+
+	if task.OnResults != nil {
+		task.OnResults(results)
+	}
+
+	// High/Critical 文件邮件与 Slack 通知，发送失败不影响报告
+	if e.config.Email.Enabled() && !shuttingDown {
+		mailer := reporting.NewEmailReporter(e.config.Email)
+		mailer.ScanRoot = root
+		if err := mailer.Generate(results, ""); err != nil {
+			logging.WarnLogger.Printf("Email notification failed: %v", err)
+		}
+	}
+	if e.config.Slack.Enabled() && !shuttingDown {
+		if notifier, err := reporting.NewSlackReporter(e.config.Slack); err != nil {
+			logging.WarnLogger.Printf("Slack notification disabled: %v", err)
+		} else {
+			notifier.ScanRoot = root
+			if err := notifier.Generate(results, ""); err != nil {
+				logging.WarnLogger.Printf("Slack notification failed: %v", err)
+			}
+		}
+	}
+
+	// Generate reports
+	return e.generateReport(results, task)
+}
+
+// recordHistory 将本次扫描写入扫描历史库，失败只记录警告
+func (e *Engine) recordHistory(startTime time.Time, root string, results []*types.ScanResult) {
+	history, err := state.OpenHistory(e.config.History.Path())
+	if err != nil {
+		logging.WarnLogger.Printf("Failed to record scan history: %v", err)
+		return
+	}
+	defer history.Close()
+	if _, err := history.RecordScan(startTime, root, results); err != nil {
+		logging.WarnLogger.Printf("Failed to record scan history: %v", err)
+	}
+}
+
+/**
+ * @Description: 扫描任务中的文件并返回结果，不生成报告、不关闭 PHP 桥接，供常驻进程 (如 REST API 服务) 并发调用。
+ * 与 Scan 共用引擎的并发名额；任务中报告、签名与扫描日志相关的字段被忽略，也不查询 VirusTotal。使用完毕后需调用 Close
+ * @author: Mr wpl
+ * @param task *Task: 任务
+ * @return []*types.ScanResult: 扫描结果
+ * @return error: 错误
+ */
+func (e *Engine) ScanPaths(task *Task) ([]*types.ScanResult, error) {
+	var results []*types.ScanResult
+	err := e.ScanEach(task, func(res *types.ScanResult) {
+		results = append(results, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+/**
+ * @Description: 与 ScanPaths 相同，但每个文件扫描完成后立即以结果调用 onResult (按完成顺序，在同一个 goroutine 中依次调用)，
+ * 供流式接口 (如 gRPC) 逐个返回结果。onResult 阻塞时已分发的文件继续扫描，结果在内部缓冲
+ * @author: Mr wpl
+ * @param task *Task: 任务
+ * @param onResult func(*types.ScanResult): 结果回调
+ * @return error: 查找文件或打开扫描状态库失败时返回错误
+ */
+func (e *Engine) ScanEach(task *Task, onResult func(*types.ScanResult)) error {
+	scanCtx := logging.WithContext(context.Background(), logging.NewScanID(), "")
+	logging.InfoCtx(scanCtx, "Scan started for %v", task.Paths)
+	defer metrics.ScanStarted()()
+
+	filesToScan, virtualPaths, cleanup, err := e.collectFiles(task)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resultChan := make(chan *types.ScanResult, len(filesToScan))
+	errChan := make(chan error, 1)
+	go func() {
+		_, dispatchErr := e.dispatch(scanCtx, task, filesToScan, virtualPaths, scanRoot(task.rootPaths()), resultChan)
+		errChan <- dispatchErr
+	}()
+	for res := range resultChan {
+		onResult(res)
+	}
+	return <-errChan
+}
+
+/**
+ * @Description: 查找任务中的待扫描文件，解包归档并登记临时文件的显示路径
+ * @author: Mr wpl
+ * @param task *Task: 任务
+ * @return []string: 待扫描的文件
+ * @return map[string]string: 临时文件 (归档成员、标准输入) 的绝对路径 → 报告中显示的路径
+ * @return func(): 删除解包目录，扫描结束后调用
+ * @return error: 错误
+ */
+func (e *Engine) collectFiles(task *Task) ([]string, map[string]string, func(), error) {
+	scanArchives := task.ScanArchives || e.config.ScanArchives
+	filesToScan, err := findFiles(task.Paths, e.exclusions(task), e.config.Extensions(), task.FollowSymlinks, scanArchives, task.TimeFilter)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error finding files to scan: %w", err)
+	}
+
+	// 解包归档，成员文件以虚拟路径 <归档>!/<成员> 显示
+	cleanup := func() {}
+	var virtualPaths map[string]string
+	if scanArchives {
+		archiveDir, tmpErr := os.MkdirTemp("", "shieldml_archives_")
+		if tmpErr != nil {
+			return nil, nil, nil, fmt.Errorf("failed to create archive unpack directory: %w", tmpErr)
+		}
+		cleanup = func() { os.RemoveAll(archiveDir) }
+		filesToScan, virtualPaths = expandArchives(filesToScan, archiveDir, e.config.Extensions(), e.config.ArchiveSizeLimit())
+	}
+	if len(task.DisplayPaths) > 0 {
+		if virtualPaths == nil {
+			virtualPaths = make(map[string]string, len(task.DisplayPaths))
+		}
+		for tmpPath, displayPath := range task.DisplayPaths {
+			if absPath, absErr := filepath.Abs(tmpPath); absErr == nil {
+				virtualPaths[absPath] = displayPath
+			}
+		}
+	}
+	return filesToScan, virtualPaths, cleanup, nil
+}
+
+/**
+ * @Description: 并发扫描文件，每个文件的结果 (包括出错、复用与中止的文件) 写入 resultChan，返回前关闭 resultChan。
+ * 并发名额来自引擎的 workers，同一引擎上同时进行的扫描共用
+ * @author: Mr wpl
+ * @param scanCtx context.Context: 日志上下文
+ * @param task *Task: 任务
+ * @param filesToScan []string: 待扫描的文件
+ * @param virtualPaths map[string]string: 临时文件路径 → 报告中显示的路径
+ * @param root string: 扫描根目录
+ * @param resultChan chan<- *types.ScanResult: 结果通道，容量不能小于 len(filesToScan)
+ * @return bool: 是否因收到 SIGTERM 提前结束
+ * @return error: 错误
+ */
+func (e *Engine) dispatch(scanCtx context.Context, task *Task, filesToScan []string, virtualPaths map[string]string, root string, resultChan chan<- *types.ScanResult) (bool, error) {
+	defer close(resultChan)
+
+	// -incremental：修改时间与大小 (或内容) 未变的文件复用扫描状态库中的上次结果
+	var store *state.Store
+	if task.Incremental {
+		names := make([]string, 0, len(e.analyzers))
+		for name := range e.analyzers {
+			names = append(names, name)
+		}
+		var err error
+		store, err = state.Open(e.config.StateFile(), names)
+		if err != nil {
+			return false, err
+		}
+		defer func() {
+			if closeErr := store.Close(); closeErr != nil {
+				logging.WarnLogger.Printf("Failed to save scan state: %v", closeErr)
+			}
+		}()
+	}
+	cachedFiles := 0
+
+	// 内容相同的文件只扫描一次，其余路径复用结果 (-no-dedup 关闭)
+	var dedup *ContentHashDeduplicator
+	if !task.NoDedup {
+		dedup = NewContentHashDeduplicator(e.fileReader)
+	}
+
+	// 收到 SIGTERM 后停止分发新文件，最多等待 performance.shutdown_timeout 让进行中的文件完成
+	shutdown := NewGracefulShutdown(e.config.Performance.ShutdownWait())
+	defer shutdown.Close()
+	notStarted := 0
+
+	for i, filePath := range filesToScan {
+		if shutdown.ShuttingDown() {
+			notStarted = len(filesToScan) - i
+			break
+		}
 		// Basic check before goroutine
-		if _, statErr := os.Stat(filePath); statErr != nil {
+		info, statErr := e.fileReader.Stat(filePath)
+		if statErr != nil {
 			logging.WarnLogger.Printf("Skipping file %s: %v", filePath, statErr)
 			// Add a result indicating the error for this file
-			results = append(results, &types.ScanResult{
+			resultChan <- &types.ScanResult{
 				File:  types.FileInfo{Path: filePath},
 				Error: fmt.Errorf("stat error: %w", statErr),
-			})
+			}
 			continue
 		}
 
-		wg.Add(1)
-		sem <- struct{}{}
+		// phar 成员与标准输入的临时文件每次路径都不同，不记录状态
+		_, virtual := virtualPaths[filePath]
+		if store != nil && !virtual {
+			if cached, ok := store.Lookup(filePath, info); ok {
+				cached.File.RelativePath = relativeToRoot(root, cached.File.Path)
+				cachedFiles++
+				resultChan <- cached
+				continue
+			}
+		}
 
-		go func(fp string) {
-			defer wg.Done()
-			defer func() { <-sem }()
-			// Pass the engine's astManager to scanFile
-			result := e.scanFile(fp, e.astManager)
-			resultChan <- result
-		}(filePath)
+		// 路径白名单只说明该路径可信，不说明内容安全：这类文件不参与去重，其 Safe 结果不会复用到其他路径的相同内容
+		var entry *dedupEntry
+		if dedup != nil && (e.whitelist == nil || !e.whitelist.MatchesPath(filePath)) {
+			entry = dedup.Check(filePath)
+		}
+
+		select {
+		case e.workers <- struct{}{}:
+		case <-shutdown.Stopping():
+			notStarted = len(filesToScan) - i
+		}
+		if notStarted > 0 {
+			break
+		}
+
+		shutdown.Start(filePath)
+		go func(fp string, info os.FileInfo, record bool, entry *dedupEntry) {
+			// 超时后被放弃的扫描结束前不释放槽位
+			work := &fileWork{}
+			defer work.release(func() { <-e.workers })
+			var first *dedupEntry
+			if entry != nil {
+				first = entry.Resolve()
+			}
+			var result *types.ScanResult
+			if first != nil {
+				// 首个相同内容的文件已先于本文件分发，等待其结果即可
+				result = first.Wait(fp, info)
+			} else {
+				// Pass the engine's astManager to scanFile
+				result = e.scanFile(withFileWork(scanCtx, work), fp, e.astManager)
+			}
+			if record {
+				store.Save(fp, info, result)
+			}
+			if virtualPath, ok := virtualPaths[fp]; ok {
+				result.File.Path = virtualPath
+			}
+			result.File.RelativePath = relativeToRoot(root, result.File.Path)
+			if entry != nil && first == nil {
+				entry.Complete(result)
+			}
+			shutdown.Done(fp, func() { resultChan <- result })
+		}(filePath, info, store != nil && !virtual, entry)
 	}
 
-	wg.Wait()
-	close(resultChan)
+	aborted := shutdown.Wait()
+	if shutdown.ShuttingDown() {
+		logging.WarnCtx(scanCtx, "Shutdown requested: %d in-flight files aborted, %d files not started; writing a partial report",
+			len(aborted), notStarted)
+	}
+	for _, fp := range aborted {
+		displayPath := fp
+		if virtualPath, ok := virtualPaths[fp]; ok {
+			displayPath = virtualPath
+		}
+		resultChan <- &types.ScanResult{
+			File:        types.FileInfo{Path: displayPath, RelativePath: relativeToRoot(root, displayPath)},
+			OverallRisk: types.RiskUnknown,
+			Incomplete:  true,
+		}
+	}
 
-	for res := range resultChan {
-		results = append(results, res)
+	if running := e.abandoned.Load(); running > 0 {
+		logging.WarnCtx(scanCtx, "%d timed-out file scans or analyzers are still running in the background", running)
 	}
+	if dedup != nil && dedup.Duplicates() > 0 {
+		logging.InfoCtx(scanCtx, "Reused results for %d files with duplicate content", dedup.Duplicates())
+	}
+	if store != nil {
+		logging.InfoCtx(scanCtx, "Incremental scan: reused results for %d unchanged files from %s", cachedFiles, e.config.StateFile())
+	}
+	return shutdown.ShuttingDown(), nil
+}
 
-	totalDuration := time.Since(startTime)
-	logging.InfoLogger.Printf("Scanning finished in %s", totalDuration)
+/**
+ * @Description: 扫描单个文件并返回结果，不生成报告，供常驻进程 (如 LSP 服务) 反复调用。
+ * 与 Scan 不同，不会清理 AST 管理器，使用完毕后需调用 Close
+ * @author: Mr wpl
+ * @param filePath string: 文件路径
+ * @return *types.ScanResult: 扫描结果
+ */
+func (e *Engine) ScanFile(filePath string) *types.ScanResult {
+	ctx := logging.WithContext(context.Background(), logging.NewScanID(), "")
+	return e.scanFile(ctx, filePath, e.astManager)
+}
 
-	// Generate reports
-	return e.generateReport(results, task)
+/**
+ * @Description: 释放引擎持有的资源 (PHP 桥接进程)
+ * @author: Mr wpl
+ * @return error: 错误
+ */
+func (e *Engine) Close() error {
+	if e.astManager == nil {
+		return nil
+	}
+	return e.astManager.Cleanup()
+}
+
+// Status 引擎状态，供常驻服务的健康检查使用
+type Status struct {
+	Analyzers   []string `json:"analyzers"`    // 已启用的分析器，按执行顺序
+	ASTBridge   string   `json:"ast_bridge"`   // PHP 桥接状态："ok"、"disabled" (无 AST 分析器或启动失败) 或错误信息
+	Workers     int      `json:"workers"`      // 文件扫描并发名额
+	BusyWorkers int      `json:"busy_workers"` // 正在使用的名额
 }
 
 /**
- * @Description: 处理文件，接收 astManager 实例，用于 AST 解析
+ * @Description: 返回引擎状态，PHP 桥接启用时发送一次最小请求确认其可用
  * @author: Mr wpl
+ * @return Status: 引擎状态
+ */
+func (e *Engine) Status() Status {
+	names := make([]string, 0, len(e.analyzers))
+	for name := range e.analyzers {
+		names = append(names, name)
+	}
+	sortAnalyzerNames(names)
+
+	bridge := "disabled"
+	if e.astManager != nil {
+		bridge = "ok"
+		if pinger, ok := e.astManager.(interface{ Ping() error }); ok {
+			if err := pinger.Ping(); err != nil {
+				bridge = err.Error()
+			}
+		}
+	}
+	busy, total := e.Workers()
+	return Status{
+		Analyzers:   names,
+		ASTBridge:   bridge,
+		Workers:     total,
+		BusyWorkers: busy,
+	}
+}
+
+// Workers 返回正在使用的与全部的文件扫描并发名额，不访问 PHP 桥接，可频繁调用 (如指标抓取)
+func (e *Engine) Workers() (busy int, total int) {
+	return len(e.workers), cap(e.workers)
+}
+
+// warmupSource 预热时交给各分析器的最小 PHP 代码
+const warmupSource = "<?php\n$greeting = 'warmup';\necho $greeting;\n"
+
+/**
+ * @Description: 在首次扫描前完成各组件的延迟初始化 (正则编译、模型加载、PHP 桥接首次请求等)，
+ * 避免首个文件承担这些耗时。预热失败不影响后续扫描，调用方可只记录警告
+ * @author: Mr wpl
+ * @return error: PHP 桥接不可用或分析器初始化失败时返回第一个错误
+ */
+func (e *Engine) Warmup() error {
+	var firstErr error
+	if pinger, ok := e.astManager.(interface{ Ping() error }); ok {
+		if err := pinger.Ping(); err != nil {
+			firstErr = err
+		}
+	}
+
+	content := []byte(warmupSource)
+	fileInfo := types.FileInfo{Path: "warmup.php", Size: int64(len(content))}
+	featureSet, _, err := features.ExtractAllFeaturesParallel(fileInfo, content, e.astManager)
+	if err != nil {
+		logging.WarnLogger.Printf("Warmup feature extraction failed: %v", err)
+	}
+	if featureSet == nil {
+		featureSet = &features.FeatureSet{}
+	}
+	featureSet.Context = logging.WithContext(context.Background(), "warmup", fileInfo.Path)
+
+	for name, analyzer := range e.analyzers {
+		if !e.canRunAnalyzer(analyzer, featureSet) {
+			continue
+		}
+		if _, err := analyzer.Analyze(fileInfo, content, featureSet); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("analyzer '%s' warmup failed: %w", name, err)
+		}
+	}
+
+	runtime.GC() // 以预热后的内存占用作为基线
+	return firstErr
+}
+
+/**
+ * @Description: 处理文件 (不限时，由 scanFile 施加 file_scan_timeout)，接收 astManager 实例，用于 AST 解析
+ * @author: Mr wpl
+ * @param ctx context.Context: 携带扫描 ID 的日志上下文
  * @param filePath string: 文件路径
  * @param astMgr ast.ASTManager: AST 管理器实例
  * @return *types.ScanResult: 扫描结果
  */
-func (e *Engine) scanFile(filePath string, astMgr ast.ASTManager) *types.ScanResult {
+func (e *Engine) scanFileContent(ctx context.Context, filePath string, astMgr ast.ASTManager) *types.ScanResult {
 	start := time.Now()
+	ctx = logging.WithContext(ctx, "", filePath)
 	result := &types.ScanResult{File: types.FileInfo{Path: filePath}}
 
 	// 1. 获取文件信息和内容
-	info, err := os.Stat(filePath)
+	info, err := e.fileReader.Stat(filePath)
 	if err != nil {
 		result.Error = fmt.Errorf("stat error: %w", err)
-		logging.ErrorLogger.Printf("Error stating file %s: %v", filePath, err)
+		logging.ErrorCtx(ctx, "Error stating file %s: %v", filePath, err)
 		result.Duration = time.Since(start)
 		return result
 	}
 	result.File.Size = info.Size()
 	result.File.ModTime = info.ModTime()
 
-	// 基本大小检查
-	const maxSize = 10 * 1024 * 1024 // 10MB 限制
-	if info.Size() > maxSize {
-		result.Error = fmt.Errorf("file exceeds size limit (%d > %d bytes)", info.Size(), maxSize)
-		logging.WarnLogger.Printf("Skipping file %s: %v", filePath, result.Error)
+	if max := e.config.Performance.MaxFileSizeBytes; max > 0 && info.Size() > max {
+		logging.InfoCtx(ctx, "Skipping file larger than max_file_size_bytes (%d > %d bytes): %s", info.Size(), max, filePath)
+		result.OverallRisk = types.RiskNone
 		result.Duration = time.Since(start)
 		return result
 	}
+	// 超过阈值的大文件分段扫描，不整体读入内存 (白名单只按路径匹配)
+	if info.Size() > e.config.Performance.SegmentThreshold() {
+		if e.whitelist != nil && e.whitelist.MatchesPath(filePath) {
+			return e.whitelisted(ctx, result, start)
+		}
+		return e.scanSegmented(ctx, result, start)
+	}
 	if info.Size() == 0 {
-		logging.InfoLogger.Printf("Skipping empty file: %s", filePath)
+		logging.InfoCtx(ctx, "Skipping empty file: %s", filePath)
 		result.OverallRisk = types.RiskNone // Empty files are not risky
 		result.Duration = time.Since(start)
 		return result
 	}
+	if min := e.config.Performance.MinFileSizeBytes; info.Size() < min {
+		logging.InfoCtx(ctx, "Skipping file smaller than min_file_size_bytes (%d < %d bytes): %s", info.Size(), min, filePath)
+		result.OverallRisk = types.RiskNone
+		result.Duration = time.Since(start)
+		return result
+	}
 
 	// 读取文件内容
-	content, err := ioutil.ReadFile(filePath)
+	content, err := e.fileReader.ReadAll(filePath)
 	if err != nil {
 		result.Error = fmt.Errorf("read error: %w", err)
-		logging.ErrorLogger.Printf("Error reading file %s: %v", filePath, err)
+		logging.ErrorCtx(ctx, "Error reading file %s: %v", filePath, err)
 		result.Duration = time.Since(start)
 		return result
 	}
 
-	// 2. 获取 AST
-	var goAST interface{}
-	var astErr error
-	if astMgr != nil {
-		astStartTime := time.Now()
-		goAST, astErr = astMgr.GetAST(content)
-		astDuration := time.Since(astStartTime)
-		if astErr != nil {
-			logging.WarnLogger.Printf("AST generation failed for %s (Duration: %s): %v", filePath, astDuration, astErr)
+	rawContent := content
+	md5Sum := md5.Sum(rawContent)
+	result.ContentMD5 = hex.EncodeToString(md5Sum[:])
+	sha256Sum := sha256.Sum256(rawContent)
+	result.ContentSHA256 = hex.EncodeToString(sha256Sum[:])
+	// 白名单按路径与原始内容的 SHA-256 匹配，匹配时不运行分析器
+	if e.whitelist != nil && e.whitelist.Matches(result.File, rawContent) {
+		return e.whitelisted(ctx, result, start)
+	}
 
-		}
+	// 非 UTF-8 文件 (GBK、Latin-1 等) 转码后再分析，失败时保留原始内容
+	transcoded, encoding, encErr := features.DetectAndTranscode(content)
+	result.File.Encoding = encoding
+	if encErr != nil {
+		logging.WarnCtx(ctx, "Encoding conversion failed for %s: %v. Analyzing original bytes.", filePath, encErr)
 	} else {
-		logging.InfoLogger.Printf("AST Manager not available, skipping AST generation for %s", filePath)
+		content = transcoded
 	}
 
-	// 3. 提取特征
-	featureSet, featErr := features.ExtractAllFeatures(result.File, content, goAST, astMgr)
+	// 2. 提取特征：统计特征与 AST 生成 (及 AST 特征) 并行进行
+	// PHP 桥接无法解析 JSP、Python 与 .htaccess，这类文件只提取统计特征，由 jsp_statistical、python_regex、htaccess 等分析器处理
+	if static.IsJSPFile(filePath) || static.IsPythonFile(filePath) || static.IsHtaccessFile(filePath) {
+		astMgr = nil
+	} else if astMgr == nil {
+		logging.InfoCtx(ctx, "AST Manager not available, skipping AST generation for %s", filePath)
+	}
+	featureSet, astInfo, featErr := features.ExtractAllFeaturesParallel(result.File, content, astMgr)
+	if astInfo.Err != nil {
+		logging.WarnCtx(ctx, "AST generation failed for %s (Duration: %s): %v", filePath, astInfo.Duration, astInfo.Err)
+	} else if astInfo.TruncatedLines > 0 {
+		// 语法错误时去掉末尾若干行后得到的部分 AST
+		result.PartialAST = true
+		result.ASTTruncatedLines = astInfo.TruncatedLines
+		logging.WarnCtx(ctx, "Using partial AST for %s: stripped the last %d lines to recover from a parse error", filePath, astInfo.TruncatedLines)
+	}
 	if featErr != nil {
 		// Log the feature extraction error, but continue analysis if possible
-		logging.WarnLogger.Printf("Feature extraction failed for %s: %v", filePath, featErr)
+		logging.WarnCtx(ctx, "Feature extraction failed for %s: %v", filePath, featErr)
 		// Allow analysis to continue with potentially incomplete features
 	}
 	// Ensure featureSet is not nil even if errors occurred, might be partially populated
 	if featureSet == nil {
 		featureSet = &features.FeatureSet{}
 	}
+	featureSet.Context = ctx // 分析器可通过 logging.FromContext(featureSet.Context) 输出带扫描 ID 的日志
+	featureSet.RawContent = rawContent
 
-	// 4. 运行所有启用的分析器
+	// 3. 运行所有启用的分析器
 	var findings []*types.Finding
 	analyzerStartTime := time.Now()
 
@@ -289,35 +837,208 @@ func (e *Engine) scanFile(filePath string, astMgr ast.ASTManager) *types.ScanRes
 	for name := range e.analyzers {
 		enabledNames = append(enabledNames, name)
 	}
+	sortAnalyzerNames(enabledNames)
 
+	// 第一阶段：只依赖文件内容与统计特征的分析器相互独立，并发执行；第二阶段按优先级顺序执行其余分析器 (AST 词、操作序列等)。
+	// 短路 (performance.short_circuit/early_exit) 只作用于第二阶段：第一阶段的分析器同时运行，
+	// 其中 regex 报告 Critical 时 yara、hash 等同阶段分析器仍会运行完，之后跳过全部第二阶段分析器
+	var parallelNames, serialNames []string
 	for _, name := range enabledNames {
 		analyzer := e.analyzers[name]
+		if !supportsFile(analyzer, filePath) {
+			continue
+		}
+		if e.config.Performance.ParallelAnalyzersEnabled() && isContentOnlyAnalyzer(analyzer) {
+			parallelNames = append(parallelNames, name)
+		} else {
+			serialNames = append(serialNames, name)
+		}
+	}
+	findings = e.runAnalyzersParallel(ctx, parallelNames, result.File, content, featureSet)
 
+	// 已确认为 Critical 时跳过剩余 (通常更慢的 ML) 分析器
+	earlyExit := e.config.EarlyExitEnabled()
+	criticalFrom := ""
+	for _, finding := range findings {
+		if earlyExit && finding.Risk >= types.RiskCritical {
+			criticalFrom = finding.AnalyzerName
+			break
+		}
+	}
+	for i, name := range serialNames {
+		if criticalFrom != "" {
+			result.SkippedAST = true
+			for _, skipped := range serialNames[i:] {
+				result.SkippedAnalyzers = append(result.SkippedAnalyzers,
+					fmt.Sprintf("%s: critical finding from %s", skipped, criticalFrom))
+			}
+			logging.InfoCtx(ctx, "Critical finding from '%s' on %s, skipping %d remaining analyzers", criticalFrom, filePath, len(serialNames)-i)
+			break
+		}
+
+		analyzer := e.analyzers[name]
 		if e.canRunAnalyzer(analyzer, featureSet) {
-			finding, analyzeErr := analyzer.Analyze(result.File, content, featureSet)
+			finding, analyzeErr := e.runAnalyzer(ctx, name, analyzer, result.File, content, featureSet)
 			if analyzeErr != nil {
-				logging.WarnLogger.Printf("Analyzer '%s' failed on %s: %v", name, filePath, analyzeErr)
+				logger := logging.WithCtx(ctx)
+				logger.Warn().Str(logging.FieldAnalyzer, name).Err(analyzeErr).Msgf("Analyzer '%s' failed on %s", name, filePath)
 			}
 			if finding != nil {
 				findings = append(findings, finding)
+				if earlyExit && finding.Risk >= types.RiskCritical {
+					criticalFrom = name
+				}
 			}
 		} else {
-			logging.InfoLogger.Printf("Skipping analyzer '%s' for %s: missing required features.", name, filePath)
+			logging.InfoCtx(ctx, "Skipping analyzer '%s' for %s: missing required features.", name, filePath)
 		}
 	}
 	analyzerDuration := time.Since(analyzerStartTime)
-	logging.InfoLogger.Printf("Analyzers finished for %s (Duration: %s)", filePath, analyzerDuration)
+	logging.InfoCtx(ctx, "Analyzers finished for %s (Duration: %s)", filePath, analyzerDuration)
 
-	// 5. 聚合得分
+	// 4. 聚合得分
+	return e.finishResult(ctx, result, findings, featureSet, start)
+}
+
+/**
+ * @Description: 聚合得分 (低置信度的发现不参与评分)，合并重复的发现，查询资产清单并截断发现
+ * @author: Mr wpl
+ * @param ctx context.Context: 日志上下文
+ * @param result *types.ScanResult: 扫描结果
+ * @param findings []*types.Finding: 分析器的发现
+ * @param featureSet *features.FeatureSet: 特征集
+ * @param start time.Time: 扫描开始时间
+ * @return *types.ScanResult: 扫描结果
+ */
+func (e *Engine) finishResult(ctx context.Context, result *types.ScanResult, findings []*types.Finding, featureSet *features.FeatureSet, start time.Time) *types.ScanResult {
+	filePath := result.File.Path
+	findings, result.SuppressedFindings = scoring.FilterByConfidence(findings, e.config.Output.MinConfidence)
+	if len(result.SuppressedFindings) > 0 {
+		logging.InfoCtx(ctx, "Suppressed %d findings below confidence %.2f for %s", len(result.SuppressedFindings), e.config.Output.MinConfidence, filePath)
+	}
 	result.Findings = findings
-	result.OverallRisk = scoring.CalculateScore(result.Findings, featureSet)
+	result.Callable = featureSet != nil && featureSet.Callable
+	result.OverallRisk = scoring.CalculateScore(result.Findings, featureSet, e.config.Scoring)
+	// 评分使用全部发现，报告只展示合并后的发现 (如 regex 与 yara 命中同一模式)
+	result.Findings = scoring.DeduplicateFindings(result.Findings)
+	if e.assetProvider != nil && result.OverallRisk > types.RiskLow {
+		e.applyAssetInventory(ctx, result) // 资产清单按原始内容的哈希匹配
+	}
+	// 评分基于全部发现，之后再截断，避免报告被单个噪声分析器淹没
+	scoring.LimitFindings(result, e.config.Performance.FindingsLimit())
 	result.Duration = time.Since(start)
 
-	logging.InfoLogger.Printf("Scan finished! Risk: %s, Findings: %d, Time: %s",
-		result.OverallRisk.String(), len(result.Findings), result.Duration)
+	logger := logging.WithCtx(ctx)
+	logger.Info().Int64(logging.FieldDurationMs, result.Duration.Milliseconds()).
+		Msgf("Scan finished! Risk: %s, Findings: %d, Time: %s", result.OverallRisk.String(), len(result.Findings), result.Duration)
 	return result
 }
 
+// whitelisted 白名单中的文件不运行分析器，结果为 Safe
+func (e *Engine) whitelisted(ctx context.Context, result *types.ScanResult, start time.Time) *types.ScanResult {
+	logging.InfoCtx(ctx, "Skipping whitelisted file: %s", result.File.Path)
+	result.OverallRisk = types.RiskNone
+	result.Duration = time.Since(start)
+	return result
+}
+
+/**
+ * @Description: 资产清单中的已知部署文件风险最高为 Low，发现仍保留在结果中。查询失败时不调整风险
+ * @author: Mr wpl
+ * @param ctx context.Context: 日志上下文
+ * @param result *types.ScanResult: 扫描结果
+ */
+func (e *Engine) applyAssetInventory(ctx context.Context, result *types.ScanResult) {
+	known, deployedBy, err := e.assetProvider.IsKnownDeployed(result.ContentSHA256, result.File.Path)
+	if err != nil {
+		logging.WarnCtx(ctx, "Asset inventory lookup failed for %s: %v", result.File.Path, err)
+		return
+	}
+	if !known {
+		return
+	}
+	if deployedBy == "" {
+		deployedBy = "unknown"
+	}
+	logging.InfoCtx(ctx, "%s is a known deployed file (%s), capping risk %s at Low (%d findings kept)",
+		result.File.Path, deployedBy, result.OverallRisk, len(result.Findings))
+	result.DeployedBy = deployedBy
+	result.OverallRisk = types.RiskLow
+}
+
+// analyzerPriority 分析器执行顺序，静态规则在前，较慢的 ML 分析器在后，便于 Critical 提前退出
+var analyzerPriority = map[string]int{
+	"hash":            0,
+	"fingerprint":     1,
+	"ssdeep":          2,
+	"yara":            3,
+	"regex":           4,
+	"python_regex":    5,
+	"htaccess":        5,
+	"shebang":         6,
+	"superglobal":     7,
+	"entropy_string":  8,
+	"ngram":           9,
+	"callgraph":       10,
+	"obfuscation":     11,
+	"statistical":     12,
+	"jsp_statistical": 13,
+	"bayes_words":     14,
+	"svm_prosses":     15,
+	"random_forest":   16,
+}
+
+/**
+ * @Description: 按 analyzerPriority 排序分析器名称，未列出的分析器排在最后并按名称排序
+ * @author: Mr wpl
+ * @param names []string: 分析器名称
+ */
+func sortAnalyzerNames(names []string) {
+	priority := func(name string) int {
+		if p, ok := analyzerPriority[name]; ok {
+			return p
+		}
+		return len(analyzerPriority)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := priority(names[i]), priority(names[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return names[i] < names[j]
+	})
+}
+
+/**
+ * @Description: 对扫描结果逐个签名，提供公钥时签名后立即验签
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param privateKeyPEM []byte: PEM 格式私钥
+ * @param publicKeyPEM []byte: PEM 格式公钥 (可为空)
+ * @return error: 错误
+ */
+func signResults(results []*types.ScanResult, privateKeyPEM []byte, publicKeyPEM []byte) error {
+	for _, res := range results {
+		sig, err := signing.Sign(res, privateKeyPEM)
+		if err != nil {
+			return err
+		}
+		res.Signature = sig
+
+		if len(publicKeyPEM) > 0 {
+			ok, err := signing.Verify(res, publicKeyPEM)
+			if err != nil {
+				return fmt.Errorf("verification of %s failed: %w", res.File.Path, err)
+			}
+			if !ok {
+				return fmt.Errorf("signature for %s does not match the verify key", res.File.Path)
+			}
+		}
+	}
+	logging.InfoLogger.Printf("Signed %d scan results", len(results))
+	return nil
+}
+
 /**
  * @Description: 检查分析器所需的功能是否在FeatureSet中可用
  * @author: Mr wpl
@@ -350,6 +1071,11 @@ func (e *Engine) canRunAnalyzer(analyzer Analyzer, fs *features.FeatureSet) bool
 			keyPresent = true
 		case "raw_ast":
 			keyPresent = fs.RawAST != nil
+		case "superglobals":
+			keyPresent = fs.SuperGlobalCounts != nil
+		case "obfuscation":
+			// ObfuscationScore 与 RawAST 一同计算，AST 不可用时分数无意义
+			keyPresent = fs.RawAST != nil
 		// Add checks for other feature keys as needed
 		default:
 			logging.WarnLogger.Printf("Analyzer '%s' requires check for unknown feature key '%s'", analyzer.Name(), featureKey)
@@ -363,6 +1089,48 @@ func (e *Engine) canRunAnalyzer(analyzer Analyzer, fs *features.FeatureSet) bool
 	return true
 }
 
+// supportsFile 分析器是否适用于该文件：SupportedExtensions 为 nil 或文件没有扩展名 (如标准输入) 时适用
+func supportsFile(analyzer Analyzer, filePath string) bool {
+	exts := analyzer.SupportedExtensions()
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if exts == nil || ext == "" {
+		return true
+	}
+	for _, supported := range exts {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @Description: 返回流式输出使用的报告器 (NDJSON 或 LSP)。指定 -output 时按扩展名 (.ndjson/.jsonl) 判断，否则按输出格式判断
+ * @author: Mr wpl
+ * @param task *Task: 任务
+ * @return reporting.StreamingReporter: 流式报告器，不使用流式输出时为 nil
+ */
+func (e *Engine) streamingReporter(task *Task) reporting.StreamingReporter {
+	format := strings.ToLower(e.config.Output.Format)
+	if task.ReportPath != "" {
+		switch strings.ToLower(filepath.Ext(task.ReportPath)) {
+		case ".ndjson", ".jsonl":
+			format = "ndjson"
+		default:
+			return nil
+		}
+	}
+	switch format {
+	case "ndjson":
+		streamer := reporting.NewNDJSONReporter()
+		streamer.Verbose = task.Verbose
+		return streamer
+	case "lsp":
+		return reporting.NewLSPDiagnosticsReporter()
+	}
+	return nil
+}
+
 /**
  * @Description: 处理报告生成逻辑，支持html生成，默认终端命令生成
  * @author: Mr wpl
@@ -388,6 +1156,21 @@ func (e *Engine) generateReport(results []*types.ScanResult, task *Task) error {
 		case ".json":
 			outputFormat = "json"
 			reporter = reporting.NewJsonReporter()
+		case ".ndjson", ".jsonl":
+			outputFormat = "ndjson"
+			reporter = reporting.NewNDJSONReporter()
+		case ".sarif":
+			outputFormat = "sarif"
+			reporter = reporting.NewSarifReporter()
+		case ".pdf":
+			outputFormat = "pdf"
+			reporter = reporting.NewPdfReporter()
+		case ".csv":
+			outputFormat = "csv"
+			reporter = reporting.NewCsvReporter()
+		case ".xml":
+			outputFormat = "junit"
+			reporter = reporting.NewJUnitReporter()
 		case ".console", ".txt", "":
 			outputFormat = "console"
 			reporter = reporting.NewConsoleReporter()
@@ -416,12 +1199,68 @@ func (e *Engine) generateReport(results []*types.ScanResult, task *Task) error {
 		case "json":
 			reporter = reporting.NewJsonReporter()
 			outputPath = ""
+		case "ndjson":
+			reporter = reporting.NewNDJSONReporter()
+			outputPath = ""
+		case "lsp":
+			reporter = reporting.NewLSPDiagnosticsReporter()
+			outputPath = ""
+		case "sarif":
+			reporter = reporting.NewSarifReporter()
+			outputPath = ""
+		case "csv":
+			reporter = reporting.NewCsvReporter()
+			outputPath = ""
+		case "junit":
+			reporter = reporting.NewJUnitReporter()
+			outputPath = ""
 		default:
 			reporter = reporting.NewConsoleReporter()
 			outputPath = ""
 		}
 	}
 
+	root := scanRoot(task.rootPaths())
+	var expiresAt time.Time
+	if task.ReportTTL > 0 {
+		expiresAt = time.Now().Add(task.ReportTTL)
+	}
+	switch rep := reporter.(type) {
+	case *reporting.ConsoleReporter:
+		rep.Verbose = task.Verbose
+		rep.GroupByDirectory = task.GroupByDirectory
+		rep.Emoji = task.Emoji
+		rep.ScanRoot = root
+		rep.OutputTemplate = e.consoleTemplate
+		rep.Config = e.config
+		rep.SortKeys = e.sortKeys
+	case *reporting.HtmlReporter:
+		rep.ScanRoot = root
+		rep.SortKeys = e.sortKeys
+		rep.Verbose = task.Verbose
+		rep.ExpiresAt = expiresAt
+		rep.ColorScheme = task.ColorScheme
+		rep.JournalPath = task.JournalPath
+	case *reporting.JsonReporter:
+		rep.ScanRoot = root
+		rep.SortKeys = e.sortKeys
+		rep.Verbose = task.Verbose
+		rep.ExpiresAt = expiresAt
+	case *reporting.NDJSONReporter:
+		rep.Verbose = task.Verbose
+	case *reporting.SarifReporter:
+		rep.ScanRoot = root
+		rep.Verbose = task.Verbose
+	case *reporting.PdfReporter:
+		rep.ScanRoot = root
+		rep.SortKeys = e.sortKeys
+		rep.FontPath = e.config.Output.PDFFont
+	case *reporting.CsvReporter:
+		rep.SortKeys = e.sortKeys
+	case *reporting.JUnitReporter:
+		rep.SortKeys = e.sortKeys
+	}
+
 	// 2. Generate the report using the selected reporter
 	logging.InfoLogger.Printf("Generating '%s' report...", outputFormat)
 	if err := reporter.Generate(results, outputPath); err != nil {
@@ -434,32 +1273,41 @@ func (e *Engine) generateReport(results []*types.ScanResult, task *Task) error {
 	}
 
 	if outputPath != "" {
-		fmt.Println("Report generated: %s\n", outputPath) // Inform user about file creation
+		fmt.Printf("Report generated: %s\n", outputPath) // Inform user about file creation
 	}
 
 	return nil
 }
 
 /**
- * @Description: 查找所有符合条件的php文件
+ * @Description: 查找所有符合条件的文件 (扩展名在 scan_extensions 中)
  * @author: Mr wpl
  * @param paths []string: 需要扫描的文件或目录
  * @param exclusions []string: 需要排除的文件或目录
- * @return []string: 符合条件的php文件
+ * @param extensions []string: 需要扫描的扩展名 (小写、带点)，见 types.Config.Extensions
+ * @param followSymlinks bool: 是否跟随符号链接
+ * @param scanArchives bool: 是否同时收集 phar 归档
+ * @param timeFilter TimeFilter: 遍历目录时按修改时间筛选，为 nil 时不筛选；直接指定的文件不受影响
+ * @return []string: 符合条件的文件
  */
-func findFiles(paths []string, exclusions []string) ([]string, error) {
+func findFiles(paths []string, exclusions []string, extensions []string, followSymlinks bool, scanArchives bool, timeFilter TimeFilter) ([]string, error) {
+	files, _, err := findFilesWithStats(paths, exclusions, extensions, followSymlinks, scanArchives, timeFilter)
+	return files, err
+}
+
+// findStats findFiles 跳过的路径数 (-dry-run 报告使用)
+type findStats struct {
+	Excluded         int // 命中 -exclude 的文件或目录 (排除的目录只计一次)
+	SkippedExtension int // 扩展名不在 scan_extensions 中的文件
+	SkippedByTime    int // 不在 -since/-last-days/-last-hours 时间范围内的文件
+}
+
+// findFilesWithStats 同 findFiles，同时返回被排除与跳过的路径数
+func findFilesWithStats(paths []string, exclusions []string, extensions []string, followSymlinks bool, scanArchives bool, timeFilter TimeFilter) ([]string, findStats, error) {
 	var files []string
-	exclusionPatterns := make(map[string]bool)
-	for _, ex := range exclusions {
-		// Clean and normalize the exclusion path
-		absEx, err := filepath.Abs(ex)
-		if err == nil {
-			exclusionPatterns[filepath.Clean(absEx)] = true
-		} else {
-			logging.WarnLogger.Printf("Could not get absolute path for exclusion '%s': %v", ex, err)
-			exclusionPatterns[filepath.Clean(ex)] = true
-		}
-	}
+	var stats findStats
+	// exclusionPatterns 与 processedPaths 的键均经 platform.NormalizePath 规范化，Windows 上 \ 与 / 写法视为同一路径
+	exclusionPatterns := newExclusionMatcher(exclusions)
 
 	processedPaths := make(map[string]bool)
 
@@ -470,28 +1318,30 @@ func findFiles(paths []string, exclusions []string) ([]string, error) {
 			continue
 		}
 		cleanPath := filepath.Clean(absP)
+		pathKey := platform.NormalizePath(cleanPath)
 
-		if processedPaths[cleanPath] {
+		if processedPaths[pathKey] {
 			continue
 		}
 
 		// Check exclusion for the root path provided
-		if exclusionPatterns[cleanPath] {
+		if exclusionPatterns.Match(pathKey) {
 			logging.InfoLogger.Printf("Excluding path provided directly: %s", p)
-			processedPaths[cleanPath] = true // Mark as processed even if excluded
+			stats.Excluded++
+			processedPaths[pathKey] = true // Mark as processed even if excluded
 			continue
 		}
 
 		info, err := os.Stat(cleanPath)
 		if err != nil {
 			logging.WarnLogger.Printf("Skipping path %s: %v", p, err)
-			processedPaths[cleanPath] = true
+			processedPaths[pathKey] = true
 			continue
 		}
 
 		if info.IsDir() {
-			fmt.Println("Walking directory: %s", cleanPath)
-			walkErr := filepath.Walk(cleanPath, func(path string, info os.FileInfo, err error) error {
+			logging.InfoLogger.Printf("Walking directory: %s", cleanPath)
+			walkFn := func(path string, info os.FileInfo, err error) error {
 				if err != nil {
 					logging.WarnLogger.Printf("Error accessing path %s during walk: %v", path, err)
 					// Decide whether to skip file or directory based on error type
@@ -504,57 +1354,194 @@ func findFiles(paths []string, exclusions []string) ([]string, error) {
 					return nil
 				}
 				cleanWalkPath := filepath.Clean(absWalkPath)
+				walkKey := platform.NormalizePath(cleanWalkPath)
 
 				// Check exclusion during walk
-				if exclusionPatterns[cleanWalkPath] {
+				if exclusionPatterns.Match(walkKey) {
+					stats.Excluded++
 					if info.IsDir() {
-						processedPaths[cleanWalkPath] = true
+						processedPaths[walkKey] = true
 						return filepath.SkipDir
 					}
 					return nil
 				}
 
 				if !info.IsDir() {
-					if processedPaths[cleanWalkPath] {
+					if processedPaths[walkKey] {
 						return nil
 					}
-					// Filter by extension (e.g., only PHP)
-					if strings.ToLower(filepath.Ext(path)) == ".php" {
+					// Filter by extension (scan_extensions, .php by default)
+					if isScannableFile(path, extensions, scanArchives) {
+						if timeFilter != nil && !timeFilter.Include(info) {
+							stats.SkippedByTime++
+							return nil
+						}
 						files = append(files, cleanWalkPath)
-						processedPaths[cleanWalkPath] = true
+						processedPaths[walkKey] = true
 					} else {
-						fmt.Println("Skipping non-PHP file during walk: %s", path)
+						stats.SkippedExtension++
+						logging.InfoLogger.Printf("Skipping file with unscanned extension during walk: %s", path)
 					}
 				} else {
-					processedPaths[cleanWalkPath] = true
+					processedPaths[walkKey] = true
 				}
 				return nil
-			})
+			}
+			var walkErr error
+			if followSymlinks {
+				walkErr = walkFollowingSymlinks(cleanPath, walkFn)
+			} else {
+				walkErr = filepath.Walk(cleanPath, walkFn)
+			}
 			if walkErr != nil {
 				logging.ErrorLogger.Printf("Error walking directory %s: %v", cleanPath, walkErr)
 			}
-			processedPaths[cleanPath] = true
+			processedPaths[pathKey] = true
 		} else {
 			// Process single file
-			if processedPaths[cleanPath] {
+			if processedPaths[pathKey] {
 				continue
 			}
-			if strings.ToLower(filepath.Ext(cleanPath)) == ".php" {
+			if isScannableFile(cleanPath, extensions, scanArchives) {
 				files = append(files, cleanPath)
 			} else {
-				logging.InfoLogger.Printf("Skipping non-PHP file specified directly: %s", p)
+				stats.SkippedExtension++
+				logging.InfoLogger.Printf("Skipping file with unscanned extension specified directly: %s", p)
 			}
-			processedPaths[cleanPath] = true
+			processedPaths[pathKey] = true
 		}
 	}
-	logging.InfoLogger.Printf("Found %d unique PHP files to scan.", len(files))
-	return files, nil
+	if stats.SkippedByTime > 0 {
+		logging.InfoLogger.Printf("Skipped %d files not modified within the time window.", stats.SkippedByTime)
+	}
+	logging.InfoLogger.Printf("Found %d unique files to scan.", len(files))
+	return files, stats, nil
+}
+
+/**
+ * @Description: 判断文件扩展名是否需要扫描
+ * @author: Mr wpl
+ * @param path string: 文件路径
+ * @param extensions []string: 需要扫描的扩展名 (小写、带点)
+ * @param scanArchives bool: 是否扫描归档 (phar、ZIP、TAR.GZ)
+ * @return bool: 是否需要扫描
+ */
+func isScannableFile(path string, extensions []string, scanArchives bool) bool {
+	if unpacker.ArchiveKind(path) != "" {
+		return scanArchives
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, scanExt := range extensions {
+		if ext == scanExt {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @Description: 解包文件列表中的归档，将其中需要扫描的成员加入扫描列表。
+ * phar 文件本身 (含 PHP stub) 仍保留在列表中；ZIP、TAR.GZ 归档本身不扫描，由其成员替代 (嵌套归档递归解包)
+ * @author: Mr wpl
+ * @param files []string: 待扫描文件
+ * @param archiveDir string: 解包目录
+ * @param extensions []string: 需要扫描的扩展名 (小写、带点)
+ * @param maxTotal int64: 单个 ZIP/TAR.GZ 归档的解压总大小上限，<= 0 时不限制
+ * @return []string: 追加成员后的文件列表
+ * @return map[string]string: 解包文件路径 -> 显示用的虚拟路径，如 archive.zip!/internal/shell.php
+ */
+func expandArchives(files []string, archiveDir string, extensions []string, maxTotal int64) ([]string, map[string]string) {
+	virtualPaths := make(map[string]string)
+	expanded := make([]string, 0, len(files))
+
+	for i, file := range files {
+		kind := unpacker.ArchiveKind(file)
+		if kind == "" {
+			expanded = append(expanded, file)
+			continue
+		}
+
+		destDir := filepath.Join(archiveDir, fmt.Sprintf("%d", i))
+		if kind == unpacker.KindPhar {
+			expanded = append(expanded, file)
+			members, err := unpacker.UnpackPhar(file, destDir)
+			if err != nil {
+				logging.WarnLogger.Printf("Could not unpack phar %s: %v", file, err)
+			}
+			for _, member := range members {
+				rel, relErr := filepath.Rel(destDir, member)
+				if relErr != nil {
+					rel = filepath.Base(member)
+				}
+				virtualPaths[member] = file + "!/" + filepath.ToSlash(rel)
+				expanded = append(expanded, member)
+			}
+			logging.InfoLogger.Printf("Unpacked %d PHP files from %s", len(members), file)
+			continue
+		}
+
+		members, err := unpacker.UnpackArchive(file, destDir, extensions, maxTotal)
+		if err != nil {
+			logging.WarnLogger.Printf("Could not fully unpack archive %s: %v", file, err)
+		}
+		for _, member := range members {
+			virtualPaths[member.Path] = file + "!/" + member.Name
+			expanded = append(expanded, member.Path)
+		}
+		logging.InfoLogger.Printf("Unpacked %d files from %s", len(members), file)
+	}
+
+	return expanded, virtualPaths
 }
 
 // Task 定义需要扫描的内容
 type Task struct {
 	Paths        []string // 需要扫描的文件或目录
-	Exclusions   []string // 需要排除的文件或目录
+	Exclusions   []string // 需要排除的文件、目录或 glob (与配置中的 exclusions 合并)
 	ReportPath   string   // 保存报告的路径 (来自 -output)
 	OutputFormat string   // Format is now determined by ReportPath or config
+	SignKey      []byte   // PEM 私钥，非空时对结果签名 (来自 -sign-key)
+	VerifyKey    []byte   // PEM 公钥，非空时签名后立即验签 (来自 -verify-key)
+	// FollowSymlinks 遍历目录时跟随符号链接 (来自 -follow-symlinks)。
+	// 注意：符号链接较多的大目录树上开启会显著增加扫描时间。
+	FollowSymlinks   bool
+	ScanArchives     bool // 扫描 phar、ZIP、TAR.GZ 归档中的文件 (来自 -scan-archives，配置 scan_archives 同样生效)
+	GroupByDirectory bool // 控制台报告按目录输出小计 (来自 -group-by-dir)
+	Emoji            bool // 控制台报告使用 emoji 风险前缀 (来自 -emoji)
+	// VTTimeout VirusTotal 查询的总时间上限 (来自 -vt-timeout)，超时的文件标记为 pending
+	VTTimeout time.Duration
+	Verbose   bool // 报告中显示被 min_confidence 抑制的发现 (来自 -verbose)
+	// TimeFilter 只扫描近期修改的文件 (来自 -since、-last-days、-last-hours)，为 nil 时扫描全部
+	TimeFilter TimeFilter
+	NoDedup    bool // 不按内容去重，每个路径都完整扫描 (来自 -no-dedup)
+	// Incremental 修改时间与大小未变的文件复用扫描状态库 (state_path) 中的上次结果 (来自 -incremental)
+	Incremental bool
+	// ReportTTL 报告保留时长 (来自 -report-ttl)，大于 0 时 JSON/HTML 报告记录过期时间
+	ReportTTL time.Duration
+	// JournalPath 扫描日志路径 (来自 -journal)，非空时记录本次扫描，HTML 报告据此显示同一目录的历史趋势
+	JournalPath string
+	// ColorScheme HTML 报告的风险等级配色 (来自 -color-scheme)，为 nil 时使用默认配色
+	ColorScheme *reporting.ColorScheme
+	// DisplayPaths 临时文件路径 → 报告中显示的名称 (来自 -path -，标准输入显示为 <stdin>)
+	DisplayPaths map[string]string
+	// OnResults 非流式模式下生成报告前回调全部结果 (定时扫描用于汇总和通知)
+	OnResults func(results []*types.ScanResult)
+	// KeepAlive 扫描结束后不关闭 PHP 桥接，引擎可继续使用 (-watch 在首次扫描后监控文件)，使用完毕后需调用 Close
+	KeepAlive bool
+	// DryRun 只列出将要扫描的文件、启用的分析器与预计耗时，不运行分析器、不生成报告 (来自 -dry-run)
+	DryRun bool
+}
+
+// rootPaths 计算扫描根目录所用的路径，跳过 DisplayPaths 中的临时文件
+func (t *Task) rootPaths() []string {
+	if len(t.DisplayPaths) == 0 {
+		return t.Paths
+	}
+	paths := make([]string, 0, len(t.Paths))
+	for _, p := range t.Paths {
+		if _, ok := t.DisplayPaths[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	return paths
 }
@@ -0,0 +1,372 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 引擎测试，使用模拟分析器代替需要模型与 PHP 桥接的真实分析器
+ */
+package engine
+
+import (
+	"archive/zip"
+	"bt-shieldml/internal/config"
+	"bt-shieldml/internal/features"
+	"bt-shieldml/internal/reporting"
+	"bt-shieldml/internal/whitelist"
+	"bt-shieldml/pkg/types"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+)
+
+// mockAnalyzer 内容包含 match 时返回风险为 risk 的发现，并记录被调用的次数
+type mockAnalyzer struct {
+	name     string
+	match    string
+	risk     types.RiskLevel
+	required []string
+	calls    atomic.Int32
+}
+
+func (m *mockAnalyzer) Name() string { return m.name }
+
+func (m *mockAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	m.calls.Add(1)
+	if !bytes.Contains(content, []byte(m.match)) {
+		return nil, nil
+	}
+	return &types.Finding{
+		AnalyzerName: m.name,
+		Description:  "mock match: " + m.match,
+		Risk:         m.risk,
+		Confidence:   1,
+	}, nil
+}
+
+func (m *mockAnalyzer) RequiredFeatures() []string { return m.required }
+
+func (m *mockAnalyzer) SupportedExtensions() []string { return nil }
+
+// newTestEngine 以默认配置与给定分析器构造引擎，不启动 PHP 桥接
+func newTestEngine(t testing.TB, analyzers ...Analyzer) *Engine {
+	t.Helper()
+	cfg := config.GetDefaultConfig()
+	cfg.EnabledAnalyzers = nil
+	enabled := make(map[string]Analyzer, len(analyzers))
+	for _, analyzer := range analyzers {
+		cfg.EnabledAnalyzers = append(cfg.EnabledAnalyzers, analyzer.Name())
+		enabled[analyzer.Name()] = analyzer
+	}
+	return &Engine{
+		config:     cfg,
+		analyzers:  enabled,
+		fileReader: osFileReader{},
+		workers:    make(chan struct{}, cfg.Performance.Concurrency),
+	}
+}
+
+// resultsByPath 按 File.Path 索引扫描结果
+func resultsByPath(results []*types.ScanResult) map[string]*types.ScanResult {
+	byPath := make(map[string]*types.ScanResult, len(results))
+	for _, res := range results {
+		byPath[res.File.Path] = res
+	}
+	return byPath
+}
+
+func TestScanPharArchive(t *testing.T) {
+	tmp := t.TempDir()
+	pharPath := filepath.Join(tmp, "plugin.phar")
+
+	f, err := os.Create(pharPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		".phar/stub.php": "<?php Phar::mapPhar('plugin.phar'); __HALT_COMPILER(); ?>",
+		"src/shell.php":  "<?php eval($_POST['cmd']);",
+		"src/clean.php":  "<?php echo 'ok';",
+	} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	analyzer := &mockAnalyzer{name: "regex", match: "eval(", risk: types.RiskCritical}
+	e := newTestEngine(t, analyzer)
+	results, err := e.ScanPaths(&Task{Paths: []string{tmp}, ScanArchives: true})
+	if err != nil {
+		t.Fatalf("ScanPaths() error = %v", err)
+	}
+
+	byPath := resultsByPath(results)
+	var paths []string
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	want := []string{
+		pharPath,
+		pharPath + "!/.phar/stub.php",
+		pharPath + "!/src/clean.php",
+		pharPath + "!/src/shell.php",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("scanned %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("scanned %v, want %v", paths, want)
+		}
+	}
+
+	shell := byPath[pharPath+"!/src/shell.php"]
+	if shell.Error != nil || len(shell.Findings) != 1 || shell.Findings[0].Risk != types.RiskCritical {
+		t.Errorf("phar member was not scanned: error=%v findings=%v", shell.Error, shell.Findings)
+	}
+	if clean := byPath[pharPath+"!/src/clean.php"]; len(clean.Findings) != 0 {
+		t.Errorf("clean phar member has findings: %v", clean.Findings)
+	}
+}
+
+/**
+ * @Description: 以 NDJSON 流式模式扫描 dir，按文件路径索引输出的每一行 (Duration 置空以便比较)
+ * @author: Mr wpl
+ * @param t *testing.T: 测试
+ * @param e *Engine: 引擎
+ * @param dir string: 扫描目录
+ * @return map[string]reporting.NDJSONResult: 每个文件的结果行
+ */
+func scanNDJSON(t *testing.T, e *Engine, dir string) map[string]reporting.NDJSONResult {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "results.ndjson")
+	if err := e.Scan(&Task{Paths: []string{dir}, ReportPath: out}); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lines := make(map[string]reporting.NDJSONResult)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line reporting.NDJSONResult
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line is not a self-contained JSON object: %v: %s", err, scanner.Text())
+		}
+		if line.File.Path == "" || line.Risk == "" || line.Findings == nil || line.Duration == "" {
+			t.Errorf("line is missing file, risk, findings or duration: %s", scanner.Text())
+		}
+		if _, dup := lines[line.File.Path]; dup {
+			t.Errorf("%s was emitted more than once", line.File.Path)
+		}
+		line.Duration = ""
+		lines[line.File.Path] = line
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+// TestScanNDJSONOrderIndependent 分析器以任意顺序注册、文件以任意顺序完成时，每个文件输出的行都相同
+func TestScanNDJSONOrderIndependent(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		content := fmt.Sprintf("<?php echo %d;", i)
+		switch i % 4 {
+		case 1:
+			content = fmt.Sprintf("<?php eval($_POST['c%d']);", i)
+		case 2:
+			content = fmt.Sprintf("<?php $f = base64_decode('%d'); system($f);", i)
+		case 3:
+			content = fmt.Sprintf("<?php eval(base64_decode($_GET['%d']));", i)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%02d.php", i)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newAnalyzers := func() []Analyzer {
+		return []Analyzer{
+			&mockAnalyzer{name: "regex", match: "eval(", risk: types.RiskCritical},
+			&mockAnalyzer{name: "superglobal", match: "$_", risk: types.RiskMedium},
+			&mockAnalyzer{name: "entropy_string", match: "base64_decode", risk: types.RiskLow},
+			&mockAnalyzer{name: "statistical", match: "system(", risk: types.RiskHigh, required: []string{"statistical"}},
+		}
+	}
+
+	want := scanNDJSON(t, newTestEngine(t, newAnalyzers()...), dir)
+	if len(want) != 20 {
+		t.Fatalf("emitted %d lines, want 20", len(want))
+	}
+
+	rng := rand.New(rand.NewSource(1640))
+	for run := 0; run < 5; run++ {
+		analyzers := newAnalyzers()
+		rng.Shuffle(len(analyzers), func(i, j int) { analyzers[i], analyzers[j] = analyzers[j], analyzers[i] })
+		e := newTestEngine(t, analyzers...)
+		e.workers = make(chan struct{}, 1+run*4) // 不同并发度下文件的完成顺序不同
+
+		got := scanNDJSON(t, e, dir)
+		if len(got) != len(want) {
+			t.Fatalf("run %d emitted %d lines, want %d", run, len(got), len(want))
+		}
+		for path, wantLine := range want {
+			if gotLine := got[path]; !reflect.DeepEqual(gotLine, wantLine) {
+				t.Errorf("run %d: line for %s = %+v, want %+v", run, path, gotLine, wantLine)
+			}
+		}
+	}
+}
+
+// TestShortCircuitAfterCritical 第一个分析器报告 Critical 后不再调用后续分析器；并发阶段的分析器不受短路影响
+func TestShortCircuitAfterCritical(t *testing.T) {
+	const shell = "<?php eval($_POST['cmd']);"
+	// required 为 callable 的分析器不是纯内容分析器，按优先级顺序执行 (hash → yara → bayes_words → svm_prosses)
+	serial := []string{"callable"}
+
+	tests := []struct {
+		name         string
+		shortCircuit bool
+		earlyExit    bool
+		parallel     bool
+		analyzers    []*mockAnalyzer
+		wantCalls    map[string]int32
+		wantSkipped  int
+	}{
+		{
+			name:         "first serial analyzer critical",
+			shortCircuit: true, earlyExit: true,
+			analyzers: []*mockAnalyzer{
+				{name: "hash", match: "eval(", risk: types.RiskCritical, required: serial},
+				{name: "yara", match: "eval(", risk: types.RiskHigh, required: serial},
+				{name: "bayes_words", match: "eval(", risk: types.RiskHigh, required: serial},
+				{name: "svm_prosses", match: "eval(", risk: types.RiskHigh, required: serial},
+			},
+			wantCalls:   map[string]int32{"hash": 1, "yara": 0, "bayes_words": 0, "svm_prosses": 0},
+			wantSkipped: 3,
+		},
+		{
+			name:         "critical midway",
+			shortCircuit: true, earlyExit: true,
+			analyzers: []*mockAnalyzer{
+				{name: "hash", match: "no match", risk: types.RiskCritical, required: serial},
+				{name: "yara", match: "eval(", risk: types.RiskCritical, required: serial},
+				{name: "bayes_words", match: "eval(", risk: types.RiskHigh, required: serial},
+			},
+			wantCalls:   map[string]int32{"hash": 1, "yara": 1, "bayes_words": 0},
+			wantSkipped: 1,
+		},
+		{
+			name:         "short_circuit disabled",
+			shortCircuit: false, earlyExit: true,
+			analyzers: []*mockAnalyzer{
+				{name: "hash", match: "eval(", risk: types.RiskCritical, required: serial},
+				{name: "yara", match: "eval(", risk: types.RiskHigh, required: serial},
+				{name: "bayes_words", match: "eval(", risk: types.RiskHigh, required: serial},
+			},
+			wantCalls: map[string]int32{"hash": 1, "yara": 1, "bayes_words": 1},
+		},
+		{
+			name:         "early_exit disabled",
+			shortCircuit: true, earlyExit: false,
+			analyzers: []*mockAnalyzer{
+				{name: "hash", match: "eval(", risk: types.RiskCritical, required: serial},
+				{name: "bayes_words", match: "eval(", risk: types.RiskHigh, required: serial},
+			},
+			wantCalls: map[string]int32{"hash": 1, "bayes_words": 1},
+		},
+		{
+			// 并发阶段的分析器同时运行：regex 的 Critical 不会阻止 yara，只跳过之后顺序执行的分析器
+			name:         "parallel analyzers still run",
+			shortCircuit: true, earlyExit: true, parallel: true,
+			analyzers: []*mockAnalyzer{
+				{name: "regex", match: "eval(", risk: types.RiskCritical},
+				{name: "yara", match: "eval(", risk: types.RiskHigh},
+				{name: "bayes_words", match: "eval(", risk: types.RiskHigh, required: serial},
+			},
+			wantCalls:   map[string]int32{"regex": 1, "yara": 1, "bayes_words": 0},
+			wantSkipped: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzers := make([]Analyzer, len(tt.analyzers))
+			for i, a := range tt.analyzers {
+				analyzers[i] = a
+			}
+			e := newTestEngine(t, analyzers...)
+			e.config.Performance.ShortCircuit = &tt.shortCircuit
+			e.config.EarlyExit = &tt.earlyExit
+			e.config.Performance.ParallelAnalyzers = &tt.parallel
+			e.SetFileReader(NewMockFileReader(map[string][]byte{"/www/shell.php": []byte(shell)}))
+
+			res := e.ScanFile("/www/shell.php")
+			if res.Error != nil {
+				t.Fatalf("ScanFile() error = %v", res.Error)
+			}
+			for _, a := range tt.analyzers {
+				if got := a.calls.Load(); got != tt.wantCalls[a.name] {
+					t.Errorf("%s called %d times, want %d", a.name, got, tt.wantCalls[a.name])
+				}
+			}
+			if len(res.SkippedAnalyzers) != tt.wantSkipped {
+				t.Errorf("SkippedAnalyzers = %v, want %d entries", res.SkippedAnalyzers, tt.wantSkipped)
+			}
+			if res.SkippedAST != (tt.wantSkipped > 0) {
+				t.Errorf("SkippedAST = %v, want %v", res.SkippedAST, tt.wantSkipped > 0)
+			}
+		})
+	}
+}
+
+// TestWhitelistedPathNotDeduplicated 路径白名单下的文件先被分发时，其 Safe 结果不会复用到其他路径下内容相同的文件
+func TestWhitelistedPathNotDeduplicated(t *testing.T) {
+	dir := t.TempDir()
+	shell := "<?php eval($_POST['x']);"
+	for _, name := range []string{"a_trusted/shell.php", "b/shell.php", "c/shell.php"} {
+		writeTestFile(t, filepath.Join(dir, filepath.FromSlash(name)), shell)
+	}
+	wlPath := filepath.Join(t.TempDir(), "whitelist.txt")
+	writeTestFile(t, wlPath, "a_trusted/**\n")
+	wl, err := whitelist.Load(wlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := newTestEngine(t, &mockAnalyzer{name: "regex", match: "eval(", risk: types.RiskCritical})
+	e.whitelist = wl
+	lines := scanNDJSON(t, e, dir)
+
+	trusted := lines[filepath.Join(dir, "a_trusted", "shell.php")]
+	if trusted.Risk != types.RiskNone.String() || len(trusted.Findings) != 0 || trusted.DuplicateOf != "" {
+		t.Errorf("whitelisted copy = %+v, want Safe without findings or duplicate_of", trusted)
+	}
+	first := lines[filepath.Join(dir, "b", "shell.php")]
+	if first.Risk == types.RiskNone.String() || len(first.Findings) != 1 || first.DuplicateOf != "" {
+		t.Errorf("first non-whitelisted copy = %+v, want the finding from its own scan", first)
+	}
+	// 白名单外的相同内容之间仍然去重
+	dup := lines[filepath.Join(dir, "c", "shell.php")]
+	if dup.Risk != first.Risk || len(dup.Findings) != 1 || dup.DuplicateOf != filepath.Join(dir, "b", "shell.php") {
+		t.Errorf("second non-whitelisted copy = %+v, want the result of b/shell.php", dup)
+	}
+}
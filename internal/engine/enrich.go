@@ -0,0 +1,80 @@
+/*
+ * @Date: 2025-05-27 11:02:36
+ * @Editors: Mr wpl
+ * @Description: 扫描完成后的 VirusTotal 结果补充
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/enrichment"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"context"
+	"time"
+)
+
+/**
+ * @Description: 对有风险的文件查询 VirusTotal，并写入 ScanResult.VT。
+ * 超过 timeout 仍未完成的文件标记为 "pending"，报告照常生成。
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param timeout time.Duration: 查询总时间上限，<=0 时不限制
+ */
+func (e *Engine) enrichWithVirusTotal(results []*types.ScanResult, timeout time.Duration) {
+	vtCfg := e.config.VirusTotal
+
+	hashByResult := make(map[*types.ScanResult]string)
+	var hashes []string
+	for _, res := range results {
+		if res == nil || res.Error != nil || res.OverallRisk <= types.RiskNone {
+			continue
+		}
+		// 使用扫描时读取的内容的哈希：文件可能已被修改，归档成员也没有可重新读取的路径
+		sha := res.ContentSHA256
+		if sha == "" {
+			logging.WarnLogger.Printf("Skipping VirusTotal lookup for %s: no content hash recorded", res.File.Path)
+			continue
+		}
+		hashByResult[res] = sha
+		hashes = append(hashes, sha)
+	}
+	if len(hashes) == 0 {
+		return
+	}
+
+	cachePath := vtCfg.CachePath
+	if cachePath == "" {
+		cachePath = enrichment.DefaultVTCachePath
+	}
+	cache, err := enrichment.OpenVTCache(cachePath)
+	if err != nil {
+		logging.WarnLogger.Printf("VirusTotal cache unavailable, continuing without it: %v", err)
+		cache = nil
+	} else {
+		defer cache.Close()
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	logging.InfoLogger.Printf("Looking up %d file hashes on VirusTotal (timeout %s)...", len(hashes), timeout)
+	checker := enrichment.NewRateLimitedHashChecker(vtCfg.APIKey, vtCfg.Concurrency, vtCfg.RequestsPerMinute, cache)
+	reports := checker.Check(ctx, hashes)
+
+	pending := 0
+	for res, sha := range hashByResult {
+		if report, ok := reports[sha]; ok {
+			res.VT = report.Summary()
+		} else {
+			res.VT = "pending"
+			pending++
+		}
+	}
+	if pending > 0 {
+		logging.WarnLogger.Printf("VirusTotal enrichment incomplete: %d files still pending", pending)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: VirusTotal 结果补充测试：按扫描时记录的 ContentSHA256 查询 (不重新读取文件)，超时未完成的文件标记为 pending
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/enrichment"
+	"bt-shieldml/pkg/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEnrichWithVirusTotal(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "vt_cache.db")
+	cache, err := enrichment.OpenVTCache(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Put(&enrichment.VTReport{SHA256: "cachedsha", Found: true, Malicious: 30, Total: 70, FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	cache.Close()
+
+	e := newTestEngine(t)
+	e.config.VirusTotal = types.VirusTotal{APIKey: "test-key", Concurrency: 1, CachePath: cachePath}
+
+	// 文件路径均不存在 (如归档成员的虚拟路径)，只能使用扫描时的哈希
+	cached := &types.ScanResult{File: types.FileInfo{Path: "/archive.zip/cached.php"}, OverallRisk: types.RiskHigh, ContentSHA256: "cachedsha"}
+	pending := &types.ScanResult{File: types.FileInfo{Path: "/archive.zip/new.php"}, OverallRisk: types.RiskCritical, ContentSHA256: "newsha"}
+	noHash := &types.ScanResult{File: types.FileInfo{Path: "/www/segment-error.php"}, OverallRisk: types.RiskMedium}
+	safe := &types.ScanResult{File: types.FileInfo{Path: "/www/index.php"}, OverallRisk: types.RiskNone, ContentSHA256: "cachedsha"}
+	results := []*types.ScanResult{cached, pending, noHash, safe, nil}
+
+	// 超时极短：缓存命中的文件有结果，其余查询未完成
+	e.enrichWithVirusTotal(results, time.Nanosecond)
+
+	if cached.VT != "30/70" {
+		t.Errorf("cached VT = %q, want 30/70 from the cache", cached.VT)
+	}
+	if pending.VT != "pending" {
+		t.Errorf("unresolved VT = %q, want pending", pending.VT)
+	}
+	if noHash.VT != "" {
+		t.Errorf("VT without a content hash = %q, want no lookup", noHash.VT)
+	}
+	if safe.VT != "" {
+		t.Errorf("safe file VT = %q, want no lookup", safe.VT)
+	}
+}
+
+// TestScanFileRecordsContentSHA256 ContentSHA256 为转码前原始字节的哈希
+func TestScanFileRecordsContentSHA256(t *testing.T) {
+	raw := []byte("\xef\xbb\xbf<?php echo 'caf\xc3\xa9';")
+	e := newTestEngine(t, &mockAnalyzer{name: "regex", match: "echo", risk: types.RiskMedium})
+	e.SetFileReader(NewMockFileReader(map[string][]byte{"/www/bom.php": raw}))
+
+	res := e.ScanFile("/www/bom.php")
+	if res.Error != nil {
+		t.Fatalf("ScanFile() error = %v", res.Error)
+	}
+	sum := sha256.Sum256(raw)
+	if want := hex.EncodeToString(sum[:]); res.ContentSHA256 != want {
+		t.Errorf("ContentSHA256 = %s, want %s", res.ContentSHA256, want)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 排除规则：不含通配符的条目按绝对路径精确匹配，含 * ? [ 的条目为 glob (filepath.Match 语义，** 匹配任意层目录)
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/platform"
+	"bt-shieldml/pkg/logging"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// exclusionGlob 一条 glob 排除规则，按 / 拆分为路径段
+type exclusionGlob struct {
+	pattern  string
+	segments []string
+	anchored bool // 绝对路径的 glob 从根目录开始匹配，相对的 glob 可匹配任意层级下的路径 (如 vendor/** 匹配 /www/site/vendor/a.php)
+}
+
+// exclusionMatcher 排除规则集合，精确路径与 glob 的键均经 platform.NormalizePath 规范化
+type exclusionMatcher struct {
+	exact map[string]bool
+	globs []exclusionGlob
+}
+
+// exclusions 配置中的 exclusions 与任务 (-exclude) 的排除规则合并
+func (e *Engine) exclusions(task *Task) []string {
+	return append(append([]string(nil), e.config.Exclusions...), task.Exclusions...)
+}
+
+/**
+ * @Description: 编译排除规则。精确路径转为绝对路径 (与原 -exclude 行为一致)；glob 中 ** 匹配零或多层目录，
+ * 因此 vendor/** 同时匹配 vendor 目录本身，遍历时整个目录被跳过。无效的 glob 记录警告后忽略
+ * @author: Mr wpl
+ * @param exclusions []string: 排除规则，如 /www/site/cache、vendor/**、*.min.php、tests/fixtures/**
+ * @return *exclusionMatcher: 排除规则集合
+ */
+func newExclusionMatcher(exclusions []string) *exclusionMatcher {
+	m := &exclusionMatcher{exact: make(map[string]bool)}
+	for _, ex := range exclusions {
+		ex = strings.TrimSpace(ex)
+		if ex == "" {
+			continue
+		}
+		if !strings.ContainsAny(ex, "*?[") {
+			// Clean and normalize the exclusion path
+			absEx, err := filepath.Abs(filepath.FromSlash(ex))
+			if err != nil {
+				logging.WarnLogger.Printf("Could not get absolute path for exclusion '%s': %v", ex, err)
+				absEx = ex
+			}
+			m.exact[platform.NormalizePath(filepath.Clean(absEx))] = true
+			continue
+		}
+
+		pattern := platform.NormalizePath(ex)
+		anchored := filepath.IsAbs(ex)
+		if !anchored {
+			pattern = strings.TrimPrefix(pattern, "./")
+		}
+		segments := splitPathSegments(pattern)
+		valid := true
+		for _, seg := range segments {
+			if _, err := path.Match(seg, ""); err != nil {
+				logging.WarnLogger.Printf("Ignoring invalid exclusion pattern '%s': %v", ex, err)
+				valid = false
+				break
+			}
+		}
+		if valid && len(segments) > 0 {
+			m.globs = append(m.globs, exclusionGlob{pattern: ex, segments: segments, anchored: anchored})
+		}
+	}
+	return m
+}
+
+/**
+ * @Description: 判断路径是否被排除
+ * @author: Mr wpl
+ * @param p string: 清理后的绝对路径
+ * @return bool: 是否被排除
+ */
+func (m *exclusionMatcher) Match(p string) bool {
+	p = platform.NormalizePath(p)
+	if m.exact[p] {
+		return true
+	}
+	if len(m.globs) == 0 {
+		return false
+	}
+	segments := splitPathSegments(p)
+	for _, g := range m.globs {
+		if g.anchored {
+			if matchSegments(g.segments, segments) {
+				return true
+			}
+			continue
+		}
+		for i := range segments {
+			if matchSegments(g.segments, segments[i:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitPathSegments 按 / 拆分路径，忽略开头、结尾与重复的 /
+func splitPathSegments(p string) []string {
+	var segments []string
+	for _, seg := range strings.Split(p, "/") {
+		if seg != "" && seg != "." {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+// matchSegments 逐段匹配 glob，** 匹配零或多段，其余段使用 path.Match
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,147 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 排除规则测试：**、*.ext、? 与 [] glob，目录规则，以及不含通配符的条目与原 -exclude 精确路径行为兼容
+ */
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestExclusionMatcherMatch(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		// ** 匹配零或多层目录，相对的 glob 可从任意层级开始匹配
+		{"** matches the directory itself", "vendor/**", "/www/site/vendor", true},
+		{"** matches a direct child", "vendor/**", "/www/site/vendor/autoload.php", true},
+		{"** matches nested files", "vendor/**", "/www/site/vendor/a/b/c.php", true},
+		{"** requires the whole segment", "vendor/**", "/www/site/vendors/a.php", false},
+		{"** does not match a file named like the directory", "vendor/**", "/www/site/vendor.php", false},
+		{"nested directory glob", "tests/fixtures/**", "/www/site/tests/fixtures/shell.php", true},
+		{"nested directory glob needs both segments", "tests/fixtures/**", "/www/site/fixtures/shell.php", false},
+		{"nested directory glob sibling", "tests/fixtures/**", "/www/site/tests/unit/shell.php", false},
+		{"leading **", "**/cache/*.php", "/www/site/a/b/cache/x.php", true},
+		{"** in the middle matches zero directories", "/www/**/cache/**", "/www/cache/x.php", true},
+		{"anchored ** in the middle", "/www/**/cache/**", "/www/site/a/cache/x.php", true},
+		{"anchored glob outside its root", "/www/**/cache/**", "/srv/www/cache/x.php", false},
+		{"./ prefix is ignored", "./vendor/**", "/www/site/vendor/a.php", true},
+
+		// *.ext 只匹配最后一段中的文件名
+		{"*.ext matches at any depth", "*.min.php", "/www/site/js/app.min.php", true},
+		{"*.ext matches in the root", "*.min.php", "/app.min.php", true},
+		{"*.ext does not match other extensions", "*.min.php", "/www/site/app.php", false},
+		{"*.ext needs the dot", "*.min.php", "/www/site/min.php", false},
+		{"* does not cross directories", "/www/*.php", "/www/site/index.php", false},
+		{"anchored *", "/www/*.php", "/www/index.php", true},
+
+		// ? 与 [] 字符类
+		{"? matches one character", "shell?.php", "/www/shell1.php", true},
+		{"? does not match two characters", "shell?.php", "/www/shell12.php", false},
+		{"character class", "backup[0-9].php", "/www/backup7.php", true},
+		{"character class mismatch", "backup[0-9].php", "/www/backupx.php", false},
+
+		// 目录规则：只匹配目录本身，遍历时整个目录被跳过
+		{"directory glob matches the directory", "*/cache", "/www/site/cache", true},
+		{"directory glob does not match children", "*/cache", "/www/site/cache/a.php", false},
+
+		// 不含通配符的条目按绝对路径精确匹配 (原 -exclude 行为)
+		{"exact absolute path", "/www/site/cache", "/www/site/cache", true},
+		{"exact path is not a string prefix", "/www/site/cache", "/www/site/cache2", false},
+		{"exact path does not match children", "/www/site/cache", "/www/site/cache/a.php", false},
+		{"exact path is cleaned", "/www/site/../site/cache/", "/www/site/cache", true},
+		{"exact relative path is made absolute", "data/tmp", filepath.Join(cwd, "data", "tmp"), true},
+		{"exact relative path is not matched elsewhere", "data/tmp", "/www/data/tmp", false},
+		{"surrounding whitespace is trimmed", "  /www/site/cache  ", "/www/site/cache", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newExclusionMatcher([]string{tt.pattern})
+			if got := m.Match(filepath.FromSlash(tt.path)); got != tt.want {
+				t.Errorf("newExclusionMatcher(%q).Match(%q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExclusionMatcherInvalid(t *testing.T) {
+	m := newExclusionMatcher([]string{"", "   ", "[bad", "ok/**"})
+	if len(m.exact) != 0 {
+		t.Errorf("exact = %v, want empty entries ignored", m.exact)
+	}
+	if len(m.globs) != 1 || m.globs[0].pattern != "ok/**" {
+		t.Errorf("globs = %+v, want only ok/** (invalid pattern ignored)", m.globs)
+	}
+	if m.Match("/www/[bad") {
+		t.Error("invalid pattern matched a path")
+	}
+	if newExclusionMatcher(nil).Match("/www/index.php") {
+		t.Error("empty matcher matched a path")
+	}
+}
+
+// TestFindFilesExclusions 遍历时目录规则跳过整个目录，精确路径与 glob 可混用
+func TestFindFilesExclusions(t *testing.T) {
+	root := t.TempDir()
+	allFiles := []string{
+		"app.min.php", "cache/page.php", "cache2/page.php", "index.php", "lib/cache/entry.php", "lib/helper.php",
+		"lib/legacy.php", "shell1.php", "tests/fixtures/shell.php", "tests/unit/TestCase.php", "vendor/autoload.php", "vendor/pkg/src/Client.php",
+	}
+	for _, name := range allFiles {
+		writeTestFile(t, filepath.Join(root, filepath.FromSlash(name)), "<?php")
+	}
+
+	tests := []struct {
+		name       string
+		exclusions []string
+		want       []string
+	}{
+		{"no exclusions", nil, allFiles},
+		{"exact directory and file paths", []string{filepath.Join(root, "cache"), filepath.Join(root, "lib", "legacy.php")}, []string{
+			"app.min.php", "cache2/page.php", "index.php", "lib/cache/entry.php", "lib/helper.php",
+			"shell1.php", "tests/fixtures/shell.php", "tests/unit/TestCase.php", "vendor/autoload.php", "vendor/pkg/src/Client.php",
+		}},
+		{"globs", []string{"vendor/**", "*.min.php", "tests/fixtures/**", "shell?.php"}, []string{
+			"cache/page.php", "cache2/page.php", "index.php", "lib/cache/entry.php", "lib/helper.php", "lib/legacy.php", "tests/unit/TestCase.php",
+		}},
+		// 不含通配符的条目是相对当前目录的精确路径，不会匹配扫描目录下的 cache
+		{"plain name is relative to the working directory", []string{"cache"}, allFiles},
+		{"unanchored directory glob", []string{"*/cache"}, []string{
+			"app.min.php", "cache2/page.php", "index.php", "lib/helper.php",
+			"lib/legacy.php", "shell1.php", "tests/fixtures/shell.php", "tests/unit/TestCase.php", "vendor/autoload.php", "vendor/pkg/src/Client.php",
+		}},
+		{"excluded scan root", []string{root}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := findFiles([]string{root}, tt.exclusions, []string{".php"}, false, false, nil)
+			if err != nil {
+				t.Fatalf("findFiles() error = %v", err)
+			}
+			var got []string
+			for _, f := range files {
+				rel, err := filepath.Rel(root, f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findFiles() with exclusions %q =\n%q\nwant\n%q", tt.exclusions, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * @Date: 2025-06-13 15:20:44
+ * @Editors: Mr wpl
+ * @Description: 文件读取抽象，scanFile 通过 FileReader 获取文件信息和内容，测试时可注入内存中的文件
+ */
+package engine
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// FileReader scanFile 使用的文件读取接口
+type FileReader interface {
+	Stat(path string) (os.FileInfo, error)
+	ReadAll(path string) ([]byte, error)
+	// Open 以流的方式读取，用于超过 segmented_scan_threshold 的大文件分段扫描
+	Open(path string) (io.ReadCloser, error)
+}
+
+// osFileReader 默认实现，直接读取文件系统
+type osFileReader struct{}
+
+// Stat 获取文件信息
+func (osFileReader) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// ReadAll 读取文件全部内容
+func (osFileReader) ReadAll(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+// Open 打开文件
+func (osFileReader) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+/**
+ * @Description: 设置引擎读取文件的方式，为 nil 时恢复为直接读取文件系统。需在 Scan/ScanFile 前调用
+ * @author: Mr wpl
+ * @param r FileReader: 文件读取实现
+ */
+func (e *Engine) SetFileReader(r FileReader) {
+	if r == nil {
+		r = osFileReader{}
+	}
+	e.fileReader = r
+}
@@ -0,0 +1,328 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: FileReader 测试，MockFileReader 以内存中的文件代替临时文件
+ */
+package engine
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/**
+ * @Description: 内存中的文件读取实现，用于测试：Files 提供预置内容，StatErrors/ReadErrors 注入错误，
+ * OnRead 可按读取次数返回不同内容 (如 Stat 与 ReadAll 之间文件被修改、第二次读取失败)
+ * @author: Mr wpl
+ */
+type MockFileReader struct {
+	Files      map[string][]byte
+	StatErrors map[string]error
+	ReadErrors map[string]error
+	// OnRead 不为 nil 时代替 Files 返回内容，call 为该路径的第几次读取 (从 1 开始)
+	OnRead func(path string, call int) ([]byte, error)
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+/**
+ * @Description: 创建内存文件读取实现
+ * @author: Mr wpl
+ * @param files map[string][]byte: 路径 → 内容
+ * @return *MockFileReader: 文件读取实现
+ */
+func NewMockFileReader(files map[string][]byte) *MockFileReader {
+	return &MockFileReader{Files: files}
+}
+
+// Stat 返回预置内容的文件信息，ModTime 为当前时间
+func (m *MockFileReader) Stat(path string) (os.FileInfo, error) {
+	if err := m.StatErrors[path]; err != nil {
+		return nil, err
+	}
+	content, ok := m.Files[path]
+	if !ok {
+		return nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+	}
+	return mockFileInfo{name: filepath.Base(path), size: int64(len(content)), modTime: time.Now()}, nil
+}
+
+// ReadAll 返回预置内容，记录读取次数
+func (m *MockFileReader) ReadAll(path string) ([]byte, error) {
+	m.mu.Lock()
+	if m.reads == nil {
+		m.reads = make(map[string]int)
+	}
+	m.reads[path]++
+	call := m.reads[path]
+	m.mu.Unlock()
+
+	if m.OnRead != nil {
+		return m.OnRead(path, call)
+	}
+	if err := m.ReadErrors[path]; err != nil {
+		return nil, err
+	}
+	content, ok := m.Files[path]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return append([]byte(nil), content...), nil
+}
+
+// Open 以 ReadAll 的结果构造流，同样计入读取次数
+func (m *MockFileReader) Open(path string) (io.ReadCloser, error) {
+	content, err := m.ReadAll(path)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+/**
+ * @Description: 返回某个路径被读取的次数
+ * @author: Mr wpl
+ * @param path string: 文件路径
+ * @return int: 读取次数
+ */
+func (m *MockFileReader) Reads(path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reads[path]
+}
+
+// mockFileInfo MockFileReader 返回的文件信息
+type mockFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (fi mockFileInfo) Name() string       { return fi.name }
+func (fi mockFileInfo) Size() int64        { return fi.size }
+func (fi mockFileInfo) Mode() os.FileMode  { return 0644 }
+func (fi mockFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi mockFileInfo) IsDir() bool        { return false }
+func (fi mockFileInfo) Sys() interface{}   { return nil }
+
+func TestScanFileWithMockFileReader(t *testing.T) {
+	const clean = "<?php echo 'hello';"
+	const shell = "<?php eval($_POST['cmd']);"
+	errDisk := errors.New("input/output error")
+
+	tests := []struct {
+		name      string
+		reader    *MockFileReader
+		wantErr   string // 结果 Error 中应包含的内容，空表示无错误
+		wantFinds int    // regex 模拟分析器的发现数
+		wantSize  int64
+		wantReads int
+	}{
+		{
+			name:      "clean file",
+			reader:    NewMockFileReader(map[string][]byte{"/www/a.php": []byte(clean)}),
+			wantSize:  int64(len(clean)),
+			wantReads: 1,
+		},
+		{
+			name:      "malicious file",
+			reader:    NewMockFileReader(map[string][]byte{"/www/a.php": []byte(shell)}),
+			wantFinds: 1,
+			wantSize:  int64(len(shell)),
+			wantReads: 1,
+		},
+		{
+			name:    "missing file",
+			reader:  NewMockFileReader(map[string][]byte{}),
+			wantErr: "stat error",
+		},
+		{
+			name: "stat error",
+			reader: &MockFileReader{
+				Files:      map[string][]byte{"/www/a.php": []byte(clean)},
+				StatErrors: map[string]error{"/www/a.php": os.ErrPermission},
+			},
+			wantErr: "stat error",
+		},
+		{
+			name: "read error",
+			reader: &MockFileReader{
+				Files:      map[string][]byte{"/www/a.php": []byte(clean)},
+				ReadErrors: map[string]error{"/www/a.php": errDisk},
+			},
+			wantErr:   "read error: input/output error",
+			wantSize:  int64(len(clean)),
+			wantReads: 1,
+		},
+		{
+			// Stat 之后文件被替换为 webshell：分析的是实际读到的内容，大小仍来自 Stat
+			name: "changed between Stat and ReadAll",
+			reader: &MockFileReader{
+				Files: map[string][]byte{"/www/a.php": []byte(clean)},
+				OnRead: func(path string, call int) ([]byte, error) {
+					return []byte(shell), nil
+				},
+			},
+			wantFinds: 1,
+			wantSize:  int64(len(clean)),
+			wantReads: 1,
+		},
+		{
+			// Stat 之后文件被截断为空：没有可分析的内容，结果无发现
+			name: "truncated between Stat and ReadAll",
+			reader: &MockFileReader{
+				Files: map[string][]byte{"/www/a.php": []byte(shell)},
+				OnRead: func(path string, call int) ([]byte, error) {
+					return []byte{}, nil
+				},
+			},
+			wantSize:  int64(len(shell)),
+			wantReads: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t, &mockAnalyzer{name: "regex", match: "eval(", risk: types.RiskCritical})
+			e.SetFileReader(tt.reader)
+
+			before := time.Now()
+			res := e.ScanFile("/www/a.php")
+			if tt.wantErr != "" {
+				if res.Error == nil || !strings.Contains(res.Error.Error(), tt.wantErr) {
+					t.Fatalf("Error = %v, want it to contain %q", res.Error, tt.wantErr)
+				}
+			} else if res.Error != nil {
+				t.Fatalf("Error = %v", res.Error)
+			}
+			if len(res.Findings) != tt.wantFinds {
+				t.Errorf("Findings = %v, want %d", res.Findings, tt.wantFinds)
+			}
+			if res.File.Size != tt.wantSize {
+				t.Errorf("File.Size = %d, want %d", res.File.Size, tt.wantSize)
+			}
+			if tt.wantSize > 0 && res.File.ModTime.Before(before) {
+				t.Errorf("File.ModTime = %v, want the mock's time.Now()", res.File.ModTime)
+			}
+			if got := tt.reader.Reads("/www/a.php"); got != tt.wantReads {
+				t.Errorf("Reads() = %d, want %d", got, tt.wantReads)
+			}
+		})
+	}
+}
+
+// TestScanFileReadErrorOnSecondCall 同一路径第二次扫描时读取失败 (如文件在两次扫描之间被删除)
+func TestScanFileReadErrorOnSecondCall(t *testing.T) {
+	reader := &MockFileReader{
+		Files: map[string][]byte{"/www/a.php": []byte("<?php eval($_POST['cmd']);")},
+		OnRead: func(path string, call int) ([]byte, error) {
+			if call > 1 {
+				return nil, os.ErrNotExist
+			}
+			return []byte("<?php eval($_POST['cmd']);"), nil
+		},
+	}
+	e := newTestEngine(t, &mockAnalyzer{name: "regex", match: "eval(", risk: types.RiskCritical})
+	e.SetFileReader(reader)
+
+	first := e.ScanFile("/www/a.php")
+	if first.Error != nil || len(first.Findings) != 1 {
+		t.Fatalf("first scan: Error = %v, Findings = %v, want no error and one finding", first.Error, first.Findings)
+	}
+	second := e.ScanFile("/www/a.php")
+	if !errors.Is(second.Error, os.ErrNotExist) {
+		t.Errorf("second scan: Error = %v, want a read error wrapping os.ErrNotExist", second.Error)
+	}
+	if len(second.Findings) != 0 {
+		t.Errorf("second scan kept findings from the first: %v", second.Findings)
+	}
+}
+
+// TestContentHashDeduplicatorUsesFileReader 内容去重通过 FileReader 读取文件，不直接访问文件系统
+func TestContentHashDeduplicatorUsesFileReader(t *testing.T) {
+	content := []byte("<?php /* shared plugin */ echo 'x';")
+	reader := NewMockFileReader(map[string][]byte{
+		"/site1/plugin.php": content,
+		"/site2/plugin.php": content,
+		"/site3/other.php":  []byte("<?php echo 'other';"),
+	})
+	d := NewContentHashDeduplicator(reader)
+
+	first := d.Check("/site1/plugin.php")
+	second := d.Check("/site2/plugin.php")
+	other := d.Check("/site3/other.php")
+	if first == nil || second == nil || other == nil {
+		t.Fatalf("Check() = %v, %v, %v, want an entry for every readable file", first, second, other)
+	}
+	if owner := first.Resolve(); owner != nil {
+		t.Errorf("Resolve(site1) = %v, want nil for the first copy", owner)
+	}
+	if owner := second.Resolve(); owner != first {
+		t.Errorf("Resolve(site2) = %v, want the site1 entry", owner)
+	}
+	if owner := other.Resolve(); owner != nil {
+		t.Errorf("Resolve(site3) = %v, want nil", owner)
+	}
+	if d.Duplicates() != 1 {
+		t.Errorf("Duplicates() = %d, want 1", d.Duplicates())
+	}
+	if reader.Reads("/site1/plugin.php") == 0 || reader.Reads("/site2/plugin.php") == 0 {
+		t.Error("deduplicator did not read files through the FileReader")
+	}
+	if entry := d.Check(filepath.Join(t.TempDir(), "missing.php")); entry != nil {
+		t.Error("Check() of a file missing from the FileReader returned an entry")
+	}
+}
+
+// TestContentHashDeduplicatorSharedHeader 头部相同的文件在 Resolve (扫描协程) 中才读取完整内容，
+// 内容相同时复用最先分发的文件
+func TestContentHashDeduplicatorSharedHeader(t *testing.T) {
+	licence := bytes.Repeat([]byte("/* GPL-2.0 licence header */\n"), 30)
+	pluginA := append(append([]byte(nil), licence...), "<?php echo 'a';"...)
+	pluginB := append(append([]byte(nil), licence...), "<?php echo 'b';"...)
+	reader := NewMockFileReader(map[string][]byte{
+		"/www/a.php":      pluginA,
+		"/www/b.php":      pluginB,
+		"/www/copy/a.php": pluginA,
+		"/www/copy/b.php": pluginB,
+	})
+	d := NewContentHashDeduplicator(reader)
+
+	paths := []string{"/www/a.php", "/www/b.php", "/www/copy/b.php", "/www/copy/a.php"}
+	entries := make([]*dedupEntry, len(paths))
+	for i, path := range paths {
+		entries[i] = d.Check(path)
+	}
+	// 分发时只读取头部
+	for _, path := range paths {
+		if n := reader.Reads(path); n != 1 {
+			t.Errorf("%s read %d times during Check(), want only the header", path, n)
+		}
+	}
+
+	// 按与分发相反的顺序解析，结果不受扫描协程执行顺序影响
+	want := []*dedupEntry{nil, nil, entries[1], entries[0]}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if got := entries[i].Resolve(); got != want[i] {
+			t.Errorf("Resolve(%s) = %v, want %v", paths[i], got, want[i])
+		}
+	}
+	if d.Duplicates() != 2 {
+		t.Errorf("Duplicates() = %d, want 2", d.Duplicates())
+	}
+	// 每个文件的完整哈希只计算一次
+	for _, path := range paths {
+		if n := reader.Reads(path); n != 2 {
+			t.Errorf("%s read %d times, want the header and one full hash", path, n)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+/*
+ * @Date: 2025-06-20 10:31:12
+ * @Editors: Mr wpl
+ * @Description: Unix 下读取文件的设备号与 inode 编号，用于跟随符号链接遍历时的成环保护
+ */
+package engine
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileIDOf 返回文件信息对应的 (st_dev, st_ino)
+func fileIDOf(info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
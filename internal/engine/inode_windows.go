@@ -0,0 +1,15 @@
+//go:build windows
+
+/*
+ * @Date: 2025-06-20 10:31:12
+ * @Editors: Mr wpl
+ * @Description: Windows 的 FileInfo 不含 inode 编号，成环保护改用解析链接后的真实路径
+ */
+package engine
+
+import "os"
+
+// fileIDOf Windows 上无法取得 inode，总是返回 false
+func fileIDOf(info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -3,6 +3,8 @@ package engine
 import (
 	"bt-shieldml/internal/features"
 	"bt-shieldml/pkg/types"
+	"crypto/sha256"
+	"os"
 )
 
 // Analyzer defines the interface for all detection methods.
@@ -10,6 +12,19 @@ type Analyzer interface {
 	Name() string                                                                                             // Returns the unique name of the analyzer
 	Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) // Pass content directly
 	RequiredFeatures() []string                                                                               // List feature keys this analyzer needs (e.g., ["statistical", "ast_op_sequence"])
+	SupportedExtensions() []string                                                                            // File extensions (lowercase, with dot) the analyzer applies to; nil means all files
+}
+
+// Reloader is implemented by analyzers that can reload their rules or models at runtime (e.g. on SIGHUP).
+// Reload must be atomic: on failure the analyzer keeps its previous state and keeps scanning with it.
+type Reloader interface {
+	Reload() error
+}
+
+// DigestMatcher is implemented by analyzers that only need the SHA-256 of the original content (e.g. hash).
+// Segmented scans of large files pass the digest computed while streaming instead of the whole content.
+type DigestMatcher interface {
+	MatchDigest(fileInfo types.FileInfo, digest [sha256.Size]byte, featureSet *features.FeatureSet) *types.Finding
 }
 
 // Reporter defines the interface for generating output reports.
@@ -22,3 +37,8 @@ type ASTManager interface {
 	GetAST(source []byte) (astData []byte, err error) // Returns raw AST data (e.g., JSON)
 	Cleanup() error                                   // Cleans up any resources (e.g., PHP process)
 }
+
+// TimeFilter decides during the directory walk whether a file is recent enough to scan.
+type TimeFilter interface {
+	Include(fi os.FileInfo) bool // Returns false to skip the file
+}
@@ -0,0 +1,247 @@
+/*
+ * @Date: 2025-06-17 09:45:13
+ * @Editors: Mr wpl
+ * @Description: 多引擎集成扫描：以不同配置 (如全部分析器的 paranoid 与仅 regex+yara 的 baseline) 扫描同一批文件并合并结果
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// 合并策略
+const (
+	ConsensusMax         = "max"          // 取各引擎中最高的风险
+	ConsensusMin         = "min"          // 取各引擎中最低的风险
+	ConsensusAnyCritical = "any_critical" // 任一引擎判为 Critical 即为 Critical，否则取最低的风险
+)
+
+// EngineConfig 一个命名的引擎配置
+type EngineConfig struct {
+	Name   string
+	Config *types.Config
+}
+
+// namedEngine 已初始化的命名引擎
+type namedEngine struct {
+	name   string
+	engine *Engine
+}
+
+/**
+ * @Description: 多引擎扫描器。各引擎共享同一个 PHP 桥接，同一文件依次交给每个引擎扫描
+ * @author: Mr wpl
+ */
+type MultiScanEngine struct {
+	engines []namedEngine
+	// ConsensusStrategy 合并策略：max (默认)、min 或 any_critical
+	ConsensusStrategy string
+	concurrency       int
+	extensions        []string // 各引擎 scan_extensions 的并集
+}
+
+/**
+ * @Description: 创建多引擎扫描器。首个需要 AST 的引擎启动 PHP 桥接，其余引擎复用
+ * (共享桥接使用首个引擎的 bridge_transport 与 ast_compression 设置)
+ * @author: Mr wpl
+ * @param configs []EngineConfig: 引擎配置，名称不能重复
+ * @return *MultiScanEngine: 多引擎扫描器
+ * @return error: 错误
+ */
+func NewMultiScanEngine(configs []EngineConfig) (*MultiScanEngine, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("multi-engine scan requires at least one engine config")
+	}
+
+	m := &MultiScanEngine{ConsensusStrategy: ConsensusMax}
+	seen := make(map[string]bool, len(configs))
+	var shared ast.ASTManager
+	for _, c := range configs {
+		if c.Name == "" || seen[c.Name] {
+			m.Close()
+			return nil, fmt.Errorf("engine name %q is empty or duplicated", c.Name)
+		}
+		seen[c.Name] = true
+
+		e, err := newEngine(c.Config, shared)
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("failed to initialize engine %q: %w", c.Name, err)
+		}
+		if shared == nil && e.astManager != nil {
+			shared = e.astManager
+		}
+		if c.Config.Performance.Concurrency > m.concurrency {
+			m.concurrency = c.Config.Performance.Concurrency
+		}
+		for _, ext := range c.Config.Extensions() {
+			if !containsString(m.extensions, ext) {
+				m.extensions = append(m.extensions, ext)
+			}
+		}
+		m.engines = append(m.engines, namedEngine{name: c.Name, engine: e})
+	}
+	if m.concurrency <= 0 {
+		m.concurrency = 4
+	}
+	return m, nil
+}
+
+/**
+ * @Description: 扫描任务中的文件，返回每个文件各引擎的结果及合并风险，按文件顺序排列。
+ * 不生成报告；任务中的 Paths、Exclusions、FollowSymlinks、ScanArchives、TimeFilter 生效
+ * @author: Mr wpl
+ * @param task *Task: 任务
+ * @return []*types.ComparisonResult: 比较结果
+ * @return error: 错误
+ */
+func (m *MultiScanEngine) Scan(task *Task) ([]*types.ComparisonResult, error) {
+	switch m.ConsensusStrategy {
+	case ConsensusMax, ConsensusMin, ConsensusAnyCritical:
+	default:
+		return nil, fmt.Errorf("unknown consensus strategy %q (expected max, min or any_critical)", m.ConsensusStrategy)
+	}
+
+	scanCtx := logging.WithContext(context.Background(), logging.NewScanID(), "")
+	logging.InfoCtx(scanCtx, "Multi-engine scan started for %v with %d engines", task.Paths, len(m.engines))
+
+	// 各引擎配置中的 exclusions 与任务的排除规则合并
+	exclusions := append([]string(nil), task.Exclusions...)
+	for _, ne := range m.engines {
+		exclusions = append(exclusions, ne.engine.config.Exclusions...)
+	}
+	files, err := findFiles(task.Paths, exclusions, m.extensions, task.FollowSymlinks, task.ScanArchives, task.TimeFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error finding files to scan: %w", err)
+	}
+	var virtualPaths map[string]string
+	if task.ScanArchives {
+		archiveDir, tmpErr := os.MkdirTemp("", "shieldml_archives_")
+		if tmpErr != nil {
+			return nil, fmt.Errorf("failed to create archive unpack directory: %w", tmpErr)
+		}
+		defer os.RemoveAll(archiveDir)
+		files, virtualPaths = expandArchives(files, archiveDir, m.extensions, m.engines[0].engine.config.ArchiveSizeLimit())
+	}
+
+	comparisons := make([]*types.ComparisonResult, len(files))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, m.concurrency)
+	for i, filePath := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, fp string) {
+			defer wg.Done()
+			work := &fileWork{}
+			defer work.release(func() { <-sem })
+
+			displayPath := fp
+			if virtualPath, ok := virtualPaths[fp]; ok {
+				displayPath = virtualPath
+			}
+			cmp := &types.ComparisonResult{
+				Path:    displayPath,
+				Results: make(map[string]*types.ScanResult, len(m.engines)),
+			}
+			for _, ne := range m.engines {
+				result := ne.engine.scanFile(withFileWork(scanCtx, work), fp, ne.engine.astManager)
+				result.File.Path = displayPath
+				cmp.Results[ne.name] = result
+			}
+			cmp.Consensus = m.consensus(cmp.Results)
+			comparisons[i] = cmp
+		}(i, filePath)
+	}
+	wg.Wait()
+
+	logging.InfoCtx(scanCtx, "Multi-engine scan finished: %d files", len(comparisons))
+	return comparisons, nil
+}
+
+/**
+ * @Description: 按合并策略计算风险级别，扫描出错的结果不参与合并，全部出错时为 Unknown
+ * @author: Mr wpl
+ * @param results map[string]*types.ScanResult: 各引擎的结果
+ * @return types.RiskLevel: 合并后的风险级别
+ */
+func (m *MultiScanEngine) consensus(results map[string]*types.ScanResult) types.RiskLevel {
+	var risks []types.RiskLevel
+	for _, res := range results {
+		if res.Error == nil {
+			risks = append(risks, res.OverallRisk)
+		}
+	}
+	if len(risks) == 0 {
+		return types.RiskUnknown
+	}
+
+	lowest, highest := risks[0], risks[0]
+	for _, r := range risks[1:] {
+		if r < lowest {
+			lowest = r
+		}
+		if r > highest {
+			highest = r
+		}
+	}
+	switch m.ConsensusStrategy {
+	case ConsensusMin:
+		return lowest
+	case ConsensusAnyCritical:
+		if highest >= types.RiskCritical {
+			return types.RiskCritical
+		}
+		return lowest
+	}
+	return highest
+}
+
+/**
+ * @Description: 返回引擎名称，按创建顺序
+ * @author: Mr wpl
+ * @return []string: 引擎名称
+ */
+func (m *MultiScanEngine) EngineNames() []string {
+	names := make([]string, 0, len(m.engines))
+	for _, ne := range m.engines {
+		names = append(names, ne.name)
+	}
+	return names
+}
+
+/**
+ * @Description: 释放资源，共享的 PHP 桥接只清理一次
+ * @author: Mr wpl
+ * @return error: 第一个清理错误
+ */
+func (m *MultiScanEngine) Close() error {
+	var firstErr error
+	cleaned := make(map[ast.ASTManager]bool)
+	for _, ne := range m.engines {
+		mgr := ne.engine.astManager
+		if mgr == nil || cleaned[mgr] {
+			continue
+		}
+		cleaned[mgr] = true
+		if err := mgr.Cleanup(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// containsString 切片中是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
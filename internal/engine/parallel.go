@@ -0,0 +1,66 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 单个文件内的分析器并发执行：只依赖文件内容与统计特征的分析器 (regex、yara、hash 等) 之间没有数据依赖
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"context"
+	"sync"
+)
+
+// isContentOnlyAnalyzer 分析器只需要文件内容 (RequiredFeatures 为空) 或统计特征，可与同类分析器并发执行
+func isContentOnlyAnalyzer(analyzer Analyzer) bool {
+	for _, feature := range analyzer.RequiredFeatures() {
+		if feature != "statistical" {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * @Description: 并发运行一组相互独立的分析器 (每个分析器一个 goroutine，仍受 analyzer_timeout 限制)，
+ * 返回的发现按 names 的顺序排列，与顺序执行时一致。每个分析器获得特征集的深拷贝
+ * @author: Mr wpl
+ * @param ctx context.Context: 日志与超时上下文
+ * @param names []string: 分析器名称 (已按优先级排序)
+ * @param fileInfo types.FileInfo: 文件信息
+ * @param content []byte: 文件内容
+ * @param featureSet *features.FeatureSet: 特征集
+ * @return []*types.Finding: 发现
+ */
+func (e *Engine) runAnalyzersParallel(ctx context.Context, names []string, fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) []*types.Finding {
+	results := make([]*types.Finding, len(names)) // 每个 goroutine 只写自己的位置
+	var wg sync.WaitGroup
+	for i, name := range names {
+		analyzer := e.analyzers[name]
+		if !e.canRunAnalyzer(analyzer, featureSet) {
+			logging.InfoCtx(ctx, "Skipping analyzer '%s' for %s: missing required features.", name, fileInfo.Path)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, name string, analyzer Analyzer, featureSet *features.FeatureSet) {
+			defer wg.Done()
+			finding, err := e.runAnalyzer(ctx, name, analyzer, fileInfo, content, featureSet)
+			if err != nil {
+				logger := logging.WithCtx(ctx)
+				logger.Warn().Str(logging.FieldAnalyzer, name).Err(err).Msgf("Analyzer '%s' failed on %s", name, fileInfo.Path)
+			}
+			results[i] = finding
+		}(i, name, analyzer, featureSet.Clone()) // 每个分析器持有自己的副本，修改特征集不影响其他分析器
+	}
+	wg.Wait()
+
+	findings := make([]*types.Finding, 0, len(names))
+	for _, finding := range results {
+		if finding != nil {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
@@ -0,0 +1,85 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 分析器执行基准：100 个文件逐个扫描时，比较单个文件内顺序执行与并发执行 (performance.parallel_analyzers) 分析器的耗时
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// costlyAnalyzer 在 mockAnalyzer 的判定前对内容做 rounds 轮 SHA-256，模拟 regex、yara 等分析器的 CPU 开销
+type costlyAnalyzer struct {
+	*mockAnalyzer
+	rounds int
+}
+
+func (c *costlyAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	sum := sha256.Sum256(content)
+	for i := 1; i < c.rounds; i++ {
+		sum = sha256.Sum256(append(sum[:], content...))
+	}
+	return c.mockAnalyzer.Analyze(fileInfo, content, featureSet)
+}
+
+// BenchmarkAnalyzerExecution 分析器为 CPU 密集型，并发的收益取决于可用核数，可用 -cpu 1,4 比较
+func BenchmarkAnalyzerExecution(b *testing.B) {
+	// 100 个约 4KB 的文件，其中 10 个包含 Medium 发现 (不触发短路)
+	dir := b.TempDir()
+	body := strings.Repeat("$total += $row['amount']; echo htmlspecialchars($row['name']);\n", 64)
+	var paths []string
+	for i := 0; i < 100; i++ {
+		content := "<?php\n" + body
+		if i%10 == 0 {
+			content += "base64_decode($payload);\n"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("file%03d.php", i))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	// 三个只依赖内容的分析器可并发执行，一个依赖统计特征的分析器同样属于并发阶段，一个依赖 AST 词的分析器随后顺序执行
+	analyzers := []Analyzer{
+		&costlyAnalyzer{&mockAnalyzer{name: "regex", match: "base64_decode", risk: types.RiskMedium}, 40},
+		&costlyAnalyzer{&mockAnalyzer{name: "yara", match: "eval(", risk: types.RiskHigh}, 40},
+		&costlyAnalyzer{&mockAnalyzer{name: "hash", match: "\x00never", risk: types.RiskCritical}, 10},
+		&costlyAnalyzer{&mockAnalyzer{name: "statistical", match: "\x00never", risk: types.RiskLow, required: []string{"statistical"}}, 20},
+		&costlyAnalyzer{&mockAnalyzer{name: "bayes_words", match: "\x00never", risk: types.RiskMedium, required: []string{"ast_words"}}, 20},
+	}
+
+	// 扫描过程中的 INFO/WARNING 日志会干扰计时
+	logging.InfoLogger.SetOutput(io.Discard)
+	logging.WarnLogger.SetOutput(io.Discard)
+
+	for _, parallel := range []bool{false, true} {
+		name := "sequential"
+		if parallel {
+			name = "parallel"
+		}
+		b.Run(name, func(b *testing.B) {
+			e := newTestEngine(b, analyzers...)
+			e.config.Performance.ParallelAnalyzers = &parallel
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, path := range paths {
+					if res := e.ScanFile(path); res.Error != nil {
+						b.Fatalf("ScanFile(%s) error = %v", path, res.Error)
+					}
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Microseconds())/float64(b.N*len(paths)), "µs/file")
+		})
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 运行时重新加载分析器规则 (如 YARA 签名)，常驻服务收到 SIGHUP 或 POST /api/v1/reload 时调用，无需重启引擎
+ */
+package engine
+
+import (
+	"bt-shieldml/pkg/logging"
+	"fmt"
+)
+
+// ReloadResult 单个分析器的重新加载结果
+type ReloadResult struct {
+	Analyzer string `json:"analyzer"`
+	Error    string `json:"error,omitempty"` // 为空表示成功，失败时分析器继续使用旧规则
+}
+
+/**
+ * @Description: 对实现了 Reloader 的已启用分析器依次调用 Reload，单个分析器失败不影响其他分析器
+ * @author: Mr wpl
+ * @return []ReloadResult: 各分析器的结果，按执行顺序
+ * @return error: 任一分析器失败时返回第一个错误
+ */
+func (e *Engine) ReloadAnalyzers() ([]ReloadResult, error) {
+	names := make([]string, 0, len(e.analyzers))
+	for name := range e.analyzers {
+		names = append(names, name)
+	}
+	sortAnalyzerNames(names)
+
+	results := []ReloadResult{}
+	var firstErr error
+	for _, name := range names {
+		reloader, ok := e.analyzers[name].(Reloader)
+		if !ok {
+			continue
+		}
+		result := ReloadResult{Analyzer: name}
+		if err := reloader.Reload(); err != nil {
+			result.Error = err.Error()
+			logging.ErrorLogger.Printf("Failed to reload analyzer '%s', keeping previous rules: %v", name, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("analyzer '%s' reload failed: %w", name, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, firstErr
+}
@@ -0,0 +1,295 @@
+/*
+ * @Date: 2025-06-18 10:12:46
+ * @Editors: Mr wpl
+ * @Description: 大文件分段读取：按固定大小切分，相邻分段重叠，避免跨分段边界的特征被漏检
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultSegmentSize = 4 * 1024 * 1024 // 默认分段大小
+	DefaultOverlapSize = 64 * 1024       // 默认重叠大小，需大于规则可能匹配的最大长度
+)
+
+// Segment 文件的一个分段
+type Segment struct {
+	Index     int    // 分段序号 (从 0 开始)
+	Offset    int64  // 分段在文件中的起始偏移
+	Data      []byte // 分段内容，开头 OverlapSize 字节与上一分段末尾相同 (首段除外)
+	StartLine int    // 分段首字节所在行 (从 1 开始)
+	EndLine   int    // 分段末字节所在行
+}
+
+/**
+ * @Description: 分段读取器，每个分段最多 SegmentSize 字节，与上一分段重叠 OverlapSize 字节。
+ * 同一时刻只持有一个分段，内存占用与文件大小无关
+ * @author: Mr wpl
+ */
+type SegmentedReader struct {
+	SegmentSize int64
+	OverlapSize int64
+
+	r    io.Reader
+	prev *Segment // 上一个分段，其末尾作为下一分段的开头
+	done bool     // 已读到文件末尾
+}
+
+/**
+ * @Description: 创建分段读取器，大小不合法时使用默认值，重叠不小于分段大小时取分段大小的一半
+ * @author: Mr wpl
+ * @param r io.Reader: 文件内容
+ * @param segmentSize int64: 分段大小
+ * @param overlapSize int64: 重叠大小
+ * @return *SegmentedReader: 分段读取器
+ */
+func NewSegmentedReader(r io.Reader, segmentSize int64, overlapSize int64) *SegmentedReader {
+	if segmentSize <= 0 {
+		segmentSize = DefaultSegmentSize
+	}
+	if overlapSize < 0 {
+		overlapSize = DefaultOverlapSize
+	}
+	if overlapSize >= segmentSize {
+		overlapSize = segmentSize / 2
+	}
+	return &SegmentedReader{SegmentSize: segmentSize, OverlapSize: overlapSize, r: r}
+}
+
+/**
+ * @Description: 读取下一个分段，没有新内容时返回 io.EOF
+ * @author: Mr wpl
+ * @return *Segment: 分段
+ * @return error: 错误
+ */
+func (s *SegmentedReader) Next() (*Segment, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+
+	seg := &Segment{StartLine: 1}
+	data := make([]byte, s.SegmentSize)
+	kept := 0
+	if s.prev != nil {
+		// 保留上一分段末尾的 OverlapSize 字节
+		cut := len(s.prev.Data) - int(s.OverlapSize)
+		if cut < 0 {
+			cut = 0
+		}
+		seg.Index = s.prev.Index + 1
+		seg.Offset = s.prev.Offset + int64(cut)
+		seg.StartLine = s.prev.StartLine + bytes.Count(s.prev.Data[:cut], []byte{'\n'})
+		kept = copy(data, s.prev.Data[cut:])
+	}
+
+	n, err := io.ReadFull(s.r, data[kept:])
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		s.done = true
+	default:
+		return nil, err
+	}
+	if n == 0 {
+		// 上一分段已包含全部内容，或文件为空
+		s.done = true
+		return nil, io.EOF
+	}
+
+	seg.Data = data[:kept+n]
+	seg.EndLine = seg.StartLine + bytes.Count(seg.Data, []byte{'\n'})
+	if seg.Data[len(seg.Data)-1] == '\n' {
+		seg.EndLine-- // 末字节为换行时仍属于该行
+	}
+	s.prev = seg
+	return seg, nil
+}
+
+// segmentAnalyzers 分段扫描时运行的分析器，只依赖原始内容
+var segmentAnalyzers = []string{"regex", "python_regex", "yara"}
+
+// segmentFinding 分段扫描的发现及其所在分段的行范围
+type segmentFinding struct {
+	finding   *types.Finding
+	startLine int
+	endLine   int
+}
+
+/**
+ * @Description: 分段扫描大文件：regex、python_regex 与 yara 逐段运行，统计特征与内容哈希在读取时流式计算，
+ * 读完后按完整内容的 SHA-256 匹配白名单哈希与已知样本 (hash)，跳过 AST 分析与编码转换。各分段的发现按描述与行范围去重
+ * @author: Mr wpl
+ * @param ctx context.Context: 日志上下文
+ * @param result *types.ScanResult: 已填充文件信息的扫描结果
+ * @param start time.Time: 扫描开始时间
+ * @return *types.ScanResult: 扫描结果
+ */
+func (e *Engine) scanSegmented(ctx context.Context, result *types.ScanResult, start time.Time) *types.ScanResult {
+	filePath := result.File.Path
+	f, err := e.fileReader.Open(filePath)
+	if err != nil {
+		result.Error = fmt.Errorf("read error: %w", err)
+		logging.ErrorCtx(ctx, "Error opening file %s: %v", filePath, err)
+		result.Duration = time.Since(start)
+		return result
+	}
+	defer f.Close()
+	logging.InfoCtx(ctx, "File %s is %d bytes (threshold %d), scanning in segments with regex and yara only (hash checks the full digest)",
+		filePath, result.File.Size, e.config.Performance.SegmentThreshold())
+
+	// 统计特征在另一个 goroutine 中从管道读取同一份数据
+	pr, pw := io.Pipe()
+	type statsResult struct {
+		features features.StatisticalFeatures
+		err      error
+	}
+	statsDone := make(chan statsResult, 1)
+	go func() {
+		sf, statErr := features.CalculateStatisticalFeatures(pr, features.FileTypeOf(filePath))
+		pr.CloseWithError(statErr)
+		statsDone <- statsResult{sf, statErr}
+	}()
+	hasher := sha256.New()
+	md5Hasher := md5.New()
+	segments := NewSegmentedReader(io.TeeReader(f, io.MultiWriter(pw, hasher, md5Hasher)), DefaultSegmentSize, DefaultOverlapSize)
+
+	featureSet := &features.FeatureSet{Context: ctx}
+	var merged []*segmentFinding
+	var readErr error
+	count := 0
+	earlyExit := e.config.EarlyExitEnabled()
+	criticalFrom := "" // 报告 Critical 的分析器，非空时后续分段只读取不分析
+	for {
+		seg, nextErr := segments.Next()
+		if nextErr == io.EOF {
+			break
+		}
+		if nextErr != nil {
+			readErr = nextErr
+			break
+		}
+		count++
+		if criticalFrom != "" {
+			continue // 仍需读完剩余数据，统计特征与内容哈希才完整
+		}
+		for _, name := range segmentAnalyzers {
+			analyzer, ok := e.analyzers[name]
+			if !ok || !supportsFile(analyzer, filePath) {
+				continue
+			}
+			finding, analyzeErr := e.runAnalyzer(ctx, name, analyzer, result.File, seg.Data, featureSet)
+			if analyzeErr != nil {
+				logging.WarnCtx(ctx, "Analyzer '%s' failed on segment %d of %s: %v", name, seg.Index, filePath, analyzeErr)
+			}
+			if finding != nil {
+				if finding.LineNumber > 0 {
+					finding.LineNumber += seg.StartLine - 1 // 分段内的行号换算为文件行号
+				}
+				merged = mergeSegmentFinding(merged, finding, seg.StartLine, seg.EndLine)
+				// 与 scanFile 一致：已确认为 Critical 时不再分析剩余分段
+				if earlyExit && finding.Risk >= types.RiskCritical {
+					criticalFrom = name
+					logging.InfoCtx(ctx, "Critical finding from '%s' in segment %d of %s, skipping the remaining segments", name, seg.Index, filePath)
+					break
+				}
+			}
+		}
+	}
+	pw.CloseWithError(readErr)
+	stats := <-statsDone
+	if readErr != nil {
+		result.Error = fmt.Errorf("read error: %w", readErr)
+		logging.ErrorCtx(ctx, "Error reading file %s: %v", filePath, readErr)
+		result.Duration = time.Since(start)
+		return result
+	}
+	if stats.err != nil {
+		logging.WarnCtx(ctx, "Statistical feature calculation failed for %s: %v", filePath, stats.err)
+	} else {
+		featureSet.Statistical = &stats.features
+	}
+	logging.InfoCtx(ctx, "Scanned %d segments of %s", count, filePath)
+
+	findings := make([]*types.Finding, 0, len(merged))
+	for _, sf := range merged {
+		if sf.finding.Metadata == nil {
+			sf.finding.Metadata = make(map[string]interface{})
+		}
+		sf.finding.Metadata["line"] = sf.startLine
+		sf.finding.Metadata["line_range"] = fmt.Sprintf("%d-%d", sf.startLine, sf.endLine)
+		findings = append(findings, sf.finding)
+	}
+
+	result.ContentMD5 = hex.EncodeToString(md5Hasher.Sum(nil))
+	var digest [sha256.Size]byte
+	copy(digest[:], hasher.Sum(nil))
+	result.ContentSHA256 = hex.EncodeToString(digest[:])
+	// 白名单的哈希列表与已知样本库 (hash) 都按完整内容的 SHA-256 匹配，使用读取时计算的摘要
+	if e.whitelist != nil && e.whitelist.MatchesHash(result.ContentSHA256) {
+		return e.whitelisted(ctx, result, start)
+	}
+
+	skipped := make([]string, 0, len(e.analyzers))
+	for name, analyzer := range e.analyzers {
+		if slices.Contains(segmentAnalyzers, name) || !supportsFile(analyzer, filePath) {
+			continue
+		}
+		if matcher, ok := analyzer.(DigestMatcher); ok {
+			if finding := matcher.MatchDigest(result.File, digest, featureSet); finding != nil {
+				findings = append(findings, finding)
+			}
+			continue
+		}
+		skipped = append(skipped, name)
+	}
+	sortAnalyzerNames(skipped)
+	for _, name := range skipped {
+		result.SkippedAnalyzers = append(result.SkippedAnalyzers, fmt.Sprintf("%s: segmented scan of large file", name))
+	}
+	if criticalFrom != "" {
+		result.SkippedAST = true
+		result.SkippedAnalyzers = append(result.SkippedAnalyzers, fmt.Sprintf("remaining segments: critical finding from %s", criticalFrom))
+	}
+
+	return e.finishResult(ctx, result, findings, featureSet, start)
+}
+
+/**
+ * @Description: 合并分段发现：同一分析器、相同描述且行范围相交 (相邻分段因重叠而相交) 时视为同一发现，扩展其行范围
+ * @author: Mr wpl
+ * @param merged []*segmentFinding: 已有发现
+ * @param finding *types.Finding: 新发现
+ * @param startLine int: 所在分段的起始行
+ * @param endLine int: 所在分段的结束行
+ * @return []*segmentFinding: 合并后的发现
+ */
+func mergeSegmentFinding(merged []*segmentFinding, finding *types.Finding, startLine int, endLine int) []*segmentFinding {
+	for _, sf := range merged {
+		if sf.finding.AnalyzerName != finding.AnalyzerName || sf.finding.Description != finding.Description {
+			continue
+		}
+		if startLine <= sf.endLine && sf.startLine <= endLine {
+			if startLine < sf.startLine {
+				sf.startLine = startLine
+			}
+			if endLine > sf.endLine {
+				sf.endLine = endLine
+			}
+			return merged
+		}
+	}
+	return append(merged, &segmentFinding{finding: finding, startLine: startLine, endLine: endLine})
+}
@@ -0,0 +1,77 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 分段扫描测试：大文件按读取时计算的完整 SHA-256 匹配已知样本 (hash) 与白名单哈希
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/analyzers/static"
+	"bt-shieldml/internal/whitelist"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanSegmentedChecksFullHash(t *testing.T) {
+	known := append([]byte("<?php\n"), bytes.Repeat([]byte("$a[] = 'padding';\n"), 200)...)
+	unknown := append([]byte("<?php\n"), bytes.Repeat([]byte("$b[] = 'padding';\n"), 200)...)
+	sum := sha256.Sum256(known)
+	knownSum := hex.EncodeToString(sum[:])
+
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "SampleHash.txt"), []byte(knownSum+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hashAnalyzer, err := static.NewHashAnalyzer(dataDir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newEngine := func() *Engine {
+		e := newTestEngine(t, hashAnalyzer, &mockAnalyzer{name: "regex", match: "eval(", risk: types.RiskHigh})
+		e.config.Performance.SegmentedScanThreshold = 1024
+		e.SetFileReader(NewMockFileReader(map[string][]byte{"/www/known.php": known, "/www/unknown.php": unknown}))
+		return e
+	}
+
+	e := newEngine()
+	res := e.ScanFile("/www/known.php")
+	if res.Error != nil {
+		t.Fatalf("ScanFile() error = %v", res.Error)
+	}
+	if len(res.Findings) != 1 || res.Findings[0].AnalyzerName != "hash" || res.Findings[0].Risk != types.RiskCritical {
+		t.Errorf("findings = %+v, want the Critical hash match", res.Findings)
+	}
+	if res.ContentSHA256 != knownSum {
+		t.Errorf("ContentSHA256 = %s, want %s", res.ContentSHA256, knownSum)
+	}
+	for _, skipped := range res.SkippedAnalyzers {
+		if strings.HasPrefix(skipped, "hash:") {
+			t.Errorf("SkippedAnalyzers = %v, want hash to be consulted", res.SkippedAnalyzers)
+		}
+	}
+
+	if res := e.ScanFile("/www/unknown.php"); len(res.Findings) != 0 {
+		t.Errorf("unknown file findings = %+v, want none", res.Findings)
+	}
+
+	// 白名单哈希列表中的大文件视为 Safe
+	wlPath := filepath.Join(t.TempDir(), "whitelist.txt")
+	writeTestFile(t, wlPath, "sha256:"+strings.ToUpper(knownSum)+"\n")
+	wl, err := whitelist.Load(wlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = newEngine()
+	e.whitelist = wl
+	res = e.ScanFile("/www/known.php")
+	if res.OverallRisk != types.RiskNone || len(res.Findings) != 0 {
+		t.Errorf("whitelisted large file = risk %s, findings %+v, want Safe without findings", res.OverallRisk, res.Findings)
+	}
+}
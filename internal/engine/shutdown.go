@@ -0,0 +1,152 @@
+package engine
+
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 扫描期间收到 SIGTERM 时停止分发新文件，等待进行中的文件完成后输出部分报告
+ */
+
+import (
+	"bt-shieldml/pkg/types"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// GracefulShutdown 跟踪进行中的文件扫描协程，收到 SIGTERM 后最多等待 timeout，
+// 超时仍未完成的文件视为中止，其后到达的结果被丢弃
+type GracefulShutdown struct {
+	timeout      time.Duration
+	signals      chan os.Signal
+	stopping     chan struct{} // 收到信号 (或调用 Trigger) 时关闭
+	closed       chan struct{} // Close 时关闭，结束信号监听协程
+	triggerOnce  sync.Once
+	shuttingDown atomic.Bool
+	wg           sync.WaitGroup
+	mu           sync.Mutex
+	inFlight     map[string]struct{} // 进行中的文件路径，只能在持有 mu 时读写
+	abandoned    bool                // Wait 已超时返回，不再接收结果，只能在持有 mu 时读写
+}
+
+/**
+ * @Description: 创建并开始监听 SIGTERM，使用完毕后需调用 Close
+ * @author: Mr wpl
+ * @param timeout time.Duration: 收到信号后等待进行中文件的最长时间，<= 0 时使用 types.DefaultShutdownTimeout
+ * @return *GracefulShutdown: 关闭控制器
+ */
+func NewGracefulShutdown(timeout time.Duration) *GracefulShutdown {
+	g := &GracefulShutdown{
+		timeout:  timeout,
+		signals:  make(chan os.Signal, 1),
+		stopping: make(chan struct{}),
+		closed:   make(chan struct{}),
+		inFlight: make(map[string]struct{}),
+	}
+	signal.Notify(g.signals, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-g.signals:
+			g.Trigger()
+		case <-g.closed:
+		}
+	}()
+	return g
+}
+
+// Trigger 进入关闭流程，与收到 SIGTERM 效果相同
+func (g *GracefulShutdown) Trigger() {
+	g.triggerOnce.Do(func() {
+		g.shuttingDown.Store(true)
+		close(g.stopping)
+	})
+}
+
+// ShuttingDown 是否已收到关闭信号
+func (g *GracefulShutdown) ShuttingDown() bool {
+	return g.shuttingDown.Load()
+}
+
+// Stopping 收到关闭信号时关闭的通道，用于中断等待并发名额的分发循环
+func (g *GracefulShutdown) Stopping() <-chan struct{} {
+	return g.stopping
+}
+
+// Start 登记一个即将启动的文件扫描协程
+func (g *GracefulShutdown) Start(path string) {
+	g.wg.Add(1)
+	g.mu.Lock()
+	g.inFlight[path] = struct{}{}
+	g.mu.Unlock()
+}
+
+/**
+ * @Description: 文件扫描完成，Wait 尚未放弃等待时调用 deliver 提交结果
+ * @author: Mr wpl
+ * @param path string: 与 Start 相同的文件路径
+ * @param deliver func(): 提交结果，如写入结果通道
+ */
+func (g *GracefulShutdown) Done(path string, deliver func()) {
+	defer g.wg.Done()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.abandoned {
+		return
+	}
+	delete(g.inFlight, path)
+	deliver()
+}
+
+/**
+ * @Description: 等待所有已登记的协程完成；收到关闭信号后最多再等待 timeout。
+ * 返回后不会再有 deliver 被调用，可以安全关闭结果通道
+ * @author: Mr wpl
+ * @return []string: 超时仍未完成 (被中止) 的文件路径，已排序；全部完成时为 nil
+ */
+func (g *GracefulShutdown) Wait() []string {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-g.stopping:
+	}
+
+	timeout := g.timeout
+	if timeout <= 0 {
+		timeout = types.DefaultShutdownTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.abandoned = true
+	if len(g.inFlight) == 0 {
+		return nil
+	}
+	aborted := make([]string, 0, len(g.inFlight))
+	for path := range g.inFlight {
+		aborted = append(aborted, path)
+	}
+	sort.Strings(aborted)
+	return aborted
+}
+
+// Close 停止监听 SIGTERM
+func (g *GracefulShutdown) Close() {
+	signal.Stop(g.signals)
+	close(g.closed)
+}
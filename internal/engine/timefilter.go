@@ -0,0 +1,79 @@
+/*
+ * @Date: 2025-06-11 10:15:37
+ * @Editors: Mr wpl
+ * @Description: 按修改时间筛选待扫描文件 (-since、-last-days、-last-hours)，用于每日增量扫描
+ */
+package engine
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// sinceLayouts -since 支持的时间格式
+var sinceLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+/**
+ * @Description: 只保留修改时间不早于 Cutoff 的文件
+ * @author: Mr wpl
+ */
+type RecentFilesFilter struct {
+	Cutoff time.Time
+}
+
+/**
+ * @Description: 修改时间不早于 Cutoff 时返回 true
+ * @author: Mr wpl
+ * @param fi os.FileInfo: 文件信息
+ * @return bool: 是否扫描
+ */
+func (f *RecentFilesFilter) Include(fi os.FileInfo) bool {
+	return !fi.ModTime().Before(f.Cutoff)
+}
+
+/**
+ * @Description: 根据 -since、-last-days、-last-hours 创建时间过滤器，同时指定多个时取最晚的截止时间 (最严格)
+ * @author: Mr wpl
+ * @param now time.Time: 当前时间
+ * @param since time.Time: 起始时间，零值表示未指定
+ * @param lastDays int: 最近天数，0 表示未指定
+ * @param lastHours int: 最近小时数，0 表示未指定
+ * @return TimeFilter: 时间过滤器，均未指定时为 nil
+ */
+func NewRecentFilesFilter(now time.Time, since time.Time, lastDays int, lastHours int) TimeFilter {
+	cutoff := since
+	if lastDays > 0 {
+		if c := now.Add(-time.Duration(lastDays) * 24 * time.Hour); c.After(cutoff) {
+			cutoff = c
+		}
+	}
+	if lastHours > 0 {
+		if c := now.Add(-time.Duration(lastHours) * time.Hour); c.After(cutoff) {
+			cutoff = c
+		}
+	}
+	if cutoff.IsZero() {
+		return nil
+	}
+	return &RecentFilesFilter{Cutoff: cutoff}
+}
+
+/**
+ * @Description: 解析 -since 参数，支持 RFC3339、"2006-01-02 15:04:05" 和 "2006-01-02" (本地时区)
+ * @author: Mr wpl
+ * @param value string: 参数值
+ * @return time.Time: 解析结果，value 为空时为零值
+ * @return error: 格式错误
+ */
+func ParseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range sinceLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected RFC3339, \"2006-01-02 15:04:05\" or \"2006-01-02\")", value)
+}
@@ -0,0 +1,172 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 修改时间过滤测试 (-since、-last-days、-last-hours)
+ */
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fakeFileInfo 只提供修改时间的 os.FileInfo
+type fakeFileInfo struct {
+	os.FileInfo
+	modTime time.Time
+}
+
+func (fi fakeFileInfo) ModTime() time.Time { return fi.modTime }
+
+func TestNewRecentFilesFilter(t *testing.T) {
+	now := time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		since      time.Time
+		lastDays   int
+		lastHours  int
+		wantCutoff time.Time // 零值表示不过滤
+	}{
+		{"none", time.Time{}, 0, 0, time.Time{}},
+		{"since only", now.Add(-72 * time.Hour), 0, 0, now.Add(-72 * time.Hour)},
+		{"last days only", time.Time{}, 1, 0, now.Add(-24 * time.Hour)},
+		{"last hours only", time.Time{}, 0, 6, now.Add(-6 * time.Hour)},
+		{"last hours stricter than last days", time.Time{}, 2, 6, now.Add(-6 * time.Hour)},
+		{"since stricter than last days", now.Add(-2 * time.Hour), 1, 0, now.Add(-2 * time.Hour)},
+		{"last days stricter than since", now.Add(-72 * time.Hour), 1, 0, now.Add(-24 * time.Hour)},
+		{"all three", now.Add(-48 * time.Hour), 1, 12, now.Add(-12 * time.Hour)},
+		{"negative values ignored", time.Time{}, -1, -1, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewRecentFilesFilter(now, tt.since, tt.lastDays, tt.lastHours)
+			if tt.wantCutoff.IsZero() {
+				if filter != nil {
+					t.Fatalf("NewRecentFilesFilter() = %+v, want nil", filter)
+				}
+				return
+			}
+			recent, ok := filter.(*RecentFilesFilter)
+			if !ok {
+				t.Fatalf("NewRecentFilesFilter() = %T, want *RecentFilesFilter", filter)
+			}
+			if !recent.Cutoff.Equal(tt.wantCutoff) {
+				t.Errorf("Cutoff = %v, want %v", recent.Cutoff, tt.wantCutoff)
+			}
+		})
+	}
+}
+
+// TestRecentFilesFilterBoundary 修改时间等于截止时间的文件包含在内，早 1ns 的文件排除，晚于当前时间的文件 (时钟偏差) 包含在内
+func TestRecentFilesFilterBoundary(t *testing.T) {
+	now := time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-36 * time.Hour)
+	tests := []struct {
+		name    string
+		filter  TimeFilter
+		modTime time.Time
+		want    bool
+	}{
+		{"since: at cutoff", NewRecentFilesFilter(now, since, 0, 0), since, true},
+		{"since: 1ns before", NewRecentFilesFilter(now, since, 0, 0), since.Add(-time.Nanosecond), false},
+		{"since: 1ns after", NewRecentFilesFilter(now, since, 0, 0), since.Add(time.Nanosecond), true},
+		{"last days: at cutoff", NewRecentFilesFilter(now, time.Time{}, 1, 0), now.Add(-24 * time.Hour), true},
+		{"last days: 1ns before", NewRecentFilesFilter(now, time.Time{}, 1, 0), now.Add(-24*time.Hour - time.Nanosecond), false},
+		{"last hours: at cutoff", NewRecentFilesFilter(now, time.Time{}, 0, 3), now.Add(-3 * time.Hour), true},
+		{"last hours: 1ns before", NewRecentFilesFilter(now, time.Time{}, 0, 3), now.Add(-3*time.Hour - time.Nanosecond), false},
+		{"until now", NewRecentFilesFilter(now, time.Time{}, 0, 3), now, true},
+		{"after now", NewRecentFilesFilter(now, time.Time{}, 0, 3), now.Add(time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Include(fakeFileInfo{modTime: tt.modTime}); got != tt.want {
+				t.Errorf("Include(%v) = %v, want %v", tt.modTime, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"2025-06-10T08:30:00Z", time.Date(2025, 6, 10, 8, 30, 0, 0, time.UTC), false},
+		{"2025-06-10 08:30:00", time.Date(2025, 6, 10, 8, 30, 0, 0, time.Local), false},
+		{"2025-06-10", time.Date(2025, 6, 10, 0, 0, 0, 0, time.Local), false},
+		{"yesterday", time.Time{}, true},
+		{"2025/06/10", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSince(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindFilesTimeFilter 创建不同修改时间的文件，验证遍历时只选中截止时间之后修改的文件
+func TestFindFilesTimeFilter(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	ages := map[string]time.Duration{
+		"fresh.php":        10 * time.Minute,
+		"today.php":        5 * time.Hour,
+		"yesterday.php":    30 * time.Hour,
+		"sub/lastweek.php": 7 * 24 * time.Hour,
+		"sub/recent.php":   2 * time.Hour,
+	}
+	for name, age := range ages {
+		path := filepath.Join(root, name)
+		writeTestFile(t, path, "<?php echo 1;")
+		modTime := now.Add(-age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter TimeFilter
+		want   []string
+	}{
+		{"no filter", nil, []string{"fresh.php", "sub/lastweek.php", "sub/recent.php", "today.php", "yesterday.php"}},
+		{"last days 1", NewRecentFilesFilter(now, time.Time{}, 1, 0), []string{"fresh.php", "sub/recent.php", "today.php"}},
+		{"last days 2", NewRecentFilesFilter(now, time.Time{}, 2, 0), []string{"fresh.php", "sub/recent.php", "today.php", "yesterday.php"}},
+		{"last hours 3", NewRecentFilesFilter(now, time.Time{}, 0, 3), []string{"fresh.php", "sub/recent.php"}},
+		{"since 1h ago", NewRecentFilesFilter(now, now.Add(-time.Hour), 0, 0), []string{"fresh.php"}},
+		{"since wins over last days", NewRecentFilesFilter(now, now.Add(-3*time.Hour), 1, 0), []string{"fresh.php", "sub/recent.php"}},
+		{"last hours wins over since", NewRecentFilesFilter(now, now.Add(-48*time.Hour), 0, 6), []string{"fresh.php", "sub/recent.php", "today.php"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := findFiles([]string{root}, nil, []string{".php"}, false, false, tt.filter)
+			if err != nil {
+				t.Fatalf("findFiles() error = %v", err)
+			}
+			got := make([]string, 0, len(files))
+			for _, file := range files {
+				rel, err := filepath.Rel(root, file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(rel))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 单个文件与单个分析器的扫描时间上限 (performance.file_scan_timeout / analyzer_timeout)
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/internal/features"
+	"bt-shieldml/internal/metrics"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// analyzerTimeoutDescription 分析器超时时记录的发现描述
+const analyzerTimeoutDescription = "analyzer timed out"
+
+// analyzeResult 后台运行的 Analyze 调用结果
+type analyzeResult struct {
+	finding *types.Finding
+	err     error
+}
+
+// fileWorkKey context 中保存 fileWork 的键
+type fileWorkKey struct{}
+
+// fileWork 单个文件的扫描中超时后被放弃、仍在后台运行的 goroutine (Analyze 与扫描本身都无法被中断)。
+// 持有工作槽位的调用方通过 release 在它们结束后才释放槽位，使实际并发不超过 performance.concurrency
+type fileWork struct {
+	wg      sync.WaitGroup
+	pending atomic.Int32
+}
+
+// withFileWork 返回携带 work 的 context，文件扫描中被放弃的 goroutine 登记到 work
+func withFileWork(ctx context.Context, work *fileWork) context.Context {
+	return context.WithValue(ctx, fileWorkKey{}, work)
+}
+
+/**
+ * @Description: 在文件的后台工作全部结束后调用 fn：没有被放弃的 goroutine 时立即调用，否则在后台等待其结束后调用。
+ * 须在 scanFile 返回后调用，此时不会再有新的 goroutine 登记
+ * @author: Mr wpl
+ * @param fn func(): 释放槽位等收尾操作
+ */
+func (w *fileWork) release(fn func()) {
+	if w == nil || w.pending.Load() == 0 {
+		fn()
+		return
+	}
+	go func() {
+		w.wg.Wait()
+		fn()
+	}()
+}
+
+/**
+ * @Description: 登记一个超时后被放弃的 goroutine，wait 返回即表示其已结束。登记到 ctx 中的 fileWork (如有)
+ * 并计入引擎的 abandoned 计数
+ * @author: Mr wpl
+ * @param ctx context.Context: 文件扫描的 context
+ * @param wait func(): 阻塞至被放弃的 goroutine 结束
+ * @return int64: 登记后仍在运行的被放弃 goroutine 数
+ */
+func (e *Engine) abandon(ctx context.Context, wait func()) int64 {
+	work, _ := ctx.Value(fileWorkKey{}).(*fileWork)
+	if work != nil {
+		work.wg.Add(1)
+		work.pending.Add(1)
+	}
+	running := e.abandoned.Add(1)
+	go func() {
+		wait()
+		e.abandoned.Add(-1)
+		if work != nil {
+			work.wg.Done()
+		}
+	}()
+	return running
+}
+
+/**
+ * @Description: 在 analyzer_timeout 内运行分析器。超时 (或文件扫描超时) 时返回一条风险未知的
+ * "analyzer timed out" 发现 (不参与评分) 并继续下一个分析器；Analyze 无法被中断，会在后台运行至结束，结果被丢弃，
+ * 期间仍占用该文件的工作槽位 (见 fileWork)
+ * @author: Mr wpl
+ * @param ctx context.Context: 日志上下文，文件扫描超时时被取消
+ * @param name string: 分析器名称
+ * @param analyzer Analyzer: 分析器
+ * @param fileInfo types.FileInfo: 文件信息
+ * @param content []byte: 文件 (或分段) 内容
+ * @param featureSet *features.FeatureSet: 特征集
+ * @return *types.Finding: 分析结果
+ * @return error: 错误
+ */
+func (e *Engine) runAnalyzer(ctx context.Context, name string, analyzer Analyzer, fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	start := time.Now()
+	defer func() { metrics.ObserveAnalyzer(name, time.Since(start)) }()
+
+	timeout := e.config.Performance.AnalyzerWait()
+	timedOut := &types.Finding{
+		AnalyzerName: name,
+		Description:  analyzerTimeoutDescription,
+		Risk:         types.RiskUnknown,
+		Metadata:     map[string]interface{}{"timeout": timeout.String()},
+	}
+	// 文件扫描已超时：结果会被丢弃，不再启动新的分析器
+	if ctx.Err() != nil {
+		return timedOut, nil
+	}
+	if timeout <= 0 {
+		return analyzer.Analyze(fileInfo, content, featureSet)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan analyzeResult, 1)
+	go func() {
+		finding, err := analyzer.Analyze(fileInfo, content, featureSet)
+		done <- analyzeResult{finding: finding, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.finding, r.err
+	case <-timeoutCtx.Done():
+		running := e.abandon(ctx, func() { <-done })
+		logger := logging.WithCtx(ctx)
+		logger.Warn().Str(logging.FieldAnalyzer, name).Msgf("Analyzer '%s' timed out after %s on %s (%d abandoned scans still running)",
+			name, timeout, fileInfo.Path, running)
+		return timedOut, nil
+	}
+}
+
+/**
+ * @Description: 扫描单个文件并记录 Prometheus 指标。持有工作槽位的调用方应通过 withFileWork 传入 fileWork，
+ * 并在 fileWork.release 中释放槽位
+ * @author: Mr wpl
+ * @param ctx context.Context: 携带扫描 ID 的日志上下文
+ * @param filePath string: 文件路径
+ * @param astMgr ast.ASTManager: AST 管理器实例
+ * @return *types.ScanResult: 扫描结果
+ */
+func (e *Engine) scanFile(ctx context.Context, filePath string, astMgr ast.ASTManager) *types.ScanResult {
+	result := e.scanFileWithTimeout(ctx, filePath, astMgr)
+	metrics.ObserveFile(result)
+	return result
+}
+
+/**
+ * @Description: 在 file_scan_timeout 内扫描单个文件，超时的文件记为扫描错误。分析器无法被中断：超时后扫描在后台
+ * 运行至当前分析器结束 (不再启动剩余的分析器)，结果被丢弃，并登记到 ctx 中的 fileWork 以继续占用工作槽位
+ * @author: Mr wpl
+ * @param ctx context.Context: 携带扫描 ID 的日志上下文
+ * @param filePath string: 文件路径
+ * @param astMgr ast.ASTManager: AST 管理器实例
+ * @return *types.ScanResult: 扫描结果
+ */
+func (e *Engine) scanFileWithTimeout(ctx context.Context, filePath string, astMgr ast.ASTManager) *types.ScanResult {
+	timeout := e.config.Performance.FileScanWait()
+	if timeout <= 0 {
+		return e.scanFileContent(ctx, filePath, astMgr)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan *types.ScanResult, 1)
+	go func() {
+		done <- e.scanFileContent(ctx, filePath, astMgr)
+	}()
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		running := e.abandon(ctx, func() { <-done })
+		logging.WarnCtx(logging.WithContext(ctx, "", filePath), "Scan of %s timed out after %s (%d abandoned scans still running)",
+			filePath, timeout, running)
+		result := &types.ScanResult{
+			File:     types.FileInfo{Path: filePath},
+			Error:    fmt.Errorf("file scan timed out after %s", timeout),
+			Duration: timeout,
+		}
+		if info, err := e.fileReader.Stat(filePath); err == nil {
+			result.File.Size = info.Size()
+			result.File.ModTime = info.ModTime()
+		}
+		return result
+	}
+}
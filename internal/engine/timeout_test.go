@@ -0,0 +1,135 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 扫描超时测试：超时的分析器记录 "analyzer timed out" 发现，被放弃的扫描结束前不释放工作槽位
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingAnalyzer Analyze 阻塞至 unblock 关闭
+type blockingAnalyzer struct {
+	mockAnalyzer
+	unblock chan struct{}
+}
+
+func (b *blockingAnalyzer) Analyze(fileInfo types.FileInfo, content []byte, featureSet *features.FeatureSet) (*types.Finding, error) {
+	b.calls.Add(1)
+	<-b.unblock
+	return nil, nil
+}
+
+// waitAbandoned 等待引擎中被放弃的 goroutine 数变为 want
+func waitAbandoned(t *testing.T, e *Engine, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for e.abandoned.Load() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("abandoned = %d, want %d", e.abandoned.Load(), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAnalyzerTimeout(t *testing.T) {
+	slow := &blockingAnalyzer{mockAnalyzer: mockAnalyzer{name: "slow"}, unblock: make(chan struct{})}
+	regex := &mockAnalyzer{name: "regex", match: "eval", risk: types.RiskHigh}
+	e := newTestEngine(t, slow, regex)
+	e.config.Performance.AnalyzerTimeout = 20 * time.Millisecond
+	e.SetFileReader(NewMockFileReader(map[string][]byte{"/www/a.php": []byte("<?php eval($x);")}))
+
+	work := &fileWork{}
+	res := e.scanFile(withFileWork(context.Background(), work), "/www/a.php", nil)
+	if res.Error != nil {
+		t.Fatalf("scanFile() error = %v", res.Error)
+	}
+	var timedOut, matched bool
+	for _, f := range res.Findings {
+		timedOut = timedOut || f.AnalyzerName == "slow" && f.Description == analyzerTimeoutDescription && f.Risk == types.RiskUnknown
+		matched = matched || f.AnalyzerName == "regex"
+	}
+	if !timedOut || !matched {
+		t.Errorf("findings = %+v, want a timed-out slow analyzer and the regex match", res.Findings)
+	}
+	if got := e.abandoned.Load(); got != 1 {
+		t.Errorf("abandoned = %d, want 1 while the analyzer is still running", got)
+	}
+
+	// 超时的分析器仍在运行时继续占用槽位
+	released := make(chan struct{})
+	work.release(func() { close(released) })
+	select {
+	case <-released:
+		t.Fatal("worker slot released while the timed-out analyzer is still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(slow.unblock)
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker slot not released after the analyzer returned")
+	}
+	waitAbandoned(t, e, 0)
+}
+
+func TestFileScanTimeout(t *testing.T) {
+	// 按 analyzerPriority 顺序执行：hash 在 regex 之前
+	slow := &blockingAnalyzer{mockAnalyzer: mockAnalyzer{name: "hash"}, unblock: make(chan struct{})}
+	next := &mockAnalyzer{name: "regex", match: "eval", risk: types.RiskHigh}
+	e := newTestEngine(t, slow, next)
+	parallel := false
+	e.config.Performance.ParallelAnalyzers = &parallel
+	e.config.Performance.AnalyzerTimeout = -1
+	e.config.Performance.FileScanTimeout = 20 * time.Millisecond
+	e.SetFileReader(NewMockFileReader(map[string][]byte{"/www/a.php": []byte("<?php eval($x);")}))
+
+	work := &fileWork{}
+	res := e.scanFile(withFileWork(context.Background(), work), "/www/a.php", nil)
+	if res.Error == nil {
+		t.Fatal("scanFile() error = nil, want a file scan timeout")
+	}
+	if res.File.Size == 0 {
+		t.Error("timed-out result has no file size")
+	}
+
+	released := make(chan struct{})
+	work.release(func() { close(released) })
+	select {
+	case <-released:
+		t.Fatal("worker slot released while the timed-out scan is still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(slow.unblock)
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker slot not released after the abandoned scan finished")
+	}
+	waitAbandoned(t, e, 0)
+
+	// 文件扫描超时后不再启动剩余的分析器
+	if calls := next.calls.Load(); calls != 0 {
+		t.Errorf("analyzer after the timeout ran %d times, want 0", calls)
+	}
+}
+
+// TestFileWorkReleaseWithoutTimeout 没有被放弃的 goroutine 时立即释放
+func TestFileWorkReleaseWithoutTimeout(t *testing.T) {
+	released := false
+	(&fileWork{}).release(func() { released = true })
+	if !released {
+		t.Error("release() did not run immediately")
+	}
+	released = false
+	var work *fileWork
+	work.release(func() { released = true })
+	if !released {
+		t.Error("release() on a nil fileWork did not run")
+	}
+}
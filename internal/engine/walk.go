@@ -0,0 +1,142 @@
+/*
+ * @Date: 2025-05-22 09:41:30
+ * @Editors: Mr wpl
+ * @Description: 跟随符号链接的目录遍历
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/platform"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fileID 唯一标识一个文件：inode 编号只在同一文件系统内唯一 (如每个 ext4 根目录都是 inode 2)，需与设备号一起使用
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+/**
+ * @Description: 与 filepath.Walk 行为一致的遍历，但会跟随符号链接进入目标文件或目录。
+ * 通过已访问目录的 (设备号, inode) 防止符号链接成环导致无限递归。
+ * 注意：在符号链接很多的大目录树上开启会显著增加扫描时间。
+ * @author: Mr wpl
+ * @param root string: 起始目录
+ * @param walkFn filepath.WalkFunc: 回调函数，path 为链接本身的路径，info 为链接目标的信息
+ * @return error: 错误
+ */
+func walkFollowingSymlinks(root string, walkFn filepath.WalkFunc) error {
+	visitedDirs := make(map[fileID]bool)
+	visitedPaths := make(map[string]bool) // 无法取得 inode 时 (Windows) 按解析链接后的真实路径判断
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return walkFn(path, nil, err)
+		}
+
+		// 解析符号链接，使用目标的文件信息
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return walkFn(path, nil, err)
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			info, err = os.Stat(target)
+			if err != nil {
+				return walkFn(path, nil, err)
+			}
+		}
+
+		// 目录成环保护
+		if info.IsDir() {
+			if id, ok := fileIDOf(info); ok {
+				if visitedDirs[id] {
+					return nil
+				}
+				visitedDirs[id] = true
+			} else if real, err := filepath.EvalSymlinks(path); err == nil {
+				key := platform.NormalizePath(real)
+				if visitedPaths[key] {
+					return nil
+				}
+				visitedPaths[key] = true
+			}
+		}
+
+		if err := walkFn(path, info, nil); err != nil {
+			if err == filepath.SkipDir && info.IsDir() {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return walkFn(path, info, err)
+		}
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names) // 与 filepath.Walk 一致，按名称顺序遍历
+		for _, name := range names {
+			if err := walk(filepath.Join(path, name)); err != nil {
+				if err == filepath.SkipDir {
+					return nil // 文件返回 SkipDir 时跳过同目录剩余条目
+				}
+				return err
+			}
+		}
+		return nil
+	}
+
+	return walk(root)
+}
+
+/**
+ * @Description: 计算扫描根目录：取第一个扫描路径的绝对路径，若为文件则取其所在目录
+ * @author: Mr wpl
+ * @param paths []string: 扫描路径
+ * @return string: 扫描根目录，无法确定时返回空字符串
+ */
+func scanRoot(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	absRoot, err := filepath.Abs(paths[0])
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Stat(absRoot); err == nil && !info.IsDir() {
+		return filepath.Dir(absRoot)
+	}
+	return absRoot
+}
+
+/**
+ * @Description: 计算文件相对于扫描根目录的路径，不在根目录下时返回空字符串
+ * @author: Mr wpl
+ * @param root string: 扫描根目录
+ * @param absPath string: 文件绝对路径 (phar 成员为 <phar>!/<member>)
+ * @return string: 相对路径
+ */
+func relativeToRoot(root string, absPath string) string {
+	if root == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return rel
+}
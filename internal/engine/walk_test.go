@@ -0,0 +1,160 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 跟随符号链接遍历测试
+ */
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// writeTestFile 在 dir 下创建文件，自动创建父目录
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// symlinkOrSkip 创建符号链接，不支持时 (如无权限的 Windows) 跳过测试
+func symlinkOrSkip(t *testing.T, target string, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+}
+
+func TestFindFilesFollowSymlinks(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestFile(t, filepath.Join(tmp, "sites-available", "shop", "index.php"), "<?php echo 1;")
+	writeTestFile(t, filepath.Join(tmp, "sites-enabled", "local.php"), "<?php echo 2;")
+	symlinkOrSkip(t, filepath.Join(tmp, "sites-available", "shop"), filepath.Join(tmp, "sites-enabled", "shop"))
+
+	root := filepath.Join(tmp, "sites-enabled")
+	tests := []struct {
+		name   string
+		follow bool
+		want   []string
+	}{
+		{"not following", false, []string{filepath.Join(root, "local.php")}},
+		{"following", true, []string{filepath.Join(root, "local.php"), filepath.Join(root, "shop", "index.php")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findFiles([]string{root}, nil, []string{".php"}, tt.follow, false, nil)
+			if err != nil {
+				t.Fatalf("findFiles() error = %v", err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkFollowingSymlinksCycle(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestFile(t, filepath.Join(tmp, "a", "a.php"), "<?php")
+	writeTestFile(t, filepath.Join(tmp, "b", "b.php"), "<?php")
+	// a/to_b → b，b/to_a → a，a/self → a：互相指向与自身指向都构成环
+	symlinkOrSkip(t, filepath.Join(tmp, "b"), filepath.Join(tmp, "a", "to_b"))
+	symlinkOrSkip(t, filepath.Join(tmp, "a"), filepath.Join(tmp, "b", "to_a"))
+	symlinkOrSkip(t, ".", filepath.Join(tmp, "a", "self"))
+
+	done := make(chan []string, 1)
+	go func() {
+		var files []string
+		_ = walkFollowingSymlinks(filepath.Join(tmp, "a"), func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		})
+		done <- files
+	}()
+
+	select {
+	case files := <-done:
+		want := []string{filepath.Join(tmp, "a", "a.php"), filepath.Join(tmp, "a", "to_b", "b.php")}
+		if !reflect.DeepEqual(files, want) {
+			t.Errorf("walk visited %v, want %v", files, want)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("walkFollowingSymlinks did not terminate on a symlink cycle")
+	}
+}
+
+func TestFileIDOf(t *testing.T) {
+	tmp := t.TempDir()
+	writeTestFile(t, filepath.Join(tmp, "one", "x.php"), "<?php")
+	writeTestFile(t, filepath.Join(tmp, "two", "x.php"), "<?php")
+	symlinkOrSkip(t, filepath.Join(tmp, "one"), filepath.Join(tmp, "alias"))
+
+	stat := func(path string) fileID {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, ok := fileIDOf(info)
+		if !ok {
+			t.Skip("file IDs are not available on this platform")
+		}
+		return id
+	}
+
+	one, two, alias := stat(filepath.Join(tmp, "one")), stat(filepath.Join(tmp, "two")), stat(filepath.Join(tmp, "alias"))
+	if one != alias {
+		t.Errorf("symlink target has a different file ID: %+v vs %+v", one, alias)
+	}
+	if one == two {
+		t.Errorf("distinct directories share file ID %+v", one)
+	}
+	// 不同文件系统上的同一 inode 编号不能被视为同一目录
+	if (fileID{dev: one.dev + 1, ino: one.ino}) == one {
+		t.Errorf("file ID ignores the device number")
+	}
+}
+
+// TestFindFilesPlatformPaths 扫描路径与排除规则分别以 / 和本平台写法 (filepath.FromSlash) 给出时结果相同，重复的根路径只扫描一次
+func TestFindFilesPlatformPaths(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+	for _, name := range []string{"index.php", "cache/page.php", "wp-content/plugins/shop/shop.php", "uploads/2025/x.php"} {
+		writeTestFile(t, filepath.FromSlash(root+"/"+name), "<?php")
+	}
+
+	tests := []struct {
+		name       string
+		paths      []string
+		exclusions []string
+	}{
+		{"slash", []string{root}, []string{root + "/cache", root + "/uploads/2025/"}},
+		{"native", []string{filepath.FromSlash(root)}, []string{filepath.FromSlash(root + "/cache"), filepath.FromSlash(root + "/uploads/2025/")}},
+		{"mixed", []string{root, filepath.FromSlash(root) + string(filepath.Separator)}, []string{filepath.FromSlash(root + "/cache"), root + "/uploads/2025"}},
+	}
+	want := []string{
+		filepath.FromSlash(root + "/index.php"),
+		filepath.FromSlash(root + "/wp-content/plugins/shop/shop.php"),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findFiles(tt.paths, tt.exclusions, []string{".php"}, false, false, nil)
+			if err != nil {
+				t.Fatalf("findFiles() error = %v", err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("findFiles() = %v, want %v", got, want)
+			}
+		})
+	}
+}
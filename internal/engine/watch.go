@@ -0,0 +1,311 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: -watch 监控模式：首次扫描后监控扫描路径的创建与写入事件，去抖后重新扫描变化的文件，风险升高时通过 webhook 通知
+ */
+package engine
+
+import (
+	"bt-shieldml/internal/reporting"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchWebhookTimeout 单次 webhook 请求超时
+const watchWebhookTimeout = 10 * time.Second
+
+// WatchAlert 重新扫描后文件风险升高时 POST 到 watch.webhook_url 的通知
+type WatchAlert struct {
+	Event        string                 `json:"event"` // 固定为 "risk_increased"
+	Host         string                 `json:"host"`
+	DetectedAt   time.Time              `json:"detected_at"`
+	PreviousRisk string                 `json:"previous_risk"` // 首次出现的文件为 Safe
+	Result       reporting.NDJSONResult `json:"result"`        // 与 -format ndjson 的单行结果相同
+}
+
+/**
+ * @Description: 监控模式的运行状态
+ * @author: Mr wpl
+ */
+type fileWatcher struct {
+	engine     *Engine
+	fsw        *fsnotify.Watcher
+	stop       <-chan struct{}
+	exclusions *exclusionMatcher
+	extensions []string
+	files      map[string]bool        // 直接指定的文件 (监控其所在目录，只处理该文件)
+	dirs       map[string]bool        // 递归监控的目录
+	timers     map[string]*time.Timer // 等待去抖的文件，只在 Watch 主循环中读写
+	ready      chan string            // 去抖结束、需要重新扫描的文件
+	debounce   time.Duration
+	webhookURL string
+	client     *http.Client
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	risks      map[string]types.RiskLevel // 文件 → 上次扫描的风险，只能在持有 mu 时读写
+}
+
+/**
+ * @Description: 监控任务中的路径 (递归监控目录，新建的子目录自动加入)，文件创建或写入后等待 watch.debounce
+ * 不再变化时通过 scanFile 重新扫描；风险高于上次结果 (baseline 中没有的文件视为 Safe) 时记录警告并通知 watch.webhook_url。
+ * 阻塞直到 ctx 取消，返回前等待进行中的扫描结束。与 Scan 共用并发名额；首次扫描需设置 Task.KeepAlive，否则 PHP 桥接已关闭
+ * @author: Mr wpl
+ * @param ctx context.Context: 取消时停止监控 (如收到 SIGINT/SIGTERM)
+ * @param task *Task: 任务，使用 Paths (标准输入的临时文件除外) 与 Exclusions
+ * @param baseline []*types.ScanResult: 首次扫描的结果，作为风险比较的基准
+ * @return error: 无法创建监控或没有可监控的路径时返回错误
+ */
+func (e *Engine) Watch(ctx context.Context, task *Task, baseline []*types.ScanResult) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	w := &fileWatcher{
+		engine:     e,
+		fsw:        fsw,
+		stop:       ctx.Done(),
+		exclusions: newExclusionMatcher(e.exclusions(task)),
+		extensions: e.config.Extensions(),
+		files:      make(map[string]bool),
+		dirs:       make(map[string]bool),
+		timers:     make(map[string]*time.Timer),
+		ready:      make(chan string),
+		debounce:   e.config.Watch.DebounceWait(),
+		webhookURL: e.config.Watch.WebhookURL,
+		client:     &http.Client{Timeout: watchWebhookTimeout},
+		risks:      make(map[string]types.RiskLevel),
+	}
+	for _, res := range baseline {
+		if res.Error == nil && !res.Incomplete {
+			w.risks[res.File.Path] = res.OverallRisk
+		}
+	}
+
+	for _, p := range task.rootPaths() {
+		absP, err := filepath.Abs(p)
+		if err != nil {
+			logging.WarnLogger.Printf("Could not get absolute path for watch target '%s': %v. Skipping.", p, err)
+			continue
+		}
+		cleanPath := filepath.Clean(absP)
+		if w.excluded(cleanPath) {
+			continue
+		}
+		info, err := os.Stat(cleanPath)
+		if err != nil {
+			logging.WarnLogger.Printf("Not watching %s: %v", p, err)
+			continue
+		}
+		if info.IsDir() {
+			w.addTree(cleanPath, false)
+			continue
+		}
+		if err := fsw.Add(filepath.Dir(cleanPath)); err != nil {
+			logging.WarnLogger.Printf("Failed to watch %s: %v", cleanPath, err)
+			continue
+		}
+		w.files[cleanPath] = true
+	}
+	if len(w.dirs) == 0 && len(w.files) == 0 {
+		return fmt.Errorf("no paths to watch")
+	}
+	logging.InfoLogger.Printf("Watching %d directories and %d files for changes (debounce %s)", len(w.dirs), len(w.files), w.debounce)
+
+	// 重新扫描不随 ctx 取消，停止监控时等待其正常结束
+	scanCtx := logging.WithContext(context.Background(), logging.NewScanID(), "")
+	for {
+		select {
+		case <-ctx.Done():
+			for _, timer := range w.timers {
+				timer.Stop()
+			}
+			w.wg.Wait()
+			logging.InfoLogger.Println("File watcher stopped")
+			return nil
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			w.handleEvent(event)
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			logging.WarnLogger.Printf("File watcher error: %v", err)
+		case path := <-w.ready:
+			delete(w.timers, path)
+			w.wg.Add(1)
+			go w.rescan(scanCtx, path)
+		}
+	}
+}
+
+/**
+ * @Description: 处理一个文件系统事件：只关注创建与写入；新建的子目录加入监控并扫描其中已有的文件
+ * (如整个目录被移入)，需要扫描的文件进入去抖
+ * @author: Mr wpl
+ * @param event fsnotify.Event: 事件
+ */
+func (w *fileWatcher) handleEvent(event fsnotify.Event) {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return
+	}
+	path := filepath.Clean(event.Name)
+	if w.excluded(path) {
+		return
+	}
+	inTree := w.dirs[filepath.Dir(path)]
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if inTree {
+				w.addTree(path, true)
+			}
+			return
+		}
+	}
+	if w.files[path] || (inTree && isScannableFile(path, w.extensions, false)) {
+		w.schedule(path)
+	}
+}
+
+/**
+ * @Description: 递归监控目录，跳过排除的目录
+ * @author: Mr wpl
+ * @param root string: 目录
+ * @param scanExisting bool: 是否同时重新扫描其中已有的文件 (监控开始后新建的目录)
+ */
+func (w *fileWatcher) addTree(root string, scanExisting bool) {
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logging.WarnLogger.Printf("Error accessing path %s while adding watches: %v", path, err)
+			return nil
+		}
+		if w.excluded(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			if scanExisting && isScannableFile(path, w.extensions, false) {
+				w.schedule(path)
+			}
+			return nil
+		}
+		if w.dirs[path] {
+			return nil
+		}
+		if err := w.fsw.Add(path); err != nil {
+			// 常见原因是 inotify 监控数达到上限 (fs.inotify.max_user_watches)
+			logging.WarnLogger.Printf("Failed to watch %s: %v", path, err)
+			return nil
+		}
+		w.dirs[path] = true
+		return nil
+	})
+	if err != nil {
+		logging.WarnLogger.Printf("Error adding watches under %s: %v", root, err)
+	}
+}
+
+// excluded 判断路径是否匹配排除规则
+func (w *fileWatcher) excluded(path string) bool {
+	return w.exclusions.Match(path)
+}
+
+// schedule 文件变化后 (重新) 开始去抖计时，计时结束时交给主循环重新扫描
+func (w *fileWatcher) schedule(path string) {
+	if timer, ok := w.timers[path]; ok {
+		timer.Reset(w.debounce)
+		return
+	}
+	w.timers[path] = time.AfterFunc(w.debounce, func() {
+		select {
+		case w.ready <- path:
+		case <-w.stop:
+		}
+	})
+}
+
+/**
+ * @Description: 重新扫描一个文件，与上次的风险比较，升高时记录警告并发送 webhook
+ * @author: Mr wpl
+ * @param ctx context.Context: 日志上下文
+ * @param path string: 文件路径
+ */
+func (w *fileWatcher) rescan(ctx context.Context, path string) {
+	defer w.wg.Done()
+
+	w.engine.workers <- struct{}{}
+	work := &fileWork{}
+	result := w.engine.scanFile(withFileWork(ctx, work), path, w.engine.astManager)
+	work.release(func() { <-w.engine.workers })
+	if result.Error != nil {
+		logging.WarnLogger.Printf("Rescan of %s failed: %v", path, result.Error)
+		return
+	}
+
+	w.mu.Lock()
+	previous, known := w.risks[path]
+	w.risks[path] = result.OverallRisk
+	w.mu.Unlock()
+	if !known {
+		previous = types.RiskNone
+	}
+	logging.InfoLogger.Printf("Rescanned %s: %s (previously %s)", path, result.OverallRisk, previous)
+	if result.OverallRisk <= previous {
+		return
+	}
+
+	logging.WarnLogger.Printf("Risk of %s increased from %s to %s", path, previous, result.OverallRisk)
+	if w.webhookURL == "" {
+		return
+	}
+	if err := w.notify(result, previous); err != nil {
+		logging.WarnLogger.Printf("Failed to send watch webhook for %s: %v", path, err)
+	}
+}
+
+/**
+ * @Description: 将风险升高的结果 POST 到 watch.webhook_url
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 重新扫描的结果
+ * @param previous types.RiskLevel: 上次的风险
+ * @return error: 错误
+ */
+func (w *fileWatcher) notify(result *types.ScanResult, previous types.RiskLevel) error {
+	host, _ := os.Hostname()
+	body, err := json.Marshal(WatchAlert{
+		Event:        "risk_increased",
+		Host:         host,
+		DetectedAt:   time.Now(),
+		PreviousRisk: previous.String(),
+		Result:       reporting.ToNDJSONResult(result),
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	logging.InfoLogger.Printf("Sent watch webhook notification for %s", result.File.Path)
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ * @Date: 2025-05-27 09:48:15
+ * @Editors: Mr wpl
+ * @Description: VirusTotal 查询结果本地缓存 (BoltDB)
+ */
+package enrichment
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// DefaultVTCachePath 默认缓存文件路径
+	DefaultVTCachePath = "data/vt_cache.db"
+	// VTCacheTTL 缓存有效期
+	VTCacheTTL = 7 * 24 * time.Hour
+)
+
+var vtBucket = []byte("vt_reports")
+
+// VTReport 单个哈希的 VirusTotal 查询结果
+type VTReport struct {
+	SHA256    string    `json:"sha256"`
+	Found     bool      `json:"found"`     // VirusTotal 是否收录该文件
+	Malicious int       `json:"malicious"` // 判定为恶意的引擎数
+	Total     int       `json:"total"`     // 参与判定的引擎总数
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Summary 返回用于报告的简短描述，如 "12/70" 或 "not_found"
+func (r *VTReport) Summary() string {
+	if !r.Found {
+		return "not_found"
+	}
+	return fmt.Sprintf("%d/%d", r.Malicious, r.Total)
+}
+
+// VTCache 基于 BoltDB 的查询结果缓存
+type VTCache struct {
+	db  *bolt.DB
+	ttl time.Duration
+}
+
+/**
+ * @Description: 打开 (或创建) 缓存数据库
+ * @author: Mr wpl
+ * @param path string: 数据库路径
+ * @return *VTCache: 缓存
+ * @return error: 错误
+ */
+func OpenVTCache(path string) (*VTCache, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vt cache %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(vtBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize vt cache: %w", err)
+	}
+	return &VTCache{db: db, ttl: VTCacheTTL}, nil
+}
+
+/**
+ * @Description: 读取缓存，过期或不存在时返回 false
+ * @author: Mr wpl
+ * @param sha256 string: 文件 SHA-256
+ * @return *VTReport: 查询结果
+ * @return bool: 是否命中
+ */
+func (c *VTCache) Get(sha256 string) (*VTReport, bool) {
+	var report *VTReport
+	c.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(vtBucket).Get([]byte(sha256))
+		if data == nil {
+			return nil
+		}
+		var r VTReport
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil // 损坏的条目视为未命中，稍后会被覆盖
+		}
+		report = &r
+		return nil
+	})
+	if report == nil || time.Since(report.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	return report, true
+}
+
+/**
+ * @Description: 写入缓存
+ * @author: Mr wpl
+ * @param report *VTReport: 查询结果
+ * @return error: 错误
+ */
+func (c *VTCache) Put(report *VTReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode vt report: %w", err)
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(vtBucket).Put([]byte(report.SHA256), data)
+	})
+}
+
+// Close 关闭缓存数据库
+func (c *VTCache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,127 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: VirusTotal 缓存测试：写入后读取、7 天有效期、损坏条目与重新打开后仍可读取
+ */
+package enrichment
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTestVTCache 在临时目录中打开缓存，测试结束时关闭
+func openTestVTCache(t *testing.T) (*VTCache, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vt_cache.db")
+	cache, err := OpenVTCache(path)
+	if err != nil {
+		t.Fatalf("OpenVTCache() error = %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache, path
+}
+
+func TestVTCacheGetPut(t *testing.T) {
+	cache, _ := openTestVTCache(t)
+	if cache.ttl != VTCacheTTL || VTCacheTTL != 7*24*time.Hour {
+		t.Errorf("ttl = %s, want 7 days", cache.ttl)
+	}
+
+	fetched := time.Now().Add(-time.Hour).UTC().Round(time.Second)
+	report := &VTReport{SHA256: "aa", Found: true, Malicious: 12, Total: 70, FetchedAt: fetched}
+	if err := cache.Put(report); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, ok := cache.Get("aa")
+	if !ok {
+		t.Fatal("Get() missed a fresh entry")
+	}
+	if !reflect.DeepEqual(got, report) {
+		t.Errorf("Get() = %+v, want %+v", got, report)
+	}
+	if got.Summary() != "12/70" {
+		t.Errorf("Summary() = %q, want 12/70", got.Summary())
+	}
+
+	if _, ok := cache.Get("bb"); ok {
+		t.Error("Get() hit a hash that was never stored")
+	}
+
+	// 未收录的文件同样缓存
+	notFound := &VTReport{SHA256: "cc", FetchedAt: time.Now()}
+	if err := cache.Put(notFound); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := cache.Get("cc"); !ok || got.Summary() != "not_found" {
+		t.Errorf("Get(not found report) = %+v, %v, want a not_found hit", got, ok)
+	}
+}
+
+func TestVTCacheTTL(t *testing.T) {
+	cache, _ := openTestVTCache(t)
+	tests := []struct {
+		name    string
+		age     time.Duration
+		wantHit bool
+	}{
+		{"fresh", time.Minute, true},
+		{"six days old", 6 * 24 * time.Hour, true},
+		{"just expired", VTCacheTTL + time.Minute, false},
+		{"thirty days old", 30 * 24 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sha := "sha-" + tt.name
+			if err := cache.Put(&VTReport{SHA256: sha, Found: true, FetchedAt: time.Now().Add(-tt.age)}); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := cache.Get(sha); ok != tt.wantHit {
+				t.Errorf("Get() hit = %v, want %v for an entry %s old", ok, tt.wantHit, tt.age)
+			}
+		})
+	}
+}
+
+func TestVTCacheCorruptEntry(t *testing.T) {
+	cache, _ := openTestVTCache(t)
+	err := cache.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(vtBucket).Put([]byte("aa"), []byte("{not json"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("aa"); ok {
+		t.Error("Get() hit a corrupt entry")
+	}
+	// 损坏的条目被新的结果覆盖
+	if err := cache.Put(&VTReport{SHA256: "aa", Found: true, FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("aa"); !ok {
+		t.Error("Get() missed an entry that replaced a corrupt one")
+	}
+}
+
+func TestVTCacheReopen(t *testing.T) {
+	cache, path := openTestVTCache(t)
+	if err := cache.Put(&VTReport{SHA256: "aa", Found: true, Malicious: 1, Total: 60, FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenVTCache(path)
+	if err != nil {
+		t.Fatalf("OpenVTCache() error = %v", err)
+	}
+	defer reopened.Close()
+	if got, ok := reopened.Get("aa"); !ok || got.Summary() != "1/60" {
+		t.Errorf("Get() after reopening = %+v, %v, want 1/60", got, ok)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ * @Date: 2025-05-27 10:20:42
+ * @Editors: Mr wpl
+ * @Description: 限速的 VirusTotal 异步哈希查询池
+ */
+package enrichment
+
+import (
+	"bt-shieldml/pkg/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultVTConcurrency 默认并发查询数
+	DefaultVTConcurrency = 4
+	// DefaultVTRequestsPerMinute VirusTotal 免费账户的速率限制
+	DefaultVTRequestsPerMinute = 4
+
+	vtAPIBase = "https://www.virustotal.com/api/v3"
+)
+
+// RateLimitedHashChecker 在速率限制内并发查询 VirusTotal，结果写入本地缓存
+type RateLimitedHashChecker struct {
+	apiKey      string
+	concurrency int
+	interval    time.Duration // 两次请求之间的最小间隔
+	cache       *VTCache      // 可为 nil
+	client      *http.Client
+	baseURL     string // VirusTotal API 地址，测试时指向本地服务器
+}
+
+/**
+ * @Description: 创建查询池
+ * @author: Mr wpl
+ * @param apiKey string: VirusTotal API Key
+ * @param concurrency int: 并发数，<=0 时使用默认值 4
+ * @param requestsPerMinute int: 每分钟请求数上限，<=0 时使用默认值 4
+ * @param cache *VTCache: 结果缓存，可为 nil
+ * @return *RateLimitedHashChecker: 查询池
+ */
+func NewRateLimitedHashChecker(apiKey string, concurrency, requestsPerMinute int, cache *VTCache) *RateLimitedHashChecker {
+	if concurrency <= 0 {
+		concurrency = DefaultVTConcurrency
+	}
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = DefaultVTRequestsPerMinute
+	}
+	return &RateLimitedHashChecker{
+		apiKey:      apiKey,
+		concurrency: concurrency,
+		interval:    time.Minute / time.Duration(requestsPerMinute),
+		cache:       cache,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		baseURL:     vtAPIBase,
+	}
+}
+
+/**
+ * @Description: 查询一组哈希。缓存命中的直接返回，其余排队按速率限制查询；
+ * ctx 结束时停止，未完成的哈希不出现在结果中 (由调用方标记为 pending)
+ * @author: Mr wpl
+ * @param ctx context.Context: 控制总查询时间
+ * @param hashes []string: SHA-256 列表
+ * @return map[string]*VTReport: SHA-256 -> 查询结果
+ */
+func (c *RateLimitedHashChecker) Check(ctx context.Context, hashes []string) map[string]*VTReport {
+	results := make(map[string]*VTReport)
+	var mu sync.Mutex
+
+	var queue []string
+	seen := make(map[string]bool)
+	for _, sha := range hashes {
+		if sha == "" || seen[sha] {
+			continue
+		}
+		seen[sha] = true
+		if c.cache != nil {
+			if report, ok := c.cache.Get(sha); ok {
+				results[sha] = report
+				continue
+			}
+		}
+		queue = append(queue, sha)
+	}
+	if len(queue) == 0 {
+		return results
+	}
+
+	// 令牌桶：首个请求立即发出，之后每 interval 发放一个令牌
+	tokens := make(chan struct{}, 1)
+	tokens <- struct{}{}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case tokens <- struct{}{}:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sha := range jobs {
+				select {
+				case <-tokens:
+				case <-ctx.Done():
+					continue // 排空队列
+				}
+				report, err := c.lookup(ctx, sha)
+				if err != nil {
+					if ctx.Err() == nil {
+						logging.WarnLogger.Printf("VirusTotal lookup failed for %s: %v", sha, err)
+					}
+					continue
+				}
+				if c.cache != nil {
+					if err := c.cache.Put(report); err != nil {
+						logging.WarnLogger.Printf("Failed to cache VirusTotal report for %s: %v", sha, err)
+					}
+				}
+				mu.Lock()
+				results[sha] = report
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, sha := range queue {
+		select {
+		case jobs <- sha:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// vtFileResponse VirusTotal /files/{id} 响应中需要的部分
+type vtFileResponse struct {
+	Data struct {
+		Attributes struct {
+			LastAnalysisStats map[string]int `json:"last_analysis_stats"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+// lookup 查询单个哈希，404 视为未收录
+func (c *RateLimitedHashChecker) lookup(ctx context.Context, sha256 string) (*VTReport, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/files/"+sha256, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-apikey", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	report := &VTReport{SHA256: sha256, FetchedAt: time.Now()}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return report, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body vtFileResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	report.Found = true
+	for category, count := range body.Data.Attributes.LastAnalysisStats {
+		report.Total += count
+		if category == "malicious" {
+			report.Malicious = count
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,205 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: VirusTotal 查询池测试：用 httptest 服务器代替 API，校验响应解析、缓存命中、令牌桶限速与超时后未完成的哈希
+ */
+package enrichment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// vtTestAPI 模拟 VirusTotal /files/{sha256}：stats 中的哈希返回 200，"error" 开头的哈希返回 500，其余返回 404
+type vtTestAPI struct {
+	*httptest.Server
+	stats map[string]string // SHA-256 → last_analysis_stats JSON
+
+	mu       sync.Mutex
+	requests []string
+	times    []time.Time
+	apiKeys  []string
+}
+
+func newVTTestAPI(t *testing.T, stats map[string]string) *vtTestAPI {
+	t.Helper()
+	api := &vtTestAPI{stats: stats}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sha := strings.TrimPrefix(r.URL.Path, "/files/")
+		api.mu.Lock()
+		api.requests = append(api.requests, sha)
+		api.times = append(api.times, time.Now())
+		api.apiKeys = append(api.apiKeys, r.Header.Get("x-apikey"))
+		api.mu.Unlock()
+		switch body, ok := stats[sha]; {
+		case ok:
+			fmt.Fprintf(w, `{"data": {"attributes": {"last_analysis_stats": %s}}}`, body)
+		case strings.HasPrefix(sha, "error"):
+			http.Error(w, "quota exceeded", http.StatusInternalServerError)
+		default:
+			http.Error(w, `{"error": {"code": "NotFoundError"}}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+// Requests 已收到的查询哈希
+func (a *vtTestAPI) Requests() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.requests...)
+}
+
+// newTestChecker 指向 api 的查询池，请求间隔为 interval
+func newTestChecker(api *vtTestAPI, concurrency int, interval time.Duration, cache *VTCache) *RateLimitedHashChecker {
+	c := NewRateLimitedHashChecker("test-key", concurrency, 0, cache)
+	c.baseURL = api.URL
+	c.interval = interval
+	return c
+}
+
+func TestNewRateLimitedHashCheckerDefaults(t *testing.T) {
+	c := NewRateLimitedHashChecker("key", 0, 0, nil)
+	if c.concurrency != DefaultVTConcurrency || c.interval != 15*time.Second || c.baseURL != vtAPIBase {
+		t.Errorf("defaults = concurrency %d, interval %s, base %s, want %d, 15s, %s",
+			c.concurrency, c.interval, c.baseURL, DefaultVTConcurrency, vtAPIBase)
+	}
+	if c := NewRateLimitedHashChecker("key", 2, 60, nil); c.concurrency != 2 || c.interval != time.Second {
+		t.Errorf("60 requests per minute = concurrency %d, interval %s, want 2, 1s", c.concurrency, c.interval)
+	}
+}
+
+func TestCheckParsesResponses(t *testing.T) {
+	api := newVTTestAPI(t, map[string]string{
+		"malicious": `{"malicious": 12, "suspicious": 3, "undetected": 50, "harmless": 5}`,
+		"clean":     `{"malicious": 0, "undetected": 60}`,
+	})
+	cache, _ := openTestVTCache(t)
+	c := newTestChecker(api, 2, time.Millisecond, cache)
+
+	reports := c.Check(context.Background(), []string{"malicious", "clean", "unknown", "error500", "malicious", ""})
+
+	want := map[string]string{"malicious": "12/70", "clean": "0/60", "unknown": "not_found"}
+	if len(reports) != len(want) {
+		t.Errorf("got %d reports, want %d (failed lookups are left out): %v", len(reports), len(want), reports)
+	}
+	for sha, summary := range want {
+		report, ok := reports[sha]
+		if !ok {
+			t.Errorf("no report for %s", sha)
+			continue
+		}
+		if report.SHA256 != sha || report.Summary() != summary {
+			t.Errorf("report for %s = %+v, want %s", sha, report, summary)
+		}
+		// 成功的查询 (包括未收录) 写入缓存
+		if cached, ok := cache.Get(sha); !ok || cached.Summary() != summary {
+			t.Errorf("cache for %s = %+v, %v, want %s", sha, cached, ok, summary)
+		}
+	}
+	if _, ok := cache.Get("error500"); ok {
+		t.Error("failed lookup was cached")
+	}
+
+	// 重复与空的哈希只查询一次，并携带 API Key
+	if got := api.Requests(); len(got) != 4 {
+		t.Errorf("API received %d requests %v, want 4", len(got), got)
+	}
+	for _, key := range api.apiKeys {
+		if key != "test-key" {
+			t.Errorf("x-apikey = %q, want test-key", key)
+		}
+	}
+}
+
+func TestCheckUsesCache(t *testing.T) {
+	api := newVTTestAPI(t, map[string]string{"fresh": `{"malicious": 1, "undetected": 9}`})
+	cache, _ := openTestVTCache(t)
+	for _, report := range []*VTReport{
+		{SHA256: "cached", Found: true, Malicious: 5, Total: 70, FetchedAt: time.Now().Add(-time.Hour)},
+		{SHA256: "fresh", Found: true, Malicious: 99, Total: 99, FetchedAt: time.Now().Add(-8 * 24 * time.Hour)},
+	} {
+		if err := cache.Put(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := newTestChecker(api, 1, time.Millisecond, cache)
+
+	reports := c.Check(context.Background(), []string{"cached", "fresh"})
+	if got := reports["cached"].Summary(); got != "5/70" {
+		t.Errorf("cached report = %s, want 5/70 from the cache", got)
+	}
+	// 超过 7 天的缓存重新查询
+	if got := reports["fresh"].Summary(); got != "1/10" {
+		t.Errorf("expired report = %s, want 1/10 from the API", got)
+	}
+	if got := api.Requests(); len(got) != 1 || got[0] != "fresh" {
+		t.Errorf("API requests = %v, want only the expired hash", got)
+	}
+
+	// 全部命中缓存时不发出请求
+	api.mu.Lock()
+	api.requests = nil
+	api.mu.Unlock()
+	if reports := c.Check(context.Background(), []string{"cached", "fresh"}); len(reports) != 2 {
+		t.Errorf("got %d reports, want 2", len(reports))
+	}
+	if got := api.Requests(); len(got) != 0 {
+		t.Errorf("API requests = %v, want none when every hash is cached", got)
+	}
+}
+
+// TestCheckRateLimit 首个请求立即发出，之后每 interval 发放一个令牌 (与并发数无关)
+func TestCheckRateLimit(t *testing.T) {
+	api := newVTTestAPI(t, nil)
+	const interval = 40 * time.Millisecond
+	c := newTestChecker(api, 4, interval, nil)
+
+	start := time.Now()
+	reports := c.Check(context.Background(), []string{"a", "b", "c", "d", "e"})
+	if len(reports) != 5 {
+		t.Fatalf("got %d reports, want 5", len(reports))
+	}
+	if elapsed := time.Since(start); elapsed < 4*interval {
+		t.Errorf("5 lookups took %s, want at least %s", elapsed, 4*interval)
+	}
+	api.mu.Lock()
+	first := api.times[0].Sub(start)
+	api.mu.Unlock()
+	if first >= interval {
+		t.Errorf("first request sent after %s, want it immediately", first)
+	}
+}
+
+// TestCheckTimeout ctx 结束后停止查询，未完成的哈希不出现在结果中 (由调用方标记为 pending)
+func TestCheckTimeout(t *testing.T) {
+	api := newVTTestAPI(t, nil)
+	cache, _ := openTestVTCache(t)
+	if err := cache.Put(&VTReport{SHA256: "cached", Found: true, Malicious: 2, Total: 10, FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestChecker(api, 1, time.Hour, cache)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	reports := c.Check(ctx, []string{"cached", "a", "b", "c"})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Check() returned after %s, want it to stop when the context ends", elapsed)
+	}
+
+	// 缓存命中与令牌桶中的首个令牌完成，其余等待令牌时超时
+	if len(reports) != 2 || reports["cached"] == nil || reports["a"] == nil {
+		t.Errorf("reports = %v, want the cached hash and the first lookup only", reports)
+	}
+	if got := api.Requests(); len(got) != 1 {
+		t.Errorf("API requests = %v, want 1", got)
+	}
+}
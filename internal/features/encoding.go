@@ -0,0 +1,119 @@
+/*
+ * @Date: 2025-05-29 16:40:12
+ * @Editors: Mr wpl
+ * @Description: 文件编码检测与转码 (GBK/GB2312/Latin-1 -> UTF-8)
+ */
+package features
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+const (
+	EncodingUTF8    = "UTF-8"
+	EncodingUTF16LE = "UTF-16LE"
+	EncodingUTF16BE = "UTF-16BE"
+	EncodingGB2312  = "GB2312"
+	EncodingGBK     = "GBK"
+	EncodingLatin1  = "ISO-8859-1"
+)
+
+// gbkPairRatio 高位字节中组成合法 GBK 双字节字符的比例阈值，达到则判定为 GBK
+const gbkPairRatio = 0.95
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+/**
+ * @Description: 检测文件编码并转码为 UTF-8。先检查 BOM，再检查是否为合法 UTF-8，
+ *               否则根据高位字节的分布判断 GBK/GB2312，其余按 Latin-1 处理
+ * @author: Mr wpl
+ * @param content []byte: 原始文件内容
+ * @return []byte: UTF-8 内容 (出错时为原始内容)
+ * @return string: 检测到的编码
+ * @return error: 转码失败时返回错误
+ */
+func DetectAndTranscode(content []byte) ([]byte, string, error) {
+	var enc encoding.Encoding
+	var detected string
+
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		return content[len(bomUTF8):], EncodingUTF8, nil
+	case bytes.HasPrefix(content, bomUTF16LE):
+		enc, detected = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), EncodingUTF16LE
+	case bytes.HasPrefix(content, bomUTF16BE):
+		enc, detected = unicode.UTF16(unicode.BigEndian, unicode.UseBOM), EncodingUTF16BE
+	case utf8.Valid(content):
+		return content, EncodingUTF8, nil
+	default:
+		detected = detectLegacyEncoding(content)
+		if detected == EncodingLatin1 {
+			enc = charmap.ISO8859_1
+		} else {
+			enc = simplifiedchinese.GBK // GB2312 是 GBK 的子集
+		}
+	}
+
+	decoded, _, err := transform.Bytes(enc.NewDecoder(), content)
+	if err != nil {
+		return content, detected, fmt.Errorf("transcode from %s failed: %w", detected, err)
+	}
+	return decoded, detected, nil
+}
+
+/**
+ * @Description: 统计高位字节的分布，判断非 UTF-8 内容是 GB2312、GBK 还是 Latin-1
+ * @author: Mr wpl
+ * @param content []byte: 原始文件内容
+ * @return string: 编码名称
+ */
+func detectLegacyEncoding(content []byte) string {
+	var highBytes, gbkBytes, gb2312Bytes int
+	for i := 0; i < len(content); i++ {
+		lead := content[i]
+		if lead < 0x80 {
+			continue
+		}
+		if i+1 < len(content) && isGBKPair(lead, content[i+1]) {
+			highBytes += 2
+			gbkBytes += 2
+			if lead >= 0xA1 && lead <= 0xF7 && content[i+1] >= 0xA1 && content[i+1] <= 0xFE {
+				gb2312Bytes += 2
+			}
+			i++
+			continue
+		}
+		highBytes++
+	}
+
+	if highBytes == 0 || float64(gbkBytes)/float64(highBytes) < gbkPairRatio {
+		return EncodingLatin1
+	}
+	if gb2312Bytes == gbkBytes {
+		return EncodingGB2312
+	}
+	return EncodingGBK
+}
+
+/**
+ * @Description: 判断两个字节是否组成合法的 GBK 双字节字符
+ * @author: Mr wpl
+ * @param lead byte: 首字节
+ * @param trail byte: 尾字节
+ * @return bool: 是否合法
+ */
+func isGBKPair(lead, trail byte) bool {
+	return lead >= 0x81 && lead <= 0xFE && trail >= 0x40 && trail <= 0xFE && trail != 0x7F
+}
@@ -0,0 +1,103 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 编码检测与转码测试：含中文变量名的 GBK/GB2312 PHP 文件、Latin-1、UTF-8/UTF-16 BOM 与合法 UTF-8
+ */
+package features
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// 只含 GB2312 字符的中文变量名
+const gb2312PHP = "<?php\n$用户名 = $_POST['name'];\n$密码 = base64_decode($用户名);\neval($密码);\n"
+
+// "镕" 不在 GB2312 中，只能按 GBK 解码
+const gbkPHP = "<?php\n$用户名 = $_POST['name'];\n$镕 = base64_decode($用户名);\neval($镕);\n"
+
+const latin1PHP = "<?php\n// Café résumé\n$naïve = $_GET['q'];\necho $naïve;\n"
+
+// mustEncode 用 enc 将 UTF-8 文本编码为测试输入
+func mustEncode(t *testing.T, enc encoding.Encoding, s string) []byte {
+	t.Helper()
+	b, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatalf("encode test input: %v", err)
+	}
+	return b
+}
+
+func TestDetectAndTranscode(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        []byte
+		wantEncoding string
+		want         string
+	}{
+		{"GBK with Chinese variable names", mustEncode(t, simplifiedchinese.GBK, gbkPHP), EncodingGBK, gbkPHP},
+		{"GB2312 with Chinese variable names", mustEncode(t, simplifiedchinese.GBK, gb2312PHP), EncodingGB2312, gb2312PHP},
+		{"Latin-1", mustEncode(t, charmap.ISO8859_1, latin1PHP), EncodingLatin1, latin1PHP},
+		{"UTF-8 BOM", append([]byte("\xef\xbb\xbf"), gbkPHP...), EncodingUTF8, gbkPHP},
+		{"UTF-16LE BOM", mustEncode(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), gbkPHP), EncodingUTF16LE, gbkPHP},
+		{"UTF-16BE BOM", mustEncode(t, unicode.UTF16(unicode.BigEndian, unicode.UseBOM), gbkPHP), EncodingUTF16BE, gbkPHP},
+		{"valid UTF-8 with Chinese", []byte(gbkPHP), EncodingUTF8, gbkPHP},
+		{"ASCII", []byte("<?php echo 'hello';"), EncodingUTF8, "<?php echo 'hello';"},
+		{"empty", []byte{}, EncodingUTF8, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, enc, err := DetectAndTranscode(tt.input)
+			if err != nil {
+				t.Fatalf("DetectAndTranscode() error = %v", err)
+			}
+			if enc != tt.wantEncoding {
+				t.Errorf("encoding = %s, want %s", enc, tt.wantEncoding)
+			}
+			if string(got) != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDetectAndTranscodeKeepsUTF8 合法 UTF-8 原样返回，不复制内容
+func TestDetectAndTranscodeKeepsUTF8(t *testing.T) {
+	input := []byte(gbkPHP)
+	got, _, err := DetectAndTranscode(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 || &got[0] != &input[0] {
+		t.Error("valid UTF-8 input was copied")
+	}
+	if !bytes.Equal(input, []byte(gbkPHP)) {
+		t.Error("input was modified")
+	}
+}
+
+func TestDetectLegacyEncoding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"GB2312 pairs only", mustEncode(t, simplifiedchinese.GBK, "用户名密码"), EncodingGB2312},
+		{"GBK extension pair", mustEncode(t, simplifiedchinese.GBK, "镕"), EncodingGBK},
+		{"isolated high bytes", []byte("caf\xe9 na\xefve"), EncodingLatin1},
+		// 少量孤立的高位字节使 GBK 比例低于 gbkPairRatio
+		{"mostly GBK with stray bytes", append(mustEncode(t, simplifiedchinese.GBK, "用户"), " \xff \xff"...), EncodingLatin1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectLegacyEncoding(tt.input); got != tt.want {
+				t.Errorf("detectLegacyEncoding(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -9,7 +9,12 @@ import (
 	"bt-shieldml/internal/ast"
 	"bt-shieldml/pkg/logging"
 	"bt-shieldml/pkg/types"
+	"bytes"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 )
 
 // ExtractAllFeatures 协调各种特征的提取。
@@ -19,64 +24,163 @@ func ExtractAllFeatures(fileInfo types.FileInfo, content []byte, goAST interface
 	fs := &FeatureSet{
 		RawAST: goAST, // Store raw AST
 	}
-	var errs []error // Collect errors
 
 	// 1. Statistical Features (calculated directly using functions in this package)
-	if len(content) > 0 {
-		// logging.InfoLogger.Printf("Extracting statistical features for %s", fileInfo.Path)
-		// Call the function directly as it's now in the 'features' package
-		calculatedStats := CalculateStatisticalFeatures(content)
-		fs.Statistical = &calculatedStats
-	} else {
+	fs.Statistical = extractStatistical(fileInfo, content)
+	fs.SuperGlobalCounts, fs.TotalSuperGlobalAccesses = CountSuperGlobals(content)
+
+	// 2. AST-based Features (only if AST is available and manager is provided)
+	errs := extractASTFeatures(fs, fileInfo, content, goAST, astMgr)
+
+	return fs, combineErrors(errs)
+}
+
+// ASTInfo 并行提取时 AST 生成的结果，供调用方记录日志和部分 AST 标记
+type ASTInfo struct {
+	TruncatedLines int           // 为恢复语法错误从文件末尾去掉的行数 (完整解析时为 0)
+	Err            error         // AST 生成失败的原因，为 nil 表示成功或未生成
+	Duration       time.Duration // AST 生成耗时
+}
+
+/**
+ * @Description: 并行提取特征：统计特征 (CPU 密集) 与 AST 生成及 AST 特征 (等待 PHP 桥接) 同时进行。
+ * AST 生成失败时仍返回统计特征
+ * @author: Mr wpl
+ * @param fileInfo types.FileInfo: 文件信息
+ * @param content []byte: 文件内容
+ * @param astMgr ast.ASTManager: AST 管理器，为 nil 时只提取统计特征
+ * @return *FeatureSet: 特征集
+ * @return ASTInfo: AST 生成结果
+ * @return error: 特征提取错误 (不含 AST 生成失败)
+ */
+func ExtractAllFeaturesParallel(fileInfo types.FileInfo, content []byte, astMgr ast.ASTManager) (*FeatureSet, ASTInfo, error) {
+	fs := &FeatureSet{}
+	var info ASTInfo
+	var errs []error
+
+	// 统计特征与超全局变量统计只依赖内容，在同一 goroutine 中计算
+	statsChan := make(chan *StatisticalFeatures, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		statsChan <- extractStatistical(fileInfo, content)
+		fs.SuperGlobalCounts, fs.TotalSuperGlobalAccesses = CountSuperGlobals(content)
+	}()
+
+	// 当前 goroutine 负责 AST：生成后依次提取词汇/可调用状态和操作序列
+	if astMgr != nil {
+		astStart := time.Now()
+		goAST, truncatedLines, astErr := ast.GetASTWithRecovery(astMgr, content)
+		info = ASTInfo{TruncatedLines: truncatedLines, Err: astErr, Duration: time.Since(astStart)}
+		if astErr == nil {
+			fs.RawAST = goAST
+			errs = extractASTFeatures(fs, fileInfo, content, goAST, astMgr)
+		}
+	}
+
+	wg.Wait()
+	fs.Statistical = <-statsChan
+
+	return fs, info, combineErrors(errs)
+}
+
+/**
+ * @Description: 计算统计特征，空文件返回 nil
+ * @author: Mr wpl
+ * @param fileInfo types.FileInfo: 文件信息
+ * @param content []byte: 文件内容
+ * @return *StatisticalFeatures: 统计特征
+ */
+func extractStatistical(fileInfo types.FileInfo, content []byte) *StatisticalFeatures {
+	if len(content) == 0 {
 		// Handle empty content - statistical features will be nil
 		logging.InfoLogger.Printf("Skipping statistical feature calculation for empty file: %s", fileInfo.Path)
+		return nil
+	}
+	calculatedStats, err := CalculateStatisticalFeatures(bytes.NewReader(content), FileTypeOf(fileInfo.Path))
+	if err != nil {
+		logging.WarnLogger.Printf("Statistical feature calculation failed for %s: %v", fileInfo.Path, err)
+		return nil
 	}
+	return &calculatedStats
+}
 
-	// 2. AST-based Features (only if AST is available and manager is provided)
-	if goAST != nil && astMgr != nil {
-
-		// Extract Words and Callable status
-		words, callable, wordsErr := astMgr.GetWordsAndCallable(goAST)
-		if wordsErr != nil {
-			logging.WarnLogger.Printf("Could not extract words/callable from AST for %s: %v", fileInfo.Path, wordsErr)
-			errs = append(errs, fmt.Errorf("ast words/callable extraction failed: %w", wordsErr))
-			// fs.ASTWords remains nil
-			// fs.Callable remains false (default)
-		} else {
-			fs.ASTWords = words
-			fs.Callable = callable // Set the extracted callable status
-		}
+/**
+ * @Description: 从 AST 提取词汇、可调用状态、操作序列和混淆分数，写入 fs
+ * @author: Mr wpl
+ * @param fs *FeatureSet: 特征集
+ * @param fileInfo types.FileInfo: 文件信息
+ * @param content []byte: 文件内容 (用于提取 heredoc)
+ * @param goAST interface{}: 解析后的 AST
+ * @param astMgr ast.ASTManager: AST 管理器
+ * @return []error: 提取过程中的错误
+ */
+func extractASTFeatures(fs *FeatureSet, fileInfo types.FileInfo, content []byte, goAST interface{}, astMgr ast.ASTManager) []error {
+	var errs []error // Collect errors
+
+	if goAST == nil {
+		logging.InfoLogger.Printf("Skipping AST-based feature extraction for %s (no AST available)", fileInfo.Path)
+		return nil
+	}
+	if astMgr == nil {
+		logging.WarnLogger.Printf("Skipping AST-based feature extraction for %s (AST Manager not provided to extractor)", fileInfo.Path)
+		return []error{fmt.Errorf("ast manager was nil during feature extraction")}
+	}
 
-		// Extract Operation Sequence
-		opSeq, opSeqErr := astMgr.GetOpSerial(goAST)
-		if opSeqErr != nil {
-			logging.WarnLogger.Printf("Could not extract op sequence from AST for %s: %v", fileInfo.Path, opSeqErr)
-			errs = append(errs, fmt.Errorf("ast op sequence extraction failed: %w", opSeqErr))
-		} else {
-			fs.ASTOpSequence = opSeq
-			// logging.InfoLogger.Printf("AST OpSequence (%d sequences) extracted for %s", len(fs.ASTOpSequence), fileInfo.Path)
+	// Extract Words and Callable status
+	words, callable, wordsErr := astMgr.GetWordsAndCallable(goAST)
+	if wordsErr != nil {
+		logging.WarnLogger.Printf("Could not extract words/callable from AST for %s: %v", fileInfo.Path, wordsErr)
+		errs = append(errs, fmt.Errorf("ast words/callable extraction failed: %w", wordsErr))
+		// fs.ASTWords remains nil
+		// fs.Callable remains false (default)
+	} else {
+		// heredoc/nowdoc 内容在 AST 中是不透明的字符串节点，单独提取后拆分成词追加
+		for _, body := range ast.ExtractHeredocContents(content) {
+			words = append(words, splitWords(body)...)
 		}
+		fs.ASTWords = words
+		fs.Callable = callable // Set the extracted callable status
+	}
 
-	} else if goAST == nil {
-		logging.InfoLogger.Printf("Skipping AST-based feature extraction for %s (no AST available)", fileInfo.Path)
+	// Extract Operation Sequence
+	opSeq, opSeqErr := astMgr.GetOpSerial(goAST)
+	if opSeqErr != nil {
+		logging.WarnLogger.Printf("Could not extract op sequence from AST for %s: %v", fileInfo.Path, opSeqErr)
+		errs = append(errs, fmt.Errorf("ast op sequence extraction failed: %w", opSeqErr))
 	} else {
-		logging.WarnLogger.Printf("Skipping AST-based feature extraction for %s (AST Manager not provided to extractor)", fileInfo.Path)
-		errs = append(errs, fmt.Errorf("ast manager was nil during feature extraction"))
+		fs.ASTOpSequence = opSeq
+	}
 
+	// 结构混淆分数：动态变量名、编码字符串、调用嵌套深度
+	obfuscation, obfErr := ast.MeasureObfuscation(goAST)
+	if obfErr != nil {
+		errs = append(errs, fmt.Errorf("ast obfuscation measurement failed: %w", obfErr))
+	} else {
+		fs.ObfuscationScore = obfuscation.Score()
 	}
+	return errs
+}
 
-	// Combine errors if needed
-	var combinedErr error
-	if len(errs) > 0 {
-		errMsg := ""
-		for i, e := range errs {
-			errMsg += e.Error()
-			if i < len(errs)-1 {
-				errMsg += "; "
-			}
+// combineErrors 将多个提取错误合并为一个，无错误时返回 nil
+func combineErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	errMsg := ""
+	for i, e := range errs {
+		errMsg += e.Error()
+		if i < len(errs)-1 {
+			errMsg += "; "
 		}
-		combinedErr = fmt.Errorf("feature extraction encountered errors: %s", errMsg)
 	}
+	return fmt.Errorf("feature extraction encountered errors: %s", errMsg)
+}
 
-	return fs, combinedErr
+// splitWords 按非标识符字符拆分字符串
+func splitWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+	})
 }
@@ -8,14 +8,79 @@ package features
  * @Description: 待提取特征列表信息
  */
 
+import (
+	"bt-shieldml/pkg/logging"
+	"context"
+)
+
 // FeatureSet holds all extracted features for a file.
 type FeatureSet struct {
 	Statistical   *StatisticalFeatures // Pointer to allow nil if not calculated
 	ASTWords      []string             // Extracted words from AST
 	ASTOpSequence [][]int              // Extracted operation sequences from AST
 	Callable      bool                 // Flag indicating if critical callable functions were found in AST
+	// SuperGlobalCounts 超全局变量的下标访问次数，如 {"_POST": 5, "_GET": 2}，未提取时为 nil
+	SuperGlobalCounts        map[string]int
+	TotalSuperGlobalAccesses int // SuperGlobalCounts 的总和
+	// ObfuscationScore AST 结构混淆分数 (0~1，见 ast.ObfuscationMetrics.Score)，仅在 AST 可用时计算
+	ObfuscationScore float64
 	// Add more feature categories as needed
 	RawAST interface{} // Store the parsed Go AST if needed by multiple analyzers
+	// Context 携带扫描 ID 与文件路径，分析器可通过 logging.FromContext(Context) 获取带前缀的日志器
+	Context context.Context
+	// RawContent 转码 (GBK、Latin-1、去除 BOM 等) 前的原始文件字节，只读；hash、ssdeep 等样本库按原始字节计算
+	RawContent []byte
+}
+
+// OriginalContent 返回转码前的原始文件字节，未设置 RawContent 时 (内容未转码或 fs 为 nil) 返回 content
+func (fs *FeatureSet) OriginalContent(content []byte) []byte {
+	if fs == nil || fs.RawContent == nil {
+		return content
+	}
+	return fs.RawContent
+}
+
+// Logger 返回绑定 Context 中扫描 ID 与文件路径的日志器，featureSet 为 nil 时同样可用
+func (fs *FeatureSet) Logger() *logging.ContextLogger {
+	if fs == nil {
+		return logging.FromContext(nil)
+	}
+	return logging.FromContext(fs.Context)
+}
+
+/**
+ * @Description: 深拷贝特征集，供并发执行的分析器各自持有一份，互不影响。
+ * RawAST 与 RawContent 视为只读，仅浅拷贝；Context 共享
+ * @author: Mr wpl
+ * @return *FeatureSet: 特征集副本，fs 为 nil 时返回 nil
+ */
+func (fs *FeatureSet) Clone() *FeatureSet {
+	if fs == nil {
+		return nil
+	}
+	clone := *fs
+	if fs.Statistical != nil {
+		statistical := *fs.Statistical
+		clone.Statistical = &statistical
+	}
+	if fs.ASTWords != nil {
+		clone.ASTWords = append([]string(nil), fs.ASTWords...)
+	}
+	if fs.ASTOpSequence != nil {
+		clone.ASTOpSequence = make([][]int, len(fs.ASTOpSequence))
+		for i, seq := range fs.ASTOpSequence {
+			if seq != nil {
+				clone.ASTOpSequence[i] = append([]int(nil), seq...)
+			}
+		}
+	}
+	if fs.SuperGlobalCounts != nil {
+		clone.SuperGlobalCounts = make(map[string]int, len(fs.SuperGlobalCounts))
+		for name, count := range fs.SuperGlobalCounts {
+			clone.SuperGlobalCounts[name] = count
+		}
+	}
+	return &clone
 }
 
 // StatisticalFeatures holds features calculated by statistical analyzer.
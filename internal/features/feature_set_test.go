@@ -0,0 +1,76 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: FeatureSet.Clone 深拷贝测试
+ */
+package features
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// newTestFeatureSet 所有字段均已填充的特征集
+func newTestFeatureSet() *FeatureSet {
+	return &FeatureSet{
+		Statistical:              &StatisticalFeatures{LM: 120, LVC: 0.8, WM: 40, WVC: 55, SR: 31, TR: 2, SPL: 1.5, IE: 5.2},
+		ASTWords:                 []string{"eval", "base64_decode", "_POST"},
+		ASTOpSequence:            [][]int{{1, 2, 3}, {4, 5}},
+		Callable:                 true,
+		SuperGlobalCounts:        map[string]int{"_POST": 2, "_GET": 1},
+		TotalSuperGlobalAccesses: 3,
+		ObfuscationScore:         0.7,
+		RawAST:                   map[string]interface{}{"kind": "AST_STMT_LIST"},
+		Context:                  context.Background(),
+	}
+}
+
+func TestFeatureSetCloneEqual(t *testing.T) {
+	original := newTestFeatureSet()
+	if clone := original.Clone(); !reflect.DeepEqual(clone, original) {
+		t.Errorf("Clone() = %+v, want %+v", clone, original)
+	}
+	if (*FeatureSet)(nil).Clone() != nil {
+		t.Error("Clone() of a nil FeatureSet is not nil")
+	}
+	empty := (&FeatureSet{}).Clone()
+	if empty.ASTWords != nil || empty.ASTOpSequence != nil || empty.SuperGlobalCounts != nil || empty.Statistical != nil {
+		t.Errorf("Clone() of an empty FeatureSet allocated fields: %+v", empty)
+	}
+}
+
+// TestFeatureSetCloneIsDeep 修改副本的各字段后原特征集不变
+func TestFeatureSetCloneIsDeep(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(clone *FeatureSet)
+	}{
+		{"ASTWords element", func(c *FeatureSet) { c.ASTWords[0] = "echo" }},
+		{"ASTWords append", func(c *FeatureSet) { c.ASTWords = append(c.ASTWords[:1], "system") }},
+		{"ASTOpSequence inner element", func(c *FeatureSet) { c.ASTOpSequence[0][1] = 99 }},
+		{"ASTOpSequence outer element", func(c *FeatureSet) { c.ASTOpSequence[1] = []int{7} }},
+		{"Statistical", func(c *FeatureSet) { c.Statistical.IE = 0 }},
+		{"SuperGlobalCounts", func(c *FeatureSet) { c.SuperGlobalCounts["_POST"] = 10; delete(c.SuperGlobalCounts, "_GET") }},
+		{"scalars", func(c *FeatureSet) { c.Callable = false; c.TotalSuperGlobalAccesses = 0; c.ObfuscationScore = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := newTestFeatureSet()
+			tt.mutate(original.Clone())
+			if want := newTestFeatureSet(); !reflect.DeepEqual(original, want) {
+				t.Errorf("original changed after mutating the clone: %+v, want %+v", original, want)
+			}
+		})
+	}
+}
+
+// TestFeatureSetCloneSharesRawAST RawAST 视为只读，副本与原特征集共享同一棵 AST
+func TestFeatureSetCloneSharesRawAST(t *testing.T) {
+	original := newTestFeatureSet()
+	clone := original.Clone()
+	clone.RawAST.(map[string]interface{})["kind"] = "changed"
+	if original.RawAST.(map[string]interface{})["kind"] != "changed" {
+		t.Error("Clone() copied RawAST, want a shallow copy")
+	}
+}
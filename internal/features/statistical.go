@@ -3,37 +3,130 @@ package features
 /*
  * @Author: wpl
  * @Date: 2025-04-15 10:24:13
- * @Description: 统计特征提取，基于CloudWalker实现
+ * @Description: 统计特征提取，基于CloudWalker实现。按流式单遍计算，超大文件无需整体读入内存
  */
 import (
+	"bufio"
+	"io"
 	"math"
-	"regexp"
+	"path/filepath"
 	"strings"
+)
+
+// statBufferSize 流式读取的缓冲区大小
+const statBufferSize = 64 * 1024
+
+// runningStats 流式计算个数、最大值、均值与方差 (Welford 算法)
+type runningStats struct {
+	n    int64
+	max  int64
+	mean float64
+	m2   float64 // 与均值差的平方和
+}
+
+// add 加入一个样本
+func (s *runningStats) add(v int64) {
+	s.n++
+	if s.n == 1 || v > s.max {
+		s.max = v
+	}
+	delta := float64(v) - s.mean
+	s.mean += delta / float64(s.n)
+	s.m2 += delta * (float64(v) - s.mean)
+}
+
+// variationCoefficient 样本标准差 (n-1) 与均值之比，与 CloudWalker 使用的 stat.Variance 一致
+func (s *runningStats) variationCoefficient() float64 {
+	if s.n <= 1 || s.mean == 0 {
+		return 0.0
+	}
+	return math.Sqrt(s.m2/float64(s.n-1)) / s.mean
+}
+
+// statAccumulator 统计特征的流式中间结果
+type statAccumulator struct {
+	bytes      int64        // 总字节数
+	lines      runningStats // 每行字符数 (字节)
+	words      runningStats // 每个单词的长度
+	lineLen    int64        // 当前行已读字节数
+	wordLen    int64        // 当前单词已读字符数
+	symbols    int64        // 非字母数字字符数 (非法UTF-8按字节)
+	statements int64        // ';' 个数
+	tags       int64        // 标签数
+	inTag      bool         // 已遇到 '<' (JSP 模式为 '<%')，等待 '>' (JSP 模式为 '%>')
+	jsp        bool         // JSP 模式：只把 <% ... %> 脚本片段计为标签
+	noTags     bool         // 不统计标签 (Python 等没有模板标签的语言)
+	prev       rune         // 上一个字符，JSP 模式识别 '<%' 与 '%>' 用
+	charCounts [256]float64 // 码点 0-255 (换行除外) 的出现次数
+	chars      float64      // 参与信息熵计算的字符数
+}
 
-	"github.com/grd/stat"
+// 文件类型，决定统计特征的分词方式，见 FileTypeOf
+const (
+	FileTypePHP    = "php"
+	FileTypePython = "python"
 )
 
+// FileTypeOf 按扩展名返回文件类型：.py 为 FileTypePython，其余 (含无扩展名的标准输入) 为 FileTypePHP
+func FileTypeOf(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".py") {
+		return FileTypePython
+	}
+	return FileTypePHP
+}
+
 /**
- * @Description: 计算给定内容的所有 8 个统计特征
+ * @Description: 从 r 流式计算所有 8 个统计特征。fileType 为 FileTypePython 时不统计 PHP/HTML 标签 (TR 为 0)，
+ * Python 代码中的 < > 多为比较运算符，按标签计数会得到无意义的标签比例
  * @author: Mr wpl
- * @param content []byte: 内容
+ * @param r io.Reader: 内容
+ * @param fileType string: 文件类型 (FileTypeOf)，为空时按 PHP 处理
  * @return StatisticalFeatures: 统计特征
+ * @return error: 读取错误
  */
-func CalculateStatisticalFeatures(content []byte) StatisticalFeatures {
+func CalculateStatisticalFeatures(r io.Reader, fileType string) (StatisticalFeatures, error) {
+	return calculateStatistical(r, &statAccumulator{noTags: fileType == FileTypePython})
+}
+
+/**
+ * @Description: 按 JSP 分词从 r 流式计算 8 个统计特征：标签只统计 <% %> 脚本片段 (含 <%= <%@ <%!)，
+ * 不统计 HTML 标签，其余特征与 CalculateStatisticalFeatures 相同
+ * @author: Mr wpl
+ * @param r io.Reader: 内容
+ * @return StatisticalFeatures: 统计特征
+ * @return error: 读取错误
+ */
+func CalculateJSPStatisticalFeatures(r io.Reader) (StatisticalFeatures, error) {
+	return calculateStatistical(r, &statAccumulator{jsp: true})
+}
+
+// calculateStatistical 用 acc 流式处理 r 并计算统计特征
+func calculateStatistical(r io.Reader, acc *statAccumulator) (StatisticalFeatures, error) {
 	var sf StatisticalFeatures
-	src := string(content)
+	br := bufio.NewReaderSize(r, statBufferSize)
+	for {
+		c, size, err := br.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sf, err
+		}
+		acc.add(c, size)
+	}
+	acc.finish()
 
 	// 计算八大统计特征
-	sf.LM = roundToSix(float64(lineMax(src)))
-	sf.LVC = roundToSix(lineVariationCoefficient(src))
-	sf.WM = roundToSix(float64(wordMax(src)))
-	sf.WVC = roundToSix(wordVariationCoefficient(src))
-	sf.SR = roundToSix(symbolRatio(src))
-	sf.TR = roundToSix(tagRatio(src))
-	sf.SPL = roundToSix(statementPerLine(src))
-	sf.IE = roundToSix(infomationEntropy(src))
+	sf.LM = roundToSix(float64(acc.lines.max))
+	sf.LVC = roundToSix(acc.lines.variationCoefficient())
+	sf.WM = roundToSix(float64(acc.words.max))
+	sf.WVC = roundToSix(acc.words.variationCoefficient() * 100) // CloudWalker乘以100
+	sf.SR = roundToSix(acc.symbolRatio())
+	sf.TR = roundToSix(acc.tagRatio())
+	sf.SPL = roundToSix(acc.statementPerLine())
+	sf.IE = roundToSix(acc.infomationEntropy())
 
-	return sf
+	return sf, nil
 }
 
 /**
@@ -48,196 +141,137 @@ func roundToSix(value float64) float64 {
 }
 
 /**
- * @Description: 计算每行字符数
+ * @Description: 处理一个字符 (非法UTF-8为 utf8.RuneError，size 为 1)
  * @author: Mr wpl
- * @param src string: 内容
- * @return []int64: 每行字符数
+ * @param c rune: 字符
+ * @param size int: 字符的字节数
  */
-func statLines(src string) []int64 {
-	var result []int64
-	splitResult := strings.Split(src, "\n")
-	for _, v := range splitResult {
-		result = append(result, int64(len(v)))
+func (a *statAccumulator) add(c rune, size int) {
+	a.bytes += int64(size)
+
+	// 每行字符数：按 '\n' 分割，按字节计
+	if c == '\n' {
+		a.lines.add(a.lineLen)
+		a.lineLen = 0
+	} else {
+		a.lineLen += int64(size)
 	}
-	return result
-}
 
-/**
- * @Description: 计算每行字符数的最大值
- * @author: Mr wpl
- * @param src string: 内容
- * @return int64: 每行字符数的最大值
- */
-func lineMax(src string) int64 {
-	lines := stat.IntSlice(statLines(src))
-	if len(lines) > 0 {
-		result, _ := stat.Max(lines)
-		return int64(result)
+	// 按照CloudWalker的方式提取单词长度，非字母数字即为符号
+	if isAlnum(c) {
+		a.wordLen++
+	} else {
+		a.symbols++
+		if a.wordLen != 0 {
+			a.words.add(a.wordLen)
+			a.wordLen = 0
+		}
 	}
-	return 0
-}
 
-/**
- * @Description: 计算每行字符数的变异系数
- * @author: Mr wpl
- * @param src string: 内容
- * @return float64: 每行字符数的变异系数
- */
-func lineVariationCoefficient(src string) float64 {
-	lines := stat.IntSlice(statLines(src))
-	if len(lines) <= 1 || stat.Mean(lines) == 0 {
-		return 0.0
+	// 等价于CloudWalker的正则 ;
+	if c == ';' {
+		a.statements++
 	}
-	return math.Sqrt(stat.Variance(lines)) / stat.Mean(lines)
-}
 
-/**
- * @Description: 计算单词数
- * @author: Mr wpl
- * @param src string: 内容
- * @return []int64: 单词数
- */
-func statWords(src string) []int64 {
-	var result []int64
-	l := int64(0)
-
-	// 按照CloudWalker的方式提取单词长度
-	for _, c := range src {
-		if (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
-			l++
-		} else if l != 0 {
-			result = append(result, l)
-			l = 0
+	if a.jsp {
+		a.addJSPTag(c)
+	} else if !a.noTags {
+		// 等价于CloudWalker的正则 <[\x00-\xFF]*?>：'<' 之后遇到第一个 '>' 计为一个标签，
+		// 中途出现码点大于 0xFF 的字符 (含非法UTF-8 解码出的 utf8.RuneError) 时该次匹配失败
+		switch {
+		case c == '>' && a.inTag:
+			a.tags++
+			a.inTag = false
+		case c == '<':
+			a.inTag = true
+		case c > 0xFF:
+			a.inTag = false
 		}
 	}
 
-	if l != 0 {
-		result = append(result, l)
+	// 信息熵只统计码点 0-255，不含换行
+	if 0 <= c && c < 256 && c != '\n' {
+		a.charCounts[c]++
+		a.chars++
 	}
-
-	return result
 }
 
-/**
- * @Description: 计算单词数的最大值
- * @author: Mr wpl
- * @param src string: 内容
- * @return int64: 单词数的最大值
- */
-func wordMax(src string) int64 {
-	words := stat.IntSlice(statWords(src))
-	if len(words) > 0 {
-		result, _ := stat.Max(words)
-		return int64(result)
+// addJSPTag JSP 模式的标签计数：'<%' 开始、'%>' 结束的脚本片段计为一个标签
+func (a *statAccumulator) addJSPTag(c rune) {
+	switch {
+	case !a.inTag && a.prev == '<' && c == '%':
+		a.inTag = true
+		c = 0 // '<%>' 中的 '%' 不能同时作为结束标记的一部分
+	case a.inTag && a.prev == '%' && c == '>':
+		a.tags++
+		a.inTag = false
 	}
-	return 0
+	a.prev = c
 }
 
-/**
- * @Description: 计算单词变异系数
- * @author: Mr wpl
- * @param src string: 内容
- * @return float64: 单词变异系数
- */
-func wordVariationCoefficient(src string) float64 {
-	words := stat.IntSlice(statWords(src))
-	if len(words) <= 1 || stat.Mean(words) == 0 {
-		return 0.0
+// finish 结束最后一行和最后一个单词
+func (a *statAccumulator) finish() {
+	a.lines.add(a.lineLen)
+	if a.wordLen != 0 {
+		a.words.add(a.wordLen)
+		a.wordLen = 0
 	}
-	// CloudWalker乘以100
-	return math.Sqrt(stat.Variance(words)) / stat.Mean(words) * 100
+}
+
+// isAlnum 是否为 ASCII 字母或数字
+func isAlnum(c rune) bool {
+	return (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
 }
 
 /**
  * @Description: 计算符号比例
  * @author: Mr wpl
- * @param src string: 内容
  * @return float64: 符号比例
  */
-func symbolRatio(src string) float64 {
-	if len(src) == 0 {
+func (a *statAccumulator) symbolRatio() float64 {
+	if a.bytes == 0 {
 		return 0.0
 	}
-
-	// 使用与CloudWalker相同的正则表达式
-	symbolReg, _ := regexp.Compile(`[^a-zA-Z0-9]`)
-	symbolNumber := len(symbolReg.FindAllString(src, -1))
-
-	return float64(symbolNumber) / float64(len(src)) * 100
+	return float64(a.symbols) / float64(a.bytes) * 100
 }
 
 /**
  * @Description: 计算标签比例
  * @author: Mr wpl
- * @param src string: 内容
  * @return float64: 标签比例
  */
-func tagRatio(src string) float64 {
-	// 使用与CloudWalker相同的正则表达式
-	tagReg, _ := regexp.Compile(`<[\x00-\xFF]*?>`)
-	tagNumber := len(tagReg.FindAllString(src, -1))
-
-	words := statWords(src)
-	wordCount := float64(len(words))
-	if wordCount == 0.0 {
+func (a *statAccumulator) tagRatio() float64 {
+	if a.words.n == 0 {
 		return 0.0
 	}
-
-	return float64(tagNumber) / wordCount * 100
+	return float64(a.tags) / float64(a.words.n) * 100
 }
 
 /**
  * @Description: 计算语句比例
  * @author: Mr wpl
- * @param src string: 内容
  * @return float64: 语句比例
  */
-func statementPerLine(src string) float64 {
-	// 使用与CloudWalker相同的正则表达式
-	statementReg, _ := regexp.Compile(`;`)
-	statementNumber := len(statementReg.FindAllString(src, -1))
-
-	lines := statLines(src)
-	lineCount := float64(len(lines))
-	if lineCount == 0.0 {
+func (a *statAccumulator) statementPerLine() float64 {
+	if a.lines.n == 0 {
 		return 0.0
 	}
-
-	return float64(statementNumber) / lineCount
+	return float64(a.statements) / float64(a.lines.n)
 }
 
 /**
  * @Description: 计算信息熵
  * @author: Mr wpl
- * @param src string: 内容
  * @return float64: 信息熵
  */
-func infomationEntropy(src string) float64 {
+func (a *statAccumulator) infomationEntropy() float64 {
 	// 使用与CloudWalker相同的熵计算方法
-	var lst []float64
-	chrs := 0.00
-
-	// 初始化频率数组
-	for i := 0; i < 256; i++ {
-		lst = append(lst, 0)
-	}
-
-	// 计算字符频率
-	for _, chr := range src {
-		if 0 <= chr && chr < 256 && chr != '\n' {
-			lst[chr]++
-			chrs++
-		}
-	}
-
-	// 计算熵
 	var entropy float64
 	for i := 0; i < 256; i++ {
-		if lst[i] > 0 {
-			probability := lst[i] / chrs
+		if a.charCounts[i] > 0 {
+			probability := a.charCounts[i] / a.chars
 			entropy -= probability * math.Log2(probability)
 		}
 	}
-
 	return entropy
 }
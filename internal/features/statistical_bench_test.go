@@ -0,0 +1,247 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 统计特征基准测试：单遍流式实现与原多遍实现 (每次调用编译正则、三次 statWords) 的对比
+ */
+package features
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// statBenchSizes 基准测试的输入大小
+var statBenchSizes = []struct {
+	name string
+	size int
+}{
+	{"1KB", 1 << 10},
+	{"100KB", 100 << 10},
+	{"1MB", 1 << 20},
+}
+
+// statBenchContent 生成约 size 字节、混合 PHP 与 HTML 的 ASCII 内容
+func statBenchContent(size int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<?php\n")
+	for i := 0; buf.Len() < size; i++ {
+		fmt.Fprintf(&buf, "<div class=\"row-%d\"><?php echo htmlspecialchars($rows[%d]['title']); ?></div>\n", i, i)
+		fmt.Fprintf(&buf, "$total_%d = array_sum(array_map('intval', explode(',', $input)));\n", i)
+		if i%7 == 0 {
+			buf.WriteString("$blob = 'ZXZhbCgkX1BPU1RbJ2NtZCddKTs7ZXZhbCgkX1BPU1RbJ2NtZCddKTs=';\n")
+		}
+	}
+	return buf.Bytes()[:size]
+}
+
+// ---- 原多遍实现 (synth-1631 之前)，只用于对比输出与性能 ----
+
+func baselineStatistical(src string) StatisticalFeatures {
+	return StatisticalFeatures{
+		LM:  roundToSix(float64(baselineMax(baselineLines(src)))),
+		LVC: roundToSix(baselineVariationCoefficient(baselineLines(src))),
+		WM:  roundToSix(float64(baselineMax(baselineWords(src)))),
+		WVC: roundToSix(baselineVariationCoefficient(baselineWords(src)) * 100),
+		SR:  roundToSix(baselineSymbolRatio(src)),
+		TR:  roundToSix(baselineTagRatio(src)),
+		SPL: roundToSix(baselineStatementPerLine(src)),
+		IE:  roundToSix(baselineEntropy(src)),
+	}
+}
+
+func baselineLines(src string) []int64 {
+	var result []int64
+	for _, v := range strings.Split(src, "\n") {
+		result = append(result, int64(len(v)))
+	}
+	return result
+}
+
+func baselineWords(src string) []int64 {
+	var result []int64
+	l := int64(0)
+	for _, c := range src {
+		if isAlnum(c) {
+			l++
+		} else if l != 0 {
+			result = append(result, l)
+			l = 0
+		}
+	}
+	if l != 0 {
+		result = append(result, l)
+	}
+	return result
+}
+
+func baselineMax(values []int64) int64 {
+	var max int64
+	for i, v := range values {
+		if i == 0 || v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// baselineVariationCoefficient 样本标准差 (n-1) 与均值之比，两遍计算
+func baselineVariationCoefficient(values []int64) float64 {
+	if len(values) <= 1 {
+		return 0.0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0.0
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (float64(v) - mean) * (float64(v) - mean)
+	}
+	return math.Sqrt(sq/float64(len(values)-1)) / mean
+}
+
+func baselineSymbolRatio(src string) float64 {
+	if len(src) == 0 {
+		return 0.0
+	}
+	symbolReg, _ := regexp.Compile(`[^a-zA-Z0-9]`)
+	return float64(len(symbolReg.FindAllString(src, -1))) / float64(len(src)) * 100
+}
+
+func baselineTagRatio(src string) float64 {
+	tagReg, _ := regexp.Compile(`<[\x00-\xFF]*?>`)
+	tags := len(tagReg.FindAllString(src, -1))
+	words := float64(len(baselineWords(src)))
+	if words == 0 {
+		return 0.0
+	}
+	return float64(tags) / words * 100
+}
+
+func baselineStatementPerLine(src string) float64 {
+	statementReg, _ := regexp.Compile(`;`)
+	statements := len(statementReg.FindAllString(src, -1))
+	return float64(statements) / float64(len(baselineLines(src)))
+}
+
+func baselineEntropy(src string) float64 {
+	var counts [256]float64
+	chars := 0.0
+	for _, c := range src {
+		if 0 <= c && c < 256 && c != '\n' {
+			counts[c]++
+			chars++
+		}
+	}
+	var entropy float64
+	for _, n := range counts {
+		if n > 0 {
+			p := n / chars
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// TestStatisticalMatchesBaseline 优化后的单遍实现与原实现的输出一致 (方差算法不同，允许末位舍入差异)
+func TestStatisticalMatchesBaseline(t *testing.T) {
+	inputs := map[string][]byte{
+		"empty":      {},
+		"one line":   []byte("<?php echo 'x';"),
+		"html tags":  []byte("<html><body><?php eval($_POST['a']); ?></body></html>\n\n<p>end</p>"),
+		"no newline": []byte("$a=1;$b=2;$c=3;"),
+	}
+	for _, s := range statBenchSizes {
+		inputs[s.name] = statBenchContent(s.size)
+	}
+
+	for name, content := range inputs {
+		t.Run(name, func(t *testing.T) {
+			got, err := CalculateStatisticalFeatures(bytes.NewReader(content), FileTypePHP)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := baselineStatistical(string(content))
+			pairs := []struct {
+				name      string
+				got, want float64
+			}{
+				{"LM", got.LM, want.LM}, {"LVC", got.LVC, want.LVC},
+				{"WM", got.WM, want.WM}, {"WVC", got.WVC, want.WVC},
+				{"SR", got.SR, want.SR}, {"TR", got.TR, want.TR},
+				{"SPL", got.SPL, want.SPL}, {"IE", got.IE, want.IE},
+			}
+			for _, p := range pairs {
+				if math.Abs(p.got-p.want) > 2e-6 {
+					t.Errorf("%s = %v, baseline %v", p.name, p.got, p.want)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCalculateStatisticalFeatures 单遍流式计算全部 8 个特征
+func BenchmarkCalculateStatisticalFeatures(b *testing.B) {
+	for _, s := range statBenchSizes {
+		content := statBenchContent(s.size)
+		b.Run(s.name, func(b *testing.B) {
+			b.SetBytes(int64(len(content)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := CalculateStatisticalFeatures(bytes.NewReader(content), FileTypePHP); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCalculateStatisticalFeaturesBaseline 原多遍实现计算全部 8 个特征，与上面对比得到加速比
+func BenchmarkCalculateStatisticalFeaturesBaseline(b *testing.B) {
+	for _, s := range statBenchSizes {
+		src := string(statBenchContent(s.size))
+		b.Run(s.name, func(b *testing.B) {
+			b.SetBytes(int64(len(src)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				baselineStatistical(src)
+			}
+		})
+	}
+}
+
+// BenchmarkStatisticalFeatureBaseline 原实现中每个特征函数单独的耗时，显示热点 (正则编译与重复分词)
+func BenchmarkStatisticalFeatureBaseline(b *testing.B) {
+	featureFuncs := []struct {
+		name string
+		fn   func(string) float64
+	}{
+		{"LM", func(s string) float64 { return float64(baselineMax(baselineLines(s))) }},
+		{"LVC", func(s string) float64 { return baselineVariationCoefficient(baselineLines(s)) }},
+		{"WM", func(s string) float64 { return float64(baselineMax(baselineWords(s))) }},
+		{"WVC", func(s string) float64 { return baselineVariationCoefficient(baselineWords(s)) }},
+		{"SR", baselineSymbolRatio},
+		{"TR", baselineTagRatio},
+		{"SPL", baselineStatementPerLine},
+		{"IE", baselineEntropy},
+	}
+	for _, f := range featureFuncs {
+		for _, s := range statBenchSizes {
+			src := string(statBenchContent(s.size))
+			b.Run(f.name+"/"+s.name, func(b *testing.B) {
+				b.SetBytes(int64(len(src)))
+				for i := 0; i < b.N; i++ {
+					f.fn(src)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * @Date: 2025-06-19 14:08:35
+ * @Editors: Mr wpl
+ * @Description: 超全局变量访问统计：正常框架文件通常只读取 $_GET 一两次，webshell 往往访问几乎全部超全局变量
+ */
+package features
+
+import (
+	"regexp"
+	"sort"
+)
+
+// superGlobalReg 以下标方式访问的超全局变量，如 $_POST['cmd']
+var superGlobalReg = regexp.MustCompile(`\$_(POST|GET|REQUEST|COOKIE|SERVER|FILES|ENV)\s*\[`)
+
+/**
+ * @Description: 统计各超全局变量的下标访问次数
+ * @author: Mr wpl
+ * @param content []byte: 文件内容
+ * @return map[string]int: 变量名 (如 "_POST") → 访问次数，没有访问时为空 map
+ * @return int: 总访问次数
+ */
+func CountSuperGlobals(content []byte) (map[string]int, int) {
+	counts := make(map[string]int)
+	total := 0
+	for _, m := range superGlobalReg.FindAllSubmatch(content, -1) {
+		counts["_"+string(m[1])]++
+		total++
+	}
+	return counts, total
+}
+
+// SortedSuperGlobals 按访问次数降序 (次数相同按名称) 返回访问过的超全局变量名
+func SortedSuperGlobals(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,122 @@
+/*
+ * @Date: 2025-05-28 09:36:20
+ * @Editors: Mr wpl
+ * @Description: 误报/漏报反馈记录 (data/feedback.jsonl)
+ */
+package feedback
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPath 默认反馈文件路径
+const DefaultPath = "data/feedback.jsonl"
+
+// 反馈标签，与 Bayes Words 模型的类别一致
+const (
+	LabelNormal   = "normal"
+	LabelWebshell = "webshell"
+)
+
+// Entry 一条反馈记录
+type Entry struct {
+	Path         string    `json:"path"`
+	CorrectLabel string    `json:"correct_label"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+var appendLock sync.Mutex
+
+/**
+ * @Description: 校验反馈内容
+ * @author: Mr wpl
+ * @return error: 路径为空或标签非法时返回错误
+ */
+func (e Entry) Validate() error {
+	if strings.TrimSpace(e.Path) == "" {
+		return fmt.Errorf("path is required")
+	}
+	if e.CorrectLabel != LabelNormal && e.CorrectLabel != LabelWebshell {
+		return fmt.Errorf("correct_label must be %q or %q", LabelNormal, LabelWebshell)
+	}
+	return nil
+}
+
+/**
+ * @Description: 追加一条反馈到 jsonl 文件
+ * @author: Mr wpl
+ * @param path string: 反馈文件路径
+ * @param entry Entry: 反馈记录
+ * @return error: 错误
+ */
+func Append(path string, entry Entry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode feedback: %w", err)
+	}
+
+	appendLock.Lock()
+	defer appendLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create feedback directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open feedback file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write feedback: %w", err)
+	}
+	return nil
+}
+
+/**
+ * @Description: 读取反馈文件中的全部记录，跳过空行
+ * @author: Mr wpl
+ * @param path string: 反馈文件路径
+ * @return []Entry: 反馈记录
+ * @return error: 错误 (含出错行号)
+ */
+func ReadAll(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open feedback file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("invalid feedback at line %d: %w", lineNo, err)
+		}
+		if err := entry.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid feedback at line %d: %w", lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read feedback file: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,294 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: gRPC 服务 (api/proto/shield.proto)：封装 engine.Engine，流式返回每个文件的扫描结果，支持双向 TLS 与反射 (grpcurl)
+ */
+package grpc
+
+import (
+	shieldpb "bt-shieldml/api/proto"
+	"bt-shieldml/internal/engine"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultAddr gRPC 服务的默认监听地址，只接受本机连接
+const DefaultAddr = "127.0.0.1:50051"
+
+// TLSConfig gRPC 服务的 TLS 配置，CertFile 为空时使用明文
+type TLSConfig struct {
+	CertFile     string // 服务端证书 (PEM)
+	KeyFile      string // 服务端私钥 (PEM)
+	ClientCAFile string // 非空时要求客户端证书并用该 CA 校验 (双向 TLS)
+}
+
+/**
+ * @Description: ShieldService 的实现
+ * @author: Mr wpl
+ */
+type Server struct {
+	shieldpb.UnimplementedShieldServiceServer
+	engine      *engine.Engine
+	activeScans atomic.Int64
+	started     time.Time
+}
+
+/**
+ * @Description: 创建 gRPC 服务实现，引擎由调用方负责关闭
+ * @author: Mr wpl
+ * @param e *engine.Engine: 已初始化的引擎
+ * @return *Server: 服务实现
+ */
+func NewServer(e *engine.Engine) *Server {
+	return &Server{engine: e, started: time.Now()}
+}
+
+/**
+ * @Description: 创建 grpc.Server，注册 ShieldService 与反射服务。tlsCfg.CertFile 为空时使用明文
+ * @author: Mr wpl
+ * @param svc *Server: 服务实现
+ * @param tlsCfg TLSConfig: TLS 配置
+ * @return *grpclib.Server: gRPC 服务
+ * @return error: 证书加载失败时返回错误
+ */
+func NewGRPCServer(svc *Server, tlsCfg TLSConfig) (*grpclib.Server, error) {
+	var opts []grpclib.ServerOption
+	if tlsCfg.CertFile != "" {
+		creds, err := loadServerCredentials(tlsCfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpclib.Creds(creds))
+	}
+	s := grpclib.NewServer(opts...)
+	shieldpb.RegisterShieldServiceServer(s, svc)
+	reflection.Register(s)
+	return s, nil
+}
+
+/**
+ * @Description: 在 addr 上监听并提供 gRPC 服务，直到 grpc.Server 停止
+ * @author: Mr wpl
+ * @param s *grpclib.Server: gRPC 服务
+ * @param addr string: 监听地址 (host:port)
+ * @return error: 监听失败或服务异常停止时返回错误
+ */
+func Serve(s *grpclib.Server, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return s.Serve(listener)
+}
+
+/**
+ * @Description: 检查监听地址。gRPC 接口没有认证且可扫描服务器上的任意路径，
+ * 未配置 TLS 证书时只允许监听回环地址 (127.0.0.1、::1、localhost)
+ * @author: Mr wpl
+ * @param addr string: 监听地址 (host:port)
+ * @param tlsCfg TLSConfig: TLS 配置
+ * @return error: 地址格式错误，或未配置证书却监听非回环地址时返回错误
+ */
+func CheckListenAddr(addr string, tlsCfg TLSConfig) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid gRPC address %q: %w", addr, err)
+	}
+	if tlsCfg.CertFile != "" || isLoopbackHost(host) {
+		return nil
+	}
+	return fmt.Errorf("refusing to serve the plaintext gRPC API on non-loopback address %q; set -grpc-cert/-grpc-key (and -grpc-client-ca) or listen on 127.0.0.1", addr)
+}
+
+// isLoopbackHost host 是否为回环地址，空 host (所有网卡) 与其他主机名均视为非回环
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// loadServerCredentials 加载服务端证书，配置了客户端 CA 时要求并校验客户端证书
+func loadServerCredentials(tlsCfg TLSConfig) (credentials.TransportCredentials, error) {
+	if tlsCfg.KeyFile == "" {
+		return nil, fmt.Errorf("gRPC TLS certificate %s has no key file", tlsCfg.CertFile)
+	}
+	cert, err := tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if tlsCfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(tlsCfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gRPC client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in gRPC client CA %s", tlsCfg.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(config), nil
+}
+
+/**
+ * @Description: 扫描请求中的路径，每个文件完成后发送一个 ScanResultEvent。客户端断开后停止发送，
+ * 已分发的文件仍会扫描完 (与 REST 接口相同，引擎不支持中途取消)
+ * @author: Mr wpl
+ * @param req *shieldpb.ScanRequest: 扫描请求
+ * @param stream shieldpb.ShieldService_ScanServer: 结果流
+ * @return error: 请求无效 (InvalidArgument) 或扫描失败 (Internal)
+ */
+func (s *Server) Scan(req *shieldpb.ScanRequest, stream shieldpb.ShieldService_ScanServer) error {
+	paths, err := validatePaths(req.GetPaths())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.activeScans.Add(1)
+	defer s.activeScans.Add(-1)
+
+	task := &engine.Task{
+		Paths:          paths,
+		Exclusions:     req.GetExclusions(),
+		ScanArchives:   req.GetScanArchives(),
+		FollowSymlinks: req.GetFollowSymlinks(),
+	}
+	var sequence int64
+	var sendErr error
+	scanErr := s.engine.ScanEach(task, func(res *types.ScanResult) {
+		if sendErr != nil {
+			return
+		}
+		sequence++
+		sendErr = stream.Send(&shieldpb.ScanResultEvent{Sequence: sequence, Result: ToProtoResult(res)})
+		if sendErr != nil {
+			logging.WarnLogger.Printf("gRPC scan stream closed after %d results: %v", sequence-1, sendErr)
+		}
+	})
+	if scanErr != nil {
+		return status.Error(codes.Internal, scanErr.Error())
+	}
+	return sendErr
+}
+
+/**
+ * @Description: 返回引擎状态，PHP 桥接不可用时 status 为 degraded
+ * @author: Mr wpl
+ * @param ctx context.Context: 请求上下文
+ * @param _ *emptypb.Empty: 空请求
+ * @return *shieldpb.StatusResponse: 状态
+ * @return error: 错误
+ */
+func (s *Server) GetStatus(ctx context.Context, _ *emptypb.Empty) (*shieldpb.StatusResponse, error) {
+	engineStatus := s.engine.Status()
+	resp := &shieldpb.StatusResponse{
+		Status:        "ok",
+		Analyzers:     engineStatus.Analyzers,
+		AstBridge:     engineStatus.ASTBridge,
+		Workers:       int32(engineStatus.Workers),
+		BusyWorkers:   int32(engineStatus.BusyWorkers),
+		ActiveScans:   s.activeScans.Load(),
+		UptimeSeconds: int64(time.Since(s.started).Seconds()),
+	}
+	if engineStatus.ASTBridge != "ok" && engineStatus.ASTBridge != "disabled" {
+		resp.Status = "degraded"
+	}
+	return resp, nil
+}
+
+// validatePaths 去掉空路径，至少保留一个且每个路径都必须存在
+func validatePaths(raw []string) ([]string, error) {
+	var paths []string
+	for _, p := range raw {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("paths is required")
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", p, err)
+		}
+	}
+	return paths, nil
+}
+
+/**
+ * @Description: 将扫描结果转换为 proto 消息，发现的 Metadata 值编码为 JSON 字符串
+ * @author: Mr wpl
+ * @param res *types.ScanResult: 扫描结果
+ * @return *shieldpb.ScanResult: proto 消息
+ */
+func ToProtoResult(res *types.ScanResult) *shieldpb.ScanResult {
+	out := &shieldpb.ScanResult{
+		File: &shieldpb.FileInfo{
+			Path:         res.File.Path,
+			RelativePath: res.File.RelativePath,
+			Size:         res.File.Size,
+			Encoding:     res.File.Encoding,
+		},
+		OverallRisk:       shieldpb.RiskLevel(res.OverallRisk),
+		Duration:          durationpb.New(res.Duration),
+		SkippedAst:        res.SkippedAST,
+		PartialAst:        res.PartialAST,
+		SkippedAnalyzers:  res.SkippedAnalyzers,
+		TruncatedFindings: res.TruncatedFindings,
+		DeployedBy:        res.DeployedBy,
+		DuplicateOf:       res.DuplicateOf,
+		Incomplete:        res.Incomplete,
+		Cached:            res.Cached,
+	}
+	if !res.File.ModTime.IsZero() {
+		out.File.ModTime = timestamppb.New(res.File.ModTime)
+	}
+	if res.Error != nil {
+		out.Error = res.Error.Error()
+	}
+	for _, f := range res.Findings {
+		finding := &shieldpb.Finding{
+			Analyzer:    f.AnalyzerName,
+			Description: f.Description,
+			Risk:        shieldpb.RiskLevel(f.Risk),
+			Confidence:  f.Confidence,
+			Severity:    f.Severity,
+			CvssVector:  f.CVSSVector,
+		}
+		if len(f.Metadata) > 0 {
+			finding.Metadata = make(map[string]string, len(f.Metadata))
+			for key, value := range f.Metadata {
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					encoded = []byte(fmt.Sprintf("%q", fmt.Sprint(value)))
+				}
+				finding.Metadata[key] = string(encoded)
+			}
+		}
+		out.Findings = append(out.Findings, finding)
+	}
+	return out
+}
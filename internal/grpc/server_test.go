@@ -0,0 +1,376 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: gRPC 服务集成测试：在 127.0.0.1 的随机端口上启动服务，经回环连接调用 Scan、GetStatus、反射服务与双向 TLS
+ */
+package grpc
+
+import (
+	shieldpb "bt-shieldml/api/proto"
+	"bt-shieldml/internal/config"
+	"bt-shieldml/internal/engine"
+	"bt-shieldml/pkg/types"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newLoopbackEngine 只启用 entropy_string 的引擎，不需要 PHP 桥接与模型
+func newLoopbackEngine(t *testing.T) *engine.Engine {
+	t.Helper()
+	cfg := config.GetDefaultConfig()
+	cfg.EnabledAnalyzers = []string{"entropy_string"}
+	e, err := engine.NewEngine(cfg)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+// startLoopbackServer 在 127.0.0.1 的随机端口上启动服务，返回监听地址，测试结束时停止
+func startLoopbackServer(t *testing.T, tlsCfg TLSConfig) string {
+	t.Helper()
+	s, err := NewGRPCServer(NewServer(newLoopbackEngine(t)), tlsCfg)
+	if err != nil {
+		t.Fatalf("NewGRPCServer() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+	return listener.Addr().String()
+}
+
+// dialLoopback 连接服务，测试结束时关闭连接
+func dialLoopback(t *testing.T, addr string, creds credentials.TransportCredentials) *grpclib.ClientConn {
+	t.Helper()
+	conn, err := grpclib.NewClient(addr, grpclib.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// testContext 带超时的请求上下文
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestScanStreamsResults(t *testing.T) {
+	root := t.TempDir()
+	var want []string
+	for _, name := range []string{"index.php", "lib/db.php", "lib/util.php", "readme.txt"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<?php echo 'hello';"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(name) == ".php" {
+			want = append(want, path)
+		}
+	}
+
+	client := shieldpb.NewShieldServiceClient(dialLoopback(t, startLoopbackServer(t, TLSConfig{}), insecure.NewCredentials()))
+	stream, err := client.Scan(testContext(t), &shieldpb.ScanRequest{Paths: []string{root}})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var got []string
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if event.GetSequence() != int64(len(got)+1) {
+			t.Errorf("event sequence = %d, want %d", event.GetSequence(), len(got)+1)
+		}
+		res := event.GetResult()
+		if res.GetError() != "" {
+			t.Errorf("%s: scan error %q", res.GetFile().GetPath(), res.GetError())
+		}
+		if res.GetOverallRisk() != shieldpb.RiskLevel(types.RiskNone) {
+			t.Errorf("%s: risk = %v, want Safe", res.GetFile().GetPath(), res.GetOverallRisk())
+		}
+		got = append(got, res.GetFile().GetPath())
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("streamed %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("streamed %q, want %q", got, want)
+			break
+		}
+	}
+}
+
+func TestScanInvalidArgument(t *testing.T) {
+	client := shieldpb.NewShieldServiceClient(dialLoopback(t, startLoopbackServer(t, TLSConfig{}), insecure.NewCredentials()))
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{"no paths", nil},
+		{"blank paths", []string{"", "   "}},
+		{"missing path", []string{filepath.Join(t.TempDir(), "missing")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.Scan(testContext(t), &shieldpb.ScanRequest{Paths: tt.paths})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Scan() error = %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	client := shieldpb.NewShieldServiceClient(dialLoopback(t, startLoopbackServer(t, TLSConfig{}), insecure.NewCredentials()))
+	resp, err := client.GetStatus(testContext(t), &emptypb.Empty{})
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if resp.GetStatus() != "ok" || resp.GetAstBridge() != "disabled" {
+		t.Errorf("GetStatus() = %s/%s, want ok/disabled", resp.GetStatus(), resp.GetAstBridge())
+	}
+	if len(resp.GetAnalyzers()) != 1 || resp.GetAnalyzers()[0] != "entropy_string" {
+		t.Errorf("analyzers = %q, want [entropy_string]", resp.GetAnalyzers())
+	}
+	if resp.GetWorkers() <= 0 || resp.GetBusyWorkers() != 0 || resp.GetActiveScans() != 0 {
+		t.Errorf("workers = %d, busy = %d, active scans = %d", resp.GetWorkers(), resp.GetBusyWorkers(), resp.GetActiveScans())
+	}
+}
+
+// TestReflectionListsShieldService grpcurl 通过反射服务发现 ShieldService
+func TestReflectionListsShieldService(t *testing.T) {
+	conn := dialLoopback(t, startLoopbackServer(t, TLSConfig{}), insecure.NewCredentials())
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(testContext(t))
+	if err != nil {
+		t.Fatalf("ServerReflectionInfo() error = %v", err)
+	}
+	req := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}
+	if err := stream.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	found := false
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		if svc.GetName() == shieldpb.ShieldService_ServiceDesc.ServiceName {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("reflection services = %v, want %s", resp.GetListServicesResponse().GetService(), shieldpb.ShieldService_ServiceDesc.ServiceName)
+	}
+}
+
+// testCertificate 签发测试证书并写入 dir，返回证书与私钥路径；parent 为 nil 时自签 (CA)
+func testCertificate(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, usage x509.ExtKeyUsage) (string, string, *x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPath, keyPath, cert, key
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caPath, _, ca, caKey := testCertificate(t, dir, "ca", nil, nil, 0)
+	serverCert, serverKey, _, _ := testCertificate(t, dir, "server", ca, caKey, x509.ExtKeyUsageServerAuth)
+	clientCertPath, clientKeyPath, _, _ := testCertificate(t, dir, "client", ca, caKey, x509.ExtKeyUsageClientAuth)
+	addr := startLoopbackServer(t, TLSConfig{CertFile: serverCert, KeyFile: serverKey, ClientCAFile: caPath})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	clientCert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		config  *tls.Config
+		wantErr bool
+	}{
+		{"client certificate", &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{clientCert}}, false},
+		{"no client certificate", &tls.Config{RootCAs: roots}, true},
+		{"untrusted server", &tls.Config{Certificates: []tls.Certificate{clientCert}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := shieldpb.NewShieldServiceClient(dialLoopback(t, addr, credentials.NewTLS(tt.config)))
+			_, err := client.GetStatus(testContext(t), &emptypb.Empty{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 明文客户端无法连接 TLS 服务
+	plain := shieldpb.NewShieldServiceClient(dialLoopback(t, addr, insecure.NewCredentials()))
+	if _, err := plain.GetStatus(testContext(t), &emptypb.Empty{}); err == nil {
+		t.Error("plaintext GetStatus() succeeded against a TLS server")
+	}
+}
+
+func TestNewGRPCServerInvalidTLS(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		cfg  TLSConfig
+	}{
+		{"certificate without key", TLSConfig{CertFile: filepath.Join(dir, "server.crt")}},
+		{"missing certificate", TLSConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: filepath.Join(dir, "missing.key")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGRPCServer(&Server{}, tt.cfg); err == nil {
+				t.Error("NewGRPCServer() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestCheckListenAddr(t *testing.T) {
+	withCert := TLSConfig{CertFile: "server.crt", KeyFile: "server.key"}
+	tests := []struct {
+		addr    string
+		tlsCfg  TLSConfig
+		wantErr bool
+	}{
+		{DefaultAddr, TLSConfig{}, false},
+		{"localhost:50051", TLSConfig{}, false},
+		{"[::1]:50051", TLSConfig{}, false},
+		{"127.0.0.2:50051", TLSConfig{}, false},
+		{":50051", TLSConfig{}, true},
+		{"0.0.0.0:50051", TLSConfig{}, true},
+		{"[::]:50051", TLSConfig{}, true},
+		{"10.0.0.5:50051", TLSConfig{}, true},
+		{"scanner.internal:50051", TLSConfig{}, true},
+		{":50051", withCert, false},
+		{"10.0.0.5:50051", withCert, false},
+		{"50051", TLSConfig{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if err := CheckListenAddr(tt.addr, tt.tlsCfg); (err != nil) != tt.wantErr {
+				t.Errorf("CheckListenAddr(%q, cert %q) error = %v, wantErr %v", tt.addr, tt.tlsCfg.CertFile, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToProtoResult(t *testing.T) {
+	modTime := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	res := &types.ScanResult{
+		File:        types.FileInfo{Path: "/www/shell.php", RelativePath: "shell.php", Size: 42, ModTime: modTime, Encoding: "UTF-8"},
+		OverallRisk: types.RiskCritical,
+		Duration:    1500 * time.Millisecond,
+		Error:       errors.New("partial read"),
+		Findings: []*types.Finding{{
+			AnalyzerName: "regex",
+			Description:  "eval($_POST)",
+			Risk:         types.RiskHigh,
+			Confidence:   0.8,
+			Metadata:     map[string]interface{}{"line": 3, "rule": "eval_post", "tags": []string{"exec"}},
+		}},
+	}
+	got := ToProtoResult(res)
+	if got.GetFile().GetPath() != "/www/shell.php" || got.GetFile().GetSize() != 42 || !got.GetFile().GetModTime().AsTime().Equal(modTime) {
+		t.Errorf("File = %v", got.GetFile())
+	}
+	if got.GetOverallRisk() != shieldpb.RiskLevel(types.RiskCritical) || got.GetDuration().AsDuration() != res.Duration || got.GetError() != "partial read" {
+		t.Errorf("risk/duration/error = %v/%v/%q", got.GetOverallRisk(), got.GetDuration().AsDuration(), got.GetError())
+	}
+	if len(got.GetFindings()) != 1 {
+		t.Fatalf("findings = %d, want 1", len(got.GetFindings()))
+	}
+	wantMetadata := map[string]string{"line": "3", "rule": `"eval_post"`, "tags": `["exec"]`}
+	for key, want := range wantMetadata {
+		if value := got.GetFindings()[0].GetMetadata()[key]; value != want {
+			t.Errorf("metadata[%s] = %s, want %s", key, value, want)
+		}
+	}
+	if ToProtoResult(&types.ScanResult{}).GetFile().GetModTime() != nil {
+		t.Error("zero ModTime should not be encoded")
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * @Date: 2025-06-12 14:52:09
+ * @Editors: Mr wpl
+ * @Description: 资产清单集成：Chef/Ansible/Puppet 等工具部署的已知文件风险最高为 Low，减少误报
+ */
+package integration
+
+import (
+	"bt-shieldml/pkg/logging"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultAssetFile 资产清单文件名 (位于 data_paths.config 下)
+const DefaultAssetFile = "deployed_assets.json"
+
+// AssetProvider 查询文件是否为部署工具下发的已知文件
+type AssetProvider interface {
+	// IsKnownDeployed 返回文件是否已知，以及部署该文件的工具名称
+	IsKnownDeployed(sha256 string, path string) (bool, string, error)
+}
+
+// DeployedAsset deployed_assets.json 中的一条记录
+type DeployedAsset struct {
+	SHA256     string    `json:"sha256"`
+	Path       string    `json:"path"` // 为空时任意路径下的相同内容均视为已知
+	DeployedBy string    `json:"deployed_by"`
+	DeployedAt time.Time `json:"deployed_at"`
+}
+
+/**
+ * @Description: 根据 asset_provider 配置创建资产清单。取值：
+ * "file" 使用 data_paths.config 下的 deployed_assets.json，"file:<路径>" 使用指定文件，
+ * "http://..." 或 "https://..." 查询 CMDB REST 接口
+ * @author: Mr wpl
+ * @param spec string: asset_provider 配置
+ * @param configDir string: 配置目录 (data_paths.config)
+ * @return AssetProvider: 资产清单，spec 为空时为 nil
+ * @return error: 错误
+ */
+func NewAssetProvider(spec string, configDir string) (AssetProvider, error) {
+	switch {
+	case spec == "":
+		return nil, nil
+	case spec == "file":
+		return NewFileAssetProvider(filepath.Join(configDir, DefaultAssetFile))
+	case strings.HasPrefix(spec, "file:"):
+		return NewFileAssetProvider(strings.TrimPrefix(spec, "file:"))
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return NewHTTPAssetProvider(spec), nil
+	}
+	return nil, fmt.Errorf("unknown asset_provider %q (expected file, file:<path>, or an http(s) URL)", spec)
+}
+
+/**
+ * @Description: 从 JSON 文件加载的资产清单
+ * @author: Mr wpl
+ */
+type FileAssetProvider struct {
+	assets map[string][]DeployedAsset // SHA-256 (小写) → 部署记录
+}
+
+/**
+ * @Description: 加载资产清单文件，内容为 DeployedAsset 数组
+ * @author: Mr wpl
+ * @param path string: 文件路径
+ * @return *FileAssetProvider: 资产清单
+ * @return error: 错误
+ */
+func NewFileAssetProvider(path string) (*FileAssetProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset inventory %s: %w", path, err)
+	}
+	var assets []DeployedAsset
+	if err := json.Unmarshal(data, &assets); err != nil {
+		return nil, fmt.Errorf("failed to parse asset inventory %s: %w", path, err)
+	}
+
+	p := &FileAssetProvider{assets: make(map[string][]DeployedAsset, len(assets))}
+	for i, asset := range assets {
+		// 只按路径匹配会让被篡改的部署文件也被放行，因此 sha256 必填
+		if asset.SHA256 == "" {
+			return nil, fmt.Errorf("asset inventory %s: entry %d has no sha256", path, i+1)
+		}
+		sum := strings.ToLower(asset.SHA256)
+		p.assets[sum] = append(p.assets[sum], asset)
+	}
+	logging.InfoLogger.Printf("Loaded %d deployed assets from %s", len(assets), path)
+	return p, nil
+}
+
+/**
+ * @Description: 内容哈希一致且 (记录未限定路径或路径一致) 时视为已知
+ * @author: Mr wpl
+ * @param sha256 string: 文件 SHA-256
+ * @param path string: 文件路径
+ * @return bool: 是否已知
+ * @return string: 部署工具
+ * @return error: 错误
+ */
+func (p *FileAssetProvider) IsKnownDeployed(sha256 string, path string) (bool, string, error) {
+	for _, asset := range p.assets[strings.ToLower(sha256)] {
+		if asset.Path == "" || filepath.Clean(asset.Path) == filepath.Clean(path) {
+			return true, asset.DeployedBy, nil
+		}
+	}
+	return false, "", nil
+}
@@ -0,0 +1,117 @@
+/*
+ * @Date: 2025-06-12 15:30:47
+ * @Editors: Mr wpl
+ * @Description: 通过 CMDB REST 接口查询部署资产
+ */
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// httpAssetTimeout 单次 CMDB 查询超时
+const httpAssetTimeout = 10 * time.Second
+
+// httpAssetResponse CMDB 接口的响应，404 视为未知
+type httpAssetResponse struct {
+	Known      bool   `json:"known"`
+	DeployedBy string `json:"deployed_by"`
+}
+
+// httpAssetResult 缓存的查询结果
+type httpAssetResult struct {
+	known      bool
+	deployedBy string
+}
+
+/**
+ * @Description: 查询 CMDB REST 接口的资产清单：GET <URL>?sha256=<哈希>&path=<路径>，
+ * 返回 {"known": true, "deployed_by": "ansible"}。同一哈希和路径只查询一次
+ * @author: Mr wpl
+ */
+type HTTPAssetProvider struct {
+	endpoint string
+	client   *http.Client
+	mu       sync.Mutex
+	cache    map[string]httpAssetResult
+}
+
+/**
+ * @Description: 创建 HTTP 资产清单
+ * @author: Mr wpl
+ * @param endpoint string: 接口地址，可带查询参数
+ * @return *HTTPAssetProvider: 资产清单
+ */
+func NewHTTPAssetProvider(endpoint string) *HTTPAssetProvider {
+	return &HTTPAssetProvider{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: httpAssetTimeout},
+		cache:    make(map[string]httpAssetResult),
+	}
+}
+
+/**
+ * @Description: 查询文件是否为已知部署文件，请求失败时返回错误 (调用方按未知处理)
+ * @author: Mr wpl
+ * @param sha256 string: 文件 SHA-256
+ * @param path string: 文件路径
+ * @return bool: 是否已知
+ * @return string: 部署工具
+ * @return error: 错误
+ */
+func (p *HTTPAssetProvider) IsKnownDeployed(sha256 string, path string) (bool, string, error) {
+	key := strings.ToLower(sha256) + "\x00" + path
+	p.mu.Lock()
+	cached, ok := p.cache[key]
+	p.mu.Unlock()
+	if ok {
+		return cached.known, cached.deployedBy, nil
+	}
+
+	result, err := p.lookup(sha256, path)
+	if err != nil {
+		return false, "", err
+	}
+	p.mu.Lock()
+	p.cache[key] = result
+	p.mu.Unlock()
+	return result.known, result.deployedBy, nil
+}
+
+// lookup 发起一次查询
+func (p *HTTPAssetProvider) lookup(sha256 string, path string) (httpAssetResult, error) {
+	u, err := url.Parse(p.endpoint)
+	if err != nil {
+		return httpAssetResult{}, fmt.Errorf("invalid asset provider URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("sha256", sha256)
+	query.Set("path", path)
+	u.RawQuery = query.Encode()
+
+	resp, err := p.client.Get(u.String())
+	if err != nil {
+		return httpAssetResult{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return httpAssetResult{}, nil
+	default:
+		return httpAssetResult{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body httpAssetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return httpAssetResult{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return httpAssetResult{known: body.Known, deployedBy: body.DeployedBy}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !debugprofile
+
+/*
+ * @Date: 2025-06-18 16:05:12
+ * @Editors: Mr wpl
+ * @Description: 默认构建不启用内存诊断，需设置 BTSHIELD_DEBUG_MEM=1
+ */
+package memdiff
+
+// buildTagEnabled 是否以 debugprofile 构建标签编译
+const buildTagEnabled = false
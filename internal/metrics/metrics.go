@@ -0,0 +1,120 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: Prometheus 指标：引擎在扫描开始、文件扫描完成与分析器执行处记录，常驻进程 (REST API 服务、-watch、-schedule) 通过 Handler 暴露
+ */
+package metrics
+
+import (
+	"bt-shieldml/pkg/types"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// FileLabel scan_duration_seconds 中整个文件扫描耗时的 analyzer 标签值
+const FileLabel = "file"
+
+// Registry 全部指标所在的注册表，另含 Go 运行时与进程指标
+var Registry = prometheus.NewRegistry()
+
+var (
+	filesScanned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bt_shieldml_files_scanned_total",
+		Help: "Files scanned, by overall risk level.",
+	}, []string{"risk"})
+	scanDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bt_shieldml_scan_duration_seconds",
+		Help:    "Time spent per file in each analyzer, and for the whole file (analyzer=\"file\").",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 120},
+	}, []string{"analyzer"})
+	analyzerFindings = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bt_shieldml_analyzer_findings_total",
+		Help: "Findings reported, by analyzer.",
+	}, []string{"analyzer"})
+	errorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bt_shieldml_errors_total",
+		Help: "Files that could not be scanned (read errors, timeouts).",
+	})
+	activeScans = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bt_shieldml_active_scans",
+		Help: "Scans in progress (CLI scans and API scan requests).",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		filesScanned, scanDuration, analyzerFindings, errorsTotal, activeScans,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	// 预先创建各风险级别，尚未出现的级别同样输出 0
+	for _, level := range []types.RiskLevel{types.RiskCritical, types.RiskHigh, types.RiskMedium, types.RiskLow, types.RiskNone} {
+		filesScanned.WithLabelValues(riskLabel(level))
+	}
+}
+
+// riskLabel 风险级别的标签值：critical、high、medium、low、none
+func riskLabel(risk types.RiskLevel) string {
+	if risk == types.RiskNone {
+		return "none"
+	}
+	return strings.ToLower(risk.String())
+}
+
+/**
+ * @Description: 记录一个文件的扫描结果：总耗时、按风险级别的文件数、各分析器的发现数，出错的文件只计入 errors_total
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 扫描结果
+ */
+func ObserveFile(result *types.ScanResult) {
+	scanDuration.WithLabelValues(FileLabel).Observe(result.Duration.Seconds())
+	if result.Error != nil {
+		errorsTotal.Inc()
+		return
+	}
+	filesScanned.WithLabelValues(riskLabel(result.OverallRisk)).Inc()
+	for _, finding := range result.Findings {
+		analyzerFindings.WithLabelValues(finding.AnalyzerName).Inc()
+	}
+}
+
+// ObserveAnalyzer 记录分析器对一个文件 (或分段) 的执行耗时
+func ObserveAnalyzer(name string, duration time.Duration) {
+	scanDuration.WithLabelValues(name).Observe(duration.Seconds())
+}
+
+// ScanStarted 记录一次开始的扫描，返回在扫描结束时调用的函数
+func ScanStarted() func() {
+	activeScans.Inc()
+	return activeScans.Dec
+}
+
+// handler 输出 Registry 的 HTTP 处理器
+var handler = promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+
+// Handler 以 Prometheus 文本格式输出 Registry 中全部指标
+func Handler() http.Handler {
+	return handler
+}
+
+/**
+ * @Description: 在独立地址上只提供 GET /metrics (-metrics-addr)，扫描接口不对外时仍可被外部抓取。阻塞直到监听出错
+ * @author: Mr wpl
+ * @param addr string: 监听地址 (host:port)
+ * @return error: 错误
+ */
+func Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
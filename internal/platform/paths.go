@@ -0,0 +1,28 @@
+/*
+ * @Date: 2025-06-20 10:04:37
+ * @Editors: Mr wpl
+ * @Description: 跨平台路径处理：Windows 上统一使用 / 分隔符，保证路径比较与 Linux/Mac 行为一致
+ */
+package platform
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// isWindows 是否运行在 Windows 上
+const isWindows = runtime.GOOS == "windows"
+
+/**
+ * @Description: 规范化路径用于比较：Windows 上将 \ 转为 /，Linux/Mac 上原样返回
+ * (Linux 文件名中的 \ 是普通字符，不能转换)
+ * @author: Mr wpl
+ * @param p string: 路径
+ * @return string: 规范化后的路径
+ */
+func NormalizePath(p string) string {
+	if !isWindows {
+		return p
+	}
+	return filepath.ToSlash(p)
+}
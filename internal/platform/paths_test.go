@@ -0,0 +1,73 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 路径规范化测试，用 filepath.FromSlash 构造本平台写法的路径，Linux CI 与 Windows 上结果一致
+ */
+package platform
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string // / 分隔的写法，经 FromSlash 转为本平台写法后规范化应还原
+	}{
+		{"absolute unix style", "/www/wwwroot/site/index.php"},
+		{"drive letter", "C:/www/site/index.php"},
+		{"relative", "vendor/autoload.php"},
+		{"dot segments kept", "./a/../b.php"},
+		{"trailing separator", "/www/cache/"},
+		{"UNC share", "//fileserver/share/site/index.php"},
+		{"no separator", "index.php"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			native := filepath.FromSlash(tt.path)
+			if got := NormalizePath(native); got != tt.path {
+				t.Errorf("NormalizePath(%q) = %q, want %q", native, got, tt.path)
+			}
+		})
+	}
+}
+
+// TestNormalizePathBackslash Windows 上 \ 是分隔符，Linux/Mac 上是文件名中的普通字符，不能转换
+func TestNormalizePathBackslash(t *testing.T) {
+	tests := []struct {
+		path        string
+		wantWindows string
+		wantOther   string
+	}{
+		{`C:\www\site\index.php`, "C:/www/site/index.php", `C:\www\site\index.php`},
+		{`/www/odd\name.php`, "/www/odd/name.php", `/www/odd\name.php`},
+		{`C:\www/mixed\index.php`, "C:/www/mixed/index.php", `C:\www/mixed\index.php`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			want := tt.wantOther
+			if runtime.GOOS == "windows" {
+				want = tt.wantWindows
+			}
+			if got := NormalizePath(tt.path); got != want {
+				t.Errorf("NormalizePath(%q) = %q, want %q", tt.path, got, want)
+			}
+		})
+	}
+}
+
+// TestNormalizePathComparison 同一路径的本平台写法与 / 写法规范化后相等，可直接用作 map 键比较
+func TestNormalizePathComparison(t *testing.T) {
+	paths := []string{"/www/site/wp-content/plugins/a.php", "C:/inetpub/wwwroot/app/index.php"}
+	for _, p := range paths {
+		if NormalizePath(filepath.FromSlash(p)) != NormalizePath(p) {
+			t.Errorf("%q and %q normalize differently", filepath.FromSlash(p), p)
+		}
+		if NormalizePath(filepath.Clean(filepath.FromSlash(p))) != NormalizePath(filepath.Clean(p)) {
+			t.Errorf("cleaned forms of %q normalize differently", p)
+		}
+	}
+}
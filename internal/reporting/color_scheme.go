@@ -0,0 +1,219 @@
+/*
+ * @Date: 2025-06-19 15:42:08
+ * @Editors: Mr wpl
+ * @Description: HTML 报告风险等级配色 (-color-scheme)：内置 default、accessible、greyscale，
+ * 可在 data/config/color_schemes.yaml 中定义更多方案，custom 方案由 -primary-color 等参数指定
+ */
+package reporting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ColorSchemeFile 配色方案文件名，位于 data_paths.config 目录
+const ColorSchemeFile = "color_schemes.yaml"
+
+// CustomColorScheme 以 default 为基础、完全由命令行颜色参数指定的方案名
+const CustomColorScheme = "custom"
+
+// hexColorRe 合法的颜色值，只接受 #rgb 与 #rrggbb，避免颜色值注入 CSS
+var hexColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ColorScheme 报告主色与各风险等级的颜色，空字段沿用基础方案
+type ColorScheme struct {
+	Primary  string `yaml:"primary"`
+	Critical string `yaml:"critical"`
+	High     string `yaml:"high"`
+	Medium   string `yaml:"medium"`
+	Low      string `yaml:"low"`
+}
+
+// builtinColorSchemes 内置配色方案，default 与报告原有配色一致
+var builtinColorSchemes = map[string]ColorScheme{
+	"default": {Primary: "#0070c0", Critical: "#e94747", High: "#e94747", Medium: "#f8a532", Low: "#f8a532"},
+	// accessible 红绿色盲 (deuteranopia) 可区分：蓝色 Critical、紫色 High
+	"accessible": {Primary: "#0072b2", Critical: "#0072b2", High: "#7b3294", Medium: "#e69f00", Low: "#56b4e9"},
+	"greyscale":  {Primary: "#333333", Critical: "#111111", High: "#444444", Medium: "#777777", Low: "#999999"},
+}
+
+// merge 用 o 中非空的颜色覆盖 c
+func (c ColorScheme) merge(o ColorScheme) ColorScheme {
+	if o.Primary != "" {
+		c.Primary = o.Primary
+	}
+	if o.Critical != "" {
+		c.Critical = o.Critical
+	}
+	if o.High != "" {
+		c.High = o.High
+	}
+	if o.Medium != "" {
+		c.Medium = o.Medium
+	}
+	if o.Low != "" {
+		c.Low = o.Low
+	}
+	return c
+}
+
+// validate 检查非空的颜色是否为 #rgb 或 #rrggbb
+func (c ColorScheme) validate() error {
+	fields := []struct{ name, value string }{
+		{"primary", c.Primary}, {"critical", c.Critical}, {"high", c.High}, {"medium", c.Medium}, {"low", c.Low},
+	}
+	for _, f := range fields {
+		if f.value != "" && !hexColorRe.MatchString(f.value) {
+			return fmt.Errorf("invalid %s color %q: expected #rgb or #rrggbb", f.name, f.value)
+		}
+	}
+	return nil
+}
+
+/**
+ * @Description: 加载配色方案：内置方案加上 configDir 下 color_schemes.yaml 中定义的方案 (同名时覆盖内置方案的对应颜色)，
+ * 文件不存在时只返回内置方案
+ * @author: Mr wpl
+ * @param configDir string: 配置目录 (data_paths.config)
+ * @return map[string]ColorScheme: 方案名 → 配色
+ * @return error: 错误
+ */
+func LoadColorSchemes(configDir string) (map[string]ColorScheme, error) {
+	schemes := make(map[string]ColorScheme, len(builtinColorSchemes))
+	for name, scheme := range builtinColorSchemes {
+		schemes[name] = scheme
+	}
+
+	path := filepath.Join(configDir, ColorSchemeFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return schemes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Schemes map[string]ColorScheme `yaml:"schemes"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for name, scheme := range file.Schemes {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == CustomColorScheme {
+			return nil, fmt.Errorf("%s: scheme name %q is reserved", path, CustomColorScheme)
+		}
+		if err := scheme.validate(); err != nil {
+			return nil, fmt.Errorf("%s: scheme %q: %w", path, name, err)
+		}
+		// 未定义的颜色沿用同名内置方案，没有同名内置方案时沿用 default
+		base, ok := schemes[name]
+		if !ok {
+			base = builtinColorSchemes["default"]
+		}
+		schemes[name] = base.merge(scheme)
+	}
+	return schemes, nil
+}
+
+/**
+ * @Description: 解析 -color-scheme 与颜色参数。custom 以 default 为基础，其他方案也可被颜色参数覆盖。
+ * 结果与报告原有配色相同时返回 nil，报告保持不变
+ * @author: Mr wpl
+ * @param name string: 方案名，为空时为 default
+ * @param configDir string: 配置目录 (data_paths.config)
+ * @param overrides ColorScheme: 命令行指定的颜色，空字段不覆盖
+ * @return *ColorScheme: 配色
+ * @return error: 错误
+ */
+func ResolveColorScheme(name string, configDir string, overrides ColorScheme) (*ColorScheme, error) {
+	if err := overrides.validate(); err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == CustomColorScheme {
+		name = "default"
+	}
+	schemes, err := LoadColorSchemes(configDir)
+	if err != nil {
+		return nil, err
+	}
+	base, ok := schemes[name]
+	if !ok {
+		names := make([]string, 0, len(schemes)+1)
+		for n := range schemes {
+			names = append(names, n)
+		}
+		names = append(names, CustomColorScheme)
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown color scheme %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	scheme := base.merge(overrides)
+	if scheme == builtinColorSchemes["default"] {
+		return nil, nil
+	}
+	return &scheme, nil
+}
+
+/**
+ * @Description: 生成配色覆盖 CSS，放在样式表末尾。使用 !important 使其在深色模式下同样生效，
+ * 渐变的终止色由起始色加深得到
+ * @author: Mr wpl
+ * @return string: CSS 片段
+ */
+func (c *ColorScheme) css() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `
+        :root, :root[data-theme] {
+            --primary-color: %s !important;
+            --risk-critical: %s !important;
+            --risk-high: %s !important;
+            --risk-medium: %s !important;
+            --risk-low: %s !important;
+        }
+`, c.Primary, c.Critical, c.High, c.Medium, c.Low)
+	levels := []struct{ class, color string }{
+		{"risk-critical", c.Critical}, {"risk-high", c.High}, {"risk-medium", c.Medium}, {"risk-low", c.Low},
+	}
+	for _, l := range levels {
+		fmt.Fprintf(&b, `
+        .%s,
+        .risk-level-badge.%s {
+            background: linear-gradient(135deg, %s, %s) !important;
+        }
+`, l.class, l.class, l.color, darkenColor(l.color, 0.15))
+	}
+	return b.String()
+}
+
+/**
+ * @Description: 返回风险分数图表的 5 个等级颜色 (1-2 级为 Low，3 级 Medium，4 级 High，5 级 Critical)
+ * @author: Mr wpl
+ * @return []string: 颜色
+ */
+func (c *ColorScheme) chartColors() []string {
+	return []string{c.Low, c.Low, c.Medium, c.High, c.Critical}
+}
+
+// darkenColor 按比例加深 #rgb/#rrggbb 颜色，无法解析时原样返回
+func darkenColor(color string, amount float64) string {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return color
+	}
+	channel := func(shift uint) uint64 {
+		return uint64(float64((v>>shift)&0xff) * (1 - amount))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", channel(16), channel(8), channel(0))
+}
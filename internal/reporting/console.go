@@ -6,13 +6,24 @@
 package reporting
 
 import (
+	"bt-shieldml/pkg/terminal"
 	"bt-shieldml/pkg/types"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 )
 
-type ConsoleReporter struct{}
+type ConsoleReporter struct {
+	GroupByDirectory bool   // 在汇总前按目录输出小计
+	Emoji            bool   // 使用 emoji 代替 [Level] 前缀，终端不支持 Unicode 时回退为文本
+	ScanRoot         string // 扫描根目录，显示在报告开头
+	// OutputTemplate text/template 格式的输出模板，为空时使用 DefaultConsoleTemplate
+	OutputTemplate string
+	Config         *types.Config // 模板上下文中的配置
+	SortKeys       []SortKey     // 结果排序键，为空时按路径排序
+	Verbose        bool          // 显示被 min_confidence 抑制的发现
+}
 
 /**
  * @Description: 创建新的终端命令行输出日志
@@ -34,56 +45,116 @@ func (r *ConsoleReporter) Generate(results []*types.ScanResult, outputPath strin
 		fmt.Fprintf(os.Stderr, "Warning: Console reporter does not support output path '%s'. Printing to stdout.\n", outputPath)
 	}
 
-	// Sort results by path for consistent output
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].File.Path < results[j].File.Path
-	})
+	// Sort results by path (default) for consistent output
+	keys := r.SortKeys
+	if len(keys) == 0 {
+		keys = []SortKey{SortByPathAsc}
+	}
+	results = SortResults(results, keys...)
 
-	fmt.Println("\n--- Scan Report ---")
-	riskCounts := make(map[types.RiskLevel]int)
-	var totalFiles, errorFiles int
+	tmplText := r.OutputTemplate
+	if tmplText == "" {
+		tmplText = DefaultConsoleTemplate
+	}
+	tmpl, err := parseConsoleTemplate(tmplText, r.Emoji)
+	if err != nil {
+		return err
+	}
 
+	summary := &types.ScanSummary{RiskCounts: make(map[types.RiskLevel]int)}
 	for _, res := range results {
-		totalFiles++
+		summary.TotalFiles++
+		if res.DuplicateOf != "" {
+			summary.DuplicateFiles++
+		}
+		if res.Incomplete {
+			summary.IncompleteFiles++
+		}
 		if res.Error != nil {
-			fmt.Printf("[ERROR] %s : %v\n", res.File.Path, res.Error)
-			riskCounts[types.RiskUnknown]++
-			errorFiles++
+			summary.RiskCounts[types.RiskUnknown]++
+			summary.ErrorFiles++
 			continue
 		}
+		summary.RiskCounts[res.OverallRisk]++
+		// Sort findings by risk level (descending)
+		sort.Slice(res.Findings, func(i, j int) bool {
+			return res.Findings[i].Risk > res.Findings[j].Risk
+		})
+	}
 
-		riskCounts[res.OverallRisk]++
-
-		// Print details only for files with findings or risk > None
-		if res.OverallRisk > types.RiskNone || len(res.Findings) > 0 {
-			fmt.Printf("[%s] %s (Risk: %s, Time: %s)\n", res.OverallRisk.String(), res.File.Path, res.OverallRisk.String(), res.Duration)
-			if len(res.Findings) > 0 {
-				// Sort findings by risk level (descending)
-				sort.Slice(res.Findings, func(i, j int) bool {
-					return res.Findings[i].Risk > res.Findings[j].Risk
-				})
-				for _, f := range res.Findings {
-					fmt.Printf("  -> [%s] %s: %s\n", f.Risk.String(), f.AnalyzerName, f.Description)
-				}
-			}
-			if res.SkippedAST {
-				fmt.Println("  -> AST analysis skipped due to early high-risk finding.")
-			}
+	ctx := ConsoleContext{Summary: summary, Config: r.Config, ScanRoot: r.ScanRoot, Verbose: r.Verbose}
+	if header := tmpl.Lookup("header"); header != nil {
+		if err := header.Execute(os.Stdout, ctx); err != nil {
+			return fmt.Errorf("failed to render console template header: %w", err)
 		}
 	}
+	for _, res := range results {
+		ctx.Result = res
+		if err := tmpl.Execute(os.Stdout, ctx); err != nil {
+			return fmt.Errorf("failed to render console template for %s: %w", res.File.Path, err)
+		}
+	}
+	ctx.Result = nil
 
-	fmt.Println("\n--- Summary ---")
-	fmt.Printf("Total Files Scanned: %d\n", totalFiles)
-	fmt.Printf("Files with Errors:   %d\n", errorFiles)
-	fmt.Printf("Risk Levels Found:\n")
-	// Print counts for each risk level
-	levels := []types.RiskLevel{types.RiskCritical, types.RiskHigh, types.RiskMedium, types.RiskLow, types.RiskNone, types.RiskUnknown}
-	for _, level := range levels {
-		if count, ok := riskCounts[level]; ok && count > 0 {
-			fmt.Printf("  - %-8s : %d\n", level.String(), count)
+	if r.GroupByDirectory {
+		r.printDirectorySummary(results)
+	}
+
+	if footer := tmpl.Lookup("footer"); footer != nil {
+		if err := footer.Execute(os.Stdout, ctx); err != nil {
+			return fmt.Errorf("failed to render console template footer: %w", err)
 		}
 	}
-	fmt.Println("--- End Report ---")
 
 	return nil
 }
+
+/**
+ * @Description: 按目录输出扫描小计，目录下的风险文件缩进显示
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ */
+func (r *ConsoleReporter) printDirectorySummary(results []*types.ScanResult) {
+	fmt.Println("\n--- Directory Summary ---")
+	groups := GroupResultsByDirectory(results)
+	for _, dir := range sortedDirectories(groups) {
+		counts := make(map[types.RiskLevel]int)
+		errors := 0
+		for _, res := range groups[dir] {
+			if res.Error != nil {
+				errors++
+				continue
+			}
+			counts[res.OverallRisk]++
+		}
+
+		line := fmt.Sprintf("[%s] %d files: %d critical, %d high, %d medium, %d low, %d clean",
+			dir, len(groups[dir]), counts[types.RiskCritical], counts[types.RiskHigh],
+			counts[types.RiskMedium], counts[types.RiskLow], counts[types.RiskNone])
+		if errors > 0 {
+			line += fmt.Sprintf(", %d errors", errors)
+		}
+		fmt.Println(line)
+
+		for _, res := range groups[dir] {
+			if res.Error != nil {
+				fmt.Printf("    [ERROR] %s\n", filepath.Base(res.File.Path))
+			} else if res.OverallRisk > types.RiskNone {
+				fmt.Printf("    %s %s\n", r.levelTag(res.OverallRisk), filepath.Base(res.File.Path))
+			}
+		}
+	}
+}
+
+/**
+ * @Description: 返回风险级别前缀，开启 Emoji 且终端支持 Unicode 时使用 emoji，否则为 [Level]
+ * @author: Mr wpl
+ * @param level types.RiskLevel: 风险级别
+ * @return string: 前缀
+ */
+func (r *ConsoleReporter) levelTag(level types.RiskLevel) string {
+	if r.Emoji && terminal.SupportsUnicode() {
+		return level.Emoji()
+	}
+	return "[" + level.String() + "]"
+}
@@ -0,0 +1,152 @@
+/*
+ * @Date: 2025-05-29 10:12:31
+ * @Editors: Mr wpl
+ * @Description: 控制台报告模板 (text/template)
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/terminal"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// ConsoleContext 控制台模板的执行上下文。
+// 主模板对每个扫描结果执行一次 (Result 非空)；可选的 "header" / "footer"
+// 子模板在报告开头和结尾各执行一次 (Result 为 nil)。
+type ConsoleContext struct {
+	Result   *types.ScanResult
+	Summary  *types.ScanSummary
+	Config   *types.Config
+	ScanRoot string
+	Verbose  bool // 为 true 时显示 Result.SuppressedFindings (-verbose)
+}
+
+// DefaultConsoleTemplate 默认控制台模板，与原有的硬编码输出一致。
+//
+// 可用的模板函数:
+//   - riskColor LEVEL      风险级别名称，终端支持时带 ANSI 颜色
+//   - truncate N STRING    截断为最多 N 个字符，超出部分以 "..." 结尾
+//   - joinFindings LIST    将发现合并为 "analyzer: description; ..." 的单行文本
+//   - levelTag LEVEL       风险前缀，[Level] 或 emoji (-emoji)
+const DefaultConsoleTemplate = `{{define "header"}}
+--- Scan Report ---
+{{if .ScanRoot}}Scan Root: {{.ScanRoot}}
+{{end}}{{end}}
+{{- define "footer"}}
+--- Summary ---
+Total Files Scanned: {{.Summary.TotalFiles}}
+Files with Errors:   {{.Summary.ErrorFiles}}
+{{if .Summary.DuplicateFiles}}Duplicate Content:   {{.Summary.DuplicateFiles}} (results reused)
+{{end -}}
+{{if .Summary.IncompleteFiles}}Partial Report:      interrupted by shutdown, {{.Summary.CompletedFiles}} completed, {{.Summary.IncompleteFiles}} aborted
+{{end -}}
+Risk Levels Found:
+{{range .Summary.Counts}}  - {{printf "%-8s" .Level.String}} : {{.Count}}
+{{end}}--- End Report ---
+{{end}}
+{{- with .Result}}{{if .Error}}[ERROR] {{.File.DisplayPath}} : {{.Error}}
+{{else if .Incomplete}}[INCOMPLETE] {{.File.DisplayPath}} : scan aborted at shutdown
+{{else if or (gt .OverallRisk 1) .Findings (and $.Verbose .SuppressedFindings)}}{{/* 1 = RiskNone */ -}}
+{{levelTag .OverallRisk}} {{.File.DisplayPath}} (Risk: {{.OverallRisk}}, Time: {{.Duration}}{{if .ContentMD5}}, MD5: {{.ContentMD5}}{{end}})
+{{range .Findings}}  -> {{levelTag .Risk}} {{.AnalyzerName}}: {{.Description}}
+{{end}}{{if $.Verbose}}{{range .SuppressedFindings}}  -> [suppressed] {{.AnalyzerName}}: {{.Description}} (confidence {{printf "%.2f" .Confidence}})
+{{end}}{{end}}{{if .TruncatedFindings}}  -> Warning: findings truncated, only the highest-risk findings are shown.
+{{end}}{{if .DeployedBy}}  -> Known deployed file ({{.DeployedBy}}), risk capped at Low.
+{{end}}{{if .DuplicateOf}}  -> Identical content to {{.DuplicateOf}}, result reused.
+{{end}}{{if .SkippedAST}}  -> AST analysis skipped due to early high-risk finding.
+{{end}}{{end}}{{end}}`
+
+// riskColors 各风险级别的 ANSI 颜色
+var riskColors = map[types.RiskLevel]string{
+	types.RiskCritical: "\033[1;31m",
+	types.RiskHigh:     "\033[31m",
+	types.RiskMedium:   "\033[33m",
+	types.RiskLow:      "\033[36m",
+	types.RiskNone:     "\033[32m",
+}
+
+/**
+ * @Description: 读取并校验控制台模板。spec 为已存在的文件路径时读取文件内容，否则包含 "{{" 时视为模板内容
+ * @author: Mr wpl
+ * @param spec string: 模板内容或模板文件路径
+ * @return string: 模板内容
+ * @return error: 文件不存在或模板解析失败时返回错误
+ */
+func LoadConsoleTemplate(spec string) (string, error) {
+	text := spec
+	if info, err := os.Stat(spec); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(spec)
+		if err != nil {
+			return "", fmt.Errorf("failed to read console template %s: %w", spec, err)
+		}
+		text = string(data)
+	} else if !strings.Contains(spec, "{{") {
+		return "", fmt.Errorf("console template file not found: %s", spec)
+	}
+
+	if _, err := parseConsoleTemplate(text, false); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+/**
+ * @Description: 解析控制台模板
+ * @author: Mr wpl
+ * @param text string: 模板内容
+ * @param emoji bool: levelTag 是否使用 emoji
+ * @return *template.Template: 模板
+ * @return error: 错误
+ */
+func parseConsoleTemplate(text string, emoji bool) (*template.Template, error) {
+	tmpl, err := template.New("console").Funcs(consoleTemplateFuncs(emoji)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid console template: %w", err)
+	}
+	return tmpl, nil
+}
+
+/**
+ * @Description: 返回控制台模板可用的函数
+ * @author: Mr wpl
+ * @param emoji bool: levelTag 是否使用 emoji
+ * @return template.FuncMap: 模板函数
+ */
+func consoleTemplateFuncs(emoji bool) template.FuncMap {
+	return template.FuncMap{
+		"riskColor": func(level types.RiskLevel) string {
+			color, ok := riskColors[level]
+			if !ok || !terminal.SupportsColor() {
+				return level.String()
+			}
+			return color + level.String() + "\033[0m"
+		},
+		"truncate": func(n int, s string) string {
+			runes := []rune(s)
+			if n < 0 || len(runes) <= n {
+				return s
+			}
+			if n <= 3 {
+				return string(runes[:n])
+			}
+			return string(runes[:n-3]) + "..."
+		},
+		"joinFindings": func(findings []*types.Finding) string {
+			parts := make([]string, 0, len(findings))
+			for _, f := range findings {
+				parts = append(parts, f.AnalyzerName+": "+f.Description)
+			}
+			return strings.Join(parts, "; ")
+		},
+		"levelTag": func(level types.RiskLevel) string {
+			if emoji && terminal.SupportsUnicode() {
+				return level.Emoji()
+			}
+			return "[" + level.String() + "]"
+		},
+	}
+}
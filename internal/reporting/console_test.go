@@ -0,0 +1,42 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 控制台报告风险前缀测试
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"testing"
+)
+
+func TestConsoleLevelTagEmojiFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji bool
+		term  string
+		lcAll string
+		want  string
+	}{
+		{"emoji on unicode terminal", true, "xterm-256color", "en_US.UTF-8", "🔴"},
+		{"emoji disabled", false, "xterm-256color", "en_US.UTF-8", "[" + types.RiskCritical.String() + "]"},
+		{"dumb terminal falls back to text", true, "dumb", "en_US.UTF-8", "[" + types.RiskCritical.String() + "]"},
+		{"C locale falls back to text", true, "xterm", "C", "[" + types.RiskCritical.String() + "]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM", tt.term)
+			t.Setenv("LC_ALL", tt.lcAll)
+			r := &ConsoleReporter{Emoji: tt.emoji}
+			if got := r.levelTag(types.RiskCritical); got != tt.want {
+				t.Errorf("levelTag() = %q, want %q", got, tt.want)
+			}
+
+			funcs := consoleTemplateFuncs(tt.emoji)
+			levelTag := funcs["levelTag"].(func(types.RiskLevel) string)
+			if got := levelTag(types.RiskCritical); got != tt.want {
+				t.Errorf("template levelTag = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: CSV 报告 (RFC 4180)，每个文件一行，便于导入 Excel、Google Sheets 跟踪处理进度
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVHeader CSV 报告的表头
+var CSVHeader = []string{
+	"path",
+	"size_bytes",
+	"mod_time",
+	"overall_risk",
+	"risk_score",
+	"finding_count",
+	"analyzer_names",
+	"first_finding_description",
+	"scan_duration_ms",
+	"error",
+}
+
+/**
+ * @Description: CSV 报告，实现 Reporter 接口
+ * @author: Mr wpl
+ */
+type CsvReporter struct {
+	SortKeys []SortKey // 结果排序键，为空时按风险降序、路径升序
+}
+
+/**
+ * @Description: 创建新的CSV报告
+ * @author: Mr wpl
+ * @return *CsvReporter: CSV报告
+ */
+func NewCsvReporter() *CsvReporter {
+	return &CsvReporter{}
+}
+
+/**
+ * @Description: 生成 CSV 报告，outputPath 为空时写到 stdout。路径等字段中的逗号、引号与换行按 RFC 4180 加引号转义
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 输出路径
+ * @return error: 错误
+ */
+func (r *CsvReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	keys := r.SortKeys
+	if len(keys) == 0 {
+		keys = []SortKey{SortByRiskDesc, SortByPathAsc}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true // RFC 4180 要求以 CRLF 换行
+	if err := cw.Write(CSVHeader); err != nil {
+		return err
+	}
+	for _, res := range SortResults(results, keys...) {
+		if err := cw.Write(CSVRecord(res)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+/**
+ * @Description: 将扫描结果转换为一行 CSV 字段，顺序与 CSVHeader 一致；analyzer_names 以 "|" 分隔
+ * @author: Mr wpl
+ * @param res *types.ScanResult: 扫描结果
+ * @return []string: CSV 字段
+ */
+func CSVRecord(res *types.ScanResult) []string {
+	var modTime string
+	if !res.File.ModTime.IsZero() {
+		modTime = res.File.ModTime.Format(time.RFC3339)
+	}
+
+	analyzers := make([]string, 0, len(res.Findings))
+	var firstDesc string
+	for i, f := range res.Findings {
+		analyzers = append(analyzers, f.AnalyzerName)
+		if i == 0 {
+			firstDesc = f.Description
+		}
+	}
+
+	var errText string
+	if res.Error != nil {
+		errText = res.Error.Error()
+	} else if res.Incomplete {
+		errText = "scan incomplete (interrupted by shutdown)"
+	}
+
+	return []string{
+		res.File.Path,
+		strconv.FormatInt(res.File.Size, 10),
+		modTime,
+		res.OverallRisk.String(),
+		strconv.Itoa(riskScore(res.OverallRisk)),
+		strconv.Itoa(len(res.Findings)),
+		strings.Join(analyzers, "|"),
+		firstDesc,
+		strconv.FormatInt(res.Duration.Milliseconds(), 10),
+		errText,
+	}
+}
+
+// riskScore 风险等级对应的分数，与 JSON 报告的 risk 字段一致 (正常 0，Low 1，Medium 3，High 4，Critical 5)
+func riskScore(level types.RiskLevel) int {
+	switch level {
+	case types.RiskLow:
+		return 1
+	case types.RiskMedium:
+		return 3
+	case types.RiskHigh:
+		return 4
+	case types.RiskCritical:
+		return 5
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: CSV 报告往返测试：生成的报告经 encoding/csv 读回后每个字段都与扫描结果一致
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCsvReporterRoundTrip(t *testing.T) {
+	modTime := time.Date(2026, 10, 1, 8, 30, 0, 0, time.FixedZone("CST", 8*3600))
+	results := []*types.ScanResult{
+		{
+			File:        types.FileInfo{Path: "/www/plain.php", Size: 120, ModTime: modTime},
+			OverallRisk: types.RiskNone,
+			Duration:    15 * time.Millisecond,
+		},
+		{
+			File:        types.FileInfo{Path: `/www/up,loads/"quoted" shell.php`, Size: 4096, ModTime: modTime},
+			OverallRisk: types.RiskCritical,
+			Findings: []*types.Finding{
+				{AnalyzerName: "yara", Description: `Rule "webshell_eval", matched`, Risk: types.RiskCritical},
+				{AnalyzerName: "regex", Description: "eval($_POST)", Risk: types.RiskHigh},
+			},
+			Duration: 1234 * time.Millisecond,
+		},
+		{
+			File:        types.FileInfo{Path: "/www/multi\nline/中文.php", Size: 77},
+			OverallRisk: types.RiskMedium,
+			Findings:    []*types.Finding{{AnalyzerName: "statistical", Description: "line one\r\nline two", Risk: types.RiskMedium}},
+			Duration:    2 * time.Second,
+		},
+		{
+			File:  types.FileInfo{Path: "/www/unreadable.php"},
+			Error: errors.New("open /www/unreadable.php: permission denied"),
+		},
+		{
+			File:        types.FileInfo{Path: "/www/interrupted.php", Size: 10},
+			OverallRisk: types.RiskLow,
+			Findings:    []*types.Finding{{AnalyzerName: "regex", Description: "base64_decode", Risk: types.RiskLow}},
+			Incomplete:  true,
+		},
+	}
+	// 按风险降序、路径升序
+	want := [][]string{
+		CSVHeader,
+		{`/www/up,loads/"quoted" shell.php`, "4096", "2026-10-01T08:30:00+08:00", types.RiskCritical.String(), "5", "2", "yara|regex", `Rule "webshell_eval", matched`, "1234", ""},
+		{"/www/multi\nline/中文.php", "77", "", types.RiskMedium.String(), "3", "1", "statistical", "line one\nline two", "2000", ""},
+		{"/www/interrupted.php", "10", "", types.RiskLow.String(), "1", "1", "regex", "base64_decode", "0", "scan incomplete (interrupted by shutdown)"},
+		{"/www/plain.php", "120", "2026-10-01T08:30:00+08:00", types.RiskNone.String(), "0", "0", "", "", "15", ""},
+		{"/www/unreadable.php", "0", "", types.RiskUnknown.String(), "0", "0", "", "", "0", "open /www/unreadable.php: permission denied"},
+	}
+
+	out := filepath.Join(t.TempDir(), "results.csv")
+	if err := NewCsvReporter().Generate(results, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) || !bytes.HasPrefix(data, []byte("path,size_bytes,mod_time,overall_risk,risk_score,finding_count,analyzer_names,first_finding_description,scan_duration_ms,error\r\n")) {
+		t.Errorf("report does not use CRLF line endings:\n%q", data)
+	}
+
+	// encoding/csv 读取时将引号内的 \r\n 规范化为 \n
+	got, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("read %d records, want %d:\n%q", len(got), len(want), got)
+	}
+	for i := range want {
+		if len(got[i]) != len(CSVHeader) {
+			t.Errorf("record %d has %d fields, want %d", i, len(got[i]), len(CSVHeader))
+			continue
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Errorf("record %d %s = %q, want %q", i, CSVHeader[j], got[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestCsvReporterSortKeys(t *testing.T) {
+	results := []*types.ScanResult{
+		{File: types.FileInfo{Path: "/www/b.php"}, OverallRisk: types.RiskCritical},
+		{File: types.FileInfo{Path: "/www/a.php"}, OverallRisk: types.RiskLow},
+		{File: types.FileInfo{Path: "/www/c.php"}, OverallRisk: types.RiskHigh},
+	}
+	out := filepath.Join(t.TempDir(), "results.csv")
+	if err := (&CsvReporter{SortKeys: []SortKey{SortByPathAsc}}).Generate(results, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, record := range records[1:] {
+		paths = append(paths, record[0])
+	}
+	if want := []string{"/www/a.php", "/www/b.php", "/www/c.php"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+}
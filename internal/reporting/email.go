@@ -0,0 +1,184 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 邮件通知：扫描发现 High/Critical 文件时通过 SMTP 发送 HTML 邮件 (汇总表与文件列表)，供不使用面板的服务器运维
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"fmt"
+	"html/template"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+)
+
+// emailMaxFindings 邮件中每个文件列出的发现数上限
+const emailMaxFindings = 5
+
+// emailTemplate 邮件 HTML 正文：汇总表与 High/Critical 文件列表
+var emailTemplate = template.Must(template.New("email").Parse(`<html><body style="font-family: sans-serif;">
+<h2>BT-ShieldML: {{.RiskyCount}} high-risk file(s) found on {{.Host}}</h2>
+<p>Scan finished at {{.Time}}{{if .ScanRoot}} for <code>{{.ScanRoot}}</code>{{end}}.</p>
+<table border="1" cellpadding="6" cellspacing="0" style="border-collapse: collapse;">
+<tr><th align="left">Risk</th><th align="right">Files</th></tr>
+{{range .Summary}}<tr><td>{{.Level}}</td><td align="right">{{.Count}}</td></tr>
+{{end}}<tr><td><b>Total</b></td><td align="right"><b>{{.Total}}</b></td></tr>
+</table>
+<h3>High-risk files</h3>
+<table border="1" cellpadding="6" cellspacing="0" style="border-collapse: collapse;">
+<tr><th align="left">File</th><th align="left">Risk</th><th align="left">Findings</th></tr>
+{{range .Files}}<tr><td><code>{{.Path}}</code></td><td style="color: {{.Color}};"><b>{{.Risk}}</b></td><td>{{range .Findings}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// emailSummaryRow 汇总表的一行
+type emailSummaryRow struct {
+	Level string
+	Count int
+}
+
+// emailFile 文件列表的一行
+type emailFile struct {
+	Path     string
+	Risk     string
+	Color    string
+	Findings []string
+}
+
+/**
+ * @Description: 邮件通知，实现 Reporter 接口。只有存在风险不低于 High 的文件时才发送邮件，outputPath 被忽略
+ * @author: Mr wpl
+ */
+type EmailReporter struct {
+	Config   types.Email
+	ScanRoot string // 扫描根目录，显示在邮件中
+	// SendMail 发送邮件，默认为 smtp.SendMail (服务器支持时使用 STARTTLS)
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+/**
+ * @Description: 创建邮件通知
+ * @author: Mr wpl
+ * @param cfg types.Email: 邮件配置
+ * @return *EmailReporter: 邮件通知
+ */
+func NewEmailReporter(cfg types.Email) *EmailReporter {
+	return &EmailReporter{Config: cfg, SendMail: smtp.SendMail}
+}
+
+/**
+ * @Description: 存在 High/Critical 文件时发送 HTML 邮件，否则不做任何事。配置了 username 时使用 PLAIN 认证
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 未使用
+ * @return error: 构造或发送邮件失败时返回错误
+ */
+func (r *EmailReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	var risky []*types.ScanResult
+	for _, res := range results {
+		if res.OverallRisk >= types.RiskHigh {
+			risky = append(risky, res)
+		}
+	}
+	if len(risky) == 0 {
+		return nil
+	}
+	if !r.Config.Enabled() {
+		return fmt.Errorf("email notification requires email.smtp_host and email.to")
+	}
+
+	msg, err := r.buildMessage(results, risky, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	var auth smtp.Auth
+	if r.Config.Username != "" {
+		auth = smtp.PlainAuth("", r.Config.Username, r.Config.Password, r.Config.SMTPHost)
+	}
+	if err := r.SendMail(r.Config.Addr(), auth, r.Config.From, r.Config.To, msg); err != nil {
+		return fmt.Errorf("failed to send email via %s: %w", r.Config.Addr(), err)
+	}
+	return nil
+}
+
+// buildMessage 构造 multipart/alternative 邮件 (纯文本与 HTML 两个部分)
+func (r *EmailReporter) buildMessage(results []*types.ScanResult, risky []*types.ScanResult, now time.Time) ([]byte, error) {
+	host, _ := os.Hostname()
+	counts := make(map[types.RiskLevel]int)
+	for _, res := range results {
+		counts[res.OverallRisk]++
+	}
+	data := struct {
+		RiskyCount int
+		Host       string
+		Time       string
+		ScanRoot   string
+		Summary    []emailSummaryRow
+		Total      int
+		Files      []emailFile
+	}{
+		RiskyCount: len(risky),
+		Host:       host,
+		Time:       now.Format("2006-01-02 15:04:05"),
+		ScanRoot:   r.ScanRoot,
+		Total:      len(results),
+	}
+	for _, level := range []types.RiskLevel{types.RiskCritical, types.RiskHigh, types.RiskMedium, types.RiskLow, types.RiskNone, types.RiskUnknown} {
+		if counts[level] > 0 {
+			data.Summary = append(data.Summary, emailSummaryRow{Level: level.String(), Count: counts[level]})
+		}
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "BT-ShieldML: %d high-risk file(s) found on %s (%d files scanned)\n\n", len(risky), host, len(results))
+	for _, res := range SortResults(risky, SortByRiskDesc, SortByPathAsc) {
+		file := emailFile{Path: res.File.Path, Risk: res.OverallRisk.String(), Color: "#e67e22"}
+		if res.OverallRisk >= types.RiskCritical {
+			file.Color = "#c0392b"
+		}
+		for i, f := range res.Findings {
+			if i == emailMaxFindings {
+				file.Findings = append(file.Findings, fmt.Sprintf("... %d more", len(res.Findings)-i))
+				break
+			}
+			file.Findings = append(file.Findings, fmt.Sprintf("[%s] %s", f.AnalyzerName, f.Description))
+		}
+		data.Files = append(data.Files, file)
+		fmt.Fprintf(&text, "%-8s %s\n", file.Risk, file.Path)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	textPart.Write([]byte(text.String()))
+	htmlPart, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	if err := emailTemplate.Execute(htmlPart, data); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", r.Config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(r.Config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [BT-ShieldML] %d high-risk file(s) found on %s\r\n", len(risky), host)
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,312 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 邮件通知测试：在本地端口启动模拟 SMTP 服务器，校验认证、信封与 multipart 邮件内容
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// smtpSession 模拟 SMTP 服务器收到的一次会话
+type smtpSession struct {
+	Auth string // AUTH PLAIN 解码后的凭据 (\x00 分隔)
+	From string
+	To   []string
+	Data string
+}
+
+// mockSMTPServer 只实现 smtp.SendMail 用到的命令 (EHLO、AUTH PLAIN、MAIL、RCPT、DATA、QUIT)，
+// rejectRcpt 不为空时以 550 拒绝该收件人
+type mockSMTPServer struct {
+	ln         net.Listener
+	rejectRcpt string
+
+	mu       sync.Mutex
+	sessions []smtpSession
+	wg       sync.WaitGroup
+}
+
+// newMockSMTPServer 在 127.0.0.1 的随机端口上启动模拟 SMTP 服务器，测试结束时关闭
+func newMockSMTPServer(t *testing.T) *mockSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &mockSMTPServer{ln: ln}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+// config 指向模拟服务器的邮件配置
+func (s *mockSMTPServer) config() types.Email {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return types.Email{
+		SMTPHost: addr.IP.String(),
+		SMTPPort: addr.Port,
+		From:     "shieldml@example.com",
+		To:       []string{"ops@example.com", "sec@example.com"},
+	}
+}
+
+// Sessions 已完成 (收到 DATA) 的会话
+func (s *mockSMTPServer) Sessions() []smtpSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpSession(nil), s.sessions...)
+}
+
+func (s *mockSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var session smtpSession
+	reply("220 mock ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-mock")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH PLAIN "):
+			decoded, err := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+			if err != nil {
+				reply("501 invalid base64")
+				continue
+			}
+			session.Auth = string(decoded)
+			reply("235 authenticated")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			session.From = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			rcpt := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if rcpt == s.rejectRcpt {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			session.To = append(session.To, rcpt)
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			session.Data = data.String()
+			s.mu.Lock()
+			s.sessions = append(s.sessions, session)
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// emailTestResults 一个 Critical、一个 High (7 条发现) 与一个正常文件
+func emailTestResults() []*types.ScanResult {
+	high := &types.ScanResult{File: types.FileInfo{Path: "/www/uploads/b<script>.php"}, OverallRisk: types.RiskHigh}
+	for i := 0; i < emailMaxFindings+2; i++ {
+		high.Findings = append(high.Findings, &types.Finding{AnalyzerName: "regex", Description: "pattern " + strconv.Itoa(i), Risk: types.RiskHigh})
+	}
+	return []*types.ScanResult{
+		{File: types.FileInfo{Path: "/www/index.php"}, OverallRisk: types.RiskNone},
+		high,
+		{
+			File:        types.FileInfo{Path: "/www/shell.php"},
+			OverallRisk: types.RiskCritical,
+			Findings:    []*types.Finding{{AnalyzerName: "yara", Description: "webshell_eval", Risk: types.RiskCritical}},
+		},
+	}
+}
+
+// readEmailParts 解析邮件，返回头部与按 Content-Type 索引的各部分正文
+func readEmailParts(t *testing.T, data string) (mail.Header, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", msg.Header.Get("Content-Type"), err)
+	}
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[part.Header.Get("Content-Type")] = string(body)
+	}
+	return msg.Header, parts
+}
+
+func TestEmailReporterSendsMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantAuth string
+	}{
+		{"unauthenticated relay", "", ""},
+		{"plain auth", "mailer", "\x00mailer\x00s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newMockSMTPServer(t)
+			cfg := srv.config()
+			if tt.username != "" {
+				cfg.Username, cfg.Password = tt.username, "s3cret"
+			}
+			r := NewEmailReporter(cfg)
+			r.ScanRoot = "/www"
+			if err := r.Generate(emailTestResults(), ""); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			sessions := srv.Sessions()
+			if len(sessions) != 1 {
+				t.Fatalf("server received %d messages, want 1", len(sessions))
+			}
+			s := sessions[0]
+			if s.Auth != tt.wantAuth {
+				t.Errorf("AUTH = %q, want %q", s.Auth, tt.wantAuth)
+			}
+			if s.From != cfg.From || strings.Join(s.To, ",") != strings.Join(cfg.To, ",") {
+				t.Errorf("envelope = %s -> %v, want %s -> %v", s.From, s.To, cfg.From, cfg.To)
+			}
+
+			header, parts := readEmailParts(t, s.Data)
+			if got := header.Get("To"); got != "ops@example.com, sec@example.com" {
+				t.Errorf("To = %q", got)
+			}
+			if subject := header.Get("Subject"); !strings.HasPrefix(subject, "[BT-ShieldML] 2 high-risk file(s) found on ") {
+				t.Errorf("Subject = %q", subject)
+			}
+			if _, err := header.Date(); err != nil {
+				t.Errorf("Date header: %v", err)
+			}
+
+			text, ok := parts["text/plain; charset=UTF-8"]
+			if !ok {
+				t.Fatalf("missing text/plain part, got %v", parts)
+			}
+			// 按风险降序：Critical 在 High 之前，正常文件不列出
+			critical, high := strings.Index(text, "/www/shell.php"), strings.Index(text, "/www/uploads/b<script>.php")
+			if critical < 0 || high < 0 || critical > high || strings.Contains(text, "/www/index.php") {
+				t.Errorf("text part does not list the risky files by risk:\n%s", text)
+			}
+
+			html, ok := parts["text/html; charset=UTF-8"]
+			if !ok {
+				t.Fatalf("missing text/html part, got %v", parts)
+			}
+			for _, want := range []string{
+				"for <code>/www</code>",
+				"<tr><td>Critical</td><td align=\"right\">1</td></tr>",
+				"<tr><td>High</td><td align=\"right\">1</td></tr>",
+				"<td align=\"right\"><b>3</b></td>",
+				"[yara] webshell_eval",
+				"b&lt;script&gt;.php",
+				"[regex] pattern 4<br>",
+				"... 2 more",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("HTML part missing %q:\n%s", want, html)
+				}
+			}
+			if strings.Contains(html, "b<script>") || strings.Contains(html, "pattern 5") {
+				t.Errorf("HTML part is not escaped or not truncated:\n%s", html)
+			}
+		})
+	}
+}
+
+// TestEmailReporterNoRiskyFiles 没有 High/Critical 文件时不连接 SMTP 服务器，也不要求配置
+func TestEmailReporterNoRiskyFiles(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	results := []*types.ScanResult{
+		{File: types.FileInfo{Path: "/www/a.php"}, OverallRisk: types.RiskMedium},
+		{File: types.FileInfo{Path: "/www/b.php"}, OverallRisk: types.RiskNone},
+	}
+	for _, cfg := range []types.Email{srv.config(), {}} {
+		if err := NewEmailReporter(cfg).Generate(results, ""); err != nil {
+			t.Errorf("Generate() error = %v, want nil", err)
+		}
+	}
+	if n := len(srv.Sessions()); n != 0 {
+		t.Errorf("server received %d messages, want 0", n)
+	}
+}
+
+func TestEmailReporterErrors(t *testing.T) {
+	srv := newMockSMTPServer(t)
+	srv.rejectRcpt = "sec@example.com"
+
+	if err := NewEmailReporter(types.Email{From: "shieldml@example.com"}).Generate(emailTestResults(), ""); err == nil {
+		t.Error("Generate() without smtp_host error = nil, want an error")
+	}
+	err := NewEmailReporter(srv.config()).Generate(emailTestResults(), "")
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("Generate() with a rejected recipient error = %v, want the 550 reply", err)
+	}
+	if n := len(srv.Sessions()); n != 0 {
+		t.Errorf("server received %d messages, want 0", n)
+	}
+}
@@ -0,0 +1,369 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: Excel (.xlsx) 导出：由 JSON 报告生成 Summary、Results、Findings 三个工作表 (-export-excel、POST /api/v1/export/excel)，
+ * 或每个文件一行的 Scan Report 工作表 (shieldml_server 的 GET /api/export-xlsx)
+ */
+package reporting
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ExcelContentType .xlsx 文件的 MIME 类型
+const ExcelContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// 工作表名称
+const (
+	excelSummarySheet    = "Summary"
+	excelResultsSheet    = "Results"
+	excelFindingsSheet   = "Findings"
+	excelScanReportSheet = "Scan Report"
+)
+
+// excelResultsHeader Results 工作表的列，风险等级在 C 列 (条件格式按该列着色)
+var excelResultsHeader = []interface{}{"Filename", "Full Path", "Risk Level", "Score", "Analyzers", "Description", "File Size", "Mod Time", "MD5"}
+
+// excelFindingsHeader Findings 工作表的列，风险等级在 C 列
+var excelFindingsHeader = []interface{}{"Filename", "Full Path", "Risk Level", "Analyzer", "Description"}
+
+// excelScanReportHeader Scan Report 工作表的列，包含正常文件
+var excelScanReportHeader = []interface{}{
+	"File Path", "File Name", "File Size", "Risk Level", "Risk Score",
+	"Analyzer Names", "Finding Descriptions", "MD5", "SHA256", "Scan Duration (ms)",
+}
+
+// excelScanReportFills Scan Report 各风险等级的行填充色
+var excelScanReportFills = map[string]string{
+	"Critical": "FF9999",
+	"High":     "FFCC99",
+	"Medium":   "FFF5CC",
+	"Low":      "FFF5CC",
+	"None":     "C6EFCE",
+}
+
+// JSONReport -output report.json 与 POST /api/v1/scan 输出的 JSON 报告中导出所需的部分
+type JSONReport struct {
+	Results  []SimpleResult `json:"results"`
+	ScanRoot string         `json:"scan_root,omitempty"`
+}
+
+/**
+ * @Description: 读取 JSON 报告
+ * @author: Mr wpl
+ * @param path string: 报告路径
+ * @return *JSONReport: 报告
+ * @return time.Time: 报告文件的修改时间，作为扫描时间
+ * @return error: 读取或解析失败时返回错误
+ */
+func ReadJSONReport(path string) (*JSONReport, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read JSON report: %w", err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid JSON report %s: %w", path, err)
+	}
+	var scanTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		scanTime = info.ModTime()
+	}
+	return &report, scanTime, nil
+}
+
+/**
+ * @Description: 将 JSON 报告写为 .xlsx：Summary 为各类文件数与扫描时间，Results 每个问题文件一行，
+ * Findings 每条发现一行；Critical/High 行红色、Medium/Low 行橙色 (条件格式，按风险等级列)。
+ * 修改时间从磁盘读取；MD5 优先使用报告中扫描时的值 (旧报告没有时从磁盘计算)，文件已不存在 (如归档成员) 时留空
+ * @author: Mr wpl
+ * @param w io.Writer: 输出
+ * @param report *JSONReport: JSON 报告
+ * @param scanTime time.Time: 扫描时间，为零时不输出
+ * @return error: 错误
+ */
+func WriteExcel(w io.Writer, report *JSONReport, scanTime time.Time) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", excelSummarySheet); err != nil {
+		return err
+	}
+	for _, sheet := range []string{excelResultsSheet, excelFindingsSheet} {
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+	}
+	headerStyle, err := newExcelHeaderStyle(f)
+	if err != nil {
+		return err
+	}
+
+	if err := writeExcelSummary(f, report, scanTime, headerStyle); err != nil {
+		return err
+	}
+	resultRows, findingRows, err := writeExcelResults(f, report, headerStyle)
+	if err != nil {
+		return err
+	}
+	if err := applyRiskFill(f, excelResultsSheet, len(excelResultsHeader), resultRows); err != nil {
+		return err
+	}
+	if err := applyRiskFill(f, excelFindingsSheet, len(excelFindingsHeader), findingRows); err != nil {
+		return err
+	}
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+/**
+ * @Description: 将 JSON 报告写为单个 Scan Report 工作表：每个文件一行 (包括正常文件)，
+ * 按风险等级整行填充 Critical 红色、High 橙色、Medium/Low 黄色、None 绿色
+ * @author: Mr wpl
+ * @param w io.Writer: 输出
+ * @param report *JSONReport: JSON 报告
+ * @return error: 错误
+ */
+func WriteExcelScanReport(w io.Writer, report *JSONReport) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := excelScanReportSheet
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+	headerStyle, err := newExcelHeaderStyle(f)
+	if err != nil {
+		return err
+	}
+	if err := f.SetSheetRow(sheet, "A1", &excelScanReportHeader); err != nil {
+		return err
+	}
+	endCell, _ := excelize.CoordinatesToCellName(len(excelScanReportHeader), 1)
+	if err := f.SetCellStyle(sheet, "A1", endCell, headerStyle); err != nil {
+		return err
+	}
+
+	rowStyles := make(map[string]int, len(excelScanReportFills))
+	for level, color := range excelScanReportFills {
+		styleID, err := f.NewStyle(&excelize.Style{
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+			Alignment: &excelize.Alignment{Vertical: "top", WrapText: true},
+		})
+		if err != nil {
+			return err
+		}
+		rowStyles[level] = styleID
+	}
+
+	for i, res := range report.Results {
+		level := excelRiskLevel(res.Risk)
+		row := []interface{}{
+			res.Path, res.Filename, res.Size, level, res.Risk,
+			strings.Join(res.Analyzers, "\n"), strings.Join(res.Findings, "\n"), res.MD5, res.SHA256, res.DurationMs,
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+		endCell, _ := excelize.CoordinatesToCellName(len(row), i+2)
+		if err := f.SetCellStyle(sheet, cell, endCell, rowStyles[level]); err != nil {
+			return err
+		}
+	}
+
+	widths := []struct {
+		from, to string
+		width    float64
+	}{{"A", "A", 40}, {"B", "B", 25}, {"F", "G", 40}, {"H", "I", 34}}
+	for _, w := range widths {
+		if err := f.SetColWidth(sheet, w.from, w.to, w.width); err != nil {
+			return err
+		}
+	}
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// newExcelHeaderStyle 表头样式：蓝底白色粗体
+func newExcelHeaderStyle(f *excelize.File) (int, error) {
+	return f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"0070C0"}},
+	})
+}
+
+// writeExcelSummary 写 Summary 工作表：总数、木马、疑似、正常文件数 (与 JSON 报告的 risk_text 分类一致) 及扫描时间
+func writeExcelSummary(f *excelize.File, report *JSONReport, scanTime time.Time, headerStyle int) error {
+	var critical, suspicious, normal int
+	for _, res := range report.Results {
+		switch {
+		case res.Risk >= 5:
+			critical++
+		case res.Risk > 0:
+			suspicious++
+		default:
+			normal++
+		}
+	}
+	rows := [][]interface{}{
+		{"Item", "Value"},
+		{"Total Files", len(report.Results)},
+		{"Critical", critical},
+		{"Suspicious", suspicious},
+		{"Normal", normal},
+	}
+	if !scanTime.IsZero() {
+		rows = append(rows, []interface{}{"Scan Time", scanTime.Format("2006-01-02 15:04:05")})
+	}
+	if report.ScanRoot != "" {
+		rows = append(rows, []interface{}{"Scan Root", report.ScanRoot})
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(excelSummarySheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(excelSummarySheet, "A1", "B1", headerStyle); err != nil {
+		return err
+	}
+	return f.SetColWidth(excelSummarySheet, "A", "B", 20)
+}
+
+// writeExcelResults 写 Results 与 Findings 工作表 (只包含有风险的文件)，返回两个工作表的数据行数
+func writeExcelResults(f *excelize.File, report *JSONReport, headerStyle int) (int, int, error) {
+	for sheet, header := range map[string][]interface{}{excelResultsSheet: excelResultsHeader, excelFindingsSheet: excelFindingsHeader} {
+		if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+			return 0, 0, err
+		}
+		endCell, _ := excelize.CoordinatesToCellName(len(header), 1)
+		if err := f.SetCellStyle(sheet, "A1", endCell, headerStyle); err != nil {
+			return 0, 0, err
+		}
+		if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	resultRows, findingRows := 0, 0
+	for _, res := range report.Results {
+		if res.Risk <= 0 {
+			continue
+		}
+		level := excelRiskLevel(res.Risk)
+		description := res.Desc
+		if len(res.Findings) > 0 {
+			description = strings.Join(res.Findings, "\n")
+		}
+		modTime, md5sum := excelFileInfo(res.Path)
+		if res.MD5 != "" {
+			md5sum = res.MD5 // 扫描时的内容哈希，文件之后可能已被修改或删除
+		}
+		row := []interface{}{res.Filename, res.Path, level, res.Risk, strings.Join(res.Analyzers, ", "), description, res.Size, modTime, md5sum}
+		resultRows++
+		cell, _ := excelize.CoordinatesToCellName(1, resultRows+1)
+		if err := f.SetSheetRow(excelResultsSheet, cell, &row); err != nil {
+			return 0, 0, err
+		}
+
+		// Analyzers 与 Findings 按发现一一对应
+		for i, finding := range res.Findings {
+			analyzer := ""
+			if i < len(res.Analyzers) {
+				analyzer = res.Analyzers[i]
+			}
+			row := []interface{}{res.Filename, res.Path, level, analyzer, finding}
+			findingRows++
+			cell, _ := excelize.CoordinatesToCellName(1, findingRows+1)
+			if err := f.SetSheetRow(excelFindingsSheet, cell, &row); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	widths := []struct {
+		sheet, from, to string
+		width           float64
+	}{
+		{excelResultsSheet, "A", "A", 25},
+		{excelResultsSheet, "B", "B", 50},
+		{excelResultsSheet, "E", "F", 40},
+		{excelResultsSheet, "H", "H", 20},
+		{excelResultsSheet, "I", "I", 34},
+		{excelFindingsSheet, "A", "A", 25},
+		{excelFindingsSheet, "B", "B", 50},
+		{excelFindingsSheet, "D", "D", 16},
+		{excelFindingsSheet, "E", "E", 60},
+	}
+	for _, w := range widths {
+		if err := f.SetColWidth(w.sheet, w.from, w.to, w.width); err != nil {
+			return 0, 0, err
+		}
+	}
+	return resultRows, findingRows, nil
+}
+
+// applyRiskFill 按 C 列风险等级设置条件格式：Critical/High 红色，Medium/Low 橙色
+func applyRiskFill(f *excelize.File, sheet string, columns int, rows int) error {
+	if rows == 0 {
+		return nil
+	}
+	red, err := f.NewConditionalStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}}})
+	if err != nil {
+		return err
+	}
+	orange, err := f.NewConditionalStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFDCA8"}}})
+	if err != nil {
+		return err
+	}
+	endCell, _ := excelize.CoordinatesToCellName(columns, rows+1)
+	return f.SetConditionalFormat(sheet, "A2:"+endCell, []excelize.ConditionalFormatOptions{
+		{Type: "formula", Criteria: `OR($C2="Critical",$C2="High")`, Format: &red},
+		{Type: "formula", Criteria: `OR($C2="Medium",$C2="Low")`, Format: &orange},
+	})
+}
+
+// excelRiskLevel JSON 报告的风险分数 (0, 1, 3, 4, 5) 对应的风险等级名称，正常文件为 None
+func excelRiskLevel(score int) string {
+	switch {
+	case score >= 5:
+		return "Critical"
+	case score == 4:
+		return "High"
+	case score >= 2:
+		return "Medium"
+	case score == 1:
+		return "Low"
+	}
+	return "None"
+}
+
+// excelFileInfo 读取文件的修改时间与 MD5，文件不存在或无法读取时返回空字符串
+func excelFileInfo(path string) (string, string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return "", ""
+	}
+	h := md5.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return info.ModTime().Format("2006-01-02 15:04:05"), ""
+	}
+	return info.ModTime().Format("2006-01-02 15:04:05"), hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,72 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: Excel 导出测试：Scan Report 工作表的列、每个文件一行 (含正常文件) 与按风险等级的行填充色
+ */
+package reporting
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteExcelScanReport(t *testing.T) {
+	report := &JSONReport{Results: []SimpleResult{
+		{Filename: "shell.php", Path: "/www/shell.php", Risk: 5, Size: 120, MD5: "m1", SHA256: "s1", DurationMs: 42,
+			Analyzers: []string{"yara", "regex"}, Findings: []string{"webshell_eval", "eval($_POST)"}},
+		{Filename: "high.php", Path: "/www/high.php", Risk: 4, DurationMs: 7},
+		{Filename: "medium.php", Path: "/www/medium.php", Risk: 3},
+		{Filename: "low.php", Path: "/www/low.php", Risk: 1},
+		{Filename: "index.php", Path: "/www/index.php", Risk: 0, Size: 10, MD5: "m5", SHA256: "s5", DurationMs: 3},
+	}}
+	var buf bytes.Buffer
+	if err := WriteExcelScanReport(&buf, report); err != nil {
+		t.Fatalf("WriteExcelScanReport() error = %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("output is not a valid .xlsx: %v", err)
+	}
+	defer f.Close()
+	if sheets := f.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Scan Report"}) {
+		t.Fatalf("sheets = %v, want [Scan Report]", sheets)
+	}
+	rows, err := f.GetRows("Scan Report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRows := [][]string{
+		{"File Path", "File Name", "File Size", "Risk Level", "Risk Score", "Analyzer Names", "Finding Descriptions", "MD5", "SHA256", "Scan Duration (ms)"},
+		{"/www/shell.php", "shell.php", "120", "Critical", "5", "yara\nregex", "webshell_eval\neval($_POST)", "m1", "s1", "42"},
+		{"/www/high.php", "high.php", "0", "High", "4", "", "", "", "", "7"},
+		{"/www/medium.php", "medium.php", "0", "Medium", "3", "", "", "", "", "0"},
+		{"/www/low.php", "low.php", "0", "Low", "1", "", "", "", "", "0"},
+		{"/www/index.php", "index.php", "10", "None", "0", "", "", "m5", "s5", "3"},
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("rows =\n%q\nwant\n%q", rows, wantRows)
+	}
+
+	// 整行 (A 到 J 列) 按风险等级填充
+	wantFills := []string{"FF9999", "FFCC99", "FFF5CC", "FFF5CC", "C6EFCE"}
+	for i, want := range wantFills {
+		for _, col := range []string{"A", "J"} {
+			cell := col + string(rune('2'+i))
+			styleID, err := f.GetCellStyle("Scan Report", cell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			style, err := f.GetStyle(styleID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(style.Fill.Color) != 1 || style.Fill.Color[0] != want {
+				t.Errorf("%s fill = %v, want %s", cell, style.Fill.Color, want)
+			}
+		}
+	}
+}
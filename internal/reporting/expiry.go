@@ -0,0 +1,154 @@
+/*
+ * @Date: 2025-06-19 09:36:51
+ * @Editors: Mr wpl
+ * @Description: 报告过期时间：JSON 报告写入 expires_at 字段，HTML 报告写入 scan-expires meta 标签，过期报告可由 cleanup-reports 或定时清理删除
+ */
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExpiresMetaName HTML 报告中记录过期时间的 meta 标签名
+const ExpiresMetaName = "scan-expires"
+
+// htmlHeadLimit 读取 HTML 报告头部的字节数，meta 标签位于 <head> 开头
+const htmlHeadLimit = 8 * 1024
+
+var expiresMetaRe = regexp.MustCompile(`<meta name="` + ExpiresMetaName + `" content="([^"]*)">`)
+
+/**
+ * @Description: 解析报告保留时长，支持天数 (如 30d) 与 time.ParseDuration 的格式 (如 12h)
+ * @author: Mr wpl
+ * @param s string: 保留时长
+ * @return time.Duration: 时长
+ * @return error: 错误
+ */
+func ParseTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid report TTL %q: expected a positive number of days such as 30d", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid report TTL %q: expected e.g. 30d or 72h", s)
+	}
+	return d, nil
+}
+
+// expiresMetaTag HTML 报告的过期时间 meta 标签，未设置过期时间时为空
+func expiresMetaTag(expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("\n    <meta name=\"%s\" content=\"%s\">", ExpiresMetaName, html.EscapeString(expiresAt.Format(time.RFC3339)))
+}
+
+/**
+ * @Description: 读取报告的过期时间，JSON 读取 expires_at 字段，HTML 读取 scan-expires meta 标签
+ * @author: Mr wpl
+ * @param path string: 报告路径 (.json 或 .html)
+ * @return time.Time: 过期时间
+ * @return bool: 报告是否带有过期时间
+ * @return error: 错误
+ */
+func ReadReportExpiry(path string) (time.Time, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer f.Close()
+
+	var raw string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var meta struct {
+			ExpiresAt string `json:"expires_at"`
+		}
+		if err := json.NewDecoder(f).Decode(&meta); err != nil {
+			return time.Time{}, false, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		raw = meta.ExpiresAt
+	case ".html":
+		head := make([]byte, htmlHeadLimit)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return time.Time{}, false, err
+		}
+		if m := expiresMetaRe.FindSubmatch(head[:n]); m != nil {
+			raw = html.UnescapeString(string(m[1]))
+		}
+	default:
+		return time.Time{}, false, fmt.Errorf("unsupported report type: %s", path)
+	}
+
+	if raw == "" {
+		return time.Time{}, false, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid expiry %q in %s: %w", raw, path, err)
+	}
+	return expiresAt, true, nil
+}
+
+// ExpiredReport 已过期的报告
+type ExpiredReport struct {
+	Path      string
+	ExpiresAt time.Time
+}
+
+/**
+ * @Description: 删除目录 (不含子目录) 中已过期的 .html 与 .json 报告。没有过期时间或无法解析的文件保留
+ * @author: Mr wpl
+ * @param dir string: 报告目录
+ * @param now time.Time: 当前时间
+ * @param dryRun bool: 只列出不删除
+ * @return []ExpiredReport: 已过期 (dryRun 时为将被删除) 的报告
+ * @return []error: 无法解析或删除的文件
+ */
+func CleanupExpiredReports(dir string, now time.Time, dryRun bool) ([]ExpiredReport, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var expired []ExpiredReport
+	var errs []error
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".html" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		expiresAt, ok, err := ReadReportExpiry(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok || !expiresAt.Before(now) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		expired = append(expired, ExpiredReport{Path: path, ExpiresAt: expiresAt})
+	}
+	return expired, errs
+}
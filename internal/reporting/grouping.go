@@ -0,0 +1,40 @@
+/*
+ * @Date: 2025-05-22 15:20:11
+ * @Editors: Mr wpl
+ * @Description: 扫描结果按目录分组
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"path/filepath"
+	"sort"
+)
+
+/**
+ * @Description: 按文件所在目录 (优先相对路径) 对扫描结果分组，各组内保持原有顺序
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @return map[string][]*types.ScanResult: 目录 -> 扫描结果
+ */
+func GroupResultsByDirectory(results []*types.ScanResult) map[string][]*types.ScanResult {
+	groups := make(map[string][]*types.ScanResult)
+	for _, res := range results {
+		if res == nil {
+			continue
+		}
+		dir := filepath.Dir(res.File.DisplayPath())
+		groups[dir] = append(groups[dir], res)
+	}
+	return groups
+}
+
+// sortedDirectories 返回按字母顺序排列的目录列表
+func sortedDirectories(groups map[string][]*types.ScanResult) []string {
+	dirs := make([]string, 0, len(groups))
+	for dir := range groups {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
@@ -0,0 +1,217 @@
+/*
+ * @Date: 2025-06-23 10:15:32
+ * @Editors: Mr wpl
+ * @Description: HTML 报告的历史趋势：从扫描日志读取同一目录的历次扫描，以纯 CSS 柱状图显示各风险等级文件数的变化
+ */
+package reporting
+
+import (
+	"bt-shieldml/internal/platform"
+	"bt-shieldml/internal/scanstats"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"html"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// htmlHistoryLimit HTML 报告中显示的最近扫描次数
+const htmlHistoryLimit = 10
+
+// historyLevels 趋势图中显示的风险等级，从柱底到柱顶
+var historyLevels = []types.RiskLevel{types.RiskLow, types.RiskMedium, types.RiskHigh, types.RiskCritical}
+
+// HistoricalSummary 一次历史扫描的各风险等级文件数
+type HistoricalSummary struct {
+	ScanTime   time.Time
+	RiskCounts map[types.RiskLevel]int
+}
+
+/**
+ * @Description: 读取扫描日志中同一扫描根目录的历次扫描，按时间升序返回最近的 limit 次。
+ * 日志不存在或无法读取时返回 nil
+ * @author: Mr wpl
+ * @param journalPath string: 扫描日志路径
+ * @param scanRoot string: 扫描根目录
+ * @param limit int: 最多返回的次数，<= 0 时不限制
+ * @return []HistoricalSummary: 历次扫描汇总
+ */
+func LoadHistoricalSummaries(journalPath string, scanRoot string, limit int) []HistoricalSummary {
+	entries, err := scanstats.ReadJournal(journalPath)
+	if err != nil || scanRoot == "" {
+		return nil
+	}
+	root := platform.NormalizePath(filepath.Clean(scanRoot))
+
+	var summaries []HistoricalSummary
+	for _, entry := range entries {
+		if entry.ScanRoot == "" || platform.NormalizePath(filepath.Clean(entry.ScanRoot)) != root {
+			continue
+		}
+		summary := HistoricalSummary{ScanTime: entry.Timestamp, RiskCounts: make(map[types.RiskLevel]int)}
+		for _, file := range entry.Files {
+			level, err := types.ParseRiskLevel(file.Risk)
+			if err != nil {
+				continue
+			}
+			summary.RiskCounts[level]++
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].ScanTime.Before(summaries[j].ScanTime)
+	})
+	if limit > 0 && len(summaries) > limit {
+		summaries = summaries[len(summaries)-limit:]
+	}
+	return summaries
+}
+
+// htmlHistoryCSS 趋势图样式，柱高按最大检出数归一化
+const htmlHistoryCSS = `
+        .history-trend {
+            margin: 15px 0;
+            padding: 15px 20px;
+            background-color: var(--surface-bg);
+            border-radius: 6px;
+        }
+
+        .history-trend h3 {
+            margin: 0 0 6px 0;
+            font-size: 15px;
+        }
+
+        .history-caption {
+            color: var(--light-text);
+            font-size: 13px;
+            margin-bottom: 10px;
+        }
+
+        .history-bars {
+            display: flex;
+            gap: 12px;
+        }
+
+        .history-bar {
+            display: flex;
+            flex-direction: column;
+            align-items: center;
+            flex: 1;
+            max-width: 60px;
+        }
+
+        .history-track {
+            display: flex;
+            align-items: flex-end;
+            width: 100%;
+            height: 70px;
+        }
+
+        .history-stack {
+            display: flex;
+            flex-direction: column-reverse;
+            width: 100%;
+            min-height: 2px;
+            background-color: var(--border-color);
+            border-radius: 3px 3px 0 0;
+            overflow: hidden;
+        }
+
+        .history-seg-critical { background-color: var(--risk-critical); }
+        .history-seg-high { background-color: var(--risk-high); filter: brightness(1.15); }
+        .history-seg-medium { background-color: var(--risk-medium); }
+        .history-seg-low { background-color: var(--risk-low); filter: brightness(1.15); }
+
+        .history-label {
+            margin-top: 4px;
+            font-size: 11px;
+            color: var(--light-text);
+            white-space: nowrap;
+        }`
+
+/**
+ * @Description: 生成历史趋势 HTML，少于两次扫描时返回空字符串。每次扫描一根柱，按风险等级分段，
+ * 高度按所有扫描中最大检出数归一化，鼠标悬停显示扫描时间与各等级文件数
+ * @author: Mr wpl
+ * @param summaries []HistoricalSummary: 按时间升序的历次扫描
+ * @param now time.Time: 当前时间，用于显示 "今天"、"N 天前"
+ * @return string: HTML 片段
+ */
+func historyHTML(summaries []HistoricalSummary, now time.Time) string {
+	if len(summaries) < 2 {
+		return ""
+	}
+	detected := func(s HistoricalSummary) int {
+		total := 0
+		for _, level := range historyLevels {
+			total += s.RiskCounts[level]
+		}
+		return total
+	}
+	maxCount := 0
+	for _, s := range summaries {
+		if n := detected(s); n > maxCount {
+			maxCount = n
+		}
+	}
+
+	first, last := summaries[0], summaries[len(summaries)-1]
+	var b strings.Builder
+	b.WriteString(`
+        <div class="history-trend">
+            <h3><i class="fas fa-chart-bar"></i> 历史趋势</h3>
+            <div class="history-caption">`)
+	fmt.Fprintf(&b, "%s：%d 个 Critical，%s：%d 个 Critical",
+		relativeDay(first.ScanTime, now), first.RiskCounts[types.RiskCritical],
+		relativeDay(last.ScanTime, now), last.RiskCounts[types.RiskCritical])
+	b.WriteString(`</div>
+            <div class="history-bars">`)
+	for _, s := range summaries {
+		counts := make([]string, 0, len(historyLevels))
+		for i := len(historyLevels) - 1; i >= 0; i-- {
+			counts = append(counts, fmt.Sprintf("%s %d", historyLevels[i], s.RiskCounts[historyLevels[i]]))
+		}
+		title := s.ScanTime.Local().Format("2006-01-02 15:04") + " - " + strings.Join(counts, ", ")
+		fmt.Fprintf(&b, `
+                <div class="history-bar" title="%s">
+                    <div class="history-track"><div class="history-stack" style="height: %.1f%%;">`, html.EscapeString(title), barPercent(detected(s), maxCount))
+		for _, level := range historyLevels {
+			if n := s.RiskCounts[level]; n > 0 {
+				fmt.Fprintf(&b, `<div class="history-seg-%s" style="flex: %d;"></div>`, strings.ToLower(level.String()), n)
+			}
+		}
+		fmt.Fprintf(&b, `</div></div>
+                    <div class="history-label">%s</div>
+                </div>`, html.EscapeString(relativeDay(s.ScanTime, now)))
+	}
+	b.WriteString(`
+            </div>
+        </div>`)
+	return b.String()
+}
+
+// barPercent 柱高占最大值的百分比，没有检出时为 0 (只显示底线)
+func barPercent(n, maxCount int) float64 {
+	if maxCount == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(maxCount)
+}
+
+// relativeDay 相对 now 的日期描述：今天、昨天、N 天前
+func relativeDay(t time.Time, now time.Time) string {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	y, m, d = t.In(now.Location()).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	switch days := int(today.Sub(day).Hours() / 24); {
+	case days <= 0:
+		return "今天"
+	case days == 1:
+		return "昨天"
+	default:
+		return fmt.Sprintf("%d 天前", days)
+	}
+}
@@ -3,17 +3,32 @@ package reporting
 import (
 	"bt-shieldml/pkg/logging"
 	"bt-shieldml/pkg/types"
-	"crypto/md5"
 	"fmt"
 	"html"
 	"io/ioutil"
+	"math"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 )
 
-type HtmlReporter struct{}
+// htmlPaginationThreshold 问题文件超过该数量时，报告表格启用分页
+const htmlPaginationThreshold = 500
+
+// htmlPageSize 分页时每页显示的问题文件数
+const htmlPageSize = 50
+
+type HtmlReporter struct {
+	ScanRoot string    // 扫描根目录，显示在报告头部
+	SortKeys []SortKey // 问题文件排序键，为空时按风险降序、路径升序
+	Verbose  bool      // 在详情中显示被 min_confidence 抑制的发现
+	// ExpiresAt 报告过期时间，非零时写入 scan-expires meta 标签
+	ExpiresAt time.Time
+	// ColorScheme 风险等级配色 (来自 -color-scheme)，为 nil 时使用默认配色
+	ColorScheme *ColorScheme
+	// JournalPath 扫描日志路径，非空且其中有同一 ScanRoot 的多次扫描时在报告顶部显示历史趋势
+	JournalPath string
+}
 
 /**
  * @Description: 创建新的HTML报告
@@ -49,10 +64,6 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 		return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
 	}
 
-	getMD5Placeholder := func(path string) string {
-		return fmt.Sprintf("%x", md5.Sum([]byte(path)))
-	}
-
 	// --- 数据处理 ---
 	scanTime := time.Now().Format("2006-01-02 15:04:05")
 	totalFiles := len(results)
@@ -75,9 +86,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 
 	for _, res := range results {
 		// 统计文件类型
-		fileExt := strings.ToLower(filepath.Ext(res.File.Path))
-		if fileExt != "" {
-			fileExt = fileExt[1:] // 移除点号
+		if fileExt := reportFileType(res.File.Path); fileExt != "" {
 			fileTypeStats[fileExt]++
 		} else {
 			fileTypeStats["unknown"]++
@@ -116,25 +125,12 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 		}
 	}
 
-	// 按风险等级排序：木马文件(Critical) > 疑似木马(High/Medium/Low) > 其他
-	sort.Slice(problemFiles, func(i, j int) bool {
-		// 定义风险等级优先级
-		riskOrder := func(risk types.RiskLevel) int {
-			switch risk {
-			case types.RiskCritical:
-				return 1
-			case types.RiskHigh:
-				return 2
-			case types.RiskMedium:
-				return 3
-			case types.RiskLow:
-				return 4
-			default:
-				return 5
-			}
-		}
-		return riskOrder(problemFiles[i].OverallRisk) < riskOrder(problemFiles[j].OverallRisk)
-	})
+	// 按风险等级排序 (默认)：木马文件(Critical) > 疑似木马(High/Medium/Low) > 其他，同级按路径
+	keys := r.SortKeys
+	if len(keys) == 0 {
+		keys = []SortKey{SortByRiskDesc, SortByPathAsc}
+	}
+	problemFiles = SortResults(problemFiles, keys...)
 
 	// 转换文件类型统计为JSON格式供图表使用
 	var fileTypeLabels []string
@@ -154,6 +150,15 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 	// 确保按顺序显示
 	riskCategories := []string{"疑似木马(1级)", "疑似木马(2级)", "疑似木马(3级)", "木马文件(4级)", "木马文件(5级)"}
 	riskCategoryColors := []string{"#28a745", "#fff5cc", "#ff9900", "#ff3300", "#cc0000"}
+	trendHTML := ""
+	if r.JournalPath != "" {
+		trendHTML = historyHTML(LoadHistoricalSummaries(r.JournalPath, r.ScanRoot, htmlHistoryLimit), time.Now())
+	}
+	colorSchemeCSS := ""
+	if r.ColorScheme != nil {
+		riskCategoryColors = r.ColorScheme.chartColors()
+		colorSchemeCSS = r.ColorScheme.css()
+	}
 
 	for i, category := range riskCategories {
 		if count := riskScoreStats[category]; count > 0 {
@@ -170,7 +175,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 <html lang="zh-CN">
 <head>
     <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">` + expiresMetaTag(r.ExpiresAt) + `
     <title>bt-ShieldML 木马查杀报告</title>
     <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/5.15.4/css/all.min.css">
     <style>
@@ -189,6 +194,9 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             --even-row: #f9f9f9;
             --header-bg: #eaeaea;
             --success-color: #28a745;
+            --container-bg: #ffffff;
+            --surface-bg: #f8f9fa;
+            --surface-hover: #f1f1f1;
         }
         
         body { 
@@ -204,7 +212,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             max-width: 1200px; 
             margin: 5px auto; 
             padding: 15px; 
-            background-color: #ffffff; 
+            background-color: var(--container-bg); 
             border-radius: 8px; 
             box-shadow: 0 2px 10px rgba(0,0,0,0.05); 
         }
@@ -270,7 +278,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             padding: 10px; 
             border: 1px solid var(--border-color); 
             border-radius: 5px; 
-            background-color: #ffffff; 
+            background-color: var(--container-bg); 
             box-shadow: 0 1px 3px rgba(0,0,0,0.05);
         }
         
@@ -430,13 +438,24 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             background-color: #cc2900;
         }
         
+        .pdf-note {
+            display: flex;
+            align-items: center;
+            font-size: 13px;
+            color: var(--light-text);
+        }
+        
+        .pdf-note i {
+            margin-right: 8px;
+        }
+        
         .search-box {
             display: flex;
             align-items: center;
             border: 1px solid var(--border-color);
             border-radius: 4px;
             padding: 5px 10px;
-            background-color: white;
+            background-color: var(--container-bg);
         }
         
         .search-box input {
@@ -452,6 +471,32 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             margin-right: 5px;
         }
         
+        .search-clear {
+            border: none;
+            background: none;
+            color: var(--light-text);
+            font-size: 18px;
+            line-height: 1;
+            padding: 0 2px;
+            cursor: pointer;
+            visibility: hidden;
+        }
+        
+        .search-clear:hover {
+            color: var(--text-color);
+        }
+        
+        .search-count {
+            margin-top: 4px;
+            font-size: 12px;
+            color: var(--light-text);
+            text-align: right;
+        }
+        
+        tr.search-match td {
+            background-color: rgba(255, 193, 7, 0.15);
+        }
+        
         .filters {
             margin-bottom: 15px;
             display: flex;
@@ -461,7 +506,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         
         .filter-btn {
             padding: 6px 12px;
-            background-color: #ffffff;
+            background-color: var(--container-bg);
             border: 1px solid var(--border-color);
             border-radius: 4px;
             margin-left: 8px;
@@ -481,6 +526,24 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             border-color: var(--primary-color);
         }
         
+        .filter-btn:disabled {
+            opacity: 0.5;
+            cursor: not-allowed;
+        }
+        
+        .pagination {
+            display: flex;
+            justify-content: center;
+            align-items: center;
+            margin-top: 15px;
+        }
+        
+        .page-indicator {
+            margin-left: 8px;
+            font-size: 14px;
+            color: var(--text-color);
+        }
+        
         table { 
             width: 100%; 
             border-collapse: collapse; 
@@ -607,7 +670,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             padding: 4px 10px;
             border: 1px solid var(--border-color);
             border-radius: 4px;
-            background-color: white;
+            background-color: var(--container-bg);
             font-size: 12px;
             cursor: pointer;
             color: var(--primary-color);
@@ -675,7 +738,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             display: inline-block;
             position: relative;
             cursor: pointer;
-            background-color: white;
+            background-color: var(--container-bg);
         }
         
         .custom-checkbox.checked:before {
@@ -776,7 +839,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         }
         
         .modal {
-            background-color: white;
+            background-color: var(--container-bg);
             border-radius: 12px;
             box-shadow: 0 10px 25px rgba(0, 0, 0, 0.15);
             width: 75%;
@@ -795,7 +858,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         }
         
         .modal-header {
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             padding: 16px 24px;
             border-bottom: 1px solid var(--border-color);
             display: flex;
@@ -844,7 +907,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         
         .file-details {
             margin-bottom: 30px;
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             border-radius: 8px;
             padding: 20px;
         }
@@ -881,7 +944,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         
         .detail-value {
             word-break: break-all;
-            background-color: white;
+            background-color: var(--container-bg);
             padding: 8px 12px;
             border-radius: 4px;
             border: 1px solid #eee;
@@ -908,7 +971,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         }
         
         .feature-list {
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             border-radius: 8px;
             padding: 5px;
         }
@@ -916,11 +979,21 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         .feature-item {
             padding: 12px 15px;
             margin-bottom: 8px;
-            background-color: white;
+            background-color: var(--container-bg);
             border-radius: 6px;
             border-left: 4px solid var(--primary-color);
             box-shadow: 0 2px 4px rgba(0,0,0,0.05);
         }
+
+        .feature-item.suppressed {
+            border-left-color: var(--border-color);
+            opacity: 0.6;
+        }
+
+        .feature-item.truncated-warning {
+            border-left-color: var(--risk-medium);
+            color: var(--risk-medium);
+        }
         
         .feature-name {
             font-weight: 600;
@@ -935,6 +1008,23 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             font-size: 14px;
         }
         
+        .code-snippet {
+            margin: 0;
+            padding: 10px 12px;
+            background-color: var(--container-bg);
+            border-radius: 6px;
+            font-family: Consolas, Monaco, monospace;
+            font-size: 13px;
+            white-space: pre-wrap;
+            word-break: break-all;
+        }
+        
+        .code-snippet mark {
+            background-color: rgba(233, 71, 71, 0.3);
+            color: inherit;
+            border-radius: 2px;
+        }
+        
         .risk-critical-text {
             color: var(--risk-critical);
             font-weight: 600;
@@ -956,7 +1046,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         }
         
         .recommendation {
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             border-radius: 8px;
             padding: 20px;
         }
@@ -964,7 +1054,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         .recommendation p {
             margin: 0;
             padding: 12px 15px;
-            background-color: white;
+            background-color: var(--container-bg);
             border-radius: 6px;
             border-left: 4px solid var(--primary-color);
             color: var(--text-color);
@@ -976,7 +1066,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             display: flex;
             justify-content: flex-end;
             gap: 12px;
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             border-radius: 0 0 12px 12px;
         }
         
@@ -1015,13 +1105,13 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         }
         
         .modal-btn-default {
-            background-color: white;
+            background-color: var(--container-bg);
             color: var(--text-color);
             border: 1px solid var(--border-color);
         }
         
         .modal-btn-default:hover {
-            background-color: #f1f1f1;
+            background-color: var(--surface-hover);
         }
 			
         .report-header {
@@ -1054,7 +1144,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         .risk-score-value {
             font-weight: bold;
             color: var(--text-color);
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             padding: 4px 10px;
             border-radius: 20px;  /* 增加圆角 */
             display: inline-block;
@@ -1083,7 +1173,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         
         .risk-level-description {
             margin-top: 10px;
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             border-radius: 8px;
             padding: 10px;
             border: 1px solid var(--border-color);
@@ -1114,7 +1204,7 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         }
         
         .risk-table th {
-            background-color: #f8f9fa;
+            background-color: var(--surface-bg);
             font-weight: 600;
             color: var(--primary-color);
         }
@@ -1165,7 +1255,8 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
         [data-tooltip]:after {
             display: none !important;
         }
-    </style>
+` + htmlThemeCSS() + htmlHistoryCSS + colorSchemeCSS + `
+    </style>` + htmlThemeScript + `
 </head>
 <body>
     <div class="container">
@@ -1174,10 +1265,11 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
                 <img src="data:image/x-icon;base64,AAABAAEAICAAAAEAIACoEAAAFgAAACgAAAAgAAAAQAAAAAEAIAAAAAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAANIkfEjCHHFY8pSNWQKcmEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABOmj0AMIYbOi6FGaMshRjvLIUY/zmkIP86pSDvO6Uhoz2mIzpbs0UAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAMIYcNC2FGbsshBj9LIUY/yyFGP8shRj/OaQg/zqlIP86pSD/OqQg/TqlIbk9piQyAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAANIkgCC6GGYsshRj7LIUY/yyFGP8shRj/LIUY/yyFGP85pCD/OqUg/zqlIP86pSD/OqUg/zqlIPs7pSGLQagpCAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAADSJHxIuhRm/LIUX/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/zmkIP86pSD/OqUg/zqlIP86pSD/OqUg/zmlIP87pSG/QKcmEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA2iiMILoUZvyyFF/8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/OaQg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP87pSC/Q6gqCAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAC6GGn4shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP85pCD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqkH/87pSJ+AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABzrmQCLYUZ2yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/zmkIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIdt5wGcCAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAHGtYwIshRjdLIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/OaQg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUh3XjBZgIAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAb6thAiyFGN0shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP85pCD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSDddsBjAgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABqqFwALIUY3SyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/zmkIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIN1wvV4AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAGKkUgAshRjbLIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/OaQg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg22u7VwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAVZxFACyFGNsshRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP85pCD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSDbYLZKAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABMlzsALIUY2yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/zmkIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlINtXskAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAEGRLwAshRjbLIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/OaQg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg206uNgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAM4gfACyFGNsshRj/LIUY/yyFGP8shRj/LIUY/yyFGP8shRj/LIUY/yyFGP85pCD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIP86pSDbQKgnAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAArhBcAK4QX2yyEF/8vixnrLYUY6SyFGP8shRj/LIUY/yyFGP8shRj/LIUY/zmkIP86pSD/OqUg/zqlIP86pSD/OqUg/zqlIOc3nh7rOqUg/zmlH9s5pR8AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAthRmrLIQY9zihH6UwhxwYLIUY8SyFGP8viRrtLIUY+SyFGP8shRj/OaQg/zqlIP86pSD5OaEg7TmkH/86pSDxPaYjFi6IGKc6pSD3OqUhqQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA/kCwGAAAAAD+PLAIzhx8WPKIkHiyFGA4shRjlLIYY/zqlIIEwhhsqLIUY+yyFGP85pCD/OqUg+zymIyorhBiDOaMf/zqlIOU5pCAMNIwfHkCpJxZLrDICAAAAAEqrMwYAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAMIccNC2FGPEuhRm/LoYZdDGHHCw1iiEEAAAAADWKIQgxiRxEPKYjKi6FGBgshRj3LIUY/zmkIP86pSD3OqUgGC6FGyo8oyJEQagoBgAAAABCqCgEPaYkKjulIXQ7pSG/OqUg8TymIzQAAAAAAAAAAAAAAAAAAAAAAAAAADKIHhQthRjZLYUZyyyFGPEshRj/LIUY/y2FGNsuhRmXLoYaTjOJIBIAAAAAOI0lAjGHHCguhhpsO6Uiaj2mIyhGqSwCAAAAAD+nJhI7pSFOO6UhlzqlINs6pSD/OqUg/zqlIPE6pSDLOqUg2T6nJRQAAAAAAAAAAAAAAAAAAAAAM4gfIDCHHDxUm0EANIkgEjCGGkwthRmTLYUZ1yyFGP0shRf/LIUY8S2FGbkuhhpwMIccKkKRLgJLrTUCPaYjKjulIXA6pSG5OqUg8TmlH/86pCD9OqUg1zulIZM8piJMQKgnEl22SAA9piM8P6cmHgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA0iR8KO40oBgAAAAA3iyMEMYcdJC+GGmothRmxLIUY8yyFGP8shRj7LYUZ2zqlINs6pSD7OqUf/zqlIPE6pSCxPKUiaj2mJCRCqCkEAAAAAEerLwZAqCYKAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAMYccEi2FGMcshRjxLYUZsS+GGmgwhxwkN4oiBAAAAAA7jSgIMIcbQC2FGYkthRjnOqQg5zulIYc9piNAR6ouCAAAAABCqCkEPaYjIjulImg6pSGvOqUg8TqlIMc+piQSAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAECRLQguhhqvLoYZgy2FGZ8thRjjLIUY/yyFGP0thRjVLoUZky+GG0oziB8SWKBLAC+GGn47pSF+ZblPAECoJxI8piJIO6UhkzqlINU6pCD9OqUg/zqlIOM6pSGfO6UhgTulIa9LrTUIAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAUZo/ADeLJAgAAAAAAAAAADWJIAgwhxw6LoYahy2FGc8shRj7LIUY/yyFGPEthRm7L4YaeDulIng6pSC7OqUg8TqlIP86pCD7OqUhzzulIYU8piM4QagnCAAAAAAAAAAAQ6kqCFqyRgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAO4wmAjKIHiIvhhpqLYUYtyyFGPcshRf/OaQf/zqlIPc6pSC3O6Uiaj2mJSBFqi0CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAANIkgDi2FGdM6pSDTQKcnDAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAALoYZnzqkIZ8AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAvhhpEO6UiRAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA////////////+B///+AH//+AAf//AAD//gAAf/4AAH/8AAA//AAAP/wAAD/8AAA//AAAP/wAAD/8AAA//AAAP/wAAD/8AAA//EACP//EI//z/D/P4D/8B/4H4H//4Af/+Pw/H/AP8A//gYH///gf///+f////n////////////8="
         width="48" height="48" style="vertical-align:middle; margin-right:15px;">
             </div>
-            <h1>bt-ShieldML 木马查杀报告</h1>
+            <h1>bt-ShieldML 木马查杀报告</h1>` + htmlThemeToggleButton + `
         </div>
         <hr>
         <div class="timestamp"><i class="far fa-clock"></i> 检测时间：` + scanTime + `</div>
+` + scanRootHTML(r.ScanRoot) + trendHTML + `
 
         <div class="summary">
             <h2><i class="fas fa-chart-pie"></i>检测数据汇总</h2>
@@ -1222,12 +1314,16 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
             
             <div class="actions-bar">
                 <div class="action-buttons">
-                    <button class="action-btn" id="exportPdfBtn"><i class="fas fa-file-pdf"></i>导出 PDF</button>
+                    <span class="pdf-note" title="PDF 报告由服务端生成"><i class="fas fa-file-pdf"></i>PDF 报告由服务端生成：-output report.pdf</span>
                     <button class="action-btn" id="exportExcelBtn"><i class="fas fa-file-excel"></i>导出 Excel</button>
                 </div>
-                <div class="search-box">
-                    <i class="fas fa-search"></i>
-                    <input type="text" id="searchInput" placeholder="搜索文件名或路径...">
+                <div>
+                    <div class="search-box">
+                        <i class="fas fa-search"></i>
+                        <input type="text" id="searchInput" placeholder="搜索文件名或检测特征...">
+                        <button type="button" class="search-clear" id="searchClear" title="清除搜索">&times;</button>
+                    </div>
+                    <div class="search-count" id="searchCount"></div>
                 </div>
             </div>
             
@@ -1263,11 +1359,14 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 			// 格式化文件大小
 			fileSize := formatFileSize(res.File.Size)
 			filePath := html.EscapeString(res.File.Path)
+			displayPath := html.EscapeString(res.File.DisplayPath())
 			fileName := filepath.Base(res.File.Path)
 			fileName = html.EscapeString(fileName)
 
-			// 模拟 MD5 值，实际实现中应该获取真实值
-			fileMD5 := getMD5Placeholder(res.File.Path)
+			fileMD5 := res.ContentMD5
+			if fileMD5 == "" {
+				fileMD5 = "N/A"
+			}
 
 			// 格式化修改时间
 			modTime := res.File.ModTime.Format("2006-01-02 15:04:05")
@@ -1317,18 +1416,31 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 			// 获取风险分数 (1-5)
 			riskScore := riskLevel
 
+			// 风险标识的悬浮提示：CVSS 严重程度与向量
+			riskTooltip := ""
+			if primary := primaryFinding(res); primary != nil && (primary.Severity != "" || primary.CVSSVector != "") {
+				riskTooltip = strings.TrimSpace("CVSS " + primary.Severity + " " + primary.CVSSVector)
+			}
+
+			// 搜索框同时匹配文件名与各发现的描述 (如 YARA 规则名)
+			descriptions := make([]string, 0, len(res.Findings))
+			for _, finding := range res.Findings {
+				descriptions = append(descriptions, finding.Description)
+			}
+			findingsAttr := html.EscapeString(strings.Join(descriptions, "|"))
+
 			htmlBuilder.WriteString(fmt.Sprintf(`
-					<tr data-filter="%s" data-risk="%d" data-filename="%s" data-id="%d">
+					<tr data-filter="%s" data-risk="%d" data-filename="%s" data-findings="%s" data-id="%d">
 						<td><div class="checkbox-container"><div class="custom-checkbox file-checkbox"></div></div></td>
 						<td>%s</td>
                         <td><div class="file-path">%s</div><span class="path-toggle">查看更多</span></td>
 						<td><span class="risk-score-value" data-score="%d">%d级</span></td>
-						<td><span class="risk-indicator %s"><i class="%s"></i>%s</span></td>
+						<td><span class="risk-indicator %s" title="%s"><i class="%s"></i>%s</span></td>
 						<td>
 							<button class="details-btn" onclick="showModal(%d)">详情</button>
 						</td>
                     </tr>
-			`, dataFilter, int(res.OverallRisk), fileName, i, fileName, filePath, riskScore, riskScore, riskClass, riskIcon, riskDesc, i))
+			`, dataFilter, int(res.OverallRisk), fileName, findingsAttr, i, fileName, displayPath, riskScore, riskScore, riskClass, html.EscapeString(riskTooltip), riskIcon, riskDesc, i))
 
 			// 生成每个文件的模态弹窗内容
 			var findingsHTML strings.Builder
@@ -1340,14 +1452,29 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 							<div class="feature-description">%s</div>
 						</div>
 					`, finding.AnalyzerName, strings.ToLower(finding.Risk.String()), finding.Risk.String(), html.EscapeString(finding.Description)))
+					findingsHTML.WriteString(renderSnippet(finding))
+					findingsHTML.WriteString(renderTopWords(finding))
 				}
 			} else {
 				findingsHTML.WriteString(`<div class="feature-item">未检测到特定特征</div>`)
 			}
+			if r.Verbose {
+				for _, finding := range res.SuppressedFindings {
+					findingsHTML.WriteString(fmt.Sprintf(`
+						<div class="feature-item suppressed">
+							<div class="feature-name">[suppressed] %s <span class="risk-%s-text">(%s, 置信度 %.2f)</span></div>
+							<div class="feature-description">%s</div>
+						</div>
+					`, finding.AnalyzerName, strings.ToLower(finding.Risk.String()), finding.Risk.String(), finding.Confidence, html.EscapeString(finding.Description)))
+				}
+			}
+			if res.TruncatedFindings {
+				findingsHTML.WriteString(`<div class="feature-item truncated-warning"><i class="fas fa-exclamation-triangle"></i> 发现数量超过上限，仅显示风险最高的部分</div>`)
+			}
 
 			// 添加详细的模态弹窗HTML
 			htmlBuilder.WriteString(fmt.Sprintf(`
-				<div class="modal-content" id="modal-content-%d" style="display:none">
+				<div class="modal-content" id="modal-content-%d" data-path="%s" style="display:none">
 					<div class="file-details">
 						<h3><i class="fas fa-file-alt"></i>文件基本信息</h3>
 						<div class="detail-items">
@@ -1377,18 +1504,23 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 							</div>
 							<div class="detail-item">
 								<div class="detail-label">风险等级</div>
-								<div class="detail-value"><span class="risk-indicator %s" style="width:auto; display:inline-flex;"><i class="%s"></i>%s</span></div>
+								<div class="detail-value"><span class="risk-indicator %s" style="width:auto; display:inline-flex;" title="%s"><i class="%s"></i>%s</span></div>
 							</div>
 						</div>
 					</div>
-				
-					
+
+					<div class="risk-features">
+						<h3><i class="fas fa-search"></i>检测特征</h3>
+						%s
+					</div>
+
 					<div class="recommendation">
 						<h3><i class="fas fa-lightbulb"></i>处理建议</h3>
 						<p>%s</p>
+						<button class="details-btn" onclick="markFalsePositive(%d)"><i class="fas fa-flag"></i> 标记为误报</button>
 					</div>
 				</div>
-			`, i, fileName, fileSize, modTime, fileMD5, filePath, riskScore, riskScore, riskClass, riskIcon, riskDesc, recommendation))
+			`, i, filePath, fileName, fileSize, modTime, fileMD5, filePath, riskScore, riskScore, riskClass, html.EscapeString(riskTooltip), riskIcon, riskDesc, findingsHTML.String(), recommendation, i))
 		}
 	} else {
 		htmlBuilder.WriteString(`<tr><td colspan="5" style="text-align:center; color: #6c757d;">未发现问题文件</td></tr>`)
@@ -1398,6 +1530,12 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 	htmlBuilder.WriteString(`
                 </tbody>
             </table>
+            
+            <div class="pagination" id="pagination" style="display:none">
+                <button class="filter-btn" id="prevPageBtn">上一页</button>
+                <span class="page-indicator" id="pageIndicator"></span>
+                <button class="filter-btn" id="nextPageBtn">下一页</button>
+            </div>
         </div>
 
         <div class="footer">
@@ -1429,28 +1567,98 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 				document.addEventListener('DOMContentLoaded', function() {
 					// 表格筛选和排序功能
 					const table = document.getElementById('fileTable');
-					const rows = Array.from(table.querySelectorAll('tbody tr'));
+					const tbody = table.querySelector('tbody');
+					const rows = Array.from(tbody.querySelectorAll('tr[data-filter]'));
 					const tabBtns = document.querySelectorAll('.tab-btn');
 					const sortBtns = document.querySelectorAll('.filter-btn[data-sort]');
 					const searchInput = document.getElementById('searchInput');
+					const searchClear = document.getElementById('searchClear');
+					const searchCount = document.getElementById('searchCount');
+					
+					// 分页：问题文件较多时表格中只保留当前页的行，筛选、搜索、排序作用于全部行后再分页
+					const paginate = rows.length > ` + fmt.Sprintf("%d", htmlPaginationThreshold) + `;
+					const pageSize = paginate ? ` + fmt.Sprintf("%d", htmlPageSize) + ` : Math.max(rows.length, 1);
+					const pagination = document.getElementById('pagination');
+					const prevPageBtn = document.getElementById('prevPageBtn');
+					const nextPageBtn = document.getElementById('nextPageBtn');
+					const pageIndicator = document.getElementById('pageIndicator');
+					let orderedRows = rows.slice();
+					let currentFilter = 'all';
+					let searchTerm = '';
+					let currentPage = 1;
+					
+					function matchesTab(row, filter) {
+						return filter === 'all' || row.getAttribute('data-filter') === filter;
+					}
+					
+					function matchesSearch(row) {
+						return row.getAttribute('data-filename').toLowerCase().includes(searchTerm) ||
+							row.getAttribute('data-findings').toLowerCase().includes(searchTerm);
+					}
+					
+					function render() {
+						const searched = orderedRows.filter(matchesSearch);
+						
+						// 有搜索词时高亮匹配的行并显示结果数
+						rows.forEach(row => row.classList.toggle('search-match', searchTerm !== '' && matchesSearch(row)));
+						searchCount.textContent = searchTerm !== '' ? searched.length + ' 个结果' : '';
+						searchClear.style.visibility = searchTerm !== '' ? 'visible' : 'hidden';
+						
+						// 选项卡计数为搜索后的总数，而非当前页的行数
+						tabBtns.forEach(btn => {
+							const count = btn.querySelector('.count');
+							const filter = btn.getAttribute('data-filter');
+							if (count) {
+								count.textContent = searched.filter(row => matchesTab(row, filter)).length;
+							}
+						});
+						
+						const visible = searched.filter(row => matchesTab(row, currentFilter));
+						const totalPages = Math.max(1, Math.ceil(visible.length / pageSize));
+						if (currentPage > totalPages) {
+							currentPage = totalPages;
+						}
+						const start = (currentPage - 1) * pageSize;
+						
+						rows.forEach(row => {
+							if (row.parentNode) {
+								row.parentNode.removeChild(row);
+							}
+						});
+						const fragment = document.createDocumentFragment();
+						visible.slice(start, start + pageSize).forEach(row => fragment.appendChild(row));
+						tbody.appendChild(fragment);
+						
+						if (paginate) {
+							pagination.style.display = '';
+							pageIndicator.textContent = '第 ' + currentPage + ' / ' + totalPages + ' 页，共 ' + visible.length + ' 个文件';
+							prevPageBtn.disabled = currentPage <= 1;
+							nextPageBtn.disabled = currentPage >= totalPages;
+						}
+					}
+					
+					prevPageBtn.addEventListener('click', () => {
+						if (currentPage > 1) {
+							currentPage--;
+							render();
+						}
+					});
+					
+					nextPageBtn.addEventListener('click', () => {
+						currentPage++;
+						render();
+					});
 					
 					// 筛选功能 - 选项卡
 					tabBtns.forEach(btn => {
 						btn.addEventListener('click', () => {
-							const filter = btn.getAttribute('data-filter');
-							
 							// 更新按钮状态
 							tabBtns.forEach(b => b.classList.remove('active'));
 							btn.classList.add('active');
 							
-							// 筛选行
-							rows.forEach(row => {
-								if(filter === 'all' || row.getAttribute('data-filter') === filter) {
-									row.style.display = '';
-								} else {
-									row.style.display = 'none';
-								}
-							});
+							currentFilter = btn.getAttribute('data-filter');
+							currentPage = 1;
+							render();
 						});
 					});
 					
@@ -1458,13 +1666,12 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 					sortBtns.forEach(btn => {
 						btn.addEventListener('click', () => {
 							const sort = btn.getAttribute('data-sort');
-							const tbody = table.querySelector('tbody');
 							
 							// 更新按钮状态
 							sortBtns.forEach(b => b.classList.remove('active'));
 							btn.classList.add('active');
 							
-							// 排序行
+							// 排序全部行后重新分页
 							const sortedRows = rows.slice();
 							
 							if(sort === 'risk') {
@@ -1479,23 +1686,25 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 								});
 							}
 							
-							// 重新添加排序后的行
-							sortedRows.forEach(row => tbody.appendChild(row));
+							orderedRows = sortedRows;
+							currentPage = 1;
+							render();
 						});
 					});
 					
 					// 搜索功能
 					searchInput.addEventListener('input', () => {
-						const searchTerm = searchInput.value.toLowerCase();
-						
-						rows.forEach(row => {
-							const filename = row.getAttribute('data-filename').toLowerCase();
-							if (filename.includes(searchTerm)) {
-								row.style.display = '';
-							} else {
-								row.style.display = 'none';
-							}
-						});
+						searchTerm = searchInput.value.toLowerCase();
+						currentPage = 1;
+						render();
+					});
+					
+					searchClear.addEventListener('click', () => {
+						searchInput.value = '';
+						searchTerm = '';
+						currentPage = 1;
+						render();
+						searchInput.focus();
 					});
 					
 					// 全选/全不选功能
@@ -1535,9 +1744,6 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 						});
 					});
 					
-					// 导出PDF功能
-					document.getElementById('exportPdfBtn').addEventListener('click', exportToPDF);
-					
 					// 导出Excel功能
 					document.getElementById('exportExcelBtn').addEventListener('click', exportToExcel);
 					
@@ -1560,6 +1766,9 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 						const score = parseInt(el.getAttribute('data-score'));
 						el.setAttribute('data-score', score);
 					});
+					
+					// 事件绑定完成后再分页，被移出表格的行同样已绑定
+					render();
 				});
 				
 				// 弹窗相关函数
@@ -1619,16 +1828,40 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 					}, 300);
 				}
 				
-				// 导出PDF功能
-				function exportToPDF() {
-					// 实际实现时应该使用jsPDF库生成PDF
-					alert('导出PDF功能尚未实现，此功能将允许导出完整的检测报告为PDF文件。');
-				}
-				
 				// 导出Excel功能
 				function exportToExcel() {
-					// 实际实现时应该使用xlsx库导出Excel
-					alert('导出Excel功能尚未实现，此功能将允许导出文件列表和检测结果为Excel文件。');
+					// 由 shieldml_server 的 /api/export-xlsx 接口生成Excel文件
+					if (location.protocol !== 'http:' && location.protocol !== 'https:') {
+						alert('导出Excel需要通过 shieldml_server 访问报告，或使用 bt-shieldml -export-excel report.xlsx -json-report report.json 由JSON报告生成。');
+						return;
+					}
+					window.location.href = '/api/export-xlsx';
+				}
+
+				// 标记误报：通过 shieldml_server 访问时提交到 /api/feedback，否则导出一行反馈记录 (追加到 data/feedback.jsonl)
+				function markFalsePositive(id) {
+					const modal = document.getElementById('modal-content-' + id);
+					if (!modal) return;
+					const entry = { path: modal.dataset.path, correct_label: 'normal' };
+
+					if (location.protocol === 'http:' || location.protocol === 'https:') {
+						fetch('/api/feedback', {
+							method: 'POST',
+							headers: { 'Content-Type': 'application/json' },
+							body: JSON.stringify(entry)
+						}).then(resp => {
+							alert(resp.ok ? '已记录误报反馈。' : '提交反馈失败。');
+						}).catch(() => alert('提交反馈失败。'));
+						return;
+					}
+
+					entry.timestamp = new Date().toISOString();
+					const blob = new Blob([JSON.stringify(entry) + '\n'], { type: 'application/x-ndjson' });
+					const link = document.createElement('a');
+					link.href = URL.createObjectURL(blob);
+					link.download = 'feedback.jsonl';
+					link.click();
+					URL.revokeObjectURL(link.href);
 				}
 			</script>
 </body>
@@ -1644,3 +1877,70 @@ func (r *HtmlReporter) Generate(results []*types.ScanResult, outputPath string)
 
 	return nil
 }
+
+/**
+ * @Description: 将 regex/YARA 发现的匹配行渲染为 <pre> 代码块，Metadata["snippet_match"] 标出的匹配部分用 <mark> 高亮
+ * @author: Mr wpl
+ * @param finding *types.Finding: 发现
+ * @return string: HTML 片段，没有代码片段时返回空字符串
+ */
+func renderSnippet(finding *types.Finding) string {
+	if finding.Snippet == "" {
+		return ""
+	}
+	code := html.EscapeString(finding.Snippet)
+	if span, ok := finding.Metadata["snippet_match"].([]int); ok && len(span) == 2 &&
+		0 <= span[0] && span[0] <= span[1] && span[1] <= len(finding.Snippet) {
+		code = html.EscapeString(finding.Snippet[:span[0]]) +
+			"<mark>" + html.EscapeString(finding.Snippet[span[0]:span[1]]) + "</mark>" +
+			html.EscapeString(finding.Snippet[span[1]:])
+	}
+	return fmt.Sprintf(`<div class="feature-item"><div class="feature-name">第 %d 行</div><pre class="code-snippet">%s</pre></div>`, finding.LineNumber, code)
+}
+
+/**
+ * @Description: 将发现中的 top_words 元数据渲染为按影响程度着色的小表格
+ * @author: Mr wpl
+ * @param finding *types.Finding: 发现
+ * @return string: HTML 片段，无数据时返回空字符串
+ */
+func renderTopWords(finding *types.Finding) string {
+	topWords, ok := finding.Metadata["top_words"].([]map[string]interface{})
+	if !ok || len(topWords) == 0 {
+		return ""
+	}
+
+	// 以最大绝对值归一化颜色深浅
+	maxAbs := 0.0
+	for _, tw := range topWords {
+		if v, ok := tw["log_odds"].(float64); ok && math.Abs(v) > maxAbs {
+			maxAbs = math.Abs(v)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`<div class="feature-item"><div class="feature-name">影响最大的词</div><table class="risk-table"><tr><th>词</th><th>对数几率</th></tr>`)
+	for _, tw := range topWords {
+		word, _ := tw["word"].(string)
+		logOdds, _ := tw["log_odds"].(float64)
+		alpha := 0.0
+		if maxAbs > 0 {
+			alpha = math.Abs(logOdds) / maxAbs * 0.6
+		}
+		color := fmt.Sprintf("rgba(233, 71, 71, %.2f)", alpha) // 偏向 webshell
+		if logOdds < 0 {
+			color = fmt.Sprintf("rgba(40, 167, 69, %.2f)", alpha) // 偏向正常
+		}
+		b.WriteString(fmt.Sprintf(`<tr style="background-color:%s"><td>%s</td><td>%.3f</td></tr>`, color, html.EscapeString(word), logOdds))
+	}
+	b.WriteString(`</table></div>`)
+	return b.String()
+}
+
+// scanRootHTML 生成报告头部的扫描根目录行，根目录为空时不显示
+func scanRootHTML(scanRoot string) string {
+	if scanRoot == "" {
+		return ""
+	}
+	return `        <div class="timestamp"><i class="far fa-folder-open"></i> 扫描目录：` + html.EscapeString(scanRoot) + `</div>`
+}
@@ -0,0 +1,244 @@
+/*
+ * @Date: 2025-05-22 15:41:53
+ * @Editors: Mr wpl
+ * @Description: 两次扫描结果的对比HTML报告
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// comparisonEntry 对比报告中的一行，Before/After 可能为 nil
+type comparisonEntry struct {
+	Path   string
+	Before *types.ScanResult
+	After  *types.ScanResult
+}
+
+/**
+ * @Description: 生成两次扫描的对比报告，包含新增、已解决、风险变化三个部分
+ * @author: Mr wpl
+ * @param before []*types.ScanResult: 上一次扫描结果
+ * @param after []*types.ScanResult: 本次扫描结果
+ * @param outputPath string: 输出路径
+ * @return error: 错误
+ */
+func (r *HtmlReporter) GenerateComparison(before, after []*types.ScanResult, outputPath string) error {
+	if outputPath == "" {
+		return fmt.Errorf("HTML reporter requires an output path")
+	}
+
+	beforeByPath := make(map[string]*types.ScanResult, len(before))
+	for _, res := range before {
+		if res != nil {
+			beforeByPath[res.File.Path] = res
+		}
+	}
+	afterByPath := make(map[string]*types.ScanResult, len(after))
+	for _, res := range after {
+		if res != nil {
+			afterByPath[res.File.Path] = res
+		}
+	}
+
+	// --- 数据处理 ---
+	var newFindings, resolved, changed []*types.ScanResult
+	entries := make(map[string]comparisonEntry)
+
+	for path, res := range afterByPath {
+		prev, existed := beforeByPath[path]
+		switch {
+		case !existed && res.OverallRisk > types.RiskNone:
+			newFindings = append(newFindings, res)
+		case existed && prev.OverallRisk != res.OverallRisk:
+			changed = append(changed, res)
+		default:
+			continue
+		}
+		entries[path] = comparisonEntry{Path: path, Before: prev, After: res}
+	}
+	for path, res := range beforeByPath {
+		if _, exists := afterByPath[path]; !exists && res.OverallRisk > types.RiskNone {
+			resolved = append(resolved, res)
+			entries[path] = comparisonEntry{Path: path, Before: res}
+		}
+	}
+
+	sections := []struct {
+		id      string
+		title   string
+		icon    string
+		results []*types.ScanResult
+	}{
+		{"new", "新增风险", "fas fa-plus-circle", newFindings},
+		{"resolved", "已解决", "fas fa-check-circle", resolved},
+		{"changed", "风险变化", "fas fa-exchange-alt", changed},
+	}
+
+	// --- HTML 生成 ---
+	var htmlBuilder strings.Builder
+	htmlBuilder.WriteString(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>bt-ShieldML 扫描对比报告</title>
+    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/5.15.4/css/all.min.css">
+    <style>
+        :root {
+            --primary-color: #0070c0;
+            --primary-light: #e6f2fc;
+            --secondary-color: #f0f0f0;
+            --text-color: #333333;
+            --light-text: #666666;
+            --border-color: #cccccc;
+            --row-hover: rgba(0, 112, 192, 0.1);
+            --even-row: #f9f9f9;
+            --header-bg: #eaeaea;
+        }
+        body { font-family: 'Arial', 'Microsoft YaHei', sans-serif; background-color: var(--secondary-color); color: var(--text-color); margin: 0; padding: 15px; line-height: 1.5; }
+        .container { max-width: 1200px; margin: 5px auto; padding: 15px; background-color: #ffffff; border-radius: 8px; box-shadow: 0 2px 10px rgba(0,0,0,0.05); }
+        h1 { text-align: center; font-size: 24px; color: var(--primary-color); }
+        .timestamp { font-size: 16px; color: var(--light-text); margin-bottom: 25px; text-align: center; }
+        .tab-filters { display: flex; background-color: var(--primary-light); border-radius: 8px 8px 0 0; border: 1px solid var(--border-color); border-bottom: none; overflow: hidden; }
+        .tab-btn { padding: 8px 15px; background-color: transparent; border: none; border-right: 1px solid var(--border-color); cursor: pointer; font-size: 14px; font-weight: 500; color: var(--text-color); display: flex; align-items: center; }
+        .tab-btn:last-child { border-right: none; }
+        .tab-btn:hover { background-color: rgba(0, 112, 192, 0.1); }
+        .tab-btn.active { background-color: var(--primary-color); color: white; }
+        .tab-btn i { margin-right: 6px; }
+        .tab-btn .count { display: inline-block; background-color: rgba(255, 255, 255, 0.3); border-radius: 10px; padding: 2px 8px; font-size: 12px; margin-left: 8px; }
+        .tab-btn.active .count { background-color: white; color: var(--primary-color); }
+        table { width: 100%; border-collapse: collapse; border: 1px solid var(--border-color); }
+        th, td { border: 1px solid var(--border-color); padding: 8px 12px; text-align: left; vertical-align: top; font-size: 14px; }
+        th { background-color: var(--header-bg); }
+        tr:nth-child(even) { background-color: var(--even-row); }
+        tr:hover { background-color: var(--row-hover); }
+        .dir-row td { background-color: var(--primary-light); font-weight: bold; color: var(--primary-color); }
+        .file-path { word-break: break-all; }
+        .risk-indicator { display: inline-block; padding: 2px 10px; border-radius: 20px; font-weight: bold; font-size: 13px; color: white; }
+        .risk-critical, .risk-high { background: linear-gradient(135deg, #e94747, #c62828); }
+        .risk-medium, .risk-low { background: linear-gradient(135deg, #f8a532, #f57c00); }
+        .risk-none { background-color: #28a745; }
+        .risk-unknown { background-color: #e2e3e5; color: #383d41; }
+        .empty { text-align: center; color: #6c757d; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1><i class="fas fa-balance-scale"></i>bt-ShieldML 扫描对比报告</h1>
+        <div class="timestamp"><i class="far fa-clock"></i> 生成时间：` + time.Now().Format("2006-01-02 15:04:05") + `</div>
+        <div class="tab-filters">
+`)
+
+	for i, section := range sections {
+		active := ""
+		if i == 0 {
+			active = " active"
+		}
+		htmlBuilder.WriteString(fmt.Sprintf(`            <button class="tab-btn%s" data-filter="%s"><i class="%s"></i>%s<span class="count">%d</span></button>
+`, active, section.id, section.icon, section.title, len(section.results)))
+	}
+	htmlBuilder.WriteString(`        </div>
+`)
+
+	for i, section := range sections {
+		display := "none"
+		if i == 0 {
+			display = "block"
+		}
+		htmlBuilder.WriteString(fmt.Sprintf(`        <div class="section" data-section="%s" style="display:%s">
+            <table>
+                <thead>
+                    <tr>
+                        <th width="20%%">文件名</th>
+                        <th width="50%%">文件路径</th>
+                        <th width="15%%">之前</th>
+                        <th width="15%%">之后</th>
+                    </tr>
+                </thead>
+                <tbody>
+`, section.id, display))
+
+		if len(section.results) == 0 {
+			htmlBuilder.WriteString(`                    <tr><td colspan="4" class="empty">无</td></tr>
+`)
+		}
+
+		groups := GroupResultsByDirectory(section.results)
+		for _, dir := range sortedDirectories(groups) {
+			htmlBuilder.WriteString(fmt.Sprintf(`                    <tr class="dir-row"><td colspan="4"><i class="fas fa-folder"></i> %s (%d)</td></tr>
+`, html.EscapeString(dir), len(groups[dir])))
+			for _, res := range groups[dir] {
+				entry := entries[res.File.Path]
+				htmlBuilder.WriteString(fmt.Sprintf(`                    <tr>
+                        <td>%s</td>
+                        <td><div class="file-path">%s</div></td>
+                        <td>%s</td>
+                        <td>%s</td>
+                    </tr>
+`, html.EscapeString(filepath.Base(entry.Path)), html.EscapeString(entry.Path), comparisonRiskCell(entry.Before), comparisonRiskCell(entry.After)))
+			}
+		}
+
+		htmlBuilder.WriteString(`                </tbody>
+            </table>
+        </div>
+`)
+	}
+
+	htmlBuilder.WriteString(`    </div>
+    <script>
+        document.querySelectorAll('.tab-btn').forEach(btn => {
+            btn.addEventListener('click', () => {
+                const filter = btn.getAttribute('data-filter');
+                document.querySelectorAll('.tab-btn').forEach(b => b.classList.remove('active'));
+                btn.classList.add('active');
+                document.querySelectorAll('.section').forEach(section => {
+                    section.style.display = section.getAttribute('data-section') === filter ? 'block' : 'none';
+                });
+            });
+        });
+    </script>
+</body>
+</html>
+`)
+
+	err := ioutil.WriteFile(outputPath, []byte(htmlBuilder.String()), 0644)
+	if err != nil {
+		logging.ErrorLogger.Printf("Failed to write comparison report to %s: %v", outputPath, err)
+		return fmt.Errorf("failed to write comparison report: %w", err)
+	}
+	return nil
+}
+
+// comparisonRiskCell 生成风险等级单元格内容，结果不存在时显示占位符
+func comparisonRiskCell(res *types.ScanResult) string {
+	if res == nil {
+		return "-"
+	}
+	if res.Error != nil {
+		return `<span class="risk-indicator risk-unknown">扫描错误</span>`
+	}
+	return fmt.Sprintf(`<span class="risk-indicator risk-%s">%s</span>`,
+		strings.ToLower(comparisonRiskClass(res.OverallRisk)), res.OverallRisk.String())
+}
+
+// comparisonRiskClass 返回风险等级对应的 CSS 类名后缀
+func comparisonRiskClass(level types.RiskLevel) string {
+	switch level {
+	case types.RiskNone:
+		return "none"
+	case types.RiskLow, types.RiskMedium, types.RiskHigh, types.RiskCritical:
+		return level.String()
+	default:
+		return "unknown"
+	}
+}
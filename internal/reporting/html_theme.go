@@ -0,0 +1,133 @@
+/*
+ * @Date: 2025-06-04 11:02:36
+ * @Editors: Mr wpl
+ * @Description: HTML 报告深色模式：跟随 prefers-color-scheme，并支持手动切换 (保存在 localStorage)
+ */
+package reporting
+
+import "strings"
+
+// htmlDarkThemeVars 深色模式下覆盖的 CSS 变量
+const htmlDarkThemeVars = `
+            color-scheme: dark;
+            --primary-light: #1f3a5f;
+            --secondary-color: #1a1a2e;
+            --text-color: #e0e0e0;
+            --light-text: #a0a4b8;
+            --border-color: #444444;
+            --risk-low: #d9a35a;        /* 降低饱和度的橙黄色 */
+            --risk-medium: #d9a35a;
+            --risk-high: #c96464;       /* 降低饱和度的红色 */
+            --risk-critical: #c96464;
+            --row-hover: rgba(120, 170, 230, 0.15);
+            --even-row: #1b2745;
+            --header-bg: #0f3460;
+            --container-bg: #16213e;
+            --surface-bg: #1b2745;
+            --surface-hover: #243357;`
+
+// htmlDarkThemeRules 深色模式下无法通过变量覆盖的规则，{root} 替换为根选择器
+const htmlDarkThemeRules = `
+        {root} .risk-critical,
+        {root} .risk-high {
+            background: linear-gradient(135deg, #c96464, #a83f3f);
+        }
+
+        {root} .risk-medium,
+        {root} .risk-low {
+            background: linear-gradient(135deg, #d9a35a, #c27a2c);
+        }
+
+        {root} .risk-error {
+            background-color: #3a3f4b;
+            color: #d0d4dc;
+        }
+
+        {root} .container,
+        {root} .modal {
+            box-shadow: 0 2px 10px rgba(0, 0, 0, 0.4);
+        }`
+
+// htmlThemeToggleCSS 主题切换按钮样式
+const htmlThemeToggleCSS = `
+        .report-header {
+            position: relative;
+        }
+
+        .theme-toggle {
+            position: absolute;
+            top: 0;
+            right: 0;
+            padding: 6px 10px;
+            background-color: var(--container-bg);
+            color: var(--text-color);
+            border: 1px solid var(--border-color);
+            border-radius: 4px;
+            cursor: pointer;
+        }
+
+        .theme-toggle:hover {
+            background-color: var(--surface-hover);
+        }`
+
+// htmlThemeToggleButton 报告头部的主题切换按钮
+const htmlThemeToggleButton = `
+            <button type="button" class="theme-toggle" id="themeToggle" title="切换深色/浅色模式"><i class="fas fa-adjust"></i></button>`
+
+// htmlThemeScript 在页面渲染前应用保存的主题，避免闪烁；点击按钮在深色/浅色之间切换
+const htmlThemeScript = `
+    <script>
+        (function() {
+            var saved = null;
+            try { saved = localStorage.getItem('shieldml-theme'); } catch (e) {}
+            if (saved === 'dark' || saved === 'light') {
+                document.documentElement.setAttribute('data-theme', saved);
+            }
+            document.addEventListener('DOMContentLoaded', function() {
+                var toggle = document.getElementById('themeToggle');
+                if (!toggle) {
+                    return;
+                }
+                toggle.addEventListener('click', function() {
+                    var current = document.documentElement.getAttribute('data-theme');
+                    if (!current) {
+                        current = window.matchMedia && window.matchMedia('(prefers-color-scheme: dark)').matches ? 'dark' : 'light';
+                    }
+                    var next = current === 'dark' ? 'light' : 'dark';
+                    document.documentElement.setAttribute('data-theme', next);
+                    try { localStorage.setItem('shieldml-theme', next); } catch (e) {}
+                });
+            });
+        })();
+    </script>`
+
+/**
+ * @Description: 生成深色模式 CSS：未手动选择浅色时跟随系统，手动选择深色时始终生效
+ * @author: Mr wpl
+ * @return string: CSS 片段
+ */
+func htmlThemeCSS() string {
+	var b strings.Builder
+	b.WriteString(`
+        @media (prefers-color-scheme: dark) {`)
+	b.WriteString(darkThemeBlock(`:root:not([data-theme="light"])`))
+	b.WriteString(`
+        }
+`)
+	b.WriteString(darkThemeBlock(`:root[data-theme="dark"]`))
+	b.WriteString(htmlThemeToggleCSS)
+	return b.String()
+}
+
+/**
+ * @Description: 生成指定根选择器下的深色模式变量与规则
+ * @author: Mr wpl
+ * @param root string: 根选择器
+ * @return string: CSS 片段
+ */
+func darkThemeBlock(root string) string {
+	return `
+        ` + root + ` {` + htmlDarkThemeVars + `
+        }
+` + strings.ReplaceAll(htmlDarkThemeRules, "{root}", root)
+}
@@ -1,11 +1,14 @@
 package reporting
 
 import (
+	"bt-shieldml/internal/signing"
 	"bt-shieldml/pkg/types"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // 简化版扫描结果
@@ -15,10 +18,44 @@ type SimpleResult struct {
 	Risk     int    `json:"risk"`        // 原始风险等级（数字）
 	RiskText string `json:"risk_text"`   // 风险等级描述
 	Desc     string `json:"description"` // 简短描述
+
+	Path         string          `json:"path"`                          // 文件完整路径
+	RelativePath string          `json:"relative_path,omitempty"`       // 相对扫描根目录的路径
+	Size         int64           `json:"size"`                          // 文件大小
+	MD5          string          `json:"md5,omitempty"`                 // 文件内容的 MD5
+	SHA256       string          `json:"sha256,omitempty"`              // 文件内容的 SHA-256
+	Analyzers    []string        `json:"analyzers,omitempty"`           // 产生发现的分析器名称
+	Findings     []string        `json:"findings,omitempty"`            // 发现描述
+	Snippets     []SimpleSnippet `json:"snippets,omitempty"`            // regex/YARA 发现的匹配行
+	DurationMs   int64           `json:"duration_ms"`                   // 扫描耗时(毫秒)
+	Signature    string          `json:"signature,omitempty"`           // 结果签名
+	VT           string          `json:"vt,omitempty"`                  // VirusTotal 检出情况，"pending" 表示超时未完成
+	Severity     string          `json:"severity,omitempty"`            // 最高风险发现的 CVSS v3.1 严重程度
+	CVSSVector   string          `json:"cvss_vector,omitempty"`         // 最高风险发现的 CVSS 向量 (已知时)
+	Skipped      []string        `json:"skipped_analyzers,omitempty"`   // 因 Critical 提前退出而跳过的分析器
+	Truncated    bool            `json:"truncated_findings,omitempty"`  // 发现数超过上限，仅保留风险最高的部分
+	Suppressed   []string        `json:"suppressed_findings,omitempty"` // 被 min_confidence 抑制的发现 (仅 -verbose)
+	DuplicateOf  string          `json:"duplicate_of,omitempty"`        // 内容相同、结果复用自该文件
+	DeployedBy   string          `json:"deployed_by,omitempty"`         // 资产清单中的部署工具，风险已限制为 Low
+	Incomplete   bool            `json:"incomplete,omitempty"`          // 收到 SIGTERM 时未完成扫描，没有结果
+	Cached       bool            `json:"cached,omitempty"`              // -incremental 时文件未变，结果复用自扫描状态库
+}
+
+// SimpleSnippet 一条发现的匹配代码片段
+type SimpleSnippet struct {
+	Analyzer   string `json:"analyzer"`
+	LineNumber int    `json:"line_number"`
+	Snippet    string `json:"snippet"`
 }
 
 // JsonReporter 实现 Reporter 接口
-type JsonReporter struct{}
+type JsonReporter struct {
+	ScanRoot string    // 扫描根目录
+	SortKeys []SortKey // 结果排序键，为空时按风险降序、路径升序
+	Verbose  bool      // 输出被 min_confidence 抑制的发现
+	// ExpiresAt 报告过期时间，非零时写入 expires_at 字段
+	ExpiresAt time.Time
+}
 
 /**
  * @Description: 创建新的JSON报告
@@ -49,6 +86,31 @@ func (r *JsonReporter) Generate(results []*types.ScanResult, outputPath string)
 		outputPath = filepath.Join(dataDir, "webshellJson.json")
 	}
 
+	// 创建或打开输出文件
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Build(results))
+}
+
+/**
+ * @Description: 将扫描结果转换为 JSON 报告内容 (与前端约定的格式)，扫描出错的文件不输出
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @return map[string]interface{}: 报告内容，results 为简化结果列表
+ */
+func (r *JsonReporter) Build(results []*types.ScanResult) map[string]interface{} {
+	keys := r.SortKeys
+	if len(keys) == 0 {
+		keys = []SortKey{SortByRiskDesc, SortByPathAsc}
+	}
+	results = SortResults(results, keys...)
+
 	// 创建简化版结果
 	simplified := make([]SimpleResult, 0, len(results))
 
@@ -58,7 +120,7 @@ func (r *JsonReporter) Generate(results []*types.ScanResult, outputPath string)
 		}
 
 		// 提取文件类型
-		fileType := strings.TrimPrefix(strings.ToLower(filepath.Ext(res.File.Path)), ".")
+		fileType := reportFileType(res.File.Path)
 
 		// 风险级别描述
 		var riskText string
@@ -93,29 +155,125 @@ func (r *JsonReporter) Generate(results []*types.ScanResult, outputPath string)
 			riskScore = 0
 		}
 
+		// 收集分析器名称和发现描述
+		var analyzers, findings []string
+		var snippets []SimpleSnippet
+		for _, f := range res.Findings {
+			analyzers = append(analyzers, f.AnalyzerName)
+			findings = append(findings, f.Description)
+			if f.Snippet != "" {
+				snippets = append(snippets, SimpleSnippet{Analyzer: f.AnalyzerName, LineNumber: f.LineNumber, Snippet: f.Snippet})
+			}
+		}
+
+		var suppressed []string
+		if r.Verbose {
+			for _, f := range res.SuppressedFindings {
+				suppressed = append(suppressed, fmt.Sprintf("[suppressed] %s: %s (confidence %.2f)", f.AnalyzerName, f.Description, f.Confidence))
+			}
+		}
+
+		var severity, cvssVector string
+		if primary := primaryFinding(res); primary != nil {
+			severity, cvssVector = primary.Severity, primary.CVSSVector
+		}
+
 		// 添加到简化结果中
 		simplified = append(simplified, SimpleResult{
-			Filename: filepath.Base(res.File.Path),
-			Type:     fileType,
-			Risk:     riskScore, // 使用明确映射的分数
-			RiskText: riskText,
-			Desc:     desc,
+			Filename:     filepath.Base(res.File.Path),
+			Type:         fileType,
+			Risk:         riskScore, // 使用明确映射的分数
+			RiskText:     riskText,
+			Desc:         desc,
+			Path:         res.File.Path,
+			RelativePath: res.File.RelativePath,
+			Size:         res.File.Size,
+			MD5:          res.ContentMD5,
+			Analyzers:    analyzers,
+			Findings:     findings,
+			Snippets:     snippets,
+			DurationMs:   res.Duration.Milliseconds(),
+			Signature:    res.Signature,
+			VT:           res.VT,
+			Severity:     severity,
+			CVSSVector:   cvssVector,
+			Skipped:      res.SkippedAnalyzers,
+			Truncated:    res.TruncatedFindings,
+			Suppressed:   suppressed,
+			DuplicateOf:  res.DuplicateOf,
+			DeployedBy:   res.DeployedBy,
+			Incomplete:   res.Incomplete,
+			Cached:       res.Cached,
 		})
 	}
 
-	// 创建或打开输出文件
-	out, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
 	// 使用map包装，和前端约定好格式
 	finalResult := map[string]interface{}{
 		"results": simplified,
 	}
+	if r.ScanRoot != "" {
+		finalResult["scan_root"] = r.ScanRoot
+	}
+	if !r.ExpiresAt.IsZero() {
+		finalResult["expires_at"] = r.ExpiresAt.Format(time.RFC3339)
+	}
+	duplicates := 0
+	for _, res := range results {
+		if res.DuplicateOf != "" {
+			duplicates++
+		}
+	}
+	if duplicates > 0 {
+		finalResult["duplicate_files"] = duplicates
+	}
+	incomplete := 0
+	for _, res := range results {
+		if res.Incomplete {
+			incomplete++
+		}
+	}
+	if incomplete > 0 {
+		finalResult["incomplete_files"] = incomplete
+		finalResult["completed_files"] = len(results) - incomplete
+	}
 
-	enc := json.NewEncoder(out)
-	enc.SetIndent("", "  ")
-	return enc.Encode(finalResult)
+	// 存在签名时附带完整的规范化结果，供 verify-report 验签
+	var signed []signing.Record
+	for _, res := range results {
+		if res.Signature != "" {
+			signed = append(signed, signing.NewRecord(res))
+		}
+	}
+	if len(signed) > 0 {
+		finalResult["signed_results"] = signed
+	}
+	return finalResult
+}
+
+// reportFileType 报告中的文件类型：小写扩展名 (不含点)，.htaccess 为 "htaccess" 伪扩展名，无扩展名时为空
+func reportFileType(path string) string {
+	if strings.EqualFold(filepath.Base(path), types.HtaccessFileName) {
+		return "htaccess"
+	}
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+}
+
+/**
+ * @Description: 返回风险最高的发现，风险相同时优先带 CVSS 向量的发现
+ * @author: Mr wpl
+ * @param res *types.ScanResult: 扫描结果
+ * @return *types.Finding: 发现，无发现时返回 nil
+ */
+func primaryFinding(res *types.ScanResult) *types.Finding {
+	var primary *types.Finding
+	for _, f := range res.Findings {
+		if f == nil {
+			continue
+		}
+		if primary == nil || f.Risk > primary.Risk ||
+			(f.Risk == primary.Risk && primary.CVSSVector == "" && f.CVSSVector != "") {
+			primary = f
+		}
+	}
+	return primary
 }
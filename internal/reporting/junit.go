@@ -0,0 +1,169 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: JUnit XML 报告，供 Jenkins、GitLab CI 等 CI 系统以测试结果的形式展示：每个文件一个 testcase，有风险的文件为 failure
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// JUnitSuiteName testsuite 与 testcase classname 使用的名称
+const JUnitSuiteName = "btShieldML"
+
+// JUnitTestSuite JUnit XML 的根元素
+type JUnitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"` // 各文件扫描耗时之和 (秒)
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase 一个文件的扫描结果
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"` // 相对扫描根目录的路径 (不在根目录下时为完整路径)
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitMessage `xml:"failure,omitempty"` // 风险高于 Safe
+	Error     *JUnitMessage `xml:"error,omitempty"`   // 扫描出错
+	Skipped   *JUnitMessage `xml:"skipped,omitempty"` // 收到 SIGTERM 时未完成扫描
+}
+
+// JUnitMessage failure/error/skipped 元素，正文为全部发现
+type JUnitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+/**
+ * @Description: JUnit XML 报告，实现 Reporter 接口
+ * @author: Mr wpl
+ */
+type JUnitReporter struct {
+	SortKeys []SortKey // 结果排序键，为空时按路径升序
+}
+
+/**
+ * @Description: 创建新的JUnit报告
+ * @author: Mr wpl
+ * @return *JUnitReporter: JUnit报告
+ */
+func NewJUnitReporter() *JUnitReporter {
+	return &JUnitReporter{}
+}
+
+/**
+ * @Description: 生成 JUnit XML 报告，outputPath 为空时写到 stdout
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 输出路径
+ * @return error: 错误
+ */
+func (r *JUnitReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	keys := r.SortKeys
+	if len(keys) == 0 {
+		keys = []SortKey{SortByPathAsc}
+	}
+	return writeJUnitXML(w, BuildJUnitSuite(SortResults(results, keys...), time.Now()))
+}
+
+// writeJUnitXML 写出带 XML 声明、两空格缩进的 testsuite
+func writeJUnitXML(w io.Writer, suite *JUnitTestSuite) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+/**
+ * @Description: 将扫描结果转换为 testsuite：Safe 文件为通过的 testcase；其它风险等级为 failure，
+ * message 为第一条发现的描述、type 为该发现的风险等级；扫描出错为 error；未完成的文件为 skipped
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果 (已排序)
+ * @param timestamp time.Time: 报告时间
+ * @return *JUnitTestSuite: testsuite
+ */
+func BuildJUnitSuite(results []*types.ScanResult, timestamp time.Time) *JUnitTestSuite {
+	suite := &JUnitTestSuite{
+		Name:      JUnitSuiteName,
+		Tests:     len(results),
+		Timestamp: timestamp.Format("2006-01-02T15:04:05"),
+		TestCases: make([]JUnitTestCase, 0, len(results)),
+	}
+	var total time.Duration
+	for _, res := range results {
+		total += res.Duration
+		name := res.File.RelativePath
+		if name == "" {
+			name = res.File.Path
+		}
+		tc := JUnitTestCase{Name: name, ClassName: JUnitSuiteName, Time: junitSeconds(res.Duration)}
+		switch {
+		case res.Error != nil:
+			suite.Errors++
+			tc.Error = &JUnitMessage{Message: res.Error.Error(), Type: "ScanError"}
+		case res.Incomplete:
+			suite.Skipped++
+			tc.Skipped = &JUnitMessage{Message: "scan interrupted before this file completed"}
+		case res.OverallRisk != types.RiskNone:
+			suite.Failures++
+			tc.Failure = junitFailure(res)
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Time = junitSeconds(total)
+	return suite
+}
+
+// junitFailure 有风险文件的 failure 元素，正文每行一条发现
+func junitFailure(res *types.ScanResult) *JUnitMessage {
+	failure := &JUnitMessage{
+		Message: fmt.Sprintf("Overall risk %s", res.OverallRisk.String()),
+		Type:    res.OverallRisk.String(),
+	}
+	if len(res.Findings) > 0 {
+		failure.Message = res.Findings[0].Description
+		failure.Type = res.Findings[0].Risk.String()
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Overall risk: %s\n", res.OverallRisk.String())
+	for _, f := range res.Findings {
+		fmt.Fprintf(&body, "[%s] %s (%s)\n", f.AnalyzerName, f.Description, f.Risk.String())
+	}
+	failure.Body = body.String()
+	return failure
+}
+
+// junitSeconds 以秒为单位、保留 3 位小数的耗时
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
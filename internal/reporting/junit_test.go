@@ -0,0 +1,134 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: JUnit XML 报告测试：与 testdata/junit_golden.xml 逐字节比较，并经 encoding/xml 往返校验结构
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const junitGoldenFile = "testdata/junit_golden.xml"
+
+// junitTestTime 报告时间，golden 文件中的 timestamp
+var junitTestTime = time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+// junitTestResults 覆盖通过、failure (含 XML 特殊字符、无发现与扫描根目录外的文件)、error 与 skipped，已按路径排序
+func junitTestResults() []*types.ScanResult {
+	return []*types.ScanResult{
+		{
+			File:        types.FileInfo{Path: "/tmp/outside.php"},
+			OverallRisk: types.RiskHigh,
+			Findings:    []*types.Finding{{AnalyzerName: "bayes_words", Description: "classified as webshell", Risk: types.RiskHigh}},
+			Duration:    3 * time.Millisecond,
+		},
+		{
+			File:        types.FileInfo{Path: "/www/site/index.php", RelativePath: "index.php"},
+			OverallRisk: types.RiskNone,
+			Duration:    12 * time.Millisecond,
+		},
+		{
+			File:        types.FileInfo{Path: "/www/site/interrupted.php", RelativePath: "interrupted.php"},
+			OverallRisk: types.RiskLow,
+			Incomplete:  true,
+		},
+		{
+			File:        types.FileInfo{Path: "/www/site/lib/medium.php", RelativePath: "lib/medium.php"},
+			OverallRisk: types.RiskMedium,
+			Duration:    250 * time.Millisecond,
+		},
+		{
+			File:        types.FileInfo{Path: "/www/site/unreadable.php", RelativePath: "unreadable.php"},
+			OverallRisk: types.RiskUnknown,
+			Error:       errors.New("open /www/site/unreadable.php: permission denied"),
+		},
+		{
+			File:        types.FileInfo{Path: "/www/site/uploads/shell.php", RelativePath: "uploads/shell.php"},
+			OverallRisk: types.RiskCritical,
+			Findings: []*types.Finding{
+				{AnalyzerName: "yara", Description: `Rule "webshell_eval" matched <eval> & more`, Risk: types.RiskCritical},
+				{AnalyzerName: "regex", Description: "eval($_POST['x'])", Risk: types.RiskHigh},
+			},
+			Duration: 1234567 * time.Microsecond,
+		},
+	}
+}
+
+func TestJUnitGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJUnitXML(&buf, BuildJUnitSuite(junitTestResults(), junitTestTime)); err != nil {
+		t.Fatalf("writeJUnitXML() error = %v", err)
+	}
+	want, err := os.ReadFile(junitGoldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("JUnit XML does not match %s\n got:\n%s\nwant:\n%s", junitGoldenFile, buf.Bytes(), want)
+	}
+}
+
+// TestJUnitGoldenRoundTrip golden 文件经 encoding/xml 解析后与 BuildJUnitSuite 的结果完全一致
+func TestJUnitGoldenRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(junitGoldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got JUnitTestSuite
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("golden file is not valid JUnit XML: %v", err)
+	}
+	want := BuildJUnitSuite(junitTestResults(), junitTestTime)
+	want.XMLName = xml.Name{Local: "testsuite"}
+	if !reflect.DeepEqual(&got, want) {
+		t.Errorf("round trip = %+v\nwant %+v", got, *want)
+	}
+
+	if got.Tests != 6 || got.Failures != 3 || got.Errors != 1 || got.Skipped != 1 || got.Time != "1.500" {
+		t.Errorf("testsuite attributes = tests %d, failures %d, errors %d, skipped %d, time %s, want 6, 3, 1, 1, 1.500",
+			got.Tests, got.Failures, got.Errors, got.Skipped, got.Time)
+	}
+	shell := got.TestCases[5]
+	if shell.Failure == nil || shell.Failure.Message != `Rule "webshell_eval" matched <eval> & more` || shell.Failure.Type != "Critical" {
+		t.Errorf("uploads/shell.php failure = %+v, want the first finding's description and risk", shell.Failure)
+	}
+}
+
+// TestJUnitReporterGenerate Generate 按路径排序后写出与 golden 文件相同的 XML (时间戳除外)
+func TestJUnitReporterGenerate(t *testing.T) {
+	results := junitTestResults()
+	shuffled := []*types.ScanResult{results[4], results[0], results[5], results[2], results[1], results[3]}
+	out := filepath.Join(t.TempDir(), "results.xml")
+	if err := NewJUnitReporter().Generate(shuffled, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suite JUnitTestSuite
+	if err := xml.Unmarshal(data, &suite); err != nil {
+		t.Fatalf("Generate() output is not valid XML: %v", err)
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05", suite.Timestamp); err != nil {
+		t.Errorf("timestamp = %q: %v", suite.Timestamp, err)
+	}
+
+	got := bytes.Replace(data, []byte(`timestamp="`+suite.Timestamp+`"`), []byte(`timestamp="`+junitTestTime.Format("2006-01-02T15:04:05")+`"`), 1)
+	want, err := os.ReadFile(junitGoldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Generate() output does not match %s\n got:\n%s\nwant:\n%s", junitGoldenFile, got, want)
+	}
+}
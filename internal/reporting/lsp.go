@@ -0,0 +1,251 @@
+/*
+ * @Date: 2025-06-13 10:12:36
+ * @Editors: Mr wpl
+ * @Description: LSP 诊断报告，以 textDocument/publishDiagnostics 通知输出扫描结果，供 IDE 显示
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LSPSource 诊断来源名称，IDE 中显示在消息旁
+const LSPSource = "btShieldML"
+
+// LSP DiagnosticSeverity 取值
+const (
+	LSPSeverityError       = 1
+	LSPSeverityWarning     = 2
+	LSPSeverityInformation = 3
+	LSPSeverityHint        = 4
+)
+
+// LSPPosition 文档位置，行和列均从 0 开始
+type LSPPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LSPRange 文档范围
+type LSPRange struct {
+	Start LSPPosition `json:"start"`
+	End   LSPPosition `json:"end"`
+}
+
+// LSPDiagnostic 一条诊断，对应一条发现
+type LSPDiagnostic struct {
+	Range    LSPRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"` // 分析器名称
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// LSPPublishDiagnosticsParams textDocument/publishDiagnostics 的参数
+type LSPPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []LSPDiagnostic `json:"diagnostics"`
+}
+
+// LSPNotification JSON-RPC 通知
+type LSPNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+/**
+ * @Description: LSP 诊断报告，实现 StreamingReporter。每个有发现的文件输出一条 publishDiagnostics 通知；
+ * 默认每行一条 JSON，Framed 为 true 时按 LSP 基础协议加 Content-Length 头
+ * @author: Mr wpl
+ */
+type LSPDiagnosticsReporter struct {
+	Framed bool // 按 LSP 基础协议分帧输出
+}
+
+/**
+ * @Description: 创建新的LSP诊断报告
+ * @author: Mr wpl
+ * @return *LSPDiagnosticsReporter: LSP诊断报告
+ */
+func NewLSPDiagnosticsReporter() *LSPDiagnosticsReporter {
+	return &LSPDiagnosticsReporter{}
+}
+
+/**
+ * @Description: 一次性输出全部结果，outputPath 为空时写到 stdout
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 输出路径
+ * @return error: 错误
+ */
+func (r *LSPDiagnosticsReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ch := make(chan *types.ScanResult, len(results))
+	for _, res := range results {
+		ch <- res
+	}
+	close(ch)
+	return r.Stream(ch, w)
+}
+
+/**
+ * @Description: 逐条读取结果并写出诊断通知，直到 results 关闭。写入失败后继续读取以免阻塞扫描协程
+ * @author: Mr wpl
+ * @param results <-chan *types.ScanResult: 结果通道
+ * @param w io.Writer: 输出
+ * @return error: 第一个写入错误
+ */
+func (r *LSPDiagnosticsReporter) Stream(results <-chan *types.ScanResult, w io.Writer) error {
+	var firstErr error
+	for res := range results {
+		if firstErr != nil {
+			continue
+		}
+		if err := r.Write(w, res); err != nil {
+			firstErr = fmt.Errorf("failed to write diagnostics for %s: %w", res.File.Path, err)
+		}
+	}
+	return firstErr
+}
+
+/**
+ * @Description: 写出单个文件的诊断通知，没有发现的文件不输出
+ * @author: Mr wpl
+ * @param w io.Writer: 输出
+ * @param res *types.ScanResult: 扫描结果
+ * @return error: 错误
+ */
+func (r *LSPDiagnosticsReporter) Write(w io.Writer, res *types.ScanResult) error {
+	params := ToLSPDiagnostics(res)
+	if len(params.Diagnostics) == 0 {
+		return nil
+	}
+	return WriteLSPMessage(w, LSPNotification{
+		JSONRPC: "2.0",
+		Method:  "textDocument/publishDiagnostics",
+		Params:  params,
+	}, r.Framed)
+}
+
+/**
+ * @Description: 写出一条 JSON-RPC 消息，framed 时加 Content-Length 头，否则以换行结尾
+ * @author: Mr wpl
+ * @param w io.Writer: 输出
+ * @param msg interface{}: 消息
+ * @param framed bool: 是否按 LSP 基础协议分帧
+ * @return error: 错误
+ */
+func WriteLSPMessage(w io.Writer, msg interface{}, framed bool) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if framed {
+		if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+/**
+ * @Description: 将扫描结果转换为 publishDiagnostics 参数。发现的 metadata 带 line (从 1 开始) 时定位到该行，
+ * 否则定位到文件首行；扫描错误转换为一条 Information 诊断
+ * @author: Mr wpl
+ * @param res *types.ScanResult: 扫描结果
+ * @return LSPPublishDiagnosticsParams: 诊断参数
+ */
+func ToLSPDiagnostics(res *types.ScanResult) LSPPublishDiagnosticsParams {
+	params := LSPPublishDiagnosticsParams{
+		URI:         FileURI(res.File.Path),
+		Diagnostics: []LSPDiagnostic{},
+	}
+	for _, f := range res.Findings {
+		line := findingLine(f) - 1
+		if line < 0 {
+			line = 0
+		}
+		params.Diagnostics = append(params.Diagnostics, LSPDiagnostic{
+			Range: LSPRange{
+				Start: LSPPosition{Line: line},
+				End:   LSPPosition{Line: line + 1},
+			},
+			Severity: lspSeverity(f.Risk),
+			Code:     f.AnalyzerName,
+			Source:   LSPSource,
+			Message:  f.Description,
+		})
+	}
+	if res.Error != nil {
+		params.Diagnostics = append(params.Diagnostics, LSPDiagnostic{
+			Range:    LSPRange{End: LSPPosition{Line: 1}},
+			Severity: LSPSeverityInformation,
+			Source:   LSPSource,
+			Message:  "Scan error: " + res.Error.Error(),
+		})
+	}
+	return params
+}
+
+/**
+ * @Description: 将本地路径转换为 file:// URI，Windows 盘符路径补前导斜杠
+ * @author: Mr wpl
+ * @param path string: 文件路径
+ * @return string: URI
+ */
+func FileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// lspSeverity 风险级别映射为诊断级别：High/Critical 为 Error，Medium 为 Warning，Low 为 Information，其余为 Hint
+func lspSeverity(risk types.RiskLevel) int {
+	switch {
+	case risk >= types.RiskHigh:
+		return LSPSeverityError
+	case risk == types.RiskMedium:
+		return LSPSeverityWarning
+	case risk == types.RiskLow:
+		return LSPSeverityInformation
+	}
+	return LSPSeverityHint
+}
+
+// findingLine 从 metadata 的 line 读取行号 (从 1 开始)，没有时返回 0
+func findingLine(f *types.Finding) int {
+	switch v := f.Metadata["line"].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+/*
+ * @Date: 2025-05-30 14:05:18
+ * @Editors: Mr wpl
+ * @Description: NDJSON 流式报告，每个文件一行 JSON
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// NDJSONFile 单行结果中的文件信息
+type NDJSONFile struct {
+	Path         string    `json:"path"`
+	RelativePath string    `json:"relative_path,omitempty"`
+	Size         int64     `json:"size"`
+	ModTime      time.Time `json:"mod_time"`
+	Encoding     string    `json:"encoding,omitempty"`
+}
+
+// NDJSONFinding 单行结果中的一条发现
+type NDJSONFinding struct {
+	Analyzer    string                 `json:"analyzer"`
+	Description string                 `json:"description"`
+	Risk        string                 `json:"risk"`
+	Confidence  float64                `json:"confidence"`
+	Severity    string                 `json:"severity,omitempty"`
+	CVSSVector  string                 `json:"cvss_vector,omitempty"`
+	Snippet     string                 `json:"snippet,omitempty"`
+	LineNumber  int                    `json:"line_number,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// NDJSONResult 一个文件的完整扫描结果，每行一个，互不依赖
+type NDJSONResult struct {
+	File             NDJSONFile      `json:"file"`
+	Risk             string          `json:"risk"`
+	RiskLevel        int             `json:"risk_level"`
+	Findings         []NDJSONFinding `json:"findings"`
+	Duration         string          `json:"duration"`
+	Error            string          `json:"error,omitempty"`
+	SkippedAnalyzers []string        `json:"skipped_analyzers,omitempty"`
+	PartialAST       bool            `json:"partial_ast,omitempty"`
+	ASTTruncated     int             `json:"ast_truncated_lines,omitempty"`
+	Truncated        bool            `json:"truncated_findings,omitempty"`
+	Suppressed       []NDJSONFinding `json:"suppressed_findings,omitempty"` // 仅 -verbose
+	DuplicateOf      string          `json:"duplicate_of,omitempty"`
+	DeployedBy       string          `json:"deployed_by,omitempty"`
+	Incomplete       bool            `json:"incomplete,omitempty"` // 收到 SIGTERM 时未完成扫描
+	Cached           bool            `json:"cached,omitempty"`     // -incremental 时结果复用自扫描状态库
+	Signature        string          `json:"signature,omitempty"`
+	VT               string          `json:"vt,omitempty"`
+}
+
+// NDJSONReporter 实现 StreamingReporter，文件扫描完成即输出一行 JSON，适合 jq 和日志采集
+type NDJSONReporter struct {
+	Verbose bool // 输出被 min_confidence 抑制的发现
+}
+
+/**
+ * @Description: 创建新的NDJSON报告
+ * @author: Mr wpl
+ * @return *NDJSONReporter: NDJSON报告
+ */
+func NewNDJSONReporter() *NDJSONReporter {
+	return &NDJSONReporter{}
+}
+
+/**
+ * @Description: 一次性输出全部结果，outputPath 为空时写到 stdout
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 输出路径
+ * @return error: 错误
+ */
+func (r *NDJSONReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ch := make(chan *types.ScanResult, len(results))
+	for _, res := range results {
+		ch <- res
+	}
+	close(ch)
+	return r.Stream(ch, w)
+}
+
+/**
+ * @Description: 逐条读取结果并写出一行 JSON，直到 results 关闭。写入失败后继续读取以免阻塞扫描协程
+ * @author: Mr wpl
+ * @param results <-chan *types.ScanResult: 结果通道
+ * @param w io.Writer: 输出
+ * @return error: 第一个写入错误
+ */
+func (r *NDJSONReporter) Stream(results <-chan *types.ScanResult, w io.Writer) error {
+	enc := json.NewEncoder(w) // Encode 每次调用以换行结尾
+	var firstErr error
+	for res := range results {
+		if firstErr != nil {
+			continue
+		}
+		line := ToNDJSONResult(res)
+		if r.Verbose {
+			line.Suppressed = toNDJSONFindings(res.SuppressedFindings)
+		}
+		if err := enc.Encode(line); err != nil {
+			firstErr = fmt.Errorf("failed to write result for %s: %w", res.File.Path, err)
+		}
+	}
+	return firstErr
+}
+
+/**
+ * @Description: 转换为自包含的单行结果 (-watch 的 webhook 通知同样使用该格式)
+ * @author: Mr wpl
+ * @param res *types.ScanResult: 扫描结果
+ * @return NDJSONResult: 单行结果
+ */
+func ToNDJSONResult(res *types.ScanResult) NDJSONResult {
+	line := NDJSONResult{
+		File: NDJSONFile{
+			Path:         res.File.Path,
+			RelativePath: res.File.RelativePath,
+			Size:         res.File.Size,
+			ModTime:      res.File.ModTime,
+			Encoding:     res.File.Encoding,
+		},
+		Risk:             res.OverallRisk.String(),
+		RiskLevel:        int(res.OverallRisk),
+		Findings:         make([]NDJSONFinding, 0, len(res.Findings)),
+		Duration:         res.Duration.String(),
+		SkippedAnalyzers: res.SkippedAnalyzers,
+		PartialAST:       res.PartialAST,
+		ASTTruncated:     res.ASTTruncatedLines,
+		Truncated:        res.TruncatedFindings,
+		DuplicateOf:      res.DuplicateOf,
+		DeployedBy:       res.DeployedBy,
+		Incomplete:       res.Incomplete,
+		Cached:           res.Cached,
+		Signature:        res.Signature,
+		VT:               res.VT,
+	}
+	if res.Error != nil {
+		line.Error = res.Error.Error()
+	}
+	line.Findings = append(line.Findings, toNDJSONFindings(res.Findings)...)
+	return line
+}
+
+// toNDJSONFindings 转换发现列表
+func toNDJSONFindings(findings []*types.Finding) []NDJSONFinding {
+	var out []NDJSONFinding
+	for _, f := range findings {
+		out = append(out, NDJSONFinding{
+			Analyzer:    f.AnalyzerName,
+			Description: f.Description,
+			Risk:        f.Risk.String(),
+			Confidence:  f.Confidence,
+			Severity:    f.Severity,
+			CVSSVector:  f.CVSSVector,
+			Snippet:     f.Snippet,
+			LineNumber:  f.LineNumber,
+			Metadata:    f.Metadata,
+		})
+	}
+	return out
+}
@@ -0,0 +1,303 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: PDF 报告 (服务端生成)，内容与 HTML 报告的汇总和问题文件列表一致
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	// pdfFontFamily 通过 FontPath 加载的 UTF-8 字体的族名
+	pdfFontFamily = "report"
+	// pdfRowHeight 表格行高 (mm)
+	pdfRowHeight = 7.0
+)
+
+// pdfColumns 问题文件表格的列宽 (mm，A4 纵向可用宽度 190)，与 HTML 表格的文件名、风险等级、分数、检测特征对应
+var pdfColumns = []float64{45, 25, 15, 105}
+
+// pdfLabels 报告中的固定文本
+type pdfLabels struct {
+	title, scanTime, scanRoot                  string
+	summary, total, normal, suspicious, trojan string
+	errors, details, noProblems, page          string
+	fileName, riskLevel, score, description    string
+	riskSuspicious, riskTrojan                 string
+	riskError, riskUnknown                     string
+}
+
+// pdfLabelsZH 配置了 UTF-8 字体时使用的中文文本，与 HTML 报告一致
+var pdfLabelsZH = pdfLabels{
+	title: "bt-ShieldML 木马查杀报告", scanTime: "检测时间", scanRoot: "扫描目录",
+	summary: "检测数据汇总", total: "检测文件总数", normal: "正常文件量", suspicious: "疑似木马文件数量", trojan: "木马文件数量",
+	errors: "扫描错误", details: "问题文件列表", noProblems: "未发现问题文件", page: "第 %d 页 / 共 {nb} 页",
+	fileName: "文件名", riskLevel: "风险等级", score: "分数", description: "检测特征",
+	riskSuspicious: "疑似木马", riskTrojan: "木马文件",
+	riskError: "扫描错误", riskUnknown: "未知",
+}
+
+// pdfLabelsEN 未配置字体时使用内置 Helvetica (仅支持 Latin-1)，改用英文文本
+var pdfLabelsEN = pdfLabels{
+	title: "bt-ShieldML Webshell Scan Report", scanTime: "Scan time", scanRoot: "Scan root",
+	summary: "Summary", total: "Total files", normal: "Normal", suspicious: "Suspicious", trojan: "Webshell",
+	errors: "Scan errors", details: "Problem files", noProblems: "No problem files found", page: "Page %d / {nb}",
+	fileName: "File", riskLevel: "Risk", score: "Score", description: "Description",
+	riskSuspicious: "Suspicious", riskTrojan: "Webshell",
+	riskError: "Scan error", riskUnknown: "Unknown",
+}
+
+/**
+ * @Description: PDF 报告，实现 Reporter 接口
+ * @author: Mr wpl
+ */
+type PdfReporter struct {
+	ScanRoot string    // 扫描根目录，显示在报告头部
+	SortKeys []SortKey // 问题文件排序键，为空时按风险降序、路径升序
+	// FontPath UTF-8 TrueType 字体文件 (如 NotoSansSC-Regular.ttf)，为空时使用内置 Helvetica，
+	// 报告文本改为英文，文件名和检测特征中的非 Latin-1 字符显示为 '?'
+	FontPath string
+}
+
+/**
+ * @Description: 创建新的PDF报告
+ * @author: Mr wpl
+ * @return *PdfReporter: PDF报告
+ */
+func NewPdfReporter() *PdfReporter {
+	return &PdfReporter{}
+}
+
+/**
+ * @Description: 生成PDF报告：报告头 (标题、检测时间、扫描目录)、汇总表和问题文件列表
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 输出路径
+ * @return error: 错误
+ */
+func (r *PdfReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	if outputPath == "" {
+		return fmt.Errorf("PDF reporter requires an output path")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	labels, family, bold := pdfLabelsEN, "Helvetica", "B"
+	text := latin1Translator(pdf)
+	if r.FontPath != "" {
+		font, err := os.ReadFile(r.FontPath)
+		if err != nil {
+			return fmt.Errorf("pdf font: %w", err)
+		}
+		// 只加载了常规字重，粗体也使用常规字重
+		pdf.AddUTF8FontFromBytes(pdfFontFamily, "", font)
+		labels, family, bold = pdfLabelsZH, pdfFontFamily, ""
+		text = func(s string) string { return s }
+	}
+
+	pdf.SetTitle(labels.title, true)
+	pdf.SetCreator(LSPSource, true)
+	pdf.SetMargins(10, 15, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(family, "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 8, text(fmt.Sprintf(labels.page, pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// 报告头：标志文字、标题、检测时间与扫描目录
+	pdf.SetFont(family, bold, 20)
+	pdf.SetTextColor(44, 133, 24)
+	pdf.CellFormat(0, 10, LSPSource, "", 1, "L", false, 0, "")
+	pdf.SetFont(family, bold, 14)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 9, text(labels.title), "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont(family, "", 10)
+	pdf.SetTextColor(80, 80, 80)
+	pdf.CellFormat(0, 6, text(labels.scanTime+": "+time.Now().Format("2006-01-02 15:04:05")), "", 1, "L", false, 0, "")
+	if r.ScanRoot != "" {
+		pdf.CellFormat(0, 6, text(labels.scanRoot+": "+r.ScanRoot), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	// 汇总表，分类与 HTML 报告一致：Low/Medium 为疑似木马，High/Critical 为木马文件
+	var normal, suspicious, trojan, errs int
+	var problems []*types.ScanResult
+	for _, res := range results {
+		switch {
+		case res.Error != nil:
+			errs++
+		case res.OverallRisk == types.RiskNone:
+			normal++
+			continue
+		case res.OverallRisk == types.RiskLow, res.OverallRisk == types.RiskMedium:
+			suspicious++
+		case res.OverallRisk >= types.RiskHigh:
+			trojan++
+		default:
+			errs++
+		}
+		problems = append(problems, res)
+	}
+	rows := [][2]string{
+		{labels.total, fmt.Sprint(len(results))},
+		{labels.normal, fmt.Sprint(normal)},
+		{labels.suspicious, fmt.Sprint(suspicious)},
+		{labels.trojan, fmt.Sprint(trojan)},
+	}
+	if errs > 0 {
+		rows = append(rows, [2]string{labels.errors, fmt.Sprint(errs)})
+	}
+
+	pdfSectionTitle(pdf, family, bold, text(labels.summary))
+	pdf.SetFont(family, "", 10)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetDrawColor(200, 200, 200)
+	for _, row := range rows {
+		pdf.SetFillColor(245, 245, 245)
+		pdf.CellFormat(60, pdfRowHeight, text(row[0]), "1", 0, "L", true, 0, "")
+		pdf.CellFormat(40, pdfRowHeight, row[1], "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	// 问题文件列表
+	pdfSectionTitle(pdf, family, bold, text(labels.details))
+	if len(problems) == 0 {
+		pdf.SetFont(family, "", 10)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(0, pdfRowHeight, text(labels.noProblems), "", 1, "L", false, 0, "")
+		return pdf.OutputFileAndClose(outputPath)
+	}
+
+	keys := r.SortKeys
+	if len(keys) == 0 {
+		keys = []SortKey{SortByRiskDesc, SortByPathAsc}
+	}
+	header := []string{labels.fileName, labels.riskLevel, labels.score, labels.description}
+	writeHeader := func() {
+		pdf.SetFont(family, bold, 10)
+		pdf.SetFillColor(44, 133, 24)
+		pdf.SetTextColor(255, 255, 255)
+		for i, h := range header {
+			pdf.CellFormat(pdfColumns[i], pdfRowHeight, text(h), "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont(family, "", 9)
+	}
+	writeHeader()
+	pdf.SetHeaderFunc(func() {
+		// 分页后的新页重复表头
+		writeHeader()
+	})
+
+	for _, res := range SortResults(problems, keys...) {
+		riskText, score, desc := pdfRow(res, labels)
+		red, green, blue := pdfRiskColor(res)
+		cells := []string{filepath.Base(res.File.DisplayPath()), riskText, fmt.Sprintf("%d", score), desc}
+		for i, cell := range cells {
+			pdf.SetTextColor(0, 0, 0)
+			if i == 1 {
+				pdf.SetTextColor(red, green, blue)
+			}
+			align := "L"
+			if i == 1 || i == 2 {
+				align = "C"
+			}
+			pdf.CellFormat(pdfColumns[i], pdfRowHeight, pdfFit(pdf, text, cell, pdfColumns[i]-2), "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	return pdf.OutputFileAndClose(outputPath)
+}
+
+// pdfSectionTitle 输出小节标题
+func pdfSectionTitle(pdf *gofpdf.Fpdf, family, style, title string) {
+	pdf.SetFont(family, style, 12)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+}
+
+/**
+ * @Description: 问题文件一行的风险等级、分数与检测特征。分数与 HTML 报告一致 (风险级别数值，扫描错误为 0)，
+ * 检测特征取风险最高的发现
+ * @author: Mr wpl
+ * @param res *types.ScanResult: 扫描结果
+ * @param labels pdfLabels: 报告文本
+ * @return string: 风险等级
+ * @return int: 分数
+ * @return string: 检测特征
+ */
+func pdfRow(res *types.ScanResult, labels pdfLabels) (string, int, string) {
+	if res.Error != nil {
+		return labels.riskError, 0, res.Error.Error()
+	}
+	desc := ""
+	if primary := primaryFinding(res); primary != nil {
+		desc = primary.Description
+		if len(res.Findings) > 1 {
+			desc = fmt.Sprintf("%s (+%d)", desc, len(res.Findings)-1)
+		}
+	}
+	switch {
+	case res.OverallRisk == types.RiskLow, res.OverallRisk == types.RiskMedium:
+		return labels.riskSuspicious, int(res.OverallRisk), desc
+	case res.OverallRisk >= types.RiskHigh:
+		return labels.riskTrojan, int(res.OverallRisk), desc
+	}
+	return labels.riskUnknown, int(res.OverallRisk), desc
+}
+
+// pdfRiskColor 风险等级文字颜色，与 HTML 报告的默认配色相近
+func pdfRiskColor(res *types.ScanResult) (int, int, int) {
+	switch {
+	case res.Error != nil:
+		return 128, 128, 128
+	case res.OverallRisk == types.RiskCritical:
+		return 204, 0, 0
+	case res.OverallRisk == types.RiskHigh:
+		return 255, 51, 0
+	case res.OverallRisk == types.RiskMedium:
+		return 255, 153, 0
+	case res.OverallRisk == types.RiskLow:
+		return 40, 167, 69
+	}
+	return 0, 0, 0
+}
+
+// pdfFit 按字体转换文本，超出宽度 width (mm) 时截断并加 "..."
+func pdfFit(pdf *gofpdf.Fpdf, text func(string) string, s string, width float64) string {
+	if fitted := text(s); pdf.GetStringWidth(fitted) <= width {
+		return fitted
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.GetStringWidth(text(string(runes)+"...")) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return text(string(runes) + "...")
+}
+
+// latin1Translator 内置字体的文本转换：非 Latin-1 字符替换为 '?'，再转换为 cp1252 编码
+func latin1Translator(pdf *gofpdf.Fpdf) func(string) string {
+	toCP1252 := pdf.UnicodeTranslatorFromDescriptor("")
+	return func(s string) string {
+		return toCP1252(strings.Map(func(c rune) rune {
+			if c > 0xFF || (c >= 0x80 && c < 0xA0) {
+				return '?'
+			}
+			return c
+		}, s))
+	}
+}
@@ -5,7 +5,10 @@
  */
 package reporting
 
-import "bt-shieldml/pkg/types"
+import (
+	"bt-shieldml/pkg/types"
+	"io"
+)
 
 // Reporter 定义了报告生成器的通用接口
 type Reporter interface {
@@ -13,3 +16,10 @@ type Reporter interface {
 	// 如果报告类型是直接输出（如控制台），outputPath 可能会被忽略
 	Generate(results []*types.ScanResult, outputPath string) error
 }
+
+// StreamingReporter 边扫描边输出的报告生成器，每个文件扫描完成后立即写出结果
+type StreamingReporter interface {
+	Reporter
+	// Stream 从 results 读取结果并逐条写入 w，直到 results 被关闭
+	Stream(results <-chan *types.ScanResult, w io.Writer) error
+}
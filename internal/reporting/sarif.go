@@ -0,0 +1,271 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: SARIF 2.1.0 报告，供 GitHub Advanced Security、Azure DevOps 等代码扫描平台导入
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// SARIFVersion 输出的 SARIF 版本
+	SARIFVersion = "2.1.0"
+	// SARIFSchema SARIF 2.1.0 的 JSON Schema 地址
+	SARIFSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+	// sarifRootBaseID 相对路径所基于的扫描根目录
+	sarifRootBaseID = "SCANROOT"
+)
+
+// SARIFLog SARIF 文档
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun 一次扫描
+type SARIFRun struct {
+	Tool               SARIFTool                        `json:"tool"`
+	Invocations        []SARIFInvocation                `json:"invocations,omitempty"`
+	OriginalURIBaseIDs map[string]SARIFArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []SARIFResult                    `json:"results"`
+}
+
+// SARIFTool 工具信息
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver 工具主组件及其规则 (每个分析器一条规则)
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules,omitempty"`
+}
+
+// SARIFRule 规则，id 为分析器名称
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFInvocation 工具运行情况，扫描出错的文件记录为执行通知
+type SARIFInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []SARIFNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+// SARIFNotification 执行通知
+type SARIFNotification struct {
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFResult 一条结果，对应一条发现
+type SARIFResult struct {
+	RuleID       string                 `json:"ruleId"`
+	Level        string                 `json:"level"`
+	Message      SARIFMessage           `json:"message"`
+	Locations    []SARIFLocation        `json:"locations"`
+	Suppressions []SARIFSuppression     `json:"suppressions,omitempty"`
+	Properties   map[string]interface{} `json:"properties,omitempty"`
+}
+
+// SARIFMessage 消息文本
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFLocation 位置
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation 文件及行号
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation 文件 URI，uriBaseId 非空时 uri 为相对路径
+type SARIFArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// SARIFRegion 行范围，行号从 1 开始
+type SARIFRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// SARIFSuppression 抑制信息，用于被 min_confidence 抑制的发现 (仅 -verbose)
+type SARIFSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+/**
+ * @Description: SARIF 报告，实现 Reporter 接口
+ * @author: Mr wpl
+ */
+type SarifReporter struct {
+	ScanRoot string // 扫描根目录，根目录下的文件以相对路径输出
+	Verbose  bool   // 输出被 min_confidence 抑制的发现 (带 suppressions)
+}
+
+/**
+ * @Description: 创建新的SARIF报告
+ * @author: Mr wpl
+ * @return *SarifReporter: SARIF报告
+ */
+func NewSarifReporter() *SarifReporter {
+	return &SarifReporter{}
+}
+
+/**
+ * @Description: 生成 SARIF 报告，outputPath 为空时写到 stdout
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 输出路径
+ * @return error: 错误
+ */
+func (r *SarifReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Build(results))
+}
+
+/**
+ * @Description: 将扫描结果转换为 SARIF 文档。每条发现一个 result，规则为产生发现的分析器；
+ * 扫描出错的文件作为执行通知记录在 invocations 中
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @return SARIFLog: SARIF 文档
+ */
+func (r *SarifReporter) Build(results []*types.ScanResult) SARIFLog {
+	run := SARIFRun{
+		Tool: SARIFTool{Driver: SARIFDriver{
+			Name:           LSPSource,
+			InformationURI: "https://github.com/aaPanel/btShieldML",
+		}},
+		Results: []SARIFResult{},
+	}
+	if r.ScanRoot != "" {
+		run.OriginalURIBaseIDs = map[string]SARIFArtifactLocation{
+			sarifRootBaseID: {URI: strings.TrimSuffix(FileURI(r.ScanRoot), "/") + "/"},
+		}
+	}
+
+	invocation := SARIFInvocation{ExecutionSuccessful: true}
+	rules := make(map[string]bool)
+	for _, res := range SortResults(results, SortByPathAsc) {
+		artifact := r.artifactLocation(res)
+		if res.Error != nil {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, SARIFNotification{
+				Level:     "error",
+				Message:   SARIFMessage{Text: "Scan error: " + res.Error.Error()},
+				Locations: []SARIFLocation{{PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: artifact}}},
+			})
+			continue
+		}
+		for _, f := range res.Findings {
+			rules[f.AnalyzerName] = true
+			run.Results = append(run.Results, sarifResult(f, res, artifact))
+		}
+		if r.Verbose {
+			for _, f := range res.SuppressedFindings {
+				rules[f.AnalyzerName] = true
+				result := sarifResult(f, res, artifact)
+				result.Suppressions = []SARIFSuppression{{Kind: "external", Justification: "confidence below output.min_confidence"}}
+				run.Results = append(run.Results, result)
+			}
+		}
+	}
+	run.Invocations = []SARIFInvocation{invocation}
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, SARIFRule{
+			ID:               name,
+			ShortDescription: SARIFMessage{Text: "Webshell detection by the " + name + " analyzer"},
+		})
+	}
+
+	return SARIFLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: []SARIFRun{run}}
+}
+
+/**
+ * @Description: 文件位置：在扫描根目录下时为相对 SCANROOT 的路径，否则为 file:// URI
+ * @author: Mr wpl
+ * @param res *types.ScanResult: 扫描结果
+ * @return SARIFArtifactLocation: 文件位置
+ */
+func (r *SarifReporter) artifactLocation(res *types.ScanResult) SARIFArtifactLocation {
+	if r.ScanRoot != "" && res.File.RelativePath != "" {
+		rel := (&url.URL{Path: filepath.ToSlash(res.File.RelativePath)}).String()
+		return SARIFArtifactLocation{URI: rel, URIBaseID: sarifRootBaseID}
+	}
+	return SARIFArtifactLocation{URI: FileURI(res.File.Path)}
+}
+
+// sarifResult 将一条发现转换为 SARIF result，metadata 带 line 时定位到该行
+func sarifResult(f *types.Finding, res *types.ScanResult, artifact SARIFArtifactLocation) SARIFResult {
+	location := SARIFLocation{PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: artifact}}
+	if line := findingLine(f); line > 0 {
+		location.PhysicalLocation.Region = &SARIFRegion{StartLine: line}
+	}
+	properties := map[string]interface{}{
+		"risk":        f.Risk.String(),
+		"overallRisk": res.OverallRisk.String(),
+	}
+	if f.Confidence > 0 {
+		properties["confidence"] = f.Confidence
+	}
+	if f.CVSSVector != "" {
+		properties["cvssVector"] = f.CVSSVector
+	}
+	return SARIFResult{
+		RuleID:     f.AnalyzerName,
+		Level:      sarifLevel(f.Risk),
+		Message:    SARIFMessage{Text: f.Description},
+		Locations:  []SARIFLocation{location},
+		Properties: properties,
+	}
+}
+
+// sarifLevel 风险级别映射为 SARIF level：Critical/High 为 error，Medium 为 warning，Low 为 note，其余为 none
+func sarifLevel(risk types.RiskLevel) string {
+	switch {
+	case risk >= types.RiskHigh:
+		return "error"
+	case risk == types.RiskMedium:
+		return "warning"
+	case risk == types.RiskLow:
+		return "note"
+	}
+	return "none"
+}
@@ -0,0 +1,176 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: SARIF 报告测试：输出按 testdata 中的 SARIF 2.1.0 JSON Schema 校验
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// loadSARIFSchema 编译 testdata 中的 SARIF 2.1.0 Schema (官方 Schema 的节选，覆盖报告输出的全部对象)
+func loadSARIFSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "sarif-schema-2.1.0.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(SARIFSchema, bytes.NewReader(data)); err != nil {
+		t.Fatalf("AddResource() error = %v", err)
+	}
+	schema, err := c.Compile(SARIFSchema)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	return schema
+}
+
+// validateSARIF 生成报告文件并按 Schema 校验，返回解码后的文档
+func validateSARIF(t *testing.T, schema *jsonschema.Schema, r *SarifReporter, results []*types.ScanResult) map[string]interface{} {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "report.sarif")
+	if err := r.Generate(results, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		t.Errorf("report does not match the SARIF 2.1.0 schema: %#v\n%s", err, data)
+	}
+	return doc
+}
+
+func TestSarifReportMatchesSchema(t *testing.T) {
+	schema := loadSARIFSchema(t)
+	root := filepath.Join(t.TempDir(), "www root")
+
+	finding := func(analyzer string, risk types.RiskLevel, line int) *types.Finding {
+		f := &types.Finding{AnalyzerName: analyzer, Description: analyzer + " matched", Risk: risk, Confidence: 0.9}
+		if line > 0 {
+			f.Metadata = map[string]interface{}{"line": line}
+		}
+		return f
+	}
+	results := []*types.ScanResult{
+		{
+			File:        types.FileInfo{Path: filepath.Join(root, "shell.php"), RelativePath: "shell.php"},
+			OverallRisk: types.RiskCritical,
+			Findings: []*types.Finding{
+				finding("yara", types.RiskCritical, 12),
+				{AnalyzerName: "hash", Description: "Matched Hash", Risk: types.RiskCritical, CVSSVector: "AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"},
+			},
+		},
+		{
+			File:               types.FileInfo{Path: filepath.Join(root, "dir with spaces", "中文.php"), RelativePath: filepath.Join("dir with spaces", "中文.php")},
+			OverallRisk:        types.RiskMedium,
+			Findings:           []*types.Finding{finding("regex", types.RiskMedium, 3)},
+			SuppressedFindings: []*types.Finding{finding("bayes_words", types.RiskLow, 0)},
+		},
+		{
+			File:        types.FileInfo{Path: filepath.Join(t.TempDir(), "outside#1.php")},
+			OverallRisk: types.RiskLow,
+			Findings:    []*types.Finding{finding("entropy_string", types.RiskLow, 0), finding("statistics", types.RiskNone, 0)},
+		},
+		{
+			File:  types.FileInfo{Path: filepath.Join(root, "unreadable.php"), RelativePath: "unreadable.php"},
+			Error: errors.New("permission denied"),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		reporter    *SarifReporter
+		results     []*types.ScanResult
+		wantResults int
+	}{
+		{"no results", &SarifReporter{}, nil, 0},
+		{"absolute file URIs", &SarifReporter{}, results, 5},
+		{"relative to scan root", &SarifReporter{ScanRoot: root}, results, 5},
+		{"verbose with suppressions", &SarifReporter{ScanRoot: root, Verbose: true}, results, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := validateSARIF(t, schema, tt.reporter, tt.results)
+			run := doc["runs"].([]interface{})[0].(map[string]interface{})
+			if got := len(run["results"].([]interface{})); got != tt.wantResults {
+				t.Errorf("got %d results, want %d", got, tt.wantResults)
+			}
+		})
+	}
+}
+
+// TestSarifSchemaRejectsInvalid 确认 Schema 确实生效：非法 level、版本、抑制类型与行号都不能通过校验
+func TestSarifSchemaRejectsInvalid(t *testing.T) {
+	schema := loadSARIFSchema(t)
+	tests := []struct {
+		name   string
+		mutate func(log *SARIFLog)
+	}{
+		{"level outside enum", func(log *SARIFLog) { log.Runs[0].Results[0].Level = "critical" }},
+		{"wrong version", func(log *SARIFLog) { log.Version = "2.0.0" }},
+		{"suppression kind outside enum", func(log *SARIFLog) {
+			log.Runs[0].Results[0].Suppressions = []SARIFSuppression{{Kind: "ignored"}}
+		}},
+		{"region line 0", func(log *SARIFLog) {
+			log.Runs[0].Results[0].Locations[0].PhysicalLocation.Region = &SARIFRegion{StartLine: 0}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := (&SarifReporter{}).Build([]*types.ScanResult{{
+				File:        types.FileInfo{Path: "/www/shell.php"},
+				OverallRisk: types.RiskHigh,
+				Findings:    []*types.Finding{{AnalyzerName: "regex", Description: "eval", Risk: types.RiskHigh}},
+			}})
+			tt.mutate(&log)
+			data, err := json.Marshal(log)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc interface{}
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if err := schema.Validate(doc); err == nil {
+				t.Errorf("invalid document passed validation: %s", data)
+			}
+		})
+	}
+}
+
+func TestSarifLevel(t *testing.T) {
+	tests := []struct {
+		risk types.RiskLevel
+		want string
+	}{
+		{types.RiskCritical, "error"},
+		{types.RiskHigh, "error"},
+		{types.RiskMedium, "warning"},
+		{types.RiskLow, "note"},
+		{types.RiskNone, "none"},
+		{types.RiskUnknown, "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.risk.String(), func(t *testing.T) {
+			if got := sarifLevel(tt.risk); got != tt.want {
+				t.Errorf("sarifLevel(%v) = %q, want %q", tt.risk, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: Slack 通知：扫描发现达到 slack.min_risk 的文件时向 Incoming Webhook 发送 Block Kit 消息 (标题、风险最高的文件、扫描时间与文件数)
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// slackMaxFiles 消息中列出的文件数上限
+	slackMaxFiles = 5
+	// slackTimeout 单次 webhook 请求超时
+	slackTimeout = 10 * time.Second
+	// slackHeaderMaxLen Block Kit header 文本的最大长度
+	slackHeaderMaxLen = 150
+)
+
+// slackMessage Incoming Webhook 的请求体，Text 用于通知预览
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlock Block Kit 的一个 block (header、section 或 context)
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText Block Kit 文本对象
+type slackText struct {
+	Type string `json:"type"` // plain_text 或 mrkdwn
+	Text string `json:"text"`
+}
+
+/**
+ * @Description: Slack 通知，实现 Reporter 接口。只有存在风险不低于 MinRisk 的文件时才发送消息，outputPath 被忽略
+ * @author: Mr wpl
+ */
+type SlackReporter struct {
+	WebhookURL string
+	MinRisk    types.RiskLevel
+	ScanRoot   string // 扫描根目录，显示在消息标题中
+	Client     *http.Client
+}
+
+/**
+ * @Description: 创建 Slack 通知
+ * @author: Mr wpl
+ * @param cfg types.Slack: Slack 配置
+ * @return *SlackReporter: Slack 通知
+ * @return error: slack.min_risk 无效时返回错误
+ */
+func NewSlackReporter(cfg types.Slack) (*SlackReporter, error) {
+	minRisk, err := cfg.MinRiskLevel()
+	if err != nil {
+		return nil, fmt.Errorf("invalid slack.min_risk: %w", err)
+	}
+	return &SlackReporter{WebhookURL: cfg.WebhookURL, MinRisk: minRisk, Client: &http.Client{Timeout: slackTimeout}}, nil
+}
+
+/**
+ * @Description: 存在风险不低于 MinRisk 的文件时 POST Block Kit 消息到 webhook，否则不做任何事
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param outputPath string: 未使用
+ * @return error: 请求失败或 webhook 返回非 2xx 时返回错误
+ */
+func (r *SlackReporter) Generate(results []*types.ScanResult, outputPath string) error {
+	var risky []*types.ScanResult
+	for _, res := range results {
+		if res.OverallRisk >= r.MinRisk {
+			risky = append(risky, res)
+		}
+	}
+	if len(risky) == 0 {
+		return nil
+	}
+	if r.WebhookURL == "" {
+		return fmt.Errorf("slack notification requires slack.webhook_url")
+	}
+
+	body, err := json.Marshal(r.buildMessage(results, risky, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: slackTimeout}
+	}
+	resp, err := client.Post(r.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post slack message: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// buildMessage 构造消息：header (最高风险等级与扫描根目录)、section (风险最高的前 slackMaxFiles 个文件)、context (扫描时间与文件总数)
+func (r *SlackReporter) buildMessage(results []*types.ScanResult, risky []*types.ScanResult, now time.Time) slackMessage {
+	sorted := SortResults(risky, SortByRiskDesc, SortByPathAsc)
+	top := sorted[0].OverallRisk
+
+	header := fmt.Sprintf("%s %s: %d risky file(s) found", top.Emoji(), top.String(), len(risky))
+	if r.ScanRoot != "" {
+		header += " in " + r.ScanRoot
+	}
+	if runes := []rune(header); len(runes) > slackHeaderMaxLen {
+		header = string(runes[:slackHeaderMaxLen-1]) + "…"
+	}
+
+	var files strings.Builder
+	for i, res := range sorted {
+		if i == slackMaxFiles {
+			fmt.Fprintf(&files, "_... and %d more_", len(sorted)-i)
+			break
+		}
+		fmt.Fprintf(&files, "*%s* `%s` (risk score %d/5)\n", res.OverallRisk.String(), slackEscape(res.File.Path), int(res.OverallRisk))
+		if len(res.Findings) > 0 {
+			fmt.Fprintf(&files, "> [%s] %s\n", slackEscape(res.Findings[0].AnalyzerName), slackEscape(res.Findings[0].Description))
+		}
+	}
+
+	host, _ := os.Hostname()
+	context := fmt.Sprintf("Scanned at %s on %s · %d files scanned", now.Format("2006-01-02 15:04:05"), slackEscape(host), len(results))
+	return slackMessage{
+		Text: header,
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: header}},
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: strings.TrimSuffix(files.String(), "\n")}},
+			{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: context}}},
+		},
+	}
+}
+
+// slackEscape 转义 mrkdwn 中的控制字符 &、<、>
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
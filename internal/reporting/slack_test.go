@@ -0,0 +1,244 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: Slack 通知测试：用 httptest 服务器代替 webhook，校验 Block Kit 消息结构、min_risk 过滤与非 2xx 响应的处理
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// slackWebhook 记录收到的请求并以 status 响应的 webhook 模拟服务器
+type slackWebhook struct {
+	*httptest.Server
+	status int
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newSlackWebhook(t *testing.T, status int) *slackWebhook {
+	t.Helper()
+	w := &slackWebhook{status: status}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read webhook body: %v", err)
+		}
+		w.mu.Lock()
+		w.requests = append(w.requests, r)
+		w.bodies = append(w.bodies, body)
+		w.mu.Unlock()
+		rw.WriteHeader(w.status)
+		io.WriteString(rw, http.StatusText(w.status))
+	}))
+	t.Cleanup(w.Close)
+	return w
+}
+
+// Bodies 已收到的请求体
+func (w *slackWebhook) Bodies() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([][]byte(nil), w.bodies...)
+}
+
+// newTestSlackReporter 指向 webhook、min_risk 为 minRisk 的 Slack 通知
+func newTestSlackReporter(t *testing.T, webhook *slackWebhook, minRisk string) *SlackReporter {
+	t.Helper()
+	r, err := NewSlackReporter(types.Slack{WebhookURL: webhook.URL, MinRisk: minRisk})
+	if err != nil {
+		t.Fatalf("NewSlackReporter() error = %v", err)
+	}
+	return r
+}
+
+// slackTestResults 两个 Critical、五个 High、一个 Medium 与一个正常文件
+func slackTestResults() []*types.ScanResult {
+	results := []*types.ScanResult{
+		{File: types.FileInfo{Path: "/www/index.php"}, OverallRisk: types.RiskNone},
+		{
+			File:        types.FileInfo{Path: "/www/lib/medium.php"},
+			OverallRisk: types.RiskMedium,
+			Findings:    []*types.Finding{{AnalyzerName: "statistical", Description: "high entropy", Risk: types.RiskMedium}},
+		},
+		{
+			File:        types.FileInfo{Path: "/www/z_shell.php"},
+			OverallRisk: types.RiskCritical,
+			Findings:    []*types.Finding{{AnalyzerName: "yara", Description: "webshell_eval", Risk: types.RiskCritical}},
+		},
+		{
+			File:        types.FileInfo{Path: "/www/a<&>.php"},
+			OverallRisk: types.RiskCritical,
+			Findings: []*types.Finding{
+				{AnalyzerName: "regex", Description: "eval(<b>$_POST</b>)", Risk: types.RiskCritical},
+				{AnalyzerName: "yara", Description: "second finding", Risk: types.RiskHigh},
+			},
+		},
+	}
+	for i := 0; i < 5; i++ {
+		results = append(results, &types.ScanResult{File: types.FileInfo{Path: fmt.Sprintf("/www/high%d.php", i)}, OverallRisk: types.RiskHigh})
+	}
+	return results
+}
+
+func TestSlackReporterPayload(t *testing.T) {
+	webhook := newSlackWebhook(t, http.StatusOK)
+	r := newTestSlackReporter(t, webhook, "")
+	r.ScanRoot = "/www"
+	if err := r.Generate(slackTestResults(), ""); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	bodies := webhook.Bodies()
+	if len(bodies) != 1 {
+		t.Fatalf("webhook received %d requests, want 1", len(bodies))
+	}
+	req := webhook.requests[0]
+	if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request = %s %s, want POST application/json", req.Method, req.Header.Get("Content-Type"))
+	}
+
+	var msg struct {
+		Text   string `json:"text"`
+		Blocks []struct {
+			Type string `json:"type"`
+			Text *struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"text"`
+			Elements []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(bodies[0], &msg); err != nil {
+		t.Fatalf("payload is not valid JSON: %v\n%s", err, bodies[0])
+	}
+
+	// 默认 min_risk 为 high：两个 Critical 与五个 High 文件
+	wantHeader := "🔴 Critical: 7 risky file(s) found in /www"
+	if msg.Text != wantHeader {
+		t.Errorf("text = %q, want %q", msg.Text, wantHeader)
+	}
+	if len(msg.Blocks) != 3 {
+		t.Fatalf("got %d blocks, want header, section and context:\n%s", len(msg.Blocks), bodies[0])
+	}
+	header, section, context := msg.Blocks[0], msg.Blocks[1], msg.Blocks[2]
+	if header.Type != "header" || header.Text == nil || header.Text.Type != "plain_text" || header.Text.Text != wantHeader {
+		t.Errorf("header block = %+v, want plain_text %q", header, wantHeader)
+	}
+
+	if section.Type != "section" || section.Text == nil || section.Text.Type != "mrkdwn" {
+		t.Fatalf("section block = %+v, want mrkdwn text", section)
+	}
+	// 按风险降序、路径升序列出前 5 个文件，路径与发现中的 &、<、> 被转义，每个文件只列出第一条发现
+	wantSection := strings.Join([]string{
+		"*Critical* `/www/a&lt;&amp;&gt;.php` (risk score 5/5)",
+		"> [regex] eval(&lt;b&gt;$_POST&lt;/b&gt;)",
+		"*Critical* `/www/z_shell.php` (risk score 5/5)",
+		"> [yara] webshell_eval",
+		"*High* `/www/high0.php` (risk score 4/5)",
+		"*High* `/www/high1.php` (risk score 4/5)",
+		"*High* `/www/high2.php` (risk score 4/5)",
+		"_... and 2 more_",
+	}, "\n")
+	if section.Text.Text != wantSection {
+		t.Errorf("section text =\n%s\nwant\n%s", section.Text.Text, wantSection)
+	}
+
+	if context.Type != "context" || len(context.Elements) != 1 || context.Elements[0].Type != "mrkdwn" {
+		t.Fatalf("context block = %+v, want one mrkdwn element", context)
+	}
+	if text := context.Elements[0].Text; !strings.HasPrefix(text, "Scanned at ") || !strings.HasSuffix(text, " · 9 files scanned") {
+		t.Errorf("context text = %q, want the scan time and total file count", text)
+	}
+}
+
+func TestSlackReporterMinRisk(t *testing.T) {
+	tests := []struct {
+		minRisk    string
+		results    []*types.ScanResult
+		wantSent   bool
+		wantHeader string
+	}{
+		{"high", slackTestResults()[:2], false, ""},
+		{"", slackTestResults()[:2], false, ""},
+		{"medium", slackTestResults()[:2], true, "🟡 Medium: 1 risky file(s) found"},
+		{"critical", slackTestResults()[4:], false, ""},
+		{"critical", slackTestResults(), true, "🔴 Critical: 2 risky file(s) found"},
+		{"low", slackTestResults(), true, "🔴 Critical: 8 risky file(s) found"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d results", tt.minRisk, len(tt.results)), func(t *testing.T) {
+			webhook := newSlackWebhook(t, http.StatusOK)
+			if err := newTestSlackReporter(t, webhook, tt.minRisk).Generate(tt.results, ""); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			bodies := webhook.Bodies()
+			if (len(bodies) == 1) != tt.wantSent || len(bodies) > 1 {
+				t.Fatalf("webhook received %d requests, want sent = %v", len(bodies), tt.wantSent)
+			}
+			if !tt.wantSent {
+				return
+			}
+			var msg slackMessage
+			if err := json.Unmarshal(bodies[0], &msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Text != tt.wantHeader {
+				t.Errorf("header = %q, want %q", msg.Text, tt.wantHeader)
+			}
+		})
+	}
+
+	if _, err := NewSlackReporter(types.Slack{MinRisk: "severe"}); err == nil {
+		t.Error("NewSlackReporter() with an invalid min_risk error = nil, want an error")
+	}
+}
+
+func TestSlackReporterErrors(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNoContent, http.StatusMovedPermanently, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			webhook := newSlackWebhook(t, status)
+			err := newTestSlackReporter(t, webhook, "").Generate(slackTestResults(), "")
+			if status < 300 {
+				if err != nil {
+					t.Errorf("Generate() error = %v, want nil for %d", err, status)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), fmt.Sprint(status)) {
+				t.Errorf("Generate() error = %v, want an error with status %d", err, status)
+			}
+		})
+	}
+
+	// 没有 webhook 地址时，只有需要发送消息才报错
+	r := &SlackReporter{MinRisk: types.RiskHigh}
+	if err := r.Generate(slackTestResults()[:2], ""); err != nil {
+		t.Errorf("Generate() without risky files error = %v, want nil", err)
+	}
+	if err := r.Generate(slackTestResults(), ""); err == nil || !strings.Contains(err.Error(), "webhook_url") {
+		t.Errorf("Generate() without webhook_url error = %v, want an error naming webhook_url", err)
+	}
+
+	// webhook 无法连接
+	webhook := newSlackWebhook(t, http.StatusOK)
+	r = newTestSlackReporter(t, webhook, "")
+	webhook.Close()
+	if err := r.Generate(slackTestResults(), ""); err == nil {
+		t.Error("Generate() with an unreachable webhook error = nil, want an error")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * @Date: 2025-06-03 10:18:52
+ * @Editors: Mr wpl
+ * @Description: 报告结果排序，各报告生成器共用
+ */
+package reporting
+
+import (
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKey 结果排序键
+type SortKey string
+
+const (
+	SortByRiskDesc     SortKey = "risk_desc"
+	SortByRiskAsc      SortKey = "risk_asc"
+	SortByPathAsc      SortKey = "path_asc"
+	SortByPathDesc     SortKey = "path_desc"
+	SortBySizeDesc     SortKey = "size_desc"
+	SortByDurationDesc SortKey = "duration_desc"
+	SortByModTimeDesc  SortKey = "mtime_desc"
+)
+
+// sortKeyCompare 各排序键的比较函数，返回负数表示 a 排在 b 前面
+var sortKeyCompare = map[SortKey]func(a, b *types.ScanResult) int{
+	SortByRiskDesc: func(a, b *types.ScanResult) int { return int(b.OverallRisk) - int(a.OverallRisk) },
+	SortByRiskAsc:  func(a, b *types.ScanResult) int { return int(a.OverallRisk) - int(b.OverallRisk) },
+	SortByPathAsc:  func(a, b *types.ScanResult) int { return strings.Compare(a.File.Path, b.File.Path) },
+	SortByPathDesc: func(a, b *types.ScanResult) int { return strings.Compare(b.File.Path, a.File.Path) },
+	SortBySizeDesc: func(a, b *types.ScanResult) int { return compareInt64(b.File.Size, a.File.Size) },
+	SortByDurationDesc: func(a, b *types.ScanResult) int {
+		return compareInt64(int64(b.Duration), int64(a.Duration))
+	},
+	SortByModTimeDesc: func(a, b *types.ScanResult) int { return b.File.ModTime.Compare(a.File.ModTime) },
+}
+
+/**
+ * @Description: 解析排序键名称 (如 "risk_desc")，名称无效时返回错误
+ * @author: Mr wpl
+ * @param names []string: 排序键名称
+ * @return []SortKey: 排序键
+ * @return error: 错误
+ */
+func ParseSortKeys(names []string) ([]SortKey, error) {
+	keys := make([]SortKey, 0, len(names))
+	for _, name := range names {
+		key := SortKey(strings.ToLower(strings.TrimSpace(name)))
+		if key == "" {
+			continue
+		}
+		if _, ok := sortKeyCompare[key]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q (valid: risk_desc, risk_asc, path_asc, path_desc, size_desc, duration_desc, mtime_desc)", name)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+/**
+ * @Description: 按多个排序键稳定排序，前面的键优先，全部相等时保持原顺序。返回排序后的新切片，不修改 results
+ * @author: Mr wpl
+ * @param results []*types.ScanResult: 扫描结果
+ * @param keys ...SortKey: 排序键
+ * @return []*types.ScanResult: 排序后的结果
+ */
+func SortResults(results []*types.ScanResult, keys ...SortKey) []*types.ScanResult {
+	sorted := make([]*types.ScanResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for _, key := range keys {
+			compare, ok := sortKeyCompare[key]
+			if !ok {
+				continue
+			}
+			if c := compare(sorted[i], sorted[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return sorted
+}
+
+// compareInt64 比较两个 int64，返回 -1、0 或 1
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
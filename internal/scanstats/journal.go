@@ -0,0 +1,344 @@
+/*
+ * @Date: 2025-06-10 16:20:44
+ * @Editors: Mr wpl
+ * @Description: 扫描日志 (data/scan_journal.jsonl) 与统计，供 /api/stats 使用
+ */
+package scanstats
+
+import (
+	"bt-shieldml/pkg/types"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJournalPath 默认扫描日志路径
+const DefaultJournalPath = "data/scan_journal.jsonl"
+
+// topDetectedLimit top_10_detected_files 返回的文件数
+const topDetectedLimit = 10
+
+// FileRecord 一次扫描中单个文件的结果
+type FileRecord struct {
+	Filename   string   `json:"filename"`
+	SHA256     string   `json:"sha256,omitempty"`
+	RiskScore  int      `json:"risk_score"`          // 0 表示未检出
+	Risk       string   `json:"risk"`                // 风险等级名称 (Critical/High/Medium/Low/None)
+	Analyzers  []string `json:"analyzers,omitempty"` // 产生发现的分析器
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// JournalEntry 一次扫描的记录，每行一条
+type JournalEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	DurationMs int64     `json:"duration_ms"`
+	// ScanRoot 命令行扫描的根目录 (HTML 报告按此查找同一目录的历史扫描)，上传扫描为空
+	ScanRoot string       `json:"scan_root,omitempty"`
+	Files    []FileRecord `json:"files"`
+}
+
+// DetectedFile 被检出的文件及检出次数
+type DetectedFile struct {
+	Filename string `json:"filename"`
+	SHA256   string `json:"sha256,omitempty"`
+	Count    int    `json:"count"`
+}
+
+// Stats GET /api/stats 的返回内容
+type Stats struct {
+	TotalScansToday        int            `json:"total_scans_today"`
+	TotalFilesScannedToday int            `json:"total_files_scanned_today"`
+	DetectionsByRisk       map[string]int `json:"detections_by_risk"`
+	AvgScanDurationMs      float64        `json:"avg_scan_duration_ms"`
+	TopDetectedFiles       []DetectedFile `json:"top_10_detected_files"`
+	LastScanTime           *time.Time     `json:"last_scan_time"` // 无扫描记录时为 null
+}
+
+// AnalyzerHit 单个分析器的触发次数与命中率
+type AnalyzerHit struct {
+	Hits    int     `json:"hits"`
+	HitRate float64 `json:"hit_rate"` // hits / total_files
+}
+
+// AnalyzerStats GET /api/stats/analyzer 的返回内容
+type AnalyzerStats struct {
+	TotalFiles int                    `json:"total_files"`
+	Analyzers  map[string]AnalyzerHit `json:"analyzers"`
+}
+
+// Recorder 记录扫描结果：写入扫描日志，同时在内存中保留一份，日志不可用时以内存为准
+type Recorder struct {
+	mu      sync.Mutex
+	path    string
+	entries []JournalEntry
+}
+
+/**
+ * @Description: 创建记录器，已有的扫描日志会被加载到内存
+ * @author: Mr wpl
+ * @param path string: 扫描日志路径
+ * @return *Recorder: 记录器
+ */
+func NewRecorder(path string) *Recorder {
+	r := &Recorder{path: path}
+	if entries, err := ReadJournal(path); err == nil {
+		r.entries = entries
+	}
+	return r
+}
+
+/**
+ * @Description: 记录一次扫描。写日志失败时返回错误，但内存计数仍会更新
+ * @author: Mr wpl
+ * @param entry JournalEntry: 扫描记录
+ * @return error: 写日志错误
+ */
+func (r *Recorder) Record(entry JournalEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, entry)
+	return AppendJournal(r.path, entry)
+}
+
+/**
+ * @Description: 向扫描日志追加一条记录，不经过 Recorder (命令行扫描使用)
+ * @author: Mr wpl
+ * @param path string: 扫描日志路径
+ * @param entry JournalEntry: 扫描记录
+ * @return error: 错误
+ */
+func AppendJournal(path string, entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+/**
+ * @Description: 由命令行扫描结果构造扫描记录，出错的文件不记录。风险分数与上传扫描一致：
+ * Critical 5、High 4、Medium 3、Low 1、未检出 0
+ * @author: Mr wpl
+ * @param start time.Time: 扫描开始时间
+ * @param scanRoot string: 扫描根目录
+ * @param results []*types.ScanResult: 扫描结果
+ * @return JournalEntry: 扫描记录
+ */
+func EntryFromResults(start time.Time, scanRoot string, results []*types.ScanResult) JournalEntry {
+	entry := JournalEntry{
+		Timestamp:  start,
+		DurationMs: time.Since(start).Milliseconds(),
+		ScanRoot:   scanRoot,
+		Files:      make([]FileRecord, 0, len(results)),
+	}
+	for _, res := range results {
+		if res.Error != nil {
+			continue
+		}
+		record := FileRecord{
+			Filename:   res.File.Path,
+			Risk:       res.OverallRisk.String(),
+			DurationMs: res.Duration.Milliseconds(),
+		}
+		switch res.OverallRisk {
+		case types.RiskCritical:
+			record.RiskScore = 5
+		case types.RiskHigh:
+			record.RiskScore = 4
+		case types.RiskMedium:
+			record.RiskScore = 3
+		case types.RiskLow:
+			record.RiskScore = 1
+		default:
+			record.Risk = "None"
+		}
+		seen := make(map[string]bool, len(res.Findings))
+		for _, f := range res.Findings {
+			if !seen[f.AnalyzerName] {
+				seen[f.AnalyzerName] = true
+				record.Analyzers = append(record.Analyzers, f.AnalyzerName)
+			}
+		}
+		entry.Files = append(entry.Files, record)
+	}
+	return entry
+}
+
+/**
+ * @Description: 返回全部扫描记录，扫描日志存在时从日志读取 (可能被其他进程追加)，否则使用内存记录
+ * @author: Mr wpl
+ * @return []JournalEntry: 扫描记录
+ */
+func (r *Recorder) Entries() []JournalEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entries, err := ReadJournal(r.path); err == nil {
+		return entries
+	}
+	return append([]JournalEntry(nil), r.entries...)
+}
+
+/**
+ * @Description: 清空内存记录并删除扫描日志
+ * @author: Mr wpl
+ * @return error: 删除日志失败时返回错误
+ */
+func (r *Recorder) Reset() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+	if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
+
+/**
+ * @Description: 读取扫描日志，跳过空行和无法解析的行
+ * @author: Mr wpl
+ * @param path string: 扫描日志路径
+ * @return []JournalEntry: 扫描记录
+ * @return error: 文件不存在或读取失败时返回错误
+ */
+func ReadJournal(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // 单次扫描文件较多时行很长
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue // 写入中断留下的残缺行
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+/**
+ * @Description: 计算扫描统计。今日计数按 now 所在时区的自然日计算，其余指标覆盖全部记录
+ * @author: Mr wpl
+ * @param entries []JournalEntry: 扫描记录
+ * @param now time.Time: 当前时间
+ * @return Stats: 统计结果
+ */
+func Compute(entries []JournalEntry, now time.Time) Stats {
+	stats := Stats{
+		DetectionsByRisk: make(map[string]int),
+		TopDetectedFiles: []DetectedFile{},
+	}
+	year, month, day := now.Date()
+	todayStart := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	var totalDuration int64
+	detected := make(map[string]*DetectedFile)
+	for i := range entries {
+		entry := &entries[i]
+		totalDuration += entry.DurationMs
+		if !entry.Timestamp.Before(todayStart) {
+			stats.TotalScansToday++
+			stats.TotalFilesScannedToday += len(entry.Files)
+		}
+		if stats.LastScanTime == nil || entry.Timestamp.After(*stats.LastScanTime) {
+			stats.LastScanTime = &entry.Timestamp
+		}
+
+		for _, file := range entry.Files {
+			if file.RiskScore <= 0 {
+				continue
+			}
+			stats.DetectionsByRisk[file.Risk]++
+
+			key := file.SHA256
+			if key == "" {
+				key = file.Filename
+			}
+			if d, ok := detected[key]; ok {
+				d.Count++
+				d.Filename = file.Filename // 显示最近一次上传的文件名
+			} else {
+				detected[key] = &DetectedFile{Filename: file.Filename, SHA256: file.SHA256, Count: 1}
+			}
+		}
+	}
+	if len(entries) > 0 {
+		stats.AvgScanDurationMs = float64(totalDuration) / float64(len(entries))
+	}
+
+	for _, d := range detected {
+		stats.TopDetectedFiles = append(stats.TopDetectedFiles, *d)
+	}
+	sort.Slice(stats.TopDetectedFiles, func(i, j int) bool {
+		a, b := stats.TopDetectedFiles[i], stats.TopDetectedFiles[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Filename < b.Filename
+	})
+	if len(stats.TopDetectedFiles) > topDetectedLimit {
+		stats.TopDetectedFiles = stats.TopDetectedFiles[:topDetectedLimit]
+	}
+	return stats
+}
+
+/**
+ * @Description: 计算每个分析器的触发次数与命中率 (触发发现的文件数 / 扫描的文件总数)
+ * @author: Mr wpl
+ * @param entries []JournalEntry: 扫描记录
+ * @return AnalyzerStats: 统计结果
+ */
+func ComputeAnalyzerStats(entries []JournalEntry) AnalyzerStats {
+	stats := AnalyzerStats{Analyzers: make(map[string]AnalyzerHit)}
+	hits := make(map[string]int)
+	for _, entry := range entries {
+		for _, file := range entry.Files {
+			stats.TotalFiles++
+			seen := make(map[string]bool, len(file.Analyzers))
+			for _, name := range file.Analyzers {
+				if !seen[name] {
+					seen[name] = true
+					hits[name]++
+				}
+			}
+		}
+	}
+	for name, count := range hits {
+		stats.Analyzers[name] = AnalyzerHit{
+			Hits:    count,
+			HitRate: float64(count) / float64(stats.TotalFiles),
+		}
+	}
+	return stats
+}
@@ -0,0 +1,286 @@
+/*
+ * @Date: 2025-06-17 14:26:38
+ * @Editors: Mr wpl
+ * @Description: 定时扫描守护模式：按 cron 表达式重复扫描，每次保存 HTML 报告，Critical 文件通过 webhook 通知，可定时清理过期报告
+ */
+package scheduler
+
+import (
+	"bt-shieldml/internal/reporting"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// reportTimeLayout 报告文件名中的时间格式
+const reportTimeLayout = "2006-01-02_15-04"
+
+// webhookTimeout 单次 webhook 请求超时
+const webhookTimeout = 10 * time.Second
+
+// Options 定时扫描配置
+type Options struct {
+	Schedule   string // 标准 5 段 cron 表达式，如 "0 3 * * *"
+	Timezone   string // IANA 时区，如 "Asia/Shanghai"，为空时使用本地时区
+	OutputDir  string // HTML 报告目录
+	LogPath    string // 每次扫描摘要的 JSON-L 日志，为空时不写
+	WebhookURL string // 出现 Critical 文件时 POST 通知的地址，为空时不通知
+	// CleanupSchedule 删除 OutputDir 中过期报告的 cron 表达式，为空时不清理。与扫描互斥，扫描进行中时等待其结束
+	CleanupSchedule string
+}
+
+// ScanFunc 执行一次扫描，HTML 报告写到 reportPath，返回全部结果
+type ScanFunc func(reportPath string) ([]*types.ScanResult, error)
+
+// RunSummary 一次定时扫描的摘要，写入 schedule log
+type RunSummary struct {
+	StartedAt     time.Time      `json:"started_at"`
+	FinishedAt    time.Time      `json:"finished_at"`
+	DurationMs    int64          `json:"duration_ms"`
+	Report        string         `json:"report"`
+	TotalFiles    int            `json:"total_files"`
+	ErrorFiles    int            `json:"error_files"`
+	RiskCounts    map[string]int `json:"risk_counts"`
+	CriticalFiles []string       `json:"critical_files,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	NextRun       time.Time      `json:"next_run"`
+}
+
+// webhookFinding webhook 通知中的一条发现
+type webhookFinding struct {
+	Analyzer    string `json:"analyzer"`
+	Description string `json:"description"`
+	Risk        string `json:"risk"`
+}
+
+// webhookFile webhook 通知中的一个 Critical 文件
+type webhookFile struct {
+	Path     string           `json:"path"`
+	Findings []webhookFinding `json:"findings"`
+}
+
+// webhookPayload Critical 文件通知
+type webhookPayload struct {
+	Event     string        `json:"event"` // 固定为 "critical_findings"
+	Host      string        `json:"host"`
+	ScannedAt time.Time     `json:"scanned_at"`
+	Report    string        `json:"report"`
+	Files     []webhookFile `json:"files"`
+}
+
+/**
+ * @Description: 定时扫描器
+ * @author: Mr wpl
+ */
+type Scheduler struct {
+	opts     Options
+	location *time.Location
+	schedule cron.Schedule
+	cleanup  cron.Schedule // 为 nil 时不清理过期报告
+	scan     ScanFunc
+	client   *http.Client
+	logMu    sync.Mutex
+	runMu    sync.Mutex // 扫描与清理互斥
+}
+
+/**
+ * @Description: 校验 cron 表达式与时区并创建定时扫描器
+ * @author: Mr wpl
+ * @param opts Options: 配置
+ * @param scan ScanFunc: 扫描函数
+ * @return *Scheduler: 定时扫描器
+ * @return error: 错误
+ */
+func New(opts Options, scan ScanFunc) (*Scheduler, error) {
+	location := time.Local
+	if opts.Timezone != "" {
+		loc, err := time.LoadLocation(opts.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", opts.Timezone, err)
+		}
+		location = loc
+	}
+	schedule, err := cron.ParseStandard(opts.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", opts.Schedule, err)
+	}
+	var cleanup cron.Schedule
+	if opts.CleanupSchedule != "" {
+		cleanup, err = cron.ParseStandard(opts.CleanupSchedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cleanup schedule %q: %w", opts.CleanupSchedule, err)
+		}
+	}
+	if opts.OutputDir == "" {
+		opts.OutputDir = "."
+	}
+	return &Scheduler{
+		opts:     opts,
+		location: location,
+		schedule: schedule,
+		cleanup:  cleanup,
+		scan:     scan,
+		client:   &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+/**
+ * @Description: 立即执行一次扫描，然后按计划重复执行，直到进程退出。上一次扫描未结束时跳过本次
+ * @author: Mr wpl
+ */
+func (s *Scheduler) Run() {
+	c := cron.New(
+		cron.WithLocation(s.location),
+		cron.WithChain(cron.SkipIfStillRunning(cron.PrintfLogger(logging.WarnLogger))),
+	)
+	c.Schedule(s.schedule, cron.FuncJob(s.runOnce))
+	if s.cleanup != nil {
+		c.Schedule(s.cleanup, cron.FuncJob(s.cleanupReports))
+		logging.InfoLogger.Printf("Expired report cleanup enabled: %q", s.opts.CleanupSchedule)
+	}
+
+	logging.InfoLogger.Printf("Scheduled scans enabled: %q (%s)", s.opts.Schedule, s.location)
+	s.runOnce()
+	c.Run()
+}
+
+/**
+ * @Description: 执行一次扫描：保存报告、发送 webhook、写入摘要日志并输出下次执行时间
+ * @author: Mr wpl
+ */
+func (s *Scheduler) runOnce() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	start := time.Now().In(s.location)
+	reportPath := filepath.Join(s.opts.OutputDir, start.Format(reportTimeLayout)+".html")
+	logging.InfoLogger.Printf("Scheduled scan started, report: %s", reportPath)
+
+	summary := RunSummary{StartedAt: start, Report: reportPath, RiskCounts: make(map[string]int)}
+	var results []*types.ScanResult
+	err := os.MkdirAll(s.opts.OutputDir, 0755)
+	if err == nil {
+		results, err = s.scan(reportPath)
+	}
+	if err != nil {
+		summary.Error = err.Error()
+		logging.ErrorLogger.Printf("Scheduled scan failed: %v", err)
+	}
+
+	var critical []*types.ScanResult
+	for _, res := range results {
+		summary.TotalFiles++
+		if res.Error != nil {
+			summary.ErrorFiles++
+			summary.RiskCounts[types.RiskUnknown.String()]++
+			continue
+		}
+		summary.RiskCounts[res.OverallRisk.String()]++
+		if res.OverallRisk >= types.RiskCritical {
+			critical = append(critical, res)
+			summary.CriticalFiles = append(summary.CriticalFiles, res.File.Path)
+		}
+	}
+
+	if len(critical) > 0 && s.opts.WebhookURL != "" {
+		if err := s.notify(start, reportPath, critical); err != nil {
+			logging.ErrorLogger.Printf("Failed to send webhook notification: %v", err)
+		}
+	}
+
+	summary.FinishedAt = time.Now().In(s.location)
+	summary.DurationMs = summary.FinishedAt.Sub(start).Milliseconds()
+	summary.NextRun = s.schedule.Next(summary.FinishedAt)
+	if err := s.appendLog(summary); err != nil {
+		logging.ErrorLogger.Printf("Failed to write schedule log: %v", err)
+	}
+	logging.InfoLogger.Printf("Scheduled scan finished in %s: %d files, %d critical. Next run at %s",
+		summary.FinishedAt.Sub(start).Round(time.Second), summary.TotalFiles, len(critical), summary.NextRun.Format(time.RFC3339))
+}
+
+/**
+ * @Description: 删除 OutputDir 中已过期的报告，扫描进行中时等待其结束
+ * @author: Mr wpl
+ */
+func (s *Scheduler) cleanupReports() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	expired, errs := reporting.CleanupExpiredReports(s.opts.OutputDir, time.Now(), false)
+	for _, err := range errs {
+		logging.WarnLogger.Printf("Report cleanup: %v", err)
+	}
+	for _, report := range expired {
+		logging.InfoLogger.Printf("Deleted expired report %s (expired %s)", report.Path, report.ExpiresAt.Format(time.RFC3339))
+	}
+	logging.InfoLogger.Printf("Report cleanup finished: %d expired reports deleted from %s. Next cleanup at %s",
+		len(expired), s.opts.OutputDir, s.cleanup.Next(time.Now().In(s.location)).Format(time.RFC3339))
+}
+
+/**
+ * @Description: 将 Critical 文件及其发现 POST 到 webhook
+ * @author: Mr wpl
+ * @param scannedAt time.Time: 扫描开始时间
+ * @param reportPath string: 报告路径
+ * @param critical []*types.ScanResult: Critical 文件
+ * @return error: 错误
+ */
+func (s *Scheduler) notify(scannedAt time.Time, reportPath string, critical []*types.ScanResult) error {
+	host, _ := os.Hostname()
+	payload := webhookPayload{Event: "critical_findings", Host: host, ScannedAt: scannedAt, Report: reportPath}
+	for _, res := range critical {
+		file := webhookFile{Path: res.File.Path}
+		for _, f := range res.Findings {
+			file.Findings = append(file.Findings, webhookFinding{Analyzer: f.AnalyzerName, Description: f.Description, Risk: f.Risk.String()})
+		}
+		payload.Files = append(payload.Files, file)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.opts.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	logging.InfoLogger.Printf("Sent webhook notification for %d critical files", len(critical))
+	return nil
+}
+
+// appendLog 追加一行扫描摘要到 schedule log
+func (s *Scheduler) appendLog(summary RunSummary) error {
+	if s.opts.LogPath == "" {
+		return nil
+	}
+	line, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	s.logMu.Lock()
+	defer s.logMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.opts.LogPath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.opts.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
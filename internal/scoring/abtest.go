@@ -0,0 +1,163 @@
+/*
+ * @Date: 2025-06-20 16:40:53
+ * @Editors: Mr wpl
+ * @Description: 评分策略 A/B 对比：对同一批扫描结果分别用两种策略重新评分，统计一致与分歧的文件及 Cohen's kappa
+ */
+package scoring
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ABDisagreement 两种策略评分不一致的文件
+type ABDisagreement struct {
+	Path  string
+	RiskA types.RiskLevel
+	RiskB types.RiskLevel
+	Diff  int // RiskB - RiskA 的级别差，正值表示 B 评分更高
+}
+
+// ABTestReport 两种评分策略的对比结果，出错的文件不参与对比
+type ABTestReport struct {
+	StrategyA string
+	StrategyB string
+	Total     int              // 参与对比的文件数
+	Agree     []string         // 两种策略评分相同的文件
+	AHigher   []ABDisagreement // A 评分更高的文件
+	BHigher   []ABDisagreement // B 评分更高的文件
+	Kappa     float64          // Cohen's kappa，1 为完全一致，0 为与随机一致程度相当
+	// RisksA/RisksB 每个参与对比的文件在两种策略下的风险等级
+	RisksA map[string]types.RiskLevel
+	RisksB map[string]types.RiskLevel
+}
+
+/**
+ * @Description: 用两种策略对扫描结果重新评分并对比。评分使用结果中保留的发现与 callable 特征
+ * @author: Mr wpl
+ * @param strategyA ScoringStrategy: 策略 A
+ * @param strategyB ScoringStrategy: 策略 B
+ * @param results []*types.ScanResult: 扫描结果
+ * @return ABTestReport: 对比结果
+ */
+func ABTest(strategyA, strategyB ScoringStrategy, results []*types.ScanResult) ABTestReport {
+	report := ABTestReport{
+		StrategyA: strategyA.Name(),
+		StrategyB: strategyB.Name(),
+		RisksA:    make(map[string]types.RiskLevel),
+		RisksB:    make(map[string]types.RiskLevel),
+	}
+	var pairs [][2]types.RiskLevel
+	for _, res := range results {
+		if res == nil || res.Error != nil {
+			continue
+		}
+		featureSet := &features.FeatureSet{Callable: res.Callable}
+		riskA := strategyA.Score(res.Findings, featureSet)
+		riskB := strategyB.Score(res.Findings, featureSet)
+		path := res.File.Path
+		report.Total++
+		report.RisksA[path] = riskA
+		report.RisksB[path] = riskB
+		pairs = append(pairs, [2]types.RiskLevel{riskA, riskB})
+
+		d := ABDisagreement{Path: path, RiskA: riskA, RiskB: riskB, Diff: int(riskB) - int(riskA)}
+		switch {
+		case d.Diff == 0:
+			report.Agree = append(report.Agree, path)
+		case d.Diff < 0:
+			report.AHigher = append(report.AHigher, d)
+		default:
+			report.BHigher = append(report.BHigher, d)
+		}
+	}
+	sort.Strings(report.Agree)
+	sortDisagreements(report.AHigher)
+	sortDisagreements(report.BHigher)
+	report.Kappa = cohensKappa(pairs)
+	return report
+}
+
+// sortDisagreements 按级别差的绝对值降序、路径升序排列
+func sortDisagreements(ds []ABDisagreement) {
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+	sort.SliceStable(ds, func(i, j int) bool {
+		if abs(ds[i].Diff) != abs(ds[j].Diff) {
+			return abs(ds[i].Diff) > abs(ds[j].Diff)
+		}
+		return ds[i].Path < ds[j].Path
+	})
+}
+
+/**
+ * @Description: 计算 Cohen's kappa: (po - pe) / (1 - pe)，po 为实际一致比例，pe 为按各自风险等级分布随机评分的期望一致比例。
+ * 两种策略都只给出同一个等级 (pe 为 1) 时返回 1
+ * @author: Mr wpl
+ * @param pairs [][2]types.RiskLevel: 每个文件在 A、B 下的风险等级
+ * @return float64: kappa
+ */
+func cohensKappa(pairs [][2]types.RiskLevel) float64 {
+	if len(pairs) == 0 {
+		return 0
+	}
+	n := float64(len(pairs))
+	countA := make(map[types.RiskLevel]float64)
+	countB := make(map[types.RiskLevel]float64)
+	agree := 0.0
+	for _, p := range pairs {
+		countA[p[0]]++
+		countB[p[1]]++
+		if p[0] == p[1] {
+			agree++
+		}
+	}
+	po := agree / n
+	pe := 0.0
+	for level, ca := range countA {
+		pe += (ca / n) * (countB[level] / n)
+	}
+	if pe >= 1 {
+		return 1
+	}
+	return (po - pe) / (1 - pe)
+}
+
+/**
+ * @Description: 以 Markdown 输出对比结果：汇总表与分歧文件表
+ * @author: Mr wpl
+ * @return string: Markdown 文本
+ */
+func (r ABTestReport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Scoring A/B test: A = `%s`, B = `%s`\n\n", r.StrategyA, r.StrategyB)
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Files compared | %d |\n", r.Total)
+	fmt.Fprintf(&b, "| Agree | %d |\n", len(r.Agree))
+	fmt.Fprintf(&b, "| A scores higher | %d |\n", len(r.AHigher))
+	fmt.Fprintf(&b, "| B scores higher | %d |\n", len(r.BHigher))
+	fmt.Fprintf(&b, "| Cohen's kappa | %.4f |\n", r.Kappa)
+
+	if len(r.AHigher)+len(r.BHigher) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n### Disagreements\n\n| File | A | B | Diff (B - A) |\n|---|---|---|---|\n")
+	for _, ds := range [][]ABDisagreement{r.AHigher, r.BHigher} {
+		for _, d := range ds {
+			fmt.Fprintf(&b, "| %s | %s | %s | %+d |\n", markdownCell(d.Path), d.RiskA, d.RiskB, d.Diff)
+		}
+	}
+	return b.String()
+}
+
+// markdownCell 转义 Markdown 表格单元格中的 |
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
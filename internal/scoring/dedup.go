@@ -0,0 +1,90 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 报告前合并重复的发现 (如 regex 与 yara 同时命中 eval(base64_decode()，评分仍使用合并前的全部发现
+ */
+package scoring
+
+import (
+	"bt-shieldml/pkg/types"
+	"strings"
+)
+
+// dedupPrefixLen 判定描述相同时比较的前缀长度 (字符数)
+const dedupPrefixLen = 80
+
+/**
+ * @Description: 合并重复的发现：描述前 80 个字符相同的发现 (不论来自哪个分析器)，以及同一分析器的同一风险等级的发现，
+ * 合并为一条。合并后的发现以先出现的一条为准，置信度取最大值，风险等级取最高者 (连同 Severity、CVSSVector)，
+ * 不同的分析器名称用 "+" 连接 (如 "regex+yara")。返回新的切片与副本，不修改传入的发现，
+ * 评分应使用传入的原始切片，只有报告使用去重结果
+ * @author: Mr wpl
+ * @param findings []*types.Finding: 发现
+ * @return []*types.Finding: 去重后的发现，顺序为各组首次出现的顺序
+ */
+func DeduplicateFindings(findings []*types.Finding) []*types.Finding {
+	if len(findings) < 2 {
+		return findings
+	}
+
+	type analyzerRisk struct {
+		analyzer string
+		risk     types.RiskLevel
+	}
+	var merged []*types.Finding
+	byPrefix := make(map[string]*types.Finding)
+	byAnalyzerRisk := make(map[analyzerRisk]*types.Finding)
+	for _, f := range findings {
+		if f == nil {
+			continue
+		}
+		prefix := descriptionPrefix(f.Description)
+		key := analyzerRisk{f.AnalyzerName, f.Risk}
+		target := byPrefix[prefix]
+		if target == nil {
+			target = byAnalyzerRisk[key]
+		}
+		if target == nil {
+			copied := *f
+			target = &copied
+			merged = append(merged, target)
+		} else {
+			mergeFinding(target, f)
+		}
+		// 以合并前的分析器与风险等级登记，后续同类发现仍能找到这一条
+		if _, ok := byPrefix[prefix]; !ok {
+			byPrefix[prefix] = target
+		}
+		if _, ok := byAnalyzerRisk[key]; !ok {
+			byAnalyzerRisk[key] = target
+		}
+	}
+	return merged
+}
+
+// mergeFinding 将 f 合并到 target：置信度取最大值，风险取最高者，追加未出现过的分析器名称
+func mergeFinding(target *types.Finding, f *types.Finding) {
+	if f.Confidence > target.Confidence {
+		target.Confidence = f.Confidence
+	}
+	if f.Risk > target.Risk {
+		target.Risk = f.Risk
+		target.Severity = f.Severity
+		target.CVSSVector = f.CVSSVector
+	}
+	for _, name := range strings.Split(target.AnalyzerName, "+") {
+		if name == f.AnalyzerName {
+			return
+		}
+	}
+	target.AnalyzerName += "+" + f.AnalyzerName
+}
+
+// descriptionPrefix 描述的前 dedupPrefixLen 个字符
+func descriptionPrefix(description string) string {
+	runes := []rune(description)
+	if len(runes) > dedupPrefixLen {
+		runes = runes[:dedupPrefixLen]
+	}
+	return string(runes)
+}
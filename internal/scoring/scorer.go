@@ -4,20 +4,82 @@ import (
 	"bt-shieldml/internal/features"
 	"bt-shieldml/pkg/logging"
 	"bt-shieldml/pkg/types"
+	"fmt"
+	"sort"
+	"strings"
 )
 
-// CalculateScore 实现指定的评分机制
-// 具体评分规则:
-// 1. 正则匹配得1分
-// 2. YARA匹配得1分
-// 3. 正则和YARA同时匹配额外加2分
-// 4. callable为true且融合预测模型置信度>0.85时加2分
-// 5. 文本统计特征异常且callable为true时加2分
-// 6. 最高分限制为5分
-func CalculateScore(findings []*types.Finding, featureSet *features.FeatureSet) types.RiskLevel {
+// ScoringStrategy 评分策略，根据发现与特征计算文件的风险等级，可通过 ABTest 对比两种策略
+type ScoringStrategy interface {
+	Name() string
+	Score(findings []*types.Finding, featureSet *features.FeatureSet) types.RiskLevel
+}
+
+// DefaultStrategy 引擎当前使用的评分策略 (CalculateScore)，Rules 为零值时使用默认规则
+type DefaultStrategy struct {
+	Rules types.ScoringRules
+}
+
+// Name 策略名称
+func (DefaultStrategy) Name() string { return "default" }
+
+// Score 使用 CalculateScore 评分
+func (s DefaultStrategy) Score(findings []*types.Finding, featureSet *features.FeatureSet) types.RiskLevel {
+	return CalculateScore(findings, featureSet, s.Rules)
+}
+
+// MaxFindingStrategy 取发现中最高的风险等级，没有发现时为 Safe
+type MaxFindingStrategy struct{}
+
+// Name 策略名称
+func (MaxFindingStrategy) Name() string { return "max_finding" }
+
+// Score 返回发现中最高的风险等级
+func (MaxFindingStrategy) Score(findings []*types.Finding, featureSet *features.FeatureSet) types.RiskLevel {
+	risk := types.RiskNone
+	for _, f := range findings {
+		if f.Risk > risk {
+			risk = f.Risk
+		}
+	}
+	return risk
+}
+
+/**
+ * @Description: 按名称获取内置评分策略
+ * @author: Mr wpl
+ * @param name string: default 或 max_finding
+ * @param rules types.ScoringRules: default 策略使用的评分规则 (配置中的 scoring)
+ * @return ScoringStrategy: 评分策略
+ * @return error: 未知名称时返回错误
+ */
+func StrategyByName(name string, rules types.ScoringRules) (ScoringStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "default", "":
+		return DefaultStrategy{Rules: rules}, nil
+	case "max_finding":
+		return MaxFindingStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown scoring strategy %q (expected default or max_finding)", name)
+}
+
+// CalculateScore 实现指定的评分机制，各项加分与阈值来自 rules (配置中的 scoring，零值时使用 types.DefaultScoringRules)
+// 具体评分规则 (括号内为默认值):
+// 1. 正则匹配得 rules.Regex 分 (1)
+// 2. YARA匹配得 rules.Yara 分 (1)
+// 3. 正则和YARA同时匹配额外加 rules.RegexAndYara 分 (2)
+// 4. callable为true且融合预测模型置信度>rules.HighConfidence (0.91) 时加 rules.CallableHighConfidence 分 (2)
+// 5. 文本统计特征异常且callable为true时加 rules.CallableStatistical 分 (2)
+// 6. 最高分限制为 rules.MaxScore 分 (5)
+// 7. 分数达到 CriticalScore/HighScore/MediumScore/LowScore (5/4/3/1) 时分别为 Critical/High/Medium/Low
+// 8. htaccess 分析器的发现不计分，风险等级不低于该发现的风险
+func CalculateScore(findings []*types.Finding, featureSet *features.FeatureSet, rules types.ScoringRules) types.RiskLevel {
 	if findings == nil || len(findings) == 0 {
 		return types.RiskNone
 	}
+	if rules == (types.ScoringRules{}) {
+		rules = types.DefaultScoringRules()
+	}
 
 	var totalScore int = 0
 
@@ -26,20 +88,40 @@ func CalculateScore(findings []*types.Finding, featureSet *features.FeatureSet)
 	hasYaraMatch := false
 	highConfidencePrediction := false
 	hasStatisticalAnomaly := false
+	scriptCallable := false // JSP、Python 等没有 AST 的文件由对应分析器判断 callable
+	htaccessRisk := types.RiskNone
 
 	// 1. 分析各检测器结果
 	for _, finding := range findings {
+		// 风险未知的发现 (如分析器超时) 只作记录，不表示匹配
+		if finding.Risk == types.RiskUnknown {
+			continue
+		}
 		switch finding.AnalyzerName {
 		case "regex":
 			hasRegexMatch = true
 			logging.InfoLogger.Printf("检测到正则匹配")
+		case "python_regex":
+			// Python 没有 AST，命中 High 及以上的命令执行/动态执行模式等同于 callable 为 true
+			hasRegexMatch = true
+			if finding.Risk >= types.RiskHigh {
+				scriptCallable = true
+			}
+			logging.InfoLogger.Printf("检测到 Python 正则匹配")
+
+		case "htaccess":
+			// .htaccess 不是脚本，以下加分规则不适用，风险等级不低于 htaccess 发现的风险
+			if finding.Risk > htaccessRisk {
+				htaccessRisk = finding.Risk
+			}
+			logging.InfoLogger.Printf("检测到可疑 .htaccess 指令")
 
 		case "yara":
 			hasYaraMatch = true
 			logging.InfoLogger.Printf("检测到YARA匹配")
 
 		case "svm_prosses":
-			if finding.Confidence > 0.91 {
+			if finding.Confidence > rules.HighConfidence {
 				highConfidencePrediction = true
 				logging.InfoLogger.Printf("检测到高置信度融合模型预测: %.4f", finding.Confidence)
 			}
@@ -47,66 +129,126 @@ func CalculateScore(findings []*types.Finding, featureSet *features.FeatureSet)
 			// 检测到统计分析器的发现，表示统计特征异常
 			hasStatisticalAnomaly = true
 			logging.InfoLogger.Printf("检测到统计特征异常")
+		case "jsp_statistical":
+			// JSP 没有 AST，jsp_statistical 只在存在危险 Java 调用时报告，等同于 callable 为 true
+			hasStatisticalAnomaly = true
+			scriptCallable = true
+			logging.InfoLogger.Printf("检测到 JSP 统计特征异常")
 		}
 	}
 
 	// 2. 根据规则计算分数
-	// 规则1: 正则匹配得1分
+	// 规则1: 正则匹配加分
 	if hasRegexMatch {
-		totalScore += 1
-		logging.InfoLogger.Printf("正则匹配加1分，当前总分: %d", totalScore)
+		totalScore += rules.Regex
+		logging.InfoLogger.Printf("正则匹配加%d分，当前总分: %d", rules.Regex, totalScore)
 	}
 
-	// 规则2: YARA匹配得1分
+	// 规则2: YARA匹配加分
 	if hasYaraMatch {
-		totalScore += 1
-		logging.InfoLogger.Printf("YARA匹配加1分，当前总分: %d", totalScore)
+		totalScore += rules.Yara
+		logging.InfoLogger.Printf("YARA匹配加%d分，当前总分: %d", rules.Yara, totalScore)
 	}
 
-	// 规则3: 正则和YARA同时匹配额外加2分
+	// 规则3: 正则和YARA同时匹配额外加分
 	if hasRegexMatch && hasYaraMatch {
-		totalScore += 2
-		logging.InfoLogger.Printf("正则和YARA同时匹配额外加2分，当前总分: %d", totalScore)
+		totalScore += rules.RegexAndYara
+		logging.InfoLogger.Printf("正则和YARA同时匹配额外加%d分，当前总分: %d", rules.RegexAndYara, totalScore)
 	}
 
-	// 规则4: callable为true且高置信度预测时加2分
-	hasCallable := featureSet != nil && featureSet.Callable
+	// 规则4: callable为true且高置信度预测时加分
+	hasCallable := (featureSet != nil && featureSet.Callable) || scriptCallable
 	// if hasCallable {
 	// 	logging.InfoLogger.Printf("检测到可执行关键函数(callable=true)")
 	// }
 
 	if hasCallable && highConfidencePrediction {
-		totalScore += 2
-		logging.InfoLogger.Printf("可执行关键函数+高置信度预测加2分，当前总分: %d", totalScore)
+		totalScore += rules.CallableHighConfidence
+		logging.InfoLogger.Printf("可执行关键函数+高置信度预测加%d分，当前总分: %d", rules.CallableHighConfidence, totalScore)
 	}
 
-	// 规则5(新增): 统计特征异常且callable为true时加2分
+	// 规则5(新增): 统计特征异常且callable为true时加分
 	if hasCallable && hasStatisticalAnomaly {
-		totalScore += 2
-		logging.InfoLogger.Printf("可执行关键函数+统计特征异常加2分，当前总分: %d", totalScore)
+		totalScore += rules.CallableStatistical
+		logging.InfoLogger.Printf("可执行关键函数+统计特征异常加%d分，当前总分: %d", rules.CallableStatistical, totalScore)
 	}
 
-	// 规则6: 最高分限制为5分
-	if totalScore > 5 {
-		logging.InfoLogger.Printf("当前分数(%d)超过上限，调整为5分", totalScore)
-		totalScore = 5
+	// 规则6: 分数上限
+	if totalScore > rules.MaxScore {
+		logging.InfoLogger.Printf("当前分数(%d)超过上限，调整为%d分", totalScore, rules.MaxScore)
+		totalScore = rules.MaxScore
 	}
 
 	// 3. 将分数转换为风险等级
 	var riskLevel types.RiskLevel
 	switch {
-	case totalScore >= 5:
+	case totalScore >= rules.CriticalScore:
 		riskLevel = types.RiskCritical
-	case totalScore >= 4:
+	case totalScore >= rules.HighScore:
 		riskLevel = types.RiskHigh
-	case totalScore >= 3:
+	case totalScore >= rules.MediumScore:
 		riskLevel = types.RiskMedium
-	case totalScore >= 1:
+	case totalScore >= rules.LowScore:
 		riskLevel = types.RiskLow
 	default:
 		riskLevel = types.RiskNone
 	}
+	if htaccessRisk > riskLevel {
+		riskLevel = htaccessRisk
+	}
 
 	logging.InfoLogger.Printf("最终评分: %d，风险等级: %s", totalScore, riskLevel.String())
 	return riskLevel
 }
+
+/**
+ * @Description: 按置信度过滤发现。置信度为 0 的发现 (静态分析器通常不设置置信度) 始终保留
+ * @author: Mr wpl
+ * @param findings []*types.Finding: 发现
+ * @param minConfidence float64: 最低置信度，<= 0 时不过滤
+ * @return []*types.Finding: 保留的发现
+ * @return []*types.Finding: 被抑制的发现
+ */
+func FilterByConfidence(findings []*types.Finding, minConfidence float64) ([]*types.Finding, []*types.Finding) {
+	if minConfidence <= 0 {
+		return findings, nil
+	}
+	var kept, suppressed []*types.Finding
+	for _, f := range findings {
+		if f.Confidence > 0 && f.Confidence < minConfidence {
+			suppressed = append(suppressed, f)
+		} else {
+			kept = append(kept, f)
+		}
+	}
+	return kept, suppressed
+}
+
+/**
+ * @Description: 限制单个文件的发现数量。超过 limit 时按风险降序保留前 limit 条，
+ * 并追加一条 "engine" 汇总发现说明被省略的数量，同时设置 result.TruncatedFindings
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 扫描结果 (Findings 已完成评分)
+ * @param limit int: 最大发现数
+ */
+func LimitFindings(result *types.ScanResult, limit int) {
+	if limit <= 0 || len(result.Findings) <= limit {
+		return
+	}
+
+	findings := make([]*types.Finding, len(result.Findings))
+	copy(findings, result.Findings)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Risk > findings[j].Risk
+	})
+
+	suppressed := len(findings) - limit
+	kept := append(findings[:limit:limit], &types.Finding{
+		AnalyzerName: "engine",
+		Description:  fmt.Sprintf("%d additional findings suppressed", suppressed),
+		Risk:         findings[limit].Risk,
+	})
+	result.Findings = kept
+	result.TruncatedFindings = true
+	logging.WarnLogger.Printf("Truncated findings for %s: kept %d, suppressed %d", result.File.Path, limit, suppressed)
+}
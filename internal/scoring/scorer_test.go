@@ -0,0 +1,172 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 评分规则测试：各分析器命中组合在默认规则与自定义规则下的风险等级
+ */
+package scoring
+
+import (
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"testing"
+)
+
+// customScoringRules 自定义规则：加分与阈值都不同于默认值，上限 5 低于 Critical 的 6 分，因此最高只能到 High
+var customScoringRules = types.ScoringRules{
+	Regex:                  2,
+	Yara:                   3,
+	RegexAndYara:           1,
+	CallableHighConfidence: 4,
+	CallableStatistical:    1,
+	HighConfidence:         0.8,
+	MaxScore:               5,
+	CriticalScore:          6,
+	HighScore:              5,
+	MediumScore:            3,
+	LowScore:               2,
+}
+
+// scoringTestFindings 按命中情况构造发现，svm_prosses 的置信度 0.95 高于两套规则的高置信度阈值
+func scoringTestFindings(regex, yara, highConfidence, statistical bool) []*types.Finding {
+	var findings []*types.Finding
+	if regex {
+		findings = append(findings, &types.Finding{AnalyzerName: "regex", Risk: types.RiskHigh})
+	}
+	if yara {
+		findings = append(findings, &types.Finding{AnalyzerName: "yara", Risk: types.RiskHigh})
+	}
+	if highConfidence {
+		findings = append(findings, &types.Finding{AnalyzerName: "svm_prosses", Risk: types.RiskHigh, Confidence: 0.95})
+	}
+	if statistical {
+		findings = append(findings, &types.Finding{AnalyzerName: "statistical", Risk: types.RiskMedium})
+	}
+	return findings
+}
+
+// TestCalculateScoreCombinations 正则、YARA、callable、高置信度预测、统计异常的全部 32 种组合
+func TestCalculateScoreCombinations(t *testing.T) {
+	tests := []struct {
+		regex, yara, callable, highConfidence, statistical bool
+		wantDefault, wantCustom                            types.RiskLevel
+	}{
+		{false, false, false, false, false, types.RiskNone, types.RiskNone},
+		{false, false, false, false, true, types.RiskNone, types.RiskNone},
+		{false, false, false, true, false, types.RiskNone, types.RiskNone},
+		{false, false, false, true, true, types.RiskNone, types.RiskNone},
+		{false, false, true, false, false, types.RiskNone, types.RiskNone},
+		{false, false, true, false, true, types.RiskLow, types.RiskNone},
+		{false, false, true, true, false, types.RiskLow, types.RiskMedium},
+		{false, false, true, true, true, types.RiskHigh, types.RiskHigh},
+		{false, true, false, false, false, types.RiskLow, types.RiskMedium},
+		{false, true, false, false, true, types.RiskLow, types.RiskMedium},
+		{false, true, false, true, false, types.RiskLow, types.RiskMedium},
+		{false, true, false, true, true, types.RiskLow, types.RiskMedium},
+		{false, true, true, false, false, types.RiskLow, types.RiskMedium},
+		{false, true, true, false, true, types.RiskMedium, types.RiskMedium},
+		{false, true, true, true, false, types.RiskMedium, types.RiskHigh},
+		{false, true, true, true, true, types.RiskCritical, types.RiskHigh},
+		{true, false, false, false, false, types.RiskLow, types.RiskLow},
+		{true, false, false, false, true, types.RiskLow, types.RiskLow},
+		{true, false, false, true, false, types.RiskLow, types.RiskLow},
+		{true, false, false, true, true, types.RiskLow, types.RiskLow},
+		{true, false, true, false, false, types.RiskLow, types.RiskLow},
+		{true, false, true, false, true, types.RiskMedium, types.RiskMedium},
+		{true, false, true, true, false, types.RiskMedium, types.RiskHigh},
+		{true, false, true, true, true, types.RiskCritical, types.RiskHigh},
+		{true, true, false, false, false, types.RiskHigh, types.RiskHigh},
+		{true, true, false, false, true, types.RiskHigh, types.RiskHigh},
+		{true, true, false, true, false, types.RiskHigh, types.RiskHigh},
+		{true, true, false, true, true, types.RiskHigh, types.RiskHigh},
+		{true, true, true, false, false, types.RiskHigh, types.RiskHigh},
+		{true, true, true, false, true, types.RiskCritical, types.RiskHigh},
+		{true, true, true, true, false, types.RiskCritical, types.RiskHigh},
+		{true, true, true, true, true, types.RiskCritical, types.RiskHigh},
+	}
+	for _, tt := range tests {
+		name := fmt.Sprintf("regex=%v/yara=%v/callable=%v/svm=%v/stat=%v", tt.regex, tt.yara, tt.callable, tt.highConfidence, tt.statistical)
+		t.Run(name, func(t *testing.T) {
+			findings := scoringTestFindings(tt.regex, tt.yara, tt.highConfidence, tt.statistical)
+			fs := &features.FeatureSet{Callable: tt.callable}
+			if got := CalculateScore(findings, fs, types.DefaultScoringRules()); got != tt.wantDefault {
+				t.Errorf("default rules: CalculateScore() = %v, want %v", got, tt.wantDefault)
+			}
+			if got := CalculateScore(findings, fs, types.ScoringRules{}); got != tt.wantDefault {
+				t.Errorf("zero rules: CalculateScore() = %v, want the default %v", got, tt.wantDefault)
+			}
+			if got := CalculateScore(findings, fs, customScoringRules); got != tt.wantCustom {
+				t.Errorf("custom rules: CalculateScore() = %v, want %v", got, tt.wantCustom)
+			}
+		})
+	}
+}
+
+func TestCalculateScoreRules(t *testing.T) {
+	callable := &features.FeatureSet{Callable: true}
+	tests := []struct {
+		name     string
+		findings []*types.Finding
+		fs       *features.FeatureSet
+		rules    types.ScoringRules
+		want     types.RiskLevel
+	}{
+		{
+			name:     "confidence at threshold is not high",
+			findings: []*types.Finding{{AnalyzerName: "svm_prosses", Risk: types.RiskHigh, Confidence: 0.91}, {AnalyzerName: "regex", Risk: types.RiskHigh}},
+			fs:       callable,
+			rules:    types.DefaultScoringRules(),
+			want:     types.RiskLow,
+		},
+		{
+			name:     "lower custom confidence threshold",
+			findings: []*types.Finding{{AnalyzerName: "svm_prosses", Risk: types.RiskHigh, Confidence: 0.85}, {AnalyzerName: "regex", Risk: types.RiskHigh}},
+			fs:       callable,
+			rules:    customScoringRules,
+			want:     types.RiskHigh,
+		},
+		{
+			name:     "unknown risk findings are ignored",
+			findings: []*types.Finding{{AnalyzerName: "regex", Risk: types.RiskUnknown}, {AnalyzerName: "yara", Risk: types.RiskUnknown}},
+			rules:    types.DefaultScoringRules(),
+			want:     types.RiskNone,
+		},
+		{
+			name:     "htaccess risk is a floor",
+			findings: []*types.Finding{{AnalyzerName: "htaccess", Risk: types.RiskHigh}, {AnalyzerName: "regex", Risk: types.RiskHigh}},
+			rules:    customScoringRules,
+			want:     types.RiskHigh,
+		},
+		{
+			name:     "high python_regex implies callable",
+			findings: []*types.Finding{{AnalyzerName: "python_regex", Risk: types.RiskHigh}, {AnalyzerName: "statistical", Risk: types.RiskMedium}},
+			rules:    types.DefaultScoringRules(),
+			want:     types.RiskMedium,
+		},
+		{
+			name:     "jsp_statistical implies callable",
+			findings: []*types.Finding{{AnalyzerName: "jsp_statistical", Risk: types.RiskMedium}},
+			rules:    customScoringRules,
+			want:     types.RiskNone,
+		},
+		{
+			name:     "max score caps before thresholds",
+			findings: scoringTestFindings(true, true, false, false),
+			rules:    types.ScoringRules{Regex: 3, Yara: 3, MaxScore: 4, CriticalScore: 5, HighScore: 4, MediumScore: 3, LowScore: 1},
+			want:     types.RiskHigh,
+		},
+		{
+			name:  "no findings",
+			fs:    callable,
+			rules: customScoringRules,
+			want:  types.RiskNone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateScore(tt.findings, tt.fs, tt.rules); got != tt.want {
+				t.Errorf("CalculateScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
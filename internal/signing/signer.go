@@ -0,0 +1,317 @@
+/*
+ * @Date: 2025-05-21 14:36:02
+ * @Editors: Mr wpl
+ * @Description: 扫描结果签名与验签，用于防篡改审计
+ */
+package signing
+
+import (
+	"bt-shieldml/pkg/types"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Record 是 ScanResult 的规范化表示，覆盖 ScanResult 的全部字段，字段按 JSON 键的字母顺序排列，
+// 序列化结果即为签名使用的规范 JSON (键有序、无空白)。ScanResult 新增字段时需同步加入 Record (由 TestRecordCoversScanResult 检查)
+type Record struct {
+	ASTTruncatedLines  int             `json:"ast_truncated_lines"`
+	Cached             bool            `json:"cached"`
+	Callable           bool            `json:"callable"`
+	ContentMD5         string          `json:"content_md5"`
+	ContentSHA256      string          `json:"content_sha256"`
+	DeployedBy         string          `json:"deployed_by"`
+	DuplicateOf        string          `json:"duplicate_of"`
+	Duration           int64           `json:"duration_ns"`
+	Error              string          `json:"error"`
+	File               RecordFile      `json:"file"`
+	Findings           []RecordFinding `json:"findings"`
+	Incomplete         bool            `json:"incomplete"`
+	OverallRisk        int             `json:"overall_risk"`
+	PartialAST         bool            `json:"partial_ast"`
+	Signature          string          `json:"signature,omitempty"`
+	SkippedAnalyzers   []string        `json:"skipped_analyzers"`
+	SkippedAST         bool            `json:"skipped_ast"`
+	SuppressedFindings []RecordFinding `json:"suppressed_findings"`
+	TruncatedFindings  bool            `json:"truncated_findings"`
+	VT                 string          `json:"vt"`
+}
+
+// RecordFile 对应 types.FileInfo
+type RecordFile struct {
+	Encoding     string `json:"encoding"`
+	MIMEType     string `json:"mime_type"`
+	ModTime      string `json:"mod_time"`
+	Path         string `json:"path"`
+	RelativePath string `json:"relative_path"`
+	Size         int64  `json:"size"`
+}
+
+// RecordFinding 对应 types.Finding，Metadata 由 encoding/json 按键排序序列化
+type RecordFinding struct {
+	Analyzer    string                 `json:"analyzer"`
+	Confidence  float64                `json:"confidence"`
+	CVSSVector  string                 `json:"cvss_vector"`
+	Description string                 `json:"description"`
+	LineNumber  int                    `json:"line_number"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Risk        int                    `json:"risk"`
+	Severity    string                 `json:"severity"`
+	Snippet     string                 `json:"snippet"`
+}
+
+/**
+ * @Description: 将扫描结果转换为规范化记录
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 扫描结果
+ * @return Record: 规范化记录
+ */
+func NewRecord(result *types.ScanResult) Record {
+	rec := Record{
+		ASTTruncatedLines: result.ASTTruncatedLines,
+		Cached:            result.Cached,
+		Callable:          result.Callable,
+		ContentMD5:        result.ContentMD5,
+		ContentSHA256:     result.ContentSHA256,
+		DeployedBy:        result.DeployedBy,
+		DuplicateOf:       result.DuplicateOf,
+		Duration:          int64(result.Duration),
+		File: RecordFile{
+			Encoding:     result.File.Encoding,
+			MIMEType:     result.File.MIMEType,
+			ModTime:      result.File.ModTime.UTC().Format(time.RFC3339Nano),
+			Path:         result.File.Path,
+			RelativePath: result.File.RelativePath,
+			Size:         result.File.Size,
+		},
+		Findings:           newRecordFindings(result.Findings),
+		Incomplete:         result.Incomplete,
+		OverallRisk:        int(result.OverallRisk),
+		PartialAST:         result.PartialAST,
+		Signature:          result.Signature,
+		SkippedAnalyzers:   append([]string{}, result.SkippedAnalyzers...),
+		SkippedAST:         result.SkippedAST,
+		SuppressedFindings: newRecordFindings(result.SuppressedFindings),
+		TruncatedFindings:  result.TruncatedFindings,
+		VT:                 result.VT,
+	}
+	if result.Error != nil {
+		rec.Error = result.Error.Error()
+	}
+	return rec
+}
+
+// newRecordFindings 转换发现列表，跳过 nil，空列表序列化为 [] 而非 null
+func newRecordFindings(findings []*types.Finding) []RecordFinding {
+	records := []RecordFinding{}
+	for _, f := range findings {
+		if f == nil {
+			continue
+		}
+		records = append(records, RecordFinding{
+			Analyzer:    f.AnalyzerName,
+			Confidence:  f.Confidence,
+			CVSSVector:  f.CVSSVector,
+			Description: f.Description,
+			LineNumber:  f.LineNumber,
+			Metadata:    f.Metadata,
+			Risk:        int(f.Risk),
+			Severity:    f.Severity,
+			Snippet:     f.Snippet,
+		})
+	}
+	return records
+}
+
+/**
+ * @Description: 将规范化记录还原为扫描结果
+ * @author: Mr wpl
+ * @return *types.ScanResult: 扫描结果
+ */
+func (r Record) ToResult() *types.ScanResult {
+	result := &types.ScanResult{
+		File: types.FileInfo{
+			Path:         r.File.Path,
+			RelativePath: r.File.RelativePath,
+			Size:         r.File.Size,
+			MIMEType:     r.File.MIMEType,
+			Encoding:     r.File.Encoding,
+		},
+		OverallRisk:        types.RiskLevel(r.OverallRisk),
+		Findings:           recordFindingsToResult(r.Findings),
+		Duration:           time.Duration(r.Duration),
+		SkippedAST:         r.SkippedAST,
+		PartialAST:         r.PartialAST,
+		ASTTruncatedLines:  r.ASTTruncatedLines,
+		SkippedAnalyzers:   r.SkippedAnalyzers,
+		SuppressedFindings: recordFindingsToResult(r.SuppressedFindings),
+		TruncatedFindings:  r.TruncatedFindings,
+		DeployedBy:         r.DeployedBy,
+		DuplicateOf:        r.DuplicateOf,
+		Incomplete:         r.Incomplete,
+		Cached:             r.Cached,
+		Callable:           r.Callable,
+		ContentMD5:         r.ContentMD5,
+		ContentSHA256:      r.ContentSHA256,
+		Signature:          r.Signature,
+		VT:                 r.VT,
+	}
+	if modTime, err := time.Parse(time.RFC3339Nano, r.File.ModTime); err == nil {
+		result.File.ModTime = modTime
+	}
+	if r.Error != "" {
+		result.Error = errors.New(r.Error)
+	}
+	return result
+}
+
+// recordFindingsToResult 将规范化发现还原为 types.Finding
+func recordFindingsToResult(records []RecordFinding) []*types.Finding {
+	var findings []*types.Finding
+	for _, f := range records {
+		findings = append(findings, &types.Finding{
+			AnalyzerName: f.Analyzer,
+			Description:  f.Description,
+			Risk:         types.RiskLevel(f.Risk),
+			Confidence:   f.Confidence,
+			Metadata:     f.Metadata,
+			Severity:     f.Severity,
+			CVSSVector:   f.CVSSVector,
+			Snippet:      f.Snippet,
+			LineNumber:   f.LineNumber,
+		})
+	}
+	return findings
+}
+
+/**
+ * @Description: 生成扫描结果的规范 JSON (不包含签名字段)
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 扫描结果
+ * @return []byte: 规范 JSON
+ * @return error: 错误
+ */
+func CanonicalJSON(result *types.ScanResult) ([]byte, error) {
+	rec := NewRecord(result)
+	rec.Signature = ""
+	return json.Marshal(rec)
+}
+
+/**
+ * @Description: 使用私钥对扫描结果签名，支持 Ed25519 与 RSA (PSS)
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 扫描结果
+ * @param privateKeyPEM []byte: PEM 格式私钥
+ * @return string: base64 编码的签名
+ * @return error: 错误
+ */
+func Sign(result *types.ScanResult, privateKeyPEM []byte) (string, error) {
+	if result == nil {
+		return "", fmt.Errorf("cannot sign nil result")
+	}
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := CanonicalJSON(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize result: %w", err)
+	}
+	digest := sha256.Sum256(payload)
+
+	var sig []byte
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, digest[:])
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPSS(rand.Reader, k, crypto.SHA256, digest[:], nil)
+		if err != nil {
+			return "", fmt.Errorf("rsa-pss signing failed: %w", err)
+		}
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+/**
+ * @Description: 使用公钥验证扫描结果的签名
+ * @author: Mr wpl
+ * @param result *types.ScanResult: 扫描结果 (Signature 字段需已设置)
+ * @param publicKeyPEM []byte: PEM 格式公钥
+ * @return bool: 签名是否有效
+ * @return error: 错误
+ */
+func Verify(result *types.ScanResult, publicKeyPEM []byte) (bool, error) {
+	if result == nil {
+		return false, fmt.Errorf("cannot verify nil result")
+	}
+	if result.Signature == "" {
+		return false, fmt.Errorf("result has no signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(result.Signature)
+	if err != nil {
+		return false, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	key, err := parsePublicKey(publicKeyPEM)
+	if err != nil {
+		return false, err
+	}
+
+	// CanonicalJSON 会清除签名字段后再序列化
+	payload, err := CanonicalJSON(result)
+	if err != nil {
+		return false, fmt.Errorf("failed to serialize result: %w", err)
+	}
+	digest := sha256.Sum256(payload)
+
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, digest[:], sig), nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil) == nil, nil
+	default:
+		return false, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// parsePrivateKey 解析 PKCS#8 或 PKCS#1 格式的 PEM 私钥
+func parsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in private key")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key format (%s)", block.Type)
+}
+
+// parsePublicKey 解析 PKIX 或 PKCS#1 格式的 PEM 公钥
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in public key")
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key format (%s)", block.Type)
+}
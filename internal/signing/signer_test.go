@@ -0,0 +1,280 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 扫描结果签名与验签测试
+ */
+package signing
+
+import (
+	"bt-shieldml/pkg/types"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testKeys 生成 PEM 格式的 Ed25519 私钥与公钥
+func testKeys(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return marshalKeys(t, priv, pub)
+}
+
+func marshalKeys(t *testing.T, priv interface{}, pub interface{}) ([]byte, []byte) {
+	t.Helper()
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+// sampleResult 构造一个填充了大部分字段的扫描结果
+func sampleResult() *types.ScanResult {
+	return &types.ScanResult{
+		File: types.FileInfo{
+			Path:         "/www/site/shell.php",
+			RelativePath: "site/shell.php",
+			Size:         2048,
+			ModTime:      time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC),
+			MIMEType:     "text/x-php",
+			Encoding:     "UTF-8",
+		},
+		OverallRisk: types.RiskCritical,
+		Findings: []*types.Finding{{
+			AnalyzerName: "regex",
+			Description:  "eval of request data",
+			Risk:         types.RiskCritical,
+			Confidence:   0.95,
+			Metadata:     map[string]interface{}{"snippet_match": []int{3, 7}, "rule": "eval_post"},
+			Severity:     "Critical",
+			CVSSVector:   "AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H",
+			Snippet:      "<?php eval($_POST['x']);",
+			LineNumber:   1,
+		}},
+		Error:              errors.New("partial read"),
+		Duration:           1500 * time.Millisecond,
+		SkippedAnalyzers:   []string{"svm_prosses: critical finding from regex"},
+		SuppressedFindings: []*types.Finding{{AnalyzerName: "statistical", Description: "high entropy", Risk: types.RiskLow, Confidence: 0.2}},
+		DeployedBy:         "composer",
+		DuplicateOf:        "/www/other/shell.php",
+		ContentMD5:         "5d41402abc4b2a76b9719d911017c592",
+		VT:                 "12/70",
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	edPriv, edPub := testKeys(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPriv, rsaPub := marshalKeys(t, rsaKey, &rsaKey.PublicKey)
+
+	for name, keys := range map[string][2][]byte{"ed25519": {edPriv, edPub}, "rsa-pss": {rsaPriv, rsaPub}} {
+		t.Run(name, func(t *testing.T) {
+			res := sampleResult()
+			sig, err := Sign(res, keys[0])
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			res.Signature = sig
+
+			ok, err := Verify(res, keys[1])
+			if err != nil || !ok {
+				t.Fatalf("Verify() = %v, %v, want true", ok, err)
+			}
+		})
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	priv, pub := testKeys(t)
+
+	tests := []struct {
+		name   string
+		tamper func(*types.ScanResult)
+	}{
+		{"overall risk", func(r *types.ScanResult) { r.OverallRisk = types.RiskNone }},
+		{"relative path", func(r *types.ScanResult) { r.File.RelativePath = "other.php" }},
+		{"encoding", func(r *types.ScanResult) { r.File.Encoding = "GBK" }},
+		{"deployed by", func(r *types.ScanResult) { r.DeployedBy = "" }},
+		{"duplicate of", func(r *types.ScanResult) { r.DuplicateOf = "" }},
+		{"vt", func(r *types.ScanResult) { r.VT = "0/70" }},
+		{"suppressed findings", func(r *types.ScanResult) { r.SuppressedFindings = nil }},
+		{"truncated findings", func(r *types.ScanResult) { r.TruncatedFindings = true }},
+		{"incomplete", func(r *types.ScanResult) { r.Incomplete = true }},
+		{"cached", func(r *types.ScanResult) { r.Cached = true }},
+		{"skipped analyzers", func(r *types.ScanResult) { r.SkippedAnalyzers = nil }},
+		{"finding severity", func(r *types.ScanResult) { r.Findings[0].Severity = "Low" }},
+		{"finding cvss vector", func(r *types.ScanResult) { r.Findings[0].CVSSVector = "" }},
+		{"finding snippet", func(r *types.ScanResult) { r.Findings[0].Snippet = "<?php echo 1;" }},
+		{"finding line number", func(r *types.ScanResult) { r.Findings[0].LineNumber = 42 }},
+		{"finding metadata", func(r *types.ScanResult) { r.Findings[0].Metadata["rule"] = "benign" }},
+		{"error", func(r *types.ScanResult) { r.Error = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := sampleResult()
+			sig, err := Sign(res, priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Signature = sig
+
+			tt.tamper(res)
+			ok, err := Verify(res, pub)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if ok {
+				t.Errorf("Verify() = true after changing %s, want false", tt.name)
+			}
+		})
+	}
+}
+
+// TestVerifyAfterReportRoundTrip 模拟 verify-report：签名结果写入 JSON 报告后读回仍可验签
+func TestVerifyAfterReportRoundTrip(t *testing.T) {
+	priv, pub := testKeys(t)
+	res := sampleResult()
+	sig, err := Sign(res, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Signature = sig
+
+	data, err := json.Marshal([]Record{NewRecord(res)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := Verify(records[0].ToResult(), pub)
+	if err != nil || !ok {
+		t.Fatalf("Verify() after round trip = %v, %v, want true", ok, err)
+	}
+}
+
+// TestCanonicalJSONSortedKeys 规范 JSON 的键必须有序：经 map 重新序列化 (encoding/json 对 map 键排序) 后应完全一致
+func TestCanonicalJSONSortedKeys(t *testing.T) {
+	payload, err := CanonicalJSON(sampleResult())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var generic map[string]interface{}
+	if err := json.Unmarshal(payload, &generic); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := generic["signature"]; ok {
+		t.Errorf("canonical JSON must not contain the signature")
+	}
+	resorted, err := json.Marshal(generic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resorted) != string(payload) {
+		t.Errorf("canonical JSON keys are not sorted:\n got  %s\n want %s", payload, resorted)
+	}
+}
+
+// fillValue 将 v 设置为非零值：字符串、数字、布尔、切片 (一个元素)、指针、map 与结构体字段递归填充，
+// time.Time 与 error 使用固定值
+func fillValue(t *testing.T, v reflect.Value, path string) {
+	t.Helper()
+	switch v.Type() {
+	case reflect.TypeOf(time.Time{}):
+		v.Set(reflect.ValueOf(time.Date(2026, 10, 17, 8, 30, 0, 123, time.UTC)))
+		return
+	case reflect.TypeOf((*error)(nil)).Elem():
+		v.Set(reflect.ValueOf(errors.New(path)))
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(path)
+	case reflect.Int, reflect.Int64:
+		v.SetInt(int64(len(path)))
+	case reflect.Float64:
+		v.SetFloat(0.5)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillValue(t, v.Index(0), path+"[0]")
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillValue(t, v.Elem(), path)
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		elem := reflect.New(v.Type().Elem()).Elem()
+		fillValue(t, elem, path+".value")
+		v.SetMapIndex(reflect.ValueOf(path+".key"), elem)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(path))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillValue(t, v.Field(i), path+"."+v.Type().Field(i).Name)
+		}
+	default:
+		t.Fatalf("fillValue: unsupported kind %s at %s, extend the test", v.Kind(), path)
+	}
+}
+
+// TestRecordCoversScanResult ScanResult 的每个字段 (含 FileInfo 与 Finding 的字段) 都填充非零值后，
+// 经 NewRecord 与 ToResult 往返应保持不变。ScanResult 新增字段而未加入 Record 时本测试失败
+func TestRecordCoversScanResult(t *testing.T) {
+	want := &types.ScanResult{}
+	fillValue(t, reflect.ValueOf(want).Elem(), "ScanResult")
+	got := NewRecord(want).ToResult()
+
+	gotValue, wantValue := reflect.ValueOf(got).Elem(), reflect.ValueOf(want).Elem()
+	for i := 0; i < wantValue.NumField(); i++ {
+		name := wantValue.Type().Field(i).Name
+		if !reflect.DeepEqual(gotValue.Field(i).Interface(), wantValue.Field(i).Interface()) {
+			t.Errorf("ScanResult.%s does not round-trip through Record: got %#v, want %#v", name, gotValue.Field(i).Interface(), wantValue.Field(i).Interface())
+		}
+	}
+
+	// 每个字段都进入规范 JSON，清空任一字段都会使签名失效
+	payload, err := CanonicalJSON(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < wantValue.NumField(); i++ {
+		name := wantValue.Type().Field(i).Name
+		if name == "Signature" {
+			continue
+		}
+		changed := &types.ScanResult{}
+		fillValue(t, reflect.ValueOf(changed).Elem(), "ScanResult")
+		field := reflect.ValueOf(changed).Elem().Field(i)
+		field.Set(reflect.Zero(field.Type()))
+		other, err := CanonicalJSON(changed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(other) == string(payload) {
+			t.Errorf("clearing ScanResult.%s does not change the canonical JSON", name)
+		}
+	}
+}
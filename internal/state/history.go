@@ -0,0 +1,339 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 扫描历史库 (SQLite)：记录每次扫描各文件的风险等级与内容哈希，用于查找首次感染时间与风险上升的文件
+ */
+package state
+
+import (
+	"bt-shieldml/pkg/types"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// historyTimeFormat scan_time 的存储格式 (UTC)，定长以便按字符串比较先后
+const historyTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// historyMigrations 依次将历史库升级到版本 1、2…，当前版本记录在 PRAGMA user_version 中。
+// 只能在末尾追加新的迁移，已发布的迁移不能修改
+var historyMigrations = []string{
+	// 版本 1：扫描记录与各文件结果
+	`CREATE TABLE scans (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		scan_time      TEXT    NOT NULL,
+		scan_root      TEXT    NOT NULL,
+		total_files    INTEGER NOT NULL,
+		critical_count INTEGER NOT NULL,
+		high_count     INTEGER NOT NULL
+	);
+	CREATE TABLE file_results (
+		scan_id     INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+		path        TEXT    NOT NULL,
+		sha256      TEXT,
+		risk_level  INTEGER NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		PRIMARY KEY (scan_id, path)
+	);
+	CREATE INDEX file_results_path ON file_results(path);
+	CREATE INDEX scans_root_time ON scans(scan_root, scan_time);`,
+}
+
+/**
+ * @Description: 扫描历史库
+ * @author: Mr wpl
+ */
+type HistoryDB struct {
+	db *sql.DB
+}
+
+/**
+ * @Description: 打开 (或创建) 扫描历史库，并将表结构升级到当前版本
+ * @author: Mr wpl
+ * @param path string: 数据库路径
+ * @return *HistoryDB: 历史库
+ * @return error: 无法打开、升级失败或库的版本高于本程序支持的版本时返回错误
+ */
+func OpenHistory(path string) (*HistoryDB, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create history directory %s: %w", dir, err)
+		}
+	}
+	// 定时扫描与 -history-report 可能同时访问，等待其他连接释放写锁
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan history %s: %w", path, err)
+	}
+	h := &HistoryDB{db: db}
+	if err := h.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to upgrade scan history %s: %w", path, err)
+	}
+	return h, nil
+}
+
+// migrate 依次执行尚未应用的迁移，每个迁移与版本号更新在同一事务中
+func (h *HistoryDB) migrate() error {
+	var version int
+	if err := h.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(historyMigrations) {
+		return fmt.Errorf("schema version %d is newer than the supported version %d", version, len(historyMigrations))
+	}
+	for ; version < len(historyMigrations); version++ {
+		tx, err := h.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(historyMigrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration to version %d: %w", version+1, err)
+		}
+		// PRAGMA 不支持参数占位符
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 关闭历史库
+func (h *HistoryDB) Close() error {
+	return h.db.Close()
+}
+
+/**
+ * @Description: 记录一次扫描。扫描出错或未完成的文件不记录；内容哈希使用扫描时记录的 ContentSHA256，
+ * 未读取内容 (空文件、按大小跳过等) 时 sha256 为空
+ * @author: Mr wpl
+ * @param scanTime time.Time: 扫描开始时间
+ * @param scanRoot string: 扫描根目录
+ * @param results []*types.ScanResult: 扫描结果
+ * @return int64: 扫描记录 ID
+ * @return error: 错误
+ */
+func (h *HistoryDB) RecordScan(scanTime time.Time, scanRoot string, results []*types.ScanResult) (int64, error) {
+	var total, critical, high int
+	for _, res := range results {
+		if res.Error != nil || res.Incomplete {
+			continue
+		}
+		total++
+		switch res.OverallRisk {
+		case types.RiskCritical:
+			critical++
+		case types.RiskHigh:
+			high++
+		}
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record scan history: %w", err)
+	}
+	defer tx.Rollback()
+
+	scanRes, err := tx.Exec(`INSERT INTO scans (scan_time, scan_root, total_files, critical_count, high_count) VALUES (?, ?, ?, ?, ?)`,
+		scanTime.UTC().Format(historyTimeFormat), scanRoot, total, critical, high)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record scan history: %w", err)
+	}
+	scanID, err := scanRes.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to record scan history: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO file_results (scan_id, path, sha256, risk_level, duration_ms) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record scan history: %w", err)
+	}
+	defer stmt.Close()
+	for _, res := range results {
+		if res.Error != nil || res.Incomplete {
+			continue
+		}
+		sum := sql.NullString{String: res.ContentSHA256, Valid: res.ContentSHA256 != ""}
+		if _, err := stmt.Exec(scanID, res.File.Path, sum, int(res.OverallRisk), res.Duration.Milliseconds()); err != nil {
+			return 0, fmt.Errorf("failed to record scan history for %s: %w", res.File.Path, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to record scan history: %w", err)
+	}
+	return scanID, nil
+}
+
+// HistoryScan 历史库中的一次扫描
+type HistoryScan struct {
+	ID            int64     `json:"id"`
+	ScanTime      time.Time `json:"scan_time"`
+	ScanRoot      string    `json:"scan_root"`
+	TotalFiles    int       `json:"total_files"`
+	CriticalCount int       `json:"critical_count"`
+	HighCount     int       `json:"high_count"`
+}
+
+// RiskIncrease 风险等级高于上次扫描的文件
+type RiskIncrease struct {
+	Path           string `json:"path"`
+	PreviousRisk   string `json:"previous_risk"` // 上次扫描中不存在的文件为 "Safe"
+	CurrentRisk    string `json:"current_risk"`
+	NewFile        bool   `json:"new_file,omitempty"` // 上次扫描中不存在
+	PreviousSHA256 string `json:"previous_sha256,omitempty"`
+	SHA256         string `json:"sha256,omitempty"`
+	// FirstSeenAtRisk 该路径在历史库中首次达到当前风险等级的扫描时间，即最早可能的感染时间
+	FirstSeenAtRisk time.Time `json:"first_seen_at_risk"`
+
+	level types.RiskLevel // 当前风险等级，用于排序
+}
+
+// RiskIncreaseReport -history-report 的输出
+type RiskIncreaseReport struct {
+	Current   *HistoryScan   `json:"current_scan"`
+	Previous  *HistoryScan   `json:"previous_scan"` // 同一扫描根目录只有一次扫描时为 null
+	Increased []RiskIncrease `json:"increased"`
+}
+
+/**
+ * @Description: 比较最近一次扫描与同一扫描根目录的上一次扫描，列出风险等级上升的文件 (新出现且有风险的文件视为从 Safe 上升)
+ * @author: Mr wpl
+ * @return *RiskIncreaseReport: 报告，没有上一次扫描时 Previous 为 nil 且 Increased 为空
+ * @return error: 历史库为空或查询失败时返回错误
+ */
+func (h *HistoryDB) RiskIncreaseReport() (*RiskIncreaseReport, error) {
+	current, err := h.scanBefore("", "")
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, errors.New("scan history is empty; enable history in the config and run a scan first")
+	}
+	report := &RiskIncreaseReport{Current: current, Increased: []RiskIncrease{}}
+	report.Previous, err = h.scanBefore(current.ScanRoot, current.ScanTime.UTC().Format(historyTimeFormat))
+	if err != nil || report.Previous == nil {
+		return report, err
+	}
+
+	previous, err := h.fileRisks(report.Previous.ID)
+	if err != nil {
+		return nil, err
+	}
+	currentRisks, err := h.fileRisks(current.ID)
+	if err != nil {
+		return nil, err
+	}
+	for path, cur := range currentRisks {
+		prev, existed := previous[path]
+		prevRisk := types.RiskNone
+		if existed {
+			prevRisk = prev.risk
+		}
+		if cur.risk <= prevRisk {
+			continue
+		}
+		report.Increased = append(report.Increased, RiskIncrease{
+			Path:           path,
+			PreviousRisk:   prevRisk.String(),
+			CurrentRisk:    cur.risk.String(),
+			NewFile:        !existed,
+			PreviousSHA256: prev.sha256,
+			SHA256:         cur.sha256,
+			level:          cur.risk,
+		})
+	}
+
+	for i := range report.Increased {
+		inc := &report.Increased[i]
+		if inc.FirstSeenAtRisk, err = h.firstSeenAtRisk(inc.Path, inc.level); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(report.Increased, func(i, j int) bool {
+		if report.Increased[i].level != report.Increased[j].level {
+			return report.Increased[i].level > report.Increased[j].level
+		}
+		return report.Increased[i].Path < report.Increased[j].Path
+	})
+	return report, nil
+}
+
+// scanBefore 返回 scan_time 早于 before 的最近一次扫描，root 非空时只查找该扫描根目录；before 为空时不限时间。没有时返回 nil
+func (h *HistoryDB) scanBefore(root string, before string) (*HistoryScan, error) {
+	query := `SELECT id, scan_time, scan_root, total_files, critical_count, high_count FROM scans`
+	var args []interface{}
+	if before != "" {
+		query += ` WHERE scan_root = ? AND scan_time < ?`
+		args = append(args, root, before)
+	}
+	query += ` ORDER BY scan_time DESC, id DESC LIMIT 1`
+
+	var scan HistoryScan
+	var scanTime string
+	err := h.db.QueryRow(query, args...).Scan(&scan.ID, &scanTime, &scan.ScanRoot, &scan.TotalFiles, &scan.CriticalCount, &scan.HighCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scan history: %w", err)
+	}
+	if scan.ScanTime, err = time.Parse(historyTimeFormat, scanTime); err != nil {
+		return nil, fmt.Errorf("invalid scan_time %q in scan history: %w", scanTime, err)
+	}
+	return &scan, nil
+}
+
+// fileRisk 一次扫描中单个文件的风险等级与内容哈希
+type fileRisk struct {
+	risk   types.RiskLevel
+	sha256 string
+}
+
+// fileRisks 返回一次扫描中各文件的风险等级与内容哈希
+func (h *HistoryDB) fileRisks(scanID int64) (map[string]fileRisk, error) {
+	rows, err := h.db.Query(`SELECT path, COALESCE(sha256, ''), risk_level FROM file_results WHERE scan_id = ?`, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scan history: %w", err)
+	}
+	defer rows.Close()
+	risks := make(map[string]fileRisk)
+	for rows.Next() {
+		var path string
+		var fr fileRisk
+		if err := rows.Scan(&path, &fr.sha256, &fr.risk); err != nil {
+			return nil, fmt.Errorf("failed to query scan history: %w", err)
+		}
+		risks[path] = fr
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query scan history: %w", err)
+	}
+	return risks, nil
+}
+
+// firstSeenAtRisk 返回该路径最近一段连续达到 level 及以上风险的起始扫描时间
+func (h *HistoryDB) firstSeenAtRisk(path string, level types.RiskLevel) (time.Time, error) {
+	// 最近一次低于 level 的扫描之后、首次达到 level 的扫描
+	var scanTime string
+	err := h.db.QueryRow(`
+		SELECT s.scan_time FROM file_results f JOIN scans s ON s.id = f.scan_id
+		WHERE f.path = ? AND f.risk_level >= ? AND s.scan_time > COALESCE((
+			SELECT MAX(s2.scan_time) FROM file_results f2 JOIN scans s2 ON s2.id = f2.scan_id
+			WHERE f2.path = ? AND f2.risk_level < ?), '')
+		ORDER BY s.scan_time ASC LIMIT 1`, path, int(level), path, int(level)).Scan(&scanTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to query scan history for %s: %w", path, err)
+	}
+	return time.Parse(historyTimeFormat, scanTime)
+}
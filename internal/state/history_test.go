@@ -0,0 +1,67 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 扫描历史库测试：文件的 sha256 来自扫描时记录的 ContentSHA256，不重新读取磁盘
+ */
+package state
+
+import (
+	"bt-shieldml/pkg/types"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordScanUsesScanTimeHash(t *testing.T) {
+	h, err := OpenHistory(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	defer h.Close()
+
+	results := []*types.ScanResult{
+		// 归档成员的虚拟路径与已删除的文件在磁盘上都不存在
+		{File: types.FileInfo{Path: "/www/upload.zip/shell.php"}, OverallRisk: types.RiskCritical, ContentSHA256: "aaaa"},
+		{File: types.FileInfo{Path: "/www/deleted.php"}, OverallRisk: types.RiskNone, ContentSHA256: "bbbb"},
+		{File: types.FileInfo{Path: "/www/empty.php"}, OverallRisk: types.RiskNone},
+		{File: types.FileInfo{Path: "/www/unreadable.php"}, Error: errors.New("permission denied"), ContentSHA256: "cccc"},
+	}
+	scanID, err := h.RecordScan(time.Now(), "/www", results)
+	if err != nil {
+		t.Fatalf("RecordScan() error = %v", err)
+	}
+
+	rows, err := h.db.Query(`SELECT path, sha256 FROM file_results WHERE scan_id = ?`, scanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	got := make(map[string]sql.NullString)
+	for rows.Next() {
+		var path string
+		var sum sql.NullString
+		if err := rows.Scan(&path, &sum); err != nil {
+			t.Fatal(err)
+		}
+		got[path] = sum
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]sql.NullString{
+		"/www/upload.zip/shell.php": {String: "aaaa", Valid: true},
+		"/www/deleted.php":          {String: "bbbb", Valid: true},
+		"/www/empty.php":            {},
+	}
+	if len(got) != len(want) {
+		t.Errorf("recorded %d files %v, want %d (scan errors are skipped)", len(got), got, len(want))
+	}
+	for path, sum := range want {
+		if got[path] != sum {
+			t.Errorf("sha256 of %s = %+v, want %+v", path, got[path], sum)
+		}
+	}
+}
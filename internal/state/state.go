@@ -0,0 +1,244 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 增量扫描状态库 (BoltDB)：记录每个文件的修改时间、大小、SHA-256 与上次扫描结果，未变化的文件直接复用结果
+ */
+package state
+
+import (
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var filesBucket = []byte("files")
+
+// Record 一个文件的扫描状态，键为文件绝对路径
+type Record struct {
+	ModTime   time.Time         `json:"mod_time"`
+	Size      int64             `json:"size"`
+	SHA256    string            `json:"sha256"`
+	LastRisk  types.RiskLevel   `json:"last_risk"`
+	Analyzers string            `json:"analyzers"` // 产生该结果时启用的分析器，与当前配置不同时记录失效
+	Result    *types.ScanResult `json:"result"`
+}
+
+/**
+ * @Description: 扫描状态库。Lookup 可并发调用；Save 先写入内存，Flush (或 Close) 时在一个事务内落盘
+ * @author: Mr wpl
+ */
+type Store struct {
+	db        *bolt.DB
+	analyzers string
+	mu        sync.Mutex
+	pending   map[string]*Record // 尚未落盘的记录，只能在持有 mu 时读写
+}
+
+/**
+ * @Description: 打开 (或创建) 状态库。文件损坏 (不是有效的 BoltDB) 时改名为 <path>.corrupt 后重建，
+ * 所有文件在本次扫描中重新扫描；被其他进程占用时返回错误
+ * @author: Mr wpl
+ * @param path string: 数据库路径
+ * @param analyzers []string: 当前启用的分析器，变化后已有记录不再复用
+ * @return *Store: 状态库
+ * @return error: 错误
+ */
+func Open(path string, analyzers []string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create state directory %s: %w", dir, err)
+		}
+	}
+
+	db, err := openDB(path)
+	if err != nil && !errors.Is(err, bolt.ErrTimeout) {
+		corruptPath := path + ".corrupt"
+		logging.WarnLogger.Printf("Scan state %s is unreadable (%v); moving it to %s and starting a full scan", path, err, corruptPath)
+		if renameErr := os.Rename(path, corruptPath); renameErr != nil {
+			return nil, fmt.Errorf("failed to move corrupt scan state %s: %w", path, renameErr)
+		}
+		db, err = openDB(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan state %s: %w", path, err)
+	}
+
+	names := append([]string(nil), analyzers...)
+	sort.Strings(names)
+	return &Store{
+		db:        db,
+		analyzers: strings.Join(names, ","),
+		pending:   make(map[string]*Record),
+	}, nil
+}
+
+// openDB 打开数据库并创建 files 桶
+func openDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(filesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+/**
+ * @Description: 删除状态库，下次 -incremental 扫描时所有文件重新扫描
+ * @author: Mr wpl
+ * @param path string: 数据库路径
+ * @return error: 错误，文件不存在时返回 nil
+ */
+func Reset(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reset scan state %s: %w", path, err)
+	}
+	return nil
+}
+
+/**
+ * @Description: 查找可复用的结果。修改时间与大小都与记录一致时命中；仅修改时间变化 (如重新部署、touch) 时
+ * 比较 SHA-256，内容未变同样命中。记录损坏或分析器配置已变化时视为未命中
+ * @author: Mr wpl
+ * @param path string: 文件绝对路径
+ * @param info os.FileInfo: 文件当前的状态
+ * @return *types.ScanResult: 复用的结果副本 (Cached 为 true)
+ * @return bool: 是否命中
+ */
+func (s *Store) Lookup(path string, info os.FileInfo) (*types.ScanResult, bool) {
+	var record *Record
+	s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(filesBucket).Get([]byte(path))
+		if data == nil {
+			return nil
+		}
+		var r Record
+		if err := json.Unmarshal(data, &r); err != nil || r.Result == nil {
+			return nil // 损坏的记录视为未命中，扫描后会被覆盖
+		}
+		record = &r
+		return nil
+	})
+	if record == nil || record.Analyzers != s.analyzers || record.Size != info.Size() {
+		return nil, false
+	}
+	if !record.ModTime.Equal(info.ModTime()) {
+		sum, err := hashFile(path)
+		if err != nil || sum != record.SHA256 {
+			return nil, false
+		}
+		// 内容未变，更新修改时间，下次无需再计算哈希
+		record.ModTime = info.ModTime()
+		s.mu.Lock()
+		s.pending[path] = record
+		s.mu.Unlock()
+	}
+
+	result := *record.Result
+	result.File.Path = path
+	result.File.RelativePath = ""
+	result.File.ModTime = info.ModTime()
+	result.Duration = 0
+	result.Signature = ""
+	result.DuplicateOf = ""
+	result.Cached = true
+	return &result, true
+}
+
+/**
+ * @Description: 记录一个文件的扫描结果，出错或未完成的结果不记录。内容哈希使用扫描时记录的 ContentSHA256，
+ * 不重新读取文件：扫描后文件被修改时，下次 Lookup 比较哈希不一致而重新扫描
+ * @author: Mr wpl
+ * @param path string: 文件绝对路径
+ * @param info os.FileInfo: 扫描前的文件状态
+ * @param result *types.ScanResult: 扫描结果
+ */
+func (s *Store) Save(path string, info os.FileInfo, result *types.ScanResult) {
+	if result == nil || result.Error != nil || result.Incomplete {
+		return
+	}
+	stored := *result
+	stored.Cached = false
+	s.mu.Lock()
+	s.pending[path] = &Record{
+		ModTime:   info.ModTime(),
+		Size:      info.Size(),
+		SHA256:    result.ContentSHA256,
+		LastRisk:  result.OverallRisk,
+		Analyzers: s.analyzers,
+		Result:    &stored,
+	}
+	s.mu.Unlock()
+}
+
+/**
+ * @Description: 在一个事务内写入所有待保存的记录
+ * @author: Mr wpl
+ * @return error: 错误
+ */
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[string]*Record)
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(filesBucket)
+		for path, record := range pending {
+			data, err := json.Marshal(record)
+			if err != nil {
+				logging.WarnLogger.Printf("Failed to encode scan state for %s: %v", path, err)
+				continue
+			}
+			if err := bucket.Put([]byte(path), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close 写入待保存的记录并关闭数据库
+func (s *Store) Close() error {
+	flushErr := s.Flush()
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+	return flushErr
+}
+
+// hashFile 计算文件内容的 SHA-256 (hex)
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
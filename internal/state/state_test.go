@@ -0,0 +1,313 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 增量扫描状态库测试：命中/未命中判定、跨进程持久化与损坏的状态库恢复
+ */
+package state
+
+import (
+	"bt-shieldml/pkg/types"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var stateTestAnalyzers = []string{"regex", "yara"}
+
+// writeStateFile 写入文件并设置修改时间，返回其状态
+func writeStateFile(t *testing.T, path, content string, modTime time.Time) os.FileInfo {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+// stateTestResult 扫描内容为 content 的文件得到的带发现的结果
+func stateTestResult(path, content string) *types.ScanResult {
+	sum := sha256.Sum256([]byte(content))
+	return &types.ScanResult{
+		File:          types.FileInfo{Path: path, RelativePath: "shell.php"},
+		OverallRisk:   types.RiskHigh,
+		Findings:      []*types.Finding{{AnalyzerName: "regex", Description: "eval($_POST)", Risk: types.RiskHigh}},
+		Duration:      time.Second,
+		ContentSHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+func TestStoreLookup(t *testing.T) {
+	modTime := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		content   string // 保存后文件的新内容
+		modTime   time.Time
+		analyzers []string
+		wantHit   bool
+	}{
+		{"unchanged", "<?php eval($_POST['a']);", modTime, stateTestAnalyzers, true},
+		{"touched with same content", "<?php eval($_POST['a']);", modTime.Add(time.Hour), stateTestAnalyzers, true},
+		{"size changed", "<?php eval($_POST['a']); // x", modTime, stateTestAnalyzers, false},
+		{"content changed with same size", "<?php eval($_POST['b']);", modTime.Add(time.Hour), stateTestAnalyzers, false},
+		{"analyzers changed", "<?php eval($_POST['a']);", modTime, []string{"regex"}, false},
+		{"analyzers reordered", "<?php eval($_POST['a']);", modTime, []string{"yara", "regex"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dbPath := filepath.Join(dir, "state.db")
+			path := filepath.Join(dir, "shell.php")
+
+			s, err := Open(dbPath, stateTestAnalyzers)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			s.Save(path, writeStateFile(t, path, "<?php eval($_POST['a']);", modTime), stateTestResult(path, "<?php eval($_POST['a']);"))
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			info := writeStateFile(t, path, tt.content, tt.modTime)
+			s, err = Open(dbPath, tt.analyzers)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer s.Close()
+
+			got, hit := s.Lookup(path, info)
+			if hit != tt.wantHit {
+				t.Fatalf("Lookup() hit = %v, want %v", hit, tt.wantHit)
+			}
+			if !hit {
+				return
+			}
+			if !got.Cached || got.OverallRisk != types.RiskHigh || len(got.Findings) != 1 {
+				t.Errorf("Lookup() = %+v, want the cached High result", got)
+			}
+			if got.Duration != 0 || got.File.RelativePath != "" || !got.File.ModTime.Equal(tt.modTime) {
+				t.Errorf("Lookup() did not reset per-scan fields: %+v", got.File)
+			}
+		})
+	}
+}
+
+func TestStoreLookupMissing(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(filepath.Join(dir, "state.db"), stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	path := filepath.Join(dir, "new.php")
+	if _, hit := s.Lookup(path, writeStateFile(t, path, "<?php", time.Now())); hit {
+		t.Error("Lookup() hit for a file that was never saved")
+	}
+}
+
+// TestStoreTouchedFileUpdatesModTime 内容未变而修改时间变化时，命中后记录新的修改时间，下次不再计算哈希
+func TestStoreTouchedFileUpdatesModTime(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "state.db")
+	path := filepath.Join(dir, "index.php")
+	modTime := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := Open(dbPath, stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Save(path, writeStateFile(t, path, "<?php echo 1;", modTime), stateTestResult(path, "<?php echo 1;"))
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	touched := writeStateFile(t, path, "<?php echo 1;", modTime.Add(time.Hour))
+	if _, hit := s.Lookup(path, touched); !hit {
+		t.Fatal("Lookup() missed a touched file with unchanged content")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	db, err := bolt.Open(dbPath, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var stored []byte
+	db.View(func(tx *bolt.Tx) error {
+		stored = append(stored, tx.Bucket(filesBucket).Get([]byte(path))...)
+		return nil
+	})
+	if len(stored) == 0 {
+		t.Fatal("record was not flushed")
+	}
+	var record Record
+	if err := json.Unmarshal(stored, &record); err != nil {
+		t.Fatal(err)
+	}
+	if !record.ModTime.Equal(touched.ModTime()) {
+		t.Errorf("stored ModTime = %v, want %v", record.ModTime, touched.ModTime())
+	}
+}
+
+// TestStoreSaveUsesScanTimeHash 扫描后、保存前文件被替换 (大小相同) 时，记录的是扫描内容的哈希，下次扫描不复用旧结果
+func TestStoreSaveUsesScanTimeHash(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.php")
+	modTime := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	s, err := Open(filepath.Join(dir, "state.db"), stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	info := writeStateFile(t, path, "<?php echo 1;", modTime)
+	result := stateTestResult(path, "<?php echo 1;")
+	replaced := writeStateFile(t, path, "<?php echo 2;", modTime.Add(time.Minute))
+	s.Save(path, info, result)
+	if record := s.pending[path]; record == nil || record.SHA256 != result.ContentSHA256 {
+		t.Errorf("saved record = %+v, want the scan-time SHA-256 %s", record, result.ContentSHA256)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if _, hit := s.Lookup(path, replaced); hit {
+		t.Error("Lookup() reused the result of the content that was scanned for a file replaced after the scan")
+	}
+	// 内容恢复为扫描时的内容后可以复用
+	if _, hit := s.Lookup(path, writeStateFile(t, path, "<?php echo 1;", modTime.Add(time.Hour))); !hit {
+		t.Error("Lookup() missed a file whose content matches the scan-time hash")
+	}
+}
+
+func TestStoreSaveSkipsIncompleteResults(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(filepath.Join(dir, "state.db"), stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		name   string
+		result *types.ScanResult
+	}{
+		{"nil result", nil},
+		{"scan error", &types.ScanResult{Error: errors.New("read failed")}},
+		{"incomplete", &types.ScanResult{OverallRisk: types.RiskLow, Incomplete: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".php")
+			info := writeStateFile(t, path, "<?php", time.Now())
+			s.Save(path, info, tt.result)
+			if _, hit := s.Lookup(path, info); hit {
+				t.Error("Lookup() hit for a result that must not be cached")
+			}
+			if len(s.pending) != 0 {
+				t.Errorf("Save() queued %d records, want 0", len(s.pending))
+			}
+		})
+	}
+}
+
+// TestOpenCorruptState 损坏的状态库被改名为 .corrupt 并重建，所有文件重新扫描
+func TestOpenCorruptState(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "state.db")
+	garbage := []byte("this is not a bolt database, just some leftover bytes")
+	if err := os.WriteFile(dbPath, garbage, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(dbPath, stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() error = %v, want recovery from a corrupt state file", err)
+	}
+	path := filepath.Join(dir, "index.php")
+	info := writeStateFile(t, path, "<?php echo 1;", time.Now())
+	if _, hit := s.Lookup(path, info); hit {
+		t.Error("Lookup() hit on a rebuilt state database")
+	}
+	s.Save(path, info, stateTestResult(path, "<?php echo 1;"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if moved, err := os.ReadFile(dbPath + ".corrupt"); err != nil || string(moved) != string(garbage) {
+		t.Errorf("corrupt state was not preserved at %s.corrupt (err = %v)", dbPath, err)
+	}
+	s, err = Open(dbPath, stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() after recovery error = %v", err)
+	}
+	defer s.Close()
+	if _, hit := s.Lookup(path, info); !hit {
+		t.Error("Lookup() missed a result saved after recovery")
+	}
+}
+
+// TestLookupCorruptRecord 无法解码的单条记录视为未命中，重新扫描后被覆盖
+func TestLookupCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "state.db")
+	path := filepath.Join(dir, "index.php")
+	info := writeStateFile(t, path, "<?php echo 1;", time.Now())
+
+	s, err := Open(dbPath, stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+	for _, data := range []string{"{not json", `{"size": 13, "result": null}`} {
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(filesBucket).Put([]byte(path), []byte(data))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, hit := s.Lookup(path, info); hit {
+			t.Errorf("Lookup() hit for corrupt record %q", data)
+		}
+	}
+
+	s.Save(path, info, stateTestResult(path, "<?php echo 1;"))
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if _, hit := s.Lookup(path, info); !hit {
+		t.Error("Lookup() missed after the corrupt record was overwritten")
+	}
+}
+
+func TestReset(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "state.db")
+	if err := Reset(dbPath); err != nil {
+		t.Errorf("Reset() on a missing file error = %v", err)
+	}
+	s, err := Open(dbPath, stateTestAnalyzers)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Close()
+	if err := Reset(dbPath); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("state file still exists after Reset(): %v", err)
+	}
+}
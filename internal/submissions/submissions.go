@@ -0,0 +1,264 @@
+/*
+ * @Date: 2025-06-16 10:08:51
+ * @Editors: Mr wpl
+ * @Description: 运维人员提交的新 webshell 规则 (data/submissions.jsonl) 及审核决定 (data/submission_decisions.jsonl)
+ */
+package submissions
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hillu/go-yara/v4"
+)
+
+const (
+	// DefaultPath 默认提交记录文件
+	DefaultPath = "data/submissions.jsonl"
+	// DefaultDecisionPath 默认审核决定文件，提交记录只追加不修改，没有决定的提交为待审核
+	DefaultDecisionPath = "data/submission_decisions.jsonl"
+	// MaxPatternLen 规则最大长度
+	MaxPatternLen = 64 << 10
+)
+
+// 规则类型
+const (
+	TypeRegex = "regex"
+	TypeYara  = "yara"
+)
+
+// 审核决定
+const (
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+// Submission 一条规则提交
+type Submission struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Pattern     string    `json:"pattern"`
+	Description string    `json:"description"`
+	Submitter   string    `json:"submitter"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Decision 一条审核决定
+type Decision struct {
+	ID        string    `json:"id"` // 对应 Submission.ID
+	Decision  string    `json:"decision"`
+	Reviewer  string    `json:"reviewer"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+var appendLock sync.Mutex
+
+/**
+ * @Description: 校验提交内容 (不编译规则)
+ * @author: Mr wpl
+ * @return error: 类型非法、规则为空或过长时返回错误
+ */
+func (s Submission) Validate() error {
+	if s.Type != TypeRegex && s.Type != TypeYara {
+		return fmt.Errorf("type must be %q or %q", TypeRegex, TypeYara)
+	}
+	if strings.TrimSpace(s.Pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	if len(s.Pattern) > MaxPatternLen {
+		return fmt.Errorf("pattern exceeds %d bytes", MaxPatternLen)
+	}
+	if s.Type == TypeRegex && strings.ContainsAny(s.Pattern, "\r\n") {
+		return fmt.Errorf("regex pattern must be a single line")
+	}
+	return nil
+}
+
+/**
+ * @Description: 试编译规则：regex 使用 regexp.Compile，yara 使用 yara 编译器
+ * @author: Mr wpl
+ * @param ruleType string: 规则类型
+ * @param pattern string: 规则内容
+ * @return error: 编译错误
+ */
+func TestCompile(ruleType string, pattern string) error {
+	switch ruleType {
+	case TypeRegex:
+		_, err := regexp.Compile(pattern)
+		return err
+	case TypeYara:
+		compiler, err := yara.NewCompiler()
+		if err != nil {
+			return fmt.Errorf("failed to create yara compiler: %w", err)
+		}
+		defer compiler.Destroy()
+		return compiler.AddString(pattern, "submission")
+	}
+	return fmt.Errorf("unknown rule type %q", ruleType)
+}
+
+/**
+ * @Description: 生成提交 ID (时间戳 + 随机数)
+ * @author: Mr wpl
+ * @param now time.Time: 当前时间
+ * @return string: ID
+ */
+func NewID(now time.Time) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return now.UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+/**
+ * @Description: 追加一条提交
+ * @author: Mr wpl
+ * @param path string: 提交记录文件
+ * @param s Submission: 提交
+ * @return error: 错误
+ */
+func Append(path string, s Submission) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	return appendLine(path, s)
+}
+
+/**
+ * @Description: 追加一条审核决定
+ * @author: Mr wpl
+ * @param path string: 审核决定文件
+ * @param d Decision: 审核决定
+ * @return error: 错误
+ */
+func AppendDecision(path string, d Decision) error {
+	if d.Decision != DecisionApproved && d.Decision != DecisionRejected {
+		return fmt.Errorf("decision must be %q or %q", DecisionApproved, DecisionRejected)
+	}
+	return appendLine(path, d)
+}
+
+/**
+ * @Description: 读取全部提交，文件不存在时返回空列表
+ * @author: Mr wpl
+ * @param path string: 提交记录文件
+ * @return []Submission: 提交
+ * @return error: 错误 (含出错行号)
+ */
+func ReadAll(path string) ([]Submission, error) {
+	var out []Submission
+	err := readLines(path, func(lineNo int, line []byte) error {
+		var s Submission
+		if err := json.Unmarshal(line, &s); err != nil {
+			return fmt.Errorf("invalid submission at line %d: %w", lineNo, err)
+		}
+		out = append(out, s)
+		return nil
+	})
+	return out, err
+}
+
+/**
+ * @Description: 读取全部审核决定，文件不存在时返回空列表
+ * @author: Mr wpl
+ * @param path string: 审核决定文件
+ * @return []Decision: 审核决定
+ * @return error: 错误 (含出错行号)
+ */
+func ReadDecisions(path string) ([]Decision, error) {
+	var out []Decision
+	err := readLines(path, func(lineNo int, line []byte) error {
+		var d Decision
+		if err := json.Unmarshal(line, &d); err != nil {
+			return fmt.Errorf("invalid decision at line %d: %w", lineNo, err)
+		}
+		out = append(out, d)
+		return nil
+	})
+	return out, err
+}
+
+/**
+ * @Description: 返回尚无审核决定的提交，保持提交顺序
+ * @author: Mr wpl
+ * @param subs []Submission: 提交
+ * @param decisions []Decision: 审核决定
+ * @return []Submission: 待审核的提交
+ */
+func Pending(subs []Submission, decisions []Decision) []Submission {
+	decided := make(map[string]bool, len(decisions))
+	for _, d := range decisions {
+		decided[d.ID] = true
+	}
+	var pending []Submission
+	for _, s := range subs {
+		if !decided[s.ID] {
+			pending = append(pending, s)
+		}
+	}
+	return pending
+}
+
+// appendLine 以 JSON 行追加到文件
+func appendLine(path string, v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	appendLock.Lock()
+	defer appendLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// readLines 逐行读取 jsonl 文件，跳过空行
+func readLines(path string, fn func(lineNo int, line []byte) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*MaxPatternLen) // JSON 转义后可能远长于规则本身
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := []byte(strings.TrimSpace(scanner.Text()))
+		if len(line) == 0 {
+			continue
+		}
+		if err := fn(lineNo, line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
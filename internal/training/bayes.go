@@ -0,0 +1,196 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 从带标签的样本目录 (webshell/、normal/) 重新训练 Bayes Words 模型，可留出部分样本评估精确率、召回率与 F1
+ */
+package training
+
+import (
+	"bt-shieldml/internal/analyzers/ml"
+	"bt-shieldml/internal/ast"
+	"bt-shieldml/internal/features"
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// BayesLabels 样本目录下的类别子目录，同时是模型中的类别名
+var BayesLabels = []string{"webshell", "normal"}
+
+// BayesSample 一个带标签的训练样本
+type BayesSample struct {
+	Name  string   // 相对样本目录的路径，如 webshell/a.php
+	Label string   // "webshell" 或 "normal"
+	Words []string // 与扫描时一致的 AST 词
+}
+
+// BayesMetrics 以 webshell 为正类的评估结果
+type BayesMetrics struct {
+	Samples        int
+	TruePositives  int
+	FalsePositives int
+	FalseNegatives int
+	TrueNegatives  int
+	Precision      float64
+	Recall         float64
+	F1             float64
+}
+
+/**
+ * @Description: 读取 samplesDir/webshell 与 samplesDir/normal 下的 PHP 文件 (递归)，经 AST 桥接提取词。
+ * 无法解析或没有提取到词的文件记录警告后跳过
+ * @author: Mr wpl
+ * @param samplesDir string: 样本目录
+ * @param astMgr ast.ASTManager: AST 管理器
+ * @return []BayesSample: 样本
+ * @return error: 类别子目录不存在或无法遍历时返回错误
+ */
+func LoadBayesSamples(samplesDir string, astMgr ast.ASTManager) ([]BayesSample, error) {
+	var samples []BayesSample
+	for _, label := range BayesLabels {
+		dir := filepath.Join(samplesDir, label)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("samples directory must contain %s/: %s is not a directory", label, dir)
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !slices.Contains(types.PHPExtensions, strings.ToLower(filepath.Ext(path))) {
+				return nil
+			}
+			words, err := extractWords(path, astMgr)
+			if err != nil {
+				logging.WarnLogger.Printf("Skipping training sample %s: %v", path, err)
+				return nil
+			}
+			name, _ := filepath.Rel(samplesDir, path)
+			samples = append(samples, BayesSample{Name: filepath.ToSlash(name), Label: label, Words: words})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s samples: %w", label, err)
+		}
+	}
+	return samples, nil
+}
+
+// extractWords 解析文件并提取与扫描时一致的 AST 词 (含 heredoc 内容)
+func extractWords(path string, astMgr ast.ASTManager) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	goAST, err := astMgr.GetAST(content)
+	if err != nil {
+		return nil, fmt.Errorf("AST parse failed: %w", err)
+	}
+	fileInfo := types.FileInfo{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+	featureSet, _ := features.ExtractAllFeatures(fileInfo, content, goAST, astMgr)
+	if featureSet == nil || len(featureSet.ASTWords) == 0 {
+		return nil, fmt.Errorf("no words extracted")
+	}
+	return featureSet.ASTWords, nil
+}
+
+/**
+ * @Description: 按类别分层随机留出 testSplit 比例的样本作为测试集 (每个类别至少留 1 个训练样本)
+ * @author: Mr wpl
+ * @param samples []BayesSample: 样本
+ * @param testSplit float64: 测试集比例，<= 0 时不留出
+ * @param seed int64: 随机种子
+ * @return []BayesSample: 训练集
+ * @return []BayesSample: 测试集
+ */
+func SplitBayesSamples(samples []BayesSample, testSplit float64, seed int64) ([]BayesSample, []BayesSample) {
+	if testSplit <= 0 {
+		return samples, nil
+	}
+	rng := rand.New(rand.NewSource(seed))
+	var train, test []BayesSample
+	for _, label := range BayesLabels {
+		var class []BayesSample
+		for _, s := range samples {
+			if s.Label == label {
+				class = append(class, s)
+			}
+		}
+		rng.Shuffle(len(class), func(i, j int) { class[i], class[j] = class[j], class[i] })
+		n := min(int(float64(len(class))*testSplit+0.5), len(class)-1)
+		if n < 0 {
+			n = 0
+		}
+		test = append(test, class[:n]...)
+		train = append(train, class[n:]...)
+	}
+	return train, test
+}
+
+/**
+ * @Description: 统计各类别的词频与文档数，生成与原 Words.model 格式相同的模型
+ * @author: Mr wpl
+ * @param samples []BayesSample: 训练样本
+ * @return *ml.WordsModel: 模型
+ * @return error: 缺少某一类别的样本或标签非法时返回错误
+ */
+func TrainBayes(samples []BayesSample) (*ml.WordsModel, error) {
+	counts := make(map[string]int)
+	model := ml.NewWordsModel()
+	for _, s := range samples {
+		if err := model.AddDocument(s.Words, s.Label); err != nil {
+			return nil, fmt.Errorf("sample %s: %w", s.Name, err)
+		}
+		counts[s.Label]++
+	}
+	for _, label := range BayesLabels {
+		if counts[label] == 0 {
+			return nil, fmt.Errorf("no %s training samples", label)
+		}
+	}
+	return model, nil
+}
+
+/**
+ * @Description: 用模型预测样本类别，以 webshell 为正类计算精确率、召回率与 F1
+ * @author: Mr wpl
+ * @param model *ml.WordsModel: 模型
+ * @param samples []BayesSample: 测试样本
+ * @return BayesMetrics: 评估结果
+ */
+func EvaluateBayes(model *ml.WordsModel, samples []BayesSample) BayesMetrics {
+	classify := model.Classifier()
+	m := BayesMetrics{Samples: len(samples)}
+	for _, s := range samples {
+		predicted := classify(s.Words)
+		switch {
+		case predicted == "webshell" && s.Label == "webshell":
+			m.TruePositives++
+		case predicted == "webshell":
+			m.FalsePositives++
+		case s.Label == "webshell":
+			m.FalseNegatives++
+		default:
+			m.TrueNegatives++
+		}
+	}
+	if m.TruePositives+m.FalsePositives > 0 {
+		m.Precision = float64(m.TruePositives) / float64(m.TruePositives+m.FalsePositives)
+	}
+	if m.TruePositives+m.FalseNegatives > 0 {
+		m.Recall = float64(m.TruePositives) / float64(m.TruePositives+m.FalseNegatives)
+	}
+	if m.Precision+m.Recall > 0 {
+		m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+	}
+	return m
+}
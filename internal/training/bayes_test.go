@@ -0,0 +1,314 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: Bayes Words 重新训练测试：在临时目录中生成极小的合成语料，用假 AST 管理器 (按空白分词) 代替 PHP 桥接
+ */
+package training
+
+import (
+	"bt-shieldml/internal/analyzers/ml"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakeASTManager 将源码按空白拆分为 AST 词 (去掉 <?php)，内容包含 PARSE_ERROR 时解析失败
+type fakeASTManager struct{}
+
+func (fakeASTManager) GetAST(source []byte) (interface{}, error) {
+	if strings.Contains(string(source), "PARSE_ERROR") {
+		return nil, errors.New("syntax error")
+	}
+	return string(source), nil
+}
+
+func (fakeASTManager) GetWordsAndCallable(astRoot interface{}) ([]string, bool, error) {
+	var words []string
+	for _, w := range strings.Fields(astRoot.(string)) {
+		if w != "<?php" {
+			words = append(words, w)
+		}
+	}
+	return words, false, nil
+}
+
+func (fakeASTManager) GetOpSerial(astRoot interface{}) ([][]int, error) { return nil, nil }
+
+func (fakeASTManager) Cleanup() error { return nil }
+
+// bayesCorpus 合成语料：webshell 样本以 eval/base64_decode/$_POST 为主，normal 样本以 echo/function/return 为主
+var bayesCorpus = map[string]string{
+	"webshell/a.php":        "<?php eval base64_decode $_POST",
+	"webshell/b.php":        "<?php eval $_POST eval",
+	"webshell/sub/c.PHP":    "<?php base64_decode $_POST assert",
+	"webshell/d.php":        "<?php eval gzinflate $_POST",
+	"webshell/broken.php":   "<?php PARSE_ERROR eval",
+	"webshell/empty.php":    "<?php",
+	"webshell/notes.txt":    "eval eval eval",
+	"normal/index.php":      "<?php echo function return",
+	"normal/lib/util.php":   "<?php function return $this",
+	"normal/view.phtml":     "<?php echo echo $this",
+	"normal/controller.php": "<?php function echo return",
+}
+
+// writeBayesCorpus 将语料写入临时目录，返回样本目录
+func writeBayesCorpus(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range bayesCorpus {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadBayesSamples(t *testing.T) {
+	samples, err := LoadBayesSamples(writeBayesCorpus(t), fakeASTManager{})
+	if err != nil {
+		t.Fatalf("LoadBayesSamples() error = %v", err)
+	}
+
+	// 无法解析、没有词的文件与非 PHP 文件被跳过
+	got := make(map[string]BayesSample)
+	for _, s := range samples {
+		got[s.Name] = s
+	}
+	want := map[string][]string{
+		"webshell/a.php":        {"eval", "base64_decode", "$_POST"},
+		"webshell/b.php":        {"eval", "$_POST", "eval"},
+		"webshell/sub/c.PHP":    {"base64_decode", "$_POST", "assert"},
+		"webshell/d.php":        {"eval", "gzinflate", "$_POST"},
+		"normal/index.php":      {"echo", "function", "return"},
+		"normal/lib/util.php":   {"function", "return", "$this"},
+		"normal/view.phtml":     {"echo", "echo", "$this"},
+		"normal/controller.php": {"function", "echo", "return"},
+	}
+	if len(got) != len(want) {
+		names := make([]string, 0, len(got))
+		for name := range got {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.Fatalf("loaded samples %v, want %d samples", names, len(want))
+	}
+	for name, words := range want {
+		s, ok := got[name]
+		if !ok {
+			t.Errorf("sample %s not loaded", name)
+			continue
+		}
+		if wantLabel := strings.SplitN(name, "/", 2)[0]; s.Label != wantLabel {
+			t.Errorf("%s label = %q, want %q", name, s.Label, wantLabel)
+		}
+		if !reflect.DeepEqual(s.Words, words) {
+			t.Errorf("%s words = %q, want %q", name, s.Words, words)
+		}
+	}
+}
+
+func TestLoadBayesSamplesMissingClass(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "webshell"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBayesSamples(dir, fakeASTManager{}); err == nil || !strings.Contains(err.Error(), "normal/") {
+		t.Errorf("LoadBayesSamples() without normal/ error = %v, want an error naming normal/", err)
+	}
+}
+
+// bayesSamples 每个类别 n 个样本
+func bayesSamples(webshell, normal int) []BayesSample {
+	var samples []BayesSample
+	for i := 0; i < webshell; i++ {
+		samples = append(samples, BayesSample{Name: "webshell/" + string(rune('a'+i)), Label: "webshell", Words: []string{"eval"}})
+	}
+	for i := 0; i < normal; i++ {
+		samples = append(samples, BayesSample{Name: "normal/" + string(rune('a'+i)), Label: "normal", Words: []string{"echo"}})
+	}
+	return samples
+}
+
+func TestSplitBayesSamples(t *testing.T) {
+	tests := []struct {
+		name             string
+		webshell, normal int
+		testSplit        float64
+		wantTrain        map[string]int
+		wantTest         map[string]int
+	}{
+		{"no split", 4, 4, 0, map[string]int{"webshell": 4, "normal": 4}, map[string]int{}},
+		{"20 percent rounds per class", 10, 5, 0.2, map[string]int{"webshell": 8, "normal": 4}, map[string]int{"webshell": 2, "normal": 1}},
+		{"half", 4, 4, 0.5, map[string]int{"webshell": 2, "normal": 2}, map[string]int{"webshell": 2, "normal": 2}},
+		{"keeps one training sample", 3, 1, 0.9, map[string]int{"webshell": 1, "normal": 1}, map[string]int{"webshell": 2}},
+	}
+	countLabels := func(samples []BayesSample) map[string]int {
+		counts := make(map[string]int)
+		for _, s := range samples {
+			counts[s.Label]++
+		}
+		return counts
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := bayesSamples(tt.webshell, tt.normal)
+			train, test := SplitBayesSamples(samples, tt.testSplit, 1)
+			if got := countLabels(train); !reflect.DeepEqual(got, tt.wantTrain) {
+				t.Errorf("train = %v, want %v", got, tt.wantTrain)
+			}
+			if got := countLabels(test); !reflect.DeepEqual(got, tt.wantTest) {
+				t.Errorf("test = %v, want %v", got, tt.wantTest)
+			}
+
+			// 训练集与测试集互不重叠且覆盖全部样本，相同种子结果相同
+			var names []string
+			for _, s := range append(append([]BayesSample(nil), train...), test...) {
+				names = append(names, s.Name)
+			}
+			sort.Strings(names)
+			if len(slices.Compact(slices.Clone(names))) != len(samples) || len(names) != len(samples) {
+				t.Errorf("train+test = %v, want each of the %d samples once", names, len(samples))
+			}
+			train2, test2 := SplitBayesSamples(samples, tt.testSplit, 1)
+			if !reflect.DeepEqual(train, train2) || !reflect.DeepEqual(test, test2) {
+				t.Error("split is not deterministic for the same seed")
+			}
+		})
+	}
+}
+
+func TestTrainBayesModelFormat(t *testing.T) {
+	samples, err := LoadBayesSamples(writeBayesCorpus(t), fakeASTManager{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := TrainBayes(samples)
+	if err != nil {
+		t.Fatalf("TrainBayes() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), ml.BayesWordsModelFile)
+	if err := model.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// 与原 Words.model 相同的 JSON 结构
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type classJSON struct {
+		DocCount       int            `json:"docCount"`
+		WordCount      map[string]int `json:"wordCount"`
+		TotalWordCount int            `json:"totalWordCount"`
+	}
+	var got struct {
+		Normal             classJSON `json:"normal"`
+		Webshell           classJSON `json:"webshell"`
+		TotalDocumentCount int       `json:"totalDocumentCount"`
+	}
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("model is not valid JSON: %v", err)
+	}
+	wantWebshell := classJSON{
+		DocCount:       4,
+		WordCount:      map[string]int{"eval": 4, "base64_decode": 2, "$_POST": 4, "assert": 1, "gzinflate": 1},
+		TotalWordCount: 12,
+	}
+	wantNormal := classJSON{
+		DocCount:       4,
+		WordCount:      map[string]int{"echo": 4, "function": 3, "return": 3, "$this": 2},
+		TotalWordCount: 12,
+	}
+	if !reflect.DeepEqual(got.Webshell, wantWebshell) {
+		t.Errorf("webshell = %+v, want %+v", got.Webshell, wantWebshell)
+	}
+	if !reflect.DeepEqual(got.Normal, wantNormal) {
+		t.Errorf("normal = %+v, want %+v", got.Normal, wantNormal)
+	}
+	if got.TotalDocumentCount != 8 {
+		t.Errorf("totalDocumentCount = %d, want 8", got.TotalDocumentCount)
+	}
+
+	// 保存的模型可以被扫描时的加载函数读取
+	loaded, err := ml.LoadWordsModel(path)
+	if err != nil {
+		t.Fatalf("LoadWordsModel() error = %v", err)
+	}
+	if loaded.Vocabulary() != 9 {
+		t.Errorf("Vocabulary() = %d, want 9", loaded.Vocabulary())
+	}
+}
+
+func TestTrainBayesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []BayesSample
+		want    string
+	}{
+		{"no samples", nil, "no webshell training samples"},
+		{"only webshell", bayesSamples(2, 0), "no normal training samples"},
+		{"only normal", bayesSamples(0, 2), "no webshell training samples"},
+		{"unknown label", append(bayesSamples(1, 1), BayesSample{Name: "other/x.php", Label: "other"}), `unknown label "other"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TrainBayes(tt.samples); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("TrainBayes() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateBayes(t *testing.T) {
+	samples, err := LoadBayesSamples(writeBayesCorpus(t), fakeASTManager{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := TrainBayes(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 两个正确识别的 webshell、一个漏报、一个误报、两个正确的 normal
+	test := []BayesSample{
+		{Name: "tp1", Label: "webshell", Words: []string{"eval", "$_POST"}},
+		{Name: "tp2", Label: "webshell", Words: []string{"base64_decode", "gzinflate"}},
+		{Name: "fn", Label: "webshell", Words: []string{"echo", "function"}},
+		{Name: "fp", Label: "normal", Words: []string{"eval", "assert"}},
+		{Name: "tn1", Label: "normal", Words: []string{"return", "$this"}},
+		{Name: "tn2", Label: "normal", Words: []string{"echo"}},
+	}
+	got := EvaluateBayes(model, test)
+	want := BayesMetrics{
+		Samples:        6,
+		TruePositives:  2,
+		FalsePositives: 1,
+		FalseNegatives: 1,
+		TrueNegatives:  2,
+	}
+	if got.Samples != want.Samples || got.TruePositives != want.TruePositives || got.FalsePositives != want.FalsePositives ||
+		got.FalseNegatives != want.FalseNegatives || got.TrueNegatives != want.TrueNegatives {
+		t.Fatalf("EvaluateBayes() = %+v, want counts %+v", got, want)
+	}
+	for name, v := range map[string]float64{"Precision": got.Precision, "Recall": got.Recall, "F1": got.F1} {
+		if math.Abs(v-2.0/3) > 1e-9 {
+			t.Errorf("%s = %v, want 2/3", name, v)
+		}
+	}
+
+	// 没有预测为 webshell 的样本时各指标为 0，不除以零
+	if m := EvaluateBayes(model, []BayesSample{{Name: "tn", Label: "normal", Words: []string{"echo"}}}); m.Precision != 0 || m.Recall != 0 || m.F1 != 0 {
+		t.Errorf("EvaluateBayes() on a single true negative = %+v, want zero metrics", m)
+	}
+}
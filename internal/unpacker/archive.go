@@ -0,0 +1,246 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: ZIP、TAR.GZ 归档解包 (含嵌套归档)，提取其中需要扫描的源码文件，限制单个成员与整个归档的解压大小
+ */
+package unpacker
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxArchiveDepth 嵌套归档的最大解包层数 (最外层为 1)
+const MaxArchiveDepth = 3
+
+// 归档类型
+const (
+	KindPhar  = "phar"
+	KindZip   = "zip"
+	KindTarGz = "tar.gz"
+)
+
+// errArchiveTooLarge 解压总大小超过限制
+var errArchiveTooLarge = errors.New("archive exceeds the total extraction limit")
+
+// Member 从归档中解压出的文件
+type Member struct {
+	Path string // 解压后的磁盘路径
+	Name string // 归档内的路径，嵌套归档以 "!/" 连接，如 "inner.zip!/shell.php"
+}
+
+// ArchiveKind 按文件名返回归档类型 (phar、zip、tar.gz)，不是支持的归档时返回空字符串
+func ArchiveKind(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".phar"):
+		return KindPhar
+	case strings.HasSuffix(lower, ".zip"):
+		return KindZip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return KindTarGz
+	}
+	return ""
+}
+
+// unpackState 一次解包 (含嵌套归档) 共用的限制与已解压大小
+type unpackState struct {
+	extensions []string
+	maxTotal   int64
+	total      int64
+}
+
+/**
+ * @Description: 解包 ZIP 或 TAR.GZ 归档，将扩展名在 extensions 中的成员解压到 destDir，嵌套的 ZIP/TAR.GZ 归档
+ * 继续解包 (最多 MaxArchiveDepth 层)。超过 MaxMemberSize 的成员跳过；解压总大小 (含嵌套归档本身) 超过 maxTotal 时
+ * 停止解包并返回已解压的成员与错误。成员路径经 MemberPath 校验，不会写到 destDir 之外
+ * @author: Mr wpl
+ * @param path string: 归档路径
+ * @param destDir string: 解压目录
+ * @param extensions []string: 需要解压的扩展名 (小写、带点)
+ * @param maxTotal int64: 解压总大小上限，<= 0 时不限制
+ * @return []Member: 解压出的成员
+ * @return error: 错误
+ */
+func UnpackArchive(path string, destDir string, extensions []string, maxTotal int64) ([]Member, error) {
+	state := &unpackState{extensions: extensions, maxTotal: maxTotal}
+	return state.unpack(path, ArchiveKind(path), destDir, 1)
+}
+
+// unpack 按类型解包一个归档
+func (s *unpackState) unpack(path string, kind string, destDir string, depth int) ([]Member, error) {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create unpack directory: %w", err)
+	}
+	switch kind {
+	case KindZip:
+		return s.unpackZip(path, destDir, depth)
+	case KindTarGz:
+		return s.unpackTarGz(path, destDir, depth)
+	}
+	return nil, fmt.Errorf("unsupported archive type: %s", path)
+}
+
+// unpackZip 解包 ZIP 归档
+func (s *unpackState) unpackZip(path string, destDir string, depth int) ([]Member, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip %s: %w", path, err)
+	}
+	defer reader.Close()
+
+	var members []Member
+	for _, entry := range reader.File {
+		if !entry.Mode().IsRegular() || entry.UncompressedSize64 > MaxMemberSize {
+			continue
+		}
+		nested := s.nestedKind(entry.Name, depth)
+		if nested == "" && !s.wanted(entry.Name) {
+			continue
+		}
+		src, err := entry.Open()
+		if err != nil {
+			return members, fmt.Errorf("failed to open member %s: %w", entry.Name, err)
+		}
+		extracted, err := s.extract(src, entry.Name, nested, destDir, depth)
+		src.Close()
+		members = append(members, extracted...)
+		if err != nil {
+			return members, err
+		}
+	}
+	return members, nil
+}
+
+// unpackTarGz 解包 TAR.GZ 归档，符号链接、硬链接等非普通文件跳过
+func (s *unpackState) unpackTarGz(path string, destDir string, depth int) ([]Member, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tar.gz %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	var members []Member
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return members, nil
+		}
+		if err != nil {
+			return members, fmt.Errorf("failed to read tar.gz %s: %w", path, err)
+		}
+		if header.Typeflag != tar.TypeReg || header.Size > MaxMemberSize {
+			continue
+		}
+		nested := s.nestedKind(header.Name, depth)
+		if nested == "" && !s.wanted(header.Name) {
+			continue
+		}
+		extracted, err := s.extract(reader, header.Name, nested, destDir, depth)
+		members = append(members, extracted...)
+		if err != nil {
+			return members, err
+		}
+	}
+}
+
+/**
+ * @Description: 写出一个成员；成员本身是归档时解包到同名的 "<成员>.d" 目录后删除归档文件，返回其中的成员
+ * @author: Mr wpl
+ * @param src io.Reader: 成员内容
+ * @param name string: 成员在归档内的路径
+ * @param nested string: 成员的归档类型，不是归档时为空
+ * @param destDir string: 解压目录
+ * @param depth int: 当前归档的层数
+ * @return []Member: 解压出的成员
+ * @return error: 错误
+ */
+func (s *unpackState) extract(src io.Reader, name string, nested string, destDir string, depth int) ([]Member, error) {
+	target, err := MemberPath(destDir, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.write(src, name, target); err != nil {
+		return nil, err
+	}
+	if nested == "" {
+		return []Member{{Path: target, Name: memberName(name)}}, nil
+	}
+
+	inner, err := s.unpack(target, nested, target+".d", depth+1)
+	os.Remove(target)
+	members := make([]Member, 0, len(inner))
+	for _, m := range inner {
+		members = append(members, Member{Path: m.Path, Name: memberName(name) + "!/" + m.Name})
+	}
+	return members, err
+}
+
+// write 将成员写入目标路径，计入解压总大小
+func (s *unpackState) write(src io.Reader, name string, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", name, err)
+	}
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	defer dst.Close()
+
+	// 限制读取长度，防止头部声明的大小与实际不符；总大小限制防止解压炸弹
+	limit := int64(MaxMemberSize)
+	if s.maxTotal > 0 && s.maxTotal-s.total < limit {
+		limit = s.maxTotal - s.total
+	}
+	n, err := io.Copy(dst, io.LimitReader(src, limit+1))
+	s.total += n
+	if err != nil {
+		return fmt.Errorf("failed to extract member %s: %w", name, err)
+	}
+	if s.maxTotal > 0 && s.total > s.maxTotal {
+		os.Remove(target)
+		return errArchiveTooLarge
+	}
+	return nil
+}
+
+// wanted 判断成员扩展名是否需要解压
+func (s *unpackState) wanted(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range s.extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedKind 返回成员的嵌套归档类型 (仅 ZIP、TAR.GZ，未超过最大层数时)
+func (s *unpackState) nestedKind(name string, depth int) string {
+	if depth >= MaxArchiveDepth {
+		return ""
+	}
+	switch kind := ArchiveKind(name); kind {
+	case KindZip, KindTarGz:
+		return kind
+	}
+	return ""
+}
+
+// memberName 归档内路径的规范形式：正斜杠分隔，不含前导 "/" 与 "./"
+func memberName(name string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean("/"+filepath.FromSlash(name))), "/")
+}
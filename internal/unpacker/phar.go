@@ -0,0 +1,104 @@
+/*
+ * @Date: 2025-05-23 10:05:47
+ * @Editors: Mr wpl
+ * @Description: phar (PHP Archive) 解包，提取其中的 php 文件
+ */
+package unpacker
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxMemberSize 单个成员文件的最大解压大小，超过则跳过 (与引擎的单文件大小限制一致)
+const MaxMemberSize = 10 * 1024 * 1024
+
+/**
+ * @Description: 以 ZIP 格式读取 phar 文件，将其中的 .php 成员解压到 destDir
+ * @author: Mr wpl
+ * @param path string: phar 文件路径
+ * @param destDir string: 解压目录
+ * @return []string: 解压出的文件路径，顺序与归档内一致
+ * @return error: 错误
+ */
+func UnpackPhar(path string, destDir string) ([]string, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open phar %s as zip: %w", path, err)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create unpack directory: %w", err)
+	}
+
+	var extracted []string
+	for _, member := range reader.File {
+		if member.FileInfo().IsDir() {
+			continue
+		}
+		if strings.ToLower(filepath.Ext(member.Name)) != ".php" {
+			continue
+		}
+		if member.UncompressedSize64 > MaxMemberSize {
+			continue
+		}
+
+		target, err := MemberPath(destDir, member.Name)
+		if err != nil {
+			return extracted, err
+		}
+		if err := extractMember(member, target); err != nil {
+			return extracted, err
+		}
+		extracted = append(extracted, target)
+	}
+
+	return extracted, nil
+}
+
+/**
+ * @Description: 计算成员在解压目录中的路径，防止 "../" 等路径穿越
+ * @author: Mr wpl
+ * @param destDir string: 解压目录
+ * @param name string: 成员名称
+ * @return string: 解压后的路径
+ * @return error: 错误
+ */
+func MemberPath(destDir string, name string) (string, error) {
+	cleanName := filepath.Clean("/" + filepath.FromSlash(name))
+	target := filepath.Join(destDir, cleanName)
+	if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal member path in archive: %s", name)
+	}
+	return target, nil
+}
+
+// extractMember 将单个 zip 成员写入目标路径
+func extractMember(member *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", member.Name, err)
+	}
+
+	src, err := member.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open member %s: %w", member.Name, err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	defer dst.Close()
+
+	// 限制读取长度，防止头部声明的大小与实际不符
+	if _, err := io.Copy(dst, io.LimitReader(src, MaxMemberSize)); err != nil {
+		return fmt.Errorf("failed to extract member %s: %w", member.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: phar 解包测试
+ */
+package unpacker
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// pharStub ZIP 格式 phar 的 stub，PHP 执行 phar 时首先运行
+const pharStub = "<?php Phar::mapPhar('app.phar'); include 'phar://app.phar/index.php'; __HALT_COMPILER(); ?>"
+
+// writePhar 构造一个最小的 ZIP 格式 phar：stub 保存在 .phar/stub.php，其余为普通成员
+func writePhar(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	entries := map[string]string{".phar/stub.php": pharStub}
+	for name, content := range members {
+		entries[name] = content
+	}
+	for name, content := range entries {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnpackPhar(t *testing.T) {
+	tmp := t.TempDir()
+	pharPath := filepath.Join(tmp, "app.phar")
+	writePhar(t, pharPath, map[string]string{
+		"index.php":         "<?php echo 'hello';",
+		"lib/shell.PHP":     "<?php eval($_POST['x']);",
+		"assets/readme.txt": "not php",
+	})
+
+	destDir := filepath.Join(tmp, "out")
+	got, err := UnpackPhar(pharPath, destDir)
+	if err != nil {
+		t.Fatalf("UnpackPhar() error = %v", err)
+	}
+
+	want := map[string]string{
+		filepath.Join(destDir, ".phar", "stub.php"): pharStub,
+		filepath.Join(destDir, "index.php"):         "<?php echo 'hello';",
+		filepath.Join(destDir, "lib", "shell.PHP"):  "<?php eval($_POST['x']);",
+	}
+	extracted := make(map[string]string, len(got))
+	for _, path := range got {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("extracted file %s is unreadable: %v", path, err)
+		}
+		extracted[path] = string(data)
+	}
+	if !reflect.DeepEqual(extracted, want) {
+		t.Errorf("UnpackPhar() extracted %v, want %v", extracted, want)
+	}
+}
+
+func TestUnpackPharNotZip(t *testing.T) {
+	tmp := t.TempDir()
+	pharPath := filepath.Join(tmp, "plain.phar")
+	if err := os.WriteFile(pharPath, []byte(pharStub), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnpackPhar(pharPath, filepath.Join(tmp, "out")); err == nil {
+		t.Error("UnpackPhar() on a non-ZIP phar returned no error")
+	}
+}
+
+func TestMemberPath(t *testing.T) {
+	destDir := filepath.Join(t.TempDir(), "out")
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"index.php", filepath.Join(destDir, "index.php")},
+		{"a/b/c.php", filepath.Join(destDir, "a", "b", "c.php")},
+		{"../../etc/evil.php", filepath.Join(destDir, "etc", "evil.php")},
+		{"/abs/evil.php", filepath.Join(destDir, "abs", "evil.php")},
+	}
+	for _, tt := range tests {
+		got, err := MemberPath(destDir, tt.name)
+		if err != nil {
+			t.Errorf("MemberPath(%q) error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MemberPath(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 白名单：按路径 (支持 glob 与 **) 或内容 SHA-256 跳过已知安全的文件，减少框架代码的误报
+ */
+package whitelist
+
+import (
+	"bt-shieldml/pkg/logging"
+	"bt-shieldml/pkg/types"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// hashPrefix 哈希条目的前缀
+const hashPrefix = "sha256:"
+
+/**
+ * @Description: 已知安全的文件路径模式与内容哈希
+ * @author: Mr wpl
+ */
+type Whitelist struct {
+	patterns [][]string      // 按 "/" 拆分的路径模式，"**" 匹配任意层目录
+	hashes   map[string]bool // SHA-256 (小写 hex)
+}
+
+/**
+ * @Description: 加载白名单文件。每行一条：以 "sha256:" 开头的为内容哈希，其余为路径模式；
+ * 空行与 # 开头的行忽略。路径模式支持 *、?、[...] 通配符与跨目录的 "**"，
+ * 不以 "/" 开头的模式在任意目录层级匹配 (如 vendor/laravel/** 匹配 /var/www/vendor/laravel/x.php)，
+ * 以 "/" 结尾的模式匹配该目录下的所有文件
+ * @author: Mr wpl
+ * @param path string: 白名单文件路径
+ * @return *Whitelist: 白名单
+ * @return error: 文件无法读取、哈希格式错误或模式语法错误时返回错误
+ */
+func Load(path string) (*Whitelist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open whitelist %s: %w", path, err)
+	}
+	defer f.Close()
+
+	w := &Whitelist{hashes: make(map[string]bool)}
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(line), hashPrefix) {
+			sum := strings.ToLower(strings.TrimSpace(line[len(hashPrefix):]))
+			if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("whitelist %s line %d: invalid SHA-256 %q", path, lineNo, sum)
+			}
+			w.hashes[sum] = true
+			continue
+		}
+		pattern, err := compilePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("whitelist %s line %d: %w", path, lineNo, err)
+		}
+		w.patterns = append(w.patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read whitelist %s: %w", path, err)
+	}
+	logging.InfoLogger.Printf("Loaded whitelist %s: %d path patterns, %d hashes", path, len(w.patterns), len(w.hashes))
+	return w, nil
+}
+
+// compilePattern 将路径模式拆分为目录段并校验通配符语法，相对模式前加 "**"
+func compilePattern(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(pattern)
+	var segments []string
+	if !strings.HasPrefix(pattern, "/") {
+		segments = append(segments, "**")
+	}
+	for _, seg := range strings.Split(pattern, "/") {
+		if seg == "" || seg == "." {
+			continue
+		}
+		if seg != "**" {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+			}
+		}
+		segments = append(segments, seg)
+	}
+	// 以 "/" 结尾的模式表示目录，匹配其下所有文件
+	if strings.HasSuffix(pattern, "/") {
+		segments = append(segments, "**")
+	}
+	if len(segments) == 0 || (len(segments) == 1 && segments[0] == "**") {
+		return nil, fmt.Errorf("path pattern %q matches every file", pattern)
+	}
+	return segments, nil
+}
+
+/**
+ * @Description: 判断文件是否在白名单中：路径匹配任一模式，或内容的 SHA-256 在哈希列表中
+ * @author: Mr wpl
+ * @param fileInfo types.FileInfo: 文件信息，使用 Path (绝对路径)
+ * @param content []byte: 文件原始内容，为 nil 时只匹配路径
+ * @return bool: 是否在白名单中
+ */
+func (w *Whitelist) Matches(fileInfo types.FileInfo, content []byte) bool {
+	if w.MatchesPath(fileInfo.Path) {
+		return true
+	}
+	if content == nil || len(w.hashes) == 0 {
+		return false
+	}
+	sum := sha256.Sum256(content)
+	return w.MatchesHash(hex.EncodeToString(sum[:]))
+}
+
+// MatchesHash 判断内容的 SHA-256 (十六进制) 是否在哈希列表中，供不整体读入内存的大文件使用
+func (w *Whitelist) MatchesHash(sum string) bool {
+	return w.hashes[strings.ToLower(sum)]
+}
+
+// MatchesPath 判断路径是否匹配任一路径模式
+func (w *Whitelist) MatchesPath(filePath string) bool {
+	if len(w.patterns) == 0 {
+		return false
+	}
+	var segments []string
+	for _, seg := range strings.Split(filepath.ToSlash(filePath), "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	for _, pattern := range w.patterns {
+		if matchSegments(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments 逐段匹配路径，"**" 匹配零个或多个目录段
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
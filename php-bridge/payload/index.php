@@ -90,11 +90,19 @@ function parseToJson(string $code) : string {
 class PhpAstServer {
 
     /**
-     * get header data
+     * whether the current request asked for a gzip-compressed response
+     */
+    private $compress = false;
+
+    /**
+     * get header data, "<length>\n" or "<length> gzip\n" to request a compressed response
      * @return length of data
      */
     function getHeader() : int {
-        fscanf(STDIN, "%d%*c", $length);
+        $line = fgets(STDIN);
+        $fields = explode(" ", trim((string)$line));
+        $length = intval($fields[0]);
+        $this->compress = isset($fields[1]) && $fields[1] === "gzip";
         if (!$length) {
              throw new Exception('message header must be number');
         }
@@ -116,11 +124,20 @@ class PhpAstServer {
     }
 
     /**
-     * write data to client
+     * write data to client, prefixed with 'C' (gzip-compressed) or 'U' (uncompressed);
+     * falls back to 'U' when the zlib extension is not available
      */
     public function write(string $msg) {
         $msg = $msg."\n";
-        $len_str = strval(strlen($msg))."\n";
+        $marker = "U";
+        if ($this->compress && function_exists('gzencode')) {
+            $compressed = gzencode($msg);
+            if ($compressed !== false) {
+                $msg = $compressed;
+                $marker = "C";
+            }
+        }
+        $len_str = $marker.strval(strlen($msg))."\n";
         fwrite(STDOUT, $len_str);
         fwrite(STDOUT, $msg);
     }
@@ -148,8 +165,28 @@ class PhpAstServer {
     }
 }
 
+/**
+ * php ast server : shared memory communication
+ * (functions shieldml_shm_read / shieldml_shm_write are registered by php-bridge/shmem.c)
+ */
+class PhpAstShmServer {
+
+    /**
+     * loop to deal with request, shieldml_shm_read() returns false when the bridge is closed
+     */
+    public function loop() {
+        while (($src = shieldml_shm_read()) !== false) {
+            shieldml_shm_write(parseToJson($src));
+        }
+    }
+}
+
 function main() {
-    $server = new PhpAstServer();
+    if (function_exists('shieldml_shm_read')) {
+        $server = new PhpAstShmServer();
+    } else {
+        $server = new PhpAstServer();
+    }
     $server->loop();
 }
 
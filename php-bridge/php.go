@@ -3,7 +3,7 @@ package php_bridge
 
 /*
 #cgo CFLAGS: -Wall -Wextra -Werror -Wno-unused-parameter -I${SRCDIR}/include/php -I${SRCDIR}/include/php/Zend -I${SRCDIR}/include/php/TSRM -I${SRCDIR}/include/php/main
-#cgo LDFLAGS: ${SRCDIR}/lib/libphp7.a -lm
+#cgo LDFLAGS: ${SRCDIR}/lib/libphp7.a -lm -lz
 
 #include <stdint.h>
 #include <stdio.h>
@@ -33,7 +33,11 @@ var (
 )
 
 // StartBridge 获取持久化的 PHP 桥接实例句柄。如果尚未初始化，则进行初始化。
-func StartBridge() (stdin *os.File, stdout *os.File, exited chan error, err error) {
+// instanceID 为 0 时是进程内的桥接 (PHP embed 只能初始化一次)；大于 0 时是独立的桥接子进程，每个 ID 各自一对管道
+func StartBridge(instanceID int) (stdin *os.File, stdout *os.File, exited chan error, err error) {
+	if instanceID > 0 {
+		return startWorker(instanceID)
+	}
 	initOnce.Do(func() {
 		// logging.InfoLogger.Println("Initializing persistent PHP bridge C layer (first call)...")
 
@@ -80,6 +84,19 @@ func StartBridge() (stdin *os.File, stdout *os.File, exited chan error, err erro
 			return
 		}
 
+		// 挂载共享内存传输 (如已通过 AttachShmem 登记)
+		if err := attachPendingShmem(); err != nil {
+			goStdinWriter.Close()
+			goStdoutReader.Close()
+			cStdinReader.Close()
+			cStdoutWriter.Close()
+			startErr = err
+			goStdinWriter = nil
+			goStdoutReader = nil
+			phpProcessExited = nil
+			return
+		}
+
 		// 启动 goroutine 运行 C.execute 并监控
 		go func(cr, cw *os.File) {
 			defer func() {
@@ -115,12 +132,16 @@ func StartBridge() (stdin *os.File, stdout *os.File, exited chan error, err erro
 	return goStdinWriter, goStdoutReader, phpProcessExited, nil
 }
 
-// StopBridge 清理持久化的 PHP 桥接资源
-func StopBridge() error {
+// StopBridge 清理持久化的 PHP 桥接资源，instanceID 与 StartBridge 相同
+func StopBridge(instanceID int) error {
+	if instanceID > 0 {
+		return stopWorker(instanceID)
+	}
 	stopOnce.Do(func() {
 		// logging.InfoLogger.Println("Stopping persistent PHP Bridge...")
 
-		// 1. 关闭 Go 端的写入，向 PHP 发送 EOF 信号
+		// 1. 关闭 Go 端的写入，向 PHP 发送 EOF 信号 (共享内存传输下通知 PHP 循环退出)
+		shutdownShmem()
 		if goStdinWriter != nil {
 			// logging.InfoLogger.Println("Closing Go stdin writer...")
 			goStdinWriter.Close()
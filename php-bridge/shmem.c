@@ -0,0 +1,134 @@
+// php-bridge/shmem.c
+// 共享内存传输：Go 将源码写入共享输入缓冲区后 sem_post(req)，
+// PHP 通过 shieldml_shm_read() 取得源码，解析后调用 shieldml_shm_write() 写入输出缓冲区并 sem_post(resp)。
+#include <errno.h>
+#include <stdint.h>
+#include <string.h>
+
+#include "shmem.h"
+
+#ifndef _WIN32
+#include <semaphore.h>
+
+#include "sapi/embed/php_embed.h"
+
+// 共享内存头部，位于映射区域起始处，之后依次为输入缓冲区与输出缓冲区
+typedef struct {
+    sem_t    req;      // Go -> PHP: 输入就绪 (或关闭)
+    sem_t    resp;     // PHP -> Go: 输出就绪
+    uint64_t in_len;   // 输入数据长度
+    uint64_t out_len;  // 输出数据长度
+    int32_t  status;   // SHMEM_STATUS_*
+    int32_t  closed;   // 非 0 表示桥接关闭，PHP 循环应退出
+} shmem_header;
+
+static shmem_header *hdr = NULL;
+static char *in_buf = NULL;
+static char *out_buf = NULL;
+static size_t in_cap = 0;
+static size_t out_cap = 0;
+
+static void sem_wait_noint(sem_t *sem) {
+    while (sem_wait(sem) == -1 && errno == EINTR) {
+    }
+}
+
+PHP_FUNCTION(shieldml_shm_read) {
+    if (zend_parse_parameters_none() == FAILURE) {
+        return;
+    }
+    sem_wait_noint(&hdr->req);
+    if (hdr->closed || hdr->in_len > in_cap) {
+        RETURN_FALSE;
+    }
+    RETURN_STRINGL(in_buf, hdr->in_len);
+}
+
+PHP_FUNCTION(shieldml_shm_write) {
+    char *data;
+    size_t len;
+    if (zend_parse_parameters(ZEND_NUM_ARGS(), "s", &data, &len) == FAILURE) {
+        return;
+    }
+    if (len > out_cap) {
+        hdr->out_len = 0;
+        hdr->status = SHMEM_STATUS_OVERFLOW;
+    } else {
+        memcpy(out_buf, data, len);
+        hdr->out_len = len;
+        hdr->status = SHMEM_STATUS_OK;
+    }
+    sem_post(&hdr->resp);
+    RETURN_TRUE;
+}
+
+static const zend_function_entry shmem_functions[] = {
+    PHP_FE(shieldml_shm_read, NULL)
+    PHP_FE(shieldml_shm_write, NULL)
+    PHP_FE_END
+};
+
+int shmem_attach(void *base, size_t input_cap, size_t output_cap) {
+    if (!base || sizeof(shmem_header) > SHMEM_HEADER_SIZE) {
+        return 1;
+    }
+
+    hdr = (shmem_header *)base;
+    in_buf = (char *)base + SHMEM_HEADER_SIZE;
+    out_buf = in_buf + input_cap;
+    in_cap = input_cap;
+    out_cap = output_cap;
+
+    memset(hdr, 0, sizeof(shmem_header));
+    if (sem_init(&hdr->req, 1, 0) != 0) {
+        return 1;
+    }
+    if (sem_init(&hdr->resp, 1, 0) != 0) {
+        sem_destroy(&hdr->req);
+        return 1;
+    }
+
+    // 注册供 payload/index.php 使用的传输函数，index.php 据此选择共享内存循环
+    if (zend_register_functions(NULL, shmem_functions, NULL, MODULE_PERSISTENT) != SUCCESS) {
+        sem_destroy(&hdr->req);
+        sem_destroy(&hdr->resp);
+        return 1;
+    }
+    return 0;
+}
+
+int64_t shmem_exchange(size_t len) {
+    if (!hdr || hdr->closed || len > in_cap) {
+        return SHMEM_STATUS_INVALID;
+    }
+    hdr->in_len = len;
+    sem_post(&hdr->req);
+    sem_wait_noint(&hdr->resp);
+    if (hdr->status != SHMEM_STATUS_OK) {
+        return hdr->status;
+    }
+    return (int64_t)hdr->out_len;
+}
+
+void shmem_shutdown(void) {
+    if (!hdr || hdr->closed) {
+        return;
+    }
+    hdr->closed = 1;
+    sem_post(&hdr->req);
+}
+
+#else
+
+int shmem_attach(void *base, size_t input_cap, size_t output_cap) {
+    return 1; // Windows 下不支持共享内存传输
+}
+
+int64_t shmem_exchange(size_t len) {
+    return SHMEM_STATUS_INVALID;
+}
+
+void shmem_shutdown(void) {
+}
+
+#endif
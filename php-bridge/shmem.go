@@ -0,0 +1,96 @@
+// php-bridge/shmem.go
+package php_bridge
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include "shmem.h"
+*/
+import "C"
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// ShmemHeaderSize 共享内存区域头部大小，输入缓冲区从该偏移开始，其后为输出缓冲区
+const ShmemHeaderSize = C.SHMEM_HEADER_SIZE
+
+var (
+	shmemBase     unsafe.Pointer // 待挂载/已挂载的共享内存区域 (非 Go 堆内存)
+	shmemInCap    int
+	shmemOutCap   int
+	shmemAttached bool
+	shmemMu       sync.Mutex // 保证同一时间只有一个请求占用共享缓冲区
+)
+
+/**
+ * @Description: 登记共享内存区域，桥接启动时 (StartBridge) 挂载到 C 层并启用共享内存传输。
+ * 必须在第一次 StartBridge 之前调用，region 必须是 mmap 得到的内存。
+ * @author: Mr wpl
+ * @param region []byte: 共享内存区域，长度至少为 ShmemHeaderSize+inCap+outCap
+ * @param inCap int: 输入缓冲区大小
+ * @param outCap int: 输出缓冲区大小
+ * @return error: 错误
+ */
+func AttachShmem(region []byte, inCap, outCap int) error {
+	shmemMu.Lock()
+	defer shmemMu.Unlock()
+
+	if goStdinWriter != nil {
+		return fmt.Errorf("php bridge already started, shared memory must be attached before StartBridge")
+	}
+	if inCap <= 0 || outCap <= 0 || len(region) < ShmemHeaderSize+inCap+outCap {
+		return fmt.Errorf("shared memory region too small: %d bytes", len(region))
+	}
+	shmemBase = unsafe.Pointer(&region[0])
+	shmemInCap = inCap
+	shmemOutCap = outCap
+	return nil
+}
+
+// attachPendingShmem 在 C.init 之后、C.execute 之前挂载已登记的共享内存
+func attachPendingShmem() error {
+	shmemMu.Lock()
+	defer shmemMu.Unlock()
+
+	if shmemBase == nil {
+		return nil
+	}
+	if ret := C.shmem_attach(shmemBase, C.size_t(shmemInCap), C.size_t(shmemOutCap)); ret != 0 {
+		return fmt.Errorf("php bridge shared memory attach failed with code %d", ret)
+	}
+	shmemAttached = true
+	return nil
+}
+
+/**
+ * @Description: 通知 PHP 输入缓冲区中已有 srcLen 字节源码，阻塞等待 AST JSON 写入输出缓冲区
+ * @author: Mr wpl
+ * @param srcLen int: 输入数据长度
+ * @return int: 输出数据长度
+ * @return error: 错误
+ */
+func ShmemExchange(srcLen int) (int, error) {
+	shmemMu.Lock()
+	defer shmemMu.Unlock()
+
+	if !shmemAttached {
+		return 0, fmt.Errorf("php bridge shared memory is not attached")
+	}
+	n := int64(C.shmem_exchange(C.size_t(srcLen)))
+	switch {
+	case n == C.SHMEM_STATUS_OVERFLOW:
+		return 0, fmt.Errorf("AST JSON exceeds shared output buffer (%d bytes)", shmemOutCap)
+	case n < 0:
+		return 0, fmt.Errorf("php bridge shared memory exchange failed with code %d", n)
+	}
+	return int(n), nil
+}
+
+// shutdownShmem 通知 PHP 共享内存循环退出
+func shutdownShmem() {
+	if shmemAttached {
+		C.shmem_shutdown()
+	}
+}
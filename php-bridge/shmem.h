@@ -0,0 +1,19 @@
+// php-bridge/shmem.h
+#ifndef SHIELDML_SHMEM_H
+#define SHIELDML_SHMEM_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+// 共享内存头部预留大小，输入缓冲区从该偏移开始
+#define SHMEM_HEADER_SIZE 4096
+
+#define SHMEM_STATUS_OK       0
+#define SHMEM_STATUS_OVERFLOW -1 // AST JSON 超出输出缓冲区
+#define SHMEM_STATUS_INVALID  -2 // 未初始化、已关闭或输入超出缓冲区
+
+int shmem_attach(void *base, size_t input_cap, size_t output_cap);
+int64_t shmem_exchange(size_t len);
+void shmem_shutdown(void);
+
+#endif
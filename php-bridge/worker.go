@@ -0,0 +1,143 @@
+// php-bridge/worker.go
+package php_bridge
+
+/*
+#include <stdint.h>
+
+int init(intptr_t fd_in, intptr_t fd_out);
+int execute(void);
+*/
+import "C"
+import (
+	"bt-shieldml/pkg/logging"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// workerEnv 桥接子进程的环境变量，值为实例 ID。设置后进程不执行 main，而是在 stdin/stdout 上运行 PHP 桥接
+const workerEnv = "BTSHIELD_PHP_BRIDGE_INSTANCE"
+
+// worker 桥接子进程 (instanceID > 0)
+type worker struct {
+	cmd    *exec.Cmd
+	stdin  *os.File   // Go -> PHP
+	stdout *os.File   // PHP -> Go
+	exited chan error // 子进程退出信号
+}
+
+var (
+	workersMu sync.Mutex
+	workers   = make(map[int]*worker)
+)
+
+func init() {
+	id := os.Getenv(workerEnv)
+	if id == "" {
+		return
+	}
+	os.Exit(serveWorker(id))
+}
+
+// serveWorker 子进程入口：PHP 桥接直接读写进程的 stdin/stdout，stdin 关闭 (主进程 StopBridge 或退出) 后返回
+func serveWorker(id string) int {
+	// stdout 是桥接协议通道，日志只能写到 stderr
+	logging.RedirectToStderr()
+	if ret := C.init(C.intptr_t(os.Stdin.Fd()), C.intptr_t(os.Stdout.Fd())); ret != 0 {
+		logging.ErrorLogger.Printf("php bridge worker %s: C initialization failed with code %d", id, ret)
+		return 1
+	}
+	if ret := C.execute(); ret != 0 {
+		return int(ret)
+	}
+	return 0
+}
+
+/**
+ * @Description: 以桥接子进程模式重新执行当前程序，返回与子进程通信的管道。同一 instanceID 已启动时返回已有的句柄
+ * @author: Mr wpl
+ * @param instanceID int: 实例 ID (> 0)
+ * @return *os.File: Go -> PHP
+ * @return *os.File: PHP -> Go
+ * @return chan error: 子进程退出信号
+ * @return error: 错误
+ */
+func startWorker(instanceID int) (*os.File, *os.File, chan error, error) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	if w, ok := workers[instanceID]; ok {
+		return w.stdin, w.stdout, w.exited, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to locate executable for php bridge worker %d: %w", instanceID, err)
+	}
+	childStdin, goStdin, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create stdin pipe for php bridge worker %d: %w", instanceID, err)
+	}
+	goStdout, childStdout, err := os.Pipe()
+	if err != nil {
+		childStdin.Close()
+		goStdin.Close()
+		return nil, nil, nil, fmt.Errorf("failed to create stdout pipe for php bridge worker %d: %w", instanceID, err)
+	}
+
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), workerEnv+"="+strconv.Itoa(instanceID))
+	cmd.Stdin = childStdin
+	cmd.Stdout = childStdout
+	cmd.Stderr = os.Stderr
+	startErr := cmd.Start()
+	// 子进程已继承子进程一端，父进程关闭自己的副本，子进程退出时读端才能收到 EOF
+	childStdin.Close()
+	childStdout.Close()
+	if startErr != nil {
+		goStdin.Close()
+		goStdout.Close()
+		return nil, nil, nil, fmt.Errorf("failed to start php bridge worker %d: %w", instanceID, startErr)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		if err == nil {
+			err = fmt.Errorf("php bridge worker %d exited unexpectedly (code 0)", instanceID)
+		} else {
+			err = fmt.Errorf("php bridge worker %d exited: %w", instanceID, err)
+		}
+		exited <- err
+		close(exited)
+	}()
+
+	workers[instanceID] = &worker{cmd: cmd, stdin: goStdin, stdout: goStdout, exited: exited}
+	return goStdin, goStdout, exited, nil
+}
+
+// stopWorker 关闭子进程的 stdin 并等待其退出，5 秒内未退出时强制结束
+func stopWorker(instanceID int) error {
+	workersMu.Lock()
+	w, ok := workers[instanceID]
+	delete(workers, instanceID)
+	workersMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	var err error
+	w.stdin.Close()
+	select {
+	case <-w.exited:
+	case <-time.After(5 * time.Second):
+		logging.ErrorLogger.Printf("Timeout waiting for php bridge worker %d to exit, killing it.", instanceID)
+		w.cmd.Process.Kill()
+		<-w.exited
+		err = fmt.Errorf("timeout waiting for php bridge worker %d exit", instanceID)
+	}
+	w.stdout.Close()
+	return err
+}
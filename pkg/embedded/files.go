@@ -16,6 +16,7 @@ import (
 //go:embed data/models/ProcessSVM.model.model
 //go:embed data/models/Words.model
 //go:embed data/signatures/Webshells_rules.yar
+//go:embed data/config/fingerprints.yaml
 var EmbeddedFiles embed.FS
 
 /**
@@ -0,0 +1,148 @@
+/*
+ * @Date: 2025-06-09 09:41:26
+ * @Editors: Mr wpl
+ * @Description: 日志上下文：在 context 中携带扫描 ID 与文件路径，输出为 scan_session_id、file_path 字段
+ */
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// scanContextKey context 中保存 scanContext 的键
+type scanContextKey struct{}
+
+// scanContext 一次扫描 (及其中单个文件) 的日志上下文
+type scanContext struct {
+	scanID   string
+	filePath string
+}
+
+/**
+ * @Description: 生成扫描 ID (8 字节随机数的十六进制)，随机源不可用时使用时间戳
+ * @author: Mr wpl
+ * @return string: 扫描 ID
+ */
+func NewScanID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+/**
+ * @Description: 返回携带扫描 ID 和文件路径的 context。参数为空时保留父 context 中的值
+ * @author: Mr wpl
+ * @param ctx context.Context: 父 context，为 nil 时使用 context.Background()
+ * @param scanID string: 扫描 ID
+ * @param filePath string: 文件路径
+ * @return context.Context: 新 context
+ */
+func WithContext(ctx context.Context, scanID string, filePath string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sc := fromContext(ctx)
+	if scanID != "" {
+		sc.scanID = scanID
+	}
+	if filePath != "" {
+		sc.filePath = filePath
+	}
+	return context.WithValue(ctx, scanContextKey{}, sc)
+}
+
+/**
+ * @Description: 读取 context 中的扫描 ID
+ * @author: Mr wpl
+ * @param ctx context.Context: context
+ * @return string: 扫描 ID，未设置时为空
+ */
+func ScanIDFromContext(ctx context.Context) string {
+	return fromContext(ctx).scanID
+}
+
+// fromContext 读取 context 中的日志上下文，ctx 为 nil 或未设置时返回零值
+func fromContext(ctx context.Context) scanContext {
+	if ctx == nil {
+		return scanContext{}
+	}
+	sc, _ := ctx.Value(scanContextKey{}).(scanContext)
+	return sc
+}
+
+// ContextLogger 绑定日志上下文的日志器，供分析器通过 FromContext 获取
+type ContextLogger struct {
+	sc scanContext
+}
+
+/**
+ * @Description: 返回绑定 ctx 中扫描 ID 和文件路径的日志器，ctx 为 nil 时输出不带前缀的日志
+ * @author: Mr wpl
+ * @param ctx context.Context: context
+ * @return *ContextLogger: 日志器
+ */
+func FromContext(ctx context.Context) *ContextLogger {
+	return &ContextLogger{sc: fromContext(ctx)}
+}
+
+// Infof 输出 INFO 日志
+func (l *ContextLogger) Infof(format string, args ...interface{}) {
+	output(InfoLogger, l.sc, format, args...)
+}
+
+// Warnf 输出 WARNING 日志
+func (l *ContextLogger) Warnf(format string, args ...interface{}) {
+	output(WarnLogger, l.sc, format, args...)
+}
+
+// Errorf 输出 ERROR 日志
+func (l *ContextLogger) Errorf(format string, args ...interface{}) {
+	output(ErrorLogger, l.sc, format, args...)
+}
+
+// InfoCtx 输出带 ctx 前缀的 INFO 日志
+func InfoCtx(ctx context.Context, format string, args ...interface{}) {
+	output(InfoLogger, fromContext(ctx), format, args...)
+}
+
+// WarnCtx 输出带 ctx 前缀的 WARNING 日志
+func WarnCtx(ctx context.Context, format string, args ...interface{}) {
+	output(WarnLogger, fromContext(ctx), format, args...)
+}
+
+// ErrorCtx 输出带 ctx 前缀的 ERROR 日志
+func ErrorCtx(ctx context.Context, format string, args ...interface{}) {
+	output(ErrorLogger, fromContext(ctx), format, args...)
+}
+
+/**
+ * @Description: 返回带 ctx 中 scan_session_id、file_path 字段的结构化日志器，如
+ * logging.WithCtx(ctx).Warn().Str("analyzer", name).Err(err).Msg("analyzer failed")
+ * @author: Mr wpl
+ * @param ctx context.Context: context
+ * @return zerolog.Logger: 日志器
+ */
+func WithCtx(ctx context.Context) zerolog.Logger {
+	sc := fromContext(ctx)
+	zc := rootLogger().With()
+	if sc.scanID != "" {
+		zc = zc.Str(FieldScanSessionID, sc.scanID)
+	}
+	if sc.filePath != "" {
+		zc = zc.Str(FieldFilePath, sc.filePath)
+	}
+	return zc.Caller().Logger()
+}
+
+// output 写入一行日志，calldepth 跳过本函数和调用的包装函数，使 caller 字段指向实际调用处
+func output(logger *Logger, sc scanContext, format string, args ...interface{}) {
+	logger.output(3, sc, fmt.Sprintf(format, args...))
+}
@@ -1,19 +1,253 @@
+/*
+ * @Date: 2026-10-17
+ * @Editors: Mr wpl
+ * @Description: 全局日志器：基于 zerolog，支持文本与 JSON (ELK/Splunk 采集) 两种格式及最低级别过滤。
+ * InfoLogger/WarnLogger/ErrorLogger 保留 *log.Logger 的常用方法 (Printf/Println/Fatalf/SetOutput)，调用处无需修改
+ */
 package logging
 
 import (
-	"log"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// FormatText 文本格式，如 "INFO: 2025/06/09 10:00:00 engine.go:12: 消息 file_path=/www/a.php"
+	FormatText = "text"
+	// FormatJSON 每行一个 JSON 对象，字段为 level、time、caller、message 及上下文字段
+	FormatJSON = "json"
 )
 
+// 结构化日志的上下文字段名
+const (
+	FieldScanSessionID = "scan_session_id"
+	FieldFilePath      = "file_path"
+	FieldDurationMs    = "duration_ms"
+	FieldAnalyzer      = "analyzer"
+)
+
+// textTimeFormat 文本格式的时间格式，与原 log.Ldate|log.Ltime 一致
+const textTimeFormat = "2006/01/02 15:04:05"
+
 var (
-	InfoLogger  *log.Logger
-	WarnLogger  *log.Logger
-	ErrorLogger *log.Logger
+	InfoLogger  *Logger
+	WarnLogger  *Logger
+	ErrorLogger *Logger
 )
 
+// state 当前日志格式与根日志器，Configure 与 SetOutput 时更新
+var state struct {
+	mu     sync.RWMutex
+	format string
+	root   zerolog.Logger
+}
+
 func init() {
-	// Simple logger setup, replace with a more robust solution (e.g., zap, logrus) if needed
-	InfoLogger = log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
-	WarnLogger = log.New(os.Stdout, "WARNING: ", log.Ldate|log.Ltime|log.Lshortfile)
-	ErrorLogger = log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
+	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.000Z07:00"
+	zerolog.CallerMarshalFunc = func(pc uintptr, file string, line int) string {
+		return filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	// INFO/WARNING 写到 stdout，ERROR 写到 stderr
+	InfoLogger = &Logger{level: zerolog.InfoLevel, out: os.Stdout}
+	WarnLogger = &Logger{level: zerolog.WarnLevel, out: os.Stdout}
+	ErrorLogger = &Logger{level: zerolog.ErrorLevel, out: os.Stderr}
+	state.format = FormatText
+	state.root = zerolog.New(levelRouter{}).Level(zerolog.InfoLevel).With().Timestamp().Logger()
+}
+
+/**
+ * @Description: 设置日志格式与最低级别 (来自配置文件 logging 段)，参数为空时使用 text 与 info
+ * @author: Mr wpl
+ * @param format string: "text" 或 "json"
+ * @param level string: "debug"、"info"、"warn" 或 "error"
+ * @return error: 格式或级别无效时返回错误，此时保持原设置
+ */
+func Configure(format string, level string) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		format = FormatText
+	case FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("invalid logging.format %q (want text or json)", format)
+	}
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.format = format
+	state.root = state.root.Level(lvl)
+	return nil
+}
+
+// parseLevel 解析日志级别名称，"warning" 等同于 "warn"
+func parseLevel(level string) (zerolog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return zerolog.DebugLevel, nil
+	case "", "info":
+		return zerolog.InfoLevel, nil
+	case "warn", "warning":
+		return zerolog.WarnLevel, nil
+	case "error":
+		return zerolog.ErrorLevel, nil
+	}
+	return zerolog.NoLevel, fmt.Errorf("invalid logging.level %q (want debug, info, warn or error)", level)
+}
+
+/**
+ * @Description: 返回带 file_path 字段的结构化日志器，用于单个文件的日志，如
+ * logging.WithFile(path).Info().Int64("duration_ms", ms).Msg("scanned")。INFO/WARN 写到 stdout，ERROR 写到 stderr
+ * @author: Mr wpl
+ * @param path string: 文件路径
+ * @return zerolog.Logger: 日志器
+ */
+func WithFile(path string) zerolog.Logger {
+	return rootLogger().With().Str(FieldFilePath, path).Caller().Logger()
+}
+
+// rootLogger 返回当前根日志器
+func rootLogger() zerolog.Logger {
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	return state.root
+}
+
+// RedirectToStderr 将 INFO/WARNING 日志改写到 stderr，避免干扰写到 stdout 的机器可读输出 (如 NDJSON)
+func RedirectToStderr() {
+	InfoLogger.SetOutput(os.Stderr)
+	WarnLogger.SetOutput(os.Stderr)
+}
+
+/**
+ * @Description: 固定级别的日志器，兼容 *log.Logger 的 Printf/Println/Fatalf/SetOutput，
+ * 输出经根日志器按当前格式与最低级别过滤后写到 SetOutput 设置的 Writer
+ * @author: Mr wpl
+ */
+type Logger struct {
+	level zerolog.Level
+	out   io.Writer // 受 state.mu 保护
+}
+
+// SetOutput 设置该级别日志的输出
+func (l *Logger) SetOutput(w io.Writer) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	l.out = w
+}
+
+// Printf 按 fmt.Sprintf 格式输出一行日志
+func (l *Logger) Printf(format string, args ...interface{}) {
+	l.Output(2, fmt.Sprintf(format, args...))
+}
+
+// Println 按 fmt.Sprintln 格式输出一行日志
+func (l *Logger) Println(args ...interface{}) {
+	l.Output(2, fmt.Sprintln(args...))
+}
+
+// Fatalf 输出日志后以状态码 1 退出
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.Output(2, fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+/**
+ * @Description: 输出一行日志，与 log.Logger.Output 相同，calldepth 为调用栈中需要跳过的层数 (1 为 Output 的调用处)
+ * @author: Mr wpl
+ * @param calldepth int: 跳过的层数，用于 caller 字段指向实际调用处
+ * @param msg string: 日志内容，末尾换行会被去掉
+ * @return error: 始终为 nil
+ */
+func (l *Logger) Output(calldepth int, msg string) error {
+	l.output(calldepth+1, scanContext{}, msg)
+	return nil
+}
+
+// output 按上下文添加 scan_session_id、file_path 字段后输出
+func (l *Logger) output(calldepth int, sc scanContext, msg string) {
+	root := rootLogger()
+	event := root.WithLevel(l.level)
+	if event == nil {
+		return
+	}
+	if sc.scanID != "" {
+		event = event.Str(FieldScanSessionID, sc.scanID)
+	}
+	if sc.filePath != "" {
+		event = event.Str(FieldFilePath, sc.filePath)
+	}
+	event.CallerSkipFrame(calldepth).Caller().Msg(strings.TrimSuffix(msg, "\n"))
+}
+
+// levelRouter 按级别将日志写到对应 Logger 的输出：ERROR 及以上为 ErrorLogger，WARN 为 WarnLogger，其余为 InfoLogger
+type levelRouter struct{}
+
+// Write 未带级别的日志写到 InfoLogger 的输出
+func (levelRouter) Write(p []byte) (int, error) {
+	return levelRouter{}.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 按级别选择输出，文本格式时经 ConsoleWriter 转换
+func (levelRouter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	target := InfoLogger
+	switch {
+	case level >= zerolog.ErrorLevel && level != zerolog.NoLevel:
+		target = ErrorLogger
+	case level == zerolog.WarnLevel:
+		target = WarnLogger
+	}
+
+	state.mu.RLock()
+	out, format := target.out, state.format
+	state.mu.RUnlock()
+	if format == FormatJSON {
+		return out.Write(p)
+	}
+	return consoleWriter(out).Write(p)
+}
+
+// consoleWriter 文本格式：级别、时间、调用位置、消息，其后为 key=value 上下文字段
+func consoleWriter(out io.Writer) zerolog.ConsoleWriter {
+	return zerolog.ConsoleWriter{
+		Out:        out,
+		NoColor:    true,
+		TimeFormat: textTimeFormat,
+		PartsOrder: []string{
+			zerolog.LevelFieldName,
+			zerolog.TimestampFieldName,
+			zerolog.CallerFieldName,
+			zerolog.MessageFieldName,
+		},
+		FormatLevel: func(i interface{}) string {
+			switch level, _ := i.(string); level {
+			case zerolog.LevelDebugValue:
+				return "DEBUG:"
+			case zerolog.LevelInfoValue:
+				return "INFO:"
+			case zerolog.LevelWarnValue:
+				return "WARNING:"
+			case zerolog.LevelErrorValue, zerolog.LevelFatalValue, zerolog.LevelPanicValue:
+				return "ERROR:"
+			default:
+				return ""
+			}
+		},
+		FormatCaller: func(i interface{}) string {
+			if caller, _ := i.(string); caller != "" {
+				return caller + ":"
+			}
+			return ""
+		},
+	}
 }
@@ -0,0 +1,36 @@
+/*
+ * @Date: 2025-05-26 15:32:09
+ * @Editors: Mr wpl
+ * @Description: 终端能力检测
+ */
+package terminal
+
+import "os"
+
+/**
+ * @Description: 判断终端是否支持 Unicode 输出。TERM=dumb 或 LC_ALL=C/POSIX 时视为不支持
+ * @author: Mr wpl
+ * @return bool: 是否支持
+ */
+func SupportsUnicode() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	switch os.Getenv("LC_ALL") {
+	case "C", "POSIX":
+		return false
+	}
+	return true
+}
+
+/**
+ * @Description: 判断终端是否支持 ANSI 颜色。设置 NO_COLOR 或 TERM=dumb 时视为不支持
+ * @author: Mr wpl
+ * @return bool: 是否支持
+ */
+func SupportsColor() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return os.Getenv("TERM") != "dumb"
+}
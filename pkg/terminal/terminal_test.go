@@ -0,0 +1,32 @@
+/*
+ * @Date: 2026-10-17 10:00:00
+ * @Editors: Mr wpl
+ * @Description: 终端能力检测测试
+ */
+package terminal
+
+import "testing"
+
+func TestSupportsUnicode(t *testing.T) {
+	tests := []struct {
+		name  string
+		term  string
+		lcAll string
+		want  bool
+	}{
+		{"xterm utf-8", "xterm-256color", "en_US.UTF-8", true},
+		{"unset locale", "xterm", "", true},
+		{"dumb terminal", "dumb", "en_US.UTF-8", false},
+		{"C locale", "xterm", "C", false},
+		{"POSIX locale", "xterm", "POSIX", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM", tt.term)
+			t.Setenv("LC_ALL", tt.lcAll)
+			if got := SupportsUnicode(); got != tt.want {
+				t.Errorf("SupportsUnicode() with TERM=%q LC_ALL=%q = %v, want %v", tt.term, tt.lcAll, got, tt.want)
+			}
+		})
+	}
+}
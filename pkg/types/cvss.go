@@ -0,0 +1,124 @@
+/*
+ * @Date: 2025-05-27 15:06:51
+ * @Editors: Mr wpl
+ * @Description: CVSS v3.1 严重程度与向量解析
+ */
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CVSS v3.1 严重程度标签
+const (
+	SeverityNone     = "None"
+	SeverityLow      = "Low"
+	SeverityMedium   = "Medium"
+	SeverityHigh     = "High"
+	SeverityCritical = "Critical"
+)
+
+// WebshellRCEVector 可远程执行任意代码的 webshell 对应的 CVSS 向量
+const WebshellRCEVector = "AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"
+
+// CvssComponents CVSS v3.1 基础指标，未出现在向量中的指标为空字符串
+type CvssComponents struct {
+	AttackVector       string // AV: N/A/L/P
+	AttackComplexity   string // AC: L/H
+	PrivilegesRequired string // PR: N/L/H
+	UserInteraction    string // UI: N/R
+	Scope              string // S: U/C
+	Confidentiality    string // C: H/L/N
+	Integrity          string // I: H/L/N
+	Availability       string // A: H/L/N
+}
+
+// cvssMetricValues 各基础指标允许的取值
+var cvssMetricValues = map[string]string{
+	"AV": "NALP",
+	"AC": "LH",
+	"PR": "NLH",
+	"UI": "NR",
+	"S":  "UC",
+	"C":  "HLN",
+	"I":  "HLN",
+	"A":  "HLN",
+}
+
+/**
+ * @Description: 解析 (可能不完整的) CVSS v3.1 向量，如 "AV:N/AC:L/PR:N"，允许 "CVSS:3.x/" 前缀
+ * @author: Mr wpl
+ * @param v string: CVSS 向量
+ * @return CvssComponents: 解析结果
+ * @return error: 指标或取值非法、指标重复时返回错误
+ */
+func ParseCVSSVector(v string) (CvssComponents, error) {
+	var c CvssComponents
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return c, fmt.Errorf("empty CVSS vector")
+	}
+
+	parts := strings.Split(v, "/")
+	if strings.HasPrefix(parts[0], "CVSS:") {
+		if parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1" {
+			return c, fmt.Errorf("unsupported CVSS version %q", parts[0])
+		}
+		parts = parts[1:]
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		metric, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return c, fmt.Errorf("malformed CVSS metric %q", part)
+		}
+		allowed, known := cvssMetricValues[metric]
+		if !known {
+			return c, fmt.Errorf("unknown CVSS metric %q", metric)
+		}
+		if len(value) != 1 || !strings.Contains(allowed, value) {
+			return c, fmt.Errorf("invalid value %q for CVSS metric %s", value, metric)
+		}
+		if seen[metric] {
+			return c, fmt.Errorf("duplicate CVSS metric %s", metric)
+		}
+		seen[metric] = true
+
+		switch metric {
+		case "AV":
+			c.AttackVector = value
+		case "AC":
+			c.AttackComplexity = value
+		case "PR":
+			c.PrivilegesRequired = value
+		case "UI":
+			c.UserInteraction = value
+		case "S":
+			c.Scope = value
+		case "C":
+			c.Confidentiality = value
+		case "I":
+			c.Integrity = value
+		case "A":
+			c.Availability = value
+		}
+	}
+	return c, nil
+}
+
+/**
+ * @Description: 规范化严重程度标签 (不区分大小写)，非法值返回空字符串
+ * @author: Mr wpl
+ * @param s string: 严重程度
+ * @return string: CVSS v3.1 严重程度标签
+ */
+func NormalizeSeverity(s string) string {
+	for _, label := range []string{SeverityNone, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical} {
+		if strings.EqualFold(strings.TrimSpace(s), label) {
+			return label
+		}
+	}
+	return ""
+}
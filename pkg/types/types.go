@@ -7,7 +7,14 @@
  */
 package types
 
-import "time"
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // 定义检测到的风险级别
 type RiskLevel int
@@ -39,6 +46,39 @@ func (rl RiskLevel) String() string {
 	}
 }
 
+// ParseRiskLevel 按名称 (不区分大小写，如 "high"、"Critical") 解析风险级别，"none" 等同于 "safe"
+func ParseRiskLevel(name string) (RiskLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "safe", "none":
+		return RiskNone, nil
+	case "low":
+		return RiskLow, nil
+	case "medium":
+		return RiskMedium, nil
+	case "high":
+		return RiskHigh, nil
+	case "critical":
+		return RiskCritical, nil
+	}
+	return RiskUnknown, fmt.Errorf("unknown risk level %q", name)
+}
+
+// 返回风险级别对应的 emoji 标识
+func (rl RiskLevel) Emoji() string {
+	switch rl {
+	case RiskNone:
+		return "🟢"
+	case RiskLow, RiskMedium:
+		return "🟡"
+	case RiskHigh:
+		return "🟠"
+	case RiskCritical:
+		return "🔴"
+	default:
+		return "⚪"
+	}
+}
+
 // DataPaths 定义数据文件路径
 type DataPaths struct {
 	Models     string `yaml:"models"`
@@ -49,26 +89,126 @@ type DataPaths struct {
 
 // Performance 定义性能相关配置
 type Performance struct {
-	Concurrency int `yaml:"concurrency"`
+	Concurrency        int `yaml:"concurrency"`
+	MaxFindingsPerFile int `yaml:"max_findings_per_file"` // 单个文件保留的最大发现数，超出部分按风险截断 (默认 50)
+	// SegmentedScanThreshold 超过该字节数的文件分段扫描：只运行 regex 与 yara，跳过 AST 分析 (默认 10MB)
+	SegmentedScanThreshold int64 `yaml:"segmented_scan_threshold"`
+	// ShutdownTimeout 扫描期间收到 SIGTERM 后等待进行中文件完成的秒数 (默认 30)，超时后输出部分报告
+	ShutdownTimeout int `yaml:"shutdown_timeout"`
+	// FileScanTimeout 单个文件扫描 (特征提取与全部分析器) 的时间上限，如 "120s" (默认 120s，负数表示不限制)，超时的文件记为扫描错误
+	FileScanTimeout time.Duration `yaml:"file_scan_timeout"`
+	// AnalyzerTimeout 单个分析器的时间上限，如 "500ms" (默认 30s，负数表示不限制)，超时记录一条 "analyzer timed out" 发现并继续
+	AnalyzerTimeout time.Duration `yaml:"analyzer_timeout"`
+	// MinFileSizeBytes 小于该字节数的文件不分析，直接视为 Safe (默认 0，不限制)
+	MinFileSizeBytes int64 `yaml:"min_file_size_bytes"`
+	// MaxFileSizeBytes 大于该字节数的文件不分析，直接视为 Safe (默认 0，不限制；未超过时大文件仍按 segmented_scan_threshold 分段扫描)
+	MaxFileSizeBytes int64 `yaml:"max_file_size_bytes"`
+	// ParallelAnalyzers 单个文件内只依赖文件内容与统计特征的分析器 (regex、yara、hash 等) 并发执行 (默认开启)
+	ParallelAnalyzers *bool `yaml:"parallel_analyzers"`
+	// ShortCircuit 出现 Critical 发现后跳过该文件剩余的分析器 (默认开启)，关闭后总是运行全部分析器
+	ShortCircuit *bool `yaml:"short_circuit"`
+}
+
+// DefaultMaxFindingsPerFile 未配置 max_findings_per_file 时单个文件保留的最大发现数
+const DefaultMaxFindingsPerFile = 50
+
+// DefaultSegmentedScanThreshold 未配置 segmented_scan_threshold 时分段扫描的文件大小阈值
+const DefaultSegmentedScanThreshold = 10 * 1024 * 1024
+
+// DefaultShutdownTimeout 未配置 shutdown_timeout 时收到 SIGTERM 后的等待时间
+const DefaultShutdownTimeout = 30 * time.Second
+
+// DefaultFileScanTimeout 未配置 file_scan_timeout 时单个文件的扫描时间上限
+const DefaultFileScanTimeout = 120 * time.Second
+
+// DefaultAnalyzerTimeout 未配置 analyzer_timeout 时单个分析器的运行时间上限
+const DefaultAnalyzerTimeout = 30 * time.Second
+
+// FindingsLimit 返回单个文件保留的最大发现数，未配置时使用默认值
+func (p *Performance) FindingsLimit() int {
+	if p.MaxFindingsPerFile <= 0 {
+		return DefaultMaxFindingsPerFile
+	}
+	return p.MaxFindingsPerFile
+}
+
+// ParallelAnalyzersEnabled 返回是否并发执行单个文件内相互独立的分析器，未配置时默认开启
+func (p *Performance) ParallelAnalyzersEnabled() bool {
+	return p.ParallelAnalyzers == nil || *p.ParallelAnalyzers
+}
+
+// ShortCircuitEnabled 返回出现 Critical 发现后是否跳过剩余分析器，未配置时默认开启
+func (p *Performance) ShortCircuitEnabled() bool {
+	return p.ShortCircuit == nil || *p.ShortCircuit
+}
+
+// SegmentThreshold 返回分段扫描的文件大小阈值，未配置时使用默认值
+func (p *Performance) SegmentThreshold() int64 {
+	if p.SegmentedScanThreshold <= 0 {
+		return DefaultSegmentedScanThreshold
+	}
+	return p.SegmentedScanThreshold
+}
+
+// ShutdownWait 返回收到 SIGTERM 后等待进行中文件的时间，未配置时使用默认值
+func (p *Performance) ShutdownWait() time.Duration {
+	if p.ShutdownTimeout <= 0 {
+		return DefaultShutdownTimeout
+	}
+	return time.Duration(p.ShutdownTimeout) * time.Second
+}
+
+// FileScanWait 返回单个文件的扫描时间上限，未配置时使用默认值，为 0 时不限制
+func (p *Performance) FileScanWait() time.Duration {
+	return timeoutOrDefault(p.FileScanTimeout, DefaultFileScanTimeout)
+}
+
+// AnalyzerWait 返回单个分析器的运行时间上限，未配置时使用默认值，为 0 时不限制
+func (p *Performance) AnalyzerWait() time.Duration {
+	return timeoutOrDefault(p.AnalyzerTimeout, DefaultAnalyzerTimeout)
+}
+
+// timeoutOrDefault 返回配置的时长：0 (未配置) 时为默认值，负数时为 0 (不限制)
+func timeoutOrDefault(timeout, defaultTimeout time.Duration) time.Duration {
+	switch {
+	case timeout == 0:
+		return defaultTimeout
+	case timeout < 0:
+		return 0
+	}
+	return timeout
 }
 
 // 文件信息结构体,保存文件的基本信息
 type FileInfo struct {
-	Path     string
-	Size     int64
-	ModTime  time.Time
-	MIMEType string // Optional: Can be added later
+	Path         string
+	RelativePath string // Path relative to the scan root (empty when outside it)
+	Size         int64
+	ModTime      time.Time
+	MIMEType     string // Optional: Can be added later
+	Encoding     string // Detected source encoding (e.g. "UTF-8", "GBK"); content is transcoded to UTF-8 before analysis
 	// Content []byte - Avoid storing full content here for memory efficiency
 }
 
+// DisplayPath 返回用于报告显示的路径，优先使用相对路径
+func (fi FileInfo) DisplayPath() string {
+	if fi.RelativePath != "" {
+		return fi.RelativePath
+	}
+	return fi.Path
+}
+
 // Finding represents a specific finding by an analyzer.
 type Finding struct {
-	AnalyzerName string    // Name of the analyzer that generated this finding
-	Description  string    // Description of the finding (e.g., "Matched Hash", "YARA Rule: XYZ")
-	Risk         RiskLevel // Assessed risk level by this analyzer
-	Confidence   float64   // Confidence score (0.0 to 1.0, optional for static)
-	// Snippet      string    // Relevant code snippet (optional)
-	// LineNumber   int       // Line number (optional)
+	AnalyzerName string                 // Name of the analyzer that generated this finding
+	Description  string                 // Description of the finding (e.g., "Matched Hash", "YARA Rule: XYZ")
+	Risk         RiskLevel              // Assessed risk level by this analyzer
+	Confidence   float64                // Confidence score (0.0 to 1.0, optional for static)
+	Metadata     map[string]interface{} // Analyzer-specific extra details (optional, e.g. "top_words")
+	Severity     string                 // CVSS v3.1 severity label (None/Low/Medium/High/Critical)
+	CVSSVector   string                 // Partial CVSS v3.1 vector where known (e.g. "AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H")
+	Snippet      string                 // Source line around the match, at most 200 bytes (regex/YARA only, optional)
+	LineNumber   int                    // 1-based line of the match, 0 if unknown
 }
 
 // ScanResult holds the overall result for a single scanned file.
@@ -80,18 +220,338 @@ type ScanResult struct {
 	Error       error         // Any error encountered during scanning this file
 	Duration    time.Duration // Time taken to scan this file
 	SkippedAST  bool          // Flag if AST generation was skipped due to early high-risk finding
+	PartialAST  bool          // AST 来自去掉末尾若干行后的源码 (原文件存在语法错误)
+	// ASTTruncatedLines 为得到 PartialAST 从文件末尾去掉的行数
+	ASTTruncatedLines int
+	// SkippedAnalyzers 因提前退出未执行的分析器及原因，如 "svm_prosses: critical finding from yara"
+	SkippedAnalyzers []string
+	// SuppressedFindings 置信度低于 output.min_confidence 而未参与评分的发现，仅在 -verbose 时显示
+	SuppressedFindings []*Finding
+	// TruncatedFindings 发现数超过 performance.max_findings_per_file 时为 true，Findings 仅保留风险最高的部分
+	TruncatedFindings bool
+	// DeployedBy 资产清单中登记的部署工具，非空时 OverallRisk 被限制为 Low (发现仍保留)
+	DeployedBy string
+	// DuplicateOf 内容与该路径的文件相同，结果复用自该文件而未重新扫描 (-no-dedup 时不去重)
+	DuplicateOf string
+	// Incomplete 收到 SIGTERM 时仍在扫描且未在 shutdown_timeout 内完成的文件，没有可用的结果
+	Incomplete bool
+	// Cached -incremental 时文件的修改时间与大小 (或内容) 未变，结果复用自扫描状态库而未重新扫描
+	Cached bool
+	// Callable 评分时的 callable 特征 (存在可执行关键函数)，用于离线重新评分 (scoring.ABTest)
+	Callable bool
+	// ContentMD5 文件原始内容的 MD5 (hex)，未读取内容 (空文件、跳过、读取失败) 时为空
+	ContentMD5 string
+	// ContentSHA256 扫描时读取的原始内容 (转码前) 的 SHA-256 (hex)，为空的情况同 ContentMD5。
+	// VirusTotal 查询、扫描历史等按此值关联文件，不再重新读取磁盘 (归档成员没有真实路径)
+	ContentSHA256 string
+	Signature     string // Base64 signature over the canonical result (empty if unsigned)
+	VT            string // VirusTotal detections, e.g. "12/70", "not_found", or "pending" if unresolved
+}
+
+// ComparisonResult 多个引擎 (不同配置) 对同一文件的扫描结果及按策略合并的风险级别
+type ComparisonResult struct {
+	Path      string                 // 文件路径 (phar 成员为虚拟路径)
+	Results   map[string]*ScanResult // 引擎名称 → 该引擎的扫描结果
+	Consensus RiskLevel              // 按 consensus 策略合并的风险级别
+}
+
+// ScanSummary 汇总一次扫描的文件数与各风险级别的文件数
+type ScanSummary struct {
+	TotalFiles     int
+	ErrorFiles     int
+	DuplicateFiles int // 复用了相同内容文件结果的文件数
+	RiskCounts     map[RiskLevel]int
+	// IncompleteFiles 因关闭 (SIGTERM) 中止的文件数，大于 0 时报告只包含部分结果
+	IncompleteFiles int
+}
+
+// CompletedFiles 完成扫描的文件数 (不含因关闭中止的文件)
+func (s *ScanSummary) CompletedFiles() int {
+	return s.TotalFiles - s.IncompleteFiles
+}
+
+// RiskCount 单个风险级别及其文件数
+type RiskCount struct {
+	Level RiskLevel
+	Count int
+}
+
+// Counts 按 Critical 到 Unknown 的顺序返回文件数大于 0 的风险级别
+func (s *ScanSummary) Counts() []RiskCount {
+	var counts []RiskCount
+	for _, level := range []RiskLevel{RiskCritical, RiskHigh, RiskMedium, RiskLow, RiskNone, RiskUnknown} {
+		if count := s.RiskCounts[level]; count > 0 {
+			counts = append(counts, RiskCount{Level: level, Count: count})
+		}
+	}
+	return counts
 }
 
 // Output 定义输出相关配置
 type Output struct {
-	Format string `yaml:"format"` // console, json, html
+	Format          string   `yaml:"format"`           // console, json, html
+	ConsoleTemplate string   `yaml:"console_template"` // 控制台报告模板 (text/template 内容或模板文件路径)，为空时使用默认格式
+	SortKeys        []string `yaml:"sort_keys"`        // 结果排序键，如 [risk_desc, path_asc]，为空时使用各报告的默认顺序
+	MinConfidence   float64  `yaml:"min_confidence"`   // 置信度低于该值 (且大于 0) 的发现被抑制，0 表示不过滤
+	PDFFont         string   `yaml:"pdf_font"`         // PDF 报告使用的 UTF-8 TrueType 字体文件，为空时使用内置字体 (英文文本)
+}
+
+// VirusTotal 定义 VirusTotal 哈希查询配置，APIKey 为空时不查询
+type VirusTotal struct {
+	APIKey            string `yaml:"api_key"`
+	Concurrency       int    `yaml:"concurrency"`         // 并发查询数 (默认 4)
+	RequestsPerMinute int    `yaml:"requests_per_minute"` // API 速率限制 (免费账户为 4)
+	CachePath         string `yaml:"cache_path"`          // 本地缓存 (默认 data/vt_cache.db)
+}
+
+// CallGraph 定义调用图分析器配置
+type CallGraph struct {
+	SuspiciousFunctions []string `yaml:"suspicious_functions"` // 循环调用中涉及这些函数时判定为 Critical (为空时使用内置列表)
+}
+
+// Ssdeep 定义 ssdeep 模糊哈希分析器配置
+type Ssdeep struct {
+	Threshold int `yaml:"threshold"` // 与已知 webshell 的相似度 (0-100) 超过该值时报告 High (默认 80)
+}
+
+// HashAnalyzer 定义已知恶意文件哈希分析器配置
+type HashAnalyzer struct {
+	FPRate float64 `yaml:"fp_rate"` // 布隆过滤器预筛选的目标误判率 (默认 0.001)，误判的哈希再经精确比对排除
+}
+
+// ScoringRules 定义 scoring.CalculateScore 的评分规则：各项命中的加分、分数上限与各风险等级的最低分
+type ScoringRules struct {
+	Regex                  int     `yaml:"regex"`                    // 正则匹配加分 (默认 1)
+	Yara                   int     `yaml:"yara"`                     // YARA 匹配加分 (默认 1)
+	RegexAndYara           int     `yaml:"regex_and_yara"`           // 正则与 YARA 同时匹配的额外加分 (默认 2)
+	CallableHighConfidence int     `yaml:"callable_high_confidence"` // callable 为 true 且 svm_prosses 高置信度时加分 (默认 2)
+	CallableStatistical    int     `yaml:"callable_statistical"`     // callable 为 true 且统计特征异常时加分 (默认 2)
+	HighConfidence         float64 `yaml:"high_confidence"`          // svm_prosses 置信度超过该值视为高置信度 (默认 0.91)
+	MaxScore               int     `yaml:"max_score"`                // 分数上限 (默认 5)
+	CriticalScore          int     `yaml:"critical_score"`           // 达到该分数为 Critical (默认 5)
+	HighScore              int     `yaml:"high_score"`               // 达到该分数为 High (默认 4)
+	MediumScore            int     `yaml:"medium_score"`             // 达到该分数为 Medium (默认 3)
+	LowScore               int     `yaml:"low_score"`                // 达到该分数为 Low (默认 1)
+}
+
+// DefaultScoringRules 返回默认评分规则 (未配置 scoring 时使用)
+func DefaultScoringRules() ScoringRules {
+	return ScoringRules{
+		Regex:                  1,
+		Yara:                   1,
+		RegexAndYara:           2,
+		CallableHighConfidence: 2,
+		CallableStatistical:    2,
+		HighConfidence:         0.91,
+		MaxScore:               5,
+		CriticalScore:          5,
+		HighScore:              4,
+		MediumScore:            3,
+		LowScore:               1,
+	}
+}
+
+// Logging 定义日志配置
+type Logging struct {
+	Format string `yaml:"format"` // text (默认) 或 json (每行一个 JSON 对象，供 ELK/Splunk 采集)
+	Level  string `yaml:"level"`  // 最低级别：debug、info (默认)、warn、error
+}
+
+// Watch 定义 -watch 监控模式配置
+type Watch struct {
+	Debounce   int    `yaml:"debounce"`    // 文件最后一次变化后等待的秒数，之后再重新扫描 (默认 2)
+	WebhookURL string `yaml:"webhook_url"` // 重新扫描后风险升高时 POST 通知的地址，为空时只记录日志
+}
+
+// DefaultWatchDebounce 未配置 watch.debounce 时的去抖时间
+const DefaultWatchDebounce = 2 * time.Second
+
+// DebounceWait 返回去抖时间，未配置或无效时使用默认值
+func (w *Watch) DebounceWait() time.Duration {
+	if w.Debounce <= 0 {
+		return DefaultWatchDebounce
+	}
+	return time.Duration(w.Debounce) * time.Second
+}
+
+// History 定义扫描历史库 (SQLite) 配置
+type History struct {
+	Enabled bool   `yaml:"enabled"` // 每次扫描完成后记录各文件的风险等级，供 -history-report 比较
+	DBPath  string `yaml:"db_path"` // 历史库路径 (默认 data/scan_history.db)
+}
+
+// DefaultHistoryDBPath 未配置 history.db_path 时的扫描历史库路径
+const DefaultHistoryDBPath = "data/scan_history.db"
+
+// Path 返回扫描历史库路径，未配置时为 DefaultHistoryDBPath
+func (h *History) Path() string {
+	if h.DBPath == "" {
+		return DefaultHistoryDBPath
+	}
+	return h.DBPath
+}
+
+// Email 定义发现高危文件时的邮件通知配置，Username、Password 支持 ${ENV_VAR} 引用环境变量
+type Email struct {
+	SMTPHost string   `yaml:"smtp_host"` // SMTP 服务器，为空时不发送邮件
+	SMTPPort int      `yaml:"smtp_port"` // SMTP 端口 (默认 25)
+	Username string   `yaml:"username"`  // 认证用户名，为空时不认证 (内网中继)
+	Password string   `yaml:"password"`  // 认证密码，建议写为 ${SMTP_PASSWORD}
+	From     string   `yaml:"from"`      // 发件人地址
+	To       []string `yaml:"to"`        // 收件人地址
+}
+
+// DefaultSMTPPort 未配置 email.smtp_port 时的 SMTP 端口
+const DefaultSMTPPort = 25
+
+// Enabled 是否配置了邮件通知 (smtp_host 与收件人均不为空)
+func (m *Email) Enabled() bool {
+	return m.SMTPHost != "" && len(m.To) > 0
+}
+
+// Addr 返回 SMTP 服务器地址 host:port
+func (m *Email) Addr() string {
+	port := m.SMTPPort
+	if port <= 0 {
+		port = DefaultSMTPPort
+	}
+	return net.JoinHostPort(m.SMTPHost, strconv.Itoa(port))
+}
+
+// Slack 定义 Slack Incoming Webhook 通知配置
+type Slack struct {
+	WebhookURL string `yaml:"webhook_url"` // Incoming Webhook 地址，为空时不发送 (-slack-webhook 覆盖)
+	MinRisk    string `yaml:"min_risk"`    // 达到该风险等级的文件才通知 (默认 high)
+}
+
+// DefaultSlackMinRisk 未配置 slack.min_risk 时的通知阈值
+const DefaultSlackMinRisk = RiskHigh
+
+// Enabled 是否配置了 Slack 通知
+func (s *Slack) Enabled() bool {
+	return s.WebhookURL != ""
+}
+
+// MinRiskLevel 返回通知阈值，未配置时为 DefaultSlackMinRisk
+func (s *Slack) MinRiskLevel() (RiskLevel, error) {
+	if strings.TrimSpace(s.MinRisk) == "" {
+		return DefaultSlackMinRisk, nil
+	}
+	return ParseRiskLevel(s.MinRisk)
+}
+
+// AST 定义 PHP 桥接 (AST 解析) 配置
+type AST struct {
+	PoolSize int `yaml:"pool_size"` // 并发解析的 PHP 桥接数，0 时为 min(performance.concurrency, 4)
+}
+
+// PoolSizeFor 返回连接池大小：未配置时为 min(concurrency, 4)，至少为 1
+func (a *AST) PoolSizeFor(concurrency int) int {
+	size := a.PoolSize
+	if size <= 0 {
+		size = min(concurrency, 4)
+	}
+	return max(size, 1)
 }
 
 // Config structure (基本示例,根据需要扩展)
 type Config struct {
-	DataPaths        DataPaths   `yaml:"data_paths"`
-	Performance      Performance `yaml:"performance"`
-	Output           Output      `yaml:"output"`
-	EnabledAnalyzers []string    `yaml:"enabled_analyzers"` // List of analyzer names to run
+	DataPaths        DataPaths    `yaml:"data_paths"`
+	Performance      Performance  `yaml:"performance"`
+	Output           Output       `yaml:"output"`
+	EnabledAnalyzers []string     `yaml:"enabled_analyzers"` // List of analyzer names to run
+	BridgeTransport  string       `yaml:"bridge_transport"`  // PHP bridge transport: "pipe" (default) or "shmem"
+	AST              AST          `yaml:"ast"`               // PHP bridge pool settings
+	VirusTotal       VirusTotal   `yaml:"virustotal"`        // Optional VirusTotal enrichment for risky files
+	CallGraph        CallGraph    `yaml:"callgraph"`         // callgraph analyzer settings
+	Ssdeep           Ssdeep       `yaml:"ssdeep"`            // ssdeep analyzer settings
+	HashAnalyzer     HashAnalyzer `yaml:"hash"`              // hash analyzer settings
+	Watch            Watch        `yaml:"watch"`             // -watch mode settings
+	Logging          Logging      `yaml:"logging"`           // Log format and minimum level
+	History          History      `yaml:"history"`           // Scan history database for trend analysis
+	Email            Email        `yaml:"email"`             // Email notification when High/Critical files are found
+	Slack            Slack        `yaml:"slack"`             // Slack webhook notification for risky files
+	EarlyExit        *bool        `yaml:"early_exit"`        // Skip remaining analyzers once one reports Critical (default true)
+	// Scoring 评分规则，配置文件中未填写的字段保持默认值 (DefaultScoringRules)
+	Scoring ScoringRules `yaml:"scoring"`
+	// WhitelistPath 白名单文件 (每行一个路径 glob 或 sha256:<哈希>)，匹配的文件不运行分析器，直接视为 Safe (来自 -whitelist)
+	WhitelistPath string `yaml:"whitelist_path"`
+	// AssetProvider 部署资产清单："file"、"file:<路径>" 或 CMDB 的 http(s) 地址，已知部署文件风险最高为 Low
+	AssetProvider string `yaml:"asset_provider"`
+	// ASTCompression 管道传输时桥接进程以 gzip 压缩 AST JSON，适合大量大文件的扫描
+	ASTCompression bool `yaml:"ast_compression"`
+	// ScanExtensions 遍历目录时扫描的文件扩展名，如 [".php", ".jsp", ".jspx"]，未配置时只扫描 .php
+	ScanExtensions []string `yaml:"scan_extensions"`
+	// StatePath -incremental 扫描状态库路径 (默认 data/scan_state.db)，记录每个文件的修改时间、大小、SHA-256 与上次结果
+	StatePath string `yaml:"state_path"`
+	// ScanArchives 解包 phar、ZIP、TAR.GZ 归档并扫描其中的文件 (与 -scan-archives 任一开启即生效)
+	ScanArchives bool `yaml:"scan_archives"`
+	// Exclusions 排除的路径或 glob (如 vendor/**、*.min.php)，与 -exclude 合并
+	Exclusions []string `yaml:"exclusions"`
+	// ScanHtaccess 遍历目录时同时收集 .htaccess 文件，由 htaccess 分析器检查
+	ScanHtaccess bool `yaml:"scan_htaccess"`
+	// MaxArchiveSize 单个归档 (含嵌套归档) 解压的总字节数上限，超过后停止解包该归档，0 时为 DefaultMaxArchiveSize
+	MaxArchiveSize int64 `yaml:"max_archive_size"`
 	// Add more config options: Exclusions, ScanDepth etc.
 }
+
+// DefaultScanExtension 未配置 scan_extensions 时扫描的文件扩展名
+const DefaultScanExtension = ".php"
+
+// PHPExtensions PHP 文件的扩展名，供只适用于 PHP 的分析器 (基于 PHP 样本训练的模型等) 声明 SupportedExtensions
+var PHPExtensions = []string{".php", ".php3", ".php4", ".php5", ".php7", ".phtml", ".inc"}
+
+// HtaccessFileName Apache 目录配置文件名，filepath.Ext 将其整体视为扩展名，作为 "htaccess" 伪扩展名参与扩展名过滤
+const HtaccessFileName = ".htaccess"
+
+// Extensions 返回需要扫描的文件扩展名 (小写、带前导点)，未配置时为 [".php"]；scan_htaccess 开启时追加 ".htaccess"
+func (c *Config) Extensions() []string {
+	var exts []string
+	for _, ext := range c.ScanExtensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		exts = append(exts, ext)
+	}
+	if len(exts) == 0 {
+		exts = []string{DefaultScanExtension}
+	}
+	if c.ScanHtaccess && !slices.Contains(exts, HtaccessFileName) {
+		exts = append(exts, HtaccessFileName)
+	}
+	return exts
+}
+
+// DefaultStatePath 未配置 state_path 时的扫描状态库路径
+const DefaultStatePath = "data/scan_state.db"
+
+// StateFile 返回扫描状态库路径，未配置时为 DefaultStatePath
+func (c *Config) StateFile() string {
+	if c.StatePath == "" {
+		return DefaultStatePath
+	}
+	return c.StatePath
+}
+
+// DefaultMaxArchiveSize 未配置 max_archive_size 时单个归档的解压总大小上限
+const DefaultMaxArchiveSize = 100 * 1024 * 1024
+
+// ArchiveSizeLimit 返回单个归档的解压总大小上限，未配置时为 DefaultMaxArchiveSize，负数表示不限制
+func (c *Config) ArchiveSizeLimit() int64 {
+	if c.MaxArchiveSize == 0 {
+		return DefaultMaxArchiveSize
+	}
+	if c.MaxArchiveSize < 0 {
+		return 0
+	}
+	return c.MaxArchiveSize
+}
+
+// EarlyExitEnabled 返回是否在出现 Critical 发现后跳过剩余分析器：early_exit 与 performance.short_circuit 均开启时为 true，未配置时默认开启
+func (c *Config) EarlyExitEnabled() bool {
+	return (c.EarlyExit == nil || *c.EarlyExit) && c.Performance.ShortCircuitEnabled()
+}
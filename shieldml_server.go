@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
@@ -16,6 +17,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"bt-shieldml/internal/feedback"
+	"bt-shieldml/internal/reporting"
+	"bt-shieldml/internal/scanstats"
+	"bt-shieldml/internal/submissions"
 )
 
 type ScanResult struct {
@@ -27,25 +33,39 @@ type ScanResult struct {
 	Risk     string `json:"risk"`
 	Icon     string `json:"icon"`
 	Desc     string `json:"desc"`
+
+	RiskScore  int      `json:"risk_score"`
+	Analyzers  []string `json:"analyzers,omitempty"`
+	Findings   []string `json:"findings,omitempty"`
+	DurationMs int64    `json:"duration_ms"`
 }
 
 // JSON文件结构体
 type JsonFileData struct {
 	Results []struct {
-		Filename    string `json:"filename"`
-		Type        string `json:"type"`
-		Risk        int    `json:"risk"`
-		RiskText    string `json:"risk_text"`
-		Description string `json:"description"`
+		Filename    string   `json:"filename"`
+		Type        string   `json:"type"`
+		Risk        int      `json:"risk"`
+		RiskText    string   `json:"risk_text"`
+		Description string   `json:"description"`
+		Analyzers   []string `json:"analyzers"`
+		Findings    []string `json:"findings"`
+		DurationMs  int64    `json:"duration_ms"`
 	} `json:"results"`
 }
 
+// 最近一次扫描结果的缓存文件，供导出使用
+const scanCachePath = "data/last_scan_result.json"
+
 // 扫描锁，防止并发扫描
 var scanLock sync.Mutex
 
 // 上次扫描时间
 var lastScanTime time.Time
 
+// 扫描统计记录器，写入 data/scan_journal.jsonl，供 /api/stats 使用
+var statsRecorder = scanstats.NewRecorder(scanstats.DefaultJournalPath)
+
 // 添加安全相关HTTP头
 func securityMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +86,12 @@ func securityMiddleware(next http.Handler) http.Handler {
 func main() {
 	// API路由
 	http.HandleFunc("/api/scan", scanHandler)
+	http.HandleFunc("/api/export-xlsx", exportXlsxHandler)
+	http.HandleFunc("/api/feedback", feedbackHandler)
+	http.HandleFunc("/api/stats", statsHandler)
+	http.HandleFunc("/api/stats/analyzer", analyzerStatsHandler)
+	http.HandleFunc("/api/stats/reset", statsResetHandler)
+	http.HandleFunc("/api/submit-pattern", submitPatternHandler)
 
 	// 静态文件处理
 	fileHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -110,6 +136,7 @@ func scanHandler(w http.ResponseWriter, r *http.Request) {
 	// 防止并发扫描，加锁
 	scanLock.Lock()
 	defer scanLock.Unlock()
+	scanStart := time.Now()
 
 	err := r.ParseMultipartForm(20 << 20) // 20MB
 	if err != nil {
@@ -237,14 +264,18 @@ func scanHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		results = append(results, ScanResult{
-			Filename: originalName,
-			Size:     fileInfo.size,
-			MD5:      fileInfo.md5,
-			SHA256:   fileInfo.sha256,
-			Type:     fileInfo.ftype,
-			Risk:     risk,
-			Icon:     icon,
-			Desc:     desc,
+			Filename:   originalName,
+			Size:       fileInfo.size,
+			MD5:        fileInfo.md5,
+			SHA256:     fileInfo.sha256,
+			Type:       fileInfo.ftype,
+			Risk:       risk,
+			Icon:       icon,
+			Desc:       desc,
+			RiskScore:  res.Risk,
+			Analyzers:  res.Analyzers,
+			Findings:   res.Findings,
+			DurationMs: res.DurationMs,
 		})
 	}
 
@@ -266,10 +297,257 @@ func scanHandler(w http.ResponseWriter, r *http.Request) {
 		return orderI < orderJ
 	})
 
+	// 缓存本次结果，供导出接口使用
+	if err := writeScanCache(results); err != nil {
+		fmt.Println("缓存扫描结果失败:", err)
+	}
+	recordScanStats(scanStart, results)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
 }
 
+// 将扫描结果写入缓存文件
+func writeScanCache(results []ScanResult) error {
+	if err := os.MkdirAll(filepath.Dir(scanCachePath), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(map[string]interface{}{"results": results})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(scanCachePath, data, 0644)
+}
+
+// 读取缓存的扫描结果
+func readScanCache() ([]ScanResult, error) {
+	content, err := ioutil.ReadFile(scanCachePath)
+	if err != nil {
+		return nil, err
+	}
+	var data struct {
+		Results []ScanResult `json:"results"`
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	return data.Results, nil
+}
+
+// 根据风险分数返回风险等级名称
+func riskLevelName(score int) string {
+	switch {
+	case score >= 5:
+		return "Critical"
+	case score >= 4:
+		return "High"
+	case score >= 3:
+		return "Medium"
+	case score >= 1:
+		return "Low"
+	default:
+		return "None"
+	}
+}
+
+// 将本次扫描写入扫描日志，写入失败不影响扫描结果返回
+func recordScanStats(start time.Time, results []ScanResult) {
+	lastScanTime = start
+	entry := scanstats.JournalEntry{
+		Timestamp:  start,
+		DurationMs: time.Since(start).Milliseconds(),
+		Files:      make([]scanstats.FileRecord, 0, len(results)),
+	}
+	for _, res := range results {
+		entry.Files = append(entry.Files, scanstats.FileRecord{
+			Filename:   res.Filename,
+			SHA256:     res.SHA256,
+			RiskScore:  res.RiskScore,
+			Risk:       riskLevelName(res.RiskScore),
+			Analyzers:  res.Analyzers,
+			DurationMs: res.DurationMs,
+		})
+	}
+	if err := statsRecorder.Record(entry); err != nil {
+		fmt.Println("写入扫描日志失败:", err)
+	}
+}
+
+// 返回扫描统计：今日扫描次数与文件数、各风险等级检出数、平均耗时、检出最多的文件
+func statsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "仅支持GET", http.StatusMethodNotAllowed)
+		return
+	}
+	stats := scanstats.Compute(statsRecorder.Entries(), time.Now())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// 返回各分析器的触发次数与命中率
+func analyzerStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "仅支持GET", http.StatusMethodNotAllowed)
+		return
+	}
+	stats := scanstats.ComputeAnalyzerStats(statsRecorder.Entries())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// 清空扫描统计 (删除扫描日志)
+func statsResetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "仅支持POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := statsRecorder.Reset(); err != nil {
+		fmt.Println("清空扫描统计失败:", err)
+		http.Error(w, "清空统计失败", 500)
+		return
+	}
+	lastScanTime = time.Time{}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// 记录人工反馈 (误报/漏报)，供 apply-feedback 工具调整 Bayes 模型
+func feedbackHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "仅支持POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Path         string `json:"path"`
+		CorrectLabel string `json:"correct_label"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&req); err != nil {
+		http.Error(w, "请求格式错误", http.StatusBadRequest)
+		return
+	}
+
+	entry := feedback.Entry{
+		Path:         req.Path,
+		CorrectLabel: req.CorrectLabel,
+		Timestamp:    time.Now(),
+	}
+	if err := entry.Validate(); err != nil {
+		http.Error(w, "参数错误: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := feedback.Append(feedback.DefaultPath, entry); err != nil {
+		fmt.Println("写入反馈失败:", err)
+		http.Error(w, "保存反馈失败", 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// 接收运维人员提交的新 webshell 规则 (regex/yara)，试编译通过后写入 data/submissions.jsonl，
+// 由 review-submissions 工具审核后并入规则库
+func submitPatternHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "仅支持POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Type        string `json:"type"`
+		Pattern     string `json:"pattern"`
+		Description string `json:"description"`
+		Submitter   string `json:"submitter"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 2*submissions.MaxPatternLen)).Decode(&req); err != nil {
+		http.Error(w, "请求格式错误", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	sub := submissions.Submission{
+		ID:          submissions.NewID(now),
+		Type:        strings.ToLower(strings.TrimSpace(req.Type)),
+		Pattern:     req.Pattern,
+		Description: strings.TrimSpace(req.Description),
+		Submitter:   strings.TrimSpace(req.Submitter),
+		Timestamp:   now,
+	}
+	if err := sub.Validate(); err != nil {
+		http.Error(w, "参数错误: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 返回试编译结果，编译失败的规则不保存
+	compile := map[string]interface{}{"ok": true}
+	w.Header().Set("Content-Type", "application/json")
+	if err := submissions.TestCompile(sub.Type, sub.Pattern); err != nil {
+		compile["ok"] = false
+		compile["error"] = err.Error()
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "error", "compile": compile})
+		return
+	}
+	if err := submissions.Append(submissions.DefaultPath, sub); err != nil {
+		fmt.Println("保存规则提交失败:", err)
+		http.Error(w, "保存提交失败", 500)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "id": sub.ID, "compile": compile})
+}
+
+// 导出最近一次扫描结果为Excel文件：Scan Report 工作表每个文件一行，按风险等级填充颜色 (reporting.WriteExcelScanReport)
+func exportXlsxHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "仅支持GET", http.StatusMethodNotAllowed)
+		return
+	}
+
+	results, err := readScanCache()
+	if err != nil {
+		fmt.Println("读取缓存结果失败:", err)
+		http.Error(w, "没有可导出的扫描结果", http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := reporting.WriteExcelScanReport(&buf, toJSONReport(results)); err != nil {
+		fmt.Println("生成Excel失败:", err)
+		http.Error(w, "生成Excel失败", 500)
+		return
+	}
+	w.Header().Set("Content-Type", reporting.ExcelContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=scan_report.xlsx")
+	if _, err := buf.WriteTo(w); err != nil {
+		fmt.Println("写出Excel失败:", err)
+	}
+}
+
+// 将缓存的扫描结果转换为 reporting 的 JSON 报告结构 (Filename 为上传文件的完整路径)
+func toJSONReport(results []ScanResult) *reporting.JSONReport {
+	report := &reporting.JSONReport{Results: make([]reporting.SimpleResult, 0, len(results))}
+	for _, res := range results {
+		report.Results = append(report.Results, reporting.SimpleResult{
+			Filename:   filepath.Base(res.Filename),
+			Type:       res.Type,
+			Risk:       res.RiskScore,
+			RiskText:   res.Risk,
+			Desc:       res.Desc,
+			Path:       res.Filename,
+			Size:       res.Size,
+			MD5:        res.MD5,
+			SHA256:     res.SHA256,
+			Analyzers:  res.Analyzers,
+			Findings:   res.Findings,
+			DurationMs: res.DurationMs,
+		})
+	}
+	return report
+}
+
 // 读取JSON文件
 func readJsonFile(path string) (*JsonFileData, error) {
 	// 确保文件存在